@@ -30,6 +30,8 @@ require (
 	github.com/stretchr/testify v1.4.0
 	github.com/urfave/cli v1.21.0
 	github.com/zalando/go-keyring v0.0.0-20190913082157-62750a1ff80d
+	golang.org/x/crypto v0.0.0-20191206172530-e9b2fee46413
+	golang.org/x/net v0.0.0-20191126235420-ef20fe5d7933
 	golang.org/x/oauth2 v0.0.0-20190604053449-0f29369cfe45
 	golang.org/x/time v0.0.0-20191023065245-6d3f0bb11be5 // indirect
 	google.golang.org/appengine v1.6.5 // indirect
@@ -42,6 +44,7 @@ require (
 	k8s.io/client-go v0.0.0-20191016111102-bec269661e48
 	k8s.io/kube-openapi v0.0.0-20200121204235-bf4fb3bd569c // indirect
 	k8s.io/utils v0.0.0-20191010214722-8d271d903fe4 // indirect
+	sigs.k8s.io/yaml v1.1.0
 )
 
 replace github.com/docker/docker => github.com/docker/engine v17.12.0-ce-rc1.0.20191007211215-3e077fc8667a+incompatible
@@ -0,0 +1,154 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// cliConfigDir is the directory holding the CLI config file
+var cliConfigDir = filepath.Join(homeDir, ".codewind")
+
+// ConfigList : Prints the settings in the CLI config file
+func ConfigList(c *cli.Context) {
+	cliConfig, configErr := config.LoadCLIConfig(cliConfigDir)
+	if configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(cliConfig)
+		return
+	}
+	tableContent := []string{"SETTING\tVALUE"}
+	for _, key := range config.CLIConfigKeys {
+		value, _ := cliConfig.Get(key)
+		tableContent = append(tableContent, key+"\t"+value)
+	}
+	PrintTable(tableContent)
+}
+
+// ConfigGet : Prints the value of a setting in the CLI config file, empty if it is not set
+func ConfigGet(c *cli.Context) {
+	if c.NArg() != 1 {
+		logr.Errorln("Must specify a setting: " + strings.Join(config.CLIConfigKeys, ", "))
+		exit(1)
+	}
+	cliConfig, configErr := config.LoadCLIConfig(cliConfigDir)
+	if configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+	value, configErr := cliConfig.Get(c.Args().First())
+	if configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(value)
+		return
+	}
+	fmt.Fprintln(commandOutput(), value)
+}
+
+// ConfigSet : Sets a setting in the CLI config file, an empty value unsetting it
+func ConfigSet(c *cli.Context) {
+	if c.NArg() != 2 {
+		logr.Errorln("Must specify a setting and its value")
+		exit(1)
+	}
+	key, value := c.Args().Get(0), c.Args().Get(1)
+	cliConfig, configErr := config.LoadCLIConfig(cliConfigDir)
+	if configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+	if key == config.KeyConnection && value != "" {
+		if _, conErr := connections.GetConnectionByID(value); conErr != nil {
+			HandleConnectionError(conErr)
+			exit(1)
+		}
+	}
+	if configErr := cliConfig.Set(key, value); configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+	if configErr := config.SaveCLIConfig(cliConfigDir, cliConfig); configErr != nil {
+		HandleConfigError(configErr)
+		exit(1)
+	}
+}
+
+// loadCLIConfig reads the CLI config file when cwctl starts. A file that cannot be read is reported and ignored, so
+// that it can still be fixed with the config command.
+func loadCLIConfig() config.CLIConfig {
+	cliConfig, configErr := config.LoadCLIConfig(cliConfigDir)
+	if configErr != nil {
+		logr.Warnln(configErr.Desc)
+	}
+	return cliConfig
+}
+
+// applyCLIConfig replaces the built-in defaults of flags with the settings of the CLI config file. A default is only
+// used when neither the flag nor its environment variable is set, so flags take precedence over environment variables,
+// which take precedence over the config file. The json setting is applied when the global flags are read.
+func applyCLIConfig(app *cli.App, cliConfig config.CLIConfig) {
+	applyFlagDefaults(app.Flags, cliConfig)
+	applyCommandDefaults(app.Commands, cliConfig)
+
+	// Kubernetes clients read the kubeconfig from KUBECONFIG, so set it unless it is already set
+	if cliConfig.Kubeconfig != "" && os.Getenv("KUBECONFIG") == "" {
+		kubeconfig := cliConfig.Kubeconfig
+		if strings.HasPrefix(kubeconfig, "~/") {
+			kubeconfig = filepath.Join(homeDir, kubeconfig[2:])
+		}
+		os.Setenv("KUBECONFIG", kubeconfig)
+	}
+}
+
+func applyCommandDefaults(commands []cli.Command, cliConfig config.CLIConfig) {
+	for i := range commands {
+		applyFlagDefaults(commands[i].Flags, cliConfig)
+		applyCommandDefaults(commands[i].Subcommands, cliConfig)
+	}
+}
+
+func applyFlagDefaults(flags []cli.Flag, cliConfig config.CLIConfig) {
+	for i, flag := range flags {
+		switch f := flag.(type) {
+		case cli.StringFlag:
+			name := flagNames(f)[0]
+			// Only --conid flags defaulting to the local connection change, not those found from the project
+			if name == "conid" && f.Value == "local" && cliConfig.Connection != "" {
+				f.Value = cliConfig.Connection
+			} else if name == "loglevel" && cliConfig.LogLevel != "" {
+				f.Value = cliConfig.LogLevel
+			} else if name == "registry-mirror" && cliConfig.RegistryMirror != "" {
+				f.Value = cliConfig.RegistryMirror
+			}
+			flags[i] = f
+		case cli.IntFlag:
+			if flagNames(f)[0] == "concurrency" && cliConfig.SyncConcurrency > 0 {
+				f.Value = cliConfig.SyncConcurrency
+			}
+			flags[i] = f
+		}
+	}
+}
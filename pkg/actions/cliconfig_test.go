@@ -0,0 +1,86 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"os"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func Test_applyCLIConfig(t *testing.T) {
+	newApp := func() *cli.App {
+		app := cli.NewApp()
+		app.Flags = []cli.Flag{cli.StringFlag{Name: "loglevel", Value: "info"}}
+		app.Commands = []cli.Command{
+			{
+				Name: "project",
+				Subcommands: []cli.Command{
+					{
+						Name: "bind",
+						Flags: []cli.Flag{
+							cli.StringFlag{Name: "conid", Value: "local"},
+							cli.IntFlag{Name: "concurrency", Value: 4},
+						},
+					},
+					{
+						Name:  "sync",
+						Flags: []cli.Flag{cli.StringFlag{Name: "conid"}},
+					},
+				},
+			},
+			{
+				Name:  "start",
+				Flags: []cli.Flag{cli.StringFlag{Name: "registry-mirror", EnvVar: "CW_REGISTRY_MIRROR"}},
+			},
+		}
+		return app
+	}
+	cliConfig := config.CLIConfig{Connection: "K8S1", LogLevel: "debug", RegistryMirror: "mirror.example.com", SyncConcurrency: 8}
+
+	t.Run("replaces the built-in defaults", func(t *testing.T) {
+		app := newApp()
+		applyCLIConfig(app, cliConfig)
+		assert.Equal(t, "debug", app.Flags[0].(cli.StringFlag).Value)
+		bind := app.Commands[0].Subcommands[0]
+		assert.Equal(t, "K8S1", bind.Flags[0].(cli.StringFlag).Value)
+		assert.Equal(t, 8, bind.Flags[1].(cli.IntFlag).Value)
+		assert.Equal(t, "", app.Commands[0].Subcommands[1].Flags[0].(cli.StringFlag).Value)
+		assert.Equal(t, "mirror.example.com", app.Commands[1].Flags[0].(cli.StringFlag).Value)
+	})
+
+	t.Run("flags and environment variables take precedence", func(t *testing.T) {
+		os.Setenv("CW_REGISTRY_MIRROR", "env.example.com")
+		defer os.Unsetenv("CW_REGISTRY_MIRROR")
+		var conID, registryMirror string
+		app := newApp()
+		app.Commands[0].Subcommands[0].Action = func(c *cli.Context) error {
+			conID = c.String("conid")
+			return nil
+		}
+		app.Commands[1].Action = func(c *cli.Context) error {
+			registryMirror = c.String("registry-mirror")
+			return nil
+		}
+		applyCLIConfig(app, cliConfig)
+
+		assert.Nil(t, app.Run([]string{"cwctl", "project", "bind"}))
+		assert.Equal(t, "K8S1", conID)
+		assert.Nil(t, app.Run([]string{"cwctl", "project", "bind", "--conid", "local"}))
+		assert.Equal(t, "local", conID)
+		assert.Nil(t, app.Run([]string{"cwctl", "start"}))
+		assert.Equal(t, "env.example.com", registryMirror)
+	})
+}
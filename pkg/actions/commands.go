@@ -16,11 +16,19 @@ import (
 	"net/http"
 	"os"
 	"path"
+	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	"github.com/eclipse/codewind-installer/pkg/config"
 	desktoputils "github.com/eclipse/codewind-installer/pkg/desktop_utils"
+	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/eclipse/codewind-installer/pkg/errors"
 	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/logging"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/telemetry"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -48,14 +56,64 @@ func Commands() {
 			Name:  "insecureKeyring",
 			Usage: "use insecure keyring instead of system keyring",
 		},
+		cli.StringFlag{
+			Name:   "credential-store",
+			Value:  "keychain",
+			Usage:  "where to keep passwords and tokens {keychain,file,both}",
+			EnvVar: "CREDENTIAL_STORE",
+		},
+		cli.BoolFlag{
+			Name:  "in-cluster",
+			Usage: "use the service account of the pod cwctl is running in for Kubernetes operations",
+		},
 		cli.BoolFlag{
 			Name:  "json, j",
 			Usage: "output as JSON",
 		},
+		cli.StringFlag{
+			Name:  "locale",
+			Usage: "language of error messages, eg: de or fr (default: from LC_ALL, LC_MESSAGES or LANG)",
+		},
+		cli.BoolFlag{
+			Name:   "non-interactive, quiet",
+			Usage:  "never prompt for input, exiting with status 3 when input is needed",
+			EnvVar: "CW_NON_INTERACTIVE",
+		},
 		cli.StringFlag{
 			Name:  "loglevel",
 			Value: "info",
-			Usage: "log level {trace,debug,info,fatal,error}",
+			Usage: "log level {trace,debug,info,warn,error,fatal}",
+		},
+		cli.StringFlag{
+			Name:   "log-format",
+			Value:  logging.FormatText,
+			Usage:  "log format {text,json}",
+			EnvVar: "CW_LOG_FORMAT",
+		},
+		cli.StringFlag{
+			Name:   "log-file",
+			Usage:  "file to append logs to, instead of stderr",
+			EnvVar: "CW_LOG_FILE",
+		},
+		cli.BoolFlag{
+			Name:   "trace-http",
+			Usage:  "trace requests sent to Codewind connections, with their status, latency and token refreshes, to stderr",
+			EnvVar: "CW_TRACE",
+		},
+		cli.BoolFlag{
+			Name:   "trace-http-headers",
+			Usage:  "also trace request and response headers, with credentials removed",
+			EnvVar: "CW_TRACE_HEADERS",
+		},
+		cli.DurationFlag{
+			Name:   "timeout",
+			Usage:  "deadline for each attempt of a request sent to a Codewind connection, except streams and file transfers eg: 30s (default: none)",
+			EnvVar: "CW_TIMEOUT",
+		},
+		cli.BoolFlag{
+			Name:   "skip-version-check",
+			Usage:  "run project commands on remote connections whose Codewind release cwctl does not work with",
+			EnvVar: "CW_SKIP_VERSION_CHECK",
 		},
 	}
 
@@ -78,6 +136,10 @@ func Commands() {
 						cli.StringFlag{Name: "username", Usage: "Username for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "password", Usage: "Password for GitHub account authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
 						cli.StringFlag{Name: "personalAccessToken", Usage: "PersonalAccessToken authorized to download the provided URL. Takes precedence over git credentials stored in keychain", Required: false},
+						cli.StringFlag{Name: "branch", Usage: "Branch of the git repository to create the project from", Required: false},
+						cli.StringFlag{Name: "tag", Usage: "Tag of the git repository to create the project from", Required: false},
+						cli.StringFlag{Name: "commit", Usage: "Commit of the git repository to create the project from", Required: false},
+						cli.StringFlag{Name: "ssh-key", Usage: "Path to the private key used to clone an SSH URL. The SSH agent and default keys are used if not given", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectCreate(c)
@@ -101,13 +163,16 @@ func Commands() {
 				},
 				{
 					Name:  "bind",
-					Usage: "Bind a project to codewind for building and running",
+					Usage: "Bind a project to codewind for building and running, requires 'name', 'language', 'type' and 'path' unless binding every project in a directory with 'all'",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "name, n", Usage: "The name of the project", Required: true},
-						cli.StringFlag{Name: "language, l", Usage: "The project language", Required: true},
-						cli.StringFlag{Name: "type, t", Usage: "The type of the project", Required: true},
-						cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: true},
+						cli.StringFlag{Name: "name, n", Usage: "The name of the project", Required: false},
+						cli.StringFlag{Name: "language, l", Usage: "The project language", Required: false},
+						cli.StringFlag{Name: "type, t", Usage: "The type of the project", Required: false},
+						cli.StringFlag{Name: "path, p", Usage: "The path to the project", Required: false},
 						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id for the project", Required: false},
+						cli.StringFlag{Name: "all", Usage: "Bind every project found in a directory, detecting the language and type of each", Required: false},
+						cli.IntFlag{Name: "concurrency", Value: project.DefaultBindConcurrency, Usage: "How many projects to bind and sync at the same time with --all", Required: false},
+						cli.StringFlag{Name: "mirror", Usage: "Bind the project as a mirror of the project with this id on another connection, synced with it by 'sync --mirror'", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectBind(c)
@@ -120,6 +185,8 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
 						cli.BoolFlag{Name: "delete, d", Usage: "delete local project files"},
+						cli.BoolFlag{Name: "keep-deployment", Usage: "keep the container or deployment of the project running"},
+						cli.BoolFlag{Name: "keep-files", Usage: "keep the project files synced to Codewind"},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectRemove(c)
@@ -132,19 +199,57 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "path, p", Usage: "the path to the project", Required: true},
 						cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
-						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds", Required: true},
+						cli.StringFlag{Name: "time, t", Usage: "UNIX timestamp of the last sync for the given project, in milliseconds", Required: false},
+						cli.StringFlag{Name: "modified-since", Usage: "Only upload the files modified since a time, given as a duration before now eg: 2h, or an RFC 3339 time, whatever --time is", Required: false},
+						cli.StringFlag{Name: "detection", Value: project.ChangeDetectionAuto, Usage: "How changed files are found: mtime, poll, or auto to poll projects on network file systems such as NFS or SMB", Required: false},
+						cli.BoolFlag{Name: "watch, w", Usage: "Keep polling the project for changes, syncing it each time its files change until interrupted", Required: false},
+						cli.DurationFlag{Name: "interval", Value: project.DefaultWatchInterval, Usage: "How often --watch polls the project for changes eg: 5s", Required: false},
+						cli.BoolFlag{Name: "mirror, m", Usage: "Also sync the mirrors of the project on other connections, all at the same time", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectSync(c)
 						return nil
 					},
 				},
+				{
+					Name:  "mirror",
+					Usage: "Manage the mirrors of a project, bound to other connections with 'bind --mirror'",
+					Subcommands: []cli.Command{
+						{
+							Name:    "list",
+							Aliases: []string{"ls"},
+							Usage:   "List the mirrors of a project",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectMirrorList(c)
+								return nil
+							},
+						},
+						{
+							Name:    "remove",
+							Aliases: []string{"rm"},
+							Usage:   "Stop syncing a mirror with its project, leaving the mirror bound to its connection",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "the project id", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the mirror", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectMirrorRemove(c)
+								return nil
+							},
+						},
+					},
+				},
 				{
 					Name:    "list",
 					Aliases: []string{"ls"},
 					Usage:   "List projects",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use, or all", Required: false},
+						cli.StringFlag{Name: "language, l", Usage: "Only list projects in this language", Required: false},
+						cli.StringFlag{Name: "state, s", Usage: "Only list projects whose app status is this state, such as running, stopped or missing", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectList(c)
@@ -166,17 +271,236 @@ func Commands() {
 				},
 				{
 					Name:  "restart",
-					Usage: "Restart a single project, requires 'id' and 'startMode'",
+					Usage: "Restart a single project, requires 'id' and either 'startMode' or 'debug'",
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "id,i", Usage: "Project ID", Required: true},
-						cli.StringFlag{Name: "startmode, s", Usage: "Start Mode of the project; can be run, debug, or debugNoInit", Required: true},
+						cli.StringFlag{Name: "startmode, s", Usage: "Start Mode of the project; can be run, debug, or debugNoInit", Required: false},
 						cli.StringFlag{Name: "conid", Value: "local", Usage: "The connection id of the remote deployment to use", Required: false},
+						cli.BoolFlag{Name: "debug", Usage: "Restart in debug mode and print the address to attach a debugger to, forwarding a local port to the project pod for remote connections until interrupted", Required: false},
+						cli.IntFlag{Name: "local-port", Usage: "Local port to forward to the debug port of a remote project, a free port is chosen by default", Required: false},
+						cli.DurationFlag{Name: "timeout", Value: project.DefaultDebugTimeout, Usage: "How long to wait for the project to start in debug mode eg: 5m", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						ProjectRestart(c)
 						return nil
 					},
 				},
+				{
+					Name:      "rename",
+					Usage:     "Rename a project, relabelling its resources on remote connections",
+					ArgsUsage: "<projectID> <newName>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectRename(c)
+						return nil
+					},
+				},
+				{
+					Name:  "settings",
+					Usage: "Manage the .cw-settings of a project",
+					Subcommands: []cli.Command{
+						{
+							Name:      "set",
+							Usage:     "Update settings in the .cw-settings of a project and apply them in Codewind, using key=value, or key+=value and key-=value for lists",
+							ArgsUsage: "<projectID> <key=value>...",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+								cli.StringFlag{Name: "path, p", Usage: "Path to the project, the location on disk Codewind reports by default", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectSettingsSet(c)
+								return nil
+							},
+						},
+					},
+				},
+				{
+					Name:      "port-forward",
+					Usage:     "Reach the application of a project on localhost, forwarding a local port to the pod of a remote project until interrupted",
+					ArgsUsage: "<projectID>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID, if not given as an argument", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+						cli.IntFlag{Name: "local-port", Usage: "Local port to forward to the application port of a remote project, a free port is chosen by default", Required: false},
+						cli.DurationFlag{Name: "timeout", Value: project.DefaultDebugTimeout, Usage: "How long to wait for the pod of the project to be running eg: 5m", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectPortForward(c)
+						return nil
+					},
+				},
+				{
+					Name:      "logs",
+					Usage:     "Print the build and app logs of a project",
+					ArgsUsage: "<projectID>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID, if not given as an argument", Required: false},
+						cli.StringFlag{Name: "type, t", Usage: "Only print the logs of this type; can be app or build", Required: false},
+						cli.BoolFlag{Name: "follow, f", Usage: "Keep printing the output added to the logs until interrupted", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectLogs(c)
+						return nil
+					},
+				},
+				{
+					Name:      "build",
+					Usage:     "Build a project now, optionally waiting for the build and printing its output",
+					ArgsUsage: "<projectID>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i", Usage: "Project ID, if not given as an argument", Required: false},
+						cli.BoolFlag{Name: "clean", Usage: "Rebuild the project image without the cache of earlier builds", Required: false},
+						cli.BoolFlag{Name: "wait", Usage: "Wait for the build to finish, printing its output, and fail if the build fails", Required: false},
+						cli.DurationFlag{Name: "timeout", Value: project.DefaultBuildTimeout, Usage: "How long to wait for the build when --wait is set eg: 10m", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						ProjectBuild(c)
+						return nil
+					},
+				},
+				{
+					Name:  "loadtest",
+					Usage: "Run load tests against a project and download the performance results",
+					Subcommands: []cli.Command{
+						{
+							Name:  "run",
+							Usage: "Start a load run using the load test configuration of the project",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+								cli.StringFlag{Name: "description, d", Usage: "Description of the load run", Required: false},
+								cli.BoolFlag{Name: "wait, w", Usage: "Wait for the load run to finish, failing if it does not complete", Required: false},
+								cli.DurationFlag{Name: "timeout", Value: project.DefaultLoadTestTimeout, Usage: "How long to wait for the load run when --wait is set eg: 10m", Required: false},
+								cli.StringFlag{Name: "output, o", Usage: "Directory to download the results to once the load run waited for completes", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLoadTestRun(c)
+								return nil
+							},
+						},
+						{
+							Name:  "status",
+							Usage: "Show the state of the load runner of a project",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLoadTestStatus(c)
+								return nil
+							},
+						},
+						{
+							Name:  "cancel",
+							Usage: "Cancel the load run of a project",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLoadTestCancel(c)
+								return nil
+							},
+						},
+						{
+							Name:  "download",
+							Usage: "Download the metrics of the load runs of a project, and the comparison of the last two runs, as JSON",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+								cli.StringFlag{Name: "output, o", Value: ".", Usage: "Directory to download the results to", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectLoadTestDownload(c)
+								return nil
+							},
+						},
+						{
+							Name:  "schedule",
+							Usage: "Start load runs of a project on a cron schedule",
+							Subcommands: []cli.Command{
+								{
+									Name:  "create",
+									Usage: "Schedule load runs, with a CronJob in the workspace for remote connections or the daemon for the local connection",
+									Flags: []cli.Flag{
+										cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+										cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+										cli.StringFlag{Name: "name, n", Usage: "Name of the schedule, lower case alphanumeric characters or '-'", Required: true},
+										cli.StringFlag{Name: "schedule, s", Usage: "Cron schedule of the load runs eg: \"0 2 * * *\" or @daily", Required: true},
+										cli.StringFlag{Name: "description, d", Usage: "Description of each load run", Required: false},
+										cli.StringFlag{Name: "path", Usage: "Path of the project the load runs send requests to, overriding the load test configuration", Required: false},
+										cli.IntFlag{Name: "rps", Usage: "Requests per second of the load runs, overriding the load test configuration", Required: false},
+										cli.IntFlag{Name: "concurrency", Usage: "Concurrent requests of the load runs, overriding the load test configuration", Required: false},
+										cli.IntFlag{Name: "max-seconds", Usage: "Length of the load runs in seconds, overriding the load test configuration", Required: false},
+										cli.StringFlag{Name: "output, o", Usage: "Directory the results of the load runs are archived in, a directory in the Codewind directory if not given", Required: false},
+									},
+									Action: func(c *cli.Context) error {
+										ProjectLoadTestScheduleCreate(c)
+										return nil
+									},
+								},
+								{
+									Name:    "list",
+									Aliases: []string{"ls"},
+									Usage:   "List the load test schedules",
+									Action: func(c *cli.Context) error {
+										ProjectLoadTestScheduleList(c)
+										return nil
+									},
+								},
+								{
+									Name:    "remove",
+									Aliases: []string{"rm"},
+									Usage:   "Remove a load test schedule, and its CronJob for remote connections",
+									Flags: []cli.Flag{
+										cli.StringFlag{Name: "name, n", Usage: "Name of the schedule", Required: true},
+									},
+									Action: func(c *cli.Context) error {
+										ProjectLoadTestScheduleRemove(c)
+										return nil
+									},
+								},
+							},
+						},
+					},
+				},
+				{
+					Name:  "profiling",
+					Usage: "List the load runs of a project and download the profiling data collected during them",
+					Subcommands: []cli.Command{
+						{
+							Name:    "list",
+							Aliases: []string{"ls"},
+							Usage:   "List the load runs of a project, identified by the time they started",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectProfilingList(c)
+								return nil
+							},
+						},
+						{
+							Name:  "download",
+							Usage: "Download the raw profiling data of a load run, .hcd for Java and JSON for Node.js projects",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "id, i", Usage: "Project ID", Required: true},
+								cli.StringFlag{Name: "conid", Usage: "The connection id of the project, found from the project ID if not given", Required: false},
+								cli.Int64Flag{Name: "run, r", Usage: "The run to download, as listed by profiling list, the latest run if not given", Required: false},
+								cli.StringFlag{Name: "output, o", Value: ".", Usage: "Directory to download the profiling data to", Required: false},
+							},
+							Action: func(c *cli.Context) error {
+								ProjectProfilingDownload(c)
+								return nil
+							},
+						},
+					},
+				},
 				{
 					Name:  "link",
 					Usage: "Manage project links",
@@ -242,10 +566,22 @@ func Commands() {
 			Aliases: []string{"in"},
 			Usage:   "Pull pfe and performance images from dockerhub",
 			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "channel",
+					Usage: "release channel to use the images of: stable or latest (default: latest)",
+				},
 				cli.StringFlag{
 					Name:  "tag, t",
-					Value: "latest",
-					Usage: "dockerhub image tag",
+					Usage: "dockerhub image tag to pin, instead of a channel",
+				},
+				cli.StringFlag{
+					Name:   "registry-mirror",
+					Usage:  "registry mirror of dockerhub to pull the images from, eg: mirror.example.com:5000",
+					EnvVar: "CW_REGISTRY_MIRROR",
+				},
+				cli.StringFlag{
+					Name:  "from-archive",
+					Usage: "install the images from an archive created by image-bundle, instead of pulling them from dockerhub",
 				},
 			},
 			Action: func(c *cli.Context) error {
@@ -258,20 +594,7 @@ func Commands() {
 					Name:    "remote",
 					Aliases: []string{"r"},
 					Usage:   "Install a remote deployment of Codewind",
-					Flags: []cli.Flag{
-						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
-						cli.StringFlag{Name: "session,ses", Usage: "Codewind session secret", Required: false},
-						cli.StringFlag{Name: "ingress,i", Usage: "Ingress Domain eg: 10.22.33.44.nip.io", Required: false},
-						cli.StringFlag{Name: "kadminuser,au", Usage: "Keycloak admin user", Required: false},
-						cli.StringFlag{Name: "kadminpass,ap", Usage: "Keycloak admin password", Required: false},
-						cli.StringFlag{Name: "kdevuser,du", Usage: "Keycloak developer username to add", Required: false},
-						cli.StringFlag{Name: "kdevpass,dp", Usage: "Keycloak developer username initial password", Required: false},
-						cli.StringFlag{Name: "krealm,r", Usage: "Keycloak realm to setup", Required: false},
-						cli.StringFlag{Name: "kclient,c", Usage: "Keycloak client to setup", Required: false},
-						cli.IntFlag{Name: "pvcsize,p", Usage: "Codewind PVC size (integer between 1 and 999 Gigabytes)", Required: false, Value: 1},
-						cli.StringFlag{Name: "kurl", Usage: "Don't deploy a new Keycloak pod, use this existing one instead", Required: false},
-						cli.BoolFlag{Name: "konly", Usage: "Install a deployment of Keycloak only", Required: false},
-					},
+					Flags:   remoteInstallFlags,
 					Action: func(c *cli.Context) error {
 						DoRemoteInstall(c)
 						return nil
@@ -280,19 +603,69 @@ func Commands() {
 			},
 		},
 
+		{
+			Name:  "image-bundle",
+			Usage: "Pull the pfe and performance images and save them to an archive for install --from-archive",
+			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "channel",
+					Usage: "release channel to use the images of: stable or latest (default: latest)",
+				},
+				cli.StringFlag{
+					Name:  "tag, t",
+					Usage: "dockerhub image tag to pin, instead of a channel",
+				},
+				cli.StringFlag{
+					Name:   "registry-mirror",
+					Usage:  "registry mirror of dockerhub to pull the images from, eg: mirror.example.com:5000",
+					EnvVar: "CW_REGISTRY_MIRROR",
+				},
+				cli.StringFlag{
+					Name:  "output, o",
+					Value: docker.DefaultImageBundle,
+					Usage: "the archive to save the images to",
+				},
+			},
+			Action: func(c *cli.Context) error {
+				ImageBundleCommand(c)
+				return nil
+			},
+		},
+
 		{
 			Name:  "start",
 			Usage: "Start the Codewind containers",
 			Flags: []cli.Flag{
+				cli.StringFlag{
+					Name:  "channel",
+					Usage: "release channel to use the images of: stable or latest (default: latest)",
+				},
 				cli.StringFlag{
 					Name:  "tag, t",
-					Value: "latest",
-					Usage: "dockerhub image tag",
+					Usage: "dockerhub image tag to pin, instead of a channel",
+				},
+				cli.StringFlag{
+					Name:   "registry-mirror",
+					Usage:  "registry mirror of dockerhub to pull the images from, eg: mirror.example.com:5000",
+					EnvVar: "CW_REGISTRY_MIRROR",
 				},
 				cli.BoolFlag{
 					Name:  "debug, d",
 					Usage: "add debug output",
 				},
+				cli.IntFlag{
+					Name:  "pfe-port",
+					Usage: "host port to publish PFE on, instead of the port it was last started on or the first free port from 10000",
+				},
+				cli.BoolFlag{
+					Name:   "tls",
+					Usage:  "serve PFE and performance over HTTPS with a self-signed certificate generated in ~/.codewind/tls",
+					EnvVar: "CW_TLS",
+				},
+				cli.BoolFlag{
+					Name:  "trust-cert",
+					Usage: "add the generated certificate to the trusted certificates of the operating system, with --tls",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				StartCommand(c, dockerComposeFile, healthEndpoint)
@@ -308,6 +681,10 @@ func Commands() {
 					Name:  "conid",
 					Usage: "ConnectionID to check",
 				},
+				cli.BoolFlag{
+					Name:  "deep",
+					Usage: "check the health of each local Codewind container, exiting with an error if any is unhealthy",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				StatusCommand(c)
@@ -348,6 +725,10 @@ func Commands() {
 					Usage:   "Removes and deletes a Codewind local deployment",
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "tag, t", Usage: "dockerhub image tag"},
+						cli.StringSliceFlag{Name: "images", Usage: "other tags of the pulled Codewind images to remove, or all"},
+						cli.BoolFlag{Name: "volumes", Usage: "also delete the codewind-workspace volume"},
+						cli.BoolFlag{Name: "purge", Usage: "also delete ~/.codewind, including connections, ports and certificates"},
+						cli.BoolFlag{Name: "dry-run", Usage: "list everything that would be removed, without removing it"},
 					},
 
 					Action: func(c *cli.Context) error {
@@ -361,7 +742,8 @@ func Commands() {
 					Usage:   "Removes and deletes a Codewind remote deployment from Kubernetes",
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
-						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID, required unless --install-id is set", Required: false},
+						cli.StringFlag{Name: "install-id", Usage: "Remove the resources labelled with this install ID, instead of those of a workspace", Required: false},
 					},
 					Action: func(c *cli.Context) error {
 						DoRemoteRemove(c)
@@ -375,6 +757,7 @@ func Commands() {
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
 						cli.StringFlag{Name: "workspace,w", Usage: "Keycloak workspace ID", Required: true},
+						cli.BoolFlag{Name: "force,f", Usage: "Remove Keycloak even if other Codewind workspaces still use it"},
 					},
 					Action: func(c *cli.Context) error {
 						DoRemoteKeycloakRemove(c)
@@ -413,6 +796,39 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:      "search",
+					Usage:     "Search the templates of enabled repos for keywords",
+					ArgsUsage: "[keyword...]",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "language, l", Usage: "Only show templates for this language, such as java or nodejs"},
+						cli.StringFlag{Name: "style, s", Usage: "Only show templates of this project style, such as Codewind or Appsody"},
+						cli.StringFlag{Name: "conid", Value: "all", Usage: "Connection ID to search, or all to search every connection"},
+					},
+					Action: func(c *cli.Context) error {
+						SearchTemplates(c)
+						return nil
+					},
+				},
+				{
+					Name:      "create-from",
+					Usage:     "Create a template repository from an existing project",
+					ArgsUsage: "<projectPath>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "path, p", Usage: "Path to the project, if not given as an argument"},
+						cli.StringFlag{Name: "url", Usage: "URL the template repository will be published at", Required: true},
+						cli.StringFlag{Name: "output, o", Value: ".", Usage: "Directory to write the index.json and template archive to"},
+						cli.StringFlag{Name: "name", Usage: "Name of the template (default: the project directory name)"},
+						cli.StringFlag{Name: "description", Usage: "Description of the template"},
+						cli.StringFlag{Name: "language", Usage: "Language of the template (default: detected from the project)"},
+						cli.StringFlag{Name: "type", Usage: "Project type of the template (default: detected from the project)"},
+						cli.StringFlag{Name: "style", Usage: "Project style of the template, such as Appsody (default: Codewind)"},
+					},
+					Action: func(c *cli.Context) error {
+						CreateTemplateFromProject(c)
+						return nil
+					},
+				},
 				{
 					Name:  "styles",
 					Usage: "List available template styles",
@@ -441,7 +857,7 @@ func Commands() {
 								cli.StringFlag{
 									Name:     "conid",
 									Value:    "local",
-									Usage:    "Connection ID",
+									Usage:    "Connection ID, or all",
 									Required: false,
 								},
 							},
@@ -476,17 +892,17 @@ func Commands() {
 								},
 								cli.StringFlag{
 									Name:     "username",
-									Usage:    "GitHub username",
+									Usage:    "Username for a private GitHub, GitHub Enterprise or Git server repo (default: $CW_GIT_USERNAME)",
 									Required: false,
 								},
 								cli.StringFlag{
 									Name:     "password",
-									Usage:    "GitHub password",
+									Usage:    "Password for the username (default: $CW_GIT_PASSWORD)",
 									Required: false,
 								},
 								cli.StringFlag{
 									Name:     "personalAccessToken",
-									Usage:    "GitHub personal access token",
+									Usage:    "Personal access token for a private GitHub, GitHub Enterprise or Git server repo (default: $CW_GIT_TOKEN)",
 									Required: false,
 								},
 							},
@@ -495,6 +911,23 @@ func Commands() {
 								return nil
 							},
 						},
+						{
+							Name:  "mirror",
+							Usage: "Copy a template repo and its templates to a directory or HTTP endpoint",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "url", Usage: "URL of the index.json of the template repo to mirror", Required: true},
+								cli.StringFlag{Name: "output, o", Usage: "Directory to write the mirror to"},
+								cli.StringFlag{Name: "upload", Usage: "URL to PUT the mirror files to, with any credentials in the URL"},
+								cli.StringFlag{Name: "base-url", Usage: "URL the mirror will be served from (default: the upload URL)"},
+								cli.StringFlag{Name: "username", Usage: "Username for a private template repo (default: $CW_GIT_USERNAME)"},
+								cli.StringFlag{Name: "password", Usage: "Password for the username (default: $CW_GIT_PASSWORD)"},
+								cli.StringFlag{Name: "personalAccessToken", Usage: "Personal access token for a private template repo (default: $CW_GIT_TOKEN)"},
+							},
+							Action: func(c *cli.Context) error {
+								MirrorTemplateRepo(c)
+								return nil
+							},
+						},
 						{
 							Name:    "remove",
 							Aliases: []string{"rm"},
@@ -554,6 +987,35 @@ func Commands() {
 		},
 
 		//  Security //
+		{
+			Name:    "seclogin",
+			Aliases: []string{"login"},
+			Usage:   "Log in to a connection and cache its tokens",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: true},
+				cli.StringFlag{Name: "username,u", Usage: "Account Username (default: the connection username)"},
+				cli.StringFlag{Name: "password,p", Usage: "Account Password (default: the password in the keyring)"},
+				cli.BoolFlag{Name: "device-flow", Usage: "Print a URL and code to approve the login in a browser, instead of using a password"},
+				cli.BoolFlag{Name: "browser", Usage: "Log in using a browser on this machine, instead of using a password"},
+			},
+			Action: func(c *cli.Context) error {
+				SecurityLogin(c)
+				return nil
+			},
+		},
+		{
+			Name:    "seclogout",
+			Aliases: []string{"logout"},
+			Usage:   "Log out of a connection, revoking its session and removing its cached tokens",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "conid", Usage: "Connection ID", Required: true},
+				cli.BoolFlag{Name: "forget-password", Usage: "Also remove the password stored in the keyring for the connection user"},
+			},
+			Action: func(c *cli.Context) error {
+				SecurityLogout(c)
+				return nil
+			},
+		},
 		{
 			Name:    "sectoken",
 			Aliases: []string{"st"},
@@ -641,6 +1103,36 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:    "export",
+					Aliases: []string{"e"},
+					Usage:   "Export a realm with its clients, roles and users as JSON (requires admin_token)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: true},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: true},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: true},
+						cli.StringFlag{Name: "file,f", Usage: "File to write the export to", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityExportRealm(c)
+						return nil
+					},
+				},
+				{
+					Name:    "import",
+					Aliases: []string{"i"},
+					Usage:   "Create a realm from an export (requires admin_token)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: true},
+						cli.StringFlag{Name: "file,f", Usage: "Realm export file to import", Required: true},
+						cli.StringFlag{Name: "newrealm,r", Usage: "Name for the imported realm", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityImportRealm(c)
+						return nil
+					},
+				},
 			},
 		},
 		{
@@ -663,28 +1155,144 @@ func Commands() {
 						return nil
 					},
 				},
-			},
-		},
-		{
-			Name:    "secclient",
-			Aliases: []string{"sc"},
-			Usage:   "Manage client access configuration",
-			Subcommands: []cli.Command{
 				{
-					Name:    "create",
-					Aliases: []string{"c"},
-					Usage:   "Create a new client in a Keycloak realm (requires either admin_token or username/password)",
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the roles of a realm (requires either admin_token or username/password)",
 					Flags: []cli.Flag{
-						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: true},
-						cli.StringFlag{Name: "realm,r", Usage: "Realm where client should be created", Required: true},
-						cli.StringFlag{Name: "newclient,c", Usage: "New client ID to create", Required: true},
-						cli.StringFlag{Name: "redirect,l", Usage: "Redirect URL", Required: false},
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
 						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
 						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
 					},
 					Action: func(c *cli.Context) error {
-						SecurityClientCreate(c)
+						SecurityListRoles(c)
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:    "secgroup",
+			Aliases: []string{"sg"},
+			Usage:   "Manage access groups",
+			Subcommands: []cli.Command{
+				{
+					Name:    "create",
+					Aliases: []string{"c"},
+					Usage:   "Create a new group in an existing realm (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+						cli.StringFlag{Name: "group,g", Usage: "Group name", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityCreateGroup(c)
+						return nil
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the groups of a realm (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityListGroups(c)
+						return nil
+					},
+				},
+				{
+					Name:    "adduser",
+					Aliases: []string{"au"},
+					Usage:   "Add an existing user to a group (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+						cli.StringFlag{Name: "group,g", Usage: "Group name", Required: true},
+						cli.StringFlag{Name: "name,n", Usage: "Existing user account name to process", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityGroupAddUser(c)
+						return nil
+					},
+				},
+				{
+					Name:    "removeuser",
+					Aliases: []string{"ru"},
+					Usage:   "Remove a user from a group (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+						cli.StringFlag{Name: "group,g", Usage: "Group name", Required: true},
+						cli.StringFlag{Name: "name,n", Usage: "Existing user account name to process", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityGroupRemoveUser(c)
+						return nil
+					},
+				},
+				{
+					Name:    "addrole",
+					Aliases: []string{"ar"},
+					Usage:   "Grant an existing role to every member of a group (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+						cli.StringFlag{Name: "group,g", Usage: "Group name", Required: true},
+						cli.StringFlag{Name: "role,l", Usage: "Existing role name to grant to the group", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityGroupAddRole(c)
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:    "secclient",
+			Aliases: []string{"sc"},
+			Usage:   "Manage client access configuration",
+			Subcommands: []cli.Command{
+				{
+					Name:    "create",
+					Aliases: []string{"c"},
+					Usage:   "Create a new client in a Keycloak realm (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: true},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm where client should be created", Required: true},
+						cli.StringFlag{Name: "newclient,c", Usage: "New client ID to create", Required: true},
+						cli.StringFlag{Name: "redirect,l", Usage: "Redirect URL", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityClientCreate(c)
 						return nil
 					},
 				},
@@ -731,11 +1339,12 @@ func Commands() {
 			Subcommands: []cli.Command{
 				{
 					Name:    "create",
-					Aliases: []string{"c"},
+					Aliases: []string{"c", "add"},
 					Usage:   "Create a new user (requires either admin_token or username/password)",
 					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
 						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
-						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: true},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
 						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
 						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
@@ -750,8 +1359,9 @@ func Commands() {
 					Aliases: []string{"g"},
 					Usage:   "Get details of a user (requires either admin_token or username/password)",
 					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
 						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
-						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: true},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
 						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
 						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
@@ -763,11 +1373,12 @@ func Commands() {
 					},
 				}, {
 					Name:    "setpw",
-					Aliases: []string{"p"},
+					Aliases: []string{"p", "set-password"},
 					Usage:   "Sets the password of an existing user (requires either admin_token or username/password)",
 					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
 						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
-						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: true},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
 						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin Access Token", Required: false},
 						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
 						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
@@ -778,6 +1389,39 @@ func Commands() {
 						SecurityUserSetPassword(c)
 						return nil
 					},
+				}, {
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the users of a realm (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityUserList(c)
+						return nil
+					},
+				}, {
+					Name:    "remove",
+					Aliases: []string{"rm"},
+					Usage:   "Remove a user from a realm (requires either admin_token or username/password)",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Usage: "Connection ID, whose Keycloak host and realm are used", Required: false},
+						cli.StringFlag{Name: "host", Usage: "URL or ingress to Keycloak service", Required: false},
+						cli.StringFlag{Name: "realm,r", Usage: "Realm name", Required: false},
+						cli.StringFlag{Name: "accesstoken,t", Usage: "Admin access_token", Required: false},
+						cli.StringFlag{Name: "username,u", Usage: "Admin Username", Required: false},
+						cli.StringFlag{Name: "password,p", Usage: "Admin Password", Required: false},
+						cli.StringFlag{Name: "name,n", Usage: "Username to remove", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						SecurityUserRemove(c)
+						return nil
+					},
 				}, {
 					Name:  "addrole",
 					Usage: "Adds an existing role to an existing user (requires admin_token)",
@@ -809,6 +1453,18 @@ func Commands() {
 						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
 						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
 						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "proxy", Usage: "HTTP or HTTPS proxy URL to reach the gatekeeper through, instead of HTTP_PROXY and HTTPS_PROXY"},
+						cli.StringFlag{Name: "noproxy", Usage: "Comma separated hosts to reach directly, in addition to NO_PROXY"},
+						cli.StringFlag{Name: "cacert", Usage: "PEM bundle of certificate authorities to trust, in addition to the system ones, for this connection"},
+						cli.BoolFlag{Name: "insecure-skip-tls-verify", Usage: "Do not verify the certificates presented by this connection"},
+						cli.StringFlag{Name: "clientcert", Usage: "PEM client certificate to present when the gatekeeper requires mutual TLS"},
+						cli.StringFlag{Name: "clientkey", Usage: "PEM private key of the client certificate"},
+						cli.StringFlag{Name: "oidc-issuer", Usage: "Issuer URL of an OpenID Connect provider, such as Okta, Azure AD or Dex, to log in with instead of Keycloak"},
+						cli.StringFlag{Name: "oidc-client", Usage: "Client ID registered with the OpenID Connect provider (default: the client ID reported by the gatekeeper)"},
+						cli.IntFlag{Name: "retries", Usage: "Times to retry a request failing with a transient error, 0 to never retry (default: 3)"},
+						cli.StringFlag{Name: "retry-backoff", Usage: "Delay before the first retry, doubled for each later one (default: 500ms)"},
+						cli.StringFlag{Name: "connect-timeout", Usage: "How long to wait for a network connection to the gatekeeper (default: 30s)"},
+						cli.StringFlag{Name: "read-timeout", Usage: "How long a request can go without reading or writing any data before failing (default: 2m)"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionAddToList(c)
@@ -821,9 +1477,22 @@ func Commands() {
 					Usage:   "Update an existing connection",
 					Flags: []cli.Flag{
 						cli.StringFlag{Name: "conid", Usage: "Connection ID to update", Required: true},
-						cli.StringFlag{Name: "label", Usage: "A displayable name", Required: true},
-						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper", Required: true},
-						cli.StringFlag{Name: "username,u", Usage: "Username", Required: true},
+						cli.StringFlag{Name: "label", Usage: "A displayable name (default: unchanged)"},
+						cli.StringFlag{Name: "url", Usage: "The ingress URL of Codewind gatekeeper (default: unchanged)"},
+						cli.StringFlag{Name: "username,u", Usage: "Username (default: unchanged)"},
+						cli.StringFlag{Name: "realm", Usage: "Authentication realm to use instead of the one reported by the gatekeeper"},
+						cli.StringFlag{Name: "proxy", Usage: "HTTP or HTTPS proxy URL to reach the gatekeeper through, empty to remove (default: unchanged)"},
+						cli.StringFlag{Name: "noproxy", Usage: "Comma separated hosts to reach directly, empty to remove (default: unchanged)"},
+						cli.StringFlag{Name: "cacert", Usage: "PEM bundle of certificate authorities to trust for this connection, empty to remove (default: unchanged)"},
+						cli.BoolFlag{Name: "insecure-skip-tls-verify", Usage: "Do not verify the certificates presented by this connection, =false to verify again (default: unchanged)"},
+						cli.StringFlag{Name: "clientcert", Usage: "PEM client certificate to present when the gatekeeper requires mutual TLS, empty to remove (default: unchanged)"},
+						cli.StringFlag{Name: "clientkey", Usage: "PEM private key of the client certificate, empty to remove (default: unchanged)"},
+						cli.StringFlag{Name: "oidc-issuer", Usage: "Issuer URL of an OpenID Connect provider to log in with, empty to use Keycloak again (default: unchanged)"},
+						cli.StringFlag{Name: "oidc-client", Usage: "Client ID registered with the OpenID Connect provider (default: unchanged)"},
+						cli.IntFlag{Name: "retries", Usage: "Times to retry a request failing with a transient error, 0 to never retry (default: unchanged)"},
+						cli.StringFlag{Name: "retry-backoff", Usage: "Delay before the first retry, doubled for each later one, empty for the default (default: unchanged)"},
+						cli.StringFlag{Name: "connect-timeout", Usage: "How long to wait for a network connection to the gatekeeper, empty for the default (default: unchanged)"},
+						cli.StringFlag{Name: "read-timeout", Usage: "How long a request can go without reading or writing any data before failing, empty for the default (default: unchanged)"},
 					},
 					Action: func(c *cli.Context) error {
 						ConnectionUpdate(c)
@@ -863,6 +1532,58 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:      "test",
+					Aliases:   []string{"t"},
+					Usage:     "Check DNS, TLS, the Gatekeeper and authentication for a connection",
+					ArgsUsage: "<conid>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "conid", Value: "local", Usage: "Connection ID to test, or all, if not given as an argument"},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionTest(c)
+						return nil
+					},
+				},
+				{
+					Name:  "discover",
+					Usage: "Find Codewind deployments in the current Kubernetes context and register them as connections",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "The namespace to search (default: all)"},
+						cli.BoolFlag{Name: "register", Usage: "Add a connection for each deployment that does not have one"},
+						cli.StringFlag{Name: "workspace", Usage: "Only register the deployment with this workspace ID"},
+						cli.StringFlag{Name: "username,u", Usage: "Username for the registered connections"},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionDiscover(c)
+						return nil
+					},
+				},
+				{
+					Name:  "export",
+					Usage: "Export connections so they can be imported on another machine",
+					Flags: []cli.Flag{
+						cli.StringSliceFlag{Name: "conid", Usage: "Connection ID to export, repeat for more than one (default: all remote connections)"},
+						cli.StringFlag{Name: "file,f", Usage: "File to write the export to (default: print to the terminal)"},
+						cli.StringFlag{Name: "passphrase", Usage: "Include passwords from the keychain, encrypted with this passphrase"},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionExport(c)
+						return nil
+					},
+				},
+				{
+					Name:  "import",
+					Usage: "Import connections from an export file",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "file,f", Usage: "Export file to import", Required: true},
+						cli.StringFlag{Name: "passphrase", Usage: "Passphrase used to encrypt passwords in the export"},
+					},
+					Action: func(c *cli.Context) error {
+						ConnectionImport(c)
+						return nil
+					},
+				},
 				{
 					Name:  "reset",
 					Usage: "Resets the connections list",
@@ -877,6 +1598,16 @@ func Commands() {
 			Name:  "remote",
 			Usage: "Manage remote connections",
 			Subcommands: []cli.Command{
+				{
+					Name:    "install",
+					Aliases: []string{"in"},
+					Usage:   "Install a remote deployment of Codewind",
+					Flags:   remoteInstallFlags,
+					Action: func(c *cli.Context) error {
+						DoRemoteInstall(c)
+						return nil
+					},
+				},
 				{
 					Name:  "list",
 					Usage: "List the remote installs",
@@ -888,6 +1619,156 @@ func Commands() {
 						return nil
 					},
 				},
+				{
+					Name:  "maintenance",
+					Usage: "Turn maintenance mode on or off for a remote install",
+					Subcommands: []cli.Command{
+						{
+							Name:  "on",
+							Usage: "Scale down project components so storage operations can be performed safely",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+								cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								RemoteMaintenance(c, true)
+								return nil
+							},
+						},
+						{
+							Name:  "off",
+							Usage: "Scale project components back up and leave maintenance mode",
+							Flags: []cli.Flag{
+								cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+								cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+							},
+							Action: func(c *cli.Context) error {
+								RemoteMaintenance(c, false)
+								return nil
+							},
+						},
+					},
+				},
+				{
+					Name:  "logs",
+					Usage: "Print the logs of a remote Codewind component",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "component,c", Usage: "Component to get logs for: pfe, performance, gatekeeper or keycloak", Value: "pfe"},
+						cli.BoolFlag{Name: "follow,f", Usage: "Stream the logs until interrupted"},
+						cli.StringFlag{Name: "since", Usage: "Only return logs newer than a relative duration eg: 10m, 1h"},
+						cli.Int64Flag{Name: "tail", Usage: "Number of lines to show from the end of the logs, -1 for all", Value: -1},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteLogs(c)
+						return nil
+					},
+				},
+				{
+					Name:  "audit",
+					Usage: "Report who used a remote Codewind and which APIs they called, from the access logs of its gatekeeper",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "since", Usage: "Start of the window, as a relative duration eg: 1h, or a time eg: 2020-06-01T09:00:00Z", Value: "24h"},
+						cli.StringFlag{Name: "until", Usage: "End of the window, as a relative duration or a time, now if not given"},
+						cli.StringFlag{Name: "user,u", Usage: "Only report the requests of this user"},
+						cli.BoolFlag{Name: "summary,s", Usage: "Only report the users and how many requests each made"},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteAudit(c)
+						return nil
+					},
+				},
+				{
+					Name:  "scale",
+					Usage: "Set the number of replicas of a remote Codewind component",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "component,c", Usage: "Component to scale: pfe, performance, gatekeeper or keycloak (default: all)"},
+						cli.IntFlag{Name: "replicas,r", Usage: "Number of replicas", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteScale(c, c.String("component"), c.Int("replicas"))
+						return nil
+					},
+				},
+				{
+					Name:  "stop",
+					Usage: "Pause a remote deployment by scaling all of its components to zero",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteScale(c, "", 0)
+						return nil
+					},
+				},
+				{
+					Name:  "start",
+					Usage: "Resume a paused remote deployment, scaling each component back to the replicas it was installed with",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteStart(c)
+						return nil
+					},
+				},
+				{
+					Name:  "rotate-secrets",
+					Usage: "Replace the Keycloak client and Gatekeeper session secrets of a remote deployment, restarting the components using them",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.BoolFlag{Name: "admin-password", Usage: "Also replace the Keycloak admin password, when Keycloak is in the workspace"},
+						cli.StringFlag{Name: "kadminuser,au", Usage: "Keycloak admin user, when Keycloak is not in the workspace"},
+						cli.StringFlag{Name: "kadminpass,ap", Usage: "Keycloak admin password, when Keycloak is not in the workspace"},
+						cli.DurationFlag{Name: "timeout", Usage: "How long to wait for each restarted deployment eg: 5m", Value: remote.DefaultReadyTimeout},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteRotateSecrets(c)
+						return nil
+					},
+				},
+				{
+					Name:  "backup",
+					Usage: "Save the contents of a remote workspace PVC to a local tarball",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "file,f", Usage: "Local file to write the backup to", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteBackup(c, false)
+						return nil
+					},
+				},
+				{
+					Name:  "restore",
+					Usage: "Load a local tarball created by backup into a remote workspace PVC",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace", Required: true},
+						cli.StringFlag{Name: "workspace,w", Usage: "Codewind workspace ID", Required: true},
+						cli.StringFlag{Name: "file,f", Usage: "Local file to read the backup from", Required: true},
+					},
+					Action: func(c *cli.Context) error {
+						RemoteBackup(c, true)
+						return nil
+					},
+				},
+			},
+		},
+		{
+			Name:  "overview",
+			Usage: "Show the health and bound projects of every connection",
+			Action: func(c *cli.Context) error {
+				Overview(c)
+				return nil
 			},
 		},
 		{
@@ -902,6 +1783,19 @@ func Commands() {
 				return nil
 			},
 		},
+		{
+			Name:  "upgrade-workspace",
+			Usage: "Migrate the projects of a workspace created by an older version of Codewind, binding them again where they are",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "workspace, ws", Usage: "the workspace directory to migrate, ~/codewind-workspace by default"},
+				cli.StringFlag{Name: "conid", Value: "local", Usage: "the connection to bind the projects to"},
+				cli.BoolFlag{Name: "dry-run", Usage: "report the projects and settings that would be migrated, without changing anything"},
+			},
+			Action: func(c *cli.Context) error {
+				UpgradeWorkspace(c)
+				return nil
+			},
+		},
 		{
 			Name:    "loglevels",
 			Aliases: []string{"log"},
@@ -1011,45 +1905,268 @@ func Commands() {
 				},
 			},
 		},
+
+		{
+			Name:  "errors",
+			Usage: "List the operation codes commands fail with, with their numeric codes, categories and exit codes",
+			Action: func(c *cli.Context) error {
+				ErrorCodes(c)
+				return nil
+			},
+		},
+
+		{
+			Name:  "plugins",
+			Usage: "Manage the commands added by plugins",
+			Subcommands: []cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the plugins found in ~/.codewind/plugins and the executables named cwctl-<name> on the PATH",
+					Action: func(c *cli.Context) error {
+						PluginList(c)
+						return nil
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "doctor",
+			Usage: "Check Docker, Compose, disk space, ports, the kubectl context and each connection, with hints on fixing problems",
+			Action: func(c *cli.Context) error {
+				Doctor(c)
+				return nil
+			},
+		},
+
+		{
+			Name:  "events",
+			Usage: "Print the project status, build and log events sent by Codewind as JSON lines",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "conid", Usage: "The connection id to read events from, local by default, or the connection of the project", Required: false},
+				cli.StringFlag{Name: "project, p", Usage: "Only print the events of this project ID", Required: false},
+				cli.BoolFlag{Name: "follow, f", Usage: "Keep printing events until interrupted, rather than exiting after the first one", Required: false},
+			},
+			Action: func(c *cli.Context) error {
+				Events(c)
+				return nil
+			},
+		},
+
+		{
+			Name:  "daemon",
+			Usage: "Serve the project, connection and remote operations over JSON-RPC on a local socket until interrupted, keeping connections and tokens between requests",
+			Flags: []cli.Flag{
+				cli.StringFlag{Name: "socket", Usage: "The path of the Unix socket to listen on, ~/.codewind/cwctl.sock by default", Required: false},
+			},
+			Action: func(c *cli.Context) error {
+				Daemon(c)
+				return nil
+			},
+		},
+
+		{
+			Name:      "completion",
+			Usage:     "Print a script completing commands, flags, connection IDs and project IDs in a shell",
+			ArgsUsage: "bash|zsh|fish|powershell",
+			Action: func(c *cli.Context) error {
+				CompletionScript(c)
+				return nil
+			},
+		},
+		{
+			Name:            completeCommand,
+			Hidden:          true,
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				Complete(c)
+				return nil
+			},
+		},
+
+		{
+			Name:  "telemetry",
+			Usage: "Turn anonymous usage metrics on or off",
+			Subcommands: []cli.Command{
+				{
+					Name:  "on",
+					Usage: "Send the name, duration and outcome of each command to an endpoint, in batches",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "endpoint", Usage: "URL the metrics are posted to, overridden by " + telemetry.EndpointEnvVar, Required: false},
+					},
+					Action: func(c *cli.Context) error {
+						TelemetryOn(c)
+						return nil
+					},
+				},
+				{
+					Name:  "off",
+					Usage: "Stop collecting usage metrics and discard those not yet sent",
+					Action: func(c *cli.Context) error {
+						TelemetryOff(c)
+						return nil
+					},
+				},
+				{
+					Name:  "status",
+					Usage: "Show whether usage metrics are collected and where they are sent",
+					Action: func(c *cli.Context) error {
+						TelemetryShowStatus(c)
+						return nil
+					},
+				},
+			},
+		},
+
+		{
+			Name:  "config",
+			Usage: "Manage the defaults of cwctl in ~/.codewind/" + config.CLIConfigFile + ", used when a flag and its environment variable are not set",
+			Subcommands: []cli.Command{
+				{
+					Name:      "set",
+					Usage:     "Set a default, or unset it with an empty value",
+					ArgsUsage: "<setting> <value>",
+					Action: func(c *cli.Context) error {
+						ConfigSet(c)
+						return nil
+					},
+				},
+				{
+					Name:      "get",
+					Usage:     "Print a default, empty if it is not set",
+					ArgsUsage: "<setting>",
+					Action: func(c *cli.Context) error {
+						ConfigGet(c)
+						return nil
+					},
+				},
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the defaults: " + strings.Join(config.CLIConfigKeys, ", "),
+					Action: func(c *cli.Context) error {
+						ConfigList(c)
+						return nil
+					},
+				},
+			},
+		},
 	}
+	recordCommands(app.Commands, "")
+	// Plugins are added after recording, so that the names of organization-specific commands stay private
+	app.Commands = append(app.Commands, pluginCommands(app.Commands, app.Flags, os.Args)...)
+	userConfig := loadCLIConfig()
+	applyCLIConfig(app, userConfig)
 
 	app.Before = func(c *cli.Context) error {
+		i18n.SetLocale(c.GlobalString("locale"))
+
 		// Handle Global flag to disable certificate checking
 		if c.GlobalBool("insecure") {
 			http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 		}
 
-		printAsJSON = c.GlobalBool("json")
+		printAsJSON = c.GlobalBool("json") || (userConfig.JSON && !c.GlobalIsSet("json"))
+		if printAsJSON {
+			useJSONOutput()
+			c.App.Writer = os.Stderr
+		}
 
+		err := globals.SetCredentialStore(c.GlobalString("credential-store"))
+		if err != nil {
+			return err
+		}
 		if c.GlobalBool("insecureKeyring") || os.Getenv("INSECURE_KEYRING") == "true" {
 			globals.SetUseInsecureKeyring(true)
 		}
 
-		// Handle Global log level flag
-		switch loglevel := c.GlobalString("loglevel"); {
-		case loglevel == "trace":
-			logr.SetLevel(logr.TraceLevel)
-			break
-		case loglevel == "debug":
-			logr.SetLevel(logr.DebugLevel)
-			break
-		case loglevel == "fatal":
-			logr.SetLevel(logr.FatalLevel)
-			break
-		case loglevel == "error":
-			logr.SetLevel(logr.ErrorLevel)
-			break
-		case loglevel == "warn":
-			logr.SetLevel(logr.WarnLevel)
-			break
-		default:
-			logr.SetLevel(logr.InfoLevel)
+		if c.GlobalBool("in-cluster") {
+			globals.SetUseInClusterConfig(true)
+		}
+
+		globals.SetNonInteractive(c.GlobalBool("non-interactive"))
+
+		globals.SetTraceHTTP(c.GlobalBool("trace-http"), c.GlobalBool("trace-http-headers"))
+
+		globals.SetRequestTimeout(c.GlobalDuration("timeout"))
+
+		// Handle Global log level, format and file flags
+		logErr := logging.Configure(c.GlobalString("loglevel"), c.GlobalString("log-format"), c.GlobalString("log-file"))
+		if logErr != nil {
+			return logErr
 		}
 
 		return nil
 	}
 
 	// Start application
+	// Errors returned by the app are invalid flags and arguments, as the commands exit themselves when they fail
 	err := app.Run(os.Args)
-	errors.CheckErr(err, 300, "")
+	if err != nil {
+		if printAsJSON {
+			printJSONError(err)
+		} else {
+			logr.Errorln(err)
+		}
+		exit(errors.ExitUsage)
+	}
+	finishOutput(0)
+}
+
+// remoteInstallFlags are shared by "install remote" and "remote install"
+var remoteInstallFlags = []cli.Flag{
+	cli.StringFlag{Name: "file,f", Usage: "YAML or JSON deployment config file to install from, instead of the other flags", Required: false},
+	cli.BoolFlag{Name: "interactive", Usage: "Ask for the namespace, ingress domain, Keycloak users, storage and resources not given as flags, then print the equivalent command", Required: false},
+	cli.StringFlag{Name: "namespace,n", Usage: "Kubernetes namespace, required unless --file is set", Required: false},
+	cli.StringSliceFlag{Name: "namespace-label", Usage: "Label key=value for the namespace when the install creates it, may be repeated", Required: false},
+	cli.StringSliceFlag{Name: "namespace-annotation", Usage: "Annotation key=value for the namespace when the install creates it, may be repeated", Required: false},
+	cli.StringFlag{Name: "session,ses", Usage: "Codewind session secret", Required: false},
+	cli.StringFlag{Name: "ingress,i", Usage: "Ingress Domain eg: 10.22.33.44.nip.io", Required: false},
+	cli.StringFlag{Name: "kadminuser,au", Usage: "Keycloak admin user", Required: false},
+	cli.StringFlag{Name: "kadminpass,ap", Usage: "Keycloak admin password", Required: false},
+	cli.StringFlag{Name: "kadmin-secret", Usage: "Existing secret holding the Keycloak admin credentials in keycloak-admin-user and keycloak-admin-password, instead of --kadminuser and --kadminpass", Required: false},
+	cli.StringFlag{Name: "kdevuser,du", Usage: "Keycloak developer username to add", Required: false},
+	cli.StringFlag{Name: "kdevpass,dp", Usage: "Keycloak developer username initial password", Required: false},
+	cli.StringFlag{Name: "krealm,r", Usage: "Keycloak realm to setup", Required: false},
+	cli.StringFlag{Name: "kclient,c", Usage: "Keycloak client to setup", Required: false},
+	cli.StringFlag{Name: "storageclass,storage-class", Usage: "Storage class for the Codewind and Keycloak PVCs, instead of the cluster default", Required: false},
+	cli.IntFlag{Name: "pvcsize,pvc-size,p", Usage: "Codewind PVC size (integer between 1 and 999 Gigabytes)", Required: false, Value: 1},
+	cli.StringFlag{Name: "kstorageclass", Usage: "Storage class for the Keycloak PVC, if different to --storage-class", Required: false},
+	cli.IntFlag{Name: "kpvcsize", Usage: "Keycloak PVC size (integer between 1 and 999 Gigabytes)", Required: false, Value: 1},
+	cli.StringFlag{Name: "kurl", Usage: "Don't deploy a new Keycloak pod, use this existing one instead", Required: false},
+	cli.BoolFlag{Name: "konly", Usage: "Install a deployment of Keycloak only", Required: false},
+	cli.StringFlag{Name: "pferesources", Usage: "PFE resource requests and limits eg: requests.cpu=500m,limits.memory=4Gi", Required: false},
+	cli.StringFlag{Name: "perfresources", Usage: "Performance dashboard resource requests and limits eg: requests.cpu=100m,limits.memory=512Mi", Required: false},
+	cli.StringFlag{Name: "gkresources", Usage: "Gatekeeper resource requests and limits eg: requests.cpu=100m,limits.memory=512Mi", Required: false},
+	cli.StringFlag{Name: "kresources", Usage: "Keycloak resource requests and limits eg: requests.cpu=250m,limits.memory=1Gi", Required: false},
+	cli.StringFlag{Name: "certissuer", Usage: "Provision TLS certificates with cert-manager using this issuer instead of self-signed certificates", Required: false},
+	cli.StringFlag{Name: "certissuerkind", Usage: "Kind of the cert-manager issuer: Issuer or ClusterIssuer", Required: false, Value: "Issuer"},
+	cli.StringFlag{Name: "gatekeeper-tls-secret", Usage: "Existing TLS secret for the Gatekeeper, instead of a self-signed or cert-manager certificate", Required: false},
+	cli.StringFlag{Name: "keycloak-tls-secret", Usage: "Existing TLS secret for Keycloak, instead of a self-signed or cert-manager certificate", Required: false},
+	cli.StringFlag{Name: "ingressclass", Usage: "Ingress class to use for the Gatekeeper and Keycloak ingresses", Required: false, Value: "nginx"},
+	cli.StringSliceFlag{Name: "ingressannotation", Usage: "Extra ingress annotation key=value, may be repeated", Required: false},
+	cli.StringFlag{Name: "gatekeeperhost", Usage: "Hostname for the Gatekeeper, instead of deriving one from the ingress domain", Required: false},
+	cli.StringFlag{Name: "keycloakhost", Usage: "Hostname for Keycloak, instead of deriving one from the ingress domain", Required: false},
+	cli.StringFlag{Name: "wildcard-domain", Usage: "Domain with a wildcard DNS record for the ingress eg: apps.example.com, used instead of --ingress and added to the generated certificates as *.<domain>", Required: false},
+	cli.BoolFlag{Name: "add-connection", Usage: "Add a connection to the installed Codewind for the --kdevuser user", Required: false},
+	cli.BoolFlag{Name: "networkpolicies", Usage: "Create network policies so only the Gatekeeper can reach PFE and Performance", Required: false},
+	cli.BoolFlag{Name: "metrics", Usage: "Annotate the PFE and Gatekeeper services for Prometheus scraping, creating ServiceMonitors when the Prometheus Operator is installed", Required: false},
+	cli.StringSliceFlag{Name: "nodeselector", Usage: "Node label key=value the Codewind pods must be scheduled on, may be repeated", Required: false},
+	cli.StringSliceFlag{Name: "toleration", Usage: "Taint the Codewind pods tolerate eg: dedicated=devtools:NoSchedule, may be repeated", Required: false},
+	cli.StringFlag{Name: "affinity", Usage: "YAML or JSON file containing the pod affinity for the Codewind pods", Required: false},
+	cli.IntFlag{Name: "perfreplicas", Usage: "Performance dashboard replicas, spread across nodes when more than 1", Required: false, Value: 1},
+	cli.IntFlag{Name: "gkreplicas", Usage: "Gatekeeper replicas, spread across nodes when more than 1", Required: false, Value: 1},
+	cli.StringFlag{Name: "pod-security", Usage: "Pod security profile for every Codewind pod: restricted", Required: false},
+	cli.StringFlag{Name: "pfesecurity", Usage: "PFE security context settings eg: runAsNonRoot=true,runAsUser=1001,fsGroup=1001,seccompProfile=RuntimeDefault,drop=ALL", Required: false},
+	cli.StringFlag{Name: "perfsecurity", Usage: "Performance dashboard security context settings", Required: false},
+	cli.StringFlag{Name: "gksecurity", Usage: "Gatekeeper security context settings", Required: false},
+	cli.StringFlag{Name: "ksecurity", Usage: "Keycloak security context settings", Required: false},
+	cli.BoolFlag{Name: "wait", Usage: "Wait for each deployment rollout to be ready, failing after the timeout", Required: false},
+	cli.DurationFlag{Name: "timeout", Usage: "How long to wait for each deployment when --wait is set eg: 5m", Required: false, Value: remote.DefaultReadyTimeout},
+	cli.StringFlag{Name: "channel", Usage: "Release channel to deploy the images of: stable or latest", Required: false},
+	cli.StringFlag{Name: "tag,t", Usage: "Image tag to pin every component to, instead of a channel", Required: false},
+	cli.BoolFlag{Name: "allow-mixed-versions", Usage: "Deploy components of different versions, such as a custom PFE image", Required: false},
+	cli.BoolFlag{Name: "preflight", Usage: "Only check the cluster can run the install, reporting each check without creating anything", Required: false},
+	cli.BoolFlag{Name: "skip-preflight", Usage: "Start the install without first checking the cluster version, permissions, storage, ingress and quotas", Required: false},
 }
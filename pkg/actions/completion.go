@@ -0,0 +1,221 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// completeCommand is the hidden command the completion scripts call with the words typed so far
+const completeCommand = "__complete"
+
+// The completion scripts pass the number of complete words, then the words, then the word being typed if it is not
+// empty, as PowerShell drops empty arguments
+var completionScripts = map[string]string{
+	"bash": `# bash completion for cwctl, load with: source <(cwctl completion bash)
+_cwctl_completions() {
+    local cur="${COMP_WORDS[COMP_CWORD]}"
+    local IFS=$'\n'
+    COMPREPLY=($(compgen -W "$(cwctl __complete $((COMP_CWORD - 1)) "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null)" -- "$cur"))
+}
+complete -o default -F _cwctl_completions cwctl
+`,
+	"zsh": `#compdef cwctl
+# zsh completion for cwctl, load with: source <(cwctl completion zsh)
+_cwctl() {
+    local -a candidates
+    candidates=("${(@f)$(cwctl __complete $((CURRENT - 2)) "${(@)words[2,CURRENT]}" 2>/dev/null)}")
+    compadd -- ${candidates:#}
+}
+compdef _cwctl cwctl
+`,
+	"fish": `# fish completion for cwctl, load with: cwctl completion fish | source
+function __cwctl_complete
+    set -l words (commandline -opc)[2..-1]
+    cwctl __complete (count $words) $words (commandline -ct) 2>/dev/null
+end
+complete -c cwctl -f -a '(__cwctl_complete)'
+`,
+	"powershell": `# PowerShell completion for cwctl, load with: cwctl completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName cwctl -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+    $words = @($commandAst.CommandElements | Select-Object -Skip 1 |
+        Where-Object { $_.Extent.EndOffset -le $cursorPosition } | ForEach-Object { $_.ToString() })
+    $complete = $words.Count
+    if ($wordToComplete -ne '') { $complete -= 1 }
+    cwctl __complete $complete @words 2>$null | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+`,
+}
+
+// use variables for the dynamic values so they can be mocked for unit testing
+var completionFunctions = struct {
+	ConnectionIDs func() []string
+	ProjectIDs    func() []string
+}{
+	ConnectionIDs: func() []string {
+		allConnections, conErr := connections.GetAllConnections()
+		if conErr != nil {
+			return nil
+		}
+		ids := []string{}
+		for _, connection := range allConnections {
+			ids = append(ids, connection.ID)
+		}
+		return ids
+	},
+	ProjectIDs: project.BoundProjectIDs,
+}
+
+// CompletionScript : Prints the completion script for a shell
+func CompletionScript(c *cli.Context) {
+	shell := strings.ToLower(c.Args().First())
+	script, ok := completionScripts[shell]
+	if !ok {
+		logr.Errorln("Must specify a shell: bash, zsh, fish or powershell")
+		exit(1)
+	}
+	fmt.Fprint(commandOutput(), script)
+}
+
+// Complete : Prints the completions of the word being typed, one per line, for the completion scripts
+func Complete(c *cli.Context) {
+	args := c.Args()
+	complete, err := strconv.Atoi(args.First())
+	if err != nil || complete < 0 || complete > len(args)-1 {
+		return
+	}
+	words := args[1 : complete+1]
+	partial := ""
+	if len(args) > complete+1 {
+		partial = args[complete+1]
+	}
+	for _, candidate := range completions(c.App, words, partial) {
+		fmt.Fprintln(commandOutput(), candidate)
+	}
+}
+
+// completions returns the subcommands, flags or values that can follow the words typed so far and start with the
+// partial word. Connection IDs complete --conid and project IDs complete --id and <projectID> arguments.
+func completions(app *cli.App, words []string, partial string) []string {
+	commands, flags := app.Commands, app.Flags
+	var command *cli.Command
+	valueOf := ""
+	arguments := 0
+	for _, word := range words {
+		if valueOf != "" {
+			valueOf = ""
+			continue
+		}
+		if strings.HasPrefix(word, "-") {
+			name := strings.TrimLeft(word, "-")
+			if flag, ok := findFlag(flags, name); ok && !strings.Contains(name, "=") && flagTakesValue(flag) {
+				valueOf = flagNames(flag)[0]
+			}
+			continue
+		}
+		if subcommand := findCommand(commands, word); subcommand != nil {
+			command, commands, flags = subcommand, subcommand.Subcommands, subcommand.Flags
+			continue
+		}
+		arguments++
+	}
+
+	var candidates []string
+	switch {
+	case valueOf == "conid":
+		candidates = completionFunctions.ConnectionIDs()
+	case valueOf == "id":
+		candidates = completionFunctions.ProjectIDs()
+	case valueOf != "":
+		return nil
+	case strings.HasPrefix(partial, "-"):
+		for _, flag := range flags {
+			for _, name := range flagNames(flag) {
+				if len(name) > 1 {
+					candidates = append(candidates, "--"+name)
+				}
+			}
+		}
+	case len(commands) > 0:
+		for _, subcommand := range commands {
+			if subcommand.Hidden {
+				continue
+			}
+			for _, name := range subcommand.Names() {
+				if name != "" {
+					candidates = append(candidates, name)
+				}
+			}
+		}
+	case command != nil && arguments == 0 && strings.HasPrefix(command.ArgsUsage, "<projectID>"):
+		candidates = completionFunctions.ProjectIDs()
+	case command != nil && arguments == 0 && strings.HasPrefix(command.ArgsUsage, "<conid>"):
+		candidates = completionFunctions.ConnectionIDs()
+	}
+
+	matches := []string{}
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, partial) {
+			matches = append(matches, candidate)
+		}
+	}
+	return matches
+}
+
+func findCommand(commands []cli.Command, name string) *cli.Command {
+	for i := range commands {
+		if commands[i].HasName(name) {
+			return &commands[i]
+		}
+	}
+	return nil
+}
+
+func findFlag(flags []cli.Flag, name string) (cli.Flag, bool) {
+	name = strings.SplitN(name, "=", 2)[0]
+	for _, flag := range flags {
+		for _, flagName := range flagNames(flag) {
+			if flagName == name {
+				return flag, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// flagNames returns the name and aliases of a flag, declared as "name, alias"
+func flagNames(flag cli.Flag) []string {
+	names := []string{}
+	for _, name := range strings.Split(flag.GetName(), ",") {
+		names = append(names, strings.TrimSpace(name))
+	}
+	return names
+}
+
+// flagTakesValue reports whether a flag is followed by a value, which boolean flags are not
+func flagTakesValue(flag cli.Flag) bool {
+	switch flag.(type) {
+	case cli.BoolFlag, cli.BoolTFlag:
+		return false
+	}
+	return true
+}
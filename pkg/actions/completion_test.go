@@ -0,0 +1,75 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func Test_completions(t *testing.T) {
+	originalFunctions := completionFunctions
+	defer func() { completionFunctions = originalFunctions }()
+	completionFunctions.ConnectionIDs = func() []string { return []string{"local", "K8S1"} }
+	completionFunctions.ProjectIDs = func() []string { return []string{"id-one", "id-two"} }
+
+	app := cli.NewApp()
+	app.Flags = []cli.Flag{cli.BoolFlag{Name: "json, j"}}
+	app.Commands = []cli.Command{
+		{
+			Name:    "project",
+			Aliases: []string{"pj"},
+			Subcommands: []cli.Command{
+				{
+					Name:      "logs",
+					ArgsUsage: "<projectID>",
+					Flags: []cli.Flag{
+						cli.StringFlag{Name: "id, i"},
+						cli.BoolFlag{Name: "follow, f"},
+					},
+				},
+				{
+					Name:  "list",
+					Flags: []cli.Flag{cli.StringFlag{Name: "conid"}},
+				},
+			},
+		},
+		{Name: "connections", ArgsUsage: "<conid>"},
+		{Name: completeCommand, Hidden: true},
+	}
+
+	tests := map[string]struct {
+		words   []string
+		partial string
+		want    []string
+	}{
+		"commands":                    {nil, "", []string{"project", "pj", "connections"}},
+		"commands with a prefix":      {nil, "p", []string{"project", "pj"}},
+		"subcommands of an alias":     {[]string{"pj"}, "l", []string{"logs", "list"}},
+		"global flags":                {nil, "--", []string{"--json"}},
+		"flags of a subcommand":       {[]string{"project", "logs"}, "-", []string{"--id", "--follow"}},
+		"connection IDs of --conid":   {[]string{"project", "list", "--conid"}, "", []string{"local", "K8S1"}},
+		"project IDs of --id":         {[]string{"project", "logs", "-i"}, "id-t", []string{"id-two"}},
+		"project ID arguments":        {[]string{"project", "logs", "--follow"}, "", []string{"id-one", "id-two"}},
+		"connection ID arguments":     {[]string{"connections"}, "K", []string{"K8S1"}},
+		"one argument":                {[]string{"project", "logs", "id-one"}, "", []string{}},
+		"after a flag value":          {[]string{"project", "list", "--conid", "local"}, "", []string{}},
+		"after a flag with its value": {[]string{"project", "list", "--conid=local"}, "", []string{}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, completions(app, test.words, test.partial))
+		})
+	}
+}
@@ -13,12 +13,15 @@ package actions
 
 import (
 	"encoding/json"
-	"fmt"
+	"flag"
+	"io/ioutil"
 	"net/http"
-	"os"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
@@ -26,10 +29,30 @@ import (
 
 // ConnectionAddToList : Add new connection to the connections config file and returns the ID of the added entry
 func ConnectionAddToList(c *cli.Context) {
-	connection, conErr := connections.AddConnectionToList(http.DefaultClient, c)
+	transport := connections.Connection{
+		ProxyURL:           c.String("proxy"),
+		NoProxy:            c.String("noproxy"),
+		CACert:             c.String("cacert"),
+		InsecureSkipVerify: c.Bool("insecure-skip-tls-verify"),
+		ClientCert:         c.String("clientcert"),
+		ClientKey:          c.String("clientkey"),
+	}
+	httpClient, transportErr := sechttp.ConnectionHTTPClient(http.DefaultClient, &transport)
+	if transportErr != nil {
+		printJSONError(transportErr)
+		exit(1)
+	}
+	connection, conErr := connections.AddConnectionToList(httpClient, c)
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
+	}
+	// The connections file only keeps the proxy URL, its user and password are kept in the keyring
+	if connection.ProxyAuth {
+		if secErr := security.SecProxyCredentialsUpdate(connection.ID, c.String("proxy")); secErr != nil {
+			printJSONError(secErr)
+			exit(1)
+		}
 	}
 
 	if printAsJSON {
@@ -38,35 +61,98 @@ func ConnectionAddToList(c *cli.Context) {
 			StatusMessage string `json:"status_message"`
 			ConID         string `json:"id"`
 		}
-		response, _ := json.Marshal(Result{Status: "OK", StatusMessage: "Connection added", ConID: strings.ToUpper(connection.ID)})
-		fmt.Println(string(response))
+		printCompactResult(Result{Status: "OK", StatusMessage: "Connection added", ConID: strings.ToUpper(connection.ID)})
 	} else {
 		logr.Printf("Connection %v added successfully", strings.ToUpper(connection.ID))
 	}
 
-	os.Exit(0)
+	exit(0)
 }
 
-// ConnectionUpdate : Update an existing connection
+// ConnectionUpdate : Update an existing connection, keeping its ID so that bound projects are unaffected
 func ConnectionUpdate(c *cli.Context) {
-	connection, conErr := connections.UpdateExistingConnection(http.DefaultClient, c)
+	connectionID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	previous, conErr := connections.GetConnectionByID(connectionID)
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
-	type Result struct {
-		Status        string `json:"status"`
-		StatusMessage string `json:"status_message"`
-		ConID         string `json:"id"`
+	transport := connections.Connection{
+		ID:                 previous.ID,
+		ProxyURL:           previous.ProxyURL,
+		ProxyAuth:          previous.ProxyAuth,
+		NoProxy:            previous.NoProxy,
+		CACert:             previous.CACert,
+		InsecureSkipVerify: previous.InsecureSkipVerify,
+		ClientCert:         previous.ClientCert,
+		ClientKey:          previous.ClientKey,
 	}
-
-	response, _ := json.Marshal(Result{Status: "OK", StatusMessage: "Connection updated", ConID: strings.ToUpper(connection.ID)})
+	if c.IsSet("proxy") {
+		transport.ProxyURL, transport.ProxyAuth = c.String("proxy"), false
+	}
+	if c.IsSet("noproxy") {
+		transport.NoProxy = c.String("noproxy")
+	}
+	if c.IsSet("cacert") {
+		transport.CACert = c.String("cacert")
+	}
+	if c.IsSet("insecure-skip-tls-verify") {
+		transport.InsecureSkipVerify = c.Bool("insecure-skip-tls-verify")
+	}
+	if c.IsSet("clientcert") {
+		transport.ClientCert = c.String("clientcert")
+	}
+	if c.IsSet("clientkey") {
+		transport.ClientKey = c.String("clientkey")
+	}
+	httpClient, transportErr := sechttp.ConnectionHTTPClient(http.DefaultClient, &transport)
+	if transportErr != nil {
+		printJSONError(transportErr)
+		exit(1)
+	}
+	connection, conErr := connections.UpdateExistingConnection(httpClient, c)
 	if conErr != nil {
-		fmt.Println(string(response))
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+
+	// Cached tokens were issued for the previous Gatekeeper, realm or user so can no longer be used
+	secDescArray := []string{}
+	if c.IsSet("proxy") {
+		if secErr := security.SecProxyCredentialsUpdate(connectionID, c.String("proxy")); secErr != nil {
+			secDescArray = append(secDescArray, secErr.Desc)
+		}
+	}
+	if previous.URL != connection.URL || previous.AuthURL != connection.AuthURL || previous.Realm != connection.Realm || !strings.EqualFold(previous.Username, connection.Username) {
+		for _, secretName := range []string{"access_token", "refresh_token"} {
+			secErr := security.DeleteSecretFromKeyring(connectionID, secretName)
+			if secErr != nil && !security.IsSecretNotFoundError(secErr) {
+				secDescArray = append(secDescArray, secErr.Desc)
+			}
+		}
+	}
+	if !strings.EqualFold(previous.Username, connection.Username) {
+		secErr := security.DeleteSecretFromKeyring(connectionID, strings.ToLower(previous.Username))
+		if secErr != nil && !security.IsSecretNotFoundError(secErr) {
+			secDescArray = append(secDescArray, secErr.Desc)
+		}
+	}
+
+	if printAsJSON {
+		type Result struct {
+			Status        string   `json:"status"`
+			StatusMessage string   `json:"status_message"`
+			ConID         string   `json:"id"`
+			Warnings      []string `json:"warnings_encountered,omitempty"`
+		}
+		printCompactResult(Result{Status: "OK", StatusMessage: "Connection updated", ConID: strings.ToUpper(connection.ID), Warnings: secDescArray})
 	} else {
+		for _, desc := range secDescArray {
+			logr.Warnf("%s", desc)
+		}
 		logr.Printf("Connection %v updated successfully", strings.ToUpper(connection.ID))
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // ConnectionGetByID : Get connection by its id
@@ -75,11 +161,11 @@ func ConnectionGetByID(c *cli.Context) {
 	connection, conErr := connections.GetConnectionByID(connectionID)
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
-	response, _ := json.Marshal(connection)
-	fmt.Println(string(response))
-	os.Exit(0)
+	connection.ProxyURL = connections.RedactProxyURL(connection.ProxyURL)
+	printCompactResult(connection)
+	exit(0)
 }
 
 // ConnectionRemoveFromList : Removes a connection from the connections config file
@@ -89,12 +175,12 @@ func ConnectionRemoveFromList(c *cli.Context) {
 	connection, conErr := connections.GetConnectionByID(connectionID)
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 	conErr = connections.RemoveConnectionFromList(c)
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	// Try to remove secrets from keychain for the specific connection.
@@ -118,6 +204,13 @@ func ConnectionRemoveFromList(c *cli.Context) {
 		secErrArray = append(secErrArray, secErr.Error())
 		secDescArray = append(secDescArray, secErr.Desc)
 	}
+	if connection.ProxyAuth {
+		secErr = security.DeleteSecretFromKeyring(connectionID, connections.ProxyCredentialsSecret)
+		if secErr != nil {
+			secErrArray = append(secErrArray, secErr.Error())
+			secDescArray = append(secDescArray, secErr.Desc)
+		}
+	}
 
 	if printAsJSON {
 		type RemoveResult struct {
@@ -125,15 +218,14 @@ func ConnectionRemoveFromList(c *cli.Context) {
 			StatusMessage string   `json:"status_message"`
 			Warnings      []string `json:"warnings_encountered"`
 		}
-		response, _ := json.Marshal(RemoveResult{Status: "OK", StatusMessage: "Connection removed", Warnings: secErrArray})
-		fmt.Println(string(response))
+		printCompactResult(RemoveResult{Status: "OK", StatusMessage: "Connection removed", Warnings: secErrArray})
 	} else {
 		for _, desc := range secDescArray {
 			logr.Warnf("%s", desc)
 		}
 		logr.Printf("Connection removed successfully")
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // ConnectionListAll : Fetch all connections
@@ -141,11 +233,13 @@ func ConnectionListAll(c *cli.Context) {
 	allConnections, conErr := connections.GetConnectionsConfig()
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
-	response, _ := json.Marshal(allConnections)
-	fmt.Println(string(response))
-	os.Exit(0)
+	for i := range allConnections.Connections {
+		allConnections.Connections[i].ProxyURL = connections.RedactProxyURL(allConnections.Connections[i].ProxyURL)
+	}
+	printCompactResult(allConnections)
+	exit(0)
 }
 
 // ConnectionResetList : Reset to a single default local connection
@@ -153,13 +247,217 @@ func ConnectionResetList(c *cli.Context) {
 	conErr := connections.ResetConnectionsFile()
 	if conErr != nil {
 		HandleConnectionError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 	if printAsJSON {
-		response, _ := json.Marshal(connections.Result{Status: "OK", StatusMessage: "Connection list reset"})
-		fmt.Println(string(response))
+		printCompactResult(connections.Result{Status: "OK", StatusMessage: "Connection list reset"})
 	} else {
 		logr.Printf("Connection list reset successfully")
 	}
-	os.Exit(0)
+	exit(0)
+}
+
+// ConnectionExport : Export connections to a file or the terminal, with their credentials when a passphrase is given
+func ConnectionExport(c *cli.Context) {
+	export, secErr := security.SecConnectionExport(c.StringSlice("conid"), c.String("passphrase"))
+	if secErr != nil {
+		printJSONError(secErr)
+		exit(1)
+	}
+	exportJSON, _ := json.MarshalIndent(export, "", "\t")
+	filename := strings.TrimSpace(c.String("file"))
+	if filename == "" {
+		printResult(export)
+		exit(0)
+	}
+	err := ioutil.WriteFile(filename, exportJSON, 0600)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	if printAsJSON {
+		printCompactResult(connections.Result{Status: "OK", StatusMessage: "Connections exported"})
+	} else {
+		logr.Printf("%v connections exported to %v", len(export.Connections), filename)
+	}
+	exit(0)
+}
+
+// ConnectionImport : Add the connections from an export file, storing included credentials in the keychain
+func ConnectionImport(c *cli.Context) {
+	exportJSON, err := ioutil.ReadFile(strings.TrimSpace(c.String("file")))
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	export := security.ConnectionExport{}
+	err = json.Unmarshal(exportJSON, &export)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	imported, secErr := security.SecConnectionImport(&export, c.String("passphrase"))
+	if secErr != nil {
+		printJSONError(secErr)
+		exit(1)
+	}
+
+	if printAsJSON {
+		type Result struct {
+			Status        string   `json:"status"`
+			StatusMessage string   `json:"status_message"`
+			ConIDs        []string `json:"ids"`
+		}
+		conIDs := []string{}
+		for _, connection := range imported {
+			conIDs = append(conIDs, strings.ToUpper(connection.ID))
+		}
+		printCompactResult(Result{Status: "OK", StatusMessage: "Connections imported", ConIDs: conIDs})
+	} else {
+		for _, connection := range imported {
+			logr.Printf("Connection %v imported successfully", strings.ToUpper(connection.ID))
+		}
+	}
+	exit(0)
+}
+
+// ConnectionTest : Check that a connection can be reached and authenticated with, reporting where it fails
+func ConnectionTest(c *cli.Context) {
+	connectionID := strings.TrimSpace(strings.ToLower(c.Args().First()))
+	if connectionID == "" {
+		connectionID = strings.TrimSpace(strings.ToLower(c.String("conid")))
+	}
+	if connections.IsAllConnections(connectionID) {
+		connectionTestAll(c)
+	}
+	connection, conErr := connections.GetConnectionByID(connectionID)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+
+	diagnosis := apiroutes.DiagnoseConnection(http.DefaultClient, connection, c.GlobalBool("insecure"))
+	if printAsJSON {
+		printCompactResult(diagnosis)
+	} else {
+		for _, check := range diagnosis.Checks {
+			switch check.Status {
+			case apiroutes.CheckFailed:
+				logr.Errorf("%-10v %v (%vms)", check.Name, check.Message, check.Latency)
+			case apiroutes.CheckSkipped:
+				logr.Infof("%-10v skipped", check.Name)
+			default:
+				logr.Infof("%-10v %v (%vms)", check.Name, check.Message, check.Latency)
+			}
+		}
+	}
+	if !diagnosis.Healthy {
+		exit(1)
+	}
+	exit(0)
+}
+
+// connectionTestAll : Check every connection, exiting with an error if any of them fail
+func connectionTestAll(c *cli.Context) {
+	result, conErr := connections.ForEachConnection(func(connection *connections.Connection) (interface{}, error) {
+		return apiroutes.DiagnoseConnection(http.DefaultClient, connection, c.GlobalBool("insecure")), nil
+	})
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+
+	healthy := true
+	for _, value := range result.Connections {
+		healthy = healthy && value.(apiroutes.ConnectionDiagnosis).Healthy
+	}
+	if printAsJSON {
+		printCompactResult(result)
+	} else {
+		for _, id := range result.SortedIDs() {
+			diagnosis := result.Connections[id].(apiroutes.ConnectionDiagnosis)
+			if diagnosis.Healthy {
+				logr.Infof("%v healthy", id)
+				continue
+			}
+			for _, check := range diagnosis.Checks {
+				if check.Status == apiroutes.CheckFailed {
+					logr.Errorf("%v %v: %v", id, check.Name, check.Message)
+				}
+			}
+		}
+	}
+	if !healthy {
+		exit(1)
+	}
+	exit(0)
+}
+
+// ConnectionDiscover : List the Codewind deployments in the current Kubernetes context, registering new ones as
+// connections when asked to
+func ConnectionDiscover(c *cli.Context) {
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+	connectionURLs := map[string]string{}
+	for _, connection := range allConnections {
+		if connection.URL != "" {
+			connectionURLs[connection.URL] = connection.ID
+		}
+	}
+
+	discovered, remInstErr := remote.DiscoverDeployments(c.String("namespace"), nil, connectionURLs)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+
+	workspaceID := strings.TrimSpace(c.String("workspace"))
+	username := strings.TrimSpace(c.String("username"))
+	if c.Bool("register") && username == "" {
+		logr.Errorln("A username (--username) is required to register connections")
+		exit(1)
+	}
+
+	for i, deployment := range discovered {
+		if !c.Bool("register") || deployment.ConnectionID != "" || (workspaceID != "" && deployment.WorkspaceID != workspaceID) {
+			continue
+		}
+		set := flag.NewFlagSet("discover", 0)
+		set.String("label", deployment.Namespace+"/"+deployment.WorkspaceID, "doc")
+		set.String("url", deployment.GatekeeperURL, "doc")
+		set.String("username", username, "doc")
+		connection, conErr := connections.AddConnectionToList(http.DefaultClient, cli.NewContext(nil, set, nil))
+		if conErr != nil {
+			logr.Warnf("Unable to register %v: %v", deployment.GatekeeperURL, conErr.Desc)
+			continue
+		}
+		discovered[i].ConnectionID = connection.ID
+	}
+
+	if printAsJSON {
+		printResult(discovered)
+	} else {
+		var tableContent []string
+		tableContent = append(tableContent, "Workspace ID \tNamespace \tVersion \tAuth Realm \tGatekeeper URL \tConnection ID")
+		for _, deployment := range discovered {
+			conID := deployment.ConnectionID
+			if conID == "" {
+				conID = "-"
+			}
+			tableContent = append(tableContent, deployment.WorkspaceID+"\t"+deployment.Namespace+"\t"+deployment.Version+"\t"+deployment.CodewindAuthRealm+"\t"+deployment.GatekeeperURL+"\t"+conID)
+		}
+		PrintTable(tableContent)
+		if !c.Bool("register") {
+			for _, deployment := range discovered {
+				if deployment.ConnectionID == "" {
+					logr.Infof("Run 'cwctl connections discover --register --username <username>' to add the unregistered deployments")
+					break
+				}
+			}
+		}
+	}
+	exit(0)
 }
@@ -0,0 +1,41 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/daemon"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// Daemon : Serves the operations of cwctl on a local socket until interrupted
+func Daemon(c *cli.Context) {
+	socket := strings.TrimSpace(c.String("socket"))
+	if socket == "" {
+		socket = daemon.DefaultSocket()
+	}
+
+	daemonErr := daemon.Serve(interruptContext(), socket)
+	if daemonErr != nil {
+		if printAsJSON {
+			printJSONError(daemonErr)
+		} else {
+			failed(daemonErr.Op)
+			logr.Error(i18n.Describe(daemonErr.Err, daemonErr.Desc))
+		}
+		exit(1)
+	}
+	exit(0)
+}
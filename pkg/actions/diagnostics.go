@@ -18,17 +18,21 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/url"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/eclipse/codewind-installer/pkg/errors"
+	"github.com/eclipse/codewind-installer/pkg/project"
 	"github.com/eclipse/codewind-installer/pkg/remote"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
@@ -54,7 +58,7 @@ var getDockerClient = docker.NewDockerClient
 
 func logDG(input string) {
 	if !printAsJSON {
-		fmt.Print(input)
+		fmt.Fprint(commandOutput(), input)
 	}
 }
 
@@ -72,7 +76,7 @@ var dgFunctions = struct {
 }{
 	CollectLocal:  dgLocalCommand,
 	CollectRemote: dgRemoteCommand,
-	Exit:          os.Exit,
+	Exit:          exit,
 }
 
 // needed for mocking, but can't go above due to initialisation loops
@@ -147,9 +151,6 @@ func DiagnosticsCollect(c *cli.Context) {
 
 	// Attempt to gather IntelliJ logs
 	gatherCodewindIntellijLogs(c.String("intellijLogsDir"))
-	if !c.Bool("nozip") {
-		createZipAndRemoveCollectedFiles()
-	}
 	// check to see if we got any data back
 	entries, _ := ioutil.ReadDir(diagnosticsDirName)
 	if len(entries) == 0 {
@@ -160,17 +161,24 @@ func DiagnosticsCollect(c *cli.Context) {
 		}
 		if printAsJSON {
 			result := dgResultStruct{DgSuccess: false, DgOutputDir: "has been deleted", DgWarningsEncountered: dgWarningArray}
-			json, _ := json.Marshal(result)
-			fmt.Println(string(json))
+			printCompactResult(result)
 		} else {
 			logDG("No diagnostics data was able to be collected - empty directory " + diagnosticsDirName + " has been deleted.")
 		}
 		dgFunctions.Exit(1)
+		return
+	}
+
+	// Add what is needed to diagnose any connection to the data collected
+	gatherSystemInfo()
+	gatherConnections()
+	gatherSyncReports()
+	if !c.Bool("nozip") {
+		createZipAndRemoveCollectedFiles()
 	}
 	if printAsJSON {
 		result := dgResultStruct{DgSuccess: true, DgOutputDir: diagnosticsDirName, DgWarningsEncountered: dgWarningArray}
-		json, _ := json.Marshal(result)
-		fmt.Println(string(json))
+		printCompactResult(result)
 	}
 }
 
@@ -230,6 +238,7 @@ func dgRemoteCommand(conid string, collectProjects bool, clientset kubernetes.In
 			collectPodInfo(clientset, cwProjPods.Items, filepath.Join(connectionID, dgProjectDirName))
 		}
 	}
+	writeEventsToFile(clientset, kubeNameSpace, workspaceID, filepath.Join(connectionID, "events.json"))
 	gatherCodewindVersions(connectionID)
 }
 
@@ -277,6 +286,25 @@ func writePodLogToFile(clientset kubernetes.Interface, pod corev1.Pod, podName s
 	return writeStreamToFile(podLogs, podName+".log")
 }
 
+// writeEventsToFile - writes the Kubernetes events of the objects of a Codewind workspace, such as failed scheduling
+// or image pulls, to a file
+func writeEventsToFile(clientset kubernetes.Interface, namespace string, workspaceID string, targetFilePath string) error {
+	logDG("Collecting events ... ")
+	eventList, err := clientset.CoreV1().Events(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		warnDG("kube_eventlist_error", "Unable to retrieve Kubernetes Events: "+err.Error())
+		return err
+	}
+	events := []corev1.Event{}
+	for _, event := range eventList.Items {
+		if strings.Contains(strings.ToLower(event.InvolvedObject.Name), strings.ToLower(workspaceID)) {
+			events = append(events, event)
+		}
+	}
+	logDG("done\n")
+	return writeJSONStructToFile(events, targetFilePath)
+}
+
 func dgLocalCommand(collectProjects bool) {
 	localDirErr := os.MkdirAll(filepath.Join(diagnosticsLocalDirName), 0755)
 	if localDirErr != nil {
@@ -508,6 +536,98 @@ func createZipAndRemoveCollectedFiles() {
 	logDG("done\n")
 }
 
+// gatherSystemInfo - writes the versions of cwctl and the operating system it is running on to a file
+func gatherSystemInfo() {
+	logDG("Collecting system information ... ")
+	systemInfo := "CWCTL VERSION: " + appconstants.VersionNum + "\n" +
+		"OS: " + runtime.GOOS + "\n" +
+		"ARCHITECTURE: " + runtime.GOARCH + "\n" +
+		"CPUS: " + strconv.Itoa(runtime.NumCPU()) + "\n" +
+		"GO VERSION: " + runtime.Version() + "\n"
+	if release := osRelease(); release != "" {
+		systemInfo += "OS RELEASE: " + release + "\n"
+	}
+	err := ioutil.WriteFile(filepath.Join(diagnosticsDirName, "system.info"), []byte(systemInfo), 0644)
+	if err != nil {
+		errors.CheckErr(err, 201, "")
+	}
+	logDG("done\n")
+}
+
+// osRelease returns the name and version of a Linux distribution, or an empty string on other operating systems
+func osRelease() string {
+	contents, err := ioutil.ReadFile("/etc/os-release")
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(contents), "\n") {
+		if strings.HasPrefix(line, "PRETTY_NAME=") {
+			return strings.Trim(strings.TrimPrefix(line, "PRETTY_NAME="), "\"")
+		}
+	}
+	return ""
+}
+
+// gatherConnections - writes the configuration of each connection to a file, without user names or proxy passwords
+func gatherConnections() {
+	logDG("Collecting connections ... ")
+	connectionList, conErr := getAllConnections()
+	if conErr != nil {
+		warnDG("connections_error", "Unable to get Connections "+conErr.Error())
+		return
+	}
+	sanitized := []connections.Connection{}
+	for _, connection := range connectionList {
+		sanitized = append(sanitized, sanitizeConnection(connection))
+	}
+	err := writeJSONStructToFile(sanitized, "connections.json")
+	if err != nil {
+		errors.CheckErr(err, 201, "")
+	}
+	logDG("done\n")
+}
+
+// sanitizeConnection removes what identifies the user from a connection: their user name, and any credentials in
+// its URLs
+func sanitizeConnection(connection connections.Connection) connections.Connection {
+	if connection.Username != "" {
+		connection.Username = redacted
+	}
+	connection.URL = sanitizeURL(connection.URL)
+	connection.AuthURL = sanitizeURL(connection.AuthURL)
+	connection.ProxyURL = sanitizeURL(connection.ProxyURL)
+	return connection
+}
+
+const redacted = "REDACTED"
+
+// sanitizeURL replaces the user and password of a URL
+func sanitizeURL(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.User == nil {
+		return rawURL
+	}
+	parsed.User = url.User(redacted)
+	return parsed.String()
+}
+
+// gatherSyncReports - copies the reports of the recent syncs of each project
+func gatherSyncReports() {
+	syncReportsDir := project.SyncReportsDir()
+	if _, err := os.Stat(syncReportsDir); os.IsNotExist(err) {
+		warnDG("Unable to collect sync reports", "no projects have been synced")
+		return
+	}
+	logDG("Collecting sync reports ... ")
+	diagnosticsSyncReportsPath := filepath.Join(diagnosticsDirName, "syncReports")
+	dirErr := os.MkdirAll(diagnosticsSyncReportsPath, 0755)
+	if dirErr != nil {
+		errors.CheckErr(dirErr, 205, "")
+	}
+	gatherEntireLogDirectory(syncReportsDir, diagnosticsSyncReportsPath, syncReportsDir)
+	logDG("done\n")
+}
+
 func gatherCodewindVersions(connectionID string) {
 	logDG("Collecting version information ... ")
 	containerVersions, cvErr := GetContainerVersions(connectionID)
@@ -0,0 +1,61 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/doctor"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/urfave/cli"
+)
+
+// Doctor : Checks the prerequisites of Codewind and the configured connections, printing a result and a hint on
+// fixing it for each check. Exits with status 1 when a check fails.
+func Doctor(c *cli.Context) {
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+
+	checks := doctor.Run(doctor.Options{
+		CodewindDir: path.Dir(dockerComposeFile),
+		Connections: allConnections,
+		HTTPClient:  sechttp.Client(),
+	})
+
+	if printAsJSON {
+		printResult(checks)
+	} else {
+		tableContent := []string{"CHECK\tRESULT\tDETAIL"}
+		var hints []string
+		for _, check := range checks {
+			tableContent = append(tableContent, check.Name+"\t"+strings.ToUpper(check.Result)+"\t"+check.Detail)
+			if check.Result != doctor.Pass && check.Hint != "" {
+				hints = append(hints, check.Name+": "+check.Hint)
+			}
+		}
+		PrintTable(tableContent)
+		for _, hint := range hints {
+			fmt.Fprintln(commandOutput(), hint)
+		}
+	}
+
+	if doctor.Failed(checks) {
+		exit(1)
+	}
+	exit(0)
+}
@@ -0,0 +1,44 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"strconv"
+
+	"github.com/eclipse/codewind-installer/pkg/errors"
+	"github.com/urfave/cli"
+)
+
+// errorCode is an operation of the error catalogue, with the exit code cwctl fails with for it
+type errorCode struct {
+	errors.Registration
+	ExitCode int `json:"exitCode"`
+}
+
+// ErrorCodes : Prints the error catalogue, the operations commands fail with and their codes and categories
+func ErrorCodes(c *cli.Context) {
+	codes := []errorCode{}
+	for _, registration := range errors.Catalogue() {
+		codes = append(codes, errorCode{registration, errors.ExitCode(registration.Op)})
+	}
+
+	if printAsJSON {
+		printResult(codes)
+	} else {
+		tableContent := []string{"CODE\tOP\tCATEGORY\tEXIT CODE"}
+		for _, code := range codes {
+			tableContent = append(tableContent, strconv.Itoa(code.Code)+"\t"+code.Op+"\t"+code.Category+"\t"+strconv.Itoa(code.ExitCode))
+		}
+		PrintTable(tableContent)
+	}
+	exit(0)
+}
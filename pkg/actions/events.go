@@ -0,0 +1,61 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/urfave/cli"
+)
+
+// Events : Prints the events PFE sends about its projects as JSON lines, until interrupted with --follow
+func Events(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("project")))
+	var conInfo *connections.Connection
+	var conURL string
+	if projectID != "" {
+		conInfo, conURL = projectConnection(c, projectID)
+	} else {
+		conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+		if conID == "" {
+			conID = "local"
+		}
+		var conErr *connections.ConError
+		conInfo, conErr = connections.GetConnectionByID(conID)
+		if conErr != nil {
+			HandleConnectionError(conErr)
+			exit(1)
+		}
+		var configErr *config.ConfigError
+		conURL, configErr = config.PFEOriginFromConnection(conInfo)
+		if configErr != nil {
+			HandleConfigError(configErr)
+			exit(1)
+		}
+	}
+
+	encoder := json.NewEncoder(commandOutput())
+	options := project.EventsOptions{ProjectID: projectID, Follow: c.Bool("follow")}
+	projErr := project.WatchEvents(interruptContext(), sechttp.Client(), conInfo, conURL, options, func(event project.ProjectEvent) {
+		encoder.Encode(event)
+	})
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	exit(0)
+}
@@ -13,41 +13,59 @@ package actions
 
 import (
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/url"
-	"os"
 	"strconv"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/project"
 	"github.com/eclipse/codewind-installer/pkg/remote"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
+	corev1 "k8s.io/api/core/v1"
 )
 
 //InstallCommand to pull images from dockerhub
 func InstallCommand(c *cli.Context) {
-	tag := c.String("tag")
+	tag := imageTagFromFlags(c)
 
-	imageArr := [2]string{
-		"docker.io/eclipse/codewind-pfe-amd64:" + tag,
-		"docker.io/eclipse/codewind-performance-amd64:" + tag,
-	}
+	imageArr := docker.CodewindImages(tag)
 
 	// creates a new docker client, which is passed into the functions that interact with the docker API
 	dockerClient, dockerErr := docker.NewDockerClient()
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
+	}
+
+	// Images loaded from an archive are installed as they are, so there is nothing to pull or check against the registry
+	if archivePath := c.String("from-archive"); archivePath != "" {
+		loaded, dockerErr := docker.LoadImageBundle(dockerClient, archivePath, imageArr)
+		if dockerErr != nil {
+			HandleDockerError(dockerErr)
+			exit(1)
+		}
+		for _, image := range loaded {
+			logr.Tracef("Loaded image %v", image)
+		}
+		fmt.Fprintln(commandOutput(), "Image Install Successful")
+		return
 	}
 
+	registryMirror := c.String("registry-mirror")
 	for i := 0; i < len(imageArr); i++ {
-		docker.PullImage(dockerClient, imageArr[i], printAsJSON)
-		imageID, dockerError := docker.ValidateImageDigest(dockerClient, imageArr[i])
+		dockerErr = docker.PullImage(dockerClient, imageArr[i], registryMirror, printAsJSON)
+		if dockerErr != nil {
+			HandleDockerError(dockerErr)
+			exit(1)
+		}
+		// The digest is checked against the registry the image was pulled from
+		imageID, dockerError := docker.ValidateImageDigest(dockerClient, docker.MirrorImage(imageArr[i], registryMirror))
 
 		if dockerError != nil {
 			logr.Tracef("%v checksum validation failed. Trying to pull image again", imageArr[i])
@@ -55,25 +73,66 @@ func InstallCommand(c *cli.Context) {
 			docker.RemoveImage(imageID)
 
 			// pull image again
-			docker.PullImage(dockerClient, imageArr[i], printAsJSON)
+			dockerErr = docker.PullImage(dockerClient, imageArr[i], registryMirror, printAsJSON)
+			if dockerErr != nil {
+				HandleDockerError(dockerErr)
+				exit(1)
+			}
 
 			// validate the new image
-			_, dockerError = docker.ValidateImageDigest(dockerClient, imageArr[i])
+			_, dockerError = docker.ValidateImageDigest(dockerClient, docker.MirrorImage(imageArr[i], registryMirror))
 
 			if dockerError != nil {
 				if printAsJSON {
-					fmt.Println(dockerError)
+					printJSONError(dockerError)
 				} else {
 					logr.Errorf("Validation of image '%v' checksum failed - Removing image", imageArr[i])
 				}
 				// Clean up the second bad image
 				docker.RemoveImage(imageID)
-				os.Exit(1)
+				exit(1)
 			}
 		}
 	}
 
-	fmt.Println("Image Install Successful")
+	fmt.Fprintln(commandOutput(), "Image Install Successful")
+}
+
+// ImageBundleCommand : Pull the Codewind images and save them to an archive, to install Codewind from without access
+// to dockerhub
+func ImageBundleCommand(c *cli.Context) {
+	images := docker.CodewindImages(imageTagFromFlags(c))
+	archivePath := c.String("output")
+
+	dockerClient, dockerErr := docker.NewDockerClient()
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+
+	for _, image := range images {
+		dockerErr = docker.PullImage(dockerClient, image, c.String("registry-mirror"), printAsJSON)
+		if dockerErr != nil {
+			HandleDockerError(dockerErr)
+			exit(1)
+		}
+	}
+
+	dockerErr = docker.SaveImageBundle(dockerClient, images, archivePath)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+
+	if printAsJSON {
+		printResult(struct {
+			Status  string   `json:"status"`
+			Archive string   `json:"archive"`
+			Images  []string `json:"images"`
+		}{"OK", archivePath, images})
+	} else {
+		fmt.Fprintf(commandOutput(), "Saved %v images to %v\n", len(images), archivePath)
+	}
 }
 
 // DoRemoteInstall : Deploy a remote PFE and support containers
@@ -82,58 +141,60 @@ func DoRemoteInstall(c *cli.Context) {
 	// Since remote will always use Self Signed Certificates initially, turn on insecure flag
 	http.DefaultTransport.(*http.Transport).TLSClientConfig = &tls.Config{InsecureSkipVerify: true}
 
-	session := c.String("session")
-	if session == "" {
-		session = strings.ToUpper(strconv.FormatInt(utils.CreateTimestamp(), 36))
+	if c.Bool("interactive") {
+		remoteInstallWizard(c)
 	}
 
-	if c.Int("pvcsize") < 0 || c.Int("pvcsize") > 999 {
-		logr.Error("Codewind PVC size should be between 1 and 999 GB")
-		os.Exit(1)
+	var deployOptions remote.DeployOptions
+	if c.String("file") != "" {
+		deployConfig, err := remote.LoadDeployConfig(c.String("file"))
+		if err != nil {
+			logr.Errorln(err)
+			exit(1)
+		}
+		deployOptions = deployConfig.DeployOptions()
+	} else {
+		deployOptions = remoteDeployOptionsFromFlags(c)
 	}
 
-	codewindPVCSize := c.Int("pvcsize")
-	if codewindPVCSize < 1 {
-		codewindPVCSize = 1
+	// The version flags apply to installs from a deployment config file too, overriding its images settings
+	if c.String("channel") != "" || c.String("tag") != "" {
+		deployOptions.ImageTag = imageTagFromFlags(c)
+	}
+	if c.Bool("allow-mixed-versions") {
+		deployOptions.AllowMixedVersions = true
 	}
 
-	keycloakHost := c.String("kurl")
-	if keycloakHost != "" {
-		u, err := url.Parse(keycloakHost)
+	if deployOptions.CodewindSessionSecret == "" {
+		deployOptions.CodewindSessionSecret = strings.ToUpper(strconv.FormatInt(utils.CreateTimestamp(), 36))
+	}
+
+	if deployOptions.KeycloakURL != "" {
+		u, err := url.Parse(deployOptions.KeycloakURL)
 		if err != nil {
 			logr.Error("Supplied Keycloak URL is invalid")
-			os.Exit(1)
+			exit(1)
 		}
-		keycloakHost = u.Hostname()
+		deployOptions.KeycloakHost = u.Hostname()
 	}
 
-	deployOptions := remote.DeployOptions{
-		Namespace:             c.String("namespace"),
-		IngressDomain:         c.String("ingress"),
-		KeycloakUser:          c.String("kadminuser"),
-		KeycloakPassword:      c.String("kadminpass"),
-		KeycloakDevUser:       c.String("kdevuser"),
-		KeycloakDevPassword:   c.String("kdevpass"),
-		KeycloakRealm:         c.String("krealm"),
-		KeycloakClient:        c.String("kclient"),
-		KeycloakURL:           c.String("kurl"),
-		KeycloakOnly:          c.Bool("konly"),
-		KeycloakHost:          keycloakHost,
-		GateKeeperTLSSecure:   true,
-		KeycloakTLSSecure:     true,
-		CodewindSessionSecret: session,
-		CodewindPVCSize:       strconv.Itoa(codewindPVCSize) + "Gi",
-		LogLevel:              c.GlobalString("loglevel"),
+	deployOptions.GateKeeperTLSSecure = true
+	deployOptions.KeycloakTLSSecure = true
+	deployOptions.LogLevel = c.GlobalString("loglevel")
+	deployOptions.SkipPreflight = c.Bool("skip-preflight")
+
+	if c.Bool("preflight") {
+		remotePreflight(&deployOptions)
 	}
 
-	deploymentResult, remInstError := remote.DeployRemote(&deployOptions)
+	deploymentResult, remInstError := remote.DeployRemoteContext(interruptContext(), &deployOptions)
 	if remInstError != nil {
 		if printAsJSON {
-			fmt.Println(remInstError.Error())
+			printJSONError(remInstError)
 		} else {
-			logr.Errorf("Error: %v - %v\n", remInstError.Op, remInstError.Desc)
+			logr.Errorf("Error: %v - %v\n", remInstError.Op, i18n.Describe(remInstError.Err, remInstError.Desc))
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
 	// If performing a Keycloak only install,  display just the keycloak URL
@@ -146,12 +207,11 @@ func DoRemoteInstall(c *cli.Context) {
 		}
 		if printAsJSON {
 			result := project.Result{Status: "OK", StatusMessage: "Keycloak Install Successful: " + keycloakURL}
-			response, _ := json.Marshal(result)
-			fmt.Println(string(response))
+			printCompactResult(result)
 		} else {
 			logr.Infoln("Keycloak is available at: " + keycloakURL)
 		}
-		os.Exit(0)
+		exit(0)
 	}
 
 	// We're doing a full install. Wait Gatekeeper to startup and for PFE to respond
@@ -164,12 +224,217 @@ func DoRemoteInstall(c *cli.Context) {
 	logr.Infoln("Waiting for Codewind PFE to start")
 	utils.WaitForService(gatekeeperURL+"/api/pfe/ready", 200, 500)
 
+	if c.Bool("add-connection") {
+		addInstalledConnection(deployOptions, gatekeeperURL)
+	}
+
 	result := project.Result{Status: "OK", StatusMessage: "Install Successful: " + gatekeeperURL}
 	if printAsJSON {
-		response, _ := json.Marshal(result)
-		fmt.Println(string(response))
+		printCompactResult(result)
 	} else {
 		logr.Infoln("Codewind is available at: " + gatekeeperURL)
+		logr.Infoln("Install ID, for cwctl remove remote --install-id: " + deploymentResult.InstallID)
+	}
+	exit(0)
+}
+
+// addInstalledConnection adds a connection to an installed Codewind for its developer user, with the Gatekeeper URL
+// and the Keycloak URL the Gatekeeper reports. Connections to self-signed certificates skip verifying them. Failing
+// to add the connection does not fail the install.
+func addInstalledConnection(deployOptions remote.DeployOptions, gatekeeperURL string) {
+	options := connections.ConnectionOptions{
+		Label:              deployOptions.Namespace,
+		URL:                gatekeeperURL,
+		Username:           deployOptions.KeycloakDevUser,
+		InsecureSkipVerify: deployOptions.CertIssuer == "" && deployOptions.GatekeeperTLSSecret == "",
+	}
+	connection, conErr := connections.AddConnection(http.DefaultClient, options)
+	if conErr != nil {
+		logr.Warnf("Unable to add a connection to %v: %v\n", gatekeeperURL, conErr.Desc)
+		return
+	}
+	logr.Infoln("Added connection " + connection.ID + ", for cwctl commands with --conid " + connection.ID)
+}
+
+// remotePreflight reports the checks an install makes before creating anything, exiting with 1 if any failed
+func remotePreflight(deployOptions *remote.DeployOptions) {
+	report, remInstErr := remote.PreflightRemote(deployOptions)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(report)
+	} else {
+		rows := []string{"CHECK\tSTATUS\tMESSAGE"}
+		for _, check := range report.Checks {
+			rows = append(rows, check.Name+"\t"+check.Status+"\t"+check.Message)
+		}
+		PrintTable(rows)
+		for _, check := range report.Checks {
+			if check.Hint != "" {
+				fmt.Fprintf(commandOutput(), "%v: %v\n", check.Name, check.Hint)
+			}
+		}
+	}
+	if !report.Passed {
+		exit(1)
+	}
+	exit(0)
+}
+
+// remoteDeployOptionsFromFlags builds the install options from the command line flags, exiting if any are invalid
+func remoteDeployOptionsFromFlags(c *cli.Context) remote.DeployOptions {
+	if c.String("namespace") == "" {
+		logr.Error("Either --namespace or --file must be set")
+		exit(1)
+	}
+
+	if c.Int("pvcsize") < 0 || c.Int("pvcsize") > 999 {
+		logr.Error("Codewind PVC size should be between 1 and 999 GB")
+		exit(1)
+	}
+
+	codewindPVCSize := c.Int("pvcsize")
+	if codewindPVCSize < 1 {
+		codewindPVCSize = 1
+	}
+
+	if c.Int("kpvcsize") < 0 || c.Int("kpvcsize") > 999 {
+		logr.Error("Keycloak PVC size should be between 1 and 999 GB")
+		exit(1)
+	}
+
+	keycloakPVCSize := c.Int("kpvcsize")
+	if keycloakPVCSize < 1 {
+		keycloakPVCSize = 1
+	}
+
+	certIssuerKind := c.String("certissuerkind")
+	if certIssuerKind != remote.CertIssuerKindIssuer && certIssuerKind != remote.CertIssuerKindClusterIssuer {
+		logr.Error("Certificate issuer kind should be Issuer or ClusterIssuer")
+		exit(1)
+	}
+
+	ingressAnnotations, err := remote.ParseIngressAnnotations(c.StringSlice("ingressannotation"))
+	if err != nil {
+		logr.Errorf("Invalid --ingressannotation value: %v\n", err)
+		exit(1)
+	}
+
+	nodeSelector, err := remote.ParseNodeSelector(c.StringSlice("nodeselector"))
+	if err != nil {
+		logr.Errorf("Invalid --nodeselector value: %v\n", err)
+		exit(1)
+	}
+
+	tolerations, err := remote.ParseTolerations(c.StringSlice("toleration"))
+	if err != nil {
+		logr.Errorf("Invalid --toleration value: %v\n", err)
+		exit(1)
+	}
+
+	affinity, err := remote.LoadAffinity(c.String("affinity"))
+	if err != nil {
+		logr.Errorf("Invalid --affinity value: %v\n", err)
+		exit(1)
+	}
+
+	performanceReplicas := c.Int("perfreplicas")
+	gatekeeperReplicas := c.Int("gkreplicas")
+	if performanceReplicas < 1 || gatekeeperReplicas < 1 {
+		logr.Error("Replicas should be at least 1")
+		exit(1)
+	}
+
+	namespaceLabels, err := remote.ParseNamespaceLabels(c.StringSlice("namespace-label"))
+	if err != nil {
+		logr.Errorf("Invalid --namespace-label value: %v\n", err)
+		exit(1)
+	}
+
+	namespaceAnnotations, err := remote.ParseNamespaceAnnotations(c.StringSlice("namespace-annotation"))
+	if err != nil {
+		logr.Errorf("Invalid --namespace-annotation value: %v\n", err)
+		exit(1)
+	}
+
+	podSecurityProfile := c.String("pod-security")
+	if err := remote.ValidatePodSecurityProfile(podSecurityProfile); err != nil {
+		logr.Errorf("Invalid --pod-security value: %v\n", err)
+		exit(1)
+	}
+
+	pfeResources := parseResourceFlag(c, "pferesources")
+	performanceResources := parseResourceFlag(c, "perfresources")
+	gatekeeperResources := parseResourceFlag(c, "gkresources")
+	keycloakResources := parseResourceFlag(c, "kresources")
+
+	return remote.DeployOptions{
+		Namespace:             c.String("namespace"),
+		NamespaceLabels:       namespaceLabels,
+		NamespaceAnnotations:  namespaceAnnotations,
+		KeycloakAdminSecret:   c.String("kadmin-secret"),
+		GatekeeperTLSSecret:   c.String("gatekeeper-tls-secret"),
+		KeycloakTLSSecret:     c.String("keycloak-tls-secret"),
+		IngressDomain:         c.String("ingress"),
+		WildcardDomain:        c.String("wildcard-domain"),
+		KeycloakUser:          c.String("kadminuser"),
+		KeycloakPassword:      c.String("kadminpass"),
+		KeycloakDevUser:       c.String("kdevuser"),
+		KeycloakDevPassword:   c.String("kdevpass"),
+		KeycloakRealm:         c.String("krealm"),
+		KeycloakClient:        c.String("kclient"),
+		KeycloakURL:           c.String("kurl"),
+		KeycloakOnly:          c.Bool("konly"),
+		CodewindSessionSecret: c.String("session"),
+		CodewindPVCSize:       strconv.Itoa(codewindPVCSize) + "Gi",
+		StorageClass:          c.String("storageclass"),
+		KeycloakStorageClass:  c.String("kstorageclass"),
+		KeycloakPVCSize:       strconv.Itoa(keycloakPVCSize) + "Gi",
+		PFEResources:          pfeResources,
+		PerformanceResources:  performanceResources,
+		GatekeeperResources:   gatekeeperResources,
+		KeycloakResources:     keycloakResources,
+		CertIssuer:            c.String("certissuer"),
+		CertIssuerKind:        certIssuerKind,
+		IngressClass:          c.String("ingressclass"),
+		IngressAnnotations:    ingressAnnotations,
+		GatekeeperHost:        c.String("gatekeeperhost"),
+		KeycloakHost:          c.String("keycloakhost"),
+		NetworkPolicies:       c.Bool("networkpolicies"),
+		Metrics:               c.Bool("metrics"),
+		NodeSelector:          nodeSelector,
+		Tolerations:           tolerations,
+		Affinity:              affinity,
+		PerformanceReplicas:   int32(performanceReplicas),
+		GatekeeperReplicas:    int32(gatekeeperReplicas),
+		PodSecurityProfile:    podSecurityProfile,
+		PFESecurity:           parseSecurityFlag(c, "pfesecurity"),
+		PerformanceSecurity:   parseSecurityFlag(c, "perfsecurity"),
+		GatekeeperSecurity:    parseSecurityFlag(c, "gksecurity"),
+		KeycloakSecurity:      parseSecurityFlag(c, "ksecurity"),
+		Wait:                  c.Bool("wait"),
+		Timeout:               c.Duration("timeout"),
+	}
+}
+
+// parseResourceFlag converts the named resource flag into resource requirements, exiting if it is malformed
+func parseResourceFlag(c *cli.Context, flag string) corev1.ResourceRequirements {
+	resources, err := remote.ParseResourceRequirements(c.String(flag))
+	if err != nil {
+		logr.Errorf("Invalid --%v value: %v\n", flag, err)
+		exit(1)
+	}
+	return resources
+}
+
+// parseSecurityFlag converts the named security flag into security context settings, exiting if it is malformed
+func parseSecurityFlag(c *cli.Context, flag string) remote.PodSecurity {
+	security, err := remote.ParsePodSecurity(c.String(flag))
+	if err != nil {
+		logr.Errorf("Invalid --%v value: %v\n", flag, err)
+		exit(1)
 	}
-	os.Exit(0)
+	return security
 }
@@ -0,0 +1,210 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/urfave/cli"
+)
+
+// ProjectLoadTestRun : Starts a load run against a project, optionally waiting for it to finish and downloading the
+// results. Exits with an error when the run waited for does not complete.
+func ProjectLoadTestRun(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	projErr := project.StartLoadTest(sechttp.Client(), conInfo, conURL, projectID, c.String("description"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if !c.Bool("wait") {
+		printResult(project.Result{Status: "OK", StatusMessage: "Load run started"})
+		exit(0)
+	}
+
+	status, projErr := project.WaitForLoadTest(sechttp.Client(), conInfo, conURL, projectID, c.Duration("timeout"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	completed := status.Status == "completed" || status.Status == "idle"
+
+	if c.String("output") != "" && completed {
+		results, projErr := project.DownloadLoadTestResults(sechttp.Client(), conInfo, conURL, projectID, c.String("output"))
+		if projErr != nil {
+			HandleProjectError(projErr)
+			exit(1)
+		}
+		printResult(results)
+	} else {
+		printResult(project.Result{Status: status.Status, StatusMessage: "Load run finished with status " + status.Status})
+	}
+	if !completed {
+		exit(1)
+	}
+	exit(0)
+}
+
+// ProjectLoadTestStatus : Prints the state of the load runner of a project
+func ProjectLoadTestStatus(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	status, projErr := project.GetLoadTestStatus(sechttp.Client(), conInfo, conURL, projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(status)
+	exit(0)
+}
+
+// ProjectLoadTestCancel : Cancels the load run of a project
+func ProjectLoadTestCancel(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	projErr := project.CancelLoadTest(sechttp.Client(), conInfo, conURL, projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(project.Result{Status: "OK", StatusMessage: "Load run cancelled"})
+	exit(0)
+}
+
+// ProjectLoadTestDownload : Downloads the metrics and comparison of the load runs of a project
+func ProjectLoadTestDownload(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	results, projErr := project.DownloadLoadTestResults(sechttp.Client(), conInfo, conURL, projectID, c.String("output"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(results)
+	exit(0)
+}
+
+// ProjectLoadTestScheduleCreate : Schedules load runs of a project, with a load profile overriding the load test
+// configuration of the project for each run
+func ProjectLoadTestScheduleCreate(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	schedule, projErr := project.AddLoadTestSchedule(sechttp.Client(), conInfo, conURL, project.LoadTestSchedule{
+		Name:        c.String("name"),
+		ProjectID:   projectID,
+		Schedule:    c.String("schedule"),
+		Description: c.String("description"),
+		Archive:     c.String("output"),
+		Profile: &project.LoadTestProfile{
+			Path:              c.String("path"),
+			RequestsPerSecond: c.Int("rps"),
+			Concurrency:       c.Int("concurrency"),
+			MaxSeconds:        c.Int("max-seconds"),
+		},
+	})
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(schedule)
+	exit(0)
+}
+
+// ProjectLoadTestScheduleList : Lists the load test schedules, and where their runs are started from
+func ProjectLoadTestScheduleList(c *cli.Context) {
+	schedules, projErr := project.GetLoadTestSchedules()
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(schedules)
+	} else if len(schedules) == 0 {
+		fmt.Fprintln(commandOutput(), "No load test schedules found")
+	} else {
+		rows := []string{"NAME\tPROJECT ID\tCONNECTION ID\tSCHEDULE\tRUN BY"}
+		for _, schedule := range schedules {
+			runBy := "daemon"
+			if schedule.CronJob != nil {
+				runBy = "CronJob " + schedule.CronJob.Namespace + "/" + schedule.CronJob.Name
+			}
+			if schedule.Archive != "" {
+				runBy += ", archived in " + schedule.Archive
+			}
+			rows = append(rows, schedule.Name+"\t"+schedule.ProjectID+"\t"+schedule.ConnectionID+"\t"+schedule.Schedule+"\t"+runBy)
+		}
+		PrintTable(rows)
+	}
+	exit(0)
+}
+
+// ProjectLoadTestScheduleRemove : Stops the scheduled load runs of a schedule
+func ProjectLoadTestScheduleRemove(c *cli.Context) {
+	name := c.String("name")
+	projErr := project.RemoveLoadTestSchedule(name)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(project.Result{Status: "OK", StatusMessage: "Load test schedule " + name + " removed"})
+	exit(0)
+}
+
+// ProjectProfilingList : Lists the load runs of a project that profiling data can be downloaded for
+func ProjectProfilingList(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	runs, projErr := project.ListProfilingRuns(sechttp.Client(), conInfo, conURL, projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(runs)
+	} else if len(runs) == 0 {
+		fmt.Fprintln(commandOutput(), "No load runs found for project "+projectID)
+	} else {
+		rows := []string{"RUN\tSTARTED\tDESCRIPTION"}
+		for _, run := range runs {
+			started := time.Unix(0, run.Time*int64(time.Millisecond)).Format(time.RFC3339)
+			rows = append(rows, strconv.FormatInt(run.Time, 10)+"\t"+started+"\t"+run.Description)
+		}
+		PrintTable(rows)
+	}
+	exit(0)
+}
+
+// ProjectProfilingDownload : Downloads the profiling data of a load run of a project, the latest if no run is given
+func ProjectProfilingDownload(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
+	conInfo, conURL := projectConnection(c, projectID)
+
+	download, projErr := project.DownloadProfilingData(sechttp.Client(), conInfo, conURL, projectID, c.Int64("run"), c.String("output"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(download)
+	exit(0)
+}
@@ -12,15 +12,12 @@
 package actions
 
 import (
-	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
-	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/urfave/cli"
 )
 
@@ -32,28 +29,28 @@ func LogLevels(c *cli.Context) {
 
 	conInfo, conInfoErr := connections.GetConnectionByID(connectionID)
 	if conInfoErr != nil {
-		fmt.Println(conInfoErr.Err)
-		os.Exit(1)
+		HandleConnectionError(conInfoErr)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
-		fmt.Println(conErr.Err)
-		os.Exit(1)
+		HandleConfigError(conErr)
+		exit(1)
 	}
 
 	if newLogLevel != "" {
-		err := apiroutes.SetLogLevel(conInfo, conURL, http.DefaultClient, newLogLevel)
+		err := apiroutes.SetLogLevel(conInfo, conURL, sechttp.Client(), newLogLevel)
 		if err != nil {
-			fmt.Println(err.Error())
-			os.Exit(1)
+			printJSONError(err)
+			exit(1)
 		}
 	}
 
-	loggingLevels, err := apiroutes.GetLogLevel(conInfo, conURL, http.DefaultClient)
+	loggingLevels, err := apiroutes.GetLogLevel(conInfo, conURL, sechttp.Client())
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
-	utils.PrettyPrintJSON(loggingLevels)
+	printResult(loggingLevels)
 }
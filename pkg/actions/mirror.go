@@ -0,0 +1,92 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/urfave/cli"
+)
+
+// projectMirrorSync syncs a project and its mirrors, printing the outcome on each connection. Exits with an error
+// when the sync fails on any connection.
+func projectMirrorSync(options project.SyncOptions) {
+	response, projErr := project.SyncMirrors(interruptContext(), options)
+	if response == nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printCompactResult(response)
+	} else {
+		rows := []string{"CONNECTION ID\tPROJECT ID\tSTATUS\tUPLOADED"}
+		for _, result := range response.Results {
+			status, uploaded := result.Error, "0"
+			if result.Response != nil {
+				uploaded = strconv.Itoa(len(result.Response.UploadedFiles))
+				if status == "" {
+					status = result.Response.Status
+				}
+			}
+			rows = append(rows, result.ConnectionID+"\t"+result.ProjectID+"\t"+status+"\t"+uploaded)
+		}
+		PrintTable(rows)
+	}
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	exit(0)
+}
+
+// ProjectMirrorList : Lists the mirrors of a project
+func ProjectMirrorList(c *cli.Context) {
+	projectID := strings.TrimSpace(c.String("id"))
+	mirrors, projErr := project.GetProjectMirrors(projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(mirrors)
+	} else if len(mirrors) == 0 {
+		fmt.Fprintln(commandOutput(), "No mirrors found for project "+projectID)
+	} else {
+		rows := []string{"CONNECTION ID\tPROJECT ID\tLAST SYNC"}
+		for _, mirror := range mirrors {
+			lastSync := "never"
+			if mirror.LastSync != 0 {
+				lastSync = time.Unix(0, mirror.LastSync*int64(time.Millisecond)).Format(time.RFC3339)
+			}
+			rows = append(rows, mirror.ConnectionID+"\t"+mirror.ProjectID+"\t"+lastSync)
+		}
+		PrintTable(rows)
+	}
+	exit(0)
+}
+
+// ProjectMirrorRemove : Stops syncing a mirror with its project
+func ProjectMirrorRemove(c *cli.Context) {
+	projectID := strings.TrimSpace(c.String("id"))
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	projErr := project.RemoveProjectMirror(projectID, conID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(project.Result{Status: "OK", StatusMessage: "Project " + projectID + " is no longer mirrored on connection " + conID})
+	exit(0)
+}
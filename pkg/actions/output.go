@@ -0,0 +1,196 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/eclipse/codewind-installer/pkg/errors"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// Statuses of Output
+const (
+	OutputSuccess = "success"
+	OutputFailure = "error"
+)
+
+type (
+	// Output : The JSON document a command prints to stdout with --json when it fails, or succeeds without returning
+	// anything. Error is set when it fails.
+	Output struct {
+		Status string       `json:"status"`
+		Error  *OutputError `json:"error,omitempty"`
+	}
+
+	// OutputError : The operation code of a failure, its numeric code and category from the error catalogue, and a
+	// description of it
+	OutputError struct {
+		Op          string `json:"op"`
+		Code        int    `json:"code"`
+		Category    string `json:"category"`
+		Description string `json:"description"`
+	}
+
+	// lastErrorHook remembers the last error logged, to describe failures with no operation code
+	lastErrorHook struct{}
+)
+
+var (
+	// jsonOutput is where the JSON document is printed, stdout when not set
+	jsonOutput io.Writer
+	// outputWritten is set once the JSON document has been printed
+	outputWritten bool
+	// lastError is the message of the last error logged
+	lastError string
+)
+
+func (lastErrorHook) Levels() []logr.Level {
+	return []logr.Level{logr.ErrorLevel, logr.FatalLevel, logr.PanicLevel}
+}
+
+func (lastErrorHook) Fire(entry *logr.Entry) error {
+	lastError = entry.Message
+	return nil
+}
+
+// useJSONOutput records the errors logged, to describe failures in the JSON document, and prints the document of
+// commands that exit with a fatal log
+func useJSONOutput() {
+	logr.AddHook(lastErrorHook{})
+	logr.RegisterExitHandler(func() { finishOutput(1) })
+}
+
+// commandOutput returns where commands print text other than the JSON document: stderr with --json, so that stdout
+// can always be parsed, and stdout without
+func commandOutput() io.Writer {
+	if printAsJSON {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// printResult prints the data a command returns, as the JSON document with --json and as indented JSON without
+func printResult(data interface{}) {
+	if printAsJSON {
+		writeDocument(resultDocument(data))
+		return
+	}
+	utils.PrettyPrintJSON(data)
+}
+
+// printCompactResult is printResult for commands that print their data on one line without --json
+func printCompactResult(data interface{}) {
+	if printAsJSON {
+		writeDocument(resultDocument(data))
+		return
+	}
+	response, _ := json.Marshal(data)
+	fmt.Println(string(response))
+}
+
+// printJSONError prints an error, in the Output document with --json and in the JSON of the error without
+func printJSONError(err error) {
+	outputErr := outputError(err)
+	failed(outputErr.Op)
+	if printAsJSON {
+		writeOutput(Output{Status: OutputFailure, Error: outputErr})
+		return
+	}
+	fmt.Println(err.Error())
+}
+
+// resultDocument returns the JSON document of the data a command returns. The data keeps the shape commands printed
+// before the document was added, so objects are only given a success status when they have no status of their own.
+func resultDocument(data interface{}) []byte {
+	response, _ := json.Marshal(data)
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(response, &fields) != nil {
+		return response
+	}
+	if _, found := fields["status"]; found {
+		return response
+	}
+	if len(fields) == 0 {
+		response, _ = json.Marshal(Output{Status: OutputSuccess})
+		return response
+	}
+	// Adding the status in front of the fields keeps them in the order they were printed in
+	return append([]byte(`{"status":"`+OutputSuccess+`",`), response[1:]...)
+}
+
+// outputError reads the operation code and description of an error. Errors of the packages are JSON objects with
+// error and error_description fields, while other errors are only described.
+func outputError(err error) *OutputError {
+	op, desc, ok := parsePackageError(err.Error())
+	if !ok {
+		return newOutputError(errors.ErrOpUnknown, err.Error())
+	}
+	// Errors wrapping an error of another package describe themselves with the JSON of that error
+	if _, innerDesc, ok := parsePackageError(desc); ok {
+		desc = innerDesc
+	}
+	return newOutputError(op, desc)
+}
+
+// newOutputError describes a failure with the code and category its operation is registered with
+func newOutputError(op string, desc string) *OutputError {
+	registration := errors.Lookup(op)
+	return &OutputError{Op: op, Code: registration.Code, Category: registration.Category, Description: desc}
+}
+
+func parsePackageError(text string) (string, string, bool) {
+	var packageErr struct {
+		Op   string `json:"error"`
+		Desc string `json:"error_description"`
+	}
+	if json.Unmarshal([]byte(text), &packageErr) != nil || packageErr.Op == "" {
+		return "", "", false
+	}
+	return packageErr.Op, packageErr.Desc, true
+}
+
+// finishOutput prints the Output document of a command that exits without having printed one, so that every command
+// prints a document with --json
+func finishOutput(code int) {
+	if !printAsJSON || outputWritten {
+		return
+	}
+	if code == 0 {
+		writeOutput(Output{Status: OutputSuccess})
+		return
+	}
+	// Commands that handled an error without printing it still report its operation
+	op := failedOp
+	if op == "" {
+		op = errors.ErrOpUnknown
+	}
+	writeOutput(Output{Status: OutputFailure, Error: newOutputError(op, lastError)})
+}
+
+func writeOutput(output Output) {
+	response, _ := json.Marshal(output)
+	writeDocument(response)
+}
+
+func writeDocument(response []byte) {
+	writer := jsonOutput
+	if writer == nil {
+		writer = os.Stdout
+	}
+	fmt.Fprintln(writer, string(response))
+	outputWritten = true
+}
@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	cwerrors "github.com/eclipse/codewind-installer/pkg/errors"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	logr "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Output(t *testing.T) {
+	originalPrintAsJSON, originalJSONOutput := printAsJSON, jsonOutput
+	defer func() {
+		printAsJSON, jsonOutput, outputWritten, failedOp = originalPrintAsJSON, originalJSONOutput, false, ""
+	}()
+	printAsJSON = true
+	var out bytes.Buffer
+	reset := func() {
+		out.Reset()
+		jsonOutput = &out
+		outputWritten = false
+		lastError = ""
+		failedOp = ""
+	}
+
+	t.Run("adds a success status to the data of a command", func(t *testing.T) {
+		reset()
+		printResult(struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		}{"local", "Local"})
+		assert.Equal(t, "{\"status\":\"success\",\"id\":\"local\",\"name\":\"Local\"}\n", out.String())
+	})
+
+	t.Run("keeps the data of a command with its own status as it is", func(t *testing.T) {
+		reset()
+		printCompactResult(struct {
+			Status        string `json:"status"`
+			StatusMessage string `json:"status_message"`
+		}{"OK", "Connection added"})
+		assert.Equal(t, "{\"status\":\"OK\",\"status_message\":\"Connection added\"}\n", out.String())
+	})
+
+	t.Run("keeps data other than objects as it is", func(t *testing.T) {
+		reset()
+		printResult([]string{"a", "b"})
+		assert.Equal(t, "[\"a\",\"b\"]\n", out.String())
+	})
+
+	t.Run("reads the operation code of package errors", func(t *testing.T) {
+		reset()
+		printJSONError(&connections.ConError{Op: "con_not_found", Err: errors.New("Connection NOPE not found"), Desc: "Connection NOPE not found"})
+		assert.Equal(t, "{\"status\":\"error\",\"error\":{\"op\":\"con_not_found\",\"code\":2006,\"category\":\"user\",\"description\":\"Connection NOPE not found\"}}\n", out.String())
+	})
+
+	t.Run("describes errors in English whatever the locale", func(t *testing.T) {
+		defer i18n.SetLocale(i18n.DefaultLocale)
+		i18n.SetLocale("de")
+		err := i18n.Errorf("connections.con_not_found", "Connection %s not found", "NOPE")
+		assert.Equal(t, "Connection NOPE not found", outputError(&connections.ConError{Op: "con_not_found", Err: err, Desc: err.Error()}).Description)
+	})
+
+	t.Run("describes errors wrapping the error of another package", func(t *testing.T) {
+		inner := &connections.ConError{Op: "con_not_found", Err: errors.New("Connection NOPE not found"), Desc: "Connection NOPE not found"}
+		outer := &config.ConfigError{Op: "config_connection_notfound", Err: inner, Desc: inner.Error()}
+		assert.Equal(t, &OutputError{Op: "config_connection_notfound", Code: 2101, Category: cwerrors.CategoryUser, Description: "Connection NOPE not found"}, outputError(outer))
+	})
+
+	t.Run("describes other errors without an operation code", func(t *testing.T) {
+		assert.Equal(t, &OutputError{Op: cwerrors.ErrOpUnknown, Code: 1000, Category: cwerrors.CategoryServer, Description: "Required flags not set"}, outputError(errors.New("Required flags not set")))
+	})
+
+	t.Run("prints a document for commands that print nothing", func(t *testing.T) {
+		reset()
+		finishOutput(0)
+		assert.Equal(t, "{\"status\":\"success\"}\n", out.String())
+	})
+
+	t.Run("prints text other than the document to stderr", func(t *testing.T) {
+		assert.Equal(t, os.Stderr, commandOutput())
+	})
+
+	t.Run("prints the last error logged for commands that fail without an error", func(t *testing.T) {
+		reset()
+		lastErrorHook{}.Fire(&logr.Entry{Message: "Must specify --name"})
+		finishOutput(1)
+		assert.Equal(t, "{\"status\":\"error\",\"error\":{\"op\":\"CWCTL_ERROR\",\"code\":1000,\"category\":\"server\",\"description\":\"Must specify --name\"}}\n", out.String())
+	})
+
+	t.Run("prints the operation of an error handled without printing it", func(t *testing.T) {
+		reset()
+		failed("proj_notfound")
+		lastErrorHook{}.Fire(&logr.Entry{Message: "project not found"})
+		finishOutput(4)
+		assert.Equal(t, "{\"status\":\"error\",\"error\":{\"op\":\"proj_notfound\",\"code\":3012,\"category\":\"user\",\"description\":\"project not found\"}}\n", out.String())
+	})
+
+	t.Run("prints one document", func(t *testing.T) {
+		reset()
+		printResult(map[string]string{})
+		finishOutput(1)
+		assert.Equal(t, "{\"status\":\"success\"}\n", out.String())
+	})
+}
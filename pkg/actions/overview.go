@@ -0,0 +1,59 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/urfave/cli"
+)
+
+// Overview : Prints the health and bound projects of every connection
+func Overview(c *cli.Context) {
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+
+	overview := project.GetOverview(sechttp.Client(), allConnections)
+
+	if printAsJSON {
+		printResult(overview)
+	} else {
+		var tableContent []string
+		tableContent = append(tableContent, "CONNECTION ID \tLABEL \tHEALTH \tPROJECT \tAPP STATUS \tBUILD STATUS \tLAST SYNC")
+		for _, con := range overview.Connections {
+			if len(con.Projects) == 0 {
+				tableContent = append(tableContent, con.ID+"\t"+con.Label+"\t"+con.Health+"\t-\t-\t-\t-")
+				continue
+			}
+			for _, proj := range con.Projects {
+				tableContent = append(tableContent, con.ID+"\t"+con.Label+"\t"+con.Health+"\t"+proj.Name+"\t"+strings.Title(proj.AppStatus)+"\t"+strings.Title(proj.BuildStatus)+"\t"+formatSyncTime(proj.LastSync))
+			}
+		}
+		PrintTable(tableContent)
+	}
+	exit(0)
+}
+
+// formatSyncTime converts a sync time in milliseconds since the epoch to a readable date
+func formatSyncTime(millis int64) string {
+	if millis == 0 {
+		return "-"
+	}
+	return time.Unix(0, millis*int64(time.Millisecond)).Format(time.RFC1123)
+}
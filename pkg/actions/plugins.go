@@ -0,0 +1,150 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/plugins"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// pluginOptions returns where plugins are looked for: the manifests in ~/.codewind/plugins and the PATH
+func pluginOptions() plugins.Options {
+	return plugins.Options{
+		Dir:     filepath.Join(homeDir, ".codewind", "plugins"),
+		PathEnv: os.Getenv("PATH"),
+	}
+}
+
+// pluginCommands returns a command for each plugin the arguments of cwctl may run, skipping plugins named after a
+// command of cwctl, as plugins can only add commands. Like kubectl, plugins are only looked for when help lists the
+// commands, or when the command given is not one of cwctl, so the commands of cwctl do not read the PATH each time.
+func pluginCommands(builtin []cli.Command, globalFlags []cli.Flag, args []string) []cli.Command {
+	taken := map[string]bool{}
+	for _, command := range builtin {
+		for _, name := range command.Names() {
+			taken[name] = true
+		}
+	}
+
+	var found []plugins.Plugin
+	name, given := commandName(globalFlags, args)
+	switch {
+	case !given || name == "help" || name == "h":
+		found, _ = plugins.Discover(pluginOptions())
+	case taken[name]:
+		return []cli.Command{}
+	default:
+		if plugin, ok := plugins.Find(pluginOptions(), name); ok {
+			found = []plugins.Plugin{plugin}
+		}
+	}
+	commands := []cli.Command{}
+	for _, plugin := range found {
+		if taken[plugin.Name] {
+			continue
+		}
+		plugin := plugin
+		usage := plugin.Usage
+		if usage == "" {
+			usage = "Run the " + plugin.Name + " plugin, " + plugin.Path
+		}
+		commands = append(commands, cli.Command{
+			Name:            plugin.Name,
+			Usage:           usage,
+			Category:        "Plugins",
+			SkipFlagParsing: true,
+			Action: func(c *cli.Context) error {
+				RunPlugin(c, plugin)
+				return nil
+			},
+		})
+	}
+	return commands
+}
+
+// commandName returns the command given in the arguments of cwctl, skipping its global flags and their values
+func commandName(globalFlags []cli.Flag, args []string) (string, bool) {
+	takesValue := map[string]bool{}
+	for _, flag := range globalFlags {
+		_, isBool := flag.(cli.BoolFlag)
+		_, isBoolT := flag.(cli.BoolTFlag)
+		for _, name := range strings.Split(flag.GetName(), ",") {
+			takesValue[strings.TrimSpace(name)] = !isBool && !isBoolT
+		}
+	}
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+		switch {
+		case arg == "--":
+			if i+1 < len(args) {
+				return args[i+1], true
+			}
+			return "", false
+		case strings.HasPrefix(arg, "-"):
+			if !strings.Contains(arg, "=") && takesValue[strings.TrimLeft(arg, "-")] {
+				i++
+			}
+		default:
+			return arg, true
+		}
+	}
+	return "", false
+}
+
+// RunPlugin : Runs a plugin with the arguments given after its name, exiting with its exit code. The plugin prints
+// its own output, so no --json document is added to it.
+func RunPlugin(c *cli.Context, plugin plugins.Plugin) {
+	self, _ := os.Executable()
+	env := map[string]string{
+		"CWCTL_BIN":        self,
+		"CWCTL_CONFIG_DIR": connections.GetConnectionConfigDir(),
+		"CWCTL_JSON":       strconv.FormatBool(printAsJSON),
+		"CWCTL_PLUGIN":     plugin.Name,
+	}
+
+	code, err := plugins.Run(plugin, c.Args(), env, os.Stdout)
+	if err != nil {
+		logr.Errorf("Unable to run the %v plugin: %v", plugin.Name, err)
+	}
+	outputWritten = true
+	exit(code)
+}
+
+// PluginList : Lists the plugins found, and the manifests that could not be read
+func PluginList(c *cli.Context) {
+	found, problems := plugins.Discover(pluginOptions())
+	for _, problem := range problems {
+		logr.Warnln("Unable to read plugin manifest " + problem)
+	}
+	if printAsJSON {
+		printResult(found)
+		exit(0)
+	}
+	if len(found) == 0 {
+		fmt.Fprintln(commandOutput(), "No plugins found")
+		exit(0)
+	}
+	tableContent := []string{"NAME\tSOURCE\tPATH"}
+	for _, plugin := range found {
+		tableContent = append(tableContent, plugin.Name+"\t"+plugin.Source+"\t"+plugin.Path)
+	}
+	PrintTable(tableContent)
+	exit(0)
+}
@@ -0,0 +1,52 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func Test_CommandName(t *testing.T) {
+	globalFlags := []cli.Flag{
+		cli.BoolFlag{Name: "json, j"},
+		cli.StringFlag{Name: "loglevel"},
+	}
+	tests := map[string]struct {
+		args    []string
+		command string
+		given   bool
+	}{
+		"command":                       {[]string{"cwctl", "project", "list"}, "project", true},
+		"command after bool flags":      {[]string{"cwctl", "--json", "-j", "registry-login"}, "registry-login", true},
+		"command after a flag value":    {[]string{"cwctl", "--loglevel", "debug", "project"}, "project", true},
+		"command after an inline value": {[]string{"cwctl", "--loglevel=debug", "project"}, "project", true},
+		"command after --":              {[]string{"cwctl", "--", "--odd"}, "--odd", true},
+		"no command":                    {[]string{"cwctl", "--json"}, "", false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			command, given := commandName(globalFlags, test.args)
+			assert.Equal(t, test.command, command)
+			assert.Equal(t, test.given, given)
+		})
+	}
+}
+
+func Test_PluginCommands(t *testing.T) {
+	t.Run("plugins are not looked for when running a command of cwctl", func(t *testing.T) {
+		builtin := []cli.Command{{Name: "project", Aliases: []string{"pj"}}}
+		assert.Empty(t, pluginCommands(builtin, nil, []string{"cwctl", "pj", "list"}))
+	})
+}
@@ -14,14 +14,19 @@ package actions
 import (
 	"encoding/json"
 	"fmt"
-	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
 	"strings"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/templates"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
@@ -32,12 +37,11 @@ import (
 func ProjectValidate(c *cli.Context) {
 	response, projectErr := project.ValidateProject(c)
 	if projectErr != nil {
-		fmt.Println(projectErr.Error())
-		os.Exit(1)
+		printJSONError(projectErr)
+		exit(1)
 	}
-	projectInfo, _ := json.Marshal(response)
-	fmt.Println(string(projectInfo))
-	os.Exit(0)
+	printCompactResult(response)
+	exit(0)
 }
 
 // ProjectCreate : Downloads template, create a new project then validate it
@@ -53,21 +57,35 @@ func ProjectCreate(c *cli.Context) {
 	if err != nil {
 		templateErr := &TemplateError{errOpAddRepo, err, err.Error()}
 		HandleTemplateError(templateErr)
-		os.Exit(1)
+		exit(1)
 	}
 	if gitCredentials == nil {
 		gitCredentials, err = templates.GetGitCredentialsFromKeychain(conID, url)
 		if err != nil {
 			err := &TemplateError{errOpGetGitCredsFromKeychain, err, err.Error()}
 			HandleTemplateError(err)
-			os.Exit(1)
+			exit(1)
 		}
 	}
 
-	result, projErr := project.DownloadTemplate(destination, url, gitCredentials)
+	cloneOptions := utils.GitCloneOptions{
+		Branch:      c.String("branch"),
+		Tag:         c.String("tag"),
+		Commit:      c.String("commit"),
+		SSHKey:      c.String("ssh-key"),
+		Credentials: gitCredentials,
+	}
+	var result *project.Result
+	var projErr *project.ProjectError
+	// Clone with git when a revision or SSH key is given, or the URL is not one that can be downloaded as an archive
+	if cloneOptions.Branch != "" || cloneOptions.Tag != "" || cloneOptions.Commit != "" || cloneOptions.SSHKey != "" || utils.IsGitCloneURL(url) {
+		result, projErr = project.DownloadTemplateFromGit(destination, url, cloneOptions)
+	} else {
+		result, projErr = project.DownloadTemplate(destination, url, gitCredentials)
+	}
 	if projErr != nil {
 		HandleProjectError(projErr)
-		os.Exit(1)
+		exit(1)
 	}
 	if printAsJSON {
 		jsonResponse, _ := json.Marshal(result)
@@ -78,49 +96,150 @@ func ProjectCreate(c *cli.Context) {
 	ProjectValidate(c)
 }
 
-// ProjectSync : Does a project Sync
+// ProjectSync : Does a project Sync, which stops without completing the upload when interrupted
 func ProjectSync(c *cli.Context) {
-	response, err := project.SyncProject(c)
+	if !c.IsSet("time") && c.String("modified-since") == "" {
+		logr.Errorln("Must specify --time, or --modified-since to upload the files modified since a time")
+		exit(1)
+	}
+	modifiedSince, timeErr := parseTimeFlag(c.String("modified-since"), time.Now())
+	if timeErr != nil {
+		logr.Errorf("Invalid --modified-since value: %v\n", timeErr)
+		exit(1)
+	}
+	options := project.SyncOptions{
+		Path:            strings.TrimSpace(c.String("path")),
+		ProjectID:       strings.TrimSpace(c.String("id")),
+		LastSync:        int64(c.Int("time")),
+		ChangeDetection: c.String("detection"),
+		ModifiedSince:   modifiedSince,
+	}
+	if c.Bool("mirror") {
+		if c.Bool("watch") {
+			logr.Errorln("--mirror cannot be used with --watch")
+			exit(1)
+		}
+		projectMirrorSync(options)
+		return
+	}
+	if c.Bool("watch") {
+		projectWatchSync(options, c.Duration("interval"))
+		return
+	}
+	response, err := project.SyncContext(interruptContext(), options)
 	if err != nil {
 		HandleProjectError(err)
-		os.Exit(1)
+		exit(1)
 	} else {
 		if printAsJSON {
-			jsonResponse, _ := json.Marshal(response)
-			fmt.Println(string(jsonResponse))
+			printCompactResult(response)
 		} else {
-			fmt.Println("Status: " + response.Status)
+			fmt.Fprintln(commandOutput(), "Status: "+response.Status)
 		}
 	}
-	os.Exit(0)
+	exit(0)
+}
+
+// projectWatchSync syncs a project each time its files change, printing the outcome of each sync, until interrupted
+func projectWatchSync(options project.SyncOptions, interval time.Duration) {
+	err := project.WatchSync(interruptContext(), options, interval, func(response *project.SyncResponse, err *project.ProjectError) {
+		if err != nil {
+			HandleProjectError(err)
+			return
+		}
+		if printAsJSON {
+			printCompactResult(response)
+		} else {
+			fmt.Fprintf(commandOutput(), "%v Status: %v, %v files uploaded\n", time.Now().Format("15:04:05"), response.Status, len(response.UploadedFiles))
+		}
+	})
+	if err != nil {
+		HandleProjectError(err)
+		exit(1)
+	}
+	exit(0)
 }
 
 // ProjectBind : Does a project bind
 func ProjectBind(c *cli.Context) {
+	if c.String("all") != "" {
+		projectBindAll(c)
+		return
+	}
+	for _, flag := range []string{"name", "language", "type", "path"} {
+		if strings.TrimSpace(c.String(flag)) == "" {
+			logr.Errorln("Must specify --" + flag + ", or --all to bind every project in a directory")
+			exit(1)
+		}
+	}
 	response, err := project.BindProject(c)
 	if err != nil {
 		HandleProjectError(err)
-		os.Exit(1)
+		exit(1)
 	} else {
 		if printAsJSON {
-			jsonResponse, _ := json.Marshal(response)
-			fmt.Println(string(jsonResponse))
+			printCompactResult(response)
 		} else {
-			fmt.Println("Project ID: " + response.ProjectID)
-			fmt.Println("Status: " + response.Status)
+			fmt.Fprintln(commandOutput(), "Project ID: "+response.ProjectID)
+			fmt.Fprintln(commandOutput(), "Status: "+response.Status)
+		}
+	}
+	exit(0)
+}
+
+// projectBindAll : Binds every project found in a directory, printing the outcome for each project. Exits with an
+// error when any project fails to bind.
+func projectBindAll(c *cli.Context) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	result, projErr := project.BindAll(c.String("all"), conID, c.Int("concurrency"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(result)
+	} else if len(result.Projects) == 0 {
+		fmt.Fprintln(commandOutput(), "No projects found in "+c.String("all"))
+	} else {
+		w := new(tabwriter.Writer)
+		w.Init(commandOutput(), 0, 8, 2, '\t', 0)
+		fmt.Fprintln(w, "NAME \tPROJECT ID \tLANGUAGE \tTYPE \tSTATUS \tPATH")
+		for _, bound := range result.Projects {
+			status := bound.Status
+			if bound.Error != "" {
+				status = bound.Error
+			}
+			fmt.Fprintln(w, bound.Name+"\t"+bound.ProjectID+"\t"+bound.Language+"\t"+bound.BuildType+"\t"+status+"\t"+bound.Path)
 		}
+		w.Flush()
+		fmt.Fprintln(commandOutput(), strconv.Itoa(result.Bound)+" bound, "+strconv.Itoa(result.Failed)+" failed")
+	}
+	if result.Failed > 0 {
+		exit(1)
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // ProjectRemove : Does a project remove
 func ProjectRemove(c *cli.Context) {
-	err := project.RemoveProject(c)
+	result, err := project.RemoveProject(c)
 	if err != nil {
 		HandleProjectError(err)
-		os.Exit(1)
+		exit(1)
 	}
-	os.Exit(0)
+	if printAsJSON {
+		printResult(result)
+	} else {
+		fmt.Fprintln(commandOutput(), "Project "+result.ProjectID+" removed from Codewind")
+		fmt.Fprintln(commandOutput(), "Deployment removed: "+strconv.FormatBool(result.DeploymentRemoved))
+		fmt.Fprintln(commandOutput(), "Files on Codewind removed: "+strconv.FormatBool(result.RemoteFilesRemoved))
+		if result.LocalFilesRemoved {
+			fmt.Fprintln(commandOutput(), "Local files removed: "+result.LocalPath)
+		} else {
+			fmt.Fprintln(commandOutput(), "Local files removed: false")
+		}
+	}
+	exit(0)
 }
 
 // UpgradeProjects : Upgrades projects
@@ -129,53 +248,95 @@ func UpgradeProjects(c *cli.Context) {
 	response, err := project.UpgradeProjects(dir)
 	if err != nil {
 		HandleProjectError(err)
-		os.Exit(1)
+		exit(1)
 	}
-	utils.PrettyPrintJSON(response)
-	os.Exit(0)
+	printResult(response)
+	exit(0)
 }
 
-// ProjectList : Print the list of projects to the terminal
-func ProjectList(c *cli.Context) {
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
-
-	conInfo, conInfoErr := connections.GetConnectionByID(conID)
-	if conInfoErr != nil {
-		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+// UpgradeWorkspace : Migrates the projects of a workspace created by an older version of Codewind, printing the
+// outcome for each project. Exits with an error when any project fails to migrate.
+func UpgradeWorkspace(c *cli.Context) {
+	options := project.UpgradeWorkspaceOptions{
+		Workspace: strings.TrimSpace(c.String("workspace")),
+		ConID:     strings.TrimSpace(strings.ToLower(c.String("conid"))),
+		DryRun:    c.Bool("dry-run"),
 	}
-
-	conURL, conErr := config.PFEOriginFromConnection(conInfo)
-	if conErr != nil {
-		HandleConfigError(conErr)
-		os.Exit(1)
+	result, projErr := project.UpgradeWorkspace(options)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
 	}
+	if printAsJSON {
+		printResult(result)
+	} else if len(result.Projects) == 0 {
+		fmt.Fprintln(commandOutput(), "No projects to migrate in "+result.Workspace)
+	} else {
+		rows := []string{"NAME\tPROJECT ID\tLANGUAGE\tTYPE\tSTATUS\tPATH"}
+		for _, upgrade := range result.Projects {
+			status := upgrade.Status
+			if upgrade.Error != "" {
+				status += ": " + upgrade.Error
+			}
+			rows = append(rows, upgrade.Name+"\t"+upgrade.ProjectID+"\t"+upgrade.Language+"\t"+upgrade.BuildType+"\t"+status+"\t"+upgrade.Path)
+		}
+		PrintTable(rows)
+		if !options.DryRun {
+			fmt.Fprintln(commandOutput(), strconv.Itoa(result.Migrated)+" migrated, "+strconv.Itoa(result.Skipped)+" skipped, "+strconv.Itoa(result.Failed)+" failed")
+		}
+	}
+	if result.Failed > 0 {
+		exit(1)
+	}
+	exit(0)
+}
 
-	projects, getAllErr := project.GetAll(http.DefaultClient, conInfo, conURL)
-	if getAllErr != nil {
-		HandleProjectError(getAllErr)
-		os.Exit(1)
+// ProjectList : List projects, with their state on the connection they are bound to
+func ProjectList(c *cli.Context) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	options := project.ListOptions{
+		Language: strings.TrimSpace(c.String("language")),
+		State:    strings.TrimSpace(c.String("state")),
+	}
+	result, projErr := project.ListProjects(conID, options)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
 	}
 
+	allConnections := connections.IsAllConnections(conID)
 	if printAsJSON {
-		json, _ := json.Marshal(projects)
-		fmt.Println(string(json))
-	} else {
-		if len(projects) == 0 {
-			fmt.Println("No projects bound to Codewind")
+		if allConnections {
+			printCompactResult(result)
 		} else {
-			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 0, 8, 2, '\t', 0)
-			fmt.Fprintln(w, "PROJECT ID \tNAME \tLANGUAGE \tAPP STATUS \tLOCATION ON DISK")
-			for _, project := range projects {
-				appStatus := strings.Title(project.AppStatus)
-				fmt.Fprintln(w, project.ProjectID+"\t"+project.Name+"\t"+project.Language+"\t"+appStatus+"\t"+project.LocationOnDisk)
+			printCompactResult(result.Connections[result.SortedIDs()[0]])
+		}
+		exit(0)
+	}
+
+	if !allConnections && len(result.Connections[result.SortedIDs()[0]].([]project.ListedProject)) == 0 {
+		fmt.Fprintln(commandOutput(), "No projects bound to Codewind")
+		exit(0)
+	}
+	w := new(tabwriter.Writer)
+	w.Init(commandOutput(), 0, 8, 2, '\t', 0)
+	fmt.Fprintln(w, "CONNECTION ID \tPROJECT ID \tNAME \tLANGUAGE \tAPP STATUS \tBUILD STATUS \tLAST SYNC \tLOCATION ON DISK")
+	for _, id := range result.SortedIDs() {
+		if errMsg, failed := result.Errors[id]; failed {
+			fmt.Fprintln(w, id+"\t"+errMsg)
+			continue
+		}
+		for _, listed := range result.Connections[id].([]project.ListedProject) {
+			lastSync := "-"
+			if listed.LastSync > 0 {
+				lastSync = time.Unix(0, listed.LastSync*int64(time.Millisecond)).Format("2006-01-02 15:04:05")
 			}
-			fmt.Fprintln(w)
-			w.Flush()
+			fmt.Fprintln(w, id+"\t"+listed.ProjectID+"\t"+listed.Name+"\t"+listed.Language+"\t"+strings.Title(listed.State())+"\t"+strings.Title(listed.BuildStatus)+"\t"+lastSync+"\t"+listed.LocationOnDisk)
 		}
 	}
-	os.Exit(0)
+	fmt.Fprintln(w)
+	w.Flush()
+	exit(0)
 }
 
 // ProjectGet : Prints information about a given project using its ID
@@ -186,14 +347,14 @@ func ProjectGet(c *cli.Context) {
 
 	if projectID == "" && projectName == "" {
 		logr.Errorln("Must specify either project ID (--id) or project name (--name)")
-		os.Exit(1)
+		exit(1)
 	}
 
 	if projectID != "" && (conID == "local" || conID == "") {
 		newConID, conIDErr := project.GetConnectionID(projectID)
 		if conIDErr != nil {
 			HandleProjectError(conIDErr)
-			os.Exit(1)
+			exit(1)
 		}
 		conID = newConID
 	}
@@ -201,41 +362,40 @@ func ProjectGet(c *cli.Context) {
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	var projectObj *project.Project
 	var projectErr *project.ProjectError
 	if projectID == "" && projectName != "" {
-		projectObj, projectErr = project.GetProjectFromName(http.DefaultClient, conInfo, conURL, projectName)
+		projectObj, projectErr = project.GetProjectFromName(sechttp.Client(), conInfo, conURL, projectName)
 	} else {
-		projectObj, projectErr = project.GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
+		projectObj, projectErr = project.GetProjectFromID(sechttp.Client(), conInfo, conURL, projectID)
 	}
 
 	if projectErr != nil {
 		HandleProjectError(projectErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	if printAsJSON {
-		json, _ := json.Marshal(projectObj)
-		fmt.Println(string(json))
+		printCompactResult(projectObj)
 	} else {
 		w := new(tabwriter.Writer)
-		w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+		w.Init(commandOutput(), 0, 8, 2, '\t', 0)
 		fmt.Fprintln(w, "PROJECT ID \tNAME \tLANGUAGE \tAPP STATUS \tLOCATION ON DISK")
 		appStatus := strings.Title(projectObj.AppStatus)
 		fmt.Fprintln(w, projectObj.ProjectID+"\t"+projectObj.Name+"\t"+projectObj.Language+"\t"+appStatus+"\t"+projectObj.LocationOnDisk)
 		fmt.Fprintln(w)
 		w.Flush()
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // ProjectRestart : restarts a project
@@ -243,28 +403,275 @@ func ProjectRestart(c *cli.Context) {
 	projectID := strings.TrimSpace(strings.ToLower(c.String("id")))
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
 	startMode := strings.TrimSpace(c.String("startmode"))
+	debug := c.Bool("debug")
+
+	if debug && startMode == "" {
+		startMode = "debug"
+	}
+	if startMode == "" {
+		logr.Errorln("Must specify either a start mode (--startmode) or --debug")
+		exit(1)
+	}
+	if debug && !project.IsDebugStartMode(startMode) {
+		logr.Errorln("--debug requires the debug or debugNoInit start mode")
+		exit(1)
+	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	err := project.RestartProject(http.DefaultClient, conInfo, conURL, projectID, startMode)
+	err := project.RestartProject(sechttp.Client(), conInfo, conURL, projectID, startMode)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
+	}
+
+	if !debug {
+		printCompactResult(project.Result{Status: "OK", StatusMessage: "Project restart request accepted"})
+		exit(0)
+	}
+
+	target, projErr := project.WaitForDebugTarget(sechttp.Client(), conInfo, conURL, projectID, c.Duration("timeout"))
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if target.PodName == "" {
+		printDebugAddress(projectID, target.Host+":"+strconv.Itoa(target.Port), "Project restarted in debug mode")
+		exit(0)
 	}
 
-	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project restart request accepted"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	forwardPodPort(target, c.Int("local-port"), c.Duration("timeout"), func(localPort int) {
+		printDebugAddress(projectID, "localhost:"+strconv.Itoa(localPort), "Forwarding to the debug port of pod "+target.PodName+", press Ctrl+C to stop")
+	})
+}
+
+// forwardPodPort : Forwards a local port to the pod port of a remote project until interrupted, then exits
+func forwardPodPort(target *project.PortTarget, localPort int, timeout time.Duration, ready func(localPort int)) {
+	stop := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		close(stop)
+	}()
+	forwardOptions := remote.PortForwardOptions{
+		Namespace: target.Namespace,
+		PodName:   target.PodName,
+		PodPort:   target.Port,
+		LocalPort: localPort,
+		Timeout:   timeout,
+	}
+	remInstErr := remote.ForwardPodPort(forwardOptions, stop, ready)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+	exit(0)
+}
+
+// printDebugAddress : Prints the address an IDE attaches its debugger to
+func printDebugAddress(projectID string, address string, message string) {
+	if printAsJSON {
+		printCompactResult(project.DebugResult{Status: "OK", StatusMessage: message, ProjectID: projectID, DebugAddress: address})
+		return
+	}
+	fmt.Fprintln(commandOutput(), message)
+	fmt.Fprintln(commandOutput(), "Debug address: "+address)
+}
+
+// ProjectRename : Renames a project, given its ID and new name as arguments
+func ProjectRename(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.Args().Get(0)))
+	newName := strings.TrimSpace(c.Args().Get(1))
+	if projectID == "" || newName == "" {
+		logr.Errorln("Must specify a project ID and a new name")
+		exit(1)
+	}
+	conInfo, conURL := projectConnection(c, projectID)
+
+	result, projErr := project.RenameProject(sechttp.Client(), conInfo, conURL, projectID, newName)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printCompactResult(result)
+	} else {
+		fmt.Fprintln(commandOutput(), result.StatusMessage)
+	}
+	exit(0)
+}
+
+// ProjectSettingsSet : Updates settings in the .cw-settings of a project, given its ID and key=value updates as arguments
+func ProjectSettingsSet(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.Args().First()))
+	if projectID == "" || len(c.Args().Tail()) == 0 {
+		logr.Errorln("Must specify a project ID and at least one setting to update")
+		exit(1)
+	}
+	updates := []project.SettingsUpdate{}
+	for _, arg := range c.Args().Tail() {
+		update, projErr := project.ParseSettingsUpdate(arg)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			exit(1)
+		}
+		updates = append(updates, update)
+	}
+	conInfo, conURL := projectConnection(c, projectID)
+
+	projectPath := strings.TrimSpace(c.String("path"))
+	if projectPath == "" {
+		projectInfo, projErr := project.GetProjectFromID(sechttp.Client(), conInfo, conURL, projectID)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			exit(1)
+		}
+		projectPath = projectInfo.LocationOnDisk
+	}
+
+	result, projErr := project.UpdateProjectSettings(sechttp.Client(), conInfo, conURL, projectID, projectPath, updates)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if printAsJSON {
+		printCompactResult(result)
+	} else {
+		fmt.Fprintln(commandOutput(), result.StatusMessage+" in "+result.SettingsFile)
+	}
+	exit(0)
+}
+
+// ProjectPortForward : Makes the application of a project reachable on localhost. Remote projects are reached by
+// forwarding a local port to their pod until interrupted, local projects already expose their application port.
+func ProjectPortForward(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.Args().First()))
+	if projectID == "" {
+		projectID = strings.TrimSpace(strings.ToLower(c.String("id")))
+	}
+	if projectID == "" {
+		logr.Errorln("Must specify a project ID")
+		exit(1)
+	}
+	conInfo, conURL := projectConnection(c, projectID)
+
+	target, projErr := project.GetAppTarget(sechttp.Client(), conInfo, conURL, projectID)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	if target.PodName == "" {
+		printAppAddress(projectID, target.Host+":"+strconv.Itoa(target.Port), "Local projects expose their application port, no forwarding is needed")
+		exit(0)
+	}
+
+	forwardPodPort(target, c.Int("local-port"), c.Duration("timeout"), func(localPort int) {
+		printAppAddress(projectID, "localhost:"+strconv.Itoa(localPort), "Forwarding to port "+strconv.Itoa(target.Port)+" of pod "+target.PodName+", press Ctrl+C to stop")
+	})
+}
+
+// printAppAddress : Prints the address the application of a project is reached at
+func printAppAddress(projectID string, address string, message string) {
+	if printAsJSON {
+		printCompactResult(project.PortForwardResult{Status: "OK", StatusMessage: message, ProjectID: projectID, AppAddress: address})
+		return
+	}
+	fmt.Fprintln(commandOutput(), message)
+	fmt.Fprintln(commandOutput(), "Application address: http://"+address)
+}
+
+// ProjectLogs : Prints the build and app logs of a project, following them when asked until interrupted
+func ProjectLogs(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.Args().First()))
+	if projectID == "" {
+		projectID = strings.TrimSpace(strings.ToLower(c.String("id")))
+	}
+	if projectID == "" {
+		logr.Errorln("Must specify a project ID")
+		exit(1)
+	}
+
+	conInfo, conURL := projectConnection(c, projectID)
+	logOptions := project.LogOptions{
+		Type:   strings.TrimSpace(strings.ToLower(c.String("type"))),
+		Follow: c.Bool("follow"),
+	}
+	projErr := project.StreamLogs(sechttp.Client(), conInfo, conURL, projectID, logOptions, commandOutput(), nil)
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	exit(0)
+}
+
+// ProjectBuild : Builds a project, waiting for the build to finish and printing its output when --wait is set
+func ProjectBuild(c *cli.Context) {
+	projectID := strings.TrimSpace(strings.ToLower(c.Args().First()))
+	if projectID == "" {
+		projectID = strings.TrimSpace(strings.ToLower(c.String("id")))
+	}
+	if projectID == "" {
+		logr.Errorln("Must specify a project ID")
+		exit(1)
+	}
+
+	conInfo, conURL := projectConnection(c, projectID)
+	if !c.Bool("wait") {
+		projErr := project.BuildProject(sechttp.Client(), conInfo, conURL, projectID, c.Bool("clean"))
+		if projErr != nil {
+			HandleProjectError(projErr)
+			exit(1)
+		}
+		printResult(project.Result{Status: "OK", StatusMessage: "Project build requested"})
+		exit(0)
+	}
+
+	result, projErr := project.BuildAndWait(sechttp.Client(), conInfo, conURL, projectID, c.Bool("clean"), c.Duration("timeout"), commandOutput())
+	if projErr != nil {
+		HandleProjectError(projErr)
+		exit(1)
+	}
+	printResult(result)
+	exit(0)
+}
+
+// projectConnection : Returns the connection given by --conid, or the connection the project is bound to, and the
+// URL of its PFE
+func projectConnection(c *cli.Context, projectID string) (*connections.Connection, string) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	if conID == "" {
+		var projErr *project.ProjectError
+		conID, projErr = project.GetConnectionID(projectID)
+		if projErr != nil {
+			HandleProjectError(projErr)
+			exit(1)
+		}
+	}
+
+	conInfo, conInfoErr := connections.GetConnectionByID(conID)
+	if conInfoErr != nil {
+		HandleConnectionError(conInfoErr)
+		exit(1)
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(conInfo)
+	if conErr != nil {
+		HandleConfigError(conErr)
+		exit(1)
+	}
+	checkConnectionCompatibility(c, conInfo, conURL)
+	return conInfo, conURL
 }
 
 // ProjectLinkList : lists all the links for a project
@@ -274,36 +681,35 @@ func ProjectLinkList(c *cli.Context) {
 	conID, getConnectionIDErr := project.GetConnectionID(projectID)
 	if getConnectionIDErr != nil {
 		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	links, projectLinkErr := project.GetProjectLinks(http.DefaultClient, conInfo, conURL, projectID)
+	links, projectLinkErr := project.GetProjectLinks(sechttp.Client(), conInfo, conURL, projectID)
 	if projectLinkErr != nil {
 		HandleProjectError(projectLinkErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	if printAsJSON {
-		json, _ := json.Marshal(links)
-		fmt.Println(string(json))
+		printCompactResult(links)
 	} else {
 		if len(links) == 0 {
-			fmt.Println("Project has no links")
+			fmt.Fprintln(commandOutput(), "Project has no links")
 		} else {
 			w := new(tabwriter.Writer)
-			w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+			w.Init(commandOutput(), 0, 8, 2, '\t', 0)
 			fmt.Fprintln(w, "TARGET PROJECT \tENVIRONMENT VARIABLE \t TARGET URL")
 			for _, project := range links {
 				fmt.Fprintln(w, project.ProjectName+"\t"+project.EnvName+"\t"+project.ProjectURL)
@@ -312,7 +718,7 @@ func ProjectLinkList(c *cli.Context) {
 			w.Flush()
 		}
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // ProjectLinkCreate : creates a new link
@@ -324,30 +730,35 @@ func ProjectLinkCreate(c *cli.Context) {
 	conID, getConnectionIDErr := project.GetConnectionID(projectID)
 	if getConnectionIDErr != nil {
 		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
+		exit(1)
+	}
+
+	linkErr := project.ValidateProjectLink(conID, projectID, targetProjectID, envName)
+	if linkErr != nil {
+		HandleProjectError(linkErr)
+		exit(1)
 	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	projectLinkErr := project.CreateProjectLink(http.DefaultClient, conInfo, conURL, projectID, targetProjectID, envName)
+	projectLinkErr := project.CreateProjectLink(sechttp.Client(), conInfo, conURL, projectID, targetProjectID, envName)
 	if projectLinkErr != nil {
 		HandleProjectError(projectLinkErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project link create request accepted"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	printCompactResult(project.Result{Status: "OK", StatusMessage: "Project link create request accepted"})
+	exit(0)
 }
 
 // ProjectLinkUpdate : updates a link
@@ -356,33 +767,38 @@ func ProjectLinkUpdate(c *cli.Context) {
 	envName := strings.TrimSpace(c.String("env"))
 	updatedEnvName := strings.TrimSpace(c.String("newEnv"))
 
+	linkErr := project.ValidateLinkEnvName(updatedEnvName)
+	if linkErr != nil {
+		HandleProjectError(linkErr)
+		exit(1)
+	}
+
 	conID, getConnectionIDErr := project.GetConnectionID(projectID)
 	if getConnectionIDErr != nil {
 		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	projectLinkErr := project.UpdateProjectLink(http.DefaultClient, conInfo, conURL, projectID, envName, updatedEnvName)
+	projectLinkErr := project.UpdateProjectLink(sechttp.Client(), conInfo, conURL, projectID, envName, updatedEnvName)
 	if projectLinkErr != nil {
 		HandleProjectError(projectLinkErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project link update request accepted"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	printCompactResult(project.Result{Status: "OK", StatusMessage: "Project link update request accepted"})
+	exit(0)
 }
 
 // ProjectLinkDelete : deletes a link
@@ -393,28 +809,27 @@ func ProjectLinkDelete(c *cli.Context) {
 	conID, getConnectionIDErr := project.GetConnectionID(projectID)
 	if getConnectionIDErr != nil {
 		HandleProjectError(getConnectionIDErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	projectLinkErr := project.DeleteProjectLink(http.DefaultClient, conInfo, conURL, projectID, envName)
+	projectLinkErr := project.DeleteProjectLink(sechttp.Client(), conInfo, conURL, projectID, envName)
 	if projectLinkErr != nil {
 		HandleProjectError(projectLinkErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	response, _ := json.Marshal(project.Result{Status: "OK", StatusMessage: "Project link delete request accepted"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	printCompactResult(project.Result{Status: "OK", StatusMessage: "Project link delete request accepted"})
+	exit(0)
 }
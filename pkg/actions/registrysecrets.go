@@ -12,7 +12,6 @@
 package actions
 
 import (
-	"net/http"
 	"os"
 	"strings"
 
@@ -20,7 +19,7 @@ import (
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
-	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/urfave/cli"
 )
 
@@ -28,13 +27,13 @@ import (
 func GetRegistrySecrets(c *cli.Context) {
 	conInfo, conURL := getConnectionDetailsOrExit(c)
 
-	registrySecrets, err := apiroutes.GetRegistrySecrets(conInfo, conURL, http.DefaultClient)
+	registrySecrets, err := apiroutes.GetRegistrySecrets(conInfo, conURL, sechttp.Client())
 	if err != nil {
 		registryErr := &RegistryError{errOpListRegistries, err, err.Error()}
 		HandleRegistryError(registryErr)
-		os.Exit(1)
+		exit(1)
 	}
-	utils.PrettyPrintJSON(registrySecrets)
+	printResult(registrySecrets)
 }
 
 // AddRegistrySecret : Set a docker registry secret.
@@ -69,15 +68,15 @@ func AddRegistrySecret(c *cli.Context) {
 		dockerErr := docker.AddDockerCredential(conInfo.ID, localAddress, username, password)
 		if dockerErr != nil {
 			HandleDockerError(dockerErr)
-			os.Exit(1)
+			exit(1)
 		}
 	}
 
-	registrySecrets, err := apiroutes.AddRegistrySecret(conInfo, conURL, http.DefaultClient, address, username, password)
+	registrySecrets, err := apiroutes.AddRegistrySecret(conInfo, conURL, sechttp.Client(), address, username, password)
 	if err != nil {
 		registryErr := &RegistryError{errOpAddRegistry, err, err.Error()}
 		HandleRegistryError(registryErr)
-		os.Exit(1)
+		exit(1)
 	}
 	if dockerErr != nil {
 		for i, registry := range *registrySecrets {
@@ -87,7 +86,7 @@ func AddRegistrySecret(c *cli.Context) {
 		}
 	}
 
-	utils.PrettyPrintJSON(registrySecrets)
+	printResult(registrySecrets)
 }
 
 // RemoveRegistrySecret : Delete a docker registry secret.
@@ -96,11 +95,11 @@ func RemoveRegistrySecret(c *cli.Context) {
 
 	address := strings.TrimSpace(c.String("address"))
 
-	registrySecrets, err := apiroutes.RemoveRegistrySecret(conInfo, conURL, http.DefaultClient, address)
+	registrySecrets, err := apiroutes.RemoveRegistrySecret(conInfo, conURL, sechttp.Client(), address)
 	if err != nil {
 		registryErr := &RegistryError{errOpRemoveRegistry, err, err.Error()}
 		HandleRegistryError(registryErr)
-		os.Exit(1)
+		exit(1)
 	}
 	// Remove secret from our keychain entry.
 	// (But don't logout of docker locally.)
@@ -114,10 +113,10 @@ func RemoveRegistrySecret(c *cli.Context) {
 		dockerErr := docker.RemoveDockerCredential(conInfo.ID, localAddress)
 		if dockerErr != nil {
 			HandleDockerError(dockerErr)
-			os.Exit(1)
+			exit(1)
 		}
 	}
-	utils.PrettyPrintJSON(registrySecrets)
+	printResult(registrySecrets)
 }
 
 func getConnectionDetailsOrExit(c *cli.Context) (*connections.Connection, string) {
@@ -126,13 +125,13 @@ func getConnectionDetailsOrExit(c *cli.Context) (*connections.Connection, string
 	conInfo, conInfoErr := connections.GetConnectionByID(connectionID)
 	if conInfoErr != nil {
 		HandleConnectionError(conInfoErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	conURL, conErr := config.PFEOriginFromConnection(conInfo)
 	if conErr != nil {
 		HandleConfigError(conErr)
-		os.Exit(1)
+		exit(1)
 	}
 	return conInfo, conURL
 }
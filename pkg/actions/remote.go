@@ -0,0 +1,274 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// RemoteMaintenance : Turns maintenance mode on or off for a remote deployment
+func RemoteMaintenance(c *cli.Context, enable bool) {
+	maintenanceOptions := remote.MaintenanceOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		Enable:      enable,
+	}
+
+	remInstErr := remote.SetMaintenanceMode(&maintenanceOptions, nil)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+
+	statusMessage := "Maintenance mode disabled"
+	if enable {
+		statusMessage = "Maintenance mode enabled"
+	}
+	if printAsJSON {
+		printCompactResult(remote.Result{Status: "OK", StatusMessage: statusMessage})
+	} else {
+		logr.Infoln(statusMessage)
+	}
+	exit(0)
+}
+
+// RemoteLogs : Prints or follows the logs of a remote Codewind component
+func RemoteLogs(c *cli.Context) {
+	var since time.Duration
+	if c.String("since") != "" {
+		var err error
+		since, err = time.ParseDuration(c.String("since"))
+		if err != nil {
+			logr.Errorf("Invalid --since value: %v\n", err)
+			exit(1)
+		}
+	}
+
+	logOptions := remote.LogOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		Component:   c.String("component"),
+		Follow:      c.Bool("follow"),
+		Since:       since,
+		Tail:        c.Int64("tail"),
+	}
+
+	remInstErr := remote.StreamLogs(&logOptions, nil, commandOutput())
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+	exit(0)
+}
+
+// RemoteAudit : Prints the requests made to a remote deployment over a window, and the users that made them
+func RemoteAudit(c *cli.Context) {
+	now := time.Now()
+	since, err := parseTimeFlag(c.String("since"), now)
+	if err != nil {
+		logr.Errorf("Invalid --since value: %v\n", err)
+		exit(1)
+	}
+	until, err := parseTimeFlag(c.String("until"), now)
+	if err != nil {
+		logr.Errorf("Invalid --until value: %v\n", err)
+		exit(1)
+	}
+
+	auditOptions := remote.AuditOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		Since:       since,
+		Until:       until,
+		User:        c.String("user"),
+	}
+	report, remInstErr := remote.AuditGatekeeper(&auditOptions, nil)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+	if c.Bool("summary") {
+		report.Entries = nil
+	}
+	if printAsJSON {
+		printResult(report)
+		exit(0)
+	}
+
+	rows := []string{"USER\tREQUESTS\tDENIED\tFIRST SEEN\tLAST SEEN"}
+	for _, user := range report.Users {
+		rows = append(rows, user.User+"\t"+strconv.Itoa(user.Requests)+"\t"+strconv.Itoa(user.Denied)+"\t"+user.FirstSeen.Format(time.RFC3339)+"\t"+user.LastSeen.Format(time.RFC3339))
+	}
+	PrintTable(rows)
+	if len(report.Entries) > 0 {
+		fmt.Fprintln(commandOutput())
+		rows = []string{"TIME\tUSER\tCLIENT\tMETHOD\tPATH\tSTATUS"}
+		for _, entry := range report.Entries {
+			user := entry.User
+			if user == "" {
+				user = remote.AnonymousUser
+			}
+			rows = append(rows, entry.Time.Format(time.RFC3339)+"\t"+user+"\t"+entry.Client+"\t"+entry.Method+"\t"+entry.Path+"\t"+strconv.Itoa(entry.Status))
+		}
+		PrintTable(rows)
+	}
+	exit(0)
+}
+
+// RemoteScale : Sets the replicas of one, or all, of the components of a remote deployment
+func RemoteScale(c *cli.Context, component string, replicas int) {
+	scaleOptions := remote.ScaleOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		Component:   component,
+		Replicas:    int32(replicas),
+	}
+
+	scaleRemote(&scaleOptions, "Scaled "+scaleOptions.WorkspaceID+" to "+strconv.Itoa(replicas)+" replicas")
+}
+
+// RemoteStart : Resumes a paused remote deployment, scaling each of its components back to the replicas it was installed with
+func RemoteStart(c *cli.Context) {
+	scaleOptions := remote.ScaleOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		Installed:   true,
+	}
+	scaleRemote(&scaleOptions, "Scaled "+scaleOptions.WorkspaceID+" to its installed replicas")
+}
+
+func scaleRemote(scaleOptions *remote.ScaleOptions, statusMessage string) {
+	remInstErr := remote.ScaleRemote(scaleOptions, nil)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+
+	if printAsJSON {
+		printCompactResult(remote.Result{Status: "OK", StatusMessage: statusMessage})
+	} else {
+		logr.Infoln(statusMessage)
+	}
+	exit(0)
+}
+
+// RemoteBackup : Copies the workspace PVC of a remote deployment to, or from, a local file
+func RemoteBackup(c *cli.Context, restore bool) {
+	backupOptions := remote.BackupOptions{
+		Namespace:   c.String("namespace"),
+		WorkspaceID: c.String("workspace"),
+		File:        c.String("file"),
+	}
+
+	var remInstErr *remote.RemInstError
+	statusMessage := "Workspace backed up to " + backupOptions.File
+	if restore {
+		remInstErr = remote.RestoreWorkspace(&backupOptions)
+		statusMessage = "Workspace restored from " + backupOptions.File
+	} else {
+		remInstErr = remote.BackupWorkspace(&backupOptions)
+	}
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+
+	if printAsJSON {
+		printCompactResult(remote.Result{Status: "OK", StatusMessage: statusMessage})
+	} else {
+		logr.Infoln(statusMessage)
+	}
+	exit(0)
+}
+
+// rotateSecretsOutput : The result of a secret rotation, with the local connections it affected
+type rotateSecretsOutput struct {
+	*remote.RotateSecretsResult
+	Refreshed     []string `json:"refreshedConnections"`
+	LoginRequired []string `json:"loginRequiredConnections"`
+}
+
+// RemoteRotateSecrets : Rotates the Keycloak and Gatekeeper secrets of a remote deployment, then refreshes the
+// credentials of the local connections to it
+func RemoteRotateSecrets(c *cli.Context) {
+	rotateOptions := remote.RotateSecretsOptions{
+		Namespace:        c.String("namespace"),
+		WorkspaceID:      c.String("workspace"),
+		AdminPassword:    c.Bool("admin-password"),
+		KeycloakUser:     c.String("kadminuser"),
+		KeycloakPassword: c.String("kadminpass"),
+		Timeout:          c.Duration("timeout"),
+	}
+
+	result, remInstErr := remote.RotateSecrets(&rotateOptions, nil, http.DefaultClient)
+	if remInstErr != nil {
+		HandleRemInstError(remInstErr)
+		exit(1)
+	}
+
+	refreshed, loginRequired := refreshRotatedConnections(result.GatekeeperURL)
+	output := rotateSecretsOutput{RotateSecretsResult: result, Refreshed: refreshed, LoginRequired: loginRequired}
+	if printAsJSON {
+		printCompactResult(output)
+		exit(0)
+	}
+	logr.Infof("Rotated %v and restarted %v\n", strings.Join(result.Rotated, ", "), strings.Join(result.Restarted, ", "))
+	if rotateOptions.AdminPassword {
+		logr.Infof("The new Keycloak admin password is in secret 'secret-keycloak-user-%v'\n", rotateOptions.WorkspaceID)
+	}
+	for _, connectionID := range output.LoginRequired {
+		logr.Warnf("Unable to refresh the credentials of connection %v, run cwctl seclogin --conid %v\n", connectionID, connectionID)
+	}
+	exit(0)
+}
+
+// refreshRotatedConnections replaces the cached tokens of the local connections to a Gatekeeper with ones issued after
+// its secrets were rotated. Connections whose refresh token is missing or rejected must log in again.
+func refreshRotatedConnections(gatekeeperURL string) ([]string, []string) {
+	refreshed, loginRequired := []string{}, []string{}
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		logr.Warnf("Unable to read the local connections: %v\n", conErr.Desc)
+		return refreshed, loginRequired
+	}
+	for _, connection := range allConnections {
+		if strings.TrimSuffix(connection.URL, "/") != gatekeeperURL {
+			continue
+		}
+		httpClient, httpSecErr := sechttp.ConnectionHTTPClient(http.DefaultClient, &connection)
+		if httpSecErr != nil {
+			loginRequired = append(loginRequired, connection.ID)
+			continue
+		}
+		refreshToken, secErr := security.SecKeyGetSecret(connection.ID, "refresh_token")
+		if secErr == nil {
+			_, secErr = security.SecRefreshAccessToken(httpClient, &connection, refreshToken)
+		}
+		if secErr != nil {
+			loginRequired = append(loginRequired, connection.ID)
+			continue
+		}
+		refreshed = append(refreshed, connection.ID)
+	}
+	return refreshed, loginRequired
+}
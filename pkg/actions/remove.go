@@ -14,78 +14,124 @@ package actions
 import (
 	"fmt"
 	"os"
-	"strings"
+	"path"
 
 	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/remote"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
-//RemoveCommand to remove all codewind and project images
+//RemoveCommand to remove all codewind and project images, and the workspace volume and Codewind directory when asked
 func RemoveCommand(c *cli.Context, dockerComposeFile string) {
 	tag := c.String("tag")
 	if tag == "" {
 		tag = "latest"
 	}
-	imageArr := []string{
-		"cw-",
+	options := docker.RemovalOptions{
+		Tags:    append([]string{tag}, c.StringSlice("images")...),
+		Volumes: c.Bool("volumes"),
+	}
+	if c.Bool("purge") {
+		options.CodewindDir = path.Dir(dockerComposeFile)
 	}
 
 	dockerClient, dockerErr := docker.NewDockerClient()
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	images, err := docker.GetImageList(dockerClient)
-	if err != nil {
-		HandleDockerError(err)
-		os.Exit(1)
+	targets, dockerErr := docker.PlanLocalRemoval(dockerClient, options)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
 	}
 
-	fmt.Println("Removing Codewind docker images..")
-
-	for _, image := range images {
-		imageRepo := strings.Join(image.RepoDigests, " ")
-		imageTags := strings.Join(image.RepoTags, " ")
-		for _, key := range imageArr {
-			if strings.HasPrefix(imageRepo, key) || strings.HasPrefix(imageTags, key) {
-				if len(image.RepoTags) > 0 {
-					fmt.Println("Deleting Image ", image.RepoTags[0], "... ")
-				} else {
-					fmt.Println("Deleting Image ", image.ID, "... ")
-				}
-				docker.RemoveImage(image.ID)
+	if c.Bool("dry-run") {
+		if printAsJSON {
+			printResult(targets)
+		} else if len(targets) == 0 {
+			fmt.Fprintln(commandOutput(), "Nothing would be removed")
+		} else {
+			fmt.Fprintln(commandOutput(), "Would remove:")
+			for _, target := range targets {
+				fmt.Fprintf(commandOutput(), "  %-9s %s\n", target.Kind, target.Name)
 			}
 		}
+		exit(0)
 	}
 
+	// Compose removes the containers and the images of the tag, so the volume and other images are no longer in use
 	dockerErr = docker.DockerComposeRemove(dockerComposeFile, tag)
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
+	}
+
+	fmt.Fprintln(commandOutput(), "Removing Codewind docker images..")
+	images, dockerErr := docker.GetImageList(dockerClient)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+	remaining := map[string]bool{}
+	for _, image := range images {
+		remaining[image.ID] = true
+	}
+	for _, target := range targets {
+		if target.Kind != docker.TargetImage || !remaining[target.ID] {
+			continue
+		}
+		fmt.Fprintln(commandOutput(), "Deleting Image ", target.Name, "... ")
+		if dockerErr = docker.RemoveImage(target.ID); dockerErr != nil {
+			HandleDockerError(dockerErr)
+		}
+	}
+
+	for _, target := range targets {
+		switch target.Kind {
+		case docker.TargetVolume:
+			fmt.Fprintln(commandOutput(), "Deleting Volume ", target.Name, "... ")
+			dockerErr = docker.RemoveVolume(dockerClient, target.Name)
+			if dockerErr != nil {
+				HandleDockerError(dockerErr)
+				exit(1)
+			}
+		case docker.TargetPath:
+			fmt.Fprintln(commandOutput(), "Deleting ", target.Name, "... ")
+			if err := os.RemoveAll(target.Name); err != nil {
+				printJSONError(err)
+				exit(1)
+			}
+		}
 	}
 }
 
 // DoRemoteRemove : Delete a remote Codewind deployment
 func DoRemoteRemove(c *cli.Context) {
+	if c.String("workspace") == "" && c.String("install-id") == "" {
+		logr.Error("Either --workspace or --install-id must be set")
+		exit(1)
+	}
 	removeOptions := remote.RemoveDeploymentOptions{
 		Namespace:   c.String("namespace"),
 		WorkspaceID: c.String("workspace"),
+		InstallID:   c.String("install-id"),
 	}
 
 	_, remInstError := remote.RemoveRemote(&removeOptions)
 	if remInstError != nil {
 		if printAsJSON {
-			fmt.Println(remInstError.Error())
+			printJSONError(remInstError)
 		} else {
-			logr.Errorf("Error: %v - %v\n", remInstError.Op, remInstError.Desc)
+			logr.Errorf("Error: %v - %v\n", remInstError.Op, i18n.Describe(remInstError.Err, remInstError.Desc))
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
-	os.Exit(0)
+	exit(0)
 }
 
 // DoRemoteKeycloakRemove : Delete a remote Keycloak deployment
@@ -93,16 +139,17 @@ func DoRemoteKeycloakRemove(c *cli.Context) {
 	removeOptions := remote.RemoveDeploymentOptions{
 		Namespace:   c.String("namespace"),
 		WorkspaceID: c.String("workspace"),
+		Force:       c.Bool("force"),
 	}
 
 	_, remInstError := remote.RemoveRemoteKeycloak(&removeOptions)
 	if remInstError != nil {
 		if printAsJSON {
-			fmt.Println(remInstError.Error())
+			printJSONError(remInstError)
 		} else {
-			logr.Errorf("Error: %v - %v\n", remInstError.Op, remInstError.Desc)
+			logr.Errorf("Error: %v - %v\n", remInstError.Op, i18n.Describe(remInstError.Err, remInstError.Desc))
 		}
-		os.Exit(1)
+		exit(1)
 	}
-	os.Exit(0)
+	exit(0)
 }
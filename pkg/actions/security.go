@@ -13,13 +13,18 @@ package actions
 
 import (
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io/ioutil"
 	"net/http"
-	"os"
+	"strconv"
 	"strings"
+	"text/tabwriter"
 
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/security"
-	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
@@ -27,140 +32,387 @@ import (
 func SecurityTokenGet(c *cli.Context) {
 	auth, err := security.SecAuthenticate(http.DefaultClient, c, "", "")
 	if err == nil && auth != nil {
-		utils.PrettyPrintJSON(auth)
+		printResult(auth)
 	} else {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
-	os.Exit(0)
+	exit(0)
+}
+
+// SecurityLogin : Log in to a connection and cache its tokens. With --browser or --device-flow the user logs in
+// using a browser, so no password is entered into the CLI or stored in the keyring.
+func SecurityLogin(c *cli.Context) {
+	connection, conErr := connections.GetConnectionByID(strings.TrimSpace(c.String("conid")))
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+	httpClient, transportErr := sechttp.ConnectionHTTPClient(http.DefaultClient, connection)
+	if transportErr != nil {
+		printJSONError(transportErr)
+		exit(1)
+	}
+
+	if c.Bool("browser") {
+		_, secErr := security.SecPKCELogin(httpClient, connection, func(loginURL string) {
+			if printAsJSON {
+				printCompactResult(map[string]string{"login_url": loginURL})
+			} else {
+				logr.Printf("To log in to connection %v, open %v", strings.ToUpper(connection.ID), loginURL)
+			}
+		})
+		if secErr != nil {
+			printJSONError(secErr)
+			exit(1)
+		}
+	} else if c.Bool("device-flow") {
+		authorization, secErr := security.SecDeviceAuthorize(httpClient, connection)
+		if secErr != nil {
+			printJSONError(secErr)
+			exit(1)
+		}
+		if printAsJSON {
+			printCompactResult(authorization)
+		} else {
+			logr.Printf("To log in to connection %v, open %v and enter the code %v", strings.ToUpper(connection.ID), authorization.VerificationURI, authorization.UserCode)
+		}
+		_, secErr = security.SecDevicePollToken(httpClient, connection, authorization)
+		if secErr != nil {
+			printJSONError(secErr)
+			exit(1)
+		}
+	} else {
+		username := strings.TrimSpace(c.String("username"))
+		if username == "" {
+			username = connection.Username
+		}
+		set := flag.NewFlagSet("Authentication", 0)
+		set.String("username", username, "doc")
+		set.String("password", c.String("password"), "doc")
+		set.String("conid", connection.ID, "doc")
+		_, secErr := security.SecAuthenticate(httpClient, cli.NewContext(nil, set, nil), "", "")
+		if secErr != nil {
+			printJSONError(secErr)
+			exit(1)
+		}
+	}
+
+	if printAsJSON {
+		printResult(security.Result{Status: "OK"})
+	} else {
+		logr.Printf("Logged in to connection %v", strings.ToUpper(connection.ID))
+	}
+	exit(0)
+}
+
+// SecurityLogout : End the session of a connection, revoking its refresh token and removing its cached tokens
+func SecurityLogout(c *cli.Context) {
+	connection, conErr := connections.GetConnectionByID(strings.TrimSpace(c.String("conid")))
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+	httpClient, transportErr := sechttp.ConnectionHTTPClient(http.DefaultClient, connection)
+	if transportErr != nil {
+		printJSONError(transportErr)
+		exit(1)
+	}
+
+	revokeErr, secErr := security.SecLogout(httpClient, connection, c.Bool("forget-password"))
+	if secErr != nil {
+		printJSONError(secErr)
+		exit(1)
+	}
+
+	if printAsJSON {
+		type LogoutResult struct {
+			Status   string   `json:"status"`
+			Warnings []string `json:"warnings_encountered,omitempty"`
+		}
+		result := LogoutResult{Status: "OK"}
+		if revokeErr != nil {
+			result.Warnings = []string{revokeErr.Error()}
+		}
+		printResult(result)
+	} else {
+		if revokeErr != nil {
+			logr.Warnf("Unable to revoke the session at the identity provider: %s", revokeErr.Desc)
+		}
+		logr.Printf("Logged out of connection %v", strings.ToUpper(connection.ID))
+	}
+	exit(0)
 }
 
 // SecurityTokenRefresh : Refresh the access token the cached refresh token
 func SecurityTokenRefresh(c *cli.Context) {
 	authTokens, secErr := security.SecRefreshTokens(http.DefaultClient, c)
 	if secErr == nil && authTokens != nil {
-		utils.PrettyPrintJSON(authTokens)
+		printResult(authTokens)
 	} else {
-		fmt.Println(secErr.Error())
-		os.Exit(1)
+		printJSONError(secErr)
+		exit(1)
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // SecurityCreateRealm : Create a realm in Keycloak
 func SecurityCreateRealm(c *cli.Context) {
 	err := security.SecRealmCreate(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	} else {
-		utils.PrettyPrintJSON(security.Result{Status: "OK"})
+		printResult(security.Result{Status: "OK"})
+	}
+	exit(0)
+}
+
+// SecurityExportRealm : Export a Keycloak realm to a file or the terminal
+func SecurityExportRealm(c *cli.Context) {
+	export, secErr := security.SecRealmExport(http.DefaultClient, c)
+	if secErr != nil {
+		printJSONError(secErr)
+		exit(1)
+	}
+	filename := strings.TrimSpace(c.String("file"))
+	if filename == "" {
+		printResult(export)
+		exit(0)
+	}
+	exportJSON, _ := json.MarshalIndent(export, "", "\t")
+	err := ioutil.WriteFile(filename, exportJSON, 0600)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
 	}
-	os.Exit(0)
+	printResult(security.Result{Status: "OK"})
+	exit(0)
+}
+
+// SecurityImportRealm : Create a Keycloak realm from an export file
+func SecurityImportRealm(c *cli.Context) {
+	exportJSON, err := ioutil.ReadFile(strings.TrimSpace(c.String("file")))
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	export := security.RealmExport{}
+	err = json.Unmarshal(exportJSON, &export)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	secErr := security.SecRealmImport(http.DefaultClient, c, export)
+	if secErr != nil {
+		printJSONError(secErr)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
 }
 
 // SecurityCreateRole : Create a role in an existing Keycloak realm
 func SecurityCreateRole(c *cli.Context) {
 	err := security.SecRoleCreate(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	} else {
-		utils.PrettyPrintJSON(security.Result{Status: "OK"})
+		printResult(security.Result{Status: "OK"})
+	}
+	exit(0)
+}
+
+// SecurityListRoles : List the roles of a Keycloak realm
+func SecurityListRoles(c *cli.Context) {
+	roles, err := security.SecRoleList(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
 	}
-	os.Exit(0)
+	printResult(roles)
+	exit(0)
+}
+
+// SecurityCreateGroup : Create a group in an existing Keycloak realm
+func SecurityCreateGroup(c *cli.Context) {
+	err := security.SecGroupCreate(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
+}
+
+// SecurityListGroups : List the groups of a Keycloak realm
+func SecurityListGroups(c *cli.Context) {
+	groups, err := security.SecGroupList(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(groups)
+	exit(0)
+}
+
+// SecurityGroupAddUser : Add an existing user to a group
+func SecurityGroupAddUser(c *cli.Context) {
+	err := security.SecGroupAddUser(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
+}
+
+// SecurityGroupRemoveUser : Remove a user from a group
+func SecurityGroupRemoveUser(c *cli.Context) {
+	err := security.SecGroupRemoveUser(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
+}
+
+// SecurityGroupAddRole : Grant an existing role to every member of a group
+func SecurityGroupAddRole(c *cli.Context) {
+	err := security.SecGroupAddRole(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
 }
 
 // SecurityClientCreate : Create a new client in Keycloak
 func SecurityClientCreate(c *cli.Context) {
 	err := security.SecClientCreate(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	} else {
-		utils.PrettyPrintJSON(security.Result{Status: "OK"})
+		printResult(security.Result{Status: "OK"})
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // SecurityClientGet : Retrieve a client configuration from Keycloak
 func SecurityClientGet(c *cli.Context) {
 	registeredClient, err := security.SecClientGet(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
 	if registeredClient != nil {
-		utils.PrettyPrintJSON(registeredClient)
-		os.Exit(0)
+		printResult(registeredClient)
+		exit(0)
 	}
-	utils.PrettyPrintJSON(security.Result{Status: "Not found"})
-	os.Exit(1)
+	printResult(security.Result{Status: "Not found"})
+	exit(1)
 }
 
 // SecurityClientGetSecret : Retrieve a client secret from Keycloak
 func SecurityClientGetSecret(c *cli.Context) {
 	registeredClientSecret, err := security.SecClientGetSecret(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
 	if registeredClientSecret != nil {
-		utils.PrettyPrintJSON(registeredClientSecret)
-		os.Exit(0)
+		printResult(registeredClientSecret)
+		exit(0)
 	}
-	utils.PrettyPrintJSON(security.Result{Status: "Not found"})
-	os.Exit(1)
+	printResult(security.Result{Status: "Not found"})
+	exit(1)
 }
 
 // SecurityUserCreate : Create a user in a Keycloak realm
 func SecurityUserCreate(c *cli.Context) {
 	err := security.SecUserCreate(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	} else {
-		utils.PrettyPrintJSON(security.Result{Status: "OK"})
+		printResult(security.Result{Status: "OK"})
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // SecurityUserGet : Retrieve the user detail from Keycloak
 func SecurityUserGet(c *cli.Context) {
 	registeredUser, err := security.SecUserGet(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
 	if registeredUser != nil {
-		utils.PrettyPrintJSON(registeredUser)
-		os.Exit(0)
+		printResult(registeredUser)
+		exit(0)
 	}
-	utils.PrettyPrintJSON(security.Result{Status: "Not found"})
-	os.Exit(1)
+	printResult(security.Result{Status: "Not found"})
+	exit(1)
 }
 
 // SecurityUserSetPassword : Set a users password in Keycloak
 func SecurityUserSetPassword(c *cli.Context) {
 	err := security.SecUserSetPW(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
+	}
+	printResult(security.Result{Status: "OK"})
+	exit(0)
+}
+
+// SecurityUserList : List the users of a Keycloak realm
+func SecurityUserList(c *cli.Context) {
+	users, err := security.SecUserList(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
+	}
+	if printAsJSON {
+		printResult(users)
+		exit(0)
+	}
+	w := new(tabwriter.Writer)
+	w.Init(commandOutput(), 0, 8, 1, '\t', 0)
+	fmt.Fprintln(w, "USERNAME\tEMAIL\tENABLED")
+	for _, user := range users {
+		fmt.Fprintln(w, user.Username+"\t"+user.Email+"\t"+strconv.FormatBool(user.Enabled))
+	}
+	fmt.Fprintln(w)
+	w.Flush()
+	exit(0)
+}
+
+// SecurityUserRemove : Remove a user from a Keycloak realm
+func SecurityUserRemove(c *cli.Context) {
+	err := security.SecUserDelete(http.DefaultClient, c)
+	if err != nil {
+		printJSONError(err)
+		exit(1)
 	}
-	utils.PrettyPrintJSON(security.Result{Status: "OK"})
-	os.Exit(0)
+	printResult(security.Result{Status: "OK"})
+	exit(0)
 }
 
 // SecurityUserAddRole : Add an existing role to the specified user
 func SecurityUserAddRole(c *cli.Context) {
 	err := security.SecUserAddRole(c)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	} else {
-		utils.PrettyPrintJSON(security.Result{Status: "OK"})
+		printResult(security.Result{Status: "OK"})
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // SecurityKeyUpdate : Creates or updates a key in the platforms keyring
@@ -170,12 +422,11 @@ func SecurityKeyUpdate(c *cli.Context) {
 	password := strings.TrimSpace(c.String("password"))
 	err := security.SecKeyUpdate(connectionID, username, password)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
-	response, _ := json.Marshal(security.Result{Status: "OK"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	printCompactResult(security.Result{Status: "OK"})
+	exit(0)
 }
 
 // SecurityKeyValidate : Checks the key is available in the platform keyring
@@ -184,10 +435,9 @@ func SecurityKeyValidate(c *cli.Context) {
 	username := strings.TrimSpace(strings.ToLower(c.String("username")))
 	_, err := security.SecKeyGetSecret(connectionID, username)
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
 	}
-	response, _ := json.Marshal(security.Result{Status: "OK"})
-	fmt.Println(string(response))
-	os.Exit(0)
+	printCompactResult(security.Result{Status: "OK"})
+	exit(0)
 }
@@ -13,8 +13,10 @@ package actions
 
 import (
 	"fmt"
-	"os"
+	"path"
+	"time"
 
+	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/urfave/cli"
 )
@@ -24,39 +26,141 @@ func StartCommand(c *cli.Context, dockerComposeFile string, healthEndpoint strin
 	dockerClient, dockerErr := docker.NewDockerClient()
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	status, err := docker.CheckContainerStatus(dockerClient, docker.LocalCWContainerNames)
 	if err != nil {
 		HandleDockerError(err)
-		os.Exit(1)
+		exit(1)
+	}
+
+	// Only a version asked for explicitly is compared with the running one
+	requestedTag := ""
+	if c.String("channel") != "" || c.String("tag") != "" {
+		requestedTag = imageTagFromFlags(c)
+	}
+	versionErr := docker.CheckRunningVersion(dockerClient, requestedTag)
+	if versionErr != nil {
+		HandleDockerError(versionErr)
+		exit(1)
 	}
 
 	if status {
-		fmt.Println("Codewind is already running!")
+		fmt.Fprintln(commandOutput(), "Codewind is already running!")
 	} else {
-		tag := c.String("tag")
+		tag := imageTagFromFlags(c)
 		debug := c.Bool("debug")
 		loglevel := c.GlobalString("loglevel")
-		fmt.Println("Debug:", debug)
+		fmt.Fprintln(commandOutput(), "Debug:", debug)
 
-		writeToComposeFileErr := docker.WriteToComposeFile(dockerComposeFile, debug)
+		// Compose would pull missing images from dockerhub, so pull them from the mirror first
+		if registryMirror := c.String("registry-mirror"); registryMirror != "" {
+			for _, image := range docker.CodewindImages(tag) {
+				pullErr := docker.PullImage(dockerClient, image, registryMirror, printAsJSON)
+				if pullErr != nil {
+					HandleDockerError(pullErr)
+					exit(1)
+				}
+			}
+		}
+
+		// Choose the port for PFE before starting anything, so an occupied port is reported rather than failing Compose
+		codewindDir := path.Dir(dockerComposeFile)
+		portMapping, portErr := docker.LoadPortMapping(codewindDir)
+		if portErr != nil {
+			HandleDockerError(portErr)
+			exit(1)
+		}
+		pfePort, portErr := docker.ChoosePFEPort(c.Int("pfe-port"), portMapping.PFE)
+		if portErr != nil {
+			HandleDockerError(portErr)
+			exit(1)
+		}
+		if pfePort == 0 {
+			fmt.Fprintln(commandOutput(), "No available external ports in range, will default to Docker-assigned port")
+		} else if portMapping.PFE != 0 && pfePort != portMapping.PFE {
+			fmt.Fprintf(commandOutput(), "Port %v is in use, PFE will be available on port %v instead\n", portMapping.PFE, pfePort)
+		} else {
+			fmt.Fprintf(commandOutput(), "PFE will be available on port %v\n", pfePort)
+		}
+
+		tlsDir := ""
+		if c.Bool("tls") {
+			tlsDir = startTLS(codewindDir, c.Bool("trust-cert"))
+		} else {
+			if c.Bool("trust-cert") {
+				fmt.Fprintln(commandOutput(), "--trust-cert has no effect without --tls")
+			}
+			untrustLocalCertificate(codewindDir)
+		}
+
+		writeToComposeFileErr := docker.WriteToComposeFile(dockerComposeFile, debug, tlsDir)
 		if writeToComposeFileErr != nil {
 			HandleDockerError(writeToComposeFileErr)
-			os.Exit(1)
+			exit(1)
 		}
 
-		err := docker.DockerCompose(dockerComposeFile, tag, loglevel)
+		err := docker.DockerCompose(dockerComposeFile, tag, loglevel, pfePort)
 		if err != nil {
 			HandleDockerError(err)
-			os.Exit(1)
+			exit(1)
+		}
+
+		if pfePort != 0 {
+			portMapping.PFE = pfePort
+			portErr = docker.SavePortMapping(codewindDir, portMapping)
+			if portErr != nil {
+				HandleDockerError(portErr)
+			}
 		}
 
-		_, pingHealthErr := docker.PingHealth(healthEndpoint)
+		httpClient, dockerErr := docker.LocalHTTPClient(codewindDir, 5*time.Second)
+		if dockerErr != nil {
+			HandleDockerError(dockerErr)
+			exit(1)
+		}
+		_, pingHealthErr := docker.PingHealth(httpClient, healthEndpoint)
 		if pingHealthErr != nil {
 			HandleDockerError(pingHealthErr)
-			os.Exit(1)
+			exit(1)
 		}
 	}
 }
+
+// startTLS generates the certificate for local Codewind to serve HTTPS with, trusting it when asked, and returns the
+// directory it is in. The local connection trusts the certificate so that cwctl can call PFE.
+func startTLS(codewindDir string, trust bool) string {
+	certPath, dockerErr := docker.GenerateCertificate(codewindDir)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+	fmt.Fprintln(commandOutput(), "PFE will serve HTTPS with the certificate "+certPath)
+	if trust {
+		fmt.Fprintln(commandOutput(), "Adding the certificate to the trusted certificates, you may be asked for your password")
+		dockerErr = docker.TrustCertificate(certPath)
+		if dockerErr != nil {
+			HandleDockerError(dockerErr)
+			exit(1)
+		}
+	}
+	conErr := connections.SetLocalCACert(certPath)
+	if conErr != nil {
+		HandleConnectionError(conErr)
+		exit(1)
+	}
+	return docker.TLSDirectory(codewindDir)
+}
+
+// untrustLocalCertificate stops the local connection trusting the generated certificate once Codewind is started
+// without TLS
+func untrustLocalCertificate(codewindDir string) {
+	local, conErr := connections.GetConnectionByID("local")
+	if conErr == nil && local.CACert == docker.CertificatePath(codewindDir) {
+		conErr = connections.SetLocalCACert("")
+	}
+	if conErr != nil {
+		HandleConnectionError(conErr)
+	}
+}
@@ -12,11 +12,12 @@
 package actions
 
 import (
-	"encoding/json"
 	"fmt"
 	"log"
 	"net/http"
-	"os"
+	"path"
+	"text/tabwriter"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
 	"github.com/eclipse/codewind-installer/pkg/connections"
@@ -27,7 +28,13 @@ import (
 // StatusCommand : to show the status
 func StatusCommand(c *cli.Context) {
 	conID := c.String("conid")
-	if conID != "" && conID != "local" {
+	if c.Bool("deep") {
+		if conID != "" && conID != "local" {
+			fmt.Fprintln(commandOutput(), "--deep checks the local Codewind containers, and is not available for remote connections")
+			exit(1)
+		}
+		StatusCommandDeep(c)
+	} else if conID != "" && conID != "local" {
 		StatusCommandRemoteConnection(c)
 	} else {
 		StatusCommandLocalConnection(c)
@@ -39,8 +46,8 @@ func StatusCommandRemoteConnection(c *cli.Context) {
 	conID := c.String("conid")
 	connection, conErr := connections.GetConnectionByID(conID)
 	if conErr != nil {
-		fmt.Println(conErr)
-		os.Exit(1)
+		printJSONError(conErr)
+		exit(1)
 	}
 
 	PFEReady, err := apiroutes.IsPFEReady(http.DefaultClient, connection.URL)
@@ -53,14 +60,13 @@ func StatusCommandRemoteConnection(c *cli.Context) {
 				Status: "stopped",
 			}
 			if err != nil {
-				fmt.Println(err)
-				os.Exit(1)
+				printJSONError(err)
+				exit(1)
 			}
-			output, _ := json.Marshal(resp)
-			fmt.Println(string(output))
-			os.Exit(1)
+			printCompactResult(resp)
+			exit(1)
 		} else {
-			fmt.Println("Codewind did not respond on remote connection", conID)
+			fmt.Fprintln(commandOutput(), "Codewind did not respond on remote connection", conID)
 			log.Println(err)
 		}
 	}
@@ -76,12 +82,11 @@ func StatusCommandRemoteConnection(c *cli.Context) {
 		resp := &status{
 			Status: "started",
 		}
-		output, _ := json.Marshal(resp)
-		fmt.Println(string(output))
+		printCompactResult(resp)
 	} else {
-		fmt.Println("Remote Codewind is installed and running")
+		fmt.Fprintln(commandOutput(), "Remote Codewind is installed and running")
 	}
-	os.Exit(0)
+	exit(0)
 }
 
 // StatusCommandLocalConnection : Output local connection details
@@ -89,33 +94,33 @@ func StatusCommandLocalConnection(c *cli.Context) {
 	dockerClient, dockerErr := docker.NewDockerClient()
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	containersAreRunning, err := docker.CheckContainerStatus(dockerClient, docker.LocalCWContainerNames)
 	if err != nil {
 		HandleDockerError(err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	if containersAreRunning {
 		// Started
-		hostname, port, err := docker.GetPFEHostAndPort(dockerClient)
+		pfeURL, err := docker.GetPFEURL(dockerClient)
 		if err != nil {
 			HandleDockerError(err)
-			os.Exit(1)
+			exit(1)
 		}
 		if printAsJSON {
 			imageTagArr, err := docker.GetImageTags(dockerClient)
 			if err != nil {
-				fmt.Println(err.Error())
-				os.Exit(1)
+				printJSONError(err)
+				exit(1)
 			}
 
 			containerTagArr, err := docker.GetContainerTags(dockerClient)
 			if err != nil {
-				fmt.Println(err.Error())
-				os.Exit(1)
+				printJSONError(err)
+				exit(1)
 			}
 
 			type status struct {
@@ -127,60 +132,117 @@ func StatusCommandLocalConnection(c *cli.Context) {
 
 			resp := &status{
 				Status:   "started",
-				URL:      "http://" + hostname + ":" + port,
+				URL:      pfeURL,
 				Versions: imageTagArr,
 				Started:  containerTagArr,
 			}
 
-			output, _ := json.Marshal(resp)
-			fmt.Println(string(output))
+			printCompactResult(resp)
 		} else {
-			fmt.Println("Codewind is installed and running on http://" + hostname + ":" + port)
+			fmt.Fprintln(commandOutput(), "Codewind is installed and running on "+pfeURL)
 		}
-		os.Exit(0)
+		exit(0)
 	}
 
 	imagesAreInstalled, err := docker.CheckImageStatus(dockerClient)
 	if err != nil {
 		HandleDockerError(err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	if imagesAreInstalled {
-		// Installed but not started
+		// Installed but not started, PFE is published on the port it last used again if that port is still free
+		portMapping, _ := docker.LoadPortMapping(path.Dir(dockerComposeFile))
 		if printAsJSON {
 
 			imageTagArr, err := docker.GetImageTags(dockerClient)
 			if err != nil {
-				fmt.Println(err.Error())
-				os.Exit(1)
+				printJSONError(err)
+				exit(1)
 			}
 
 			type status struct {
 				Status   string   `json:"status"`
 				Versions []string `json:"installed-versions"`
+				PFEPort  int      `json:"pfe-port,omitempty"`
 			}
 
 			resp := &status{
 				Status:   "stopped",
 				Versions: imageTagArr,
+				PFEPort:  portMapping.PFE,
 			}
 
-			output, _ := json.Marshal(resp)
-			fmt.Println(string(output))
+			printCompactResult(resp)
+		} else if portMapping.PFE != 0 {
+			fmt.Fprintf(commandOutput(), "Codewind is installed but not running, it was last available on port %v\n", portMapping.PFE)
 		} else {
-			fmt.Println("Codewind is installed but not running")
+			fmt.Fprintln(commandOutput(), "Codewind is installed but not running")
 		}
-		os.Exit(0)
+		exit(0)
 	} else {
 		// Not installed
 		if printAsJSON {
-			output, _ := json.Marshal(map[string]string{"status": "uninstalled"})
-			fmt.Println(string(output))
+			printCompactResult(map[string]string{"status": "uninstalled"})
 		} else {
-			fmt.Println("Codewind is not installed")
+			fmt.Fprintln(commandOutput(), "Codewind is not installed")
 		}
-		os.Exit(0)
+		exit(0)
 	}
 	return
 }
+
+// StatusCommandDeep : Output the health of each local Codewind container, exiting with an error if any is unhealthy
+func StatusCommandDeep(c *cli.Context) {
+	dockerClient, dockerErr := docker.NewDockerClient()
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+
+	httpClient, dockerErr := docker.LocalHTTPClient(path.Dir(dockerComposeFile), 5*time.Second)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+	components, dockerErr := docker.CheckLocalHealth(dockerClient, httpClient, healthEndpoint)
+	if dockerErr != nil {
+		HandleDockerError(dockerErr)
+		exit(1)
+	}
+
+	status := "healthy"
+	for _, component := range components {
+		if !component.Healthy {
+			status = "unhealthy"
+		}
+	}
+
+	if printAsJSON {
+		printResult(struct {
+			Status     string                   `json:"status"`
+			Components []docker.ComponentHealth `json:"components"`
+		}{status, components})
+	} else {
+		w := tabwriter.NewWriter(commandOutput(), 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "COMPONENT\tCONTAINER\tSTATE\tRESTARTS\tHEALTH")
+		for _, component := range components {
+			health := "healthy"
+			if !component.Healthy {
+				health = "unhealthy"
+			}
+			fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", component.Component, component.Container, component.State, component.RestartCount, health)
+		}
+		w.Flush()
+		for _, component := range components {
+			for _, check := range component.Checks {
+				if !check.Passed {
+					fmt.Fprintf(commandOutput(), "%v %v: %v\n", component.Component, check.Name, check.Detail)
+				}
+			}
+		}
+	}
+	if status != "healthy" {
+		exit(1)
+	}
+}
@@ -13,7 +13,6 @@ package actions
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/urfave/cli"
@@ -26,25 +25,25 @@ func StopAllCommand(c *cli.Context, dockerComposeFile string) {
 	dockerClient, dockerErr := docker.NewDockerClient()
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
 	}
 
 	containers, err := docker.GetContainerList(dockerClient)
 	if err != nil {
 		HandleDockerError(err)
-		os.Exit(1)
+		exit(1)
 	}
 
 	dockerErr = docker.DockerComposeStop(tag, dockerComposeFile)
 	if dockerErr != nil {
 		HandleDockerError(dockerErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	fmt.Println("Stopping Project containers")
+	fmt.Fprintln(commandOutput(), "Stopping Project containers")
 	containersToRemove := docker.GetCodewindProjectContainers(containers)
 	for _, container := range containersToRemove {
-		fmt.Println("Stopping container ", container.Names[0], "... ")
+		fmt.Fprintln(commandOutput(), "Stopping container ", container.Names[0], "... ")
 		docker.StopContainer(dockerClient, container)
 	}
 }
@@ -13,7 +13,6 @@ package actions
 
 import (
 	"fmt"
-	"os"
 
 	"github.com/eclipse/codewind-installer/pkg/docker"
 	"github.com/urfave/cli"
@@ -22,10 +21,10 @@ import (
 //StopCommand to stop only the codewind containers
 func StopCommand(c *cli.Context, dockerComposeFile string) {
 	tag := c.String("tag")
-	fmt.Println("Only stopping Codewind containers. To stop project containers, please use 'stop-all'")
+	fmt.Fprintln(commandOutput(), "Only stopping Codewind containers. To stop project containers, please use 'stop-all'")
 	err := docker.DockerComposeStop(tag, dockerComposeFile)
 	if err != nil {
 		HandleDockerError(err)
-		os.Exit(1)
+		exit(1)
 	}
 }
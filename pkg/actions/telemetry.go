@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/telemetry"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+)
+
+// TelemetryStatus : Whether usage metrics are collected, as printed by the telemetry commands
+type TelemetryStatus struct {
+	Enabled  bool   `json:"enabled"`
+	Endpoint string `json:"endpoint,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Queued   int    `json:"queued"`
+}
+
+// TelemetryOn : Turns on the collection of anonymous usage metrics
+func TelemetryOn(c *cli.Context) {
+	settings, err := telemetry.Enable(connections.GetConnectionConfigDir(), c.String("endpoint"))
+	if err != nil {
+		logr.Errorln(err)
+		exit(1)
+	}
+	printTelemetryStatus(settings)
+}
+
+// TelemetryOff : Turns off the collection of usage metrics
+func TelemetryOff(c *cli.Context) {
+	settings, err := telemetry.Disable(connections.GetConnectionConfigDir())
+	if err != nil {
+		logr.Errorln(err)
+		exit(1)
+	}
+	printTelemetryStatus(settings)
+}
+
+// TelemetryShowStatus : Prints whether usage metrics are collected and where they are sent
+func TelemetryShowStatus(c *cli.Context) {
+	settings, err := telemetry.LoadSettings(connections.GetConnectionConfigDir())
+	if err != nil {
+		logr.Errorln(err)
+		exit(1)
+	}
+	printTelemetryStatus(settings)
+}
+
+func printTelemetryStatus(settings telemetry.Settings) {
+	status := TelemetryStatus{
+		Enabled:  settings.Enabled,
+		Endpoint: telemetry.Endpoint(settings),
+		ID:       settings.ID,
+		Queued:   telemetry.Queued(connections.GetConnectionConfigDir()),
+	}
+	if printAsJSON {
+		printResult(status)
+		return
+	}
+	if !status.Enabled {
+		fmt.Fprintln(commandOutput(), "Usage metrics are off")
+		return
+	}
+	fmt.Fprintln(commandOutput(), "Usage metrics are on, sent to "+status.Endpoint)
+	fmt.Fprintf(commandOutput(), "Anonymous ID: %s, events waiting to be sent: %d\n", status.ID, status.Queued)
+}
+
+// recordCommands wraps the action of each command so that its name, duration and outcome are recorded when usage
+// metrics are on. The telemetry commands themselves, and the completions run as a command is typed, are not recorded.
+func recordCommands(commands []cli.Command, parent string) {
+	for i := range commands {
+		name := strings.TrimSpace(parent + " " + commands[i].Name)
+		if name == "telemetry" || name == completeCommand {
+			continue
+		}
+		recordCommands(commands[i].Subcommands, name)
+		action, ok := commands[i].Action.(func(*cli.Context) error)
+		if !ok {
+			continue
+		}
+		commands[i].Action = func(c *cli.Context) error {
+			telemetry.Start(connections.GetConnectionConfigDir(), name, appconstants.VersionNum)
+			err := action(c)
+			telemetry.Finish(err == nil)
+			return err
+		}
+	}
+}
@@ -13,9 +13,11 @@ package actions
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/templates"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
@@ -34,12 +36,70 @@ func ListTemplates(c *cli.Context) {
 		return
 	}
 	if len(templates) > 0 {
-		utils.PrettyPrintJSON(templates)
+		printResult(templates)
 	} else {
-		fmt.Println(templates)
+		fmt.Fprintln(commandOutput(), templates)
 	}
 }
 
+// SearchTemplates lists the templates of enabled repos matching the keywords, language and style given
+func SearchTemplates(c *cli.Context) {
+	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	options := templates.SearchOptions{
+		Keywords:     c.Args(),
+		Language:     strings.TrimSpace(c.String("language")),
+		ProjectStyle: strings.TrimSpace(c.String("style")),
+	}
+	result, err := templates.SearchTemplates(conID, options)
+	if err != nil {
+		templateErr := &TemplateError{errOpSearchTemplates, err, err.Error()}
+		HandleTemplateError(templateErr)
+		return
+	}
+	if printAsJSON {
+		printResult(result)
+		return
+	}
+	tableContent := []string{"NAME	LANGUAGE	STYLE	SOURCE	CONNECTION ID	DESCRIPTION"}
+	for _, template := range result.Templates {
+		tableContent = append(tableContent, template.Label+"\t"+template.Language+"\t"+templates.TemplateStyle(template.Template)+"\t"+template.Source+"\t"+template.ConnectionID+"\t"+template.Description)
+	}
+	PrintTable(tableContent)
+	failedIDs := []string{}
+	for id := range result.Errors {
+		failedIDs = append(failedIDs, id)
+	}
+	sort.Strings(failedIDs)
+	for _, id := range failedIDs {
+		fmt.Fprintln(commandOutput(), "Unable to search connection "+id+": "+result.Errors[id])
+	}
+}
+
+// CreateTemplateFromProject creates a template repository from the project at the path given
+func CreateTemplateFromProject(c *cli.Context) {
+	projectPath := c.Args().First()
+	if projectPath == "" {
+		projectPath = c.String("path")
+	}
+	options := templates.ScaffoldOptions{
+		ProjectPath:  projectPath,
+		OutputDir:    c.String("output"),
+		BaseURL:      strings.TrimSpace(c.String("url")),
+		Name:         c.String("name"),
+		Description:  c.String("description"),
+		Language:     c.String("language"),
+		ProjectType:  c.String("type"),
+		ProjectStyle: c.String("style"),
+	}
+	result, err := templates.ScaffoldTemplate(options)
+	if err != nil {
+		templateErr := &TemplateError{errOpCreateFromProject, err, err.Error()}
+		HandleTemplateError(templateErr)
+		return
+	}
+	printResult(result)
+}
+
 // ListTemplateStyles lists all template styles of which Codewind is aware.
 func ListTemplateStyles(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
@@ -49,19 +109,30 @@ func ListTemplateStyles(c *cli.Context) {
 		HandleTemplateError(templateErr)
 		return
 	}
-	utils.PrettyPrintJSON(styles)
+	printResult(styles)
 }
 
 // ListTemplateRepos lists all template repos of which Codewind is aware.
 func ListTemplateRepos(c *cli.Context) {
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	if connections.IsAllConnections(conID) {
+		result, conErr := connections.ForEachConnection(func(connection *connections.Connection) (interface{}, error) {
+			return apiroutes.GetTemplateRepos(connection.ID)
+		})
+		if conErr != nil {
+			HandleConnectionError(conErr)
+			return
+		}
+		printResult(result)
+		return
+	}
 	repos, err := apiroutes.GetTemplateRepos(conID)
 	if err != nil {
 		templateErr := &TemplateError{errOpListRepos, err, err.Error()}
 		HandleTemplateError(templateErr)
 		return
 	}
-	utils.PrettyPrintJSON(repos)
+	printResult(repos)
 }
 
 // AddTemplateRepo adds the provided template repo to PFE.
@@ -73,6 +144,7 @@ func AddTemplateRepo(c *cli.Context) {
 	password := c.String("password")
 	personalAccessToken := c.String("personalAccessToken")
 
+	username, password, personalAccessToken = utils.GitCredentialsFromEnvironment(username, password, personalAccessToken)
 	gitCredentials, err := utils.ExtractGitCredentials(username, password, personalAccessToken)
 	if err != nil {
 		templateErr := &TemplateError{errOpAddRepo, err, err.Error()}
@@ -91,7 +163,32 @@ func AddTemplateRepo(c *cli.Context) {
 	if err == nil {
 		utils.OnAddTemplateRepo(extensions, url, repos)
 	}
-	utils.PrettyPrintJSON(repos)
+	printResult(repos)
+}
+
+// MirrorTemplateRepo copies a template repo and its templates to a directory or HTTP endpoint
+func MirrorTemplateRepo(c *cli.Context) {
+	username, password, personalAccessToken := utils.GitCredentialsFromEnvironment(c.String("username"), c.String("password"), c.String("personalAccessToken"))
+	gitCredentials, err := utils.ExtractGitCredentials(username, password, personalAccessToken)
+	if err != nil {
+		templateErr := &TemplateError{errOpMirrorRepo, err, err.Error()}
+		HandleTemplateError(templateErr)
+		return
+	}
+	options := templates.MirrorOptions{
+		IndexURL:       strings.TrimSpace(c.String("url")),
+		OutputDir:      strings.TrimSpace(c.String("output")),
+		UploadURL:      strings.TrimSpace(c.String("upload")),
+		BaseURL:        strings.TrimSpace(c.String("base-url")),
+		GitCredentials: gitCredentials,
+	}
+	result, err := templates.MirrorTemplateRepo(options)
+	if err != nil {
+		templateErr := &TemplateError{errOpMirrorRepo, err, err.Error()}
+		HandleTemplateError(templateErr)
+		return
+	}
+	printResult(result)
 }
 
 // DeleteTemplateRepo deletes the provided template repo from PFE.
@@ -111,7 +208,7 @@ func DeleteTemplateRepo(c *cli.Context) {
 		HandleTemplateError(templateErr)
 		return
 	}
-	utils.PrettyPrintJSON(repos)
+	printResult(repos)
 }
 
 // EnableTemplateRepos enables templates repo of which Codewind is aware.
@@ -123,7 +220,7 @@ func EnableTemplateRepos(c *cli.Context) {
 		HandleTemplateError(templateErr)
 		return
 	}
-	utils.PrettyPrintJSON(repos)
+	printResult(repos)
 }
 
 // DisableTemplateRepos disables templates repo of which Codewind is aware.
@@ -135,5 +232,5 @@ func DisableTemplateRepos(c *cli.Context) {
 		HandleTemplateError(templateErr)
 		return
 	}
-	utils.PrettyPrintJSON(repos)
+	printResult(repos)
 }
@@ -23,6 +23,9 @@ type TemplateError struct {
 const (
 	errOpListTemplates           = "LIST_TEMPLATES_ERROR"
 	errOpListStyles              = "LIST_STYLES_ERROR"
+	errOpSearchTemplates         = "SEARCH_TEMPLATES_ERROR"
+	errOpCreateFromProject       = "CREATE_TEMPLATE_ERROR"
+	errOpMirrorRepo              = "MIRROR_REPO_ERROR"
 	errOpListRepos               = "LIST_REPOS_ERROR"
 	errOpAddRepo                 = "ADD_REPO_ERROR"
 	errOpDeleteRepo              = "DELETE_REPO_ERROR"
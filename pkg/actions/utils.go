@@ -12,88 +12,173 @@
 package actions
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 	"text/tabwriter"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/errors"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/project"
 	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/telemetry"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
 )
 
 // HandleDockerError prints a Docker error, in JSON format if the global flag is set and as a string if not
 func HandleDockerError(err *docker.DockerError) {
+	failed(err.Op)
 	// printAsJSON is a global variable, set in commands.go
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleTemplateError prints a Template error, in JSON format if the global flag is set, and as a string if not
 func HandleTemplateError(err *TemplateError) {
+	failed(err.Op)
 	// printAsJSON is a global variable, set in commands.go
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleConnectionError prints a Connection error, in JSON format if the global flag is set and as a string if not
 func HandleConnectionError(err *connections.ConError) {
+	failed(err.Op)
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleProjectError prints a Project error, in JSON format if the global flag is set and as a string if not
 func HandleProjectError(err *project.ProjectError) {
+	failed(err.Op)
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleConfigError prints a Config error, in JSON format if the global flag is set and as a string if not
 func HandleConfigError(err *config.ConfigError) {
+	failed(err.Op)
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleRemInstError prints a RemInst error, in JSON format if the global flag is set and as a string if not
 func HandleRemInstError(err *remote.RemInstError) {
+	failed(err.Op)
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
 // HandleRegistryError prints a Registry error, in JSON format if the global flag is set, and as a string if not
 func HandleRegistryError(err *RegistryError) {
+	failed(err.Op)
 	// printAsJSON is a global variable, set in commands.go
 	if printAsJSON {
-		fmt.Println(err.Error())
+		printJSONError(err)
 	} else {
-		logr.Error(err.Desc)
+		logr.Error(i18n.Describe(err.Err, err.Desc))
 	}
 }
 
+// parseTimeFlag reads a time given as a duration before now, or as an RFC 3339 time. An empty value is the zero
+// time.
+func parseTimeFlag(value string, now time.Time) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if ago, err := time.ParseDuration(value); err == nil {
+		return now.Add(-ago), nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// failedOp is the operation of the last error handled, used to choose the exit code of a failed command
+var failedOp string
+
+// failed records the operation of an error for the exit code and usage metrics
+func failed(op string) {
+	failedOp = op
+	telemetry.Failed(op)
+}
+
+// exit prints the --json document if the command has not, and records the outcome of the command for usage
+// metrics, then exits with a status code. A general failure exits with the code of the last error handled instead.
+func exit(code int) {
+	if code == errors.ExitFailure && failedOp != "" {
+		code = errors.ExitCode(failedOp)
+	}
+	finishOutput(code)
+	telemetry.Finish(code == 0)
+	os.Exit(code)
+}
+
+// interruptContext returns a context that is cancelled when cwctl is interrupted with Ctrl+C or terminated, so that a
+// long running command stops what it is doing cleanly and reports it was cancelled. Interrupting again exits at once.
+func interruptContext() context.Context {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		logr.Warnln("Interrupted, stopping - interrupt again to exit now")
+		cancel()
+		<-signals
+		os.Exit(errors.ExitCancelled)
+	}()
+	return ctx
+}
+
+// inputRequired fails a command that needs input when prompts are turned off by --non-interactive
+func inputRequired(desc string) {
+	failed(errors.ErrOpInputRequired)
+	if printAsJSON {
+		writeOutput(Output{Status: OutputFailure, Error: newOutputError(errors.ErrOpInputRequired, desc)})
+	} else {
+		logr.Error(desc)
+	}
+	exit(errors.ExitInputRequired)
+}
+
+// imageTagFromFlags returns the image tag selected by the --channel and --tag flags, exiting if they are invalid
+func imageTagFromFlags(c *cli.Context) string {
+	tag, err := utils.ResolveImageTag(c.String("channel"), c.String("tag"))
+	if err != nil {
+		logr.Errorln(err)
+		exit(1)
+	}
+	return tag
+}
+
 // PrintTable prints a formatted table into the terminal
 func PrintTable(content []string) {
 	w := new(tabwriter.Writer)
-	w.Init(os.Stdout, 0, 8, 2, '\t', 0)
+	w.Init(commandOutput(), 0, 8, 2, '\t', 0)
 	for _, line := range content {
 		fmt.Fprintln(w, line)
 	}
@@ -12,15 +12,12 @@
 package actions
 
 import (
-	"fmt"
-	"net/http"
-	"os"
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/appconstants"
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
-	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	logr "github.com/sirupsen/logrus"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
@@ -44,22 +41,25 @@ func GetSingleConnectionVersion(c *cli.Context) {
 	containerVersions, cvErr := GetContainerVersions(connectionID)
 	if cvErr != nil {
 		if printAsJSON {
-			fmt.Println(cvErr.Error())
+			printJSONError(cvErr)
 		} else {
 			logr.Error(cvErr.Error())
 		}
-		os.Exit(1)
+		exit(1)
 	}
 
 	if printAsJSON {
-		utils.PrettyPrintJSON(containerVersions)
+		printResult(containerVersions)
 	} else {
 		var tableContent []string
 		tableContent = append(tableContent, "CWCTL VERSION: "+containerVersions.CwctlVersion+"\n")
-		tableContent = append(tableContent, "CONNECTION ID \tPFE VERSION\tPERFORMANCE VERSION\tGATEKEEPER VERSION")
-		tableContent = append(tableContent, connectionID+"\t"+containerVersions.PFEVersion+"\t"+containerVersions.PerformanceVersion+"\t"+containerVersions.GatekeeperVersion)
+		tableContent = append(tableContent, "CONNECTION ID \tPFE VERSION\tPERFORMANCE VERSION\tGATEKEEPER VERSION\tCOMPATIBILITY")
+		tableContent = append(tableContent, connectionID+"\t"+containerVersions.PFEVersion+"\t"+containerVersions.PerformanceVersion+"\t"+containerVersions.GatekeeperVersion+"\t"+containerVersions.Compatibility.Status)
 
 		PrintTable(tableContent)
+		for _, message := range containerVersions.Compatibility.Translate() {
+			logr.Warnln(message)
+		}
 	}
 }
 
@@ -77,10 +77,12 @@ func GetContainerVersions(connectionID string) (apiroutes.ContainerVersions, err
 		return errorVersions, conErr
 	}
 
-	containerVersions, err := apiroutes.GetContainerVersions(conURL, appconstants.VersionNum, conInfo, http.DefaultClient)
+	containerVersions, err := apiroutes.GetContainerVersions(conURL, appconstants.VersionNum, conInfo, sechttp.Client())
 	if err != nil {
 		return errorVersions, err
 	}
+	compatibility := apiroutes.CheckCompatibility(appconstants.VersionNum, containerVersions)
+	containerVersions.Compatibility = &compatibility
 	return containerVersions, nil
 }
 
@@ -89,23 +91,28 @@ func GetAllConnectionVersions() {
 	connections, getConnectionsErr := connections.GetAllConnections()
 	if getConnectionsErr != nil {
 		HandleConnectionError(getConnectionsErr)
-		os.Exit(1)
+		exit(1)
 	}
 
-	containerVersionsList, err := apiroutes.GetAllContainerVersions(connections, appconstants.VersionNum, http.DefaultClient)
+	containerVersionsList, err := apiroutes.GetAllContainerVersions(connections, appconstants.VersionNum, sechttp.Client())
 	if err != nil {
-		fmt.Println(err.Error())
-		os.Exit(1)
+		printJSONError(err)
+		exit(1)
+	}
+	for conID, containerVersions := range containerVersionsList.Connections {
+		compatibility := apiroutes.CheckCompatibility(appconstants.VersionNum, containerVersions)
+		containerVersions.Compatibility = &compatibility
+		containerVersionsList.Connections[conID] = containerVersions
 	}
 
 	if printAsJSON {
-		utils.PrettyPrintJSON(containerVersionsList)
+		printResult(containerVersionsList)
 	} else {
 		var tableContent []string
 		tableContent = append(tableContent, "CWCTL VERSION: "+containerVersionsList.CwctlVersion+"\n")
-		tableContent = append(tableContent, "CONNECTION ID \tPFE VERSION\tPERFORMANCE VERSION\tGATEKEEPER VERSION")
+		tableContent = append(tableContent, "CONNECTION ID \tPFE VERSION\tPERFORMANCE VERSION\tGATEKEEPER VERSION\tCOMPATIBILITY")
 		for conID, con := range containerVersionsList.Connections {
-			tableContent = append(tableContent, conID+"\t"+con.PFEVersion+"\t"+con.PerformanceVersion+"\t"+con.GatekeeperVersion)
+			tableContent = append(tableContent, conID+"\t"+con.PFEVersion+"\t"+con.PerformanceVersion+"\t"+con.GatekeeperVersion+"\t"+con.Compatibility.Status)
 		}
 		numConErrs := len(containerVersionsList.ConnectionErrors)
 		if numConErrs > 0 {
@@ -119,23 +126,46 @@ func GetAllConnectionVersions() {
 	}
 }
 
+// checkConnectionCompatibility stops a command on a remote connection running a release of Codewind that cwctl does
+// not work with, rather than letting it fail on endpoints that were renamed or removed, and warns when some commands
+// may fail. Connections whose versions cannot be read are left to the command to report.
+func checkConnectionCompatibility(c *cli.Context, conInfo *connections.Connection, conURL string) {
+	if conInfo.ID == "local" || c.GlobalBool("skip-version-check") {
+		return
+	}
+	compatibility, err := apiroutes.GetConnectionCompatibility(conInfo, conURL, appconstants.VersionNum, sechttp.Client())
+	if err != nil {
+		return
+	}
+	switch compatibility.Status {
+	case apiroutes.CompatibilityIncompatible:
+		desc := strings.Join(compatibility.Messages, ". ")
+		HandleConnectionError(&connections.ConError{Op: apiroutes.ErrOpIncompatible, Err: compatibility.Err(), Desc: desc})
+		exit(1)
+	case apiroutes.CompatibilityWarning:
+		for _, message := range compatibility.Translate() {
+			logr.Warnln(message)
+		}
+	}
+}
+
 // RemoteListAll prints information for all remote installations in the given namespace
 func RemoteListAll(c *cli.Context) {
 	namespace := c.String("namespace")
 	remoteInstalls, err := remote.GetExistingDeployments(namespace, nil)
 	if err != nil {
 		HandleRemInstError(err)
-		os.Exit(1)
+		exit(1)
 	}
 	if printAsJSON {
-		utils.PrettyPrintJSON(remoteInstalls)
+		printResult(remoteInstalls)
 	} else {
 		var tableContent []string
-		tableContent = append(tableContent, "Workspace ID \tNamespace \tVersion \tInstall Date \tAuth Realm \tURL")
+		tableContent = append(tableContent, "Workspace ID \tNamespace \tVersion \tAge \tInstall Date \tAuth Realm \tGatekeeper URL")
 		for _, install := range remoteInstalls {
-			tableContent = append(tableContent, install.WorkspaceID+"\t"+install.Namespace+"\t"+install.Version+"\t"+install.InstallDate+"\t"+install.CodewindAuthRealm+"\t"+install.CodewindURL)
+			tableContent = append(tableContent, install.WorkspaceID+"\t"+install.Namespace+"\t"+install.Version+"\t"+install.Age+"\t"+install.InstallDate+"\t"+install.CodewindAuthRealm+"\t"+install.GatekeeperURL)
 		}
 		PrintTable(tableContent)
 	}
-	os.Exit(0)
+	exit(0)
 }
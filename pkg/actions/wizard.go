@@ -0,0 +1,261 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/remote/kube"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	"golang.org/x/crypto/ssh/terminal"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// hiddenValue replaces passwords in the equivalent command printed by the wizard
+const hiddenValue = "****"
+
+// wizardStep is a question the wizard asks to set a flag that was not given on the command line
+type wizardStep struct {
+	Flag     string
+	Prompt   string
+	Default  string
+	Required bool
+	Secret   bool
+	Validate func(string) error
+}
+
+// wizard asks the questions of its steps, repeating each one until the answer is valid
+type wizard struct {
+	in  *bufio.Reader
+	out io.Writer
+	// readSecret reads an answer without echoing it
+	readSecret func() (string, error)
+}
+
+// use variables so the wizard can be tested without a terminal or a cluster
+var (
+	wizardInput   io.Reader = os.Stdin
+	newWizard               = newTerminalWizard
+	kubeNamespace           = func() string {
+		namespace, _, err := kube.GetKubeClientConfig().Namespace()
+		if err != nil {
+			return ""
+		}
+		return namespace
+	}
+)
+
+func newTerminalWizard() *wizard {
+	w := &wizard{in: bufio.NewReader(wizardInput), out: commandOutput()}
+	w.readSecret = w.readLine
+	if file, ok := wizardInput.(*os.File); ok && terminal.IsTerminal(int(file.Fd())) {
+		w.readSecret = func() (string, error) {
+			secret, err := terminal.ReadPassword(int(file.Fd()))
+			fmt.Fprintln(w.out)
+			return string(secret), err
+		}
+	}
+	return w
+}
+
+// remoteInstallSteps are the questions asked by remote install --interactive
+func remoteInstallSteps() []wizardStep {
+	return []wizardStep{
+		{Flag: "namespace", Prompt: "Kubernetes namespace to install Codewind in", Default: kubeNamespace(), Required: true, Validate: validateDNSLabel},
+		{Flag: "ingress", Prompt: "Ingress domain, eg: 10.22.33.44.nip.io (leave empty to discover it)", Validate: validateDNSSubdomain},
+		{Flag: "kadminuser", Prompt: "Keycloak admin user", Default: "admin", Required: true},
+		{Flag: "kadminpass", Prompt: "Keycloak admin password", Required: true, Secret: true},
+		{Flag: "kdevuser", Prompt: "Codewind developer user to add to Keycloak", Default: "developer", Required: true},
+		{Flag: "kdevpass", Prompt: "Initial password of the developer user", Required: true, Secret: true},
+		{Flag: "storageclass", Prompt: "Storage class of the Codewind and Keycloak PVCs (leave empty for the cluster default)"},
+		{Flag: "pvcsize", Prompt: "Codewind PVC size in GB", Default: "1", Required: true, Validate: validatePVCSize},
+		{Flag: "kpvcsize", Prompt: "Keycloak PVC size in GB", Default: "1", Required: true, Validate: validatePVCSize},
+		{Flag: "pferesources", Prompt: "PFE resource requests and limits, eg: requests.cpu=500m,limits.memory=4Gi (leave empty for none)", Validate: validateResources},
+		{Flag: "perfresources", Prompt: "Performance dashboard resource requests and limits (leave empty for none)", Validate: validateResources},
+		{Flag: "gkresources", Prompt: "Gatekeeper resource requests and limits (leave empty for none)", Validate: validateResources},
+		{Flag: "kresources", Prompt: "Keycloak resource requests and limits (leave empty for none)", Validate: validateResources},
+	}
+}
+
+// remoteInstallWizard asks for the install settings that were not given as flags and sets the flags from the answers,
+// then prints the equivalent command and asks to go ahead. Exits if the install is cancelled or there is no input.
+func remoteInstallWizard(c *cli.Context) {
+	if c.String("file") != "" {
+		logr.Errorln("--interactive cannot be used with --file")
+		exit(1)
+	}
+	if globals.NonInteractive {
+		inputRequired("--interactive asks for the install settings, but prompts are turned off by --non-interactive")
+	}
+	w := newWizard()
+	fmt.Fprintln(w.out, "Answer each question to install Codewind, or press Enter to accept the default in brackets.")
+	for _, step := range remoteInstallSteps() {
+		if c.IsSet(step.Flag) {
+			continue
+		}
+		answer, err := w.ask(step)
+		if err != nil {
+			logr.Errorln(err)
+			exit(1)
+		}
+		if answer != "" {
+			c.Set(step.Flag, answer)
+		}
+	}
+
+	fmt.Fprintln(w.out, "\nThe equivalent command, with the passwords hidden, is:")
+	fmt.Fprintln(w.out, "  "+equivalentCommand(c, []string{"kadminpass", "kdevpass"}))
+	install, err := w.confirm("Install Codewind now?")
+	if err != nil {
+		logr.Errorln(err)
+		exit(1)
+	}
+	if !install {
+		logr.Errorln("Install cancelled")
+		exit(1)
+	}
+}
+
+// ask asks the question of a step until it gets a valid answer, returning the default for an empty answer
+func (w *wizard) ask(step wizardStep) (string, error) {
+	for {
+		if step.Default != "" {
+			fmt.Fprintf(w.out, "%s [%s]: ", step.Prompt, step.Default)
+		} else {
+			fmt.Fprintf(w.out, "%s: ", step.Prompt)
+		}
+		var answer string
+		var err error
+		if step.Secret {
+			answer, err = w.readSecret()
+		} else {
+			answer, err = w.readLine()
+		}
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = step.Default
+		}
+		if answer == "" {
+			if !step.Required {
+				return "", nil
+			}
+			fmt.Fprintln(w.out, "  A value is required")
+			continue
+		}
+		if step.Validate != nil {
+			if err := step.Validate(answer); err != nil {
+				fmt.Fprintln(w.out, "  "+err.Error())
+				continue
+			}
+		}
+		return answer, nil
+	}
+}
+
+// confirm asks a yes or no question, no being the default
+func (w *wizard) confirm(question string) (bool, error) {
+	fmt.Fprintf(w.out, "%s [y/N]: ", question)
+	answer, err := w.readLine()
+	if err != nil {
+		return false, err
+	}
+	answer = strings.ToLower(answer)
+	return answer == "y" || answer == "yes", nil
+}
+
+func (w *wizard) readLine() (string, error) {
+	line, err := w.in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err == io.EOF {
+		return "", errors.New("No answer given, the input ended")
+	}
+	return strings.TrimSpace(line), err
+}
+
+// equivalentCommand returns the command line that sets the flags set on a command, hiding the values of secret flags
+func equivalentCommand(c *cli.Context, secretFlags []string) string {
+	args := []string{c.Command.HelpName}
+	for _, flag := range c.Command.Flags {
+		name := flagNames(flag)[0]
+		if name == "interactive" || !c.IsSet(name) {
+			continue
+		}
+		switch flag.(type) {
+		case cli.BoolFlag:
+			args = append(args, "--"+name)
+		case cli.StringSliceFlag:
+			for _, value := range c.StringSlice(name) {
+				args = append(args, "--"+name, shellQuote(value))
+			}
+		default:
+			value := fmt.Sprint(c.Generic(name))
+			for _, secretFlag := range secretFlags {
+				if name == secretFlag {
+					value = hiddenValue
+				}
+			}
+			args = append(args, "--"+name, shellQuote(value))
+		}
+	}
+	return strings.Join(args, " ")
+}
+
+var shellSafe = regexp.MustCompile(`^[A-Za-z0-9_./:=,@%+-]+$`)
+
+// shellQuote quotes a value for a POSIX shell, unless it only has characters that need no quoting
+func shellQuote(value string) string {
+	if shellSafe.MatchString(value) {
+		return value
+	}
+	return "'" + strings.Replace(value, "'", `'\''`, -1) + "'"
+}
+
+func validateDNSLabel(value string) error {
+	if problems := validation.IsDNS1123Label(value); len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func validateDNSSubdomain(value string) error {
+	if problems := validation.IsDNS1123Subdomain(value); len(problems) > 0 {
+		return errors.New(strings.Join(problems, ", "))
+	}
+	return nil
+}
+
+func validatePVCSize(value string) error {
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 1 || size > 999 {
+		return errors.New("Must be a whole number of GB between 1 and 999")
+	}
+	return nil
+}
+
+func validateResources(value string) error {
+	_, err := remote.ParseResourceRequirements(value)
+	return err
+}
@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package actions
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+func newTestWizard(input string, out *bytes.Buffer) *wizard {
+	w := &wizard{in: bufio.NewReader(strings.NewReader(input)), out: out}
+	w.readSecret = w.readLine
+	return w
+}
+
+func Test_wizardAsk(t *testing.T) {
+	t.Run("repeats the question until the answer is valid", func(t *testing.T) {
+		var out bytes.Buffer
+		w := newTestWizard("0\nlots\n20\n", &out)
+		answer, err := w.ask(wizardStep{Prompt: "PVC size", Default: "1", Required: true, Validate: validatePVCSize})
+		assert.Nil(t, err)
+		assert.Equal(t, "20", answer)
+		assert.Equal(t, 3, strings.Count(out.String(), "PVC size [1]: "))
+	})
+
+	t.Run("returns the default for an empty answer", func(t *testing.T) {
+		w := newTestWizard("\n", &bytes.Buffer{})
+		answer, err := w.ask(wizardStep{Prompt: "Keycloak admin user", Default: "admin", Required: true})
+		assert.Nil(t, err)
+		assert.Equal(t, "admin", answer)
+	})
+
+	t.Run("asks again for a required value", func(t *testing.T) {
+		var out bytes.Buffer
+		w := newTestWizard("\nsecret\n", &out)
+		answer, err := w.ask(wizardStep{Prompt: "Password", Required: true, Secret: true})
+		assert.Nil(t, err)
+		assert.Equal(t, "secret", answer)
+		assert.Contains(t, out.String(), "A value is required")
+	})
+
+	t.Run("fails when the input ends", func(t *testing.T) {
+		w := newTestWizard("", &bytes.Buffer{})
+		_, err := w.ask(wizardStep{Prompt: "Namespace", Required: true})
+		assert.NotNil(t, err)
+	})
+}
+
+func Test_remoteInstallWizard(t *testing.T) {
+	originalNewWizard, originalKubeNamespace := newWizard, kubeNamespace
+	defer func() { newWizard, kubeNamespace = originalNewWizard, originalKubeNamespace }()
+	kubeNamespace = func() string { return "default" }
+	var out bytes.Buffer
+	// The namespace is given as a flag, so the first answer is the ingress domain
+	answers := []string{"Not A Domain", "10.0.0.1.nip.io", "", "admin pass", "dev", "devpass", "", "5", "", "requests.cpu=1", "", "", "", "y"}
+	newWizard = func() *wizard { return newTestWizard(strings.Join(answers, "\n")+"\n", &out) }
+
+	var namespace, ingress, kadminpass, pferesources string
+	var pvcsize, kpvcsize int
+	app := cli.NewApp()
+	app.Name, app.HelpName = "cwctl", "cwctl"
+	app.Commands = []cli.Command{{
+		Name:  "install",
+		Flags: remoteInstallFlags,
+		Action: func(c *cli.Context) error {
+			remoteInstallWizard(c)
+			namespace, ingress, kadminpass, pferesources = c.String("namespace"), c.String("ingress"), c.String("kadminpass"), c.String("pferesources")
+			pvcsize, kpvcsize = c.Int("pvcsize"), c.Int("kpvcsize")
+			return nil
+		},
+	}}
+	assert.Nil(t, app.Run([]string{"cwctl", "install", "--interactive", "--namespace", "codewind", "--wait"}))
+
+	assert.Equal(t, "codewind", namespace)
+	assert.Equal(t, "10.0.0.1.nip.io", ingress)
+	assert.Equal(t, "admin pass", kadminpass)
+	assert.Equal(t, "requests.cpu=1", pferesources)
+	assert.Equal(t, 5, pvcsize)
+	assert.Equal(t, 1, kpvcsize)
+	assert.NotContains(t, out.String(), "Kubernetes namespace")
+	assert.Contains(t, out.String(), "cwctl install --namespace codewind --ingress 10.0.0.1.nip.io --kadminuser admin --kadminpass '****' --kdevuser dev --kdevpass '****' --pvcsize 5 --kpvcsize 1 --pferesources requests.cpu=1 --wait\n")
+}
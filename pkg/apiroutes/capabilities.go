@@ -0,0 +1,61 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// CapabilityUploadDedup : PFE keeps the contents of uploaded files by their SHA-256 hash, so a file with the same
+// contents as one already uploaded can be sent as a reference to that hash instead
+const CapabilityUploadDedup = "upload_dedup"
+
+// GetPFECapabilities : Get the optional features supported by the PFE of a connection, as reported by its environment
+// API. Versions of PFE from before capabilities were reported support none of them.
+func GetPFECapabilities(connection *connections.Connection, conURL string, httpClient utils.HTTPClient) ([]string, error) {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/environment", nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, connection)
+	if httpSecError != nil {
+		return nil, httpSecError
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return []string{}, nil
+	}
+
+	var env EnvResponse
+	if err := json.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, err
+	}
+	if env.Capabilities == nil {
+		return []string{}, nil
+	}
+	return env.Capabilities, nil
+}
+
+// HasCapability : Reports whether a list of capabilities includes the given one
+func HasCapability(capabilities []string, capability string) bool {
+	for _, supported := range capabilities {
+		if supported == capability {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_GetPFECapabilities(t *testing.T) {
+	connection := &connections.Connection{ID: "local"}
+
+	t.Run("returns the capabilities PFE reports", func(t *testing.T) {
+		body := CreateMockResponseBody(EnvResponse{Version: "x.x.dev", Capabilities: []string{CapabilityUploadDedup}})
+		capabilities, err := GetPFECapabilities(connection, "dummyURL", &MockResponse{StatusCode: http.StatusOK, Body: body})
+		assert.Nil(t, err)
+		assert.True(t, HasCapability(capabilities, CapabilityUploadDedup))
+	})
+
+	t.Run("returns no capabilities for a PFE that does not report them", func(t *testing.T) {
+		body := CreateMockResponseBody(EnvResponse{Version: "x.x.dev"})
+		capabilities, err := GetPFECapabilities(connection, "dummyURL", &MockResponse{StatusCode: http.StatusOK, Body: body})
+		assert.Nil(t, err)
+		assert.Empty(t, capabilities)
+		assert.False(t, HasCapability(capabilities, CapabilityUploadDedup))
+	})
+
+	t.Run("returns no capabilities when the environment API fails", func(t *testing.T) {
+		body := CreateMockResponseBody("")
+		capabilities, err := GetPFECapabilities(connection, "dummyURL", &MockResponse{StatusCode: http.StatusInternalServerError, Body: body})
+		assert.Nil(t, err)
+		assert.Empty(t, capabilities)
+	})
+}
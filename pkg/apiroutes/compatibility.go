@@ -0,0 +1,230 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// ErrOpIncompatible : The operation of failures caused by a connection running a release of Codewind that cwctl
+// does not work with
+const ErrOpIncompatible = "con_incompatible"
+
+// Outcomes of a compatibility check
+const (
+	// CompatibilityOK : Codewind is the same release as cwctl
+	CompatibilityOK = "compatible"
+	// CompatibilityWarning : Codewind is one release older or newer than cwctl, so some commands may fail
+	CompatibilityWarning = "warning"
+	// CompatibilityIncompatible : Codewind is further from the release of cwctl, and operations are refused
+	CompatibilityIncompatible = "incompatible"
+	// CompatibilityUnknown : cwctl or Codewind is a development build, or did not report its version
+	CompatibilityUnknown = "unknown"
+)
+
+// Messages of a compatibility check, given the component, its version and the version of cwctl
+const (
+	textIncompatibleRelease = "%s %s cannot be used with cwctl %s, install the cwctl of the same release as Codewind"
+	textNewerRelease        = "%s %s is newer than cwctl %s, some commands may fail until cwctl is upgraded"
+	textOlderRelease        = "%s %s is older than cwctl %s, some commands may fail until Codewind is upgraded"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgIncompatibleRelease = "apiroutes.incompatible_release"
+	msgNewerRelease        = "apiroutes.newer_release"
+	msgOlderRelease        = "apiroutes.older_release"
+)
+
+// Compatibility : Whether the components of a connection are a release of Codewind cwctl works with
+type Compatibility struct {
+	Status   string   `json:"status"`
+	Messages []string `json:"messages,omitempty"`
+	// messages are the Messages as they were built, so that they can be translated
+	messages []*i18n.Message
+}
+
+// Translate : Returns the messages of the compatibility check in the selected locale
+func (c Compatibility) Translate() []string {
+	translations := []string{}
+	for _, message := range c.messages {
+		translations = append(translations, message.Translate())
+	}
+	return translations
+}
+
+// Err : Returns the messages of the compatibility check as one error, which can be translated
+func (c Compatibility) Err() error {
+	return i18n.Join(c.messages, ". ")
+}
+
+// releaseVersion matches the major and minor version of a release, such as 0.14 of 0.14.0 or 0.14.0-20200708-1234
+var releaseVersion = regexp.MustCompile(`^v?(\d+)\.(\d+)\.\d+`)
+
+// CheckCompatibility : Compares the versions of the PFE and Gatekeeper of a connection with the version of cwctl.
+// Components of the same release work together, and those one minor release apart mostly do, as endpoints are only
+// renamed or removed after a release has deprecated them.
+func CheckCompatibility(cwctlVersion string, versions ContainerVersions) Compatibility {
+	compatibility := Compatibility{Status: CompatibilityOK}
+	components := []struct{ name, version string }{{"PFE", versions.PFEVersion}, {"Gatekeeper", versions.GatekeeperVersion}}
+	for i, component := range components {
+		// Only remote connections have a Gatekeeper
+		if i > 0 && component.version == "" {
+			continue
+		}
+		status, message := compareReleases(cwctlVersion, component.name, component.version)
+		compatibility.Status = worseCompatibility(compatibility.Status, status)
+		if message != nil {
+			compatibility.Messages = append(compatibility.Messages, message.Error())
+			compatibility.messages = append(compatibility.messages, message)
+		}
+	}
+	return compatibility
+}
+
+// compareReleases compares the release of a component with that of cwctl
+func compareReleases(cwctlVersion string, component string, version string) (string, *i18n.Message) {
+	cwctlMajor, cwctlMinor, cwctlRelease := parseRelease(cwctlVersion)
+	major, minor, release := parseRelease(version)
+	if !cwctlRelease || !release {
+		return CompatibilityUnknown, nil
+	}
+	switch distance := minor - cwctlMinor; {
+	case major != cwctlMajor || distance > 1 || distance < -1:
+		return CompatibilityIncompatible, i18n.NewMessage(msgIncompatibleRelease, textIncompatibleRelease, component, version, cwctlVersion)
+	case distance == 1:
+		return CompatibilityWarning, i18n.NewMessage(msgNewerRelease, textNewerRelease, component, version, cwctlVersion)
+	case distance == -1:
+		return CompatibilityWarning, i18n.NewMessage(msgOlderRelease, textOlderRelease, component, version, cwctlVersion)
+	}
+	return CompatibilityOK, nil
+}
+
+// parseRelease returns the major and minor version of a release, and false for development builds such as x.x.dev
+func parseRelease(version string) (int, int, bool) {
+	match := releaseVersion.FindStringSubmatch(version)
+	if match == nil {
+		return 0, 0, false
+	}
+	major, _ := strconv.Atoi(match[1])
+	minor, _ := strconv.Atoi(match[2])
+	return major, minor, true
+}
+
+// worseCompatibility returns the outcome that most limits what cwctl can do
+func worseCompatibility(current string, status string) string {
+	order := map[string]int{CompatibilityOK: 0, CompatibilityUnknown: 1, CompatibilityWarning: 2, CompatibilityIncompatible: 3}
+	if order[status] > order[current] {
+		return status
+	}
+	return current
+}
+
+// GetConnectionCompatibility : Checks the versions of the PFE and Gatekeeper of a connection against the version of
+// cwctl. The versions of remote connections are reused for a few minutes, so commands run one after another do not
+// each ask for them, except incompatible ones so that an upgraded Codewind can be used straight away.
+func GetConnectionCompatibility(connection *connections.Connection, conURL string, cwctlVersion string, httpClient utils.HTTPClient) (Compatibility, error) {
+	if versions, ok := loadCachedVersions(connection.ID, conURL); ok {
+		return CheckCompatibility(cwctlVersion, versions), nil
+	}
+	versions := ContainerVersions{}
+	pfeVersion, err := GetPFEVersionFromConnection(connection, conURL, httpClient)
+	if err != nil {
+		return Compatibility{}, err
+	}
+	versions.PFEVersion = pfeVersion
+	if connection.ID != "local" {
+		gatekeeperVersion, err := GetGatekeeperVersionFromConnection(connection, conURL, httpClient)
+		if err != nil {
+			return Compatibility{}, err
+		}
+		versions.GatekeeperVersion = gatekeeperVersion
+	}
+	compatibility := CheckCompatibility(cwctlVersion, versions)
+	if connection.ID != "local" && compatibility.Status != CompatibilityIncompatible {
+		saveCachedVersions(connection.ID, conURL, versions)
+	}
+	return compatibility, nil
+}
+
+// versionsTTL is how long the versions read from a connection are reused by later commands
+const versionsTTL = 10 * time.Minute
+
+// versionsCacheFilename returns the file the versions of connections are kept in between commands
+var versionsCacheFilename = func() string {
+	return filepath.Join(connections.GetConnectionConfigDir(), "versions.json")
+}
+
+// cachedVersions : The versions of the PFE and Gatekeeper of a connection, when and from which URL they were read
+type cachedVersions struct {
+	URL               string    `json:"url"`
+	PFEVersion        string    `json:"PFEVersion"`
+	GatekeeperVersion string    `json:"gatekeeperVersion,omitempty"`
+	CheckedAt         time.Time `json:"checkedAt"`
+}
+
+// loadVersionsCache reads the versions kept for each connection, treating a missing or unreadable file as empty
+func loadVersionsCache() map[string]cachedVersions {
+	cache := map[string]cachedVersions{}
+	contents, err := ioutil.ReadFile(versionsCacheFilename())
+	if err != nil || json.Unmarshal(contents, &cache) != nil {
+		return map[string]cachedVersions{}
+	}
+	return cache
+}
+
+// loadCachedVersions returns the versions kept for a connection, when they were read from its URL less than
+// versionsTTL ago
+func loadCachedVersions(conID string, conURL string) (ContainerVersions, bool) {
+	cached, ok := loadVersionsCache()[conID]
+	if !ok || cached.URL != conURL || time.Since(cached.CheckedAt) > versionsTTL {
+		return ContainerVersions{}, false
+	}
+	return ContainerVersions{PFEVersion: cached.PFEVersion, GatekeeperVersion: cached.GatekeeperVersion}, true
+}
+
+// saveCachedVersions keeps the versions read from a connection. The cache only saves requests, so failures to write
+// it are ignored, and it is replaced in one rename so that commands run at the same time do not read part of it.
+func saveCachedVersions(conID string, conURL string, versions ContainerVersions) {
+	cache := loadVersionsCache()
+	cache[conID] = cachedVersions{URL: conURL, PFEVersion: versions.PFEVersion, GatekeeperVersion: versions.GatekeeperVersion, CheckedAt: time.Now()}
+	contents, err := json.MarshalIndent(cache, "", "  ")
+	if err != nil {
+		return
+	}
+	filename := versionsCacheFilename()
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		return
+	}
+	staged, err := ioutil.TempFile(filepath.Dir(filename), filepath.Base(filename))
+	if err != nil {
+		return
+	}
+	_, err = staged.Write(contents)
+	staged.Close()
+	if err == nil {
+		err = os.Rename(staged.Name(), filename)
+	}
+	if err != nil {
+		os.Remove(staged.Name())
+	}
+}
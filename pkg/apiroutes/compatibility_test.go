@@ -0,0 +1,178 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckCompatibility(t *testing.T) {
+	tests := map[string]struct {
+		cwctlVersion string
+		versions     ContainerVersions
+		status       string
+		messages     int
+	}{
+		"success case: same release with a build time": {
+			cwctlVersion: "0.14.0",
+			versions:     ContainerVersions{PFEVersion: "0.14.0-20200708-1234", GatekeeperVersion: "0.14.1"},
+			status:       CompatibilityOK,
+		},
+		"success case: local connections have no Gatekeeper": {
+			cwctlVersion: "0.14.0",
+			versions:     ContainerVersions{PFEVersion: "0.14.0"},
+			status:       CompatibilityOK,
+		},
+		"success case: development builds are not compared": {
+			cwctlVersion: "x.x.dev",
+			versions:     ContainerVersions{PFEVersion: "0.9.0"},
+			status:       CompatibilityUnknown,
+		},
+		"warning case: one release older": {
+			cwctlVersion: "0.14.0",
+			versions:     ContainerVersions{PFEVersion: "0.13.0", GatekeeperVersion: "0.13.0"},
+			status:       CompatibilityWarning,
+			messages:     2,
+		},
+		"warning case: one release newer": {
+			cwctlVersion: "0.13.0",
+			versions:     ContainerVersions{PFEVersion: "0.14.0"},
+			status:       CompatibilityWarning,
+			messages:     1,
+		},
+		"error case: two releases apart": {
+			cwctlVersion: "0.14.0",
+			versions:     ContainerVersions{PFEVersion: "0.14.0", GatekeeperVersion: "0.12.0"},
+			status:       CompatibilityIncompatible,
+			messages:     1,
+		},
+		"error case: different major version": {
+			cwctlVersion: "0.14.0",
+			versions:     ContainerVersions{PFEVersion: "1.14.0"},
+			status:       CompatibilityIncompatible,
+			messages:     1,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			compatibility := CheckCompatibility(test.cwctlVersion, test.versions)
+			assert.Equal(t, test.status, compatibility.Status)
+			assert.Len(t, compatibility.Messages, test.messages)
+		})
+	}
+
+	t.Run("success case: messages name the component and both versions", func(t *testing.T) {
+		compatibility := CheckCompatibility("0.14.0", ContainerVersions{PFEVersion: "0.11.0"})
+		assert.Equal(t, []string{"PFE 0.11.0 cannot be used with cwctl 0.14.0, install the cwctl of the same release as Codewind"}, compatibility.Messages)
+	})
+
+	t.Run("success case: messages are translated, and kept in English for JSON", func(t *testing.T) {
+		defer i18n.SetLocale(i18n.DefaultLocale)
+		i18n.SetLocale("de")
+		compatibility := CheckCompatibility("0.14.0", ContainerVersions{PFEVersion: "0.13.0", GatekeeperVersion: "0.15.0"})
+		assert.Equal(t, []string{
+			"PFE 0.13.0 ist älter als cwctl 0.14.0, einige Befehle können fehlschlagen, bis Codewind aktualisiert wird",
+			"Gatekeeper 0.15.0 ist neuer als cwctl 0.14.0, einige Befehle können fehlschlagen, bis cwctl aktualisiert wird",
+		}, compatibility.Translate())
+		assert.Equal(t, "PFE 0.13.0 is older than cwctl 0.14.0, some commands may fail until Codewind is upgraded", compatibility.Messages[0])
+		assert.Equal(t, strings.Join(compatibility.Messages, ". "), compatibility.Err().Error())
+	})
+}
+
+func TestGetConnectionCompatibility(t *testing.T) {
+	cacheDir, _ := ioutil.TempDir("", "cwctl-versions")
+	defer os.RemoveAll(cacheDir)
+	originalFilename := versionsCacheFilename
+	defer func() { versionsCacheFilename = originalFilename }()
+	versionsCacheFilename = func() string { return filepath.Join(cacheDir, "versions.json") }
+
+	t.Run("success case: checks the PFE of local connections", func(t *testing.T) {
+		mockClient := MockMultipleResponses{
+			MockResponses: []MockResponse{{StatusCode: http.StatusOK, Body: CreateMockResponseBody(EnvResponse{Version: "0.12.0", ImageBuildTime: "20200708"})}},
+		}
+		compatibility, err := GetConnectionCompatibility(&connections.Connection{ID: "local"}, "http://localhost", "0.14.0", &mockClient)
+		assert.Nil(t, err)
+		assert.Equal(t, CompatibilityIncompatible, compatibility.Status)
+		assert.Equal(t, 1, mockClient.Counter)
+	})
+
+	t.Run("success case: versions Codewind does not report are unknown", func(t *testing.T) {
+		mockClient := MockMultipleResponses{
+			MockResponses: []MockResponse{{StatusCode: http.StatusNotFound, Body: CreateMockResponseBody("")}},
+		}
+		compatibility, err := GetConnectionCompatibility(&connections.Connection{ID: "local"}, "http://localhost", "0.14.0", &mockClient)
+		assert.Nil(t, err)
+		assert.Equal(t, CompatibilityUnknown, compatibility.Status)
+	})
+
+	t.Run("success case: reuses the versions of a remote connection read recently", func(t *testing.T) {
+		saveCachedVersions("remote1", "https://gatekeeper.remote", ContainerVersions{PFEVersion: "0.13.0-20200708", GatekeeperVersion: "0.14.0-20200708"})
+		mockClient := MockMultipleResponses{}
+		compatibility, err := GetConnectionCompatibility(&connections.Connection{ID: "remote1"}, "https://gatekeeper.remote", "0.14.0", &mockClient)
+		assert.Nil(t, err)
+		assert.Equal(t, CompatibilityWarning, compatibility.Status)
+		assert.Len(t, compatibility.Translate(), 1)
+		assert.Equal(t, 0, mockClient.Counter)
+	})
+}
+
+func TestCachedVersions(t *testing.T) {
+	cacheDir, _ := ioutil.TempDir("", "cwctl-versions")
+	defer os.RemoveAll(cacheDir)
+	originalFilename := versionsCacheFilename
+	defer func() { versionsCacheFilename = originalFilename }()
+	versionsCacheFilename = func() string { return filepath.Join(cacheDir, "config", "versions.json") }
+	versions := ContainerVersions{PFEVersion: "0.14.0", GatekeeperVersion: "0.14.0"}
+
+	t.Run("success case: versions are kept for each connection", func(t *testing.T) {
+		saveCachedVersions("remote1", "https://gatekeeper.remote", versions)
+		saveCachedVersions("remote2", "https://other.remote", ContainerVersions{PFEVersion: "0.13.0"})
+		cached, ok := loadCachedVersions("remote1", "https://gatekeeper.remote")
+		assert.True(t, ok)
+		assert.Equal(t, versions, cached)
+		cached, ok = loadCachedVersions("remote2", "https://other.remote")
+		assert.True(t, ok)
+		assert.Equal(t, "0.13.0", cached.PFEVersion)
+	})
+
+	t.Run("error case: versions read from another URL are not used", func(t *testing.T) {
+		saveCachedVersions("remote1", "https://gatekeeper.remote", versions)
+		_, ok := loadCachedVersions("remote1", "https://moved.remote")
+		assert.False(t, ok)
+	})
+
+	t.Run("error case: versions older than the TTL are not used", func(t *testing.T) {
+		contents, _ := json.Marshal(map[string]cachedVersions{
+			"remote1": {URL: "https://gatekeeper.remote", PFEVersion: "0.14.0", CheckedAt: time.Now().Add(-versionsTTL - time.Minute)},
+		})
+		ioutil.WriteFile(versionsCacheFilename(), contents, 0644)
+		_, ok := loadCachedVersions("remote1", "https://gatekeeper.remote")
+		assert.False(t, ok)
+	})
+
+	t.Run("error case: an unreadable cache is ignored", func(t *testing.T) {
+		ioutil.WriteFile(versionsCacheFilename(), []byte("{"), 0644)
+		_, ok := loadCachedVersions("remote1", "https://gatekeeper.remote")
+		assert.False(t, ok)
+	})
+}
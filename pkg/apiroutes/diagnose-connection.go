@@ -0,0 +1,205 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/gatekeeper"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// Outcomes of a connection check
+const (
+	CheckPassed  = "passed"
+	CheckFailed  = "failed"
+	CheckSkipped = "skipped"
+)
+
+// diagnoseTimeout is how long the DNS and TLS checks wait before giving up
+const diagnoseTimeout = 10 * time.Second
+
+type (
+	// ConnectionDiagnosis : The result of each check made against a connection, in the order they were made
+	ConnectionDiagnosis struct {
+		ConnectionID string            `json:"id"`
+		URL          string            `json:"url"`
+		Healthy      bool              `json:"healthy"`
+		Checks       []ConnectionCheck `json:"checks"`
+	}
+
+	// ConnectionCheck : A single check, and how long it took in milliseconds
+	ConnectionCheck struct {
+		Name    string `json:"name"`
+		Status  string `json:"status"`
+		Message string `json:"message,omitempty"`
+		Latency int64  `json:"latencyMs"`
+	}
+)
+
+// DiagnoseConnection : Check DNS resolution, the TLS handshake, the Gatekeeper environment, authentication and the
+// round trip to PFE for a connection. Each check is skipped once an earlier one fails, as its result would only
+// repeat the same problem.
+func DiagnoseConnection(httpClient utils.HTTPClient, connection *connections.Connection, insecure bool) ConnectionDiagnosis {
+	diagnosis := ConnectionDiagnosis{ConnectionID: strings.ToUpper(connection.ID), Checks: []ConnectionCheck{}}
+	remote := strings.ToLower(connection.ID) != "local"
+
+	conURL, conErr := config.PFEOriginFromConnection(connection)
+	diagnosis.URL = conURL
+	if conErr != nil {
+		diagnosis.Checks = append(diagnosis.Checks, ConnectionCheck{Name: "url", Status: CheckFailed, Message: conErr.Desc})
+		return diagnosis
+	}
+	parsedURL, err := url.Parse(conURL)
+	if err != nil || parsedURL.Hostname() == "" {
+		diagnosis.Checks = append(diagnosis.Checks, ConnectionCheck{Name: "url", Status: CheckFailed, Message: "Connection URL " + conURL + " is not valid"})
+		return diagnosis
+	}
+
+	// DNS and the TLS handshake are the proxy's concern when there is one
+	direct := remote && connection.ProxyURL == ""
+	connectionClient, transportErr := sechttp.ConnectionHTTPClient(httpClient, connection)
+	if transportErr != nil {
+		diagnosis.Checks = append(diagnosis.Checks, ConnectionCheck{Name: "transport", Status: CheckFailed, Message: transportErr.Desc})
+		return diagnosis
+	}
+
+	checks := []struct {
+		name     string
+		required bool
+		run      func() ConnectionCheck
+	}{
+		{"dns", direct, func() ConnectionCheck { return checkDNS(parsedURL.Hostname()) }},
+		{"tls", direct && parsedURL.Scheme == "https", func() ConnectionCheck { return checkTLS(parsedURL, connection, insecure) }},
+		{"gatekeeper", remote, func() ConnectionCheck { return checkGatekeeper(connectionClient, connection, conURL) }},
+		{"pfe", true, func() ConnectionCheck { return checkPFE(httpClient, connection, conURL) }},
+	}
+	failed := false
+	for _, check := range checks {
+		if !check.required || failed {
+			diagnosis.Checks = append(diagnosis.Checks, ConnectionCheck{Name: check.name, Status: CheckSkipped})
+			continue
+		}
+		start := time.Now()
+		result := check.run()
+		result.Name = check.name
+		result.Latency = time.Since(start).Nanoseconds() / int64(time.Millisecond)
+		failed = result.Status == CheckFailed
+		diagnosis.Checks = append(diagnosis.Checks, result)
+	}
+	diagnosis.Healthy = !failed
+	return diagnosis
+}
+
+// checkDNS resolves the connection hostname
+func checkDNS(hostname string) ConnectionCheck {
+	addresses, err := net.LookupHost(hostname)
+	if err != nil {
+		return ConnectionCheck{Status: CheckFailed, Message: "Unable to resolve " + hostname + ": " + err.Error()}
+	}
+	return ConnectionCheck{Status: CheckPassed, Message: hostname + " resolves to " + strings.Join(addresses, ", ")}
+}
+
+// checkTLS completes a TLS handshake with the connection host, using the CA bundle of the connection, and reports
+// the certificate it presents
+func checkTLS(connectionURL *url.URL, connection *connections.Connection, insecure bool) ConnectionCheck {
+	port := connectionURL.Port()
+	if port == "" {
+		port = "443"
+	}
+	tlsConfig, tlsErr := sechttp.ConnectionTLSConfig(connection)
+	if tlsErr != nil {
+		return ConnectionCheck{Status: CheckFailed, Message: tlsErr.Desc}
+	}
+	tlsConfig.ServerName = connectionURL.Hostname()
+	if insecure {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	dialer := &net.Dialer{Timeout: diagnoseTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", net.JoinHostPort(connectionURL.Hostname(), port), tlsConfig)
+	if err != nil {
+		message := "TLS handshake failed: " + err.Error()
+		if !tlsConfig.InsecureSkipVerify {
+			message += ". If the Gatekeeper certificate is signed by an internal CA, add its bundle to the connection with --cacert, or retry with --insecure"
+		}
+		return ConnectionCheck{Status: CheckFailed, Message: message}
+	}
+	defer conn.Close()
+
+	certificates := conn.ConnectionState().PeerCertificates
+	if len(certificates) == 0 {
+		return ConnectionCheck{Status: CheckPassed, Message: "TLS handshake succeeded"}
+	}
+	certificate := certificates[0]
+	message := "Certificate for " + certificate.Subject.CommonName + " issued by " + certificate.Issuer.CommonName + ", expires " + certificate.NotAfter.Format(time.RFC3339)
+	if time.Now().After(certificate.NotAfter) {
+		return ConnectionCheck{Status: CheckFailed, Message: message + " (expired)"}
+	}
+	return ConnectionCheck{Status: CheckPassed, Message: message}
+}
+
+// checkGatekeeper fetches the Gatekeeper environment and compares it with the authentication details of the connection
+func checkGatekeeper(httpClient utils.HTTPClient, connection *connections.Connection, conURL string) ConnectionCheck {
+	environment, err := gatekeeper.GetGatekeeperEnvironment(httpClient, conURL)
+	if err != nil {
+		return ConnectionCheck{Status: CheckFailed, Message: err.Error()}
+	}
+	mismatches := []string{}
+	if environment.AuthURL != connection.AuthURL {
+		mismatches = append(mismatches, "auth URL "+environment.AuthURL)
+	}
+	if environment.Realm != connection.Realm {
+		mismatches = append(mismatches, "realm "+environment.Realm)
+	}
+	if environment.ClientID != connection.ClientID {
+		mismatches = append(mismatches, "client "+environment.ClientID)
+	}
+	if len(mismatches) > 0 {
+		return ConnectionCheck{Status: CheckFailed, Message: "Gatekeeper now uses " + strings.Join(mismatches, ", ") + ". Update the connection to match"}
+	}
+	return ConnectionCheck{Status: CheckPassed, Message: "Gatekeeper uses realm " + environment.Realm + " at " + environment.AuthURL}
+}
+
+// checkPFE sends an authenticated request to PFE, refreshing or renewing the access token if needed
+func checkPFE(httpClient utils.HTTPClient, connection *connections.Connection, conURL string) ConnectionCheck {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/environment", nil)
+	if err != nil {
+		return ConnectionCheck{Status: CheckFailed, Message: err.Error()}
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, connection)
+	if httpSecError != nil {
+		return ConnectionCheck{Status: CheckFailed, Message: httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return ConnectionCheck{Status: CheckFailed, Message: "PFE responded with status " + strconv.Itoa(resp.StatusCode)}
+	}
+
+	message := "PFE responded"
+	if strings.ToLower(connection.ID) != "local" {
+		accessToken, _ := security.GetSecretFromKeyring(strings.ToLower(connection.ID), "access_token")
+		if expiry, ok := security.TokenExpiry(accessToken); ok {
+			message += ", access token valid until " + expiry.Format(time.RFC3339)
+		}
+	}
+	return ConnectionCheck{Status: CheckPassed, Message: message}
+}
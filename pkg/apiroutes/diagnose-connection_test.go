@@ -0,0 +1,98 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package apiroutes
+
+import (
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/gatekeeper"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_DiagnoseConnection(t *testing.T) {
+	t.Run("Later checks are skipped once the TLS handshake fails", func(t *testing.T) {
+		connection := connections.Connection{ID: "REMOTE", URL: "https://127.0.0.1:1"}
+		diagnosis := DiagnoseConnection(&MockResponse{StatusCode: http.StatusOK}, &connection, false)
+		assert.False(t, diagnosis.Healthy)
+		assert.Equal(t, "https://127.0.0.1:1", diagnosis.URL)
+		assert.Len(t, diagnosis.Checks, 4)
+		assert.Equal(t, CheckPassed, diagnosis.Checks[0].Status)
+		assert.Equal(t, "tls", diagnosis.Checks[1].Name)
+		assert.Equal(t, CheckFailed, diagnosis.Checks[1].Status)
+		assert.Equal(t, CheckSkipped, diagnosis.Checks[2].Status)
+		assert.Equal(t, CheckSkipped, diagnosis.Checks[3].Status)
+	})
+	t.Run("An invalid URL fails without making any requests", func(t *testing.T) {
+		connection := connections.Connection{ID: "REMOTE", URL: "not a url"}
+		diagnosis := DiagnoseConnection(&MockResponse{StatusCode: http.StatusOK}, &connection, false)
+		assert.False(t, diagnosis.Healthy)
+		assert.Len(t, diagnosis.Checks, 1)
+		assert.Equal(t, "url", diagnosis.Checks[0].Name)
+	})
+}
+
+func Test_CheckTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+	serverURL, _ := url.Parse(server.URL)
+
+	connection := connections.Connection{ID: "REMOTE", URL: server.URL}
+
+	t.Run("A self-signed certificate fails verification", func(t *testing.T) {
+		check := checkTLS(serverURL, &connection, false)
+		assert.Equal(t, CheckFailed, check.Status)
+		assert.Contains(t, check.Message, "--cacert")
+		assert.Contains(t, check.Message, "--insecure")
+	})
+	t.Run("A self-signed certificate is accepted when insecure", func(t *testing.T) {
+		check := checkTLS(serverURL, &connection, true)
+		assert.Equal(t, CheckPassed, check.Status)
+		assert.Contains(t, check.Message, "expires")
+	})
+	t.Run("A certificate signed by the connection CA bundle is accepted", func(t *testing.T) {
+		caFile, _ := ioutil.TempFile("", "cacert")
+		defer os.Remove(caFile.Name())
+		pem.Encode(caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		caFile.Close()
+
+		trusted := connection
+		trusted.CACert = caFile.Name()
+		check := checkTLS(serverURL, &trusted, false)
+		assert.Equal(t, CheckPassed, check.Status)
+	})
+}
+
+func Test_CheckGatekeeper(t *testing.T) {
+	environment := gatekeeper.GatekeeperEnvironment{AuthURL: "https://auth.remote", Realm: "codewind", ClientID: "codewind-client"}
+	connection := connections.Connection{ID: "REMOTE", URL: "https://gatekeeper.remote", AuthURL: "https://auth.remote", Realm: "codewind", ClientID: "codewind-client"}
+
+	t.Run("Passes when the Gatekeeper matches the connection", func(t *testing.T) {
+		mockClient := &MockResponse{StatusCode: http.StatusOK, Body: CreateMockResponseBody(environment)}
+		check := checkGatekeeper(mockClient, &connection, connection.URL)
+		assert.Equal(t, CheckPassed, check.Status)
+	})
+	t.Run("Fails when the Gatekeeper has moved to another realm", func(t *testing.T) {
+		moved := environment
+		moved.Realm = "other"
+		mockClient := &MockResponse{StatusCode: http.StatusOK, Body: CreateMockResponseBody(moved)}
+		check := checkGatekeeper(mockClient, &connection, connection.URL)
+		assert.Equal(t, CheckFailed, check.Status)
+		assert.Contains(t, check.Message, "realm other")
+	})
+}
@@ -37,7 +37,7 @@ func GetExtensions(conID string) ([]utils.Extension, error) {
 	if err != nil {
 		return nil, err
 	}
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := sechttp.DispatchHTTPRequest(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -86,7 +86,7 @@ func GetTemplates(conID, projectStyle string, showEnabledOnly bool) ([]Template,
 		query.Add("showEnabledOnly", "true")
 	}
 	req.URL.RawQuery = query.Encode()
-	client := &http.Client{}
+	client := sechttp.Client()
 
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
@@ -128,7 +128,7 @@ func GetTemplateStyles(conID string) ([]string, error) {
 	if err != nil {
 		return nil, err
 	}
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -163,7 +163,7 @@ func GetTemplateRepos(conID string) ([]utils.TemplateRepo, error) {
 	if err != nil {
 		return nil, err
 	}
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -215,7 +215,7 @@ func AddTemplateRepoToPFE(conID, URL, description, name string, gitCredentials *
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -265,7 +265,7 @@ func DeleteTemplateRepoFromPFE(conID, URL string) ([]utils.TemplateRepo, error)
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -374,7 +374,7 @@ func BatchPatchTemplateRepos(conID string, operations []RepoOperation) ([]SubRes
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{}
+	client := sechttp.Client()
 	resp, httpSecError := HTTPRequestWithRetryOnLock(client, req, conInfo)
 	if httpSecError != nil {
 		return nil, httpSecError
@@ -14,6 +14,7 @@ package apiroutes
 import (
 	"bytes"
 	"encoding/json"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -40,8 +41,11 @@ func (c *MockResponse) Do(req *http.Request) (*http.Response, error) {
 	}, nil
 }
 
-// Do makes a http request and increments the MockMultipleResponses counter
+// Do makes a http request and increments the MockMultipleResponses counter, failing once the responses are used up
 func (c *MockMultipleResponses) Do(req *http.Request) (*http.Response, error) {
+	if c.Counter >= len(c.MockResponses) {
+		return nil, fmt.Errorf("no mock response for request %d to %v", c.Counter+1, req.URL)
+	}
 	response := c.MockResponses[c.Counter]
 	c.Counter++
 	return &http.Response{
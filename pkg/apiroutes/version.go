@@ -37,12 +37,15 @@ type (
 		PerformanceVersion string `json:"performanceVersion"`
 		GatekeeperVersion  string `json:"gatekeeperVersion,omitempty"`
 		PFEVersion         string `json:"PFEVersion"`
+		// Compatibility is whether the containers are a release cwctl works with, when it has been checked
+		Compatibility *Compatibility `json:"compatibility,omitempty"`
 	}
 
 	// EnvResponse : The relevant response fields from the remote environment API
 	EnvResponse struct {
-		Version        string `json:"codewind_version"`
-		ImageBuildTime string `json:"image_build_time"`
+		Version        string   `json:"codewind_version"`
+		ImageBuildTime string   `json:"image_build_time"`
+		Capabilities   []string `json:"capabilities,omitempty"`
 	}
 )
 
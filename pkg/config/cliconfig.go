@@ -0,0 +1,167 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	logr "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+// CLIConfigFile : The file in the Codewind directory holding the user's defaults for cwctl
+const CLIConfigFile = "cli-config.yaml"
+
+const (
+	errOpCLIConfigRead  = "config_cli_read"
+	errOpCLIConfigWrite = "config_cli_write"
+	errOpCLIConfigKey   = "config_cli_key"
+	errOpCLIConfigValue = "config_cli_value"
+)
+
+// Keys of the settings in the CLI config file
+const (
+	KeyConnection      = "connection"
+	KeyJSON            = "json"
+	KeyKubeconfig      = "kubeconfig"
+	KeyLogLevel        = "loglevel"
+	KeyRegistryMirror  = "registry-mirror"
+	KeySyncConcurrency = "sync-concurrency"
+)
+
+// CLIConfigKeys : The keys of the settings in the CLI config file, in the order they are listed
+var CLIConfigKeys = []string{KeyConnection, KeyJSON, KeyKubeconfig, KeyLogLevel, KeyRegistryMirror, KeySyncConcurrency}
+
+// CLIConfig : Defaults for cwctl, used in place of the built-in defaults when a flag or its environment variable is
+// not set. Unset settings are empty.
+type CLIConfig struct {
+	Connection      string `yaml:"connection,omitempty" json:"connection,omitempty"`
+	JSON            bool   `yaml:"json,omitempty" json:"json,omitempty"`
+	Kubeconfig      string `yaml:"kubeconfig,omitempty" json:"kubeconfig,omitempty"`
+	LogLevel        string `yaml:"loglevel,omitempty" json:"loglevel,omitempty"`
+	RegistryMirror  string `yaml:"registry-mirror,omitempty" json:"registry-mirror,omitempty"`
+	SyncConcurrency int    `yaml:"sync-concurrency,omitempty" json:"sync-concurrency,omitempty"`
+}
+
+// LoadCLIConfig : Reads the CLI config file from a directory, returning no settings if there is no file
+func LoadCLIConfig(dir string) (CLIConfig, *ConfigError) {
+	cliConfig := CLIConfig{}
+	contents, err := ioutil.ReadFile(filepath.Join(dir, CLIConfigFile))
+	if os.IsNotExist(err) {
+		return cliConfig, nil
+	}
+	if err != nil {
+		return cliConfig, &ConfigError{errOpCLIConfigRead, err, err.Error()}
+	}
+	// Reject unknown keys so that a misspelt setting is not silently ignored
+	if err := yaml.UnmarshalStrict(contents, &cliConfig); err != nil {
+		return CLIConfig{}, &ConfigError{errOpCLIConfigRead, err, "Invalid " + filepath.Join(dir, CLIConfigFile) + ": " + err.Error()}
+	}
+	return cliConfig, nil
+}
+
+// SaveCLIConfig : Writes the CLI config file to a directory
+func SaveCLIConfig(dir string, cliConfig CLIConfig) *ConfigError {
+	contents, err := yaml.Marshal(cliConfig)
+	if err != nil {
+		return &ConfigError{errOpCLIConfigWrite, err, err.Error()}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return &ConfigError{errOpCLIConfigWrite, err, err.Error()}
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, CLIConfigFile), contents, 0644); err != nil {
+		return &ConfigError{errOpCLIConfigWrite, err, err.Error()}
+	}
+	return nil
+}
+
+// Get : Returns the value of a setting, which is empty when it is not set
+func (cliConfig CLIConfig) Get(key string) (string, *ConfigError) {
+	switch key {
+	case KeyConnection:
+		return cliConfig.Connection, nil
+	case KeyJSON:
+		if !cliConfig.JSON {
+			return "", nil
+		}
+		return strconv.FormatBool(cliConfig.JSON), nil
+	case KeyKubeconfig:
+		return cliConfig.Kubeconfig, nil
+	case KeyLogLevel:
+		return cliConfig.LogLevel, nil
+	case KeyRegistryMirror:
+		return cliConfig.RegistryMirror, nil
+	case KeySyncConcurrency:
+		if cliConfig.SyncConcurrency == 0 {
+			return "", nil
+		}
+		return strconv.Itoa(cliConfig.SyncConcurrency), nil
+	}
+	return "", unknownKeyError(key)
+}
+
+// Set : Validates and sets the value of a setting, an empty value unsetting it
+func (cliConfig *CLIConfig) Set(key string, value string) *ConfigError {
+	switch key {
+	case KeyConnection:
+		cliConfig.Connection = value
+	case KeyJSON:
+		if value == "" {
+			cliConfig.JSON = false
+			return nil
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return invalidValueError(key, value, "true or false")
+		}
+		cliConfig.JSON = enabled
+	case KeyKubeconfig:
+		cliConfig.Kubeconfig = value
+	case KeyLogLevel:
+		if value != "" {
+			if _, err := logr.ParseLevel(value); err != nil {
+				return invalidValueError(key, value, "trace, debug, info, warn, error or fatal")
+			}
+		}
+		cliConfig.LogLevel = value
+	case KeyRegistryMirror:
+		cliConfig.RegistryMirror = value
+	case KeySyncConcurrency:
+		if value == "" {
+			cliConfig.SyncConcurrency = 0
+			return nil
+		}
+		concurrency, err := strconv.Atoi(value)
+		if err != nil || concurrency < 1 {
+			return invalidValueError(key, value, "a number greater than 0")
+		}
+		cliConfig.SyncConcurrency = concurrency
+	default:
+		return unknownKeyError(key)
+	}
+	return nil
+}
+
+func unknownKeyError(key string) *ConfigError {
+	desc := "Unknown setting " + key + ", must be one of " + strings.Join(CLIConfigKeys, ", ")
+	return &ConfigError{errOpCLIConfigKey, errors.New(desc), desc}
+}
+
+func invalidValueError(key string, value string, want string) *ConfigError {
+	desc := "Invalid value " + value + " for " + key + ", must be " + want
+	return &ConfigError{errOpCLIConfigValue, errors.New(desc), desc}
+}
@@ -0,0 +1,73 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCLIConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cliconfig")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("has no settings without a file", func(t *testing.T) {
+		cliConfig, configErr := LoadCLIConfig(dir)
+		assert.Nil(t, configErr)
+		assert.Equal(t, CLIConfig{}, cliConfig)
+	})
+
+	t.Run("saves and loads the settings", func(t *testing.T) {
+		cliConfig := CLIConfig{}
+		assert.Nil(t, cliConfig.Set(KeyConnection, "K8S1"))
+		assert.Nil(t, cliConfig.Set(KeyJSON, "true"))
+		assert.Nil(t, cliConfig.Set(KeySyncConcurrency, "8"))
+		assert.Nil(t, SaveCLIConfig(dir, cliConfig))
+
+		loaded, configErr := LoadCLIConfig(dir)
+		assert.Nil(t, configErr)
+		assert.Equal(t, CLIConfig{Connection: "K8S1", JSON: true, SyncConcurrency: 8}, loaded)
+		value, _ := loaded.Get(KeySyncConcurrency)
+		assert.Equal(t, "8", value)
+		value, _ = loaded.Get(KeyLogLevel)
+		assert.Equal(t, "", value)
+	})
+
+	t.Run("unsets a setting with an empty value", func(t *testing.T) {
+		cliConfig := CLIConfig{JSON: true, SyncConcurrency: 8}
+		assert.Nil(t, cliConfig.Set(KeyJSON, ""))
+		assert.Nil(t, cliConfig.Set(KeySyncConcurrency, ""))
+		assert.Equal(t, CLIConfig{}, cliConfig)
+	})
+
+	t.Run("rejects invalid values and unknown settings", func(t *testing.T) {
+		cliConfig := CLIConfig{}
+		assert.Equal(t, errOpCLIConfigValue, cliConfig.Set(KeyJSON, "yes please").Op)
+		assert.Equal(t, errOpCLIConfigValue, cliConfig.Set(KeyLogLevel, "loud").Op)
+		assert.Equal(t, errOpCLIConfigValue, cliConfig.Set(KeySyncConcurrency, "0").Op)
+		assert.Equal(t, errOpCLIConfigKey, cliConfig.Set("colour", "blue").Op)
+		_, configErr := cliConfig.Get("colour")
+		assert.Equal(t, errOpCLIConfigKey, configErr.Op)
+	})
+
+	t.Run("rejects a file with unknown settings", func(t *testing.T) {
+		err := ioutil.WriteFile(filepath.Join(dir, CLIConfigFile), []byte("loglevel: debug\nconnexion: K8S1\n"), 0644)
+		assert.Nil(t, err)
+		_, configErr := LoadCLIConfig(dir)
+		assert.Equal(t, errOpCLIConfigRead, configErr.Op)
+	})
+}
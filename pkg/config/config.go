@@ -13,11 +13,11 @@ package config
 
 import (
 	"encoding/json"
-	"errors"
 	"os"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 )
 
 // ConfigError : config package errors
@@ -31,6 +31,9 @@ const errOpConfConNotFound = "config_connection_notfound"
 const errOpConfPFEHostnamePortNotFound = "config_pfe_hostname_port_notfound"
 const textHostnameOrPortNotFound = "Hostname or port for Codewind containers not found. Make sure they are running."
 
+// ID of the message in the i18n catalogs
+const msgHostnameOrPortNotFound = "config.hostname_or_port_not_found"
+
 // ConfigError : Error formatted in JSON containing an errorOp and a description from
 // either a fault condition in the CLI, or an error payload from a REST request
 func (ce *ConfigError) Error() string {
@@ -66,12 +69,12 @@ func getLocalHostnameAndPort() (string, *ConfigError) {
 		return "https://localhost:9090", nil
 	}
 
-	hostname, port, err := docker.GetPFEHostAndPort(dockerClient)
+	pfeURL, err := docker.GetPFEURL(dockerClient)
 	if err != nil {
 		return "", &ConfigError{errOpConfPFEHostnamePortNotFound, err, err.Desc}
-	} else if hostname == "" || port == "" {
-		pfeHostPortErr := errors.New(textHostnameOrPortNotFound)
+	} else if pfeURL == "" {
+		pfeHostPortErr := i18n.Errorf(msgHostnameOrPortNotFound, textHostnameOrPortNotFound)
 		return "", &ConfigError{errOpConfPFEHostnamePortNotFound, pfeHostPortErr, textHostnameOrPortNotFound}
 	}
-	return "http://" + hostname + ":" + port, nil
+	return pfeURL, nil
 }
@@ -0,0 +1,75 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"sort"
+	"strings"
+	"sync"
+)
+
+// AllConnections : The connection ID which runs a command against every connection
+const AllConnections = "all"
+
+// BatchResult : The result of an operation run against every connection, keyed by connection ID.
+// Errors are kept per connection so that one unreachable connection does not hide the results of the others.
+type BatchResult struct {
+	Connections map[string]interface{} `json:"connections"`
+	Errors      map[string]string      `json:"errors"`
+}
+
+// IsAllConnections : Reports whether a connection ID asks for every connection
+func IsAllConnections(conID string) bool {
+	return strings.EqualFold(strings.TrimSpace(conID), AllConnections)
+}
+
+// ForEachConnection : Runs the operation against every connection at the same time, collecting the results.
+// The operation must return a nil error interface, not a nil typed error, when it succeeds.
+func ForEachConnection(operation func(connection *Connection) (interface{}, error)) (*BatchResult, *ConError) {
+	allConnections, conErr := GetAllConnections()
+	if conErr != nil {
+		return nil, conErr
+	}
+
+	result := BatchResult{Connections: map[string]interface{}{}, Errors: map[string]string{}}
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := range allConnections {
+		wg.Add(1)
+		go func(connection *Connection) {
+			defer wg.Done()
+			value, err := operation(connection)
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				result.Errors[connection.ID] = err.Error()
+				return
+			}
+			result.Connections[connection.ID] = value
+		}(&allConnections[i])
+	}
+	wg.Wait()
+	return &result, nil
+}
+
+// SortedIDs : The IDs of every connection in the result, successful or not, in order
+func (result *BatchResult) SortedIDs() []string {
+	ids := []string{}
+	for id := range result.Connections {
+		ids = append(ids, id)
+	}
+	for id := range result.Errors {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/gatekeeper"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
 )
@@ -35,6 +36,14 @@ type ConnectionConfig struct {
 	Connections   []Connection `json:"connections"`
 }
 
+// Identity providers a connection can authenticate with
+const (
+	// ProviderKeycloak is the Keycloak deployed with Codewind, used when no provider is set
+	ProviderKeycloak = "keycloak"
+	// ProviderOIDC is any other OpenID Connect provider, whose issuer URL is the AuthURL of the connection
+	ProviderOIDC = "oidc"
+)
+
 // Connection entry
 type Connection struct {
 	ID    string `json:"id"`
@@ -45,8 +54,34 @@ type Connection struct {
 	Realm    string `json:"realm"`
 	ClientID string `json:"clientid"`
 	Username string `json:"username"`
+	// Provider is the kind of identity provider at AuthURL, ProviderKeycloak when empty
+	Provider string `json:"provider,omitempty"`
+	// ProxyURL is used instead of HTTP_PROXY and HTTPS_PROXY for requests to this connection
+	ProxyURL string `json:"proxy,omitempty"`
+	// ProxyAuth is set when the proxy user and password are kept in the keyring, under ProxyCredentialsSecret
+	ProxyAuth bool `json:"proxyauth,omitempty"`
+	// NoProxy lists hosts reached directly, in addition to those in NO_PROXY
+	NoProxy string `json:"noproxy,omitempty"`
+	// CACert is a PEM bundle of certificate authorities trusted, in addition to the system ones, for this connection
+	CACert string `json:"cacert,omitempty"`
+	// InsecureSkipVerify disables verification of the certificates presented by this connection
+	InsecureSkipVerify bool `json:"insecure,omitempty"`
+	// ClientCert and ClientKey are the PEM certificate and key presented to a Gatekeeper requiring mutual TLS
+	ClientCert string `json:"clientcert,omitempty"`
+	ClientKey  string `json:"clientkey,omitempty"`
+	// Retries is how many times a request failing with a transient error is sent again, the default when nil
+	Retries *int `json:"retries,omitempty"`
+	// RetryBackoff is the delay before the first retry, doubled for each later one, such as "500ms"
+	RetryBackoff string `json:"retrybackoff,omitempty"`
+	// ConnectTimeout is how long to wait for a network connection to the Gatekeeper, such as "10s"
+	ConnectTimeout string `json:"connecttimeout,omitempty"`
+	// ReadTimeout is how long a request waits without reading or writing any data before failing, such as "1m"
+	ReadTimeout string `json:"readtimeout,omitempty"`
 }
 
+// ProxyCredentialsSecret : The keyring secret of a connection holding the user and password of its proxy
+const ProxyCredentialsSecret = "proxy_credentials"
+
 const actionUpdateEntry = 0x01
 const actionAddEntry = 0x02
 
@@ -82,7 +117,7 @@ func ResetConnectionsFile() *ConError {
 		return &ConError{errOpFileParse, err, err.Error()}
 	}
 
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+	err = writeConnectionsConfigFile(body)
 	if err != nil {
 		return &ConError{errOpFileWrite, err, err.Error()}
 	}
@@ -100,7 +135,7 @@ func GetConnectionByID(conID string) (*Connection, *ConError) {
 			return &connection, nil
 		}
 	}
-	err := errors.New("Connection " + strings.ToUpper(conID) + " not found")
+	err := i18n.Errorf(msgConNotFound, textConNotFound, strings.ToUpper(conID))
 	return nil, &ConError{errOpNotFound, err, err.Error()}
 }
 
@@ -113,35 +148,272 @@ func GetConnectionsConfig() (*ConnectionConfig, *ConError) {
 	return data, nil
 }
 
-// AddConnectionToList : validates then adds a new connection to the connection config
+// ConnectionOptions : The settings of a connection being added
+type ConnectionOptions struct {
+	Label              string
+	URL                string
+	Username           string
+	ProxyURL           string
+	NoProxy            string
+	CACert             string
+	InsecureSkipVerify bool
+	ClientCert         string
+	ClientKey          string
+	// Retries is the default when nil
+	Retries        *int
+	RetryBackoff   string
+	ConnectTimeout string
+	ReadTimeout    string
+	// OIDCIssuer and OIDCClientID set another OpenID Connect provider instead of the Keycloak of the Gatekeeper
+	OIDCIssuer   string
+	OIDCClientID string
+}
+
+// ConnectionUpdate : The settings of an existing connection to change. Empty strings keep the current label, URL,
+// username and realm, and nil keeps the current value of the other settings.
+type ConnectionUpdate struct {
+	Label              string
+	URL                string
+	Username           string
+	Realm              string
+	ProxyURL           *string
+	NoProxy            *string
+	CACert             *string
+	InsecureSkipVerify *bool
+	ClientCert         *string
+	ClientKey          *string
+	Retries            *int
+	RetryBackoff       *string
+	ConnectTimeout     *string
+	ReadTimeout        *string
+	OIDCIssuer         *string
+	OIDCClientID       *string
+}
+
+// AddConnectionToList : Adds a connection with the settings given by the flags, see AddConnection
 func AddConnectionToList(httpClient utils.HTTPClient, c *cli.Context) (*Connection, *ConError) {
+	options := ConnectionOptions{
+		Label:              strings.TrimSpace(c.String("label")),
+		URL:                strings.TrimSpace(c.String("url")),
+		Username:           strings.TrimSpace(c.String("username")),
+		ProxyURL:           strings.TrimSpace(c.String("proxy")),
+		NoProxy:            strings.TrimSpace(c.String("noproxy")),
+		CACert:             strings.TrimSpace(c.String("cacert")),
+		InsecureSkipVerify: c.Bool("insecure-skip-tls-verify"),
+		ClientCert:         strings.TrimSpace(c.String("clientcert")),
+		ClientKey:          strings.TrimSpace(c.String("clientkey")),
+		RetryBackoff:       strings.TrimSpace(c.String("retry-backoff")),
+		ConnectTimeout:     strings.TrimSpace(c.String("connect-timeout")),
+		ReadTimeout:        strings.TrimSpace(c.String("read-timeout")),
+		OIDCIssuer:         strings.TrimSpace(c.String("oidc-issuer")),
+		OIDCClientID:       strings.TrimSpace(c.String("oidc-client")),
+	}
+	if c.IsSet("retries") {
+		retries := c.Int("retries")
+		options.Retries = &retries
+	}
+	return AddConnection(httpClient, options)
+}
+
+// AddConnection : validates then adds a new connection to the connection config
+func AddConnection(httpClient utils.HTTPClient, options ConnectionOptions) (*Connection, *ConError) {
 	conID := strings.ToUpper(strconv.FormatInt(utils.CreateTimestamp(), 36))
-	label := strings.TrimSpace(c.String("label"))
-	url := strings.TrimSpace(c.String("url"))
-	username := strings.TrimSpace(c.String("username"))
-	conInfo, conErr := updateConnectionList(actionAddEntry, httpClient, conID, label, url, username)
+	transport := connectionTransport{
+		ProxyURL:           options.ProxyURL,
+		NoProxy:            options.NoProxy,
+		CACert:             options.CACert,
+		InsecureSkipVerify: options.InsecureSkipVerify,
+		ClientCert:         options.ClientCert,
+		ClientKey:          options.ClientKey,
+		Retries:            options.Retries,
+		RetryBackoff:       options.RetryBackoff,
+		ConnectTimeout:     options.ConnectTimeout,
+		ReadTimeout:        options.ReadTimeout,
+	}
+	identity := connectionIdentity{
+		Issuer:   options.OIDCIssuer,
+		ClientID: options.OIDCClientID,
+	}
+	conInfo, conErr := updateConnectionList(actionAddEntry, httpClient, conID, options.Label, options.URL, options.Username, "", transport, identity)
 	return conInfo, conErr
 }
 
-// UpdateExistingConnection : Update an existing connection
+// UpdateExistingConnection : Updates the connection given by the --conid flag with the settings of the flags that
+// are set, see UpdateConnection
 func UpdateExistingConnection(httpClient utils.HTTPClient, c *cli.Context) (*Connection, *ConError) {
-	conID := strings.ToUpper(c.String("conid"))
-	label := strings.TrimSpace(c.String("label"))
-	url := strings.TrimSpace(c.String("url"))
-	username := strings.TrimSpace(c.String("username"))
-	conInfo, conErr := updateConnectionList(actionUpdateEntry, httpClient, conID, label, url, username)
+	update := ConnectionUpdate{
+		Label:          strings.TrimSpace(c.String("label")),
+		URL:            strings.TrimSpace(c.String("url")),
+		Username:       strings.TrimSpace(c.String("username")),
+		Realm:          strings.TrimSpace(c.String("realm")),
+		ProxyURL:       stringFlagIfSet(c, "proxy"),
+		NoProxy:        stringFlagIfSet(c, "noproxy"),
+		CACert:         stringFlagIfSet(c, "cacert"),
+		ClientCert:     stringFlagIfSet(c, "clientcert"),
+		ClientKey:      stringFlagIfSet(c, "clientkey"),
+		RetryBackoff:   stringFlagIfSet(c, "retry-backoff"),
+		ConnectTimeout: stringFlagIfSet(c, "connect-timeout"),
+		ReadTimeout:    stringFlagIfSet(c, "read-timeout"),
+		OIDCIssuer:     stringFlagIfSet(c, "oidc-issuer"),
+		OIDCClientID:   stringFlagIfSet(c, "oidc-client"),
+	}
+	if c.IsSet("insecure-skip-tls-verify") {
+		insecure := c.Bool("insecure-skip-tls-verify")
+		update.InsecureSkipVerify = &insecure
+	}
+	if c.IsSet("retries") {
+		retries := c.Int("retries")
+		update.Retries = &retries
+	}
+	return UpdateConnection(httpClient, c.String("conid"), update)
+}
+
+// stringFlagIfSet returns the trimmed value of a flag, or nil when it is not set
+func stringFlagIfSet(c *cli.Context, name string) *string {
+	if !c.IsSet(name) {
+		return nil
+	}
+	value := strings.TrimSpace(c.String(name))
+	return &value
+}
+
+// UpdateConnection : Update an existing connection in place, keeping its ID so projects stay bound to it.
+// Settings which are not given keep their current values.
+func UpdateConnection(httpClient utils.HTTPClient, conID string, update ConnectionUpdate) (*Connection, *ConError) {
+	existing, conErr := GetConnectionByID(strings.ToUpper(conID))
+	if conErr != nil {
+		return nil, conErr
+	}
+	label := update.Label
+	if label == "" {
+		label = existing.Label
+	}
+	url := strings.TrimSuffix(update.URL, "/")
+	if url == "" {
+		url = existing.URL
+	}
+	username := update.Username
+	if username == "" {
+		username = existing.Username
+	}
+	// Keep a realm set on an earlier update unless the connection moves to another Gatekeeper
+	realm := update.Realm
+	if realm == "" && url == existing.URL {
+		realm = existing.Realm
+	}
+	// An empty proxy, CA or client certificate setting removes it, so only change it when given
+	transport := connectionTransport{
+		ProxyURL:           existing.ProxyURL,
+		ProxyAuth:          existing.ProxyAuth,
+		NoProxy:            existing.NoProxy,
+		CACert:             existing.CACert,
+		InsecureSkipVerify: existing.InsecureSkipVerify,
+		ClientCert:         existing.ClientCert,
+		ClientKey:          existing.ClientKey,
+		Retries:            existing.Retries,
+		RetryBackoff:       existing.RetryBackoff,
+		ConnectTimeout:     existing.ConnectTimeout,
+		ReadTimeout:        existing.ReadTimeout,
+	}
+	if update.ProxyURL != nil {
+		transport.ProxyURL, transport.ProxyAuth = *update.ProxyURL, false
+	}
+	if update.NoProxy != nil {
+		transport.NoProxy = *update.NoProxy
+	}
+	if update.CACert != nil {
+		transport.CACert = *update.CACert
+	}
+	if update.InsecureSkipVerify != nil {
+		transport.InsecureSkipVerify = *update.InsecureSkipVerify
+	}
+	if update.ClientCert != nil {
+		transport.ClientCert = *update.ClientCert
+	}
+	if update.ClientKey != nil {
+		transport.ClientKey = *update.ClientKey
+	}
+	if update.Retries != nil {
+		transport.Retries = update.Retries
+	}
+	if update.RetryBackoff != nil {
+		transport.RetryBackoff = *update.RetryBackoff
+	}
+	if update.ConnectTimeout != nil {
+		transport.ConnectTimeout = *update.ConnectTimeout
+	}
+	if update.ReadTimeout != nil {
+		transport.ReadTimeout = *update.ReadTimeout
+	}
+	// Keep another OpenID Connect provider unless it is changed, or removed with an empty issuer
+	identity := connectionIdentity{}
+	if existing.Provider == ProviderOIDC {
+		identity = connectionIdentity{Issuer: existing.AuthURL, ClientID: existing.ClientID}
+	}
+	if update.OIDCIssuer != nil {
+		identity.Issuer = *update.OIDCIssuer
+	}
+	if update.OIDCClientID != nil {
+		identity.ClientID = *update.OIDCClientID
+	}
+	conInfo, conErr := updateConnectionList(actionUpdateEntry, httpClient, existing.ID, label, url, username, realm, transport, identity)
 	return conInfo, conErr
 }
 
+// connectionTransport : The proxy, TLS, retry and timeout settings of a connection being added or updated
+type connectionTransport struct {
+	ProxyURL string
+	// ProxyAuth keeps the proxy credentials already in the keyring, when the proxy URL does not give new ones
+	ProxyAuth          bool
+	NoProxy            string
+	CACert             string
+	InsecureSkipVerify bool
+	ClientCert         string
+	ClientKey          string
+	Retries            *int
+	RetryBackoff       string
+	ConnectTimeout     string
+	ReadTimeout        string
+}
+
+// connectionIdentity : The OpenID Connect provider of a connection being added or updated, when it does not use the
+// Keycloak reported by its Gatekeeper
+type connectionIdentity struct {
+	Issuer   string
+	ClientID string
+}
+
 // updateConnectionList : validates then adds a new connection to the connection config
-func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID string, label string, url string, username string) (*Connection, *ConError) {
+func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID string, label string, url string, username string, realm string, transport connectionTransport, identity connectionIdentity) (*Connection, *ConError) {
 	if strings.EqualFold(connectionID, "LOCAL") {
 		err := errors.New("Local is a required connection that must not be modified")
 		return nil, &ConError{errOpProtected, err, err.Error()}
 	}
+	if conErr := validateProxyURL(transport.ProxyURL); conErr != nil {
+		return nil, conErr
+	}
+	if conErr := validateIssuerURL(identity.Issuer); conErr != nil {
+		return nil, conErr
+	}
+	if conErr := validateRetryPolicy(transport.Retries, transport.RetryBackoff); conErr != nil {
+		return nil, conErr
+	}
+	if conErr := validateTimeouts(transport.ConnectTimeout, transport.ReadTimeout); conErr != nil {
+		return nil, conErr
+	}
+	caCert, conErr := resolveCACert(transport.CACert)
+	if conErr != nil {
+		return nil, conErr
+	}
+	clientCert, clientKey, conErr := resolveClientCert(transport.ClientCert, transport.ClientKey)
+	if conErr != nil {
+		return nil, conErr
+	}
 	if url != "" && len(strings.TrimSpace(url)) > 0 {
 		url = strings.TrimSuffix(url, "/")
 	}
+	// Only the proxy URL is saved, its credentials are stored in the keyring by the caller
+	proxyURL, proxyCredentials := SplitProxyURL(transport.ProxyURL)
 	data, conErr := loadConnectionsConfigFile()
 	if conErr != nil {
 		return nil, conErr
@@ -167,6 +439,15 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 			err := errors.New("Unable to update connection")
 			return nil, &ConError{errOpNotFound, err, err.Error()}
 		}
+		for i := 0; i < len(data.Connections); i++ {
+			if strings.EqualFold(connectionID, data.Connections[i].ID) {
+				continue
+			}
+			if strings.EqualFold(label, data.Connections[i].Label) || strings.EqualFold(url, data.Connections[i].URL) {
+				conErr := errors.New("Connection ID: " + data.Connections[i].ID + " already uses this label or URL")
+				return nil, &ConError{errOpConflict, conErr, conErr.Error()}
+			}
+		}
 	}
 
 	gatekeeperEnv, err := gatekeeper.GetGatekeeperEnvironment(httpClient, url)
@@ -176,13 +457,35 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 
 	// create the new connection
 	newConnection := Connection{
-		ID:       connectionID,
-		Label:    label,
-		URL:      url,
-		AuthURL:  gatekeeperEnv.AuthURL,
-		Realm:    gatekeeperEnv.Realm,
-		ClientID: gatekeeperEnv.ClientID,
-		Username: username,
+		ID:                 connectionID,
+		Label:              label,
+		URL:                url,
+		AuthURL:            gatekeeperEnv.AuthURL,
+		Realm:              gatekeeperEnv.Realm,
+		ClientID:           gatekeeperEnv.ClientID,
+		Username:           username,
+		ProxyURL:           proxyURL,
+		ProxyAuth:          proxyURL != "" && (proxyCredentials != "" || transport.ProxyAuth),
+		NoProxy:            transport.NoProxy,
+		CACert:             caCert,
+		InsecureSkipVerify: transport.InsecureSkipVerify,
+		ClientCert:         clientCert,
+		ClientKey:          clientKey,
+		Retries:            transport.Retries,
+		RetryBackoff:       transport.RetryBackoff,
+		ConnectTimeout:     transport.ConnectTimeout,
+		ReadTimeout:        transport.ReadTimeout,
+	}
+	if realm != "" {
+		newConnection.Realm = realm
+	}
+	if identity.Issuer != "" {
+		newConnection.Provider = ProviderOIDC
+		newConnection.AuthURL = strings.TrimSuffix(identity.Issuer, "/")
+		newConnection.Realm = ""
+		if identity.ClientID != "" {
+			newConnection.ClientID = identity.ClientID
+		}
 	}
 
 	switch action {
@@ -210,7 +513,7 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 		return nil, &ConError{errOpFileParse, err, err.Error()}
 	}
 
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+	err = writeConnectionsConfigFile(body)
 	if err != nil {
 		return nil, &ConError{errOpFileWrite, err, err.Error()}
 	}
@@ -218,9 +521,66 @@ func updateConnectionList(action int, httpClient utils.HTTPClient, connectionID
 	return &newConnection, nil
 }
 
-// RemoveConnectionFromList : Removes the stored entry
+// ImportConnection : Adds a previously exported connection, keeping its ID so that it can be shared between machines
+func ImportConnection(connection Connection) *ConError {
+	if strings.EqualFold(connection.ID, "LOCAL") {
+		err := errors.New("Local is a required connection that must not be imported")
+		return &ConError{errOpProtected, err, err.Error()}
+	}
+	if connection.ID == "" || connection.URL == "" {
+		err := errors.New("Imported connections must have an ID and URL")
+		return &ConError{errOpFileParse, err, err.Error()}
+	}
+	if conErr := validateProxyURL(connection.ProxyURL); conErr != nil {
+		return conErr
+	}
+
+	data, conErr := loadConnectionsConfigFile()
+	if conErr != nil {
+		return conErr
+	}
+
+	for i := 0; i < len(data.Connections); i++ {
+		existing := data.Connections[i]
+		if strings.EqualFold(connection.ID, existing.ID) || strings.EqualFold(connection.Label, existing.Label) || strings.EqualFold(connection.URL, existing.URL) {
+			err := errors.New("Connection ID: " + existing.ID + " already exists. Remove it before importing " + strings.ToUpper(connection.ID))
+			return &ConError{errOpConflict, err, err.Error()}
+		}
+	}
+
+	connection.ID = strings.ToUpper(connection.ID)
+	if proxyURL, credentials := SplitProxyURL(connection.ProxyURL); credentials != "" {
+		connection.ProxyURL, connection.ProxyAuth = proxyURL, true
+	}
+	data.Connections = append(data.Connections, connection)
+	return saveConnectionsConfigFile(data)
+}
+
+// SetLocalCACert : Sets the CA bundle trusted for the local connection, used when local Codewind serves HTTPS with a
+// generated certificate. An empty bundle trusts only the system certificate authorities again.
+func SetLocalCACert(caCert string) *ConError {
+	data, conErr := loadConnectionsConfigFile()
+	if conErr != nil {
+		return conErr
+	}
+	for i := 0; i < len(data.Connections); i++ {
+		if strings.EqualFold(data.Connections[i].ID, "LOCAL") {
+			data.Connections[i].CACert = caCert
+			return saveConnectionsConfigFile(data)
+		}
+	}
+	err := i18n.Errorf(msgConNotFound, textConNotFound, "LOCAL")
+	return &ConError{errOpNotFound, err, err.Error()}
+}
+
+// RemoveConnectionFromList : Removes the connection given by the --conid flag, see RemoveConnection
 func RemoveConnectionFromList(c *cli.Context) *ConError {
-	id := strings.ToUpper(c.String("conid"))
+	return RemoveConnection(c.String("conid"))
+}
+
+// RemoveConnection : Removes the stored entry
+func RemoveConnection(conID string) *ConError {
+	id := strings.ToUpper(conID)
 
 	if strings.EqualFold(id, "LOCAL") {
 		err := errors.New("Local is a required connection and must not be removed")
@@ -249,7 +609,7 @@ func RemoveConnectionFromList(c *cli.Context) *ConError {
 		return &ConError{errOpFileParse, err, err.Error()}
 	}
 
-	err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+	err = writeConnectionsConfigFile(body)
 	if err != nil {
 		return &ConError{errOpFileWrite, err, err.Error()}
 	}
@@ -270,18 +630,22 @@ func GetAllConnections() ([]Connection, *ConError) {
 }
 
 // loadConnectionsConfigFile : Load the connections configuration file from disk
-// and returns the contents of the file or an error
+// and returns the contents of the file or an error. The file is only read again when it has changed,
+// and callers get their own copy of the connections to change.
 func loadConnectionsConfigFile() (*ConnectionConfig, *ConError) {
-	file, err := ioutil.ReadFile(GetConnectionConfigFilename())
-	if err != nil {
-		return nil, &ConError{errOpFileLoad, err, err.Error()}
+	var parseErr error
+	value, err := store.load(GetConnectionConfigFilename(), func(file []byte) (interface{}, error) {
+		data := ConnectionConfig{}
+		parseErr = json.Unmarshal([]byte(file), &data)
+		return &data, parseErr
+	})
+	if parseErr != nil {
+		return nil, &ConError{errOpFileParse, parseErr, parseErr.Error()}
 	}
-	data := ConnectionConfig{}
-	err = json.Unmarshal([]byte(file), &data)
 	if err != nil {
-		return nil, &ConError{errOpFileParse, err, err.Error()}
+		return nil, &ConError{errOpFileLoad, err, err.Error()}
 	}
-	return &data, nil
+	return copyConnectionConfig(value.(*ConnectionConfig)), nil
 }
 
 // saveConnectionsConfigFile : Save the connections configuration file to disk
@@ -291,7 +655,7 @@ func saveConnectionsConfigFile(ConnectionConfig *ConnectionConfig) *ConError {
 	if err != nil {
 		return &ConError{errOpFileParse, err, err.Error()}
 	}
-	conErr := ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+	conErr := writeConnectionsConfigFile(body)
 	if conErr != nil {
 		return &ConError{errOpFileWrite, conErr, conErr.Error()}
 	}
@@ -386,7 +750,7 @@ func applySchemaUpdates() *ConError {
 			if err != nil {
 				return &ConError{errOpFileParse, err, err.Error()}
 			}
-			err = ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+			err = writeConnectionsConfigFile(body)
 			if err != nil {
 				return &ConError{errOpFileWrite, err, err.Error()}
 			}
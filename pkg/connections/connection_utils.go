@@ -12,7 +12,16 @@
 package connections
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // ConError : Connection package errors
@@ -31,10 +40,22 @@ const (
 	errOpNotFound     = "con_not_found"
 	errOpProtected    = "con_protected"
 	errOpGetEnv       = "con_environment"
+	errOpBadProxy     = "con_proxy"
+	errOpBadCACert    = "con_cacert"
+	errOpBadCert      = "con_clientcert"
+	errOpBadIssuer    = "con_issuer"
+	errOpBadRetry     = "con_retry"
+	errOpBadTimeout   = "con_timeout"
 )
 
 const (
 	errTargetNotFound = "Target connection not found"
+	textConNotFound   = "Connection %s not found"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgConNotFound = "connections.con_not_found"
 )
 
 // ConError : Error formatted in JSON containing an errorOp and a description from
@@ -49,8 +70,154 @@ func (se *ConError) Error() string {
 	return string(jsonError)
 }
 
+// validateProxyURL checks a proxy URL names a host and a scheme that the HTTP transport supports
+func validateProxyURL(proxyURL string) *ConError {
+	if proxyURL == "" {
+		return nil
+	}
+	parsed, err := url.Parse(proxyURL)
+	if err == nil && parsed.Host != "" && (parsed.Scheme == "http" || parsed.Scheme == "https" || parsed.Scheme == "socks5") {
+		return nil
+	}
+	proxyErr := errors.New("Proxy " + RedactProxyURL(proxyURL) + " should be a URL such as http://proxy.example.com:8080")
+	return &ConError{errOpBadProxy, proxyErr, proxyErr.Error()}
+}
+
+// validateIssuerURL checks an OpenID Connect issuer is an https URL, as required for its discovery document
+func validateIssuerURL(issuer string) *ConError {
+	if issuer == "" {
+		return nil
+	}
+	parsed, err := url.Parse(issuer)
+	if err == nil && parsed.Host != "" && parsed.Scheme == "https" {
+		return nil
+	}
+	issuerErr := errors.New("Issuer " + issuer + " should be an https URL such as https://example.okta.com/oauth2/default")
+	return &ConError{errOpBadIssuer, issuerErr, issuerErr.Error()}
+}
+
+// validateRetryPolicy checks the retry count is not negative and the backoff is a positive duration such as 500ms
+func validateRetryPolicy(retries *int, backoff string) *ConError {
+	if retries != nil && *retries < 0 {
+		retryErr := errors.New("Retries must be 0 or more, not " + strconv.Itoa(*retries))
+		return &ConError{errOpBadRetry, retryErr, retryErr.Error()}
+	}
+	if backoff == "" {
+		return nil
+	}
+	duration, err := time.ParseDuration(backoff)
+	if err != nil || duration <= 0 {
+		retryErr := errors.New("Retry backoff " + backoff + " should be a duration such as 500ms or 2s")
+		return &ConError{errOpBadRetry, retryErr, retryErr.Error()}
+	}
+	return nil
+}
+
+// validateTimeouts checks the connect and read timeouts are positive durations such as 10s or 1m, when set
+func validateTimeouts(timeouts ...string) *ConError {
+	for _, timeout := range timeouts {
+		if timeout == "" {
+			continue
+		}
+		duration, err := time.ParseDuration(timeout)
+		if err != nil || duration <= 0 {
+			timeoutErr := errors.New("Timeout " + timeout + " should be a duration such as 10s or 1m")
+			return &ConError{errOpBadTimeout, timeoutErr, timeoutErr.Error()}
+		}
+	}
+	return nil
+}
+
+// resolveCACert checks a CA bundle contains at least one PEM certificate, returning its absolute path so the
+// connection works from any directory
+func resolveCACert(caCert string) (string, *ConError) {
+	if caCert == "" {
+		return "", nil
+	}
+	caCertPath, err := filepath.Abs(caCert)
+	if err != nil {
+		return "", &ConError{errOpBadCACert, err, err.Error()}
+	}
+	contents, err := ioutil.ReadFile(caCertPath)
+	if err != nil {
+		return "", &ConError{errOpBadCACert, err, err.Error()}
+	}
+	if !x509.NewCertPool().AppendCertsFromPEM(contents) {
+		certErr := errors.New("CA bundle " + caCertPath + " does not contain any PEM encoded certificates")
+		return "", &ConError{errOpBadCACert, certErr, certErr.Error()}
+	}
+	return caCertPath, nil
+}
+
+// resolveClientCert checks a client certificate and its key are given together and match, returning their
+// absolute paths
+func resolveClientCert(clientCert string, clientKey string) (string, string, *ConError) {
+	if clientCert == "" && clientKey == "" {
+		return "", "", nil
+	}
+	if clientCert == "" || clientKey == "" {
+		certErr := errors.New("A client certificate and its key must be given together")
+		return "", "", &ConError{errOpBadCert, certErr, certErr.Error()}
+	}
+	clientCertPath, err := filepath.Abs(clientCert)
+	if err != nil {
+		return "", "", &ConError{errOpBadCert, err, err.Error()}
+	}
+	clientKeyPath, err := filepath.Abs(clientKey)
+	if err != nil {
+		return "", "", &ConError{errOpBadCert, err, err.Error()}
+	}
+	if _, err := tls.LoadX509KeyPair(clientCertPath, clientKeyPath); err != nil {
+		certErr := errors.New("Unable to load client certificate " + clientCertPath + ": " + err.Error())
+		return "", "", &ConError{errOpBadCert, certErr, certErr.Error()}
+	}
+	return clientCertPath, clientKeyPath, nil
+}
+
 // Result : status message
 type Result struct {
 	Status        string `json:"status"`
 	StatusMessage string `json:"status_message"`
 }
+
+// SplitProxyURL : Returns a proxy URL without its user and password, and the user and password as they appear in the
+// URL, empty when it has none. The credentials are kept in the keyring, under ProxyCredentialsSecret, rather than in
+// the connections file.
+func SplitProxyURL(proxyURL string) (string, string) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.User == nil {
+		return proxyURL, ""
+	}
+	credentials := parsed.User.String()
+	parsed.User = nil
+	return parsed.String(), credentials
+}
+
+// ProxyURLWithCredentials : Returns a proxy URL with the user and password split from it by SplitProxyURL
+func ProxyURLWithCredentials(proxyURL string, credentials string) string {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || credentials == "" {
+		return proxyURL
+	}
+	withUser, err := url.Parse("//" + credentials + "@" + parsed.Host)
+	if err != nil {
+		return proxyURL
+	}
+	parsed.User = withUser.User
+	return parsed.String()
+}
+
+// RedactProxyURL : Returns a proxy URL with any user and password replaced, for proxies saved with their credentials
+// in the URL by earlier versions
+func RedactProxyURL(proxyURL string) string {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil || parsed.User == nil {
+		// Without a scheme, any credentials are not parsed as such
+		if at := strings.LastIndex(proxyURL, "@"); at >= 0 {
+			return "REDACTED" + proxyURL[at:]
+		}
+		return proxyURL
+	}
+	parsed.User = url.User("REDACTED")
+	return parsed.String()
+}
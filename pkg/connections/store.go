@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// cachedFile is the value parsed from a file, with the modification time and size the file had when it was read
+type cachedFile struct {
+	modTime time.Time
+	size    int64
+	value   interface{}
+}
+
+// fileCache keeps the values parsed from files for the life of the process, so that commands and watch mode working
+// through many projects do not read and parse the same files again. A file is read again when its modification time or
+// size changes, which picks up changes made by other processes such as the IDEs.
+type fileCache struct {
+	mutex sync.Mutex
+	files map[string]cachedFile
+}
+
+// store caches the connections file and the project connection files
+var store = fileCache{files: map[string]cachedFile{}}
+
+// load returns the value parsed from a file, reading and parsing the file only if it changed since it was last read
+func (cache *fileCache) load(filename string, parse func([]byte) (interface{}, error)) (interface{}, error) {
+	info, err := os.Stat(filename)
+	if err != nil {
+		cache.invalidate(filename)
+		return nil, err
+	}
+	cache.mutex.Lock()
+	cached, ok := cache.files[filename]
+	cache.mutex.Unlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.value, nil
+	}
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	value, err := parse(contents)
+	if err != nil {
+		cache.invalidate(filename)
+		return nil, err
+	}
+	cache.mutex.Lock()
+	cache.files[filename] = cachedFile{info.ModTime(), info.Size(), value}
+	cache.mutex.Unlock()
+	return value, nil
+}
+
+// invalidate forgets the value parsed from a file, so that it is read again. Files written by this process are
+// invalidated, as a write that keeps the size of a file may also keep its modification time on coarse file systems.
+func (cache *fileCache) invalidate(filename string) {
+	cache.mutex.Lock()
+	delete(cache.files, filename)
+	cache.mutex.Unlock()
+}
+
+// writeConnectionsConfigFile : Write the connections file and forget the cached connections
+func writeConnectionsConfigFile(body []byte) error {
+	defer store.invalidate(GetConnectionConfigFilename())
+	return ioutil.WriteFile(GetConnectionConfigFilename(), body, 0644)
+}
+
+// copyConnectionConfig returns a copy of cached connections that callers can change without changing the cache
+func copyConnectionConfig(cached *ConnectionConfig) *ConnectionConfig {
+	data := *cached
+	data.Connections = append([]Connection(nil), cached.Connections...)
+	return &data
+}
+
+// GetProjectConnectionsDir : Get the path to the directory of project connection files, which record the connection
+// each project on this machine was bound with
+func GetProjectConnectionsDir() string {
+	return path.Join(GetConnectionConfigDir(), "connections")
+}
+
+// GetProjectConnectionID : Returns the connection ID recorded for a project when it was bound, and whether there is one
+func GetProjectConnectionID(projectID string) (string, bool) {
+	conID, err := loadProjectConnectionFile(filepath.Join(GetProjectConnectionsDir(), projectID+".json"))
+	if err != nil || conID == "" {
+		return "", false
+	}
+	return conID, true
+}
+
+// GetProjectConnections : Returns the connection IDs recorded for the projects bound on this machine, keyed by
+// project ID. Files that cannot be read or parsed are left out.
+func GetProjectConnections() map[string]string {
+	projectConnections := map[string]string{}
+	files, err := ioutil.ReadDir(GetProjectConnectionsDir())
+	if err != nil {
+		return projectConnections
+	}
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+		conID, err := loadProjectConnectionFile(filepath.Join(GetProjectConnectionsDir(), file.Name()))
+		if err != nil || conID == "" {
+			continue
+		}
+		projectConnections[strings.TrimSuffix(file.Name(), ".json")] = conID
+	}
+	return projectConnections
+}
+
+// InvalidateProjectConnection : Forget the cached connection of a project, after its project connection file is
+// written, renamed or removed
+func InvalidateProjectConnection(projectID string) {
+	store.invalidate(filepath.Join(GetProjectConnectionsDir(), projectID+".json"))
+}
+
+// loadProjectConnectionFile returns the connection ID in a project connection file
+func loadProjectConnectionFile(filename string) (string, error) {
+	value, err := store.load(filename, func(contents []byte) (interface{}, error) {
+		var projectConnection struct {
+			ID string `json:"id"`
+		}
+		err := json.Unmarshal(contents, &projectConnection)
+		return projectConnection.ID, err
+	})
+	if err != nil {
+		return "", err
+	}
+	return value.(string), nil
+}
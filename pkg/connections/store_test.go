@@ -0,0 +1,131 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package connections
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setupStoreHome(t *testing.T) func() {
+	home, err := ioutil.TempDir("", "connections-store")
+	if err != nil {
+		t.Fatal(err)
+	}
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	os.MkdirAll(GetProjectConnectionsDir(), 0755)
+	return func() {
+		os.Setenv("HOME", originalHome)
+		os.RemoveAll(home)
+	}
+}
+
+func Test_FileCache(t *testing.T) {
+	defer setupStoreHome(t)()
+	filename := filepath.Join(GetConnectionConfigDir(), "cached.txt")
+	parses := 0
+	parse := func(contents []byte) (interface{}, error) {
+		parses++
+		return string(contents), nil
+	}
+	cache := fileCache{files: map[string]cachedFile{}}
+
+	t.Run("parses a file only once while it is unchanged", func(t *testing.T) {
+		ioutil.WriteFile(filename, []byte("one"), 0644)
+		value, err := cache.load(filename, parse)
+		assert.Nil(t, err)
+		assert.Equal(t, "one", value)
+		value, _ = cache.load(filename, parse)
+		assert.Equal(t, "one", value)
+		assert.Equal(t, 1, parses)
+	})
+
+	t.Run("parses a file again when another process changes it", func(t *testing.T) {
+		ioutil.WriteFile(filename, []byte("three"), 0644)
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(filename, later, later)
+		value, _ := cache.load(filename, parse)
+		assert.Equal(t, "three", value)
+		assert.Equal(t, 2, parses)
+	})
+
+	t.Run("parses a file again once it is invalidated", func(t *testing.T) {
+		cache.invalidate(filename)
+		cache.load(filename, parse)
+		assert.Equal(t, 3, parses)
+	})
+
+	t.Run("returns an error for a file that was removed", func(t *testing.T) {
+		os.Remove(filename)
+		_, err := cache.load(filename, parse)
+		assert.True(t, os.IsNotExist(err))
+		assert.Empty(t, cache.files)
+	})
+}
+
+func Test_CachedConnectionsConfig(t *testing.T) {
+	defer setupStoreHome(t)()
+	assert.Nil(t, InitConfigFileIfRequired())
+
+	t.Run("callers cannot change the cached connections", func(t *testing.T) {
+		data, conErr := GetConnectionsConfig()
+		assert.Nil(t, conErr)
+		data.Connections[0].ID = "CHANGED"
+		connection, conErr := GetConnectionByID("local")
+		assert.Nil(t, conErr)
+		assert.Equal(t, "local", connection.ID)
+	})
+
+	t.Run("saved connections are returned straight away", func(t *testing.T) {
+		assert.Nil(t, ImportConnection(Connection{ID: "remote1", Label: "remote", URL: "https://codewind.example.com"}))
+		connection, conErr := GetConnectionByID("REMOTE1")
+		assert.Nil(t, conErr)
+		assert.Equal(t, "https://codewind.example.com", connection.URL)
+	})
+}
+
+func Test_ProjectConnections(t *testing.T) {
+	defer setupStoreHome(t)()
+	dir := GetProjectConnectionsDir()
+	ioutil.WriteFile(filepath.Join(dir, "id-local.json"), []byte(`{"id":"local"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "id-remote.json"), []byte(`{"id":"remote","name":"remote-project"}`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "id-invalid.json"), []byte(`not json`), 0644)
+	ioutil.WriteFile(filepath.Join(dir, "notes.txt"), []byte(`{"id":"local"}`), 0644)
+
+	t.Run("returns the connection of every bound project", func(t *testing.T) {
+		assert.Equal(t, map[string]string{"id-local": "local", "id-remote": "remote"}, GetProjectConnections())
+	})
+
+	t.Run("returns the connection of a bound project", func(t *testing.T) {
+		conID, ok := GetProjectConnectionID("id-remote")
+		assert.True(t, ok)
+		assert.Equal(t, "remote", conID)
+		_, ok = GetProjectConnectionID("id-invalid")
+		assert.False(t, ok)
+		_, ok = GetProjectConnectionID("id-unknown")
+		assert.False(t, ok)
+	})
+
+	t.Run("forgets projects whose connection file is removed", func(t *testing.T) {
+		os.Remove(filepath.Join(dir, "id-local.json"))
+		InvalidateProjectConnection("id-local")
+		_, ok := GetProjectConnectionID("id-local")
+		assert.False(t, ok)
+		assert.Equal(t, map[string]string{"id-remote": "remote"}, GetProjectConnections())
+	})
+}
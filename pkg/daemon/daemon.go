@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	logr "github.com/sirupsen/logrus"
+)
+
+// ServiceName is the name the operations of the daemon are called by, as in Codewind.SyncProject
+const ServiceName = "Codewind"
+
+// DaemonError : Error formatted in JSON containing an errorOp and a description
+type DaemonError struct {
+	Op   string
+	Err  error
+	Desc string
+}
+
+const (
+	errOpRunning = "daemon_running"
+	errOpListen  = "daemon_listen"
+)
+
+// loadTestScheduleInterval is how often the daemon starts the scheduled load runs that are due
+var loadTestScheduleInterval = time.Minute
+
+const (
+	textDaemonRunning = "A daemon is already listening on the socket"
+	textNotSocket     = "The path exists and is not a socket"
+)
+
+// ID of the message in the i18n catalogs
+const msgDaemonRunning = "daemon.running"
+
+// Error : Error formatted in JSON containing an errorOp and a description
+func (de *DaemonError) Error() string {
+	type Output struct {
+		Operation   string `json:"error"`
+		Description string `json:"error_description"`
+	}
+	tempOutput := &Output{Operation: de.Op, Description: de.Err.Error()}
+	jsonError, _ := json.Marshal(tempOutput)
+	return string(jsonError)
+}
+
+// DefaultSocket : Returns the path of the socket the daemon listens on when no other is given
+func DefaultSocket() string {
+	return path.Join(path.Dir(connections.GetConnectionConfigDir()), "cwctl.sock")
+}
+
+// Dial : Connects to the daemon listening on a socket, returning a JSON-RPC client for its operations
+func Dial(socket string) (*rpc.Client, error) {
+	return jsonrpc.Dial("unix", socket)
+}
+
+// Serve : Listens on a Unix socket for JSON-RPC 1.0 requests until the context is cancelled. Each connection is
+// served at the same time, as are the requests made on a connection. The socket is only accessible to the current
+// user, and is removed when the daemon stops. A socket left behind by a daemon that did not stop cleanly is replaced,
+// but any other file at the path is left in place. While it listens, the daemon also starts the load runs scheduled
+// on the local connection.
+func Serve(ctx context.Context, socket string) *DaemonError {
+	if conn, err := net.DialTimeout("unix", socket, time.Second); err == nil {
+		conn.Close()
+		err := i18n.Errorf(msgDaemonRunning, textDaemonRunning)
+		return &DaemonError{errOpRunning, err, textDaemonRunning}
+	}
+	// Only a socket left behind is replaced, never a file the path was given for by mistake
+	if info, err := os.Lstat(socket); err == nil {
+		if info.Mode()&os.ModeSocket == 0 {
+			err := errors.New(textNotSocket + ": " + socket)
+			return &DaemonError{errOpListen, err, err.Error()}
+		}
+		os.Remove(socket)
+	}
+
+	listener, err := listenPrivate(socket)
+	if err != nil {
+		return &DaemonError{errOpListen, err, err.Error()}
+	}
+	if err := os.Chmod(socket, 0600); err != nil {
+		listener.Close()
+		return &DaemonError{errOpListen, err, err.Error()}
+	}
+
+	server := rpc.NewServer()
+	if err := server.RegisterName(ServiceName, &Service{ctx: ctx}); err != nil {
+		listener.Close()
+		return &DaemonError{errOpListen, err, err.Error()}
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-stopped:
+		}
+		listener.Close()
+	}()
+	defer close(stopped)
+	go runLoadTestSchedules(ctx)
+
+	logr.Infof("Listening on %v", socket)
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				break
+			}
+			return &DaemonError{errOpListen, err, err.Error()}
+		}
+		go server.ServeCodec(jsonrpc.NewServerCodec(conn))
+	}
+	// Closing the listener removes the socket, and the operations still running are cancelled with the context
+	logr.Infof("Stopped listening on %v", socket)
+	return nil
+}
+
+// runLoadTestSchedules starts the scheduled load runs that are due every interval until the context is cancelled.
+// A run in progress when the context is cancelled is left to finish on the load runner.
+func runLoadTestSchedules(ctx context.Context) {
+	ticker := time.NewTicker(loadTestScheduleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			for _, run := range project.RunDueLoadTestSchedules(sechttp.Client(), now) {
+				if run.Error != "" {
+					logr.Errorf("Scheduled load run %v failed: %v", run.Name, run.Error)
+					continue
+				}
+				logr.Infof("Scheduled load run %v %v, results archived in %v", run.Name, run.Results.Status, run.Results.Directory)
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package daemon
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	"github.com/stretchr/testify/assert"
+)
+
+// startDaemon serves on a socket in a new directory, returning the socket, a function stopping the daemon and the
+// channel its result is sent on
+func startDaemon(t *testing.T) (string, context.CancelFunc, chan *DaemonError) {
+	dir, err := ioutil.TempDir("", "cwctl-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	socket := filepath.Join(dir, "cwctl.sock")
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan *DaemonError, 1)
+	go func() {
+		result <- Serve(ctx, socket)
+		os.RemoveAll(dir)
+	}()
+	for i := 0; i < 100; i++ {
+		if _, err := os.Stat(socket); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return socket, cancel, result
+}
+
+func TestServe(t *testing.T) {
+	socket, stop, result := startDaemon(t)
+
+	t.Run("operations are served over JSON-RPC", func(t *testing.T) {
+		client, err := Dial(socket)
+		if !assert.Nil(t, err) {
+			return
+		}
+		defer client.Close()
+		var reply PingResult
+		err = client.Call(ServiceName+".Ping", Empty{}, &reply)
+		assert.Nil(t, err)
+		assert.Equal(t, appconstants.VersionNum, reply.Version)
+		assert.Equal(t, os.Getpid(), reply.PID)
+	})
+
+	t.Run("the socket is only accessible to the current user", func(t *testing.T) {
+		info, err := os.Stat(socket)
+		if assert.Nil(t, err) {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("a second daemon on the same socket fails", func(t *testing.T) {
+		daemonErr := Serve(context.Background(), socket)
+		if assert.NotNil(t, daemonErr) {
+			assert.Equal(t, errOpRunning, daemonErr.Op)
+		}
+	})
+
+	t.Run("cancelling the context stops the daemon and removes the socket", func(t *testing.T) {
+		stop()
+		select {
+		case daemonErr := <-result:
+			assert.Nil(t, daemonErr)
+		case <-time.After(5 * time.Second):
+			t.Fatal("the daemon did not stop")
+		}
+		_, err := os.Stat(socket)
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestServeReplacesStaleSocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cwctl-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "cwctl.sock")
+	// A listener that does not remove its socket when closed leaves it behind, as a daemon that was killed does
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+	listener.(*net.UnixListener).SetUnlinkOnClose(false)
+	listener.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	result := make(chan *DaemonError, 1)
+	go func() { result <- Serve(ctx, socket) }()
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	assert.Nil(t, <-result)
+}
+
+func TestServeKeepsFileThatIsNotASocket(t *testing.T) {
+	dir, err := ioutil.TempDir("", "cwctl-daemon")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "cwctl.sock")
+	if err := ioutil.WriteFile(socket, []byte("not a socket"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	daemonErr := Serve(context.Background(), socket)
+	assert.NotNil(t, daemonErr)
+	assert.Equal(t, errOpListen, daemonErr.Op)
+	contents, _ := ioutil.ReadFile(socket)
+	assert.Equal(t, "not a socket", string(contents))
+}
@@ -0,0 +1,19 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package daemon
+
+import "net"
+
+// listenPrivate listens on a Unix socket, which Windows creates with the permissions of the directory it is in
+func listenPrivate(socket string) (net.Listener, error) {
+	return net.Listen("unix", socket)
+}
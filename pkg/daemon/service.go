@@ -0,0 +1,171 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package daemon
+
+import (
+	"context"
+	"os"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/project"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/security"
+)
+
+type (
+	// Service : The operations served by the daemon. Each one runs the same function as its cwctl command, in a
+	// process that keeps its pooled HTTP connections and refreshed tokens between requests. Failures
+	// are returned as the JSON error document of the function, with its error and error_description.
+	Service struct {
+		ctx context.Context
+	}
+
+	// Empty : The arguments or result of an operation that has none
+	Empty struct{}
+
+	// PingResult : Describes the running daemon
+	PingResult struct {
+		Version string `json:"version"`
+		PID     int    `json:"pid"`
+	}
+
+	// ListProjectsArgs : The connection to list the projects of, or all, and the filters of project list
+	ListProjectsArgs struct {
+		ConID   string              `json:"conid"`
+		Options project.ListOptions `json:"options"`
+	}
+
+	// UpdateConnectionArgs : The connection to update and the settings to change
+	UpdateConnectionArgs struct {
+		ConID  string                       `json:"conid"`
+		Update connections.ConnectionUpdate `json:"update"`
+	}
+)
+
+// Ping : Reports the version and process of the daemon, so that clients can check it is running
+func (service *Service) Ping(args Empty, reply *PingResult) error {
+	*reply = PingResult{Version: appconstants.VersionNum, PID: os.Getpid()}
+	return nil
+}
+
+// SyncProject : Runs project sync, cancelled if the daemon stops
+func (service *Service) SyncProject(args project.SyncOptions, reply *project.SyncResponse) error {
+	response, projErr := project.SyncContext(service.ctx, args)
+	if projErr != nil {
+		return projErr
+	}
+	*reply = *response
+	return nil
+}
+
+// ValidateProject : Runs project validate
+func (service *Service) ValidateProject(args project.ValidateOptions, reply *project.ValidationResponse) error {
+	response, projErr := project.Validate(args)
+	if projErr != nil {
+		return projErr
+	}
+	*reply = *response
+	return nil
+}
+
+// RemoveProject : Runs project remove
+func (service *Service) RemoveProject(args project.RemoveOptions, reply *project.RemoveResult) error {
+	result, projErr := project.Remove(args)
+	if projErr != nil {
+		return projErr
+	}
+	*reply = *result
+	return nil
+}
+
+// ListProjects : Runs project list
+func (service *Service) ListProjects(args ListProjectsArgs, reply *connections.BatchResult) error {
+	result, projErr := project.ListProjects(args.ConID, args.Options)
+	if projErr != nil {
+		return projErr
+	}
+	*reply = *result
+	return nil
+}
+
+// ListConnections : Runs connections list
+func (service *Service) ListConnections(args Empty, reply *[]connections.Connection) error {
+	list, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		return conErr
+	}
+	for i := range list {
+		list[i].ProxyURL = connections.RedactProxyURL(list[i].ProxyURL)
+	}
+	*reply = list
+	return nil
+}
+
+// AddConnection : Runs connections add
+func (service *Service) AddConnection(args connections.ConnectionOptions, reply *connections.Connection) error {
+	connection, conErr := connections.AddConnection(sechttp.Client(), args)
+	if conErr != nil {
+		return conErr
+	}
+	if connection.ProxyAuth {
+		if secErr := security.SecProxyCredentialsUpdate(connection.ID, args.ProxyURL); secErr != nil {
+			return secErr
+		}
+	}
+	*reply = *connection
+	return nil
+}
+
+// UpdateConnection : Runs connections update
+func (service *Service) UpdateConnection(args UpdateConnectionArgs, reply *connections.Connection) error {
+	connection, conErr := connections.UpdateConnection(sechttp.Client(), args.ConID, args.Update)
+	if conErr != nil {
+		return conErr
+	}
+	if args.Update.ProxyURL != nil {
+		if secErr := security.SecProxyCredentialsUpdate(connection.ID, *args.Update.ProxyURL); secErr != nil {
+			return secErr
+		}
+	}
+	*reply = *connection
+	return nil
+}
+
+// RemoveConnection : Runs connections remove, given the connection ID
+func (service *Service) RemoveConnection(conID string, reply *Empty) error {
+	if conErr := connections.RemoveConnection(conID); conErr != nil {
+		return conErr
+	}
+	return nil
+}
+
+// InstallRemote : Runs remote install, cancelled if the daemon stops
+func (service *Service) InstallRemote(args remote.DeployOptions, reply *remote.DeploymentResult) error {
+	result, remInstErr := remote.DeployRemoteContext(service.ctx, &args)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	*reply = *result
+	return nil
+}
+
+// RemoveRemote : Runs remove remote
+func (service *Service) RemoveRemote(args remote.RemoveDeploymentOptions, reply *remote.RemovalResult) error {
+	result, remInstErr := remote.RemoveRemote(&args)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	*reply = *result
+	return nil
+}
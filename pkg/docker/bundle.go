@@ -0,0 +1,121 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// DefaultImageBundle : The archive Codewind images are bundled into and installed from
+const DefaultImageBundle = "codewind-images.tar.gz"
+
+// CodewindImages : Returns the images a local Codewind runs, at a tag
+func CodewindImages(tag string) []string {
+	return []string{
+		"docker.io/" + pfeImageName + "-amd64:" + tag,
+		"docker.io/" + performanceImageName + "-amd64:" + tag,
+	}
+}
+
+// SaveImageBundle : Writes images to a gzipped tar archive that LoadImageBundle installs them from. The images must
+// have been pulled. The archive is only put in place once every image is written to it.
+func SaveImageBundle(dockerClient DockerClient, images []string, archivePath string) *DockerError {
+	imagesOut, err := dockerClient.ImageSave(context.Background(), images)
+	if err != nil {
+		return &DockerError{errOpImageSave, err, err.Error()}
+	}
+	defer imagesOut.Close()
+
+	partialPath := archivePath + ".partial"
+	archive, err := os.Create(partialPath)
+	if err != nil {
+		return &DockerError{errOpImageSave, err, err.Error()}
+	}
+	gzipWriter := gzip.NewWriter(archive)
+	_, err = io.Copy(gzipWriter, imagesOut)
+	if err == nil {
+		err = gzipWriter.Close()
+	}
+	if closeErr := archive.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(partialPath, archivePath)
+	}
+	if err != nil {
+		os.Remove(partialPath)
+		return &DockerError{errOpImageSave, err, err.Error()}
+	}
+	return nil
+}
+
+// LoadImageBundle : Loads the images in an archive written by SaveImageBundle, or by docker save, through the Docker
+// API, returning the images loaded. Returns an error if any of the images required is not in the archive.
+func LoadImageBundle(dockerClient DockerClient, archivePath string, required []string) ([]string, *DockerError) {
+	archive, err := os.Open(archivePath)
+	if err != nil {
+		return nil, &DockerError{errOpImageLoad, err, err.Error()}
+	}
+	defer archive.Close()
+
+	response, err := dockerClient.ImageLoad(context.Background(), archive, true)
+	if err != nil {
+		return nil, &DockerError{errOpImageLoad, err, err.Error()}
+	}
+	defer response.Body.Close()
+
+	loaded := []string{}
+	decoder := json.NewDecoder(response.Body)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, &DockerError{errOpImageLoad, err, err.Error()}
+		}
+		if message.Error != nil {
+			err := errors.New(message.Error.Message)
+			return nil, &DockerError{errOpImageLoad, err, err.Error()}
+		}
+		if image := strings.TrimPrefix(strings.TrimSpace(message.Stream), "Loaded image: "); image != strings.TrimSpace(message.Stream) {
+			loaded = append(loaded, image)
+		}
+	}
+
+	for _, image := range required {
+		if !containsImage(loaded, image) {
+			err := i18n.Errorf(msgImageNotInBundle, textImageNotInBundle+": %s", image)
+			return nil, &DockerError{errOpImageNotFound, err, err.Error()}
+		}
+	}
+	return loaded, nil
+}
+
+// containsImage reports whether an image is in a list, ignoring the docker.io registry Docker leaves out of names
+func containsImage(images []string, image string) bool {
+	for _, candidate := range images {
+		if strings.TrimPrefix(candidate, "docker.io/") == strings.TrimPrefix(image, "docker.io/") {
+			return true
+		}
+	}
+	return false
+}
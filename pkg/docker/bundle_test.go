@@ -0,0 +1,82 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveImageBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	archivePath := filepath.Join(dir, DefaultImageBundle)
+
+	t.Run("writes the saved images to a gzipped archive", func(t *testing.T) {
+		dockerErr := SaveImageBundle(&MockDockerClientWithCw{}, CodewindImages("latest"), archivePath)
+		assert.Nil(t, dockerErr)
+
+		archive, err := os.Open(archivePath)
+		assert.Nil(t, err)
+		defer archive.Close()
+		gzipReader, err := gzip.NewReader(archive)
+		assert.Nil(t, err)
+		contents, err := ioutil.ReadAll(gzipReader)
+		assert.Nil(t, err)
+		assert.Equal(t, mockImageArchive, string(contents))
+		_, err = os.Stat(archivePath + ".partial")
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("returns DockerError when the images cannot be saved", func(t *testing.T) {
+		dockerErr := SaveImageBundle(&MockDockerErrorClient{}, CodewindImages("latest"), filepath.Join(dir, "failed.tar.gz"))
+		assert.Equal(t, errOpImageSave, dockerErr.Op)
+		_, err := os.Stat(filepath.Join(dir, "failed.tar.gz"))
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestLoadImageBundle(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bundle")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	archivePath := filepath.Join(dir, DefaultImageBundle)
+	assert.Nil(t, ioutil.WriteFile(archivePath, []byte(mockImageArchive), 0644))
+
+	t.Run("returns the images loaded when the archive has every image required", func(t *testing.T) {
+		loaded, dockerErr := LoadImageBundle(&MockDockerClientWithCw{}, archivePath, CodewindImages("latest"))
+		assert.Nil(t, dockerErr)
+		assert.Equal(t, []string{"eclipse/codewind-pfe-amd64:latest", "eclipse/codewind-performance-amd64:latest"}, loaded)
+	})
+
+	t.Run("returns DockerError when the archive does not have an image required", func(t *testing.T) {
+		_, dockerErr := LoadImageBundle(&MockDockerClientWithCw{}, archivePath, CodewindImages("0.9.0"))
+		assert.Equal(t, errOpImageNotFound, dockerErr.Op)
+		assert.Contains(t, dockerErr.Desc, "docker.io/eclipse/codewind-pfe-amd64:0.9.0")
+	})
+
+	t.Run("returns DockerError when the archive does not exist", func(t *testing.T) {
+		_, dockerErr := LoadImageBundle(&MockDockerClientWithCw{}, filepath.Join(dir, "missing.tar.gz"), CodewindImages("latest"))
+		assert.Equal(t, errOpImageLoad, dockerErr.Op)
+	})
+
+	t.Run("returns DockerError when Docker cannot load the images", func(t *testing.T) {
+		_, dockerErr := LoadImageBundle(&MockDockerErrorClient{}, archivePath, CodewindImages("latest"))
+		assert.Equal(t, errOpImageLoad, dockerErr.Op)
+	})
+}
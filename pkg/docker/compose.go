@@ -0,0 +1,86 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// composeProjectName is the project the Codewind containers, network and volume are created in
+const composeProjectName = "codewind"
+
+// ComposeCLI : The Docker Compose command found on the machine, either the `docker compose` plugin of Compose v2 or
+// the standalone docker-compose, which is v1 on older installs
+type ComposeCLI struct {
+	Command []string
+	Version int
+}
+
+// composeCommands are tried in order, preferring the plugin current Docker Desktop installs ship
+var composeCommands = [][]string{{"docker", "compose"}, {"docker-compose"}}
+
+// composeVersion returns the version a Compose command reports, replaced in tests
+var composeVersion = func(command []string) (string, error) {
+	args := append(append([]string{}, command[1:]...), "version", "--short")
+	output, err := exec.Command(command[0], args...).Output()
+	return string(output), err
+}
+
+// FindComposeCLI : Returns the Docker Compose command to run, or an error if neither the Compose plugin nor
+// docker-compose is installed
+func FindComposeCLI() (*ComposeCLI, *DockerError) {
+	for _, command := range composeCommands {
+		version, err := composeVersion(command)
+		if err != nil {
+			continue
+		}
+		return &ComposeCLI{Command: command, Version: composeMajorVersion(version)}, nil
+	}
+	err := i18n.Errorf(msgComposeNotFound, textComposeNotFound)
+	return nil, &DockerError{errOpDockerComposeNotFound, err, textComposeNotFound}
+}
+
+// composeMajorVersion : Returns the major version of a version such as 1.29.2 or v2.24.6, assuming v1 when the
+// version cannot be read as older releases did not all support --short
+func composeMajorVersion(version string) int {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	major, err := strconv.Atoi(strings.SplitN(version, ".", 2)[0])
+	if err != nil || major < 1 {
+		return 1
+	}
+	return major
+}
+
+// Cmd : Returns the command running Compose against the Codewind compose file. The project is named explicitly, as
+// Compose v2 names it from the directory of the compose file otherwise.
+func (compose *ComposeCLI) Cmd(composeFile string, args ...string) *exec.Cmd {
+	composeArgs := append(append([]string{}, compose.Command[1:]...), "-f", composeFile, "-p", composeProjectName)
+	return exec.Command(compose.Command[0], append(composeArgs, args...)...)
+}
+
+// composeOutputFailed : Reports whether the output of Compose shows a failure. Compose v1 prints ERROR: lines, while
+// v2 prints the errors of the Docker daemon as they are, such as "Error response from daemon".
+func composeOutputFailed(output string) bool {
+	if strings.Contains(output, "ERROR") || strings.Contains(output, "error") {
+		return true
+	}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "Error") {
+			return true
+		}
+	}
+	return false
+}
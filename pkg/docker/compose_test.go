@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFindComposeCLI(t *testing.T) {
+	originalComposeVersion := composeVersion
+	defer func() { composeVersion = originalComposeVersion }()
+	installed := func(versions map[string]string) func(command []string) (string, error) {
+		return func(command []string) (string, error) {
+			version, ok := versions[strings.Join(command, " ")]
+			if !ok {
+				return "", errors.New("not found")
+			}
+			return version, nil
+		}
+	}
+
+	t.Run("prefers the Compose v2 plugin", func(t *testing.T) {
+		composeVersion = installed(map[string]string{"docker compose": "v2.24.6\n", "docker-compose": "1.29.2\n"})
+		compose, err := FindComposeCLI()
+		assert.Nil(t, err)
+		assert.Equal(t, &ComposeCLI{Command: []string{"docker", "compose"}, Version: 2}, compose)
+	})
+
+	t.Run("falls back to the standalone docker-compose", func(t *testing.T) {
+		composeVersion = installed(map[string]string{"docker-compose": "1.25.4\n"})
+		compose, err := FindComposeCLI()
+		assert.Nil(t, err)
+		assert.Equal(t, &ComposeCLI{Command: []string{"docker-compose"}, Version: 1}, compose)
+	})
+
+	t.Run("returns DockerError when Compose is not installed", func(t *testing.T) {
+		composeVersion = installed(map[string]string{})
+		_, err := FindComposeCLI()
+		assert.Equal(t, errOpDockerComposeNotFound, err.Op)
+	})
+}
+
+func TestComposeMajorVersion(t *testing.T) {
+	assert.Equal(t, 1, composeMajorVersion("1.29.2"))
+	assert.Equal(t, 2, composeMajorVersion("v2.24.6"))
+	assert.Equal(t, 2, composeMajorVersion("2.3.3\n"))
+	assert.Equal(t, 1, composeMajorVersion("docker-compose version 1.21.0"))
+}
+
+func TestComposeCmd(t *testing.T) {
+	plugin := &ComposeCLI{Command: []string{"docker", "compose"}, Version: 2}
+	cmd := plugin.Cmd("/home/user/.codewind/docker-compose.yaml", "up", "-d")
+	assert.Equal(t, []string{"docker", "compose", "-f", "/home/user/.codewind/docker-compose.yaml", "-p", "codewind", "up", "-d"}, cmd.Args)
+
+	standalone := &ComposeCLI{Command: []string{"docker-compose"}, Version: 1}
+	cmd = standalone.Cmd("docker-compose.yaml", "down")
+	assert.Equal(t, []string{"docker-compose", "-f", "docker-compose.yaml", "-p", "codewind", "down"}, cmd.Args)
+}
+
+func TestComposeOutputFailed(t *testing.T) {
+	tests := map[string]struct {
+		output string
+		want   bool
+	}{
+		"v1 success":     {"Creating codewind-pfe ... done\n", false},
+		"v1 failure":     {"ERROR: for codewind-pfe  Cannot start service\n", true},
+		"v2 success":     {" Container codewind-performance  Started\n Container codewind-pfe  Started\n", false},
+		"v2 failure":     {" Container codewind-pfe  Starting\nError response from daemon: port is already allocated\n", true},
+		"v2 pull denied": {"Error: pull access denied for eclipse/codewind-pfe-amd64\n", true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, composeOutputFailed(test.output))
+		})
+	}
+}
@@ -25,9 +25,11 @@ import (
 	"os/exec"
 	"path"
 	"runtime"
+	"sort"
 	"strconv"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 
@@ -116,6 +118,7 @@ type Compose struct {
 		} `yaml:"codewind-pfe"`
 		PERFORMANCE struct {
 			Image         string   `yaml:"image"`
+			Environment   []string `yaml:"environment,omitempty"`
 			Ports         []string `yaml:"ports"`
 			ContainerName string   `yaml:"container_name"`
 			Volumes       []string `yaml:"volumes"`
@@ -153,8 +156,11 @@ type (
 	}
 )
 
-// constant to identify the internal port of PFE in its container
-const internalPFEPort = 9090
+// constants to identify the internal port of PFE in its container, serving HTTP or, when started with TLS, HTTPS
+const (
+	internalPFEPort      = 9090
+	internalPFEHTTPSPort = 9191
+)
 
 // constants to identify the range of external ports on which to expose PFE
 const (
@@ -164,10 +170,21 @@ const (
 	maxDebugPort = 35000
 )
 
-// DockerCompose to set up the Codewind environment
-func DockerCompose(dockerComposeFile string, tag string, loglevel string) *DockerError {
-	setupDockerComposeEnvs(tag, "", loglevel)
-	cmd := exec.Command("docker-compose", "-f", dockerComposeFile, "up", "-d", "--force-recreate")
+// DockerCompose to set up the Codewind environment, publishing PFE on a host port, or a port Docker assigns when it
+// is 0
+func DockerCompose(dockerComposeFile string, tag string, loglevel string, pfePort int) *DockerError {
+	compose, dockerErr := FindComposeCLI()
+	if dockerErr != nil {
+		os.Remove(dockerComposeFile)
+		ClearDockerConfigSecret(path.Dir(dockerComposeFile))
+		return dockerErr
+	}
+	externalPort := ""
+	if pfePort != 0 {
+		externalPort = strconv.Itoa(pfePort)
+	}
+	setupDockerComposeEnvs(tag, externalPort, loglevel)
+	cmd := compose.Cmd(dockerComposeFile, "up", "-d", "--force-recreate")
 	output := new(bytes.Buffer)
 	cmd.Stdout = output
 	cmd.Stderr = output
@@ -188,7 +205,7 @@ func DockerCompose(dockerComposeFile string, tag string, loglevel string) *Docke
 	}
 	fmt.Printf(output.String()) // Wait to finish execution, so we can read all output
 
-	if strings.Contains(output.String(), "ERROR") || strings.Contains(output.String(), "error") {
+	if composeOutputFailed(output.String()) {
 		os.Remove(dockerComposeFile)
 		ClearDockerConfigSecret(path.Dir(dockerComposeFile))
 		os.Exit(1)
@@ -204,12 +221,16 @@ func DockerCompose(dockerComposeFile string, tag string, loglevel string) *Docke
 
 // DockerComposeStop to stop Codewind containers
 func DockerComposeStop(tag, dockerComposeFile string) *DockerError {
-	setupDockerComposeEnvs(tag, "stop", "")
+	setupDockerComposeEnvs(tag, "", "")
 
 	// Delete the docker configuration file whether we have a clean shutdown or not.
 	ClearDockerConfigSecret(path.Dir(dockerComposeFile))
 
-	cmd := exec.Command("docker-compose", "-f", dockerComposeFile, "rm", "--stop", "-f")
+	compose, dockerErr := FindComposeCLI()
+	if dockerErr != nil {
+		return dockerErr
+	}
+	cmd := compose.Cmd(dockerComposeFile, "rm", "--stop", "-f")
 	output := new(bytes.Buffer)
 	cmd.Stdout = output
 	cmd.Stderr = output
@@ -226,7 +247,7 @@ func DockerComposeStop(tag, dockerComposeFile string) *DockerError {
 	}
 	fmt.Printf(output.String()) // Wait to finish execution, so we can read all output
 
-	if strings.Contains(output.String(), "ERROR") || strings.Contains(output.String(), "error") {
+	if composeOutputFailed(output.String()) {
 		os.Exit(1)
 	}
 	return nil
@@ -234,8 +255,12 @@ func DockerComposeStop(tag, dockerComposeFile string) *DockerError {
 
 // DockerComposeRemove to remove Codewind images
 func DockerComposeRemove(dockerComposeFile, tag string) *DockerError {
-	setupDockerComposeEnvs(tag, "remove", "")
-	cmd := exec.Command("docker-compose", "-f", dockerComposeFile, "down", "--rmi", "all")
+	compose, dockerErr := FindComposeCLI()
+	if dockerErr != nil {
+		return dockerErr
+	}
+	setupDockerComposeEnvs(tag, "", "")
+	cmd := compose.Cmd(dockerComposeFile, "down", "--rmi", "all")
 	output := new(bytes.Buffer)
 	cmd.Stdout = output
 	cmd.Stderr = output
@@ -255,14 +280,14 @@ func DockerComposeRemove(dockerComposeFile, tag string) *DockerError {
 	}
 	fmt.Printf(output.String()) // Wait to finish execution, so we can read all output
 
-	if strings.Contains(output.String(), "ERROR") || strings.Contains(output.String(), "error") {
+	if composeOutputFailed(output.String()) {
 		os.Exit(1)
 	}
 	return nil
 }
 
 // setupDockerComposeEnvs for docker-compose to use
-func setupDockerComposeEnvs(tag, command string, loglevel string) {
+func setupDockerComposeEnvs(tag, pfePort string, loglevel string) {
 	home := os.Getenv("HOME")
 	os.Setenv("PFE_IMAGE_NAME", pfeImageName)
 	os.Setenv("PERFORMANCE_IMAGE_NAME", performanceImageName)
@@ -288,38 +313,48 @@ func setupDockerComposeEnvs(tag, command string, loglevel string) {
 		os.Setenv("HOST_HOME", home)
 	}
 	os.Setenv("HOST_OS", GOOS)
-	os.Setenv("COMPOSE_PROJECT_NAME", "codewind")
+	os.Setenv("COMPOSE_PROJECT_NAME", composeProjectName)
 	os.Setenv("HOST_MAVEN_OPTS", os.Getenv("MAVEN_OPTS"))
 
-	if command == "remove" || command == "stop" {
-		os.Setenv("PFE_EXTERNAL_PORT", "")
-	} else {
-		fmt.Printf("Attempting to find available port\n")
-		portAvailable, port := isTCPPortAvailable(minTCPPort, maxTCPPort)
-		if !portAvailable {
-			fmt.Printf("No available external ports in range, will default to Docker-assigned port")
-		}
-		os.Setenv("PFE_EXTERNAL_PORT", port)
-	}
+	os.Setenv("PFE_EXTERNAL_PORT", pfePort)
 	os.Setenv("LOG_LEVEL", loglevel)
 }
 
-// PullImage - pull pfe/performance images from dockerhub
-func PullImage(dockerClient DockerClient, image string, jsonOutput bool) *DockerError {
-
-	codewindOut, err := dockerClient.ImagePull(context.Background(), image, types.ImagePullOptions{})
+// PullImage - pull pfe/performance images from dockerhub, or from a registry mirror of it when one is given, reporting
+// the progress of each layer. A terminal shows progress bars, other output gets a line per progress update, and JSON
+// output a PullProgress object per line. Images pulled from a mirror are tagged with their dockerhub name, which is
+// the name Codewind is started from.
+func PullImage(dockerClient DockerClient, image string, registryMirror string, jsonOutput bool) *DockerError {
+	pullFrom := MirrorImage(image, registryMirror)
+	codewindOut, err := dockerClient.ImagePull(context.Background(), pullFrom, types.ImagePullOptions{})
 
 	if err != nil {
-		return &DockerError{errOpImagePull, err, err.Error()}
+		return pullError(dockerClient, pullFrom, err)
 	}
+	defer codewindOut.Close()
 
-	if jsonOutput == true {
-		defer codewindOut.Close()
-		io.Copy(os.Stdout, codewindOut)
+	termFd, isTerm := term.GetFdInfo(os.Stderr)
+	if !jsonOutput && isTerm {
+		err = jsonmessage.DisplayJSONMessagesStream(codewindOut, os.Stderr, termFd, isTerm, nil)
 	} else {
-		defer codewindOut.Close()
-		termFd, isTerm := term.GetFdInfo(os.Stderr)
-		jsonmessage.DisplayJSONMessagesStream(codewindOut, os.Stderr, termFd, isTerm, nil)
+		err = ReadPullProgress(codewindOut, image, func(progress PullProgress) {
+			if jsonOutput {
+				progressJSON, _ := json.Marshal(progress)
+				fmt.Println(string(progressJSON))
+			} else {
+				fmt.Fprintln(os.Stderr, FormatPullProgress(progress))
+			}
+		})
+	}
+	if err != nil {
+		return pullError(dockerClient, pullFrom, err)
+	}
+
+	if pullFrom != image {
+		err = dockerClient.ImageTag(context.Background(), pullFrom, image)
+		if err != nil {
+			return &DockerError{errOpImageTag, err, err.Error()}
+		}
 	}
 	return nil
 }
@@ -360,7 +395,7 @@ func ValidateImageDigest(dockerClient DockerClient, image string) (string, *Dock
 					logr.Tracef("Validation for image digest ..%v succeeded\n", last10)
 				} else {
 					logr.Traceln("Local image digest did not match queried image digest from dockerhub - This could be a result of a bad download")
-					valError := errors.New(textBadDigest)
+					valError := i18n.Errorf(msgBadDigest, textBadDigest)
 					return image.ID, &DockerError{errOpValidate, valError, valError.Error()}
 				}
 			}
@@ -516,31 +551,58 @@ func getContainerAutoRemovePolicy(dockerClient DockerClient, containerID string)
 
 // GetPFEHostAndPort will return the current hostname and port that PFE is running on
 func GetPFEHostAndPort(dockerClient DockerClient) (string, string, *DockerError) {
+	_, hostname, port, err := getPFEAddress(dockerClient)
+	return hostname, port, err
+}
+
+// GetPFEURL : Returns the URL PFE is running on, which is https when Codewind was started with TLS, or an empty
+// string when PFE is not running
+func GetPFEURL(dockerClient DockerClient) (string, *DockerError) {
+	scheme, hostname, port, err := getPFEAddress(dockerClient)
+	if err != nil || hostname == "" || port == "" {
+		return "", err
+	}
+	return scheme + "://" + hostname + ":" + port, nil
+}
+
+// getPFEAddress returns the scheme, hostname and port PFE is running on
+func getPFEAddress(dockerClient DockerClient) (string, string, string, *DockerError) {
 	// only check that a PFE container is running, as that is all that's needed to get hostname and port
 	containerIsRunning, err := CheckContainerStatus(dockerClient, []string{PfeContainerName})
 	if err != nil {
-		return "", "", err
+		return "", "", "", err
 	}
 
 	// on Che, can assume PFE is always on localhost:9090
 	if os.Getenv("CHE_API_EXTERNAL") != "" {
-		return "localhost", "9090", nil
+		return "https", "localhost", "9090", nil
 	} else if containerIsRunning {
 		containerList, err := GetContainerList(dockerClient)
 		if err != nil {
-			return "", "", err
+			return "", "", "", err
 		}
 		for _, container := range containerList {
 			if strings.HasPrefix(container.Image, pfeImageName) {
 				for _, port := range container.Ports {
-					if port.PrivatePort == internalPFEPort {
-						return port.IP, strconv.Itoa(int(port.PublicPort)), nil
+					if scheme := pfeScheme(port.PrivatePort); scheme != "" {
+						return scheme, port.IP, strconv.Itoa(int(port.PublicPort)), nil
 					}
 				}
 			}
 		}
 	}
-	return "", "", nil
+	return "", "", "", nil
+}
+
+// pfeScheme returns the scheme PFE serves on a port of its container, or an empty string for its other ports
+func pfeScheme(privatePort uint16) string {
+	switch privatePort {
+	case internalPFEPort:
+		return "http"
+	case internalPFEHTTPSPort:
+		return "https"
+	}
+	return ""
 }
 
 // GetImageTags of Codewind images
@@ -618,6 +680,25 @@ func GetContainerTags(dockerClient DockerClient) ([]string, *DockerError) {
 	return tagArr, nil
 }
 
+// CheckRunningVersion : Returns an error if the running Codewind containers are of different versions, or when a
+// tag is given, of a version other than the tag
+func CheckRunningVersion(dockerClient DockerClient, tag string) *DockerError {
+	runningTags, dockerErr := GetContainerTags(dockerClient)
+	if dockerErr != nil {
+		return dockerErr
+	}
+	sort.Strings(runningTags)
+	if len(runningTags) > 1 {
+		err := i18n.Errorf(msgMixedVersions, textMixedVersions+": %s", strings.Join(runningTags, ", "))
+		return &DockerError{errOpVersionMismatch, err, err.Error()}
+	}
+	if tag != "" && len(runningTags) == 1 && runningTags[0] != tag {
+		err := i18n.Errorf(msgRunningVersion, textRunningVersion+": %s", runningTags[0])
+		return &DockerError{errOpVersionMismatch, err, err.Error()}
+	}
+	return nil
+}
+
 // LoginToRegistry : Log in locally to a docker registry with the supplied credentials.
 func LoginToRegistry(address string, username string, password string) *DockerError {
 	// Pipe the password via stdin.
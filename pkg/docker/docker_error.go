@@ -34,11 +34,22 @@ const (
 	errOpDockerComposeStart      = "DOCKER_COMPOSE_START_ERROR"
 	errOpDockerComposeStop       = "DOCKER_COMPOSE_STOP_ERROR"
 	errOpDockerComposeRemove     = "DOCKER_COMPOSE_REMOVE"
+	errOpDockerComposeNotFound   = "DOCKER_COMPOSE_NOT_FOUND"
 	errOpImageNotFound           = "IMAGE_NOT_FOUND"
 	errOpImagePull               = "IMAGE_PULL_ERROR"
 	errOpImageTag                = "IMAGE_TAG_ERROR"
 	errOpImageRemove             = "IMAGE_REMOVE_ERROR"
 	errOpImageDigest             = "IMAGE_DIGEST_ERROR"
+	errOpImageSave               = "IMAGE_SAVE_ERROR"
+	errOpImageLoad               = "IMAGE_LOAD_ERROR"
+	errOpVersionMismatch         = "VERSION_MISMATCH_ERROR"
+	errOpRegistryUnreachable     = "REGISTRY_UNREACHABLE_ERROR"
+	errOpPortInUse               = "PORT_IN_USE_ERROR"
+	errOpPortMapping             = "PORT_MAPPING_ERROR"
+	errOpCertificate             = "CERTIFICATE_ERROR"
+	errOpCertificateTrust        = "CERTIFICATE_TRUST_ERROR"
+	errOpVolumeList              = "VOLUME_LIST_ERROR"
+	errOpVolumeRemove            = "VOLUME_REMOVE_ERROR"
 	// ErrOpContainerList exported for test purposes
 	ErrOpContainerList  = "CONTAINER_LIST_ERROR"
 	errOpImageList      = "IMAGE_LIST_ERROR"
@@ -48,7 +59,28 @@ const (
 )
 
 const (
-	textBadDigest = "Failed to validate docker image checksum"
+	textBadDigest           = "Failed to validate docker image checksum"
+	textComposeNotFound     = "Docker Compose not found, install Docker Desktop or the docker compose plugin"
+	textImageNotInBundle    = "Image bundle does not contain image"
+	textMixedVersions       = "Codewind containers of different versions are running, run cwctl stop before starting one version"
+	textRegistryUnreachable = "Docker could not reach the registry to pull"
+	textPortInUse           = "The port is in use by another process, choose another with --pfe-port"
+	textInvalidPort         = "The port must be between 1 and 65535"
+	textTrustUnsupported    = "Trusting the certificate is not supported on this operating system, add it to the trusted certificates manually"
+	textRunningVersion      = "Codewind is already running a different version, run cwctl stop before starting"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgBadDigest           = "docker.bad_digest"
+	msgComposeNotFound     = "docker.compose_not_found"
+	msgImageNotInBundle    = "docker.image_not_in_bundle"
+	msgMixedVersions       = "docker.mixed_versions"
+	msgRegistryUnreachable = "docker.registry_unreachable"
+	msgPortInUse           = "docker.port_in_use"
+	msgInvalidPort         = "docker.invalid_port"
+	msgTrustUnsupported    = "docker.trust_unsupported"
+	msgRunningVersion      = "docker.running_version"
 )
 
 // DockerError : Error formatted in JSON containing an errorOp and a description
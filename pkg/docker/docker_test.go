@@ -21,13 +21,19 @@ import (
 func TestPullImage(t *testing.T) {
 	t.Run("does not error when docker ImagePull succeeds", func(t *testing.T) {
 		client := &MockDockerClientWithCw{}
-		err := PullImage(client, "dummyImage", true)
+		err := PullImage(client, "dummyImage", "", true)
+		assert.Nil(t, err)
+	})
+
+	t.Run("does not error when pulling from a registry mirror succeeds", func(t *testing.T) {
+		client := &MockDockerClientWithCw{}
+		err := PullImage(client, "dummyImage", "mirror.example.com", true)
 		assert.Nil(t, err)
 	})
 
 	t.Run("returns DockerError when docker ImagePull errors", func(t *testing.T) {
 		client := &MockDockerErrorClient{}
-		err := PullImage(client, "dummyImage", true)
+		err := PullImage(client, "dummyImage", "", true)
 		wantErr := &DockerError{errOpImagePull, errImagePull, errImagePull.Error()}
 		assert.Equal(t, wantErr, err)
 	})
@@ -168,6 +174,26 @@ func TestGetContainerTags(t *testing.T) {
 	})
 }
 
+func TestCheckRunningVersion(t *testing.T) {
+	t.Run("success case - the running version is the tag", func(t *testing.T) {
+		assert.Nil(t, CheckRunningVersion(&MockDockerClientWithCw{}, "0.0.9"))
+	})
+
+	t.Run("success case - any running version when no tag is given", func(t *testing.T) {
+		assert.Nil(t, CheckRunningVersion(&MockDockerClientWithCw{}, ""))
+	})
+
+	t.Run("success case - nothing running", func(t *testing.T) {
+		assert.Nil(t, CheckRunningVersion(&mockDockerClientWithoutCw{}, "0.9.0"))
+	})
+
+	t.Run("returns DockerError when a different version is running", func(t *testing.T) {
+		err := CheckRunningVersion(&MockDockerClientWithCw{}, "0.9.0")
+		assert.Equal(t, errOpVersionMismatch, err.Op)
+		assert.Contains(t, err.Desc, "0.0.9")
+	})
+}
+
 func TestGetPFEHostAndPort(t *testing.T) {
 	t.Run("returns the PFE host and port set in the ContainerList mock", func(t *testing.T) {
 		client := &MockDockerClientWithCw{}
@@ -195,6 +221,24 @@ func TestGetPFEHostAndPort(t *testing.T) {
 	})
 }
 
+func TestGetPFEURL(t *testing.T) {
+	t.Run("returns the http URL of PFE set in the ContainerList mock", func(t *testing.T) {
+		pfeURL, err := GetPFEURL(&MockDockerClientWithCw{})
+		assert.Nil(t, err)
+		assert.Equal(t, "http://pfe:1000", pfeURL)
+	})
+
+	t.Run("returns an https URL when PFE publishes its HTTPS port", func(t *testing.T) {
+		assert.Equal(t, "https", pfeScheme(9191))
+		assert.Equal(t, "", pfeScheme(9777))
+	})
+
+	t.Run("returns DockerError when docker ContainerList errors", func(t *testing.T) {
+		_, err := GetPFEURL(&MockDockerErrorClient{})
+		assert.Equal(t, ErrOpContainerList, err.Op)
+	})
+}
+
 func TestValidateImageDigest(t *testing.T) {
 	t.Run("no error returned when image digests match those from dockerhub", func(t *testing.T) {
 		client := &MockDockerClientWithCw{}
@@ -21,7 +21,9 @@ import (
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/api/types/volume"
 	v1 "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -82,6 +84,14 @@ var mockContainerListWithoutCwContainers = []types.Container{
 	},
 }
 
+// mockImageLoadStream is what Docker reports when loading the images of a Codewind image bundle
+var mockImageLoadStream = `{"stream":"Loaded image: eclipse/codewind-pfe-amd64:latest\n"}
+{"stream":"Loaded image: eclipse/codewind-performance-amd64:latest\n"}
+`
+
+// mockImageArchive stands in for the tar archive of saved images
+var mockImageArchive = "mock image archive"
+
 //MockDockerClientWithCw - This mock client will return container and images lists, with Codewind items included
 type MockDockerClientWithCw struct {
 }
@@ -134,6 +144,39 @@ func (m *MockDockerClientWithCw) ServerVersion(ctx context.Context) (types.Versi
 	return types.Version{Platform: struct{ Name string }{""}, Components: []types.ComponentVersion{}, Version: "", APIVersion: "", MinAPIVersion: "", GitCommit: "", GoVersion: "", Os: "", Arch: "", KernelVersion: "", Experimental: true, BuildTime: ""}, nil
 }
 
+//ImageLoad - returns the images loaded from a Codewind image bundle
+func (m *MockDockerClientWithCw) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	ioutil.ReadAll(input)
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageLoadStream)))
+	return types.ImageLoadResponse{Body: r, JSON: true}, nil
+}
+
+//ImageSave - returns the contents of a mock image archive
+func (m *MockDockerClientWithCw) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageArchive)))
+	return r, nil
+}
+
+//ImageTag - returns nil
+func (m *MockDockerClientWithCw) ImageTag(ctx context.Context, source, target string) error {
+	return nil
+}
+
+//Info - returns the Info of a daemon without a proxy
+func (m *MockDockerClientWithCw) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, nil
+}
+
+//VolumeList - returns the Codewind workspace volume
+func (m *MockDockerClientWithCw) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{Volumes: []*types.Volume{{Name: "codewind_cw-workspace"}}}, nil
+}
+
+//VolumeRemove - returns nil
+func (m *MockDockerClientWithCw) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return nil
+}
+
 //ContainerInspect - returns basic ContainerJSON
 func (m *MockDockerClientWithCw) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
 	return types.ContainerJSON{
@@ -207,6 +250,39 @@ func (m *mockDockerClientWithPFEContainerOnly) ServerVersion(ctx context.Context
 	return types.Version{Platform: struct{ Name string }{""}, Components: []types.ComponentVersion{}, Version: "", APIVersion: "", MinAPIVersion: "", GitCommit: "", GoVersion: "", Os: "", Arch: "", KernelVersion: "", Experimental: true, BuildTime: ""}, nil
 }
 
+//ImageLoad - returns the images loaded from a Codewind image bundle
+func (m *mockDockerClientWithPFEContainerOnly) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	ioutil.ReadAll(input)
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageLoadStream)))
+	return types.ImageLoadResponse{Body: r, JSON: true}, nil
+}
+
+//ImageSave - returns the contents of a mock image archive
+func (m *mockDockerClientWithPFEContainerOnly) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageArchive)))
+	return r, nil
+}
+
+//ImageTag - returns nil
+func (m *mockDockerClientWithPFEContainerOnly) ImageTag(ctx context.Context, source, target string) error {
+	return nil
+}
+
+//Info - returns the Info of a daemon without a proxy
+func (m *mockDockerClientWithPFEContainerOnly) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, nil
+}
+
+//VolumeList - returns no volumes
+func (m *mockDockerClientWithPFEContainerOnly) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{}, nil
+}
+
+//VolumeRemove - returns nil
+func (m *mockDockerClientWithPFEContainerOnly) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return nil
+}
+
 func (m *mockDockerClientWithPFEContainerOnly) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
 	return types.ContainerJSON{
 		ContainerJSONBase: &types.ContainerJSONBase{
@@ -302,12 +378,51 @@ func (m *mockDockerClientWithoutCw) ServerVersion(ctx context.Context) (types.Ve
 	return types.Version{Platform: struct{ Name string }{""}, Components: []types.ComponentVersion{}, Version: "", APIVersion: "", MinAPIVersion: "", GitCommit: "", GoVersion: "", Os: "", Arch: "", KernelVersion: "", Experimental: true, BuildTime: ""}, nil
 }
 
+//ImageLoad - returns the images loaded from a Codewind image bundle
+func (m *mockDockerClientWithoutCw) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	ioutil.ReadAll(input)
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageLoadStream)))
+	return types.ImageLoadResponse{Body: r, JSON: true}, nil
+}
+
+//ImageSave - returns the contents of a mock image archive
+func (m *mockDockerClientWithoutCw) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	r := ioutil.NopCloser(bytes.NewReader([]byte(mockImageArchive)))
+	return r, nil
+}
+
+//ImageTag - returns nil
+func (m *mockDockerClientWithoutCw) ImageTag(ctx context.Context, source, target string) error {
+	return nil
+}
+
+//Info - returns the Info of a daemon without a proxy
+func (m *mockDockerClientWithoutCw) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, nil
+}
+
+//VolumeList - returns no volumes
+func (m *mockDockerClientWithoutCw) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{}, nil
+}
+
+//VolumeRemove - returns nil
+func (m *mockDockerClientWithoutCw) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return nil
+}
+
 //MockDockerErrorClient - This mock client will return errors for each call to a docker function
 type MockDockerErrorClient struct {
 }
 
 var errImagePull = errors.New("error pulling image")
 var errImageList = errors.New("error listing images")
+var errImageLoad = errors.New("error loading images")
+var errImageSave = errors.New("error saving images")
+var errImageTag = errors.New("error tagging image")
+var errInfo = errors.New("error getting system info")
+var errVolumeList = errors.New("error listing volumes")
+var errVolumeRemove = errors.New("error removing volume")
 
 //ErrContainerList - exported for testing purposes
 var ErrContainerList = errors.New("error listing containers")
@@ -394,3 +509,33 @@ func (m *MockDockerErrorClient) CopyFromContainer(ctx context.Context, container
 func (m *MockDockerErrorClient) ServerVersion(ctx context.Context) (types.Version, error) {
 	return types.Version{Platform: struct{ Name string }{""}, Components: []types.ComponentVersion{}, Version: "", APIVersion: "", MinAPIVersion: "", GitCommit: "", GoVersion: "", Os: "", Arch: "", KernelVersion: "", Experimental: true, BuildTime: ""}, ErrServerVersion
 }
+
+//ImageLoad - returns an error
+func (m *MockDockerErrorClient) ImageLoad(ctx context.Context, input io.Reader, quiet bool) (types.ImageLoadResponse, error) {
+	return types.ImageLoadResponse{}, errImageLoad
+}
+
+//ImageSave - returns an error
+func (m *MockDockerErrorClient) ImageSave(ctx context.Context, imageIDs []string) (io.ReadCloser, error) {
+	return nil, errImageSave
+}
+
+//ImageTag - returns an error
+func (m *MockDockerErrorClient) ImageTag(ctx context.Context, source, target string) error {
+	return errImageTag
+}
+
+//Info - returns an error
+func (m *MockDockerErrorClient) Info(ctx context.Context) (types.Info, error) {
+	return types.Info{}, errInfo
+}
+
+//VolumeList - returns an error
+func (m *MockDockerErrorClient) VolumeList(ctx context.Context, filter filters.Args) (volume.VolumeListOKBody, error) {
+	return volume.VolumeListOKBody{}, errVolumeList
+}
+
+//VolumeRemove - returns an error
+func (m *MockDockerErrorClient) VolumeRemove(ctx context.Context, volumeID string, force bool) error {
+	return errVolumeRemove
+}
@@ -20,14 +20,17 @@ import (
 	"net/http"
 	"path"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"gopkg.in/yaml.v2"
 )
 
-// WriteToComposeFile the contents of the docker compose yaml
-func WriteToComposeFile(dockerComposeFile string, debug bool) *DockerError {
+// WriteToComposeFile the contents of the docker compose yaml. When a TLS directory is given, PFE and performance serve
+// HTTPS with the certificate in it.
+func WriteToComposeFile(dockerComposeFile string, debug bool, tlsDir string) *DockerError {
 	dockerComposeTempErr := utils.CreateTempFile(dockerComposeFile)
 	if dockerComposeTempErr != nil {
 		return &DockerError{errOpDockerComposeFileCreate, dockerComposeTempErr, dockerComposeTempErr.Error()}
@@ -56,6 +59,10 @@ func WriteToComposeFile(dockerComposeFile string, debug bool) *DockerError {
 		return &DockerError{errOpDockerComposeFileCreate, unmarshDataErr, unmarshDataErr.Error()}
 	}
 
+	if tlsDir != "" {
+		useTLS(&dataStruct, tlsDir)
+	}
+
 	if debug == true && len(dataStruct.SERVICES.PFE.Ports) > 0 {
 		debugPort := DetermineDebugPortForPFE()
 		// Add the debug port to the docker compose data
@@ -80,6 +87,26 @@ func WriteToComposeFile(dockerComposeFile string, debug bool) *DockerError {
 	return nil
 }
 
+// useTLS mounts the certificate into the containers and has them serve HTTPS, publishing the HTTPS port of PFE
+func useTLS(dataStruct *Compose, tlsDir string) {
+	environment := []string{
+		"PORTAL_HTTPS=true",
+		"CODEWIND_TLS_CERT=" + containerTLSDirectory + "/" + certificateFile,
+		"CODEWIND_TLS_KEY=" + containerTLSDirectory + "/" + keyFile,
+	}
+	volume := tlsDir + ":" + containerTLSDirectory + ":ro"
+
+	pfe := &dataStruct.SERVICES.PFE
+	pfe.Environment = append(pfe.Environment, environment...)
+	pfe.Volumes = append(pfe.Volumes, volume)
+	for i, port := range pfe.Ports {
+		pfe.Ports[i] = strings.Replace(port, ":"+strconv.Itoa(internalPFEPort), ":"+strconv.Itoa(internalPFEHTTPSPort), 1)
+	}
+	performance := &dataStruct.SERVICES.PERFORMANCE
+	performance.Environment = append(performance.Environment, environment...)
+	performance.Volumes = append(performance.Volumes, volume)
+}
+
 func writeDockerConfigSecretFile(parentPath string) (string, *DockerError) {
 	dockerConfig, err := getDockerCredentials("local")
 	if err != nil {
@@ -107,7 +134,7 @@ func ClearDockerConfigSecret(parentPath string) error {
 }
 
 // PingHealth - pings environment api every 15 seconds to check if containers started
-func PingHealth(healthEndpoint string) (bool, *DockerError) {
+func PingHealth(httpClient *http.Client, healthEndpoint string) (bool, *DockerError) {
 	var started = false
 	fmt.Println("Waiting for Codewind to start")
 
@@ -116,18 +143,18 @@ func PingHealth(healthEndpoint string) (bool, *DockerError) {
 		return false, err
 	}
 
-	hostname, port, err := GetPFEHostAndPort(dockerClient)
+	pfeURL, err := GetPFEURL(dockerClient)
 	if err != nil {
 		return false, err
 	}
 	for i := 0; i < 120; i++ {
-		resp, err := http.Get("http://" + hostname + ":" + port + healthEndpoint)
+		resp, err := httpClient.Get(pfeURL + healthEndpoint)
 		if err != nil {
 			fmt.Printf(".")
 		} else {
 			if resp.StatusCode == 200 {
 				fmt.Println("\nHTTP Response Status:", resp.StatusCode, http.StatusText(resp.StatusCode))
-				fmt.Println("Codewind successfully started on http://" + hostname + ":" + port)
+				fmt.Println("Codewind successfully started on " + pfeURL)
 				started = true
 				break
 			}
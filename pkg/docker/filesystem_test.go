@@ -12,6 +12,7 @@
 package docker
 
 import (
+	"fmt"
 	"os"
 	"path"
 	"testing"
@@ -19,6 +20,7 @@ import (
 	"github.com/eclipse/codewind-installer/pkg/globals"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
 )
 
 const testDir = "./testDir"
@@ -34,7 +36,7 @@ func TestWriteToComposeFile(t *testing.T) {
 		os.Create(testFile)
 		defer os.RemoveAll(testDir)
 
-		err := WriteToComposeFile(testFile, false)
+		err := WriteToComposeFile(testFile, false, "")
 
 		pathExists := utils.PathExists(testFile)
 		assert.True(t, pathExists)
@@ -42,3 +44,19 @@ func TestWriteToComposeFile(t *testing.T) {
 	})
 	globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
 }
+
+func TestUseTLS(t *testing.T) {
+	dataStruct := Compose{}
+	assert.Nil(t, yaml.Unmarshal([]byte(fmt.Sprintf(composeTemplate, "/dev/null")), &dataStruct))
+
+	useTLS(&dataStruct, "/home/user/.codewind/tls")
+
+	pfe := dataStruct.SERVICES.PFE
+	assert.Equal(t, []string{"127.0.0.1:${PFE_EXTERNAL_PORT}:9191"}, pfe.Ports)
+	assert.Contains(t, pfe.Environment, "PORTAL_HTTPS=true")
+	assert.Contains(t, pfe.Environment, "CODEWIND_TLS_CERT=/codewind-tls/cert.pem")
+	assert.Contains(t, pfe.Volumes, "/home/user/.codewind/tls:/codewind-tls:ro")
+	performance := dataStruct.SERVICES.PERFORMANCE
+	assert.Contains(t, performance.Environment, "PORTAL_HTTPS=true")
+	assert.Equal(t, []string{"/home/user/.codewind/tls:/codewind-tls:ro"}, performance.Volumes)
+}
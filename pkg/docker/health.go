@@ -0,0 +1,194 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// dockerSocket is the socket PFE builds and runs projects through
+const dockerSocket = "/var/run/docker.sock"
+
+type (
+	// ComponentHealth : The diagnosis of one local Codewind container
+	ComponentHealth struct {
+		Component    string        `json:"component"`
+		Container    string        `json:"container"`
+		Healthy      bool          `json:"healthy"`
+		State        string        `json:"state"`
+		RestartCount int           `json:"restartCount"`
+		Checks       []HealthCheck `json:"checks"`
+	}
+
+	// HealthCheck : The result of one check of a container
+	HealthCheck struct {
+		Name   string `json:"name"`
+		Passed bool   `json:"passed"`
+		Detail string `json:"detail"`
+	}
+)
+
+func (health *ComponentHealth) check(name string, passed bool, detail string) {
+	health.Checks = append(health.Checks, HealthCheck{Name: name, Passed: passed, Detail: detail})
+	if !passed {
+		health.Healthy = false
+	}
+}
+
+// CheckLocalHealth : Checks each local Codewind container in turn, reporting whether it is running, how often it
+// has restarted, and whether it is doing its job: PFE answering its API with the docker socket mounted, and the
+// performance container reachable by PFE. The HTTP client is used to call PFE.
+func CheckLocalHealth(dockerClient DockerClient, httpClient utils.HTTPClient, healthEndpoint string) ([]ComponentHealth, *DockerError) {
+	containers, dockerErr := GetContainerListWithOptions(dockerClient, types.ContainerListOptions{All: true})
+	if dockerErr != nil {
+		return nil, dockerErr
+	}
+
+	pfe, pfeInfo, dockerErr := inspectComponent(dockerClient, containers, "pfe", PfeContainerName)
+	if dockerErr != nil {
+		return nil, dockerErr
+	}
+	performance, performanceInfo, dockerErr := inspectComponent(dockerClient, containers, "performance", PerformanceContainerName)
+	if dockerErr != nil {
+		return nil, dockerErr
+	}
+
+	pfeURL := ""
+	if pfeInfo != nil {
+		pfe.check("docker socket", hasMount(*pfeInfo, dockerSocket), "PFE needs "+dockerSocket+" mounted to build and run projects")
+		if pfe.State == "running" {
+			pfeURL = pfeAddress(containers)
+			status, detail := checkEndpoint(httpClient, pfeURL+healthEndpoint)
+			pfe.check("api", status == http.StatusOK, detail)
+		}
+	}
+	if performanceInfo != nil && performance.State == "running" {
+		if pfeInfo != nil {
+			passed, detail := sharesNetwork(*pfeInfo, *performanceInfo)
+			performance.check("network", passed, detail)
+		}
+		if pfeURL != "" {
+			// PFE proxies the performance dashboard, answering with a server error when it cannot reach it
+			status, detail := checkEndpoint(httpClient, pfeURL+"/performance/")
+			performance.check("reachable", status != 0 && status < 500, detail)
+		}
+	}
+	return []ComponentHealth{pfe, performance}, nil
+}
+
+// inspectComponent checks the state and restarts of a container, returning its details if it exists
+func inspectComponent(dockerClient DockerClient, containers []types.Container, component string, name string) (ComponentHealth, *types.ContainerJSON, *DockerError) {
+	health := ComponentHealth{Component: component, Container: name, Healthy: true, State: "missing", Checks: []HealthCheck{}}
+	containerID := ""
+	for _, container := range containers {
+		if len(container.Names) > 0 && container.Names[0] == "/"+name {
+			containerID = container.ID
+		}
+	}
+	if containerID == "" {
+		health.check("running", false, "The container does not exist, run cwctl start")
+		return health, nil, nil
+	}
+
+	info, dockerErr := InspectContainer(dockerClient, containerID)
+	if dockerErr != nil {
+		return health, nil, dockerErr
+	}
+	if info.ContainerJSONBase != nil {
+		health.RestartCount = info.RestartCount
+	}
+	if info.ContainerJSONBase == nil || info.State == nil {
+		health.State = "unknown"
+		health.check("running", false, "Docker did not report the state of the container")
+		return health, &info, nil
+	}
+
+	health.State = info.State.Status
+	if info.State.Running && !info.State.Restarting {
+		health.check("running", true, "Running since "+info.State.StartedAt)
+	} else {
+		detail := "The container is " + info.State.Status
+		if info.State.ExitCode != 0 {
+			detail += " with exit code " + strconv.Itoa(info.State.ExitCode)
+		}
+		if info.State.Error != "" {
+			detail += ": " + info.State.Error
+		}
+		health.check("running", false, detail)
+	}
+	health.check("restarts", health.RestartCount == 0, "Restarted "+strconv.Itoa(health.RestartCount)+" times")
+	return health, &info, nil
+}
+
+// pfeAddress returns the URL PFE is published on, from the container list
+func pfeAddress(containers []types.Container) string {
+	for _, container := range containers {
+		if len(container.Names) == 0 || container.Names[0] != "/"+PfeContainerName {
+			continue
+		}
+		for _, port := range container.Ports {
+			if port.PrivatePort == internalPFEPort && port.PublicPort != 0 {
+				host := port.IP
+				if host == "" || host == "0.0.0.0" {
+					host = "127.0.0.1"
+				}
+				return "http://" + host + ":" + strconv.Itoa(int(port.PublicPort))
+			}
+		}
+	}
+	return ""
+}
+
+// checkEndpoint returns the status an endpoint responds with, or 0 when it does not respond
+func checkEndpoint(httpClient utils.HTTPClient, url string) (int, string) {
+	if strings.HasPrefix(url, "/") {
+		return 0, "PFE is not published on a port"
+	}
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err.Error()
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, "No response from " + url + ": " + err.Error()
+	}
+	defer resp.Body.Close()
+	return resp.StatusCode, url + " responded " + resp.Status
+}
+
+// hasMount reports whether a path is mounted into a container
+func hasMount(info types.ContainerJSON, destination string) bool {
+	for _, mount := range info.Mounts {
+		if mount.Destination == destination {
+			return true
+		}
+	}
+	return false
+}
+
+// sharesNetwork reports whether two containers are attached to a common network, so they can reach each other
+func sharesNetwork(first types.ContainerJSON, second types.ContainerJSON) (bool, string) {
+	if first.NetworkSettings == nil || second.NetworkSettings == nil {
+		return false, "Docker did not report the networks of the containers"
+	}
+	for name := range second.NetworkSettings.Networks {
+		if _, ok := first.NetworkSettings.Networks[name]; ok {
+			return true, "On network " + name + " with PFE"
+		}
+	}
+	return false, "Not on a network with PFE"
+}
@@ -0,0 +1,139 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/network"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockHealthClient lists the Codewind containers and reports the details given for each
+type mockHealthClient struct {
+	MockDockerClientWithCw
+	containers map[string]types.ContainerJSON
+}
+
+func (m *mockHealthClient) ContainerList(ctx context.Context, containerListOptions types.ContainerListOptions) ([]types.Container, error) {
+	containers := []types.Container{}
+	for id := range m.containers {
+		container := types.Container{ID: id, Names: []string{"/" + id}}
+		if id == PfeContainerName {
+			container.Ports = []types.Port{{PrivatePort: 9090, PublicPort: 10000, IP: "127.0.0.1"}}
+		}
+		containers = append(containers, container)
+	}
+	return containers, nil
+}
+
+func (m *mockHealthClient) ContainerInspect(ctx context.Context, containerID string) (types.ContainerJSON, error) {
+	return m.containers[containerID], nil
+}
+
+func containerDetails(status string, restarts int, mounts []string, networks ...string) types.ContainerJSON {
+	details := types.ContainerJSON{
+		ContainerJSONBase: &types.ContainerJSONBase{
+			State:        &types.ContainerState{Status: status, Running: status == "running", StartedAt: "2020-04-01T10:00:00Z"},
+			RestartCount: restarts,
+		},
+		NetworkSettings: &types.NetworkSettings{Networks: map[string]*network.EndpointSettings{}},
+	}
+	if status == "exited" {
+		details.State.ExitCode = 137
+	}
+	for _, mount := range mounts {
+		details.Mounts = append(details.Mounts, types.MountPoint{Destination: mount})
+	}
+	for _, name := range networks {
+		details.NetworkSettings.Networks[name] = &network.EndpointSettings{}
+	}
+	return details
+}
+
+// mockPFE answers requests for each path with a status, and fails those for other paths
+type mockPFE struct {
+	statuses map[string]int
+}
+
+func (m *mockPFE) Do(req *http.Request) (*http.Response, error) {
+	status, ok := m.statuses[req.URL.Path]
+	if !ok {
+		return nil, errors.New("connection refused")
+	}
+	return &http.Response{StatusCode: status, Status: http.StatusText(status), Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}, nil
+}
+
+func checksOf(health ComponentHealth) map[string]bool {
+	checks := map[string]bool{}
+	for _, check := range health.Checks {
+		checks[check.Name] = check.Passed
+	}
+	return checks
+}
+
+func TestCheckLocalHealth(t *testing.T) {
+	t.Run("reports every component healthy", func(t *testing.T) {
+		client := &mockHealthClient{containers: map[string]types.ContainerJSON{
+			PfeContainerName:         containerDetails("running", 0, []string{dockerSocket}, "codewind_network"),
+			PerformanceContainerName: containerDetails("running", 0, nil, "codewind_network"),
+		}}
+		httpClient := &mockPFE{statuses: map[string]int{"/api/v1/environment": 200, "/performance/": 200}}
+
+		health, err := CheckLocalHealth(client, httpClient, "/api/v1/environment")
+		assert.Nil(t, err)
+		assert.True(t, health[0].Healthy)
+		assert.Equal(t, map[string]bool{"running": true, "restarts": true, "docker socket": true, "api": true}, checksOf(health[0]))
+		assert.True(t, health[1].Healthy)
+		assert.Equal(t, map[string]bool{"running": true, "restarts": true, "network": true, "reachable": true}, checksOf(health[1]))
+	})
+
+	t.Run("diagnoses a restarting PFE without the docker socket and an unreachable performance container", func(t *testing.T) {
+		client := &mockHealthClient{containers: map[string]types.ContainerJSON{
+			PfeContainerName:         containerDetails("running", 3, nil, "codewind_network"),
+			PerformanceContainerName: containerDetails("running", 0, nil, "bridge"),
+		}}
+		httpClient := &mockPFE{statuses: map[string]int{"/api/v1/environment": 503, "/performance/": 502}}
+
+		health, err := CheckLocalHealth(client, httpClient, "/api/v1/environment")
+		assert.Nil(t, err)
+		assert.False(t, health[0].Healthy)
+		assert.Equal(t, 3, health[0].RestartCount)
+		assert.Equal(t, map[string]bool{"running": true, "restarts": false, "docker socket": false, "api": false}, checksOf(health[0]))
+		assert.False(t, health[1].Healthy)
+		assert.Equal(t, map[string]bool{"running": true, "restarts": true, "network": false, "reachable": false}, checksOf(health[1]))
+	})
+
+	t.Run("reports stopped and missing containers", func(t *testing.T) {
+		client := &mockHealthClient{containers: map[string]types.ContainerJSON{
+			PfeContainerName: containerDetails("exited", 0, []string{dockerSocket}),
+		}}
+
+		health, err := CheckLocalHealth(client, &mockPFE{}, "/api/v1/environment")
+		assert.Nil(t, err)
+		assert.Equal(t, "exited", health[0].State)
+		assert.Equal(t, HealthCheck{Name: "running", Passed: false, Detail: "The container is exited with exit code 137"}, health[0].Checks[0])
+		assert.Equal(t, "missing", health[1].State)
+		assert.Equal(t, map[string]bool{"running": false}, checksOf(health[1]))
+	})
+
+	t.Run("returns DockerError when docker ContainerList errors", func(t *testing.T) {
+		_, err := CheckLocalHealth(&MockDockerErrorClient{}, &mockPFE{}, "/api/v1/environment")
+		assert.Equal(t, ErrOpContainerList, err.Op)
+	})
+}
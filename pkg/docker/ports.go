@@ -0,0 +1,97 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// portMappingFile is the file in the Codewind directory the chosen ports are kept in between starts
+const portMappingFile = "ports.json"
+
+// PortMapping : The host ports the local Codewind containers are published on, kept so that PFE stays on the same
+// port from one start to the next
+type PortMapping struct {
+	PFE int `json:"pfe"`
+}
+
+// LoadPortMapping : Returns the ports chosen when Codewind was last started, or an empty mapping if it has not been
+func LoadPortMapping(codewindDir string) (PortMapping, *DockerError) {
+	mapping := PortMapping{}
+	contents, err := ioutil.ReadFile(filepath.Join(codewindDir, portMappingFile))
+	if os.IsNotExist(err) {
+		return mapping, nil
+	}
+	if err == nil {
+		err = json.Unmarshal(contents, &mapping)
+	}
+	if err != nil {
+		return mapping, &DockerError{errOpPortMapping, err, err.Error()}
+	}
+	return mapping, nil
+}
+
+// SavePortMapping : Keeps the ports Codewind was started on for the next start
+func SavePortMapping(codewindDir string, mapping PortMapping) *DockerError {
+	contents, err := json.MarshalIndent(mapping, "", "  ")
+	if err == nil {
+		err = ioutil.WriteFile(filepath.Join(codewindDir, portMappingFile), contents, 0644)
+	}
+	if err != nil {
+		return &DockerError{errOpPortMapping, err, err.Error()}
+	}
+	return nil
+}
+
+// portFree reports whether a port can be published on localhost, replaced in tests
+var portFree = func(port int) bool {
+	listener, err := net.Listen("tcp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		return false
+	}
+	listener.Close()
+	return true
+}
+
+// ChoosePFEPort : Returns the host port to publish PFE on. A requested port is used if it is free, and is an error if
+// it is not. Otherwise the port PFE was last published on is kept while it is free, falling back to the lowest free
+// port in the Codewind range, so the choice is the same from one start to the next. Returns 0 for Docker to assign a
+// port when the whole range is in use.
+func ChoosePFEPort(requested int, previous int) (int, *DockerError) {
+	if requested != 0 {
+		if requested < 1 || requested > 65535 {
+			err := i18n.Errorf(msgInvalidPort, textInvalidPort+": %s", strconv.Itoa(requested))
+			return 0, &DockerError{errOpPortInUse, err, err.Error()}
+		}
+		if !portFree(requested) {
+			err := i18n.Errorf(msgPortInUse, textPortInUse+": %s", strconv.Itoa(requested))
+			return 0, &DockerError{errOpPortInUse, err, err.Error()}
+		}
+		return requested, nil
+	}
+	if previous != 0 && portFree(previous) {
+		return previous, nil
+	}
+	for port := minTCPPort; port < maxTCPPort; port++ {
+		if portFree(port) {
+			return port, nil
+		}
+	}
+	return 0, nil
+}
@@ -0,0 +1,102 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChoosePFEPort(t *testing.T) {
+	originalPortFree := portFree
+	defer func() { portFree = originalPortFree }()
+	inUse := func(ports ...int) {
+		portFree = func(port int) bool {
+			for _, used := range ports {
+				if port == used {
+					return false
+				}
+			}
+			return true
+		}
+	}
+
+	t.Run("uses the requested port when it is free", func(t *testing.T) {
+		inUse(10000)
+		port, err := ChoosePFEPort(9191, 10000)
+		assert.Nil(t, err)
+		assert.Equal(t, 9191, port)
+	})
+
+	t.Run("returns DockerError when the requested port is in use", func(t *testing.T) {
+		inUse(9090)
+		_, err := ChoosePFEPort(9090, 0)
+		assert.Equal(t, errOpPortInUse, err.Op)
+		assert.Contains(t, err.Desc, "9090")
+	})
+
+	t.Run("returns DockerError when the requested port is invalid", func(t *testing.T) {
+		inUse()
+		_, err := ChoosePFEPort(70000, 0)
+		assert.Equal(t, errOpPortInUse, err.Op)
+	})
+
+	t.Run("keeps the previous port while it is free", func(t *testing.T) {
+		inUse()
+		port, err := ChoosePFEPort(0, 10005)
+		assert.Nil(t, err)
+		assert.Equal(t, 10005, port)
+	})
+
+	t.Run("chooses the lowest free port when the previous port is in use", func(t *testing.T) {
+		inUse(10000, 10001, 10005)
+		port, err := ChoosePFEPort(0, 10005)
+		assert.Nil(t, err)
+		assert.Equal(t, 10002, port)
+	})
+
+	t.Run("leaves Docker to assign a port when the range is in use", func(t *testing.T) {
+		portFree = func(int) bool { return false }
+		port, err := ChoosePFEPort(0, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, port)
+	})
+}
+
+func TestPortMapping(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ports")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("is empty before Codewind is first started", func(t *testing.T) {
+		mapping, err := LoadPortMapping(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, PortMapping{}, mapping)
+	})
+
+	t.Run("is kept between starts", func(t *testing.T) {
+		assert.Nil(t, SavePortMapping(dir, PortMapping{PFE: 10003}))
+		mapping, err := LoadPortMapping(dir)
+		assert.Nil(t, err)
+		assert.Equal(t, PortMapping{PFE: 10003}, mapping)
+	})
+
+	t.Run("returns DockerError when the file is not valid", func(t *testing.T) {
+		assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, portMappingFile), []byte("{"), 0644))
+		_, err := LoadPortMapping(dir)
+		assert.Equal(t, errOpPortMapping, err.Op)
+	})
+}
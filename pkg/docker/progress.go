@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/docker/docker/pkg/jsonmessage"
+)
+
+type (
+	// PullProgress : The progress of an image pull, reported each time a layer changes state or the percentage of
+	// the image pulled changes
+	PullProgress struct {
+		Image   string          `json:"image"`
+		Layer   string          `json:"layer,omitempty"`
+		Status  string          `json:"status"`
+		Current int64           `json:"current,omitempty"`
+		Total   int64           `json:"total,omitempty"`
+		Percent int             `json:"percent"`
+		Layers  []LayerProgress `json:"layers"`
+	}
+
+	// LayerProgress : The state of one layer of an image being pulled
+	LayerProgress struct {
+		ID      string `json:"id"`
+		Status  string `json:"status"`
+		Current int64  `json:"current,omitempty"`
+		Total   int64  `json:"total,omitempty"`
+	}
+)
+
+// layerDone reports whether a layer needs nothing more downloaded
+func layerDone(status string) bool {
+	switch status {
+	case "Already exists", "Pull complete", "Download complete", "Extracting", "Verifying Checksum":
+		return true
+	}
+	return false
+}
+
+// pullTracker follows the layers of an image through a pull
+type pullTracker struct {
+	image       string
+	layers      map[string]*LayerProgress
+	lastPercent int
+}
+
+// update applies a message of the pull stream, returning the progress to report or nil when nothing reportable
+// changed
+func (tracker *pullTracker) update(message jsonmessage.JSONMessage) *PullProgress {
+	// Messages about the whole image name the tag being pulled, or nothing, rather than a layer
+	if message.ID == "" || strings.HasPrefix(message.Status, "Pulling from") {
+		if message.Status == "" {
+			return nil
+		}
+		return tracker.progress("", message.Status)
+	}
+	layer, ok := tracker.layers[message.ID]
+	if !ok {
+		layer = &LayerProgress{ID: message.ID}
+		tracker.layers[message.ID] = layer
+	}
+	statusChanged := layer.Status != message.Status
+	layer.Status = message.Status
+	if message.Progress != nil && message.Status == "Downloading" {
+		layer.Current = message.Progress.Current
+		if message.Progress.Total > 0 {
+			layer.Total = message.Progress.Total
+		}
+	}
+	if layerDone(message.Status) && layer.Total > 0 {
+		layer.Current = layer.Total
+	}
+
+	percent := tracker.percent()
+	if !statusChanged && percent == tracker.lastPercent {
+		return nil
+	}
+	tracker.lastPercent = percent
+	return tracker.progress(message.ID, message.Status)
+}
+
+// percent is the share of the image downloaded, over the layers whose sizes are known
+func (tracker *pullTracker) percent() int {
+	var current, total int64
+	for _, layer := range tracker.layers {
+		current += layer.Current
+		total += layer.Total
+	}
+	if total == 0 {
+		return 0
+	}
+	return int(current * 100 / total)
+}
+
+func (tracker *pullTracker) progress(layerID string, status string) *PullProgress {
+	progress := PullProgress{Image: tracker.image, Layer: layerID, Status: status, Percent: tracker.percent(), Layers: []LayerProgress{}}
+	for _, layer := range tracker.layers {
+		progress.Layers = append(progress.Layers, *layer)
+		progress.Current += layer.Current
+		progress.Total += layer.Total
+	}
+	sort.Slice(progress.Layers, func(i, j int) bool {
+		return progress.Layers[i].ID < progress.Layers[j].ID
+	})
+	return &progress
+}
+
+// ReadPullProgress : Reads the JSON messages Docker streams while pulling an image, calling report as layers change
+// state and as the percentage of the image downloaded grows. Returns the error Docker reports if the pull fails.
+func ReadPullProgress(stream io.Reader, image string, report func(PullProgress)) error {
+	tracker := pullTracker{image: image, layers: map[string]*LayerProgress{}}
+	decoder := json.NewDecoder(stream)
+	for {
+		var message jsonmessage.JSONMessage
+		if err := decoder.Decode(&message); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if message.Error != nil {
+			return errors.New(message.Error.Message)
+		}
+		if progress := tracker.update(message); progress != nil {
+			report(*progress)
+		}
+	}
+}
+
+// FormatPullProgress : Describes a pull progress update on one line, for output that is not a terminal
+func FormatPullProgress(progress PullProgress) string {
+	if progress.Layer == "" {
+		return progress.Status
+	}
+	line := fmt.Sprintf("%v: %v", progress.Layer, progress.Status)
+	if progress.Total > 0 {
+		line += fmt.Sprintf(" (%d%% of %v)", progress.Percent, progress.Image)
+	}
+	return line
+}
@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const pullStream = `{"status":"Pulling from eclipse/codewind-pfe-amd64","id":"latest"}
+{"status":"Already exists","progressDetail":{},"id":"aaa"}
+{"status":"Pulling fs layer","progressDetail":{},"id":"bbb"}
+{"status":"Downloading","progressDetail":{"current":50,"total":200},"id":"bbb"}
+{"status":"Downloading","progressDetail":{"current":50,"total":200},"id":"bbb"}
+{"status":"Downloading","progressDetail":{"current":150,"total":200},"id":"bbb"}
+{"status":"Download complete","progressDetail":{},"id":"bbb"}
+{"status":"Pull complete","progressDetail":{},"id":"bbb"}
+{"status":"Digest: sha256:abc"}
+{"status":"Status: Downloaded newer image for eclipse/codewind-pfe-amd64:latest"}
+`
+
+func TestReadPullProgress(t *testing.T) {
+	t.Run("reports layers changing state and the image percentage growing", func(t *testing.T) {
+		reports := []PullProgress{}
+		err := ReadPullProgress(strings.NewReader(pullStream), "pfe", func(progress PullProgress) {
+			reports = append(reports, progress)
+		})
+		assert.Nil(t, err)
+
+		summary := []string{}
+		for _, report := range reports {
+			summary = append(summary, FormatPullProgress(report))
+		}
+		assert.Equal(t, []string{
+			"Pulling from eclipse/codewind-pfe-amd64",
+			"aaa: Already exists",
+			"bbb: Pulling fs layer",
+			"bbb: Downloading (25% of pfe)",
+			"bbb: Downloading (75% of pfe)",
+			"bbb: Download complete (100% of pfe)",
+			"bbb: Pull complete (100% of pfe)",
+			"Digest: sha256:abc",
+			"Status: Downloaded newer image for eclipse/codewind-pfe-amd64:latest",
+		}, summary)
+
+		last := reports[len(reports)-1]
+		assert.Equal(t, 100, last.Percent)
+		assert.Equal(t, []LayerProgress{{ID: "aaa", Status: "Already exists"}, {ID: "bbb", Status: "Pull complete", Current: 200, Total: 200}}, last.Layers)
+	})
+
+	t.Run("returns the error Docker reports", func(t *testing.T) {
+		stream := `{"status":"Pulling from eclipse/codewind-pfe-amd64","id":"latest"}
+{"errorDetail":{"message":"manifest unknown"},"error":"manifest unknown"}
+`
+		err := ReadPullProgress(strings.NewReader(stream), "pfe", func(PullProgress) {})
+		assert.EqualError(t, err, "manifest unknown")
+	})
+}
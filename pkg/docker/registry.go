@@ -0,0 +1,91 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// MirrorImage : Returns the reference of a Docker Hub image on a registry mirror, such as mirror.example.com or
+// https://mirror.example.com:5000, or the image itself when there is no mirror
+func MirrorImage(image string, registryMirror string) string {
+	if registryMirror == "" {
+		return image
+	}
+	registryMirror = strings.TrimPrefix(strings.TrimPrefix(registryMirror, "https://"), "http://")
+	return strings.TrimSuffix(registryMirror, "/") + "/" + strings.TrimPrefix(image, "docker.io/")
+}
+
+// unreachableErrors are the messages Docker gives when it cannot connect to a registry at all
+var unreachableErrors = []string{
+	"dial tcp",
+	"no such host",
+	"i/o timeout",
+	"connection refused",
+	"Client.Timeout exceeded",
+	"TLS handshake timeout",
+	"proxyconnect",
+	"network is unreachable",
+}
+
+// registryUnreachable reports whether a pull failed because the Docker daemon could not connect to the registry
+func registryUnreachable(err error) bool {
+	for _, message := range unreachableErrors {
+		if strings.Contains(err.Error(), message) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientProxy returns the proxy cwctl is run with, from the standard environment variables
+func clientProxy() string {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		if proxy := os.Getenv(name); proxy != "" {
+			return proxy
+		}
+	}
+	return ""
+}
+
+// proxyHint explains how to get a pull through to the registry. The Docker daemon pulls images itself, so it does
+// not see the proxy of cwctl, and needs its own.
+func proxyHint(clientProxy string, daemonProxy string) string {
+	switch {
+	case daemonProxy != "":
+		return "The Docker daemon is using the proxy " + daemonProxy + ", check it can reach the registry, or use --registry-mirror to pull from a registry mirror"
+	case clientProxy != "":
+		return "The Docker daemon pulls images itself and is not configured with the proxy " + clientProxy + " cwctl is using. Configure the Docker daemon proxy, or use --registry-mirror to pull from a registry mirror"
+	}
+	return "If this machine needs a proxy to reach the registry, configure the Docker daemon proxy, or use --registry-mirror to pull from a registry mirror"
+}
+
+// pullError returns the error for a failed pull, explaining what to do when the registry could not be reached
+func pullError(dockerClient DockerClient, image string, err error) *DockerError {
+	if !registryUnreachable(err) {
+		return &DockerError{errOpImagePull, err, err.Error()}
+	}
+	daemonProxy := ""
+	info, infoErr := dockerClient.Info(context.Background())
+	if infoErr == nil {
+		daemonProxy = info.HTTPSProxy
+		if daemonProxy == "" {
+			daemonProxy = info.HTTPProxy
+		}
+	}
+	pullErr := i18n.Errorf(msgRegistryUnreachable, textRegistryUnreachable+" %s: %s. %s", image, err.Error(), proxyHint(clientProxy(), daemonProxy))
+	return &DockerError{errOpRegistryUnreachable, pullErr, pullErr.Error()}
+}
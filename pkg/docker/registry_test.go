@@ -0,0 +1,64 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMirrorImage(t *testing.T) {
+	image := "docker.io/eclipse/codewind-pfe-amd64:latest"
+	assert.Equal(t, image, MirrorImage(image, ""))
+	assert.Equal(t, "mirror.example.com/eclipse/codewind-pfe-amd64:latest", MirrorImage(image, "mirror.example.com"))
+	assert.Equal(t, "mirror.example.com:5000/eclipse/codewind-pfe-amd64:latest", MirrorImage(image, "https://mirror.example.com:5000/"))
+	assert.Equal(t, "mirror.example.com/eclipse/codewind-pfe-amd64:latest", MirrorImage("eclipse/codewind-pfe-amd64:latest", "mirror.example.com"))
+}
+
+func TestPullError(t *testing.T) {
+	for _, name := range []string{"HTTPS_PROXY", "https_proxy", "HTTP_PROXY", "http_proxy"} {
+		value, set := os.LookupEnv(name)
+		os.Unsetenv(name)
+		if set {
+			defer os.Setenv(name, value)
+		}
+	}
+	unreachable := errors.New("Get https://registry-1.docker.io/v2/: dial tcp: lookup registry-1.docker.io: no such host")
+
+	t.Run("returns the error as it is when the registry was reached", func(t *testing.T) {
+		err := pullError(&MockDockerClientWithCw{}, "eclipse/codewind-pfe-amd64:latest", errImagePull)
+		assert.Equal(t, &DockerError{errOpImagePull, errImagePull, errImagePull.Error()}, err)
+	})
+
+	t.Run("explains how to reach the registry when it could not be reached", func(t *testing.T) {
+		err := pullError(&MockDockerClientWithCw{}, "eclipse/codewind-pfe-amd64:latest", unreachable)
+		assert.Equal(t, errOpRegistryUnreachable, err.Op)
+		assert.Contains(t, err.Desc, "no such host")
+		assert.Contains(t, err.Desc, "--registry-mirror")
+	})
+
+	t.Run("names the proxy of cwctl the Docker daemon is not using", func(t *testing.T) {
+		os.Setenv("HTTPS_PROXY", "http://proxy.example.com:3128")
+		defer os.Unsetenv("HTTPS_PROXY")
+		err := pullError(&MockDockerClientWithCw{}, "eclipse/codewind-pfe-amd64:latest", unreachable)
+		assert.Contains(t, err.Desc, "not configured with the proxy http://proxy.example.com:3128")
+	})
+}
+
+func TestProxyHint(t *testing.T) {
+	assert.Contains(t, proxyHint("", "http://daemon-proxy:3128"), "The Docker daemon is using the proxy http://daemon-proxy:3128")
+	assert.Contains(t, proxyHint("http://proxy:3128", ""), "not configured with the proxy http://proxy:3128")
+	assert.Contains(t, proxyHint("", ""), "If this machine needs a proxy")
+}
@@ -0,0 +1,202 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+const (
+	// tlsDirectory is the directory in the Codewind directory the certificate and key are kept in
+	tlsDirectory    = "tls"
+	certificateFile = "cert.pem"
+	keyFile         = "key.pem"
+	// containerTLSDirectory is where the certificate and key are mounted in the containers
+	containerTLSDirectory = "/codewind-tls"
+	// certificateLifetime is how long a generated certificate is valid for, and certificateRenewal how long before
+	// it expires it is replaced
+	certificateLifetime = 365 * 24 * time.Hour
+	certificateRenewal  = 30 * 24 * time.Hour
+)
+
+// TLSDirectory : Returns the directory the certificate for local Codewind is kept in
+func TLSDirectory(codewindDir string) string {
+	return filepath.Join(codewindDir, tlsDirectory)
+}
+
+// CertificatePath : Returns the path of the certificate local Codewind serves HTTPS with
+func CertificatePath(codewindDir string) string {
+	return filepath.Join(TLSDirectory(codewindDir), certificateFile)
+}
+
+// GenerateCertificate : Creates a self-signed certificate and key for localhost in the Codewind directory, returning
+// the path of the certificate. A certificate generated earlier is kept until it is close to expiring, so it only
+// needs to be trusted once.
+func GenerateCertificate(codewindDir string) (string, *DockerError) {
+	certPath := CertificatePath(codewindDir)
+	keyPath := filepath.Join(TLSDirectory(codewindDir), keyFile)
+	if _, err := os.Stat(keyPath); err == nil && certificateValid(certPath, time.Now()) {
+		return certPath, nil
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return "", &DockerError{errOpCertificate, err, err.Error()}
+	}
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return "", &DockerError{errOpCertificate, err, err.Error()}
+	}
+	// The certificate is a self-signed leaf that cannot sign other certificates. Its key is mounted into containers
+	// that build user code, so trusting it must not let anyone who reads the key issue certificates for other hosts.
+	notBefore := time.Now().Add(-time.Hour)
+	template := x509.Certificate{
+		SerialNumber:          serialNumber,
+		Subject:               pkix.Name{Organization: []string{"Codewind"}, CommonName: "localhost"},
+		NotBefore:             notBefore,
+		NotAfter:              notBefore.Add(certificateLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  false,
+		DNSNames:              []string{"localhost"},
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1"), net.IPv6loopback},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return "", &DockerError{errOpCertificate, err, err.Error()}
+	}
+	keyBytes, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", &DockerError{errOpCertificate, err, err.Error()}
+	}
+
+	err = os.MkdirAll(TLSDirectory(codewindDir), 0700)
+	if err == nil {
+		err = ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyBytes}), 0600)
+	}
+	if err == nil {
+		err = ioutil.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644)
+	}
+	if err != nil {
+		return "", &DockerError{errOpCertificate, err, err.Error()}
+	}
+	return certPath, nil
+}
+
+// certificateValid reports whether a certificate can be read and is not close to expiring. A certificate that can sign
+// others, as earlier versions generated, is replaced.
+func certificateValid(certPath string, now time.Time) bool {
+	certificate, err := readCertificate(certPath)
+	if err != nil || certificate.IsCA || certificate.KeyUsage&x509.KeyUsageCertSign != 0 {
+		return false
+	}
+	return now.After(certificate.NotBefore) && now.Add(certificateRenewal).Before(certificate.NotAfter)
+}
+
+// readCertificate parses the first certificate in a PEM file
+func readCertificate(certPath string) (*x509.Certificate, error) {
+	contents, err := ioutil.ReadFile(certPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(contents)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, errors.New(certPath + " does not contain a PEM encoded certificate")
+	}
+	return x509.ParseCertificate(block.Bytes)
+}
+
+// trustCommands returns the commands that add a certificate to the trusted roots of an operating system
+func trustCommands(goos string, certPath string) [][]string {
+	switch goos {
+	case "darwin":
+		keychain := filepath.Join(homeDir, "Library", "Keychains", "login.keychain")
+		return [][]string{{"security", "add-trusted-cert", "-r", "trustRoot", "-k", keychain, certPath}}
+	case "linux":
+		return [][]string{
+			{"sudo", "cp", certPath, "/usr/local/share/ca-certificates/codewind-local.crt"},
+			{"sudo", "update-ca-certificates"},
+		}
+	case "windows":
+		return [][]string{{"certutil", "-user", "-addstore", "Root", certPath}}
+	}
+	return nil
+}
+
+// TrustCertificate : Adds a certificate to the trusted roots of the operating system, so that browsers and other
+// tools accept it. The commands may ask for a password to change the trusted roots.
+func TrustCertificate(certPath string) *DockerError {
+	commands := trustCommands(runtime.GOOS, certPath)
+	if commands == nil {
+		err := i18n.Errorf(msgTrustUnsupported, textTrustUnsupported+": %s", certPath)
+		return &DockerError{errOpCertificateTrust, err, err.Error()}
+	}
+	for _, command := range commands {
+		if globals.NonInteractive && command[0] == "sudo" {
+			// Fail instead of asking for a password
+			command = append([]string{"sudo", "-n"}, command[1:]...)
+		}
+		cmd := exec.Command(command[0], command[1:]...)
+		if !globals.NonInteractive {
+			cmd.Stdin = os.Stdin
+		}
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			return &DockerError{errOpCertificateTrust, err, err.Error()}
+		}
+	}
+	return nil
+}
+
+// LocalHTTPClient : Returns an HTTP client for local Codewind, trusting the generated certificate in addition to the
+// system roots when Codewind has been started with TLS
+func LocalHTTPClient(codewindDir string, timeout time.Duration) (*http.Client, *DockerError) {
+	client := &http.Client{Timeout: timeout}
+	certificate, err := readCertificate(CertificatePath(codewindDir))
+	if os.IsNotExist(err) {
+		return client, nil
+	}
+	if err != nil {
+		return nil, &DockerError{errOpCertificate, err, err.Error()}
+	}
+	roots, err := x509.SystemCertPool()
+	if err != nil {
+		roots = x509.NewCertPool()
+	}
+	roots.AddCert(certificate)
+	client.Transport = &http.Transport{
+		Proxy:           http.ProxyFromEnvironment,
+		TLSClientConfig: &tls.Config{RootCAs: roots},
+	}
+	return client, nil
+}
@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateCertificate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("creates a certificate for localhost", func(t *testing.T) {
+		certPath, dockerErr := GenerateCertificate(dir)
+		assert.Nil(t, dockerErr)
+		assert.Equal(t, CertificatePath(dir), certPath)
+
+		certificate, err := readCertificate(certPath)
+		assert.Nil(t, err)
+		assert.Nil(t, certificate.VerifyHostname("localhost"))
+		assert.Nil(t, certificate.VerifyHostname("127.0.0.1"))
+		assert.False(t, certificate.IsCA)
+		assert.Zero(t, certificate.KeyUsage&x509.KeyUsageCertSign)
+		info, err := os.Stat(filepath.Join(dir, tlsDirectory, keyFile))
+		assert.Nil(t, err)
+		if os.PathSeparator == '/' {
+			assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+		}
+	})
+
+	t.Run("keeps the certificate until it is close to expiring", func(t *testing.T) {
+		before, _ := ioutil.ReadFile(CertificatePath(dir))
+		_, dockerErr := GenerateCertificate(dir)
+		assert.Nil(t, dockerErr)
+		after, _ := ioutil.ReadFile(CertificatePath(dir))
+		assert.Equal(t, before, after)
+
+		assert.True(t, certificateValid(CertificatePath(dir), time.Now()))
+		assert.False(t, certificateValid(CertificatePath(dir), time.Now().Add(certificateLifetime-certificateRenewal)))
+	})
+
+	t.Run("replaces a certificate that can sign other certificates", func(t *testing.T) {
+		writeCACertificate(t, dir)
+		assert.False(t, certificateValid(CertificatePath(dir), time.Now()))
+		certPath, dockerErr := GenerateCertificate(dir)
+		assert.Nil(t, dockerErr)
+		certificate, err := readCertificate(certPath)
+		assert.Nil(t, err)
+		assert.False(t, certificate.IsCA)
+	})
+
+	t.Run("replaces a certificate that cannot be read", func(t *testing.T) {
+		assert.Nil(t, ioutil.WriteFile(CertificatePath(dir), []byte("not a certificate"), 0644))
+		certPath, dockerErr := GenerateCertificate(dir)
+		assert.Nil(t, dockerErr)
+		assert.True(t, certificateValid(certPath, time.Now()))
+	})
+}
+
+// writeCACertificate writes a self-signed CA certificate for localhost, as earlier versions generated
+func writeCACertificate(t *testing.T, dir string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.Nil(t, err)
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(certificateLifetime),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+		DNSNames:              []string{"localhost"},
+	}
+	certBytes, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	assert.Nil(t, err)
+	assert.Nil(t, ioutil.WriteFile(CertificatePath(dir), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certBytes}), 0644))
+}
+
+func TestTrustCommands(t *testing.T) {
+	assert.Equal(t, [][]string{{"certutil", "-user", "-addstore", "Root", "cert.pem"}}, trustCommands("windows", "cert.pem"))
+	assert.Equal(t, "update-ca-certificates", trustCommands("linux", "cert.pem")[1][1])
+	assert.Equal(t, "add-trusted-cert", trustCommands("darwin", "cert.pem")[0][1])
+	assert.Nil(t, trustCommands("plan9", "cert.pem"))
+}
+
+func TestLocalHTTPClient(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("is a plain client before a certificate is generated", func(t *testing.T) {
+		client, dockerErr := LocalHTTPClient(dir, time.Second)
+		assert.Nil(t, dockerErr)
+		assert.Nil(t, client.Transport)
+	})
+
+	t.Run("trusts the generated certificate", func(t *testing.T) {
+		certPath, dockerErr := GenerateCertificate(dir)
+		assert.Nil(t, dockerErr)
+		keyPair, err := tls.LoadX509KeyPair(certPath, filepath.Join(dir, tlsDirectory, keyFile))
+		assert.Nil(t, err)
+		server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.TLS = &tls.Config{Certificates: []tls.Certificate{keyPair}}
+		server.StartTLS()
+		defer server.Close()
+
+		client, dockerErr := LocalHTTPClient(dir, time.Second)
+		assert.Nil(t, dockerErr)
+		resp, err := client.Get(server.URL)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		resp.Body.Close()
+	})
+}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// AllTags selects every tag of the Codewind images for removal
+const AllTags = "all"
+
+// workspaceVolume is the volume Compose creates for the Codewind workspace
+const workspaceVolume = composeProjectName + "_cw-workspace"
+
+type (
+	// RemovalOptions : What to remove along with the local Codewind containers
+	RemovalOptions struct {
+		// Tags of the Codewind images to remove, or AllTags
+		Tags []string
+		// Volumes removes the codewind-workspace volume
+		Volumes bool
+		// CodewindDir is purged when set
+		CodewindDir string
+	}
+
+	// RemovalTarget : An image, volume, container or path a local removal deletes
+	RemovalTarget struct {
+		Kind string `json:"kind"`
+		Name string `json:"name"`
+		ID   string `json:"id,omitempty"`
+	}
+)
+
+// Kinds of RemovalTarget
+const (
+	TargetContainer = "container"
+	TargetImage     = "image"
+	TargetVolume    = "volume"
+	TargetPath      = "path"
+)
+
+// PlanLocalRemoval : Lists everything a local removal deletes: the Codewind containers, project images, the Codewind
+// images of the given tags, and the workspace volume and Codewind directory when asked for
+func PlanLocalRemoval(dockerClient DockerClient, options RemovalOptions) ([]RemovalTarget, *DockerError) {
+	targets := []RemovalTarget{}
+
+	containers, dockerErr := GetContainerListWithOptions(dockerClient, types.ContainerListOptions{All: true})
+	if dockerErr != nil {
+		return nil, dockerErr
+	}
+	for _, container := range containers {
+		for _, name := range LocalCWContainerNames {
+			if len(container.Names) > 0 && container.Names[0] == "/"+name {
+				targets = append(targets, RemovalTarget{Kind: TargetContainer, Name: name, ID: container.ID})
+			}
+		}
+	}
+
+	images, dockerErr := GetImageList(dockerClient)
+	if dockerErr != nil {
+		return nil, dockerErr
+	}
+	for _, image := range images {
+		if name, ok := removableImage(image, options.Tags); ok {
+			targets = append(targets, RemovalTarget{Kind: TargetImage, Name: name, ID: image.ID})
+		}
+	}
+
+	if options.Volumes {
+		volumes, err := dockerClient.VolumeList(context.Background(), filters.NewArgs(filters.Arg("name", workspaceVolume)))
+		if err != nil {
+			return nil, &DockerError{errOpVolumeList, err, err.Error()}
+		}
+		for _, volume := range volumes.Volumes {
+			// The name filter matches substrings, so check for the volume itself
+			if volume.Name == workspaceVolume {
+				targets = append(targets, RemovalTarget{Kind: TargetVolume, Name: volume.Name})
+			}
+		}
+	}
+
+	if options.CodewindDir != "" {
+		if _, err := os.Stat(options.CodewindDir); err == nil {
+			targets = append(targets, RemovalTarget{Kind: TargetPath, Name: options.CodewindDir})
+		}
+	}
+	return targets, nil
+}
+
+// removableImage returns the name of an image a removal deletes: a project image, or a Codewind image of one of the
+// tags
+func removableImage(image types.ImageSummary, tags []string) (string, bool) {
+	for _, repoTag := range image.RepoTags {
+		if strings.HasPrefix(repoTag, "cw-") {
+			return repoTag, true
+		}
+		name := strings.TrimPrefix(repoTag, "docker.io/")
+		if !strings.HasPrefix(name, pfeImageName) && !strings.HasPrefix(name, performanceImageName) {
+			continue
+		}
+		for _, tag := range tags {
+			if tag == AllTags || tag == utils.ImageTag(name) {
+				return repoTag, true
+			}
+		}
+	}
+	// Project images built without a tag are only known by their digest
+	if len(image.RepoTags) == 0 && strings.HasPrefix(strings.Join(image.RepoDigests, " "), "cw-") {
+		return image.ID, true
+	}
+	return "", false
+}
+
+// RemoveVolume : Deletes a volume, which must not be in use by a container
+func RemoveVolume(dockerClient DockerClient, name string) *DockerError {
+	err := dockerClient.VolumeRemove(context.Background(), name, false)
+	if err != nil {
+		return &DockerError{errOpVolumeRemove, err, err.Error()}
+	}
+	return nil
+}
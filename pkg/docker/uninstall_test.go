@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package docker
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockRemovalClient lists Codewind images of two versions alongside project and other images
+type mockRemovalClient struct {
+	MockDockerClientWithCw
+}
+
+func (m *mockRemovalClient) ImageList(ctx context.Context, imageListOptions types.ImageListOptions) ([]types.ImageSummary, error) {
+	return []types.ImageSummary{
+		{ID: "pfe-old", RepoTags: []string{"eclipse/codewind-pfe-amd64:0.8.0"}},
+		{ID: "pfe-new", RepoTags: []string{"eclipse/codewind-pfe-amd64:0.9.0"}},
+		{ID: "performance-new", RepoTags: []string{"eclipse/codewind-performance-amd64:0.9.0"}},
+		{ID: "project", RepoTags: []string{"cw-nodeproject-1234:latest"}},
+		{ID: "golang", RepoTags: []string{"golang:1.12"}},
+	}, nil
+}
+
+func targetNames(targets []RemovalTarget) []string {
+	names := []string{}
+	for _, target := range targets {
+		names = append(names, target.Kind+" "+target.Name)
+	}
+	return names
+}
+
+func TestPlanLocalRemoval(t *testing.T) {
+	t.Run("targets the containers, project images and Codewind images of a tag", func(t *testing.T) {
+		targets, err := PlanLocalRemoval(&mockRemovalClient{}, RemovalOptions{Tags: []string{"0.8.0"}})
+		assert.Nil(t, err)
+		assert.Equal(t, []string{
+			"container codewind-pfe",
+			"container codewind-performance",
+			"image eclipse/codewind-pfe-amd64:0.8.0",
+			"image cw-nodeproject-1234:latest",
+		}, targetNames(targets))
+	})
+
+	t.Run("targets every tag, the workspace volume and the Codewind directory", func(t *testing.T) {
+		dir, err := ioutil.TempDir("", "codewind")
+		assert.Nil(t, err)
+		defer os.RemoveAll(dir)
+
+		targets, dockerErr := PlanLocalRemoval(&mockRemovalClient{}, RemovalOptions{Tags: []string{AllTags}, Volumes: true, CodewindDir: dir})
+		assert.Nil(t, dockerErr)
+		assert.Equal(t, []string{
+			"container codewind-pfe",
+			"container codewind-performance",
+			"image eclipse/codewind-pfe-amd64:0.8.0",
+			"image eclipse/codewind-pfe-amd64:0.9.0",
+			"image eclipse/codewind-performance-amd64:0.9.0",
+			"image cw-nodeproject-1234:latest",
+			"volume codewind_cw-workspace",
+			"path " + dir,
+		}, targetNames(targets))
+	})
+
+	t.Run("skips a Codewind directory that does not exist", func(t *testing.T) {
+		targets, err := PlanLocalRemoval(&mockDockerClientWithoutCw{}, RemovalOptions{Volumes: true, CodewindDir: "/does/not/exist"})
+		assert.Nil(t, err)
+		assert.Empty(t, targets)
+	})
+
+	t.Run("returns DockerError when docker ContainerList errors", func(t *testing.T) {
+		_, err := PlanLocalRemoval(&MockDockerErrorClient{}, RemovalOptions{})
+		assert.Equal(t, ErrOpContainerList, err.Op)
+	})
+}
+
+func TestRemoveVolume(t *testing.T) {
+	assert.Nil(t, RemoveVolume(&MockDockerClientWithCw{}, workspaceVolume))
+	err := RemoveVolume(&MockDockerErrorClient{}, workspaceVolume)
+	assert.Equal(t, errOpVolumeRemove, err.Op)
+}
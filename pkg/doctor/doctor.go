@@ -0,0 +1,261 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/docker/docker/api/types/versions"
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/remote/kube"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// Results of a check
+const (
+	Pass = "pass"
+	Warn = "warn"
+	Fail = "fail"
+)
+
+const (
+	// minDockerAPIVersion is the API version cwctl talks to Docker with, first served by Docker 17.06
+	minDockerAPIVersion = "1.30"
+	// Free disk space below which pulling the Codewind images and building projects is likely to fail
+	minFreeDiskSpace  = 2 << 30
+	warnFreeDiskSpace = 10 << 30
+)
+
+// Remediation hints printed with checks that did not pass
+const (
+	hintInstallDocker    = "Install Docker Desktop, or Docker Engine on Linux, and check DOCKER_HOST if it is set"
+	hintStartDocker      = "Start Docker, and on Linux check your user is in the docker group"
+	hintUpgradeDocker    = "Upgrade Docker to 17.06 or later"
+	hintPodman           = "Codewind needs the Docker API: run `podman system service` and point DOCKER_HOST at its socket, or install Docker"
+	hintInstallCompose   = "Install the docker compose plugin, or docker-compose"
+	hintDiskSpace        = "Free up space, for example with `docker system prune` or `cwctl remove --images all`"
+	hintPorts            = "Stop the processes using ports 10000-11000, or choose a port with `cwctl start --pfe-port`"
+	hintKubeConfig       = "Fix the kubeconfig file, or point KUBECONFIG at a valid one"
+	hintKubeContext      = "Choose a context with `kubectl config use-context`"
+	hintInstallKubectl   = "Install kubectl to manage remote deployments"
+	hintStartLocal       = "Start Codewind with `cwctl start`"
+	hintRemoteConnection = "Check the deployment with `cwctl remote status`, or correct the URL with `cwctl connections update`"
+)
+
+type (
+	// Check : The result of one prerequisite check, with a hint on fixing it when it did not pass
+	Check struct {
+		Name   string `json:"name"`
+		Result string `json:"result"`
+		Detail string `json:"detail"`
+		Hint   string `json:"hint,omitempty"`
+	}
+
+	// Options : What the checks run against
+	Options struct {
+		CodewindDir string
+		Connections []connections.Connection
+		HTTPClient  utils.HTTPClient
+	}
+)
+
+// Functions the checks call, replaced in tests
+var (
+	newDockerClient = docker.NewDockerClient
+	findComposeCLI  = docker.FindComposeCLI
+	choosePFEPort   = docker.ChoosePFEPort
+	lookPath        = exec.LookPath
+	freeDiskSpace   = diskFree
+	loadKubeConfig  = func() (clientcmdapi.Config, error) {
+		return kube.GetKubeClientConfig().RawConfig()
+	}
+	pfeVersion = func(connection connections.Connection, httpClient utils.HTTPClient) (string, error) {
+		conURL, conErr := config.PFEOriginFromConnection(&connection)
+		if conErr != nil {
+			return "", conErr
+		}
+		return apiroutes.GetPFEVersionFromConnection(&connection, conURL, httpClient)
+	}
+)
+
+// Run : Checks everything Codewind needs, locally and for each connection, in the order they are printed
+func Run(options Options) []Check {
+	checks := []Check{}
+	dockerClient, dockerErr := newDockerClient()
+	if dockerErr == nil {
+		checks = append(checks, checkContainerEngine(dockerClient))
+	} else {
+		checks = append(checks, Check{"container engine", Fail, dockerErr.Desc, hintInstallDocker})
+	}
+	checks = append(checks, checkCompose())
+	checks = append(checks, checkDiskSpace(options.CodewindDir))
+	if checks[0].Result != Fail {
+		checks = append(checks, checkPorts(dockerClient, options.CodewindDir))
+	}
+	checks = append(checks, checkKubeContext())
+	for _, connection := range options.Connections {
+		checks = append(checks, checkConnection(connection, options.HTTPClient))
+	}
+	return checks
+}
+
+// Failed : Reports whether any check failed
+func Failed(checks []Check) bool {
+	for _, check := range checks {
+		if check.Result == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// checkContainerEngine checks that the Docker daemon answers and is recent enough, warning about Podman, whose
+// Docker compatible API PFE cannot build projects through
+func checkContainerEngine(dockerClient docker.DockerClient) Check {
+	check := Check{Name: "container engine"}
+	version, err := dockerClient.ServerVersion(context.Background())
+	if err != nil {
+		check.Result, check.Detail, check.Hint = Fail, err.Error(), hintStartDocker
+		if _, podmanErr := lookPath("podman"); podmanErr == nil {
+			check.Hint = hintPodman
+		}
+		return check
+	}
+	check.Detail = "Docker " + version.Version + " (API " + version.APIVersion + ")"
+	if strings.Contains(strings.ToLower(version.Platform.Name), "podman") {
+		check.Result, check.Detail, check.Hint = Warn, version.Platform.Name+" "+version.Version, hintPodman
+		return check
+	}
+	if versions.LessThan(version.APIVersion, minDockerAPIVersion) {
+		check.Result, check.Hint = Fail, hintUpgradeDocker
+		return check
+	}
+	check.Result = Pass
+	return check
+}
+
+// checkCompose checks that the Compose plugin or docker-compose is installed
+func checkCompose() Check {
+	compose, dockerErr := findComposeCLI()
+	if dockerErr != nil {
+		return Check{"docker compose", Fail, dockerErr.Desc, hintInstallCompose}
+	}
+	return Check{"docker compose", Pass, strings.Join(compose.Command, " ") + " v" + strconv.Itoa(compose.Version), ""}
+}
+
+// checkDiskSpace checks there is room for the Codewind images and project builds, on the filesystem the Codewind
+// directory is, or will be, created on
+func checkDiskSpace(dir string) Check {
+	for _, err := os.Stat(dir); os.IsNotExist(err) && filepath.Dir(dir) != dir; _, err = os.Stat(dir) {
+		dir = filepath.Dir(dir)
+	}
+	free, err := freeDiskSpace(dir)
+	if err != nil {
+		return Check{"disk space", Warn, err.Error(), ""}
+	}
+	detail := fmt.Sprintf("%.1f GB free in %s", float64(free)/(1<<30), dir)
+	switch {
+	case free < minFreeDiskSpace:
+		return Check{"disk space", Fail, detail, hintDiskSpace}
+	case free < warnFreeDiskSpace:
+		return Check{"disk space", Warn, detail, hintDiskSpace}
+	}
+	return Check{"disk space", Pass, detail, ""}
+}
+
+// checkPorts checks that PFE has a port to be published on, which is only a concern while Codewind is not running
+func checkPorts(dockerClient docker.DockerClient, codewindDir string) Check {
+	if pfeURL, _ := docker.GetPFEURL(dockerClient); pfeURL != "" {
+		return Check{"ports", Pass, "Codewind is running at " + pfeURL, ""}
+	}
+	mapping, dockerErr := docker.LoadPortMapping(codewindDir)
+	if dockerErr != nil {
+		return Check{"ports", Warn, dockerErr.Desc, ""}
+	}
+	port, dockerErr := choosePFEPort(0, mapping.PFE)
+	switch {
+	case dockerErr != nil:
+		return Check{"ports", Fail, dockerErr.Desc, hintPorts}
+	case port == 0:
+		return Check{"ports", Warn, "Every port in the Codewind range is in use, Docker will assign one", hintPorts}
+	case mapping.PFE != 0 && port != mapping.PFE:
+		return Check{"ports", Warn, "Port " + strconv.Itoa(mapping.PFE) + " used last time is in use, PFE will move to " + strconv.Itoa(port), hintPorts}
+	}
+	return Check{"ports", Pass, "PFE will be published on port " + strconv.Itoa(port), ""}
+}
+
+// checkKubeContext checks that the current Kubernetes context refers to a cluster and user that exist. Kubernetes
+// is only needed for remote deployments, so a missing configuration is a warning.
+func checkKubeContext() Check {
+	check := Check{Name: "kubectl context"}
+	kubeConfig, err := loadKubeConfig()
+	if err != nil {
+		check.Result, check.Detail, check.Hint = Fail, err.Error(), hintKubeConfig
+		return check
+	}
+	if kubeConfig.CurrentContext == "" {
+		check.Result, check.Detail, check.Hint = Warn, "No current context, needed only for remote deployments", hintKubeContext
+		return check
+	}
+	context, ok := kubeConfig.Contexts[kubeConfig.CurrentContext]
+	if !ok {
+		check.Result, check.Detail, check.Hint = Fail, "Current context "+kubeConfig.CurrentContext+" is not defined", hintKubeContext
+		return check
+	}
+	if _, ok := kubeConfig.Clusters[context.Cluster]; !ok {
+		check.Result, check.Detail, check.Hint = Fail, "Cluster "+context.Cluster+" of context "+kubeConfig.CurrentContext+" is not defined", hintKubeConfig
+		return check
+	}
+	if _, ok := kubeConfig.AuthInfos[context.AuthInfo]; !ok && context.AuthInfo != "" {
+		check.Result, check.Detail, check.Hint = Fail, "User "+context.AuthInfo+" of context "+kubeConfig.CurrentContext+" is not defined", hintKubeConfig
+		return check
+	}
+	check.Result, check.Detail = Pass, "Context "+kubeConfig.CurrentContext+" on cluster "+context.Cluster
+	if context.Namespace != "" {
+		check.Detail += ", namespace " + context.Namespace
+	}
+	if _, err := lookPath("kubectl"); err != nil {
+		check.Result, check.Hint = Warn, hintInstallKubectl
+		check.Detail += ", kubectl is not installed"
+	}
+	return check
+}
+
+// checkConnection checks that Codewind answers on a connection. The local connection not answering is a warning, as
+// Codewind may not have been started yet.
+func checkConnection(connection connections.Connection, httpClient utils.HTTPClient) Check {
+	check := Check{Name: "connection " + connection.ID}
+	version, err := pfeVersion(connection, httpClient)
+	if err == nil && version != "" {
+		check.Result, check.Detail = Pass, "Codewind "+version+" at "+connection.URL
+		return check
+	}
+	if connection.ID == "local" {
+		check.Result, check.Detail, check.Hint = Warn, "Codewind is not running", hintStartLocal
+		return check
+	}
+	check.Result, check.Detail, check.Hint = Fail, "Codewind is not responding at "+connection.URL, hintRemoteConnection
+	if err != nil {
+		check.Detail += ": " + err.Error()
+	}
+	return check
+}
@@ -0,0 +1,191 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package doctor
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/docker"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// versionClient is a Docker client reporting a server version
+type versionClient struct {
+	docker.DockerClient
+	version types.Version
+	err     error
+}
+
+func (m *versionClient) ServerVersion(ctx context.Context) (types.Version, error) {
+	return m.version, m.err
+}
+
+func TestCheckContainerEngine(t *testing.T) {
+	originalLookPath := lookPath
+	defer func() { lookPath = originalLookPath }()
+	lookPath = func(file string) (string, error) { return "", errors.New("not found") }
+
+	t.Run("passes for a recent Docker", func(t *testing.T) {
+		check := checkContainerEngine(&versionClient{version: types.Version{Version: "19.03.5", APIVersion: "1.40"}})
+		assert.Equal(t, Pass, check.Result)
+		assert.Equal(t, "Docker 19.03.5 (API 1.40)", check.Detail)
+	})
+
+	t.Run("fails for a Docker older than the API cwctl uses", func(t *testing.T) {
+		check := checkContainerEngine(&versionClient{version: types.Version{Version: "17.03.2", APIVersion: "1.27"}})
+		assert.Equal(t, Fail, check.Result)
+		assert.Equal(t, hintUpgradeDocker, check.Hint)
+	})
+
+	t.Run("warns about Podman", func(t *testing.T) {
+		version := types.Version{Version: "1.8.0", APIVersion: "1.40"}
+		version.Platform.Name = "Podman Engine"
+		check := checkContainerEngine(&versionClient{version: version})
+		assert.Equal(t, Warn, check.Result)
+		assert.Equal(t, hintPodman, check.Hint)
+	})
+
+	t.Run("fails when Docker is not running", func(t *testing.T) {
+		check := checkContainerEngine(&versionClient{err: errors.New("Cannot connect to the Docker daemon")})
+		assert.Equal(t, Fail, check.Result)
+		assert.Equal(t, hintStartDocker, check.Hint)
+
+		lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+		check = checkContainerEngine(&versionClient{err: errors.New("Cannot connect to the Docker daemon")})
+		assert.Equal(t, hintPodman, check.Hint)
+	})
+}
+
+func TestCheckCompose(t *testing.T) {
+	originalFindComposeCLI := findComposeCLI
+	defer func() { findComposeCLI = originalFindComposeCLI }()
+
+	findComposeCLI = func() (*docker.ComposeCLI, *docker.DockerError) {
+		return &docker.ComposeCLI{Command: []string{"docker", "compose"}, Version: 2}, nil
+	}
+	assert.Equal(t, Check{"docker compose", Pass, "docker compose v2", ""}, checkCompose())
+
+	findComposeCLI = func() (*docker.ComposeCLI, *docker.DockerError) {
+		return nil, &docker.DockerError{Op: "docker_compose_not_found", Desc: "Docker Compose not found"}
+	}
+	assert.Equal(t, Fail, checkCompose().Result)
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	originalFreeDiskSpace := freeDiskSpace
+	defer func() { freeDiskSpace = originalFreeDiskSpace }()
+	var checked string
+	free := func(bytes uint64) {
+		freeDiskSpace = func(dir string) (uint64, error) {
+			checked = dir
+			return bytes, nil
+		}
+	}
+
+	free(50 << 30)
+	assert.Equal(t, Pass, checkDiskSpace(os.TempDir()).Result)
+	free(5 << 30)
+	assert.Equal(t, Warn, checkDiskSpace(os.TempDir()).Result)
+	free(1 << 30)
+	assert.Equal(t, Fail, checkDiskSpace(os.TempDir()).Result)
+
+	t.Run("checks the parent of a Codewind directory not yet created", func(t *testing.T) {
+		checkDiskSpace(filepath.Join(os.TempDir(), "doctor-missing", ".codewind"))
+		assert.Equal(t, os.TempDir(), checked)
+	})
+}
+
+func TestCheckKubeContext(t *testing.T) {
+	originalLoadKubeConfig, originalLookPath := loadKubeConfig, lookPath
+	defer func() { loadKubeConfig, lookPath = originalLoadKubeConfig, originalLookPath }()
+	lookPath = func(file string) (string, error) { return "/usr/bin/" + file, nil }
+	kubeConfig := func(config *clientcmdapi.Config) {
+		loadKubeConfig = func() (clientcmdapi.Config, error) { return *config, nil }
+	}
+	validConfig := func() *clientcmdapi.Config {
+		config := clientcmdapi.NewConfig()
+		config.Clusters["cluster"] = &clientcmdapi.Cluster{Server: "https://cluster:6443"}
+		config.AuthInfos["user"] = &clientcmdapi.AuthInfo{Token: "token"}
+		config.Contexts["dev"] = &clientcmdapi.Context{Cluster: "cluster", AuthInfo: "user", Namespace: "codewind"}
+		config.CurrentContext = "dev"
+		return config
+	}
+
+	t.Run("passes for a context whose cluster and user exist", func(t *testing.T) {
+		kubeConfig(validConfig())
+		check := checkKubeContext()
+		assert.Equal(t, Pass, check.Result)
+		assert.Equal(t, "Context dev on cluster cluster, namespace codewind", check.Detail)
+	})
+
+	t.Run("warns when there is no current context", func(t *testing.T) {
+		kubeConfig(clientcmdapi.NewConfig())
+		assert.Equal(t, Warn, checkKubeContext().Result)
+	})
+
+	t.Run("fails when the context refers to a missing cluster", func(t *testing.T) {
+		config := validConfig()
+		delete(config.Clusters, "cluster")
+		kubeConfig(config)
+		check := checkKubeContext()
+		assert.Equal(t, Fail, check.Result)
+		assert.Contains(t, check.Detail, "Cluster cluster")
+	})
+
+	t.Run("fails when the current context is missing", func(t *testing.T) {
+		config := validConfig()
+		config.CurrentContext = "prod"
+		kubeConfig(config)
+		assert.Equal(t, Fail, checkKubeContext().Result)
+	})
+
+	t.Run("warns when kubectl is not installed", func(t *testing.T) {
+		kubeConfig(validConfig())
+		lookPath = func(file string) (string, error) { return "", errors.New("not found") }
+		check := checkKubeContext()
+		assert.Equal(t, Warn, check.Result)
+		assert.Equal(t, hintInstallKubectl, check.Hint)
+	})
+}
+
+func TestCheckConnection(t *testing.T) {
+	originalPFEVersion := pfeVersion
+	defer func() { pfeVersion = originalPFEVersion }()
+	pfeVersion = func(connection connections.Connection, httpClient utils.HTTPClient) (string, error) {
+		if connection.ID == "remote" {
+			return "0.9.0", nil
+		}
+		return "", errors.New("connection refused")
+	}
+
+	check := checkConnection(connections.Connection{ID: "remote", URL: "https://codewind.example.com"}, nil)
+	assert.Equal(t, Pass, check.Result)
+	assert.Equal(t, "connection remote", check.Name)
+
+	check = checkConnection(connections.Connection{ID: "local"}, nil)
+	assert.Equal(t, Warn, check.Result)
+	assert.Equal(t, hintStartLocal, check.Hint)
+
+	check = checkConnection(connections.Connection{ID: "other", URL: "https://gone.example.com"}, nil)
+	assert.Equal(t, Fail, check.Result)
+	assert.Contains(t, check.Detail, "connection refused")
+	assert.True(t, Failed([]Check{{Result: Pass}, check}))
+	assert.False(t, Failed([]Check{{Result: Pass}, {Result: Warn}}))
+}
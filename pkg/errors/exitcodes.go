@@ -0,0 +1,107 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package errors
+
+import "strings"
+
+// Exit codes of cwctl. Scripts and IDEs act on these, so a code must never be reused for a different kind of failure.
+const (
+	ExitOK            = 0   // The command succeeded
+	ExitFailure       = 1   // Any failure without a more specific code
+	ExitUsage         = 2   // Invalid flags, arguments or settings
+	ExitInputRequired = 3   // The command needs input, but prompts are turned off with --non-interactive
+	ExitNotFound      = 4   // A connection, project, deployment, image or secret does not exist
+	ExitAuth          = 5   // Authentication failed, or credentials could not be read or saved
+	ExitNetwork       = 6   // Codewind or a registry could not be reached
+	ExitDocker        = 7   // A Docker or Docker Compose operation failed
+	ExitKubernetes    = 8   // A Kubernetes operation of a remote deployment failed
+	ExitSync          = 9   // Project files could not be synchronized
+	ExitTimeout       = 10  // Waiting for a project or deployment timed out
+	ExitConflict      = 11  // A connection, project or port is already in use
+	ExitBuild         = 12  // A project build failed
+	ExitCancelled     = 130 // The command was interrupted, the code shells report for a command stopped by Ctrl+C
+)
+
+// ErrOpInputRequired : The operation of failures caused by prompts being turned off
+const ErrOpInputRequired = "input_required"
+
+// exitCodes maps operations to their exit codes, taking precedence over exitCodePrefixes
+var exitCodes = map[string]int{
+	ErrOpInputRequired:                  ExitInputRequired,
+	"proj_options_invalid":              ExitUsage,
+	"proj_id_invalid":                   ExitUsage,
+	"sec_cli_options":                   ExitUsage,
+	"config_cli_key":                    ExitUsage,
+	"config_cli_value":                  ExitUsage,
+	"con_not_found":                     ExitNotFound,
+	"connection_notfound":               ExitNotFound,
+	"config_connection_notfound":        ExitNotFound,
+	"proj_notfound":                     ExitNotFound,
+	"rem_not_found":                     ExitNotFound,
+	"sec_notfound":                      ExitNotFound,
+	"sec_keyring_secret_not_found":      ExitNotFound,
+	"IMAGE_NOT_FOUND":                   ExitNotFound,
+	"DOCKER_COMPOSE_NOT_FOUND":          ExitNotFound,
+	"docker_compose_not_found":          ExitNotFound,
+	"tx_auth":                           ExitAuth,
+	"tx_nopassword":                     ExitAuth,
+	"invalid_git_credentials":           ExitAuth,
+	"GET_CREDS_KEYCHAIN_ERROR":          ExitAuth,
+	"sec_connection":                    ExitNetwork,
+	"sec_badhostname":                   ExitNetwork,
+	"con_proxy":                         ExitNetwork,
+	"con_retry":                         ExitNetwork,
+	"con_timeout":                       ExitNetwork,
+	"config_pfe_hostname_port_notfound": ExitNetwork,
+	"REGISTRY_UNREACHABLE_ERROR":        ExitNetwork,
+	"proj_events":                       ExitNetwork,
+	"proj_debug_timeout":                ExitTimeout,
+	"proj_loadtest_timeout":             ExitTimeout,
+	"rem_ready_timeout":                 ExitTimeout,
+	"proj_build_timeout":                ExitTimeout,
+	"proj_build_failed":                 ExitBuild,
+	"tx_cancelled":                      ExitCancelled,
+	"proj_sync_cancelled":               ExitCancelled,
+	"rem_cancelled":                     ExitCancelled,
+	"con_conflict":                      ExitConflict,
+	"proj_conflict":                     ExitConflict,
+	"PORT_IN_USE_ERROR":                 ExitConflict,
+	"daemon_running":                    ExitConflict,
+}
+
+// exitCodePrefixes maps families of operations to their exit codes
+var exitCodePrefixes = []struct {
+	prefix string
+	code   int
+}{
+	{"proj_sync", ExitSync},
+	{"sec_", ExitAuth},
+	{"tx_", ExitNetwork},
+	{"rem_", ExitKubernetes},
+	{"DOCKER_", ExitDocker},
+	{"IMAGE_", ExitDocker},
+	{"CONTAINER_", ExitDocker},
+	{"VOLUME_", ExitDocker},
+}
+
+// ExitCode : Returns the exit code of a failed operation, ExitFailure if it has no more specific code
+func ExitCode(op string) int {
+	if code, ok := exitCodes[op]; ok {
+		return code
+	}
+	for _, family := range exitCodePrefixes {
+		if strings.HasPrefix(op, family.prefix) {
+			return family.code
+		}
+	}
+	return ExitFailure
+}
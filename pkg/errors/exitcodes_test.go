@@ -0,0 +1,51 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package errors
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExitCode(t *testing.T) {
+	tests := map[string]struct {
+		op   string
+		code int
+	}{
+		"input required":              {ErrOpInputRequired, ExitInputRequired},
+		"invalid options":             {"proj_options_invalid", ExitUsage},
+		"connection not found":        {"con_not_found", ExitNotFound},
+		"project connection missing":  {"connection_notfound", ExitNotFound},
+		"failed authentication":       {"tx_auth", ExitAuth},
+		"security family":             {"sec_keyring", ExitAuth},
+		"unreachable connection":      {"tx_connection", ExitNetwork},
+		"docker family":               {"DOCKER_COMPOSE_START_ERROR", ExitDocker},
+		"remote deployment family":    {"rem_create_namespace", ExitKubernetes},
+		"remote deployment not found": {"rem_not_found", ExitNotFound},
+		"sync":                        {"proj_sync", ExitSync},
+		"sync family":                 {"proj_sync_maintenance", ExitSync},
+		"timeout":                     {"rem_ready_timeout", ExitTimeout},
+		"conflict":                    {"proj_conflict", ExitConflict},
+		"build failed":                {"proj_build_failed", ExitBuild},
+		"cancelled sync":              {"proj_sync_cancelled", ExitCancelled},
+		"cancelled request":           {"tx_cancelled", ExitCancelled},
+		"daemon already running":      {"daemon_running", ExitConflict},
+		"other operations":            {"proj_rename", ExitFailure},
+		"unknown operations":          {"CWCTL_ERROR", ExitFailure},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.code, ExitCode(test.op))
+		})
+	}
+}
@@ -0,0 +1,215 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package errors
+
+import "sort"
+
+// Categories of failures, telling a caller who can fix them
+const (
+	CategoryUser    = "user"    // The command, its input or the local setup must be changed
+	CategoryNetwork = "network" // Codewind, Keycloak, a registry or the cluster could not be reached
+	CategoryServer  = "server"  // Codewind, Docker, Kubernetes or cwctl itself failed
+)
+
+// Registration : An operation in the error catalogue, with its numeric code and category. Codes are stable: a code
+// is never reused for a different operation, and an operation keeps its code.
+type Registration struct {
+	Op       string `json:"op"`
+	Code     int    `json:"code"`
+	Category string `json:"category"`
+}
+
+// ErrOpUnknown : The operation of failures without an operation code, such as errors that were only logged
+const ErrOpUnknown = "CWCTL_ERROR"
+
+// catalogue holds every operation the packages fail with. Codes are grouped by package: 1xxx for cwctl itself,
+// 2xxx connections and configuration, 3xxx projects, 4xxx remote deployments, 5xxx security, 6xxx requests to
+// Codewind, 7xxx Docker, 8xxx templates and registries, and 9xxx the daemon. New operations take the next free code
+// of their group.
+var catalogue = []Registration{
+	{ErrOpUnknown, 1000, CategoryServer},
+	{ErrOpInputRequired, 1001, CategoryUser},
+
+	{"con_parse", 2001, CategoryUser},
+	{"con_load", 2002, CategoryUser},
+	{"con_write", 2003, CategoryUser},
+	{"con_schema_update", 2004, CategoryUser},
+	{"con_conflict", 2005, CategoryUser},
+	{"con_not_found", 2006, CategoryUser},
+	{"con_protected", 2007, CategoryUser},
+	{"con_environment", 2008, CategoryUser},
+	{"con_proxy", 2009, CategoryNetwork},
+	{"con_cacert", 2010, CategoryUser},
+	{"con_clientcert", 2011, CategoryUser},
+	{"con_issuer", 2012, CategoryServer},
+	{"con_retry", 2013, CategoryNetwork},
+	{"con_timeout", 2014, CategoryNetwork},
+	{"con_incompatible", 2015, CategoryUser},
+	{"config_connection_notfound", 2101, CategoryUser},
+	{"config_pfe_hostname_port_notfound", 2102, CategoryNetwork},
+	{"config_cli_read", 2103, CategoryUser},
+	{"config_cli_write", 2104, CategoryUser},
+	{"config_cli_key", 2105, CategoryUser},
+	{"config_cli_value", 2106, CategoryUser},
+
+	{"proj_bind", 3001, CategoryServer},
+	{"proj_request", 3002, CategoryNetwork},
+	{"proj_response", 3003, CategoryServer},
+	{"proj_parse", 3004, CategoryServer},
+	{"proj_load", 3005, CategoryUser},
+	{"proj_write", 3006, CategoryUser},
+	{"proj_delete", 3007, CategoryUser},
+	{"proj_unbind", 3008, CategoryServer},
+	{"proj_get", 3009, CategoryServer},
+	{"project create", 3010, CategoryUser},
+	{"proj_conflict", 3011, CategoryUser},
+	{"proj_notfound", 3012, CategoryUser},
+	{"connection_notfound", 3013, CategoryUser},
+	{"proj_id_invalid", 3014, CategoryUser},
+	{"proj_options_invalid", 3015, CategoryUser},
+	{"proj_sync", 3016, CategoryServer},
+	{"proj_sync_ref", 3017, CategoryUser},
+	{"proj_sync_maintenance", 3018, CategoryServer},
+	{"proj_sync_case", 3019, CategoryUser},
+	{"proj_sync_cancelled", 3020, CategoryUser},
+	{"proj_write_cw_settings", 3021, CategoryUser},
+	{"invalid_git_credentials", 3022, CategoryUser},
+	{"proj_debug_timeout", 3023, CategoryServer},
+	{"proj_loadtest_timeout", 3024, CategoryServer},
+	{"proj_not_running", 3025, CategoryUser},
+	{"proj_rename", 3026, CategoryServer},
+	{"proj_build_failed", 3027, CategoryUser},
+	{"proj_build_timeout", 3028, CategoryServer},
+	{"proj_events", 3029, CategoryNetwork},
+	{"proj_loadtest_schedule", 3030, CategoryServer},
+	{"proj_sync_mirror", 3031, CategoryNetwork},
+
+	{"rem_not_found", 4001, CategoryUser},
+	{"rem_no_ingress", 4002, CategoryUser},
+	{"rem_create_namespace", 4003, CategoryServer},
+	{"rem_scale", 4004, CategoryServer},
+	{"rem_logs", 4005, CategoryServer},
+	{"rem_backup", 4006, CategoryServer},
+	{"rem_keycloak_shared", 4007, CategoryUser},
+	{"rem_ready_timeout", 4008, CategoryServer},
+	{"rem_storage_class", 4009, CategoryUser},
+	{"rem_port_forward", 4010, CategoryNetwork},
+	{"rem_relabel", 4011, CategoryServer},
+	{"rem_mixed_versions", 4012, CategoryUser},
+	{"rem_rotate_secrets", 4013, CategoryServer},
+	{"rem_existing_secret", 4014, CategoryUser},
+	{"rem_cancelled", 4015, CategoryUser},
+	{"rem_preflight", 4016, CategoryUser},
+	{"rem_ingress_host", 4017, CategoryUser},
+	{"rem_loadtest_job", 4018, CategoryServer},
+
+	{"sec_connection", 5001, CategoryNetwork},
+	{"sec_response", 5002, CategoryServer},
+	{"sec_bodyparser", 5003, CategoryServer},
+	{"sec_notfound", 5004, CategoryUser},
+	{"sec_create", 5005, CategoryServer},
+	{"sec_passwordcontent", 5006, CategoryUser},
+	{"sec_badhostname", 5007, CategoryNetwork},
+	{"sec_keyring", 5008, CategoryUser},
+	{"sec_keyring_secret_not_found", 5009, CategoryUser},
+	{"sec_insecure_keyring", 5010, CategoryUser},
+	{"sec_con_config", 5011, CategoryUser},
+	{"sec_cli_options", 5012, CategoryUser},
+	{"sec_password_read", 5013, CategoryUser},
+
+	{"tx_connection", 6001, CategoryNetwork},
+	{"tx_auth", 6002, CategoryUser},
+	{"tx_failed", 6003, CategoryNetwork},
+	{"tx_nopassword", 6004, CategoryUser},
+	{"tx_proxy", 6005, CategoryUser},
+	{"tx_cacert", 6006, CategoryUser},
+	{"tx_clientcert", 6007, CategoryUser},
+	{"tx_cancelled", 6008, CategoryUser},
+	{"tx_websocket", 6009, CategoryNetwork},
+
+	{"DOCKER_VALIDATE", 7001, CategoryServer},
+	{"CLIENT_CREATE_ERROR", 7002, CategoryServer},
+	{"CONTAINER_INSPECT_ERROR", 7003, CategoryServer},
+	{"CONTAINER_LOGS_ERROR", 7004, CategoryServer},
+	{"CONTAINER_ERROR", 7005, CategoryServer},
+	{"CONTAINER_STOP_ERROR", 7006, CategoryServer},
+	{"DOCKER_COMPOSE_FILE_CREATE_ERROR", 7007, CategoryUser},
+	{"DOCKER_COMPOSE_START_ERROR", 7008, CategoryServer},
+	{"DOCKER_COMPOSE_STOP_ERROR", 7009, CategoryServer},
+	{"DOCKER_COMPOSE_REMOVE", 7010, CategoryServer},
+	{"DOCKER_COMPOSE_NOT_FOUND", 7011, CategoryUser},
+	{"docker_compose_not_found", 7012, CategoryUser},
+	{"IMAGE_NOT_FOUND", 7013, CategoryUser},
+	{"IMAGE_PULL_ERROR", 7014, CategoryNetwork},
+	{"IMAGE_TAG_ERROR", 7015, CategoryServer},
+	{"IMAGE_REMOVE_ERROR", 7016, CategoryServer},
+	{"IMAGE_DIGEST_ERROR", 7017, CategoryServer},
+	{"IMAGE_SAVE_ERROR", 7018, CategoryServer},
+	{"IMAGE_LOAD_ERROR", 7019, CategoryServer},
+	{"VERSION_MISMATCH_ERROR", 7020, CategoryUser},
+	{"REGISTRY_UNREACHABLE_ERROR", 7021, CategoryNetwork},
+	{"PORT_IN_USE_ERROR", 7022, CategoryUser},
+	{"PORT_MAPPING_ERROR", 7023, CategoryServer},
+	{"CERTIFICATE_ERROR", 7024, CategoryServer},
+	{"CERTIFICATE_TRUST_ERROR", 7025, CategoryUser},
+	{"VOLUME_LIST_ERROR", 7026, CategoryServer},
+	{"VOLUME_REMOVE_ERROR", 7027, CategoryServer},
+	{"CONTAINER_LIST_ERROR", 7028, CategoryServer},
+	{"IMAGE_LIST_ERROR", 7029, CategoryServer},
+	{"DOCKER_CREDENTIAL_ERROR", 7030, CategoryUser},
+	{"DOCKER_VERSION_ERROR", 7031, CategoryUser},
+
+	{"LIST_TEMPLATES_ERROR", 8001, CategoryServer},
+	{"LIST_STYLES_ERROR", 8002, CategoryServer},
+	{"SEARCH_TEMPLATES_ERROR", 8003, CategoryServer},
+	{"CREATE_TEMPLATE_ERROR", 8004, CategoryUser},
+	{"MIRROR_REPO_ERROR", 8005, CategoryNetwork},
+	{"LIST_REPOS_ERROR", 8006, CategoryServer},
+	{"ADD_REPO_ERROR", 8007, CategoryServer},
+	{"DELETE_REPO_ERROR", 8008, CategoryServer},
+	{"ENABLE_REPO_ERROR", 8009, CategoryServer},
+	{"DISABLE_REPO_ERROR", 8010, CategoryServer},
+	{"GET_CREDS_KEYCHAIN_ERROR", 8011, CategoryUser},
+	{"LIST_REGISTRIES_ERROR", 8101, CategoryServer},
+	{"ADD_REGISTRY_ERROR", 8102, CategoryServer},
+	{"DELETE_REGISTRY_ERROR", 8103, CategoryServer},
+
+	{"daemon_running", 9001, CategoryUser},
+	{"daemon_listen", 9002, CategoryUser},
+}
+
+// registrations indexes the catalogue by operation
+var registrations = func() map[string]Registration {
+	index := map[string]Registration{}
+	for _, registration := range catalogue {
+		index[registration.Op] = registration
+	}
+	return index
+}()
+
+// Lookup : Returns the registration of an operation, or the code and category of CWCTL_ERROR for an operation
+// missing from the catalogue
+func Lookup(op string) Registration {
+	if registration, ok := registrations[op]; ok {
+		return registration
+	}
+	unknown := registrations[ErrOpUnknown]
+	unknown.Op = op
+	return unknown
+}
+
+// Catalogue : Returns every registered operation, ordered by code
+func Catalogue() []Registration {
+	sorted := append([]Registration{}, catalogue...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Code < sorted[j].Code })
+	return sorted
+}
@@ -0,0 +1,78 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package errors
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogue(t *testing.T) {
+	t.Run("codes and operations are only registered once", func(t *testing.T) {
+		codes := map[int]string{}
+		ops := map[string]bool{}
+		for _, registration := range catalogue {
+			assert.Empty(t, codes[registration.Code], "code %d is registered for %s and %s", registration.Code, codes[registration.Code], registration.Op)
+			assert.False(t, ops[registration.Op], "%s is registered twice", registration.Op)
+			codes[registration.Code] = registration.Op
+			ops[registration.Op] = true
+		}
+	})
+
+	t.Run("every operation has a category", func(t *testing.T) {
+		for _, registration := range catalogue {
+			assert.Contains(t, []string{CategoryUser, CategoryNetwork, CategoryServer}, registration.Category, registration.Op)
+		}
+	})
+
+	t.Run("every operation with an exit code is registered", func(t *testing.T) {
+		for op := range exitCodes {
+			_, ok := registrations[op]
+			assert.True(t, ok, "%s has an exit code but is not registered", op)
+		}
+	})
+
+	t.Run("every operation of the packages is registered", func(t *testing.T) {
+		// Operations are declared as constants named errOp..., ErrOp... or errDocker...
+		declaration := regexp.MustCompile(`(?m)^\s*(?:const\s+)?(?:errOp|ErrOp|errDocker|ErrDocker)\w*\s*=\s*"([^"]+)"`)
+		filepath.Walk("..", func(path string, info os.FileInfo, err error) error {
+			if err != nil || info.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+				return nil
+			}
+			source, _ := ioutil.ReadFile(path)
+			for _, match := range declaration.FindAllStringSubmatch(string(source), -1) {
+				_, ok := registrations[match[1]]
+				assert.True(t, ok, "%s declared in %s is not registered", match[1], path)
+			}
+			return nil
+		})
+	})
+
+	t.Run("the catalogue is ordered by code", func(t *testing.T) {
+		sorted := Catalogue()
+		assert.Len(t, sorted, len(catalogue))
+		for i := 1; i < len(sorted); i++ {
+			assert.True(t, sorted[i-1].Code < sorted[i].Code)
+		}
+	})
+}
+
+func TestLookup(t *testing.T) {
+	assert.Equal(t, Registration{"proj_sync", 3016, CategoryServer}, Lookup("proj_sync"))
+	assert.Equal(t, Registration{"something_new", 1000, CategoryServer}, Lookup("something_new"))
+}
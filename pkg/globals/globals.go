@@ -11,10 +11,77 @@
 
 package globals
 
+import (
+	"errors"
+	"time"
+)
+
+// Credential stores that secrets can be kept in
+const (
+	CredentialStoreKeychain = "keychain" // The platform keychain: macOS Keychain, Windows Credential Manager or libsecret
+	CredentialStoreFile     = "file"     // The insecure keyring file in the Codewind config directory
+	CredentialStoreBoth     = "both"     // Saved to both, read from the keychain first
+)
+
 // UseInsecureKeyring decides whether we should use the insecure keyring or the (secure) system keyring
 var UseInsecureKeyring = false
 
+// CredentialStore decides where secrets are saved and read from
+var CredentialStore = CredentialStoreKeychain
+
 // SetUseInsecureKeyring sets useInsecureKeyring
 func SetUseInsecureKeyring(newUseInsecureKeyring bool) {
 	UseInsecureKeyring = newUseInsecureKeyring
+	if newUseInsecureKeyring {
+		CredentialStore = CredentialStoreFile
+	} else {
+		CredentialStore = CredentialStoreKeychain
+	}
+}
+
+// SetCredentialStore sets CredentialStore, rejecting unknown stores
+func SetCredentialStore(newCredentialStore string) error {
+	switch newCredentialStore {
+	case CredentialStoreKeychain, CredentialStoreFile, CredentialStoreBoth:
+		CredentialStore = newCredentialStore
+		UseInsecureKeyring = newCredentialStore == CredentialStoreFile
+		return nil
+	}
+	return errors.New("Unknown credential store " + newCredentialStore + ", use keychain, file or both")
+}
+
+// UseInClusterConfig decides whether Kubernetes operations use the service account of the pod cwctl is running in
+var UseInClusterConfig = false
+
+// SetUseInClusterConfig sets UseInClusterConfig
+func SetUseInClusterConfig(newUseInClusterConfig bool) {
+	UseInClusterConfig = newUseInClusterConfig
+}
+
+// TraceHTTP decides whether requests sent to Codewind connections are traced to stderr
+var TraceHTTP = false
+
+// TraceHTTPHeaders decides whether traced requests include their headers, with credentials removed
+var TraceHTTPHeaders = false
+
+// SetTraceHTTP sets TraceHTTP and TraceHTTPHeaders, headers being traced only when tracing is enabled
+func SetTraceHTTP(newTraceHTTP bool, newTraceHTTPHeaders bool) {
+	TraceHTTP = newTraceHTTP || newTraceHTTPHeaders
+	TraceHTTPHeaders = newTraceHTTPHeaders
+}
+
+// NonInteractive decides whether commands fail instead of prompting for input
+var NonInteractive = false
+
+// SetNonInteractive sets NonInteractive
+func SetNonInteractive(newNonInteractive bool) {
+	NonInteractive = newNonInteractive
+}
+
+// RequestTimeout is the deadline for each request sent to a Codewind connection, no deadline when zero
+var RequestTimeout time.Duration
+
+// SetRequestTimeout sets RequestTimeout
+func SetRequestTimeout(newRequestTimeout time.Duration) {
+	RequestTimeout = newRequestTimeout
 }
@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package i18n
+
+// catalogDE : German messages
+var catalogDE = map[string]string{
+	// apiroutes
+	"apiroutes.incompatible_release": "%s %s kann nicht mit cwctl %s verwendet werden, installieren Sie das cwctl desselben Release wie Codewind",
+	"apiroutes.newer_release":        "%s %s ist neuer als cwctl %s, einige Befehle können fehlschlagen, bis cwctl aktualisiert wird",
+	"apiroutes.older_release":        "%s %s ist älter als cwctl %s, einige Befehle können fehlschlagen, bis Codewind aktualisiert wird",
+
+	// config
+	"config.hostname_or_port_not_found": "Hostname oder Port der Codewind-Container nicht gefunden. Stellen Sie sicher, dass sie laufen.",
+
+	// connections
+	"connections.con_not_found": "Verbindung %s nicht gefunden",
+
+	// daemon
+	"daemon.running": "Auf dem Socket wartet bereits ein Daemon",
+
+	// docker
+	"docker.bad_digest":           "Die Prüfsumme des Docker-Images konnte nicht validiert werden",
+	"docker.compose_not_found":    "Docker Compose nicht gefunden, installieren Sie Docker Desktop oder das Plugin docker compose",
+	"docker.image_not_in_bundle":  "Das Image-Bundle enthält das Image nicht: %s",
+	"docker.mixed_versions":       "Codewind-Container unterschiedlicher Versionen laufen, führen Sie cwctl stop aus, bevor Sie eine Version starten: %s",
+	"docker.registry_unreachable": "Docker konnte die Registry zum Abrufen von %s nicht erreichen: %s. %s",
+	"docker.port_in_use":          "Der Port wird von einem anderen Prozess verwendet, wählen Sie mit --pfe-port einen anderen: %s",
+	"docker.invalid_port":         "Der Port muss zwischen 1 und 65535 liegen: %s",
+	"docker.trust_unsupported":    "Das Vertrauen des Zertifikats wird auf diesem Betriebssystem nicht unterstützt, fügen Sie es manuell zu den vertrauenswürdigen Zertifikaten hinzu: %s",
+	"docker.running_version":      "Codewind läuft bereits in einer anderen Version, führen Sie cwctl stop vor dem Starten aus: %s",
+
+	// project
+	"project.dup_name":                       "Der Projektname wird bereits verwendet",
+	"project.invalid_type":                   "Der Projekttyp ist ungültig",
+	"project.api_not_found":                  "Die angeforderte Ressource wurde auf dem Codewind-Server nicht gefunden",
+	"project.upgrade_error":                  "Beim Aktualisieren der Projekte ist ein Fehler aufgetreten",
+	"project.no_project_path":                "Kein Projektpfad angegeben",
+	"project.project_path_does_not_exist":    "Der angegebene Projektpfad existiert nicht",
+	"project.project_path_non_empty":         "Das angegebene Verzeichnis ist nicht leer",
+	"project.unknown_response_code":          "Der Codewind-Server hat einen unbekannten Antwortcode zurückgegeben",
+	"project.project_link_unknown_not_found": "Der Codewind-Server hat einen unbekannten 404-Fehler zurückgegeben",
+	"project.project_link_conflict":          "Die Umgebungsvariable der Projektverknüpfung wird bereits verwendet",
+	"project.invalid_request":                "Die Anforderungsparameter sind ungültig",
+	"project.maintenance_mode":               "Codewind ist nicht verfügbar, zum Beispiel im Wartungsmodus, die Synchronisierung wurde gestoppt - versuchen Sie es später erneut",
+	"project.debug_timeout":                  "Zeitüberschreitung beim Warten auf den Start des Projekts im Debugmodus",
+	"project.load_test_conflict":             "Für dieses Projekt läuft bereits ein Lasttest",
+	"project.load_test_timeout":              "Zeitüberschreitung beim Warten auf das Ende des Lasttests",
+	"project.build_conflict":                 "Für dieses Projekt läuft bereits ein Build",
+	"project.build_failed":                   "Der Build wurde mit dem Status %s beendet",
+	"project.build_timeout":                  "Zeitüberschreitung beim Warten auf das Ende des Builds",
+	"project.app_not_running":                "Das Projekt läuft nicht, starten Sie es, bevor Sie seinen Port weiterleiten",
+	"project.app_port_unknown":               "Das Projekt hat seinen Anwendungsport nicht gemeldet",
+	"project.invalid_project_name":           "Der Projektname darf nur Buchstaben, Ziffern, '.', '_' und '-' enthalten",
+	"project.invalid_setting":                "Einstellungen müssen die Form key=value, key+=value oder key-=value haben: %s",
+	"project.events_closed":                  "Codewind hat den Ereignisstrom geschlossen",
+	"project.events_refused":                 "Codewind hat den Ereignisstrom abgelehnt: %s",
+	"project.no_profiling_run":               "Kein Lastlauf für das Projekt gefunden, führen Sie einen Lasttest aus, um es zu profilieren",
+	"project.no_profiling_data":              "Während des Lastlaufs wurden keine Profilingdaten erfasst",
+	"project.case_collision":                 "%s unterscheidet sich nur in der Groß-/Kleinschreibung von %s und wurde daher nicht synchronisiert",
+	"project.sync_cancelled":                 "Die Synchronisierung wurde abgebrochen, der Upload wurde nicht abgeschlossen",
+	"project.invalid_link_env":               "Die Umgebungsvariable der Verknüpfung muss mit einem Buchstaben oder '_' beginnen und darf nur Buchstaben, Ziffern und '_' enthalten",
+	"project.link_to_self":                   "Ein Projekt kann nicht mit sich selbst verknüpft werden",
+	"project.link_connection":                "Das Zielprojekt ist an die Verbindung %s gebunden, Verknüpfungen sind aber nur zwischen Projekten der Verbindung %s möglich",
+	"project.invalid_change_detection":       "Die Änderungserkennung muss auto, mtime oder poll sein",
+	"project.invalid_watch_interval":         "Das Überwachungsintervall muss größer als 0 sein",
+	"project.invalid_cron_schedule":          "Der Zeitplan muss aus fünf Cron-Feldern (Minute Stunde Tag-des-Monats Monat Wochentag) oder einem Makro wie @daily bestehen",
+	"project.invalid_schedule_name":          "Der Name des Zeitplans darf nur aus alphanumerischen Kleinbuchstaben oder '-' bestehen und muss mit einem alphanumerischen Zeichen beginnen und enden",
+	"project.schedule_exists":                "Ein Lasttest-Zeitplan namens %s ist bereits vorhanden",
+	"project.schedule_not_found":             "Kein Lasttest-Zeitplan namens %s",
+	"project.mirror_connection":              "Projekt %s ist an Verbindung %s gebunden, Spiegel müssen an andere Verbindungen gebunden werden",
+	"project.mirror_exists":                  "Projekt %s wird auf Verbindung %s bereits durch Projekt %s gespiegelt",
+	"project.mirror_not_found":               "Projekt %s hat keinen Spiegel auf Verbindung %s",
+	"project.mirror_sync_failed":             "Synchronisierung auf den Verbindungen %s fehlgeschlagen",
+
+	// remote
+	"remote.preflight_failed":        "Preflight-Prüfungen fehlgeschlagen, es wurde nichts erstellt: %s",
+	"remote.bad_ingress_host":        "Ingress-Hostnamen und die Platzhalterdomäne müssen gültige DNS-Namen sein: %s",
+	"remote.ingress_domain_conflict": "Die Platzhalterdomäne ersetzt die Ingress-Domäne, legen Sie nur eine davon fest",
+	"remote.bad_load_test_job_name":  "Namen von Zeitplänen dürfen nur Kleinbuchstaben, Ziffern und Bindestriche enthalten, müssen mit einem Buchstaben oder einer Ziffer beginnen und enden und kurz genug für den Namen eines CronJobs sein",
+	"remote.no_connection_workspace": "Kein Codewind-Arbeitsbereich im Cluster hat den Gatekeeper der Verbindung",
+
+	// security
+	"security.user_not_found":    "Registrierter Benutzer nicht gefunden",
+	"security.group_not_found":   "Gruppe nicht gefunden",
+	"security.client_not_found":  "Registrierter Client nicht gefunden",
+	"security.unable_to_parse":   "Die Antwort von Keycloak kann nicht verarbeitet werden",
+	"security.invalid_options":   "Ungültige oder fehlende Befehlszeilenoptionen",
+	"security.auth_is_down":      "Der Authentifizierungsdienst ist nicht verfügbar",
+	"security.secret_not_found":  "Geheimnis %s wurde im Schlüsselbund nicht gefunden",
+	"security.keyring_not_found": "Schlüsselbund nicht gefunden",
+	"security.bad_realm_export":  "Der Realm-Export benennt keinen Realm",
+	"security.bad_con_export":    "Der Verbindungsexport hat kein unterstütztes Format",
+	"security.bad_passphrase":    "Die Anmeldedaten können nicht entschlüsselt werden, überprüfen Sie die Passphrase",
+	"security.device_expired":    "Der Gerätecode ist abgelaufen, bevor die Anmeldung bestätigt wurde",
+	"security.no_device_flow":    "Der Identitätsanbieter unterstützt keine Geräteautorisierung",
+	"security.bad_discovery":     "Das Discovery-Dokument des Identitätsanbieters passt nicht zu seinem Aussteller",
+	"security.bad_id_token":      "Das ID-Token wurde nicht vom Identitätsanbieter für diesen Client ausgestellt",
+	"security.bad_login_state":   "Die Anmeldeantwort passt nicht zur Anforderung, melden Sie sich erneut an",
+	"security.login_timeout":     "Zeitüberschreitung beim Warten auf den Abschluss der Anmeldung im Browser",
+}
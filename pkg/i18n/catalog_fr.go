@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package i18n
+
+// catalogFR : French messages
+var catalogFR = map[string]string{
+	// apiroutes
+	"apiroutes.incompatible_release": "%s %s ne peut pas être utilisé avec cwctl %s, installez le cwctl de la même version que Codewind",
+	"apiroutes.newer_release":        "%s %s est plus récent que cwctl %s, certaines commandes peuvent échouer tant que cwctl n'est pas mis à niveau",
+	"apiroutes.older_release":        "%s %s est plus ancien que cwctl %s, certaines commandes peuvent échouer tant que Codewind n'est pas mis à niveau",
+
+	// config
+	"config.hostname_or_port_not_found": "Nom d'hôte ou port des conteneurs Codewind introuvable. Vérifiez qu'ils sont en cours d'exécution.",
+
+	// connections
+	"connections.con_not_found": "Connexion %s introuvable",
+
+	// daemon
+	"daemon.running": "Un démon écoute déjà sur le socket",
+
+	// docker
+	"docker.bad_digest":           "Échec de la validation de la somme de contrôle de l'image docker",
+	"docker.compose_not_found":    "Docker Compose introuvable, installez Docker Desktop ou le plugin docker compose",
+	"docker.image_not_in_bundle":  "Le paquet d'images ne contient pas l'image: %s",
+	"docker.mixed_versions":       "Des conteneurs Codewind de versions différentes sont en cours d'exécution, lancez cwctl stop avant de démarrer une version: %s",
+	"docker.registry_unreachable": "Docker n'a pas pu joindre le registre pour télécharger %s: %s. %s",
+	"docker.port_in_use":          "Le port est utilisé par un autre processus, choisissez-en un autre avec --pfe-port: %s",
+	"docker.invalid_port":         "Le port doit être compris entre 1 et 65535: %s",
+	"docker.trust_unsupported":    "L'approbation du certificat n'est pas prise en charge sur ce système d'exploitation, ajoutez-le manuellement aux certificats de confiance: %s",
+	"docker.running_version":      "Codewind exécute déjà une autre version, lancez cwctl stop avant de démarrer: %s",
+
+	// project
+	"project.dup_name":                       "le nom du projet est déjà utilisé",
+	"project.invalid_type":                   "le type de projet n'est pas valide",
+	"project.api_not_found":                  "ressource demandée introuvable sur le serveur Codewind",
+	"project.upgrade_error":                  "une erreur s'est produite lors de la mise à niveau des projets",
+	"project.no_project_path":                "chemin du projet non indiqué",
+	"project.project_path_does_not_exist":    "le chemin de projet indiqué n'existe pas",
+	"project.project_path_non_empty":         "Le répertoire indiqué n'est pas vide",
+	"project.unknown_response_code":          "code de réponse inconnu renvoyé par le serveur Codewind",
+	"project.project_link_unknown_not_found": "erreur 404 inconnue renvoyée par le serveur Codewind",
+	"project.project_link_conflict":          "la variable d'environnement du lien de projet est déjà utilisée",
+	"project.invalid_request":                "les paramètres de la requête ne sont pas valides",
+	"project.maintenance_mode":               "Codewind est indisponible, par exemple en mode maintenance, synchronisation arrêtée - réessayez plus tard",
+	"project.debug_timeout":                  "délai dépassé en attendant le démarrage du projet en mode débogage",
+	"project.load_test_conflict":             "un test de charge est déjà en cours pour ce projet",
+	"project.load_test_timeout":              "délai dépassé en attendant la fin du test de charge",
+	"project.build_conflict":                 "une construction est déjà en cours pour ce projet",
+	"project.build_failed":                   "la construction s'est terminée avec le statut %s",
+	"project.build_timeout":                  "délai dépassé en attendant la fin de la construction",
+	"project.app_not_running":                "le projet n'est pas en cours d'exécution, démarrez-le avant de rediriger son port",
+	"project.app_port_unknown":               "le projet n'a pas indiqué le port de son application",
+	"project.invalid_project_name":           "le nom du projet ne doit contenir que des lettres, des chiffres, '.', '_' et '-'",
+	"project.invalid_setting":                "les paramètres doivent être de la forme key=value, key+=value ou key-=value: %s",
+	"project.events_closed":                  "Codewind a fermé le flux d'événements",
+	"project.events_refused":                 "Codewind a refusé le flux d'événements : %s",
+	"project.no_profiling_run":               "Aucune exécution de charge trouvée pour le projet, lancez un test de charge pour le profiler",
+	"project.no_profiling_data":              "Aucune donnée de profilage n'a été collectée pendant l'exécution de charge",
+	"project.case_collision":                 "%s ne diffère de %s que par la casse, il n'a donc pas été synchronisé",
+	"project.sync_cancelled":                 "Synchronisation annulée, le téléversement n'a pas été terminé",
+	"project.invalid_link_env":               "la variable d'environnement du lien doit commencer par une lettre ou '_', et ne contenir que des lettres, des chiffres et '_'",
+	"project.link_to_self":                   "un projet ne peut pas être lié à lui-même",
+	"project.link_connection":                "le projet cible est lié à la connexion %s, mais les liens ne peuvent être créés qu'entre des projets de la connexion %s",
+	"project.invalid_change_detection":       "La détection des modifications doit être auto, mtime ou poll",
+	"project.invalid_watch_interval":         "L'intervalle de surveillance doit être supérieur à 0",
+	"project.invalid_cron_schedule":          "La planification doit comporter cinq champs cron (minute heure jour-du-mois mois jour-de-la-semaine) ou une macro telle que @daily",
+	"project.invalid_schedule_name":          "Le nom de la planification doit être composé de caractères alphanumériques en minuscules ou de '-', et commencer et se terminer par un caractère alphanumérique",
+	"project.schedule_exists":                "Une planification de test de charge nommée %s existe déjà",
+	"project.schedule_not_found":             "Aucune planification de test de charge nommée %s",
+	"project.mirror_connection":              "Le projet %s est lié à la connexion %s, les miroirs doivent être liés à d'autres connexions",
+	"project.mirror_exists":                  "Le projet %s est déjà mis en miroir sur la connexion %s, par le projet %s",
+	"project.mirror_not_found":               "Le projet %s n'a pas de miroir sur la connexion %s",
+	"project.mirror_sync_failed":             "Échec de la synchronisation sur les connexions %s",
+
+	// remote
+	"remote.preflight_failed":        "Échec des vérifications préalables, rien n'a été créé: %s",
+	"remote.bad_ingress_host":        "Les noms d'hôte d'ingress et le domaine générique doivent être des noms DNS valides: %s",
+	"remote.ingress_domain_conflict": "Le domaine générique remplace le domaine d'ingress, définissez un seul des deux",
+	"remote.bad_load_test_job_name":  "Les noms de planification doivent contenir uniquement des minuscules, des chiffres et des tirets, commencer et se terminer par une lettre ou un chiffre, et être assez courts pour nommer un CronJob",
+	"remote.no_connection_workspace": "Aucun espace de travail Codewind du cluster n'a le Gatekeeper de la connexion",
+
+	// security
+	"security.user_not_found":    "Utilisateur enregistré introuvable",
+	"security.group_not_found":   "Groupe introuvable",
+	"security.client_not_found":  "Client enregistré introuvable",
+	"security.unable_to_parse":   "Impossible d'analyser la réponse de Keycloak",
+	"security.invalid_options":   "Options de ligne de commande non valides ou manquantes",
+	"security.auth_is_down":      "Service d'authentification indisponible",
+	"security.secret_not_found":  "Secret %s introuvable dans le trousseau",
+	"security.keyring_not_found": "Trousseau introuvable",
+	"security.bad_realm_export":  "L'export de realm ne nomme aucun realm",
+	"security.bad_con_export":    "L'export de connexion n'est pas dans un format pris en charge",
+	"security.bad_passphrase":    "Impossible de déchiffrer les identifiants, vérifiez la phrase secrète",
+	"security.device_expired":    "Le code d'appareil a expiré avant l'approbation de la connexion",
+	"security.no_device_flow":    "Le fournisseur d'identité ne prend pas en charge l'autorisation d'appareil",
+	"security.bad_discovery":     "Le document de découverte du fournisseur d'identité ne correspond pas à son émetteur",
+	"security.bad_id_token":      "Le jeton d'ID n'a pas été émis par le fournisseur d'identité pour ce client",
+	"security.bad_login_state":   "La réponse de connexion ne correspond pas à la requête, reconnectez-vous",
+	"security.login_timeout":     "Délai dépassé en attendant la fin de la connexion dans le navigateur",
+}
@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultLocale : The locale of the messages in the code, used when there is no catalog for the selected locale
+const DefaultLocale = "en"
+
+// catalogs maps each locale to its catalog. A catalog maps the ID of each message to its translation, which takes the
+// same fmt verbs as the English message built with that ID.
+var catalogs = map[string]map[string]string{
+	"de": catalogDE,
+	"fr": catalogFR,
+}
+
+var (
+	locale  = DefaultLocale
+	catalog map[string]string
+	// verbs are the fmt verbs the messages of the catalogs use
+	verbs = regexp.MustCompile(`%[sv]`)
+)
+
+// Message : A message of the catalogs, built from its ID, its English format and the values of its verbs. Error returns
+// the message in English, for logs and --json, while Translate returns it in the selected locale.
+type Message struct {
+	ID     string
+	Format string
+	Args   []interface{}
+}
+
+// NewMessage : Returns a message of the catalogs built from its ID, its English format and the values of its verbs
+func NewMessage(id string, format string, args ...interface{}) *Message {
+	return &Message{id, format, args}
+}
+
+// Errorf : Returns an error built from a message of the catalogs, which Describe translates
+func Errorf(id string, format string, args ...interface{}) error {
+	return NewMessage(id, format, args...)
+}
+
+// Error : Returns the message in English
+func (m *Message) Error() string {
+	return sprintf(m.Format, m.Args)
+}
+
+// Translate : Returns the message in the selected locale, or in English when the catalog does not translate it
+func (m *Message) Translate() string {
+	if translation, ok := catalog[m.ID]; ok {
+		return sprintf(translation, m.Args)
+	}
+	return m.Error()
+}
+
+// Describe : Returns the description of an error in the selected locale. Errors built from a message of the catalogs
+// are translated when they are the whole description; other descriptions are returned unchanged.
+func Describe(err error, desc string) string {
+	if message, ok := err.(interface{ Translate() string }); ok && err.Error() == desc {
+		return message.Translate()
+	}
+	return desc
+}
+
+// Join : Returns an error of several messages of the catalogs, joined by a separator, which Describe translates
+func Join(messages []*Message, separator string) error {
+	return joinedMessages{messages, separator}
+}
+
+type joinedMessages struct {
+	messages  []*Message
+	separator string
+}
+
+func (j joinedMessages) Error() string {
+	texts := []string{}
+	for _, message := range j.messages {
+		texts = append(texts, message.Error())
+	}
+	return strings.Join(texts, j.separator)
+}
+
+func (j joinedMessages) Translate() string {
+	texts := []string{}
+	for _, message := range j.messages {
+		texts = append(texts, message.Translate())
+	}
+	return strings.Join(texts, j.separator)
+}
+
+// SetLocale : Selects the catalog messages are translated with. An empty locale selects the locale of the environment,
+// from LC_ALL, LC_MESSAGES or LANG in that order. Locales without a catalog, such as en, C or POSIX, leave messages in
+// English, so cwctl always works whatever locale it is given.
+func SetLocale(name string) {
+	if name == "" {
+		for _, variable := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+			if name = os.Getenv(variable); name != "" {
+				break
+			}
+		}
+	}
+	locale, catalog = DefaultLocale, nil
+	catalogName, ok := findCatalog(name)
+	if !ok {
+		return
+	}
+	locale, catalog = catalogName, catalogs[catalogName]
+}
+
+// Locale : Returns the locale of the catalog messages are translated with
+func Locale() string {
+	return locale
+}
+
+// Locales : Returns the locales there are catalogs for, and the default locale
+func Locales() []string {
+	names := []string{DefaultLocale}
+	for name := range catalogs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sprintf(format string, args []interface{}) string {
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// findCatalog finds the catalog of a locale such as de, de-DE or de_DE.UTF-8, falling back to its language
+func findCatalog(name string) (string, bool) {
+	name = strings.ToLower(name)
+	name = strings.SplitN(name, ".", 2)[0]
+	name = strings.SplitN(name, "@", 2)[0]
+	name = strings.Replace(name, "-", "_", -1)
+	if _, ok := catalogs[name]; ok {
+		return name, true
+	}
+	language := strings.SplitN(name, "_", 2)[0]
+	if _, ok := catalogs[language]; ok {
+		return language, true
+	}
+	return "", false
+}
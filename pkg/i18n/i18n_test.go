@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetLocale(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+	tests := map[string]struct {
+		locale string
+		lcAll  string
+		lang   string
+		want   string
+	}{
+		"language":                    {"de", "", "", "de"},
+		"language and region":         {"fr-CA", "", "", "fr"},
+		"POSIX locale":                {"de_DE.UTF-8", "", "", "de"},
+		"locale without a catalog":    {"ja", "", "de_DE.UTF-8", DefaultLocale},
+		"LANG":                        {"", "", "fr_FR.UTF-8", "fr"},
+		"LC_ALL before LANG":          {"", "de_AT.UTF-8", "fr_FR.UTF-8", "de"},
+		"C locale":                    {"", "C", "", DefaultLocale},
+		"no locale":                   {"", "", "", DefaultLocale},
+		"flag before the environment": {"en", "", "de_DE.UTF-8", DefaultLocale},
+	}
+	originalLCAll, originalLCMessages, originalLang := os.Getenv("LC_ALL"), os.Getenv("LC_MESSAGES"), os.Getenv("LANG")
+	defer func() {
+		os.Setenv("LC_ALL", originalLCAll)
+		os.Setenv("LC_MESSAGES", originalLCMessages)
+		os.Setenv("LANG", originalLang)
+	}()
+	os.Unsetenv("LC_MESSAGES")
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			os.Setenv("LC_ALL", test.lcAll)
+			os.Setenv("LANG", test.lang)
+			SetLocale(test.locale)
+			assert.Equal(t, test.want, Locale())
+		})
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+	SetLocale("de")
+	tests := map[string]struct {
+		message *Message
+		want    string
+	}{
+		"message":                {NewMessage("project.project_path_does_not_exist", "given project path does not exist"), "Der angegebene Projektpfad existiert nicht"},
+		"message with a value":   {NewMessage("connections.con_not_found", "Connection %s not found", "K8S1"), "Verbindung K8S1 nicht gefunden"},
+		"message with details":   {NewMessage("docker.port_in_use", "The port is in use by another process, choose another with --pfe-port: %s", "10000"), "Der Port wird von einem anderen Prozess verwendet, wählen Sie mit --pfe-port einen anderen: 10000"},
+		"message not in catalog": {NewMessage("project.unknown", "something went wrong"), "something went wrong"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.message.Translate())
+		})
+	}
+
+	t.Run("leaves messages in English without a catalog", func(t *testing.T) {
+		SetLocale(DefaultLocale)
+		assert.Equal(t, "Connection K8S1 not found", NewMessage("connections.con_not_found", "Connection %s not found", "K8S1").Translate())
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	defer SetLocale(DefaultLocale)
+	SetLocale("fr")
+	err := Errorf("connections.con_not_found", "Connection %s not found", "K8S1")
+
+	t.Run("errors are described in English", func(t *testing.T) {
+		assert.Equal(t, "Connection K8S1 not found", err.Error())
+	})
+
+	t.Run("an error of the catalogs is translated", func(t *testing.T) {
+		assert.Equal(t, "Connexion K8S1 introuvable", Describe(err, err.Error()))
+	})
+
+	t.Run("a description other than the error is kept", func(t *testing.T) {
+		assert.Equal(t, "Connection K8S1 not found: timeout", Describe(err, "Connection K8S1 not found: timeout"))
+	})
+
+	t.Run("other errors are kept", func(t *testing.T) {
+		plainErr := fmt.Errorf("Connection K8S1 not found")
+		assert.Equal(t, "Connection K8S1 not found", Describe(plainErr, plainErr.Error()))
+	})
+
+	t.Run("joined messages are translated one by one", func(t *testing.T) {
+		joined := Join([]*Message{
+			NewMessage("connections.con_not_found", "Connection %s not found", "K8S1"),
+			NewMessage("security.keyring_not_found", "Keyring not found"),
+		}, ". ")
+		assert.Equal(t, "Connection K8S1 not found. Keyring not found", joined.Error())
+		assert.Equal(t, "Connexion K8S1 introuvable. Trousseau introuvable", Describe(joined, joined.Error()))
+	})
+}
+
+func TestCatalogs(t *testing.T) {
+	assert.Equal(t, []string{"de", "en", "fr"}, Locales())
+	for name, catalog := range catalogs {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, len(catalogDE), len(catalog), "catalogs should translate the same messages")
+			for id, translation := range catalog {
+				reference, ok := catalogDE[id]
+				assert.True(t, ok, "message missing from the de catalog: %s", id)
+				assert.Equal(t, len(verbs.FindAllString(reference, -1)), len(verbs.FindAllString(translation, -1)), "translation should take the values of: %s", id)
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package logging
+
+import (
+	"errors"
+	"io"
+	"os"
+
+	logr "github.com/sirupsen/logrus"
+)
+
+// Log formats
+const (
+	FormatText = "text" // Readable lines, coloured on a terminal
+	FormatJSON = "json" // One JSON object per line, for IDE extensions and log collectors
+)
+
+// levels are the log levels that can be chosen, from the most to the least detailed
+var levels = map[string]logr.Level{
+	"trace": logr.TraceLevel,
+	"debug": logr.DebugLevel,
+	"info":  logr.InfoLevel,
+	"warn":  logr.WarnLevel,
+	"error": logr.ErrorLevel,
+	"fatal": logr.FatalLevel,
+}
+
+// Configure sets the level, format and destination of the logs of every package, which log through logrus. Logs are
+// written to stderr, so that they do not mix with command output, unless a file is given to append them to.
+func Configure(level string, format string, file string) error {
+	return configure(logr.StandardLogger(), level, format, file)
+}
+
+func configure(logger *logr.Logger, level string, format string, file string) error {
+	logLevel, ok := levels[level]
+	if !ok {
+		return errors.New("Unknown log level " + level + ", use trace, debug, info, warn, error or fatal")
+	}
+
+	var formatter logr.Formatter
+	switch format {
+	case FormatText, "":
+		formatter = &logr.TextFormatter{}
+	case FormatJSON:
+		formatter = &logr.JSONFormatter{}
+	default:
+		return errors.New("Unknown log format " + format + ", use text or json")
+	}
+
+	var output io.Writer = os.Stderr
+	if file != "" {
+		logFile, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return err
+		}
+		output = logFile
+	}
+
+	logger.SetLevel(logLevel)
+	logger.SetFormatter(formatter)
+	logger.SetOutput(output)
+	return nil
+}
@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package logging
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	logr "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigure(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logging")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("appends JSON logs at the level to a file", func(t *testing.T) {
+		logFile := filepath.Join(dir, "cwctl.log")
+		logger := logr.New()
+		assert.Nil(t, configure(logger, "warn", FormatJSON, logFile))
+
+		logger.Info("not logged")
+		logger.WithField("namespace", "codewind").Warn("Deployment is slow to start")
+		logger.Error("Deployment failed")
+
+		contents, err := ioutil.ReadFile(logFile)
+		assert.Nil(t, err)
+		lines := strings.Split(strings.TrimSpace(string(contents)), "\n")
+		assert.Len(t, lines, 2)
+		entry := map[string]string{}
+		assert.Nil(t, json.Unmarshal([]byte(lines[0]), &entry))
+		assert.Equal(t, "warning", entry["level"])
+		assert.Equal(t, "Deployment is slow to start", entry["msg"])
+		assert.Equal(t, "codewind", entry["namespace"])
+	})
+
+	t.Run("defaults to text on stderr", func(t *testing.T) {
+		logger := logr.New()
+		assert.Nil(t, configure(logger, "debug", "", ""))
+		assert.Equal(t, logr.DebugLevel, logger.Level)
+		assert.IsType(t, &logr.TextFormatter{}, logger.Formatter)
+		assert.Equal(t, os.Stderr, logger.Out)
+	})
+
+	t.Run("rejects unknown levels and formats", func(t *testing.T) {
+		assert.Error(t, configure(logr.New(), "verbose", FormatText, ""))
+		assert.Error(t, configure(logr.New(), "info", "xml", ""))
+	})
+}
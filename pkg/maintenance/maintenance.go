@@ -0,0 +1,29 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package maintenance
+
+import "net/http"
+
+// Header is set to true on the 503 responses a proxy in front of a remote Codewind answers with while it is in
+// maintenance mode, so that they are not retried
+const Header = "X-Codewind-Maintenance"
+
+// Announced reports whether a response says Codewind is in maintenance mode
+func Announced(res *http.Response) bool {
+	return res.StatusCode == http.StatusServiceUnavailable && res.Header.Get(Header) == "true"
+}
+
+// Unavailable reports whether a response says Codewind cannot take requests. While maintenance mode has scaled PFE
+// down, or PFE is restarting, the Gatekeeper answers requests for it with a 503.
+func Unavailable(res *http.Response) bool {
+	return res.StatusCode == http.StatusServiceUnavailable
+}
@@ -0,0 +1,33 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package maintenance
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResponses(t *testing.T) {
+	announced := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{Header: []string{"true"}}}
+	unavailable := &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}}
+	ok := &http.Response{StatusCode: http.StatusOK, Header: http.Header{Header: []string{"true"}}}
+
+	assert.True(t, Announced(announced))
+	assert.False(t, Announced(unavailable))
+	assert.False(t, Announced(ok))
+
+	assert.True(t, Unavailable(announced))
+	assert.True(t, Unavailable(unavailable))
+	assert.False(t, Unavailable(ok))
+}
@@ -0,0 +1,279 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package plugins
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Prefix is the start of the name of plugin executables found on the PATH, as in cwctl-registry-login
+const Prefix = "cwctl-"
+
+// Sources of plugins
+const (
+	SourcePath     = "path"
+	SourceManifest = "manifest"
+)
+
+type (
+	// Options : Where plugins are looked for
+	Options struct {
+		// Dir holds the plugin manifests, ~/.codewind/plugins by default
+		Dir string
+		// PathEnv is the list of directories searched for executables, the PATH by default
+		PathEnv string
+	}
+
+	// Manifest : A plugin declared in a YAML or JSON file in the plugins directory
+	Manifest struct {
+		Name    string            `yaml:"name" json:"name"`
+		Usage   string            `yaml:"usage,omitempty" json:"usage,omitempty"`
+		Command string            `yaml:"command" json:"command"`
+		Args    []string          `yaml:"args,omitempty" json:"args,omitempty"`
+		Env     map[string]string `yaml:"env,omitempty" json:"env,omitempty"`
+	}
+
+	// Plugin : A command added to cwctl, run as an executable with the arguments given after its name
+	Plugin struct {
+		Name   string            `json:"name"`
+		Usage  string            `json:"usage,omitempty"`
+		Path   string            `json:"path"`
+		Args   []string          `json:"args,omitempty"`
+		Env    map[string]string `json:"env,omitempty"`
+		Source string            `json:"source"`
+	}
+)
+
+// validName matches names that can be typed as a cwctl command
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]*$`)
+
+// Discover : Returns the plugins declared by manifests and the executables named cwctl-<name> on the PATH, ordered
+// by name, along with a description of each manifest that could not be read. A manifest takes precedence over an
+// executable of the same name, and an executable earlier on the PATH over a later one.
+func Discover(options Options) ([]Plugin, []string) {
+	found := map[string]Plugin{}
+	for _, plugin := range pathPlugins(options.PathEnv) {
+		if _, ok := found[plugin.Name]; !ok {
+			found[plugin.Name] = plugin
+		}
+	}
+	manifests, problems := manifestPlugins(options.Dir)
+	for _, plugin := range manifests {
+		found[plugin.Name] = plugin
+	}
+
+	plugins := []Plugin{}
+	for _, plugin := range found {
+		plugins = append(plugins, plugin)
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, problems
+}
+
+// Find : Returns the plugin of the given name, the same one Discover would. Only the files that could be the
+// executable of the plugin are looked up on the PATH, rather than every file of its directories.
+func Find(options Options, name string) (Plugin, bool) {
+	if !validName.MatchString(name) {
+		return Plugin{}, false
+	}
+	manifests, _ := manifestPlugins(options.Dir)
+	// The last manifest of the name is the one Discover keeps
+	for i := len(manifests) - 1; i >= 0; i-- {
+		if manifests[i].Name == name {
+			return manifests[i], true
+		}
+	}
+	for _, dir := range filepath.SplitList(options.PathEnv) {
+		if dir == "" {
+			continue
+		}
+		for _, fileName := range executableFileNames(Prefix + name) {
+			file, err := os.Lstat(filepath.Join(dir, fileName))
+			if err != nil {
+				continue
+			}
+			if runName, ok := executableName(file); ok && runName == Prefix+name {
+				return Plugin{Name: name, Path: filepath.Join(dir, file.Name()), Source: SourcePath}, true
+			}
+		}
+	}
+	return Plugin{}, false
+}
+
+// executableFileNames returns the names of the files that are run by a name, with each extension Windows runs
+func executableFileNames(name string) []string {
+	if runtime.GOOS != "windows" {
+		return []string{name}
+	}
+	fileNames := []string{}
+	for _, ext := range windowsExecutableExts() {
+		fileNames = append(fileNames, name+ext)
+	}
+	return fileNames
+}
+
+// windowsExecutableExts returns the extensions of the files Windows runs, in lower case
+func windowsExecutableExts() []string {
+	pathExt := os.Getenv("PATHEXT")
+	if pathExt == "" {
+		pathExt = ".com;.exe;.bat;.cmd"
+	}
+	return filepath.SplitList(strings.ToLower(pathExt))
+}
+
+// pathPlugins returns the executables named cwctl-<name> in the directories of a PATH, in the order they are found
+func pathPlugins(pathEnv string) []Plugin {
+	plugins := []Plugin{}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if dir == "" {
+			continue
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, file := range files {
+			name, ok := executableName(file)
+			if !ok || !strings.HasPrefix(name, Prefix) {
+				continue
+			}
+			name = strings.TrimPrefix(name, Prefix)
+			if !validName.MatchString(name) {
+				continue
+			}
+			plugins = append(plugins, Plugin{Name: name, Path: filepath.Join(dir, file.Name()), Source: SourcePath})
+		}
+	}
+	return plugins
+}
+
+// executableName returns the name a file is run by, without the extension Windows runs it by, if it can be run
+func executableName(file os.FileInfo) (string, bool) {
+	if !file.Mode().IsRegular() {
+		return "", false
+	}
+	if runtime.GOOS != "windows" {
+		return file.Name(), file.Mode()&0111 != 0
+	}
+	ext := strings.ToLower(filepath.Ext(file.Name()))
+	for _, runnable := range windowsExecutableExts() {
+		if ext != "" && ext == runnable {
+			return strings.TrimSuffix(file.Name(), filepath.Ext(file.Name())), true
+		}
+	}
+	return "", false
+}
+
+// manifestPlugins reads the .yaml, .yml and .json manifests of a directory. A command given as a relative path is
+// found in the directory.
+func manifestPlugins(dir string) ([]Plugin, []string) {
+	plugins := []Plugin{}
+	problems := []string{}
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			problems = append(problems, err.Error())
+		}
+		return plugins, problems
+	}
+	for _, file := range files {
+		switch strings.ToLower(filepath.Ext(file.Name())) {
+		case ".yaml", ".yml", ".json":
+		default:
+			continue
+		}
+		manifestPath := filepath.Join(dir, file.Name())
+		plugin, err := readManifest(manifestPath)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", manifestPath, err))
+			continue
+		}
+		plugins = append(plugins, plugin)
+	}
+	return plugins, problems
+}
+
+// readManifest reads the plugin declared by a manifest file
+func readManifest(manifestPath string) (Plugin, error) {
+	content, err := ioutil.ReadFile(manifestPath)
+	if err != nil {
+		return Plugin{}, err
+	}
+	manifest := Manifest{}
+	if err := yaml.UnmarshalStrict(content, &manifest); err != nil {
+		return Plugin{}, err
+	}
+	if !validName.MatchString(manifest.Name) {
+		return Plugin{}, fmt.Errorf("invalid name %q, names must only contain lower case letters, numbers, '_' and '-'", manifest.Name)
+	}
+	if manifest.Command == "" {
+		return Plugin{}, fmt.Errorf("no command given")
+	}
+	command := manifest.Command
+	if !filepath.IsAbs(command) && strings.ContainsAny(command, `/\`) {
+		command = filepath.Join(filepath.Dir(manifestPath), command)
+	}
+	return Plugin{
+		Name:   manifest.Name,
+		Usage:  manifest.Usage,
+		Path:   command,
+		Args:   manifest.Args,
+		Env:    manifest.Env,
+		Source: SourceManifest,
+	}, nil
+}
+
+// Run : Runs a plugin with the arguments given after its name, reading the input and writing errors to the terminal
+// of cwctl, and returns its exit code. The environment of cwctl is passed on, with the variables of its manifest and
+// the env given. cwctl leaves interrupts to the plugin while it runs.
+func Run(plugin Plugin, args []string, env map[string]string, stdout io.Writer) (int, error) {
+	cmd := exec.Command(plugin.Path, append(append([]string{}, plugin.Args...), args...)...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	cmd.Env = os.Environ()
+	for _, vars := range []map[string]string{plugin.Env, env} {
+		for name, value := range vars {
+			cmd.Env = append(cmd.Env, name+"="+value)
+		}
+	}
+
+	// The terminal sends interrupts to the plugin as well, which decides how to stop
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	defer signal.Stop(signals)
+
+	err := cmd.Run()
+	if exitErr, ok := err.(*exec.ExitError); ok {
+		// Plugins killed by a signal have no exit code
+		if exitErr.ExitCode() < 0 {
+			return 1, nil
+		}
+		return exitErr.ExitCode(), nil
+	}
+	if err != nil {
+		return 1, err
+	}
+	return 0, nil
+}
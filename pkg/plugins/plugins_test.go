@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package plugins
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// writeFile writes a file in a directory, creating the directory
+func writeFile(t *testing.T, dir string, name string, content string, mode os.FileMode) string {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(file, []byte(content), mode); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestDiscover(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins on the PATH are found by their extension on Windows")
+	}
+	root, err := ioutil.TempDir("", "cwctl-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	first := filepath.Join(root, "first")
+	second := filepath.Join(root, "second")
+	manifests := filepath.Join(root, "plugins")
+
+	login := writeFile(t, first, "cwctl-registry-login", "#!/bin/sh\n", 0755)
+	writeFile(t, second, "cwctl-registry-login", "#!/bin/sh\n", 0755)
+	writeFile(t, second, "cwctl-not-executable", "", 0644)
+	writeFile(t, second, "kubectl-other", "#!/bin/sh\n", 0755)
+	writeFile(t, second, "cwctl-deploy", "#!/bin/sh\n", 0755)
+	writeFile(t, manifests, "deploy.yaml", "name: deploy\nusage: Deploy to staging\ncommand: scripts/deploy.sh\nargs: [--env, staging]\n", 0644)
+	writeFile(t, manifests, "audit.json", `{"name": "audit", "command": "/usr/bin/audit", "env": {"AUDIT_LEVEL": "full"}}`, 0644)
+	writeFile(t, manifests, "broken.yaml", "name: Broken Name\ncommand: broken\n", 0644)
+	writeFile(t, manifests, "unknown.yml", "name: unknown\ncommand: unknown\nshell: bash\n", 0644)
+	writeFile(t, manifests, "README.md", "not a manifest", 0644)
+
+	found, problems := Discover(Options{Dir: manifests, PathEnv: first + string(os.PathListSeparator) + second})
+
+	assert.Equal(t, []Plugin{
+		{Name: "audit", Path: "/usr/bin/audit", Env: map[string]string{"AUDIT_LEVEL": "full"}, Source: SourceManifest},
+		{Name: "deploy", Usage: "Deploy to staging", Path: filepath.Join(manifests, "scripts", "deploy.sh"), Args: []string{"--env", "staging"}, Source: SourceManifest},
+		{Name: "registry-login", Path: login, Source: SourcePath},
+	}, found, "manifests should replace executables, and executables earlier on the PATH later ones")
+	if assert.Len(t, problems, 2) {
+		assert.Contains(t, problems[0], "broken.yaml")
+		assert.Contains(t, problems[1], "unknown.yml")
+	}
+}
+
+func TestFind(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("plugins on the PATH are found by their extension on Windows")
+	}
+	root, err := ioutil.TempDir("", "cwctl-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	first := filepath.Join(root, "first")
+	second := filepath.Join(root, "second")
+	manifests := filepath.Join(root, "plugins")
+
+	writeFile(t, first, "cwctl-not-executable", "", 0644)
+	login := writeFile(t, second, "cwctl-registry-login", "#!/bin/sh\n", 0755)
+	writeFile(t, second, "cwctl-deploy", "#!/bin/sh\n", 0755)
+	writeFile(t, manifests, "deploy.yaml", "name: deploy\ncommand: /usr/bin/deploy\n", 0644)
+	options := Options{Dir: manifests, PathEnv: first + string(os.PathListSeparator) + second}
+
+	plugin, ok := Find(options, "registry-login")
+	assert.True(t, ok)
+	assert.Equal(t, Plugin{Name: "registry-login", Path: login, Source: SourcePath}, plugin)
+
+	plugin, ok = Find(options, "deploy")
+	assert.True(t, ok)
+	assert.Equal(t, Plugin{Name: "deploy", Path: "/usr/bin/deploy", Source: SourceManifest}, plugin, "manifests should replace executables")
+
+	_, ok = Find(options, "not-executable")
+	assert.False(t, ok)
+	_, ok = Find(options, "missing")
+	assert.False(t, ok)
+	_, ok = Find(options, "../deploy")
+	assert.False(t, ok)
+}
+
+func TestDiscoverWithoutPluginsDir(t *testing.T) {
+	found, problems := Discover(Options{Dir: filepath.Join(os.TempDir(), "cwctl-no-plugins-here"), PathEnv: ""})
+	assert.Empty(t, found)
+	assert.Empty(t, problems)
+}
+
+func TestRun(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the plugin is a shell script")
+	}
+	dir, err := ioutil.TempDir("", "cwctl-plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	script := writeFile(t, dir, "cwctl-echo", "#!/bin/sh\necho \"$@ $GREETING $CWCTL_PLUGIN\"\nexit 3\n", 0755)
+
+	plugin := Plugin{Name: "echo", Path: script, Args: []string{"--from-manifest"}, Env: map[string]string{"GREETING": "hello"}}
+	var out bytes.Buffer
+	code, err := Run(plugin, []string{"a", "b"}, map[string]string{"CWCTL_PLUGIN": "echo"}, &out)
+	assert.Nil(t, err)
+	assert.Equal(t, 3, code)
+	assert.Equal(t, "--from-manifest a b hello echo\n", out.String())
+
+	code, err = Run(Plugin{Name: "missing", Path: filepath.Join(dir, "missing")}, nil, nil, &out)
+	assert.NotNil(t, err)
+	assert.Equal(t, 1, code)
+}
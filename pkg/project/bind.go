@@ -13,8 +13,8 @@ package project
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -23,6 +23,7 @@ import (
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
@@ -67,6 +68,9 @@ func BindProject(c *cli.Context) (*BindResponse, *ProjectError) {
 	language := strings.TrimSpace(c.String("language"))
 	buildType := strings.TrimSpace(c.String("type"))
 	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	if mirroredID := strings.TrimSpace(c.String("mirror")); mirroredID != "" {
+		return BindMirror(projectPath, name, language, buildType, conID, mirroredID)
+	}
 	return Bind(projectPath, name, language, buildType, conID)
 }
 
@@ -86,7 +90,7 @@ func Bind(projectPath string, name string, language string, projectType string,
 		Time:        creationTime,
 	}
 
-	client := &http.Client{}
+	client := sechttp.Client()
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
@@ -105,11 +109,16 @@ func Bind(projectPath string, name string, language string, projectType string,
 	}
 	projectID := projectInfo.ProjectID
 
-	// Sync all the project files
-	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, 0, conInfo)
+	// Sync all the project files, recording their states when later syncs poll the project for changes
+	strategy, _ := ResolveChangeDetection(ChangeDetectionAuto, projectPath)
+	detector := newChangeDetector(strategy, SyncSnapshotsDir(), projectID)
+	syncInfo, syncErr := syncFilesDetecting(context.Background(), sechttp.Client(), projectPath, projectID, conURL, 0, conInfo, detector)
 
 	// Call bind/end to complete
 	completeStatus, completeStatusCode := completeBind(client, projectID, conURL, conInfo)
+	if completeStatusCode == http.StatusOK {
+		detector.record(SyncSnapshotsDir(), projectID, syncInfo.UploadedFileList)
+	}
 	response := BindResponse{
 		ProjectID:     projectID,
 		UploadedFiles: syncInfo.UploadedFileList,
@@ -138,14 +147,14 @@ func bindToPFE(client utils.HTTPClient, bindRequest BindRequest, conInfo *connec
 
 	switch httpCode := resp.StatusCode; {
 	case httpCode == 400:
-		err := errors.New(textInvalidType)
+		err := i18n.Errorf(msgInvalidType, textInvalidType)
 		return nil, &ProjectError{errOpResponse, err, textInvalidType}
 	case httpCode == 404:
-		err := errors.New(textAPINotFound)
+		err := i18n.Errorf(msgAPINotFound, textAPINotFound)
 		return nil, &ProjectError{errOpResponse, err, textAPINotFound}
 	case httpCode == 409:
-		err := errors.New(textDupName)
-		return nil, &ProjectError{errOpResponse, err, textDupName}
+		err := i18n.Errorf(msgDupName, textDupName)
+		return nil, &ProjectError{errOpConflict, err, textDupName}
 	}
 	defer resp.Body.Close()
 	bodyBytes, err := ioutil.ReadAll(resp.Body)
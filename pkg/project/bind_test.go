@@ -14,7 +14,6 @@ package project
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
@@ -23,6 +22,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/security"
 )
 
@@ -78,17 +78,17 @@ func TestBindToPFE(t *testing.T) {
 		"Expect failure - API not found": {
 			bindRequest:      exampleBindRequest,
 			mockResponseCode: http.StatusNotFound,
-			wantedError:      ProjectError{errOpResponse, errors.New(textAPINotFound), textAPINotFound},
+			wantedError:      ProjectError{errOpResponse, i18n.Errorf(msgAPINotFound, textAPINotFound), textAPINotFound},
 		},
 		"Expect failure - bad request": {
 			bindRequest:      exampleBindRequest,
 			mockResponseCode: http.StatusBadRequest,
-			wantedError:      ProjectError{errOpResponse, errors.New(textInvalidType), textInvalidType},
+			wantedError:      ProjectError{errOpResponse, i18n.Errorf(msgInvalidType, textInvalidType), textInvalidType},
 		},
 		"Expect failure - duplicate name": {
 			bindRequest:      exampleBindRequest,
 			mockResponseCode: http.StatusConflict,
-			wantedError:      ProjectError{errOpResponse, errors.New(textDupName), textDupName},
+			wantedError:      ProjectError{errOpConflict, i18n.Errorf(msgDupName, textDupName), textDupName},
 		},
 	}
 
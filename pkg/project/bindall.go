@@ -0,0 +1,161 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+type (
+	// BindAllResult : The outcome of binding every project found in a directory
+	BindAllResult struct {
+		Status   string         `json:"status"`
+		Bound    int            `json:"bound"`
+		Failed   int            `json:"failed"`
+		Projects []BoundProject `json:"projects"`
+	}
+
+	// BoundProject : The outcome of binding one project found in a directory
+	BoundProject struct {
+		Path      string `json:"path"`
+		Name      string `json:"name"`
+		Language  string `json:"language"`
+		BuildType string `json:"projectType"`
+		ProjectID string `json:"projectID,omitempty"`
+		Status    string `json:"status"`
+		Error     string `json:"error,omitempty"`
+	}
+)
+
+// DefaultBindConcurrency is how many projects are bound and synced at the same time
+const DefaultBindConcurrency = 4
+
+// projectRootFiles mark the root directory of a project Codewind can build
+var projectRootFiles = []string{".cw-settings", "pom.xml", "package.json", "Package.swift", "Dockerfile"}
+
+// skippedDirs are never searched for projects, as they hold dependencies or build output
+var skippedDirs = []string{"node_modules", "target", "build", "dist", "vendor"}
+
+// invalidNameChars are removed from directory names to give project names
+var invalidNameChars = regexp.MustCompile("[^a-zA-Z0-9._-]")
+
+// bindProject binds a single project, replaced in tests
+var bindProject = Bind
+
+// FindProjectRoots : Returns the root directories of the projects in a directory, in order. A directory with a
+// .cw-settings file, a build file Codewind recognises, or matching a detection rule is a project root, and is not
+// searched any further. Hidden directories and directories of dependencies or build output are skipped.
+func FindProjectRoots(dir string) ([]string, *ProjectError) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	if !info.IsDir() {
+		err = errors.New(dir + " is not a directory")
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	rules := LoadDetectionRules()
+	roots := []string{}
+	var search func(dir string)
+	search = func(dir string) {
+		if isProjectRoot(dir, rules) {
+			roots = append(roots, dir)
+			return
+		}
+		files, err := ioutil.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, file := range files {
+			if !file.IsDir() || strings.HasPrefix(file.Name(), ".") || stringInSlice(file.Name(), skippedDirs) {
+				continue
+			}
+			search(filepath.Join(dir, file.Name()))
+		}
+	}
+	search(dir)
+	return roots, nil
+}
+
+func isProjectRoot(dir string, rules []DetectionRule) bool {
+	for _, file := range projectRootFiles {
+		if _, err := os.Stat(filepath.Join(dir, file)); err == nil {
+			return true
+		}
+	}
+	return matchDetectionRule(rules, dir) != nil
+}
+
+// projectNameFromDir : Returns the name a project is bound with, from the name of its directory
+func projectNameFromDir(dir string) string {
+	return strings.ToLower(invalidNameChars.ReplaceAllString(filepath.Base(dir), ""))
+}
+
+// BindAll : Binds every project found in a directory to a connection, detecting the language and build type of
+// each. Projects are bound, and their files synced, a few at a time. A project failing to bind does not stop the
+// others, and the outcome of each is reported in the order the projects were found.
+func BindAll(dir string, conID string, concurrency int) (*BindAllResult, *ProjectError) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	roots, projErr := FindProjectRoots(dir)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if concurrency < 1 {
+		concurrency = DefaultBindConcurrency
+	}
+
+	result := BindAllResult{Status: "OK", Projects: make([]BoundProject, len(roots))}
+	slots := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, root := range roots {
+		wg.Add(1)
+		go func(i int, root string) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			language, buildType := determineProjectInfo(root)
+			bound := BoundProject{Path: root, Name: projectNameFromDir(root), Language: language, BuildType: buildType, Status: "OK"}
+			response, projErr := bindProject(root, bound.Name, language, buildType, conID)
+			if response != nil {
+				bound.ProjectID = response.ProjectID
+			}
+			if projErr != nil {
+				bound.Status = "error"
+				bound.Error = projErr.Desc
+			}
+			result.Projects[i] = bound
+		}(i, root)
+	}
+	wg.Wait()
+
+	for _, bound := range result.Projects {
+		if bound.Status == "OK" {
+			result.Bound++
+		} else {
+			result.Failed++
+		}
+	}
+	if result.Failed > 0 {
+		result.Status = "error"
+	}
+	return &result, nil
+}
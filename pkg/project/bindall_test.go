@@ -0,0 +1,101 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeWorkspace(t *testing.T) string {
+	workspace, err := ioutil.TempDir("", "bind-all")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := []string{
+		"node app/package.json",
+		"node app/src/package.json",
+		"services/java/pom.xml",
+		"services/docker/Dockerfile",
+		"services/configured/.cw-settings",
+		"services/node_modules/dep/package.json",
+		".hidden/package.json",
+		"docs/README.md",
+	}
+	for _, file := range files {
+		os.MkdirAll(filepath.Join(workspace, filepath.Dir(file)), 0755)
+		ioutil.WriteFile(filepath.Join(workspace, file), []byte("{}"), 0644)
+	}
+	return workspace
+}
+
+func Test_FindProjectRoots(t *testing.T) {
+	workspace := writeWorkspace(t)
+	defer os.RemoveAll(workspace)
+
+	roots, projErr := FindProjectRoots(workspace)
+	assert.Nil(t, projErr)
+	assert.Equal(t, []string{
+		filepath.Join(workspace, "node app"),
+		filepath.Join(workspace, "services", "configured"),
+		filepath.Join(workspace, "services", "docker"),
+		filepath.Join(workspace, "services", "java"),
+	}, roots)
+
+	t.Run("error case - not a directory", func(t *testing.T) {
+		_, projErr := FindProjectRoots(filepath.Join(workspace, "docs", "README.md"))
+		assert.Equal(t, errBadPath, projErr.Op)
+	})
+}
+
+func Test_BindAll(t *testing.T) {
+	workspace := writeWorkspace(t)
+	defer os.RemoveAll(workspace)
+	defer func() { bindProject = Bind }()
+
+	var mutex sync.Mutex
+	bound := map[string]string{}
+	bindProject = func(projectPath string, name string, language string, projectType string, conID string) (*BindResponse, *ProjectError) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		bound[name] = language + "/" + projectType
+		if name == "docker" {
+			err := errors.New(textDupName)
+			return nil, &ProjectError{errOpConflict, err, textDupName}
+		}
+		return &BindResponse{ProjectID: name + "-id"}, nil
+	}
+
+	result, projErr := BindAll(workspace, "local", 2)
+	assert.Nil(t, projErr)
+	assert.Equal(t, "error", result.Status)
+	assert.Equal(t, 3, result.Bound)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, map[string]string{
+		"nodeapp":    "javascript/nodejs",
+		"configured": "unknown/docker",
+		"docker":     "unknown/docker",
+		"java":       "java/docker",
+	}, bound)
+	assert.Equal(t, BoundProject{
+		Path: filepath.Join(workspace, "node app"), Name: "nodeapp", Language: "javascript", BuildType: "nodejs", ProjectID: "nodeapp-id", Status: "OK",
+	}, result.Projects[0])
+	assert.Equal(t, BoundProject{
+		Path: filepath.Join(workspace, "services", "docker"), Name: "docker", Language: "unknown", BuildType: "docker", Status: "error", Error: textDupName,
+	}, result.Projects[2])
+}
@@ -0,0 +1,137 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// BuildParameters : The request to build a project
+	BuildParameters struct {
+		Action string `json:"action"`
+		Clean  bool   `json:"clean,omitempty"`
+	}
+
+	// BuildResult : The outcome of a build that was waited for
+	BuildResult struct {
+		ProjectID   string `json:"projectID"`
+		BuildStatus string `json:"buildStatus"`
+		LastBuild   int64  `json:"lastbuild"`
+	}
+)
+
+// Build states reported by PFE in the buildStatus of a project
+const (
+	BuildStatusSuccess = "success"
+	BuildStatusFailed  = "failed"
+)
+
+// DefaultBuildTimeout is how long a build is waited for
+const DefaultBuildTimeout = 20 * time.Minute
+
+// buildPollInterval is how often a project is checked for its build finishing
+var buildPollInterval = 2 * time.Second
+
+// buildActive reports whether a build of a project is waiting to start or running
+func buildActive(status string) bool {
+	return status == "queued" || status == "inProgress"
+}
+
+// BuildProject : Asks PFE to build a project now, rebuilding its image without the cache of earlier builds when clean
+func BuildProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, clean bool) *ProjectError {
+	payload, _ := json.Marshal(BuildParameters{Action: "build", Clean: clean})
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/build", bytes.NewBuffer(payload))
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	case http.StatusConflict:
+		respErr := i18n.Errorf(msgBuildConflict, textBuildConflict)
+		return &ProjectError{errOpConflict, respErr, textBuildConflict}
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	respErr := fmt.Errorf("Build request failed with status code %d: %s", resp.StatusCode, string(body))
+	return &ProjectError{errOpResponse, respErr, respErr.Error()}
+}
+
+// BuildAndWait : Builds a project and polls it until the build finishes, writing the output of its build logs to out
+// as they are written. Only the output of the new build is written. A failed build returns its result along with an
+// error, and a build that has not finished within the timeout returns an error.
+func BuildAndWait(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, clean bool, timeout time.Duration, out io.Writer) (*BuildResult, *ProjectError) {
+	before, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	// Skip the output of earlier builds
+	tail := newLogTail()
+	if projErr := tail.printNew(httpClient, conInfo, conURL, projectID, "build", ioutil.Discard); projErr != nil {
+		return nil, projErr
+	}
+	tail.lastLog = ""
+
+	if projErr := BuildProject(httpClient, conInfo, conURL, projectID, clean); projErr != nil {
+		return nil, projErr
+	}
+
+	deadline := time.Now().Add(timeout)
+	seenActive := false
+	for {
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		// The status is read before the logs, so the logs printed last include all of the output of a finished build
+		active := buildActive(project.BuildStatus)
+		finished := !active && (seenActive || project.LastBuild != before.LastBuild)
+		seenActive = seenActive || active
+		if projErr := tail.printNew(httpClient, conInfo, conURL, projectID, "build", out); projErr != nil {
+			return nil, projErr
+		}
+
+		if finished {
+			result := &BuildResult{ProjectID: projectID, BuildStatus: project.BuildStatus, LastBuild: project.LastBuild}
+			if project.BuildStatus != BuildStatusSuccess {
+				err := i18n.Errorf(msgBuildFailed, textBuildFailed, project.BuildStatus)
+				return result, &ProjectError{errOpBuildFailed, err, err.Error()}
+			}
+			return result, nil
+		}
+		if time.Now().After(deadline) {
+			err := i18n.Errorf(msgBuildTimeout, textBuildTimeout)
+			return nil, &ProjectError{errOpBuildTimeout, err, textBuildTimeout}
+		}
+		time.Sleep(buildPollInterval)
+	}
+}
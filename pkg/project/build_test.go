@@ -0,0 +1,85 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BuildProject(t *testing.T) {
+	t.Run("success case - build accepted", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{"POST /api/v1/projects/mockID/build": {{http.StatusAccepted, ""}}}}
+		err := BuildProject(mockClient, &mockConnection, "", "mockID", true)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error case - build already in progress", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{"POST /api/v1/projects/mockID/build": {{http.StatusConflict, ""}}}}
+		err := BuildProject(mockClient, &mockConnection, "", "mockID", false)
+		assert.Equal(t, errOpConflict, err.Op)
+	})
+}
+
+func Test_BuildAndWait(t *testing.T) {
+	buildPollInterval = time.Millisecond
+	projectPath := "GET /api/v1/projects/mockID/"
+	buildResponses := func(statuses ...mockResponse) map[string][]mockResponse {
+		return map[string][]mockResponse{
+			"POST /api/v1/projects/mockID/build": {{http.StatusAccepted, ""}},
+			projectPath:                          statuses,
+			"GET /api/v1/projects/mockID/logs":   {{http.StatusOK, `{"build":[{"logName":"docker.build"}],"app":[]}`}},
+			"GET /api/v1/projects/mockID/logs/build/docker.build": {
+				{http.StatusOK, "old build\n"},
+				{http.StatusOK, "old build\nstep 1\n"},
+				{http.StatusOK, "old build\nstep 1\nstep 2\n"},
+			},
+		}
+	}
+
+	t.Run("success case - prints the output of the new build until it finishes", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: buildResponses(
+			mockResponse{http.StatusOK, `{"buildStatus":"success","lastbuild":1}`},
+			mockResponse{http.StatusOK, `{"buildStatus":"inProgress","lastbuild":1}`},
+			mockResponse{http.StatusOK, `{"buildStatus":"success","lastbuild":2}`},
+		)}
+		out := &bytes.Buffer{}
+		result, err := BuildAndWait(mockClient, &mockConnection, "", "mockID", false, time.Second, out)
+		assert.Nil(t, err)
+		assert.Equal(t, BuildStatusSuccess, result.BuildStatus)
+		assert.Equal(t, int64(2), result.LastBuild)
+		assert.Equal(t, "==> build/docker.build <==\nstep 1\nstep 2\n", out.String())
+	})
+
+	t.Run("error case - build fails", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: buildResponses(
+			mockResponse{http.StatusOK, `{"buildStatus":"success","lastbuild":1}`},
+			mockResponse{http.StatusOK, `{"buildStatus":"failed","lastbuild":2}`},
+		)}
+		result, err := BuildAndWait(mockClient, &mockConnection, "", "mockID", false, time.Second, &bytes.Buffer{})
+		assert.Equal(t, errOpBuildFailed, err.Op)
+		assert.Equal(t, BuildStatusFailed, result.BuildStatus)
+	})
+
+	t.Run("error case - build does not finish in time", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: buildResponses(
+			mockResponse{http.StatusOK, `{"buildStatus":"success","lastbuild":1}`},
+			mockResponse{http.StatusOK, `{"buildStatus":"inProgress","lastbuild":1}`},
+		)}
+		_, err := BuildAndWait(mockClient, &mockConnection, "", "mockID", false, 10*time.Millisecond, &bytes.Buffer{})
+		assert.Equal(t, errOpBuildTimeout, err.Op)
+	})
+}
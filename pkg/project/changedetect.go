@@ -0,0 +1,172 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+
+	logr "github.com/sirupsen/logrus"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// Strategies for finding the files of a project changed since its last sync
+const (
+	// ChangeDetectionAuto polls projects on network file systems, and compares modification times otherwise
+	ChangeDetectionAuto = "auto"
+	// ChangeDetectionMTime uploads files modified after the time of the last sync
+	ChangeDetectionMTime = "mtime"
+	// ChangeDetectionPoll uploads files whose size or modification time differ from those recorded at the last sync.
+	// Only the local clock's view of each file is compared, so clock skew with a file server and coarse modification
+	// times do not hide changes.
+	ChangeDetectionPoll = "poll"
+)
+
+type (
+	// fileState is what polling compares between syncs to find changed files
+	fileState struct {
+		Size    int64 `json:"size"`
+		ModTime int64 `json:"modTime"`
+	}
+
+	// syncSnapshot holds the state of each file of a project at a sync, by path relative to the project
+	syncSnapshot map[string]fileState
+
+	// changeDetector decides which files a sync uploads, recording the state of every file it is asked about
+	changeDetector struct {
+		strategy string
+		previous syncSnapshot
+		current  syncSnapshot
+	}
+)
+
+// onNetworkFilesystem reports whether a path is on a network file system, such as NFS or SMB
+var onNetworkFilesystem = isNetworkFilesystem
+
+// ResolveChangeDetection : Returns the strategy a sync of a project path uses, choosing polling for auto when the
+// path is on a network file system
+func ResolveChangeDetection(strategy string, projectPath string) (string, *ProjectError) {
+	switch strategy {
+	case ChangeDetectionMTime, ChangeDetectionPoll:
+		return strategy, nil
+	case "", ChangeDetectionAuto:
+		if onNetworkFilesystem(projectPath) {
+			logr.Infof("%v is on a network file system, polling it for changes\n", projectPath)
+			return ChangeDetectionPoll, nil
+		}
+		return ChangeDetectionMTime, nil
+	}
+	err := i18n.Errorf(msgInvalidChangeDetection, textInvalidChangeDetection)
+	return "", &ProjectError{errOpInvalidOptions, err, textInvalidChangeDetection}
+}
+
+// SyncSnapshotsDir : Returns the directory the file states recorded by polling syncs are kept in
+func SyncSnapshotsDir() string {
+	return path.Join(getCodewindDir(), "sync-state")
+}
+
+// newChangeDetector returns the detector of a strategy, reading the states a polling sync recorded for the project
+func newChangeDetector(strategy string, snapshotsDir string, projectID string) *changeDetector {
+	detector := changeDetector{strategy: strategy, previous: syncSnapshot{}, current: syncSnapshot{}}
+	if strategy == ChangeDetectionPoll {
+		if contents, err := ioutil.ReadFile(path.Join(snapshotsDir, projectID+".json")); err == nil {
+			json.Unmarshal(contents, &detector.previous)
+		}
+	}
+	return &detector
+}
+
+// changed reports whether a file is uploaded. Every file is uploaded by a sync without a last sync time.
+func (detector *changeDetector) changed(relativePath string, info os.FileInfo, lastSync int64) bool {
+	state := fileState{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+	detector.current[relativePath] = state
+	if detector.strategy != ChangeDetectionPoll {
+		return state.ModTime/1000000 > lastSync
+	}
+	if lastSync == 0 {
+		return true
+	}
+	previous, found := detector.previous[relativePath]
+	return !found || previous != state
+}
+
+// record keeps the states of a completed polling sync for the next one. Files that failed to upload are left out,
+// so that the next sync uploads them again. Failing to keep the states does not fail the sync.
+func (detector *changeDetector) record(snapshotsDir string, projectID string, uploaded []UploadedFile) {
+	if detector.strategy != ChangeDetectionPoll {
+		return
+	}
+	for _, file := range uploaded {
+		if file.StatusCode != 200 {
+			delete(detector.current, file.FilePath)
+		}
+	}
+	contents, err := json.Marshal(detector.current)
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(snapshotsDir, 0755) == nil {
+		ioutil.WriteFile(path.Join(snapshotsDir, projectID+".json"), contents, 0644)
+	}
+}
+
+// scanProject returns the state of each file of a project that is synced, to find changes between polls without
+// uploading anything
+func scanProject(projectPath string) syncSnapshot {
+	snapshot := syncSnapshot{}
+	ignoredPaths := retrieveIgnoredPathsList(projectPath)
+	for _, refPath := range retrieveRefPathsList(projectPath) {
+		ignoredPaths = append(ignoredPaths, refPath.To)
+		from := refPath.From
+		if !filepath.IsAbs(from) {
+			from = filepath.Join(projectPath, from)
+		}
+		if info, err := os.Stat(longPath(from)); err == nil && !info.IsDir() {
+			snapshot[refPath.To] = fileState{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+	}
+	walkRoot := longPath(projectPath)
+	filepath.Walk(walkRoot, func(walkPath string, info os.FileInfo, err error) error {
+		if err != nil || walkPath == walkRoot {
+			return nil
+		}
+		relativePath := filepath.ToSlash(walkPath[len(walkRoot)+1:])
+		if ignoreFileOrDirectory(relativePath, info.IsDir(), ignoredPaths) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() {
+			snapshot[relativePath] = fileState{Size: info.Size(), ModTime: info.ModTime().UnixNano()}
+		}
+		return nil
+	})
+	return snapshot
+}
+
+// equal reports whether two scans of a project found the same files in the same states
+func (snapshot syncSnapshot) equal(other syncSnapshot) bool {
+	if len(snapshot) != len(other) {
+		return false
+	}
+	for relativePath, state := range snapshot {
+		if otherState, found := other[relativePath]; !found || otherState != state {
+			return false
+		}
+	}
+	return true
+}
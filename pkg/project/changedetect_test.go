@@ -0,0 +1,173 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveChangeDetection(t *testing.T) {
+	defer func(original func(string) bool) { onNetworkFilesystem = original }(onNetworkFilesystem)
+	networked := false
+	onNetworkFilesystem = func(string) bool { return networked }
+
+	t.Run("success case - auto compares modification times of local projects", func(t *testing.T) {
+		strategy, projErr := ResolveChangeDetection("", "project")
+		assert.Nil(t, projErr)
+		assert.Equal(t, ChangeDetectionMTime, strategy)
+	})
+
+	t.Run("success case - auto polls projects on network file systems", func(t *testing.T) {
+		networked = true
+		strategy, _ := ResolveChangeDetection(ChangeDetectionAuto, "project")
+		assert.Equal(t, ChangeDetectionPoll, strategy)
+		strategy, _ = ResolveChangeDetection(ChangeDetectionMTime, "project")
+		assert.Equal(t, ChangeDetectionMTime, strategy)
+	})
+
+	t.Run("error case - unknown strategy", func(t *testing.T) {
+		_, projErr := ResolveChangeDetection("inotify", "project")
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+	})
+}
+
+func TestPollChangeDetection(t *testing.T) {
+	projectPath, _ := ioutil.TempDir("", "poll-project")
+	snapshotsDir, _ := ioutil.TempDir("", "sync-state")
+	defer os.RemoveAll(projectPath)
+	defer os.RemoveAll(snapshotsDir)
+	mockConnection := connections.Connection{ID: "local"}
+	mockClient := func(statusCode int) *security.ClientMockAuthenticate {
+		return &security.ClientMockAuthenticate{StatusCode: statusCode, Body: ioutil.NopCloser(bytes.NewReader([]byte{}))}
+	}
+	sync := func(statusCode int, lastSync int64) *SyncInfo {
+		detector := newChangeDetector(ChangeDetectionPoll, snapshotsDir, "mockID")
+		syncInfo, _ := syncFilesDetecting(context.Background(), mockClient(statusCode), projectPath, "mockID", "dummyURL", lastSync, &mockConnection, detector)
+		detector.record(snapshotsDir, "mockID", syncInfo.UploadedFileList)
+		return syncInfo
+	}
+
+	ioutil.WriteFile(filepath.Join(projectPath, "changed"), []byte("before"), 0644)
+	ioutil.WriteFile(filepath.Join(projectPath, "unchanged"), []byte("same"), 0644)
+	lastSync := time.Now().UnixNano() / 1000000
+
+	t.Run("success case - a sync without a last sync time uploads every file", func(t *testing.T) {
+		assert.Equal(t, []string{"changed", "unchanged"}, sync(http.StatusOK, 0).modifiedList)
+	})
+
+	t.Run("success case - changes with modification times before the last sync are uploaded", func(t *testing.T) {
+		// A file server whose clock is behind gives changed files modification times before the last sync
+		ioutil.WriteFile(filepath.Join(projectPath, "changed"), []byte("after the sync"), 0644)
+		skewed := time.Now().Add(-time.Hour)
+		os.Chtimes(filepath.Join(projectPath, "changed"), skewed, skewed)
+
+		mtimeInfo, _ := syncFiles(context.Background(), mockClient(http.StatusOK), projectPath, "mockID", "dummyURL", lastSync, &mockConnection)
+		assert.Empty(t, mtimeInfo.modifiedList)
+		assert.Equal(t, []string{"changed"}, sync(http.StatusOK, lastSync).modifiedList)
+		assert.Empty(t, sync(http.StatusOK, lastSync).modifiedList)
+	})
+
+	t.Run("success case - files that failed to upload are uploaded again", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, "added"), []byte{}, 0644)
+		assert.Equal(t, []string{"added"}, sync(http.StatusInternalServerError, lastSync).modifiedList)
+		assert.Equal(t, []string{"added"}, sync(http.StatusOK, lastSync).modifiedList)
+	})
+}
+
+func TestScanProject(t *testing.T) {
+	projectPath, _ := ioutil.TempDir("", "scan-project")
+	defer os.RemoveAll(projectPath)
+	os.Mkdir(filepath.Join(projectPath, "src"), 0755)
+	os.Mkdir(filepath.Join(projectPath, ".vscode"), 0755)
+	ioutil.WriteFile(filepath.Join(projectPath, "src", "app.js"), []byte("app"), 0644)
+	ioutil.WriteFile(filepath.Join(projectPath, ".vscode", "settings.json"), []byte("{}"), 0644)
+
+	scanned := scanProject(projectPath)
+	assert.Equal(t, []string{"src/app.js"}, snapshotPaths(scanned))
+	assert.True(t, scanned.equal(scanProject(projectPath)))
+
+	ioutil.WriteFile(filepath.Join(projectPath, "src", "app.js"), []byte("changed app"), 0644)
+	assert.False(t, scanned.equal(scanProject(projectPath)))
+}
+
+func snapshotPaths(snapshot syncSnapshot) []string {
+	paths := []string{}
+	for relativePath := range snapshot {
+		paths = append(paths, relativePath)
+	}
+	return paths
+}
+
+func TestWatchSync(t *testing.T) {
+	defer func(original func(context.Context, SyncOptions) (*SyncResponse, *ProjectError)) { watchSync = original }(watchSync)
+	projectPath, _ := ioutil.TempDir("", "watch-project")
+	defer os.RemoveAll(projectPath)
+	ioutil.WriteFile(filepath.Join(projectPath, "app.js"), []byte("app"), 0644)
+
+	t.Run("success case - syncs once, then again when a file changes", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		lastSyncs := []int64{}
+		modifiedSince := []time.Time{}
+		watchSync = func(ctx context.Context, options SyncOptions) (*SyncResponse, *ProjectError) {
+			lastSyncs = append(lastSyncs, options.LastSync)
+			modifiedSince = append(modifiedSince, options.ModifiedSince)
+			return &SyncResponse{StatusCode: http.StatusOK}, nil
+		}
+		synced := func(*SyncResponse, *ProjectError) {
+			switch len(lastSyncs) {
+			case 1:
+				ioutil.WriteFile(filepath.Join(projectPath, "app.js"), []byte("changed app"), 0644)
+			case 2:
+				cancel()
+			}
+		}
+		repairFrom := time.Now().Add(-time.Hour)
+		projErr := WatchSync(ctx, SyncOptions{Path: projectPath, ChangeDetection: ChangeDetectionPoll, ModifiedSince: repairFrom}, 10*time.Millisecond, synced)
+		assert.Nil(t, projErr)
+		assert.Len(t, lastSyncs, 2)
+		assert.Equal(t, int64(0), lastSyncs[0])
+		assert.NotEqual(t, int64(0), lastSyncs[1])
+		assert.Equal(t, []time.Time{repairFrom, {}}, modifiedSince)
+	})
+
+	t.Run("success case - failed syncs are tried again", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		syncs := 0
+		watchSync = func(ctx context.Context, options SyncOptions) (*SyncResponse, *ProjectError) {
+			syncs++
+			if syncs == 3 {
+				cancel()
+			}
+			return &SyncResponse{StatusCode: http.StatusServiceUnavailable}, nil
+		}
+		WatchSync(ctx, SyncOptions{Path: projectPath}, 10*time.Millisecond, func(*SyncResponse, *ProjectError) {})
+		assert.Equal(t, 3, syncs)
+	})
+
+	t.Run("error case - interval must be positive", func(t *testing.T) {
+		projErr := WatchSync(context.Background(), SyncOptions{Path: projectPath}, 0, nil)
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+	})
+}
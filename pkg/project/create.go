@@ -13,10 +13,7 @@ package project
 
 import (
 	"encoding/json"
-	"errors"
 	"io/ioutil"
-	"log"
-	"net/http"
 	"os"
 	"path"
 	"path/filepath"
@@ -27,7 +24,10 @@ import (
 	"github.com/eclipse/codewind-installer/pkg/connections"
 
 	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
@@ -47,6 +47,7 @@ type (
 		InternalDebugPort *string  `json:"internalDebugPort,omitempty"`
 		IsHTTPS           bool     `json:"isHttps"`
 		IgnoredPaths      []string `json:"ignoredPaths"`
+		IgnorePreset      string   `json:"ignorePreset,omitempty"`
 		MavenProfiles     []string `json:"mavenProfiles,omitempty"`
 		MavenProperties   []string `json:"mavenProperties,omitempty"`
 		StatusPingTimeout string   `json:"statusPingTimeout"`
@@ -55,6 +56,24 @@ type (
 
 // DownloadTemplate using the url/link provided
 func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredentials) (*Result, *ProjectError) {
+	return downloadTemplate(destination, func() error {
+		return utils.DownloadFromURLThenExtract(url, destination, gitCredentials)
+	})
+}
+
+// DownloadTemplateFromGit clones the git repository at the url provided, which can be an SSH URL, checking out the
+// branch, tag or commit given in the options. The history of the template is not kept, as for downloaded templates.
+func DownloadTemplateFromGit(destination, url string, options utils.GitCloneOptions) (*Result, *ProjectError) {
+	return downloadTemplate(destination, func() error {
+		if err := utils.GitClone(url, destination, options); err != nil {
+			return err
+		}
+		return os.RemoveAll(filepath.Join(destination, ".git"))
+	})
+}
+
+// downloadTemplate fetches a template into an empty destination, then names the project after the destination
+func downloadTemplate(destination string, fetch func() error) (*Result, *ProjectError) {
 	projErr := checkProjectDirIsEmpty(destination)
 	if projErr != nil {
 		return nil, projErr
@@ -70,11 +89,11 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 		projectName = "PROJ_NAME_PLACEHOLDER"
 	}
 
-	err := utils.DownloadFromURLThenExtract(url, destination, gitCredentials)
+	err := fetch()
 	if err != nil {
 		errOp := errOpCreateProject
 		// if 401 error, use invalid credentials error code
-		if strings.Contains(err.Error(), "401 Unauthorized") {
+		if strings.Contains(err.Error(), "401 Unauthorized") || strings.Contains(err.Error(), "Authentication failed") {
 			errOp = errOpInvalidCredentials
 		}
 		return nil, &ProjectError{errOp, err, err.Error()}
@@ -89,11 +108,12 @@ func DownloadTemplate(destination, url string, gitCredentials *utils.GitCredenti
 	return &response, nil
 }
 
-// checkIsExtension checks if a project is an extension project and run associated commands as necessary
-func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error) {
+// checkIsExtension checks if a project is an extension project and run associated commands as necessary. A type hint
+// of the form type:subtype picks the extension instead of detecting it.
+func checkIsExtension(conID, projectPath string, typeHint string) (string, error) {
 	extensions, err := apiroutes.GetExtensions(conID)
 	if err != nil {
-		log.Println("There was a problem retrieving extensions data")
+		logr.Warnln("There was a problem retrieving extensions data")
 		return "unknown", err
 	}
 
@@ -101,9 +121,8 @@ func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error)
 	commandName := "postProjectValidate"
 
 	// determine if type:subtype hint was given
-	// but only if url was not given
-	if c.String("u") == "" && c.String("t") != "" {
-		parts := strings.Split(c.String("t"), ":")
+	if typeHint != "" {
+		parts := strings.Split(typeHint, ":")
 		params["$type"] = parts[0]
 		if len(parts) > 1 {
 			params["$subtype"] = parts[1]
@@ -139,11 +158,28 @@ func checkIsExtension(conID, projectPath string, c *cli.Context) (string, error)
 	return "", nil
 }
 
-// ValidateProject returns the language and buildType for a project at given filesystem path,
-// and writes a default .cw-settings file to that project
+// ValidateOptions : The project to validate
+type ValidateOptions struct {
+	Path  string
+	ConID string
+	// TypeHint is the type, or type:subtype, of an extension project, found from the project files when empty
+	TypeHint string
+}
+
+// ValidateProject validates the project given by the --path, --conid and --type flags, see Validate
 func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
-	projectPath := c.String("path")
-	conID := strings.TrimSpace(strings.ToLower(c.String("conid")))
+	return Validate(ValidateOptions{
+		Path:     c.String("path"),
+		ConID:    strings.TrimSpace(strings.ToLower(c.String("conid"))),
+		TypeHint: c.String("type"),
+	})
+}
+
+// Validate returns the language and buildType for a project at given filesystem path,
+// and writes a default .cw-settings file to that project
+func Validate(options ValidateOptions) (*ValidationResponse, *ProjectError) {
+	projectPath := options.Path
+	conID := options.ConID
 	projErr := checkProjectPathExists(projectPath)
 	if projErr != nil {
 		return nil, projErr
@@ -158,7 +194,7 @@ func ValidateProject(c *cli.Context) (*ValidationResponse, *ProjectError) {
 		BuildType: buildType,
 	}
 
-	extensionType, err := checkIsExtension(conID, projectPath, c)
+	extensionType, err := checkIsExtension(conID, projectPath, options.TypeHint)
 	if extensionType != "" {
 		if err == nil {
 			validationResult = ProjectType{
@@ -209,7 +245,7 @@ func writeCwSettingsIfNotInProject(conID string, projectPath string, BuildType s
 			return projErr
 		}
 	} else if _, err := os.Stat(pathToCwSettings); os.IsNotExist(err) {
-		projErr := writeNewCwSettings(&http.Client{}, connection, conURL, pathToCwSettings, BuildType)
+		projErr := writeNewCwSettings(sechttp.Client(), connection, conURL, pathToCwSettings, BuildType)
 		if projErr != nil {
 			return projErr
 		}
@@ -220,7 +256,7 @@ func writeCwSettingsIfNotInProject(conID string, projectPath string, BuildType s
 // checkProjectDirIsEmpty return a project error if the given local filepath already exists, or is an empty string
 func checkProjectDirIsEmpty(projectPath string) *ProjectError {
 	if projectPath == "" {
-		err := errors.New(textNoProjectPath)
+		err := i18n.Errorf(msgNoProjectPath, textNoProjectPath)
 		return &ProjectError{errOpCreateProject, err, err.Error()}
 	}
 
@@ -231,7 +267,7 @@ func checkProjectDirIsEmpty(projectPath string) *ProjectError {
 			return &ProjectError{errOpCreateProject, err, err.Error()}
 		}
 		if !dirIsEmpty {
-			projErr := errors.New(textProjectPathNonEmpty)
+			projErr := i18n.Errorf(msgProjectPathNonEmpty, textProjectPathNonEmpty)
 			return &ProjectError{errOpCreateProject, projErr, projErr.Error()}
 		}
 	}
@@ -241,20 +277,33 @@ func checkProjectDirIsEmpty(projectPath string) *ProjectError {
 // checkProjectPathExists returns a project error if the given local filepath does not exist, or is an empty string
 func checkProjectPathExists(projectPath string) *ProjectError {
 	if projectPath == "" {
-		err := errors.New(textNoProjectPath)
+		err := i18n.Errorf(msgNoProjectPath, textNoProjectPath)
 		return &ProjectError{errOpCreateProject, err, err.Error()}
 	}
 	if !utils.PathExists(projectPath) {
-		err := errors.New(textProjectPathDoesNotExist)
+		err := i18n.Errorf(msgProjectPathDoesNotExist, textProjectPathDoesNotExist)
 		return &ProjectError{errOpCreateProject, err, err.Error()}
 	}
 	return nil
 }
 
-// determineProjectInfo returns the language and build-type of a project
+// DetectProjectType returns the language and build type Codewind would use for the project at a filesystem path
+func DetectProjectType(projectPath string) ProjectType {
+	language, buildType := determineProjectInfo(projectPath)
+	return ProjectType{Language: language, BuildType: buildType}
+}
+
+// determineProjectInfo returns the language and build-type of a project.
+// Detection rules installed as extensions are checked first, so they can recognise stacks Codewind does not know
+// about and override the built-in detection.
 func determineProjectInfo(projectPath string) (string, string) {
 	language, buildType := "unknown", "docker"
-	if utils.PathExists(path.Join(projectPath, "pom.xml")) {
+	if rule := matchDetectionRule(LoadDetectionRules(), projectPath); rule != nil {
+		language = rule.Language
+		if rule.BuildType != "" {
+			buildType = rule.BuildType
+		}
+	} else if utils.PathExists(path.Join(projectPath, "pom.xml")) {
 		language = "java"
 		buildType = determineJavaBuildType(projectPath)
 	} else if utils.PathExists(path.Join(projectPath, "package.json")) {
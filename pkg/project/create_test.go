@@ -14,7 +14,6 @@ package project
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"log"
 	"net/http"
@@ -24,6 +23,7 @@ import (
 	"testing"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/eclipse/codewind-installer/pkg/test"
 	"github.com/eclipse/codewind-installer/pkg/utils"
@@ -292,8 +292,8 @@ func TestWriteNewCwSettings(t *testing.T) {
 }
 
 func TestProjectPathExists(t *testing.T) {
-	errNoPath := errors.New(textNoProjectPath)
-	errNoProject := errors.New(textProjectPathDoesNotExist)
+	errNoPath := i18n.Errorf(msgNoProjectPath, textNoProjectPath)
+	errNoProject := i18n.Errorf(msgProjectPathDoesNotExist, textProjectPathDoesNotExist)
 	tests := map[string]struct {
 		path      string
 		wantError *ProjectError
@@ -320,8 +320,8 @@ func TestProjectPathExists(t *testing.T) {
 }
 
 func TestCheckProjectDirIsEmpty(t *testing.T) {
-	errNoPath := errors.New(textNoProjectPath)
-	errProjectNonEmpty := errors.New(textProjectPathNonEmpty)
+	errNoPath := i18n.Errorf(msgNoProjectPath, textNoProjectPath)
+	errProjectNonEmpty := i18n.Errorf(msgProjectPathNonEmpty, textProjectPathNonEmpty)
 
 	testFolder := "check_project_dir_empty_folder_delete_me"
 	os.Mkdir(testFolder, 0777)
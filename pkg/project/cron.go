@@ -0,0 +1,145 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// cronSchedule : The minutes, hours, days of the month, months and days of the week a cron schedule runs at
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays []bool
+	// A day matches either a restricted day of the month or a restricted day of the week, as in cron
+	anyDay, anyWeekday bool
+}
+
+// cronMacros are the schedules Kubernetes CronJobs accept in place of the five fields
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// parseCronSchedule parses the minute, hour, day of month, month and day of week fields of a cron schedule. Each
+// field is *, or a list of values and ranges such as 1,15 or 9-17, any of them with a step such as */15 or 8-18/2.
+func parseCronSchedule(schedule string) (*cronSchedule, error) {
+	if macro, found := cronMacros[strings.TrimSpace(schedule)]; found {
+		schedule = macro
+	}
+	fields := strings.Fields(schedule)
+	if len(fields) != 5 {
+		return nil, i18n.Errorf(msgInvalidCronSchedule, textInvalidCronSchedule)
+	}
+	limits := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	parsed := [5][]bool{}
+	for i, field := range fields {
+		values, err := parseCronField(field, limits[i][0], limits[i][1])
+		if err != nil {
+			return nil, err
+		}
+		parsed[i] = values
+	}
+	// Sunday is both 0 and 7
+	parsed[4][0] = parsed[4][0] || parsed[4][7]
+	return &cronSchedule{
+		minutes:    parsed[0],
+		hours:      parsed[1],
+		days:       parsed[2],
+		months:     parsed[3],
+		weekdays:   parsed[4],
+		anyDay:     strings.HasPrefix(fields[2], "*"),
+		anyWeekday: strings.HasPrefix(fields[4], "*"),
+	}, nil
+}
+
+// parseCronField returns which values from 0 to max a field of a cron schedule matches
+func parseCronField(field string, min int, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		step := 1
+		if slash := strings.Index(part, "/"); slash >= 0 {
+			parsedStep, err := strconv.Atoi(part[slash+1:])
+			if err != nil || parsedStep < 1 {
+				return nil, i18n.Errorf(msgInvalidCronSchedule, textInvalidCronSchedule)
+			}
+			step, part = parsedStep, part[:slash]
+		}
+		first, last := min, max
+		if part != "*" {
+			bounds := strings.SplitN(part, "-", 2)
+			var err error
+			if first, err = strconv.Atoi(bounds[0]); err != nil {
+				return nil, i18n.Errorf(msgInvalidCronSchedule, textInvalidCronSchedule)
+			}
+			last = first
+			if len(bounds) == 2 {
+				if last, err = strconv.Atoi(bounds[1]); err != nil {
+					return nil, i18n.Errorf(msgInvalidCronSchedule, textInvalidCronSchedule)
+				}
+			} else if step > 1 {
+				last = max
+			}
+		}
+		if first < min || last > max || first > last {
+			return nil, i18n.Errorf(msgInvalidCronSchedule, textInvalidCronSchedule)
+		}
+		for value := first; value <= last; value += step {
+			values[value] = true
+		}
+	}
+	return values, nil
+}
+
+// next returns the first time after the given time that the schedule runs at, or the zero time if it never runs,
+// such as on the 30th of February
+func (schedule *cronSchedule) next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// Every schedule that runs at all runs within 5 years, which covers the 29th of February
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		switch {
+		case !schedule.months[t.Month()]:
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+		case !schedule.dayMatches(t):
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+		case !schedule.hours[t.Hour()]:
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+		case !schedule.minutes[t.Minute()]:
+			t = t.Add(time.Minute)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches reports whether the schedule runs on the day of a time
+func (schedule *cronSchedule) dayMatches(t time.Time) bool {
+	day, weekday := schedule.days[t.Day()], schedule.weekdays[t.Weekday()]
+	switch {
+	case schedule.anyDay && schedule.anyWeekday:
+		return true
+	case schedule.anyDay:
+		return weekday
+	case schedule.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,55 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCronScheduleNext(t *testing.T) {
+	// A Wednesday
+	after := time.Date(2020, time.April, 15, 10, 30, 0, 0, time.UTC)
+	tests := map[string]struct {
+		schedule string
+		want     time.Time
+	}{
+		"every minute":              {"* * * * *", time.Date(2020, time.April, 15, 10, 31, 0, 0, time.UTC)},
+		"steps":                     {"*/20 * * * *", time.Date(2020, time.April, 15, 10, 40, 0, 0, time.UTC)},
+		"ranges with steps":         {"0 8-18/4 * * *", time.Date(2020, time.April, 15, 12, 0, 0, 0, time.UTC)},
+		"lists":                     {"15,45 9,10 * * *", time.Date(2020, time.April, 15, 10, 45, 0, 0, time.UTC)},
+		"macro":                     {"@daily", time.Date(2020, time.April, 16, 0, 0, 0, 0, time.UTC)},
+		"day of week 7 is Sunday":   {"0 2 * * 7", time.Date(2020, time.April, 19, 2, 0, 0, 0, time.UTC)},
+		"day of month or week":      {"0 0 1 * 5", time.Date(2020, time.April, 17, 0, 0, 0, 0, time.UTC)},
+		"next year":                 {"0 0 1 1 *", time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)},
+		"29th of February":          {"0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		"30th of February never is": {"0 0 30 2 *", time.Time{}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			schedule, err := parseCronSchedule(test.schedule)
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, schedule.next(after))
+		})
+	}
+}
+
+func TestParseCronScheduleInvalid(t *testing.T) {
+	for _, schedule := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *", "@often"} {
+		t.Run(schedule, func(t *testing.T) {
+			_, err := parseCronSchedule(schedule)
+			assert.EqualError(t, err, textInvalidCronSchedule)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// PortTarget : Where a port of a project is reached. Local projects expose their ports on the host, and remote
+	// projects are reached through their pod.
+	PortTarget struct {
+		Host      string
+		Port      int
+		PodName   string
+		Namespace string
+	}
+
+	// DebugResult : The address an IDE attaches its debugger to
+	DebugResult struct {
+		Status        string `json:"status"`
+		StatusMessage string `json:"status_message"`
+		ProjectID     string `json:"projectID"`
+		DebugAddress  string `json:"debugAddress"`
+	}
+)
+
+// DefaultDebugTimeout is how long a restart waits for a project to start in debug mode
+const DefaultDebugTimeout = 2 * time.Minute
+
+// debugPollInterval is how often PFE is asked whether a project has started in debug mode
+var debugPollInterval = time.Second
+
+// IsDebugStartMode : Reports whether a start mode starts the project with a debugger
+func IsDebugStartMode(startMode string) bool {
+	return startMode == "debug" || startMode == "debugNoInit"
+}
+
+// WaitForDebugTarget : Polls PFE until a project restarted in a debug start mode reports its debug port, or returns
+// an error after the timeout. Projects of remote connections must also report the pod they run in.
+func WaitForDebugTarget(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, timeout time.Duration) (*PortTarget, *ProjectError) {
+	deadline := time.Now().Add(timeout)
+	for {
+		project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		if target := debugTargetOf(project, conInfo.ID != "local"); target != nil {
+			return target, nil
+		}
+		if time.Now().After(deadline) {
+			err := i18n.Errorf(msgDebugTimeout, textDebugTimeout)
+			return nil, &ProjectError{errOpDebugTimeout, err, textDebugTimeout}
+		}
+		time.Sleep(debugPollInterval)
+	}
+}
+
+// debugTargetOf : Returns where the debugger of a project listens, or nil while it is not starting in debug mode
+func debugTargetOf(project *Project, remote bool) *PortTarget {
+	if !IsDebugStartMode(project.StartMode) || project.Ports == nil {
+		return nil
+	}
+	if status := strings.ToLower(project.AppStatus); status != "starting" && status != "started" {
+		return nil
+	}
+	if remote {
+		port, err := strconv.Atoi(project.Ports.InternalDebugPort)
+		if err != nil || project.PodName == "" || project.Namespace == "" {
+			return nil
+		}
+		return &PortTarget{PodName: project.PodName, Namespace: project.Namespace, Port: port}
+	}
+	port, err := strconv.Atoi(project.Ports.ExposedDebugPort)
+	if err != nil {
+		return nil
+	}
+	return &PortTarget{Host: "localhost", Port: port}
+}
@@ -0,0 +1,95 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockProjectSequence returns each project in turn, then the last one
+type mockProjectSequence struct {
+	projects []string
+	requests int
+}
+
+func (c *mockProjectSequence) Do(req *http.Request) (*http.Response, error) {
+	body := c.projects[len(c.projects)-1]
+	if c.requests < len(c.projects) {
+		body = c.projects[c.requests]
+	}
+	c.requests++
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewBufferString(body))}, nil
+}
+
+func Test_DebugTargetOf(t *testing.T) {
+	debugPorts := &Ports{InternalDebugPort: "9229", ExposedDebugPort: "32768"}
+	tests := map[string]struct {
+		project Project
+		remote  bool
+		want    *PortTarget
+	}{
+		"local project exposes its debug port": {
+			project: Project{StartMode: "debug", AppStatus: "starting", Ports: debugPorts},
+			want:    &PortTarget{Host: "localhost", Port: 32768},
+		},
+		"remote project is reached through its pod": {
+			project: Project{StartMode: "debugNoInit", AppStatus: "started", Ports: debugPorts, PodName: "pod", Namespace: "ns"},
+			remote:  true,
+			want:    &PortTarget{PodName: "pod", Namespace: "ns", Port: 9229},
+		},
+		"project still running without a debugger": {
+			project: Project{StartMode: "run", AppStatus: "started", Ports: debugPorts},
+		},
+		"project still stopping": {
+			project: Project{StartMode: "debug", AppStatus: "stopping", Ports: debugPorts},
+		},
+		"debug port not reported yet": {
+			project: Project{StartMode: "debug", AppStatus: "starting", Ports: &Ports{}},
+		},
+		"remote pod not reported yet": {
+			project: Project{StartMode: "debug", AppStatus: "starting", Ports: debugPorts},
+			remote:  true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, debugTargetOf(&test.project, test.remote))
+		})
+	}
+}
+
+func Test_WaitForDebugTarget(t *testing.T) {
+	debugPollInterval = time.Millisecond
+	restarting := `{"projectID":"mockID","startMode":"run","appStatus":"stopping"}`
+	debugging := `{"projectID":"mockID","startMode":"debug","appStatus":"starting","podName":"pod","namespace":"ns","ports":{"internalDebugPort":"9229","exposedDebugPort":"32768"}}`
+
+	t.Run("success case - waits for the local project to start in debug mode", func(t *testing.T) {
+		mockClient := &mockProjectSequence{projects: []string{restarting, restarting, debugging}}
+		target, err := WaitForDebugTarget(mockClient, &mockConnection, "mockURL", "mockID", time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, &PortTarget{Host: "localhost", Port: 32768}, target)
+		assert.Equal(t, 3, mockClient.requests)
+	})
+
+	t.Run("error case - times out when the project does not start in debug mode", func(t *testing.T) {
+		mockClient := &mockProjectSequence{projects: []string{restarting}}
+		target, err := WaitForDebugTarget(mockClient, &mockConnection, "mockURL", "mockID", 5*time.Millisecond)
+		assert.Nil(t, target)
+		assert.Equal(t, errOpDebugTimeout, err.Op)
+	})
+}
@@ -0,0 +1,241 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	logr "github.com/sirupsen/logrus"
+)
+
+type (
+	// DetectionRule : Recognises the language and build type of a project from its files, so that stacks Codewind
+	// does not know about can be validated and bound. Every file pattern must match a file in the project, and every
+	// file matching a contents pattern must contain the text given for it.
+	DetectionRule struct {
+		Language  string             `json:"language"`
+		BuildType string             `json:"buildType"`
+		Files     []string           `json:"files"`
+		Contents  []DetectionContent `json:"contents,omitempty"`
+		Priority  int                `json:"priority,omitempty"`
+		// Source is the file the rule was loaded from
+		Source string `json:"-"`
+	}
+
+	// DetectionContent : Text a file of the project must contain for a rule to match
+	DetectionContent struct {
+		File string `json:"file"`
+		Text string `json:"text"`
+	}
+)
+
+// detectionFileName is the rules file of an extension directory or archive
+const detectionFileName = "detection.json"
+
+// getDetectionRulesDir : Get the directory detection rules are loaded from
+func getDetectionRulesDir() string {
+	return path.Join(getCodewindDir(), "extensions")
+}
+
+// LoadDetectionRules : Loads the detection rules in the extensions directory of the Codewind home directory, from JSON
+// files, and from the detection.json of extension directories and of .zip, .tar.gz and .tgz extension archives.
+// A file holds one rule or an array of them. Rules with a higher priority are checked first, then rules in the order
+// of their files. Files that cannot be read are skipped with a warning, so one bad extension does not stop validation.
+func LoadDetectionRules() []DetectionRule {
+	return loadDetectionRules(getDetectionRulesDir())
+}
+
+func loadDetectionRules(rulesDir string) []DetectionRule {
+	rules := []DetectionRule{}
+	files, err := ioutil.ReadDir(rulesDir)
+	if err != nil {
+		return rules
+	}
+	for _, file := range files {
+		filePath := filepath.Join(rulesDir, file.Name())
+		contents, err := readDetectionFile(filePath, file)
+		if err != nil {
+			logr.Warnf("Skipping detection rules in %v: %v", filePath, err)
+			continue
+		}
+		if contents == nil {
+			continue
+		}
+		fileRules, err := parseDetectionRules(contents)
+		if err != nil {
+			logr.Warnf("Skipping detection rules in %v: %v", filePath, err)
+			continue
+		}
+		for _, rule := range fileRules {
+			rule.Source = filePath
+			rules = append(rules, rule)
+		}
+	}
+	sort.SliceStable(rules, func(i, j int) bool {
+		return rules[i].Priority > rules[j].Priority
+	})
+	return rules
+}
+
+// readDetectionFile : Returns the detection rules JSON of an entry in the extensions directory, or nil if it has none
+func readDetectionFile(filePath string, info os.FileInfo) ([]byte, error) {
+	name := strings.ToLower(info.Name())
+	switch {
+	case info.IsDir():
+		contents, err := ioutil.ReadFile(filepath.Join(filePath, detectionFileName))
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return contents, err
+	case strings.HasSuffix(name, ".json"):
+		return ioutil.ReadFile(filePath)
+	case strings.HasSuffix(name, ".zip"):
+		return readDetectionFromZip(filePath)
+	case strings.HasSuffix(name, ".tar.gz"), strings.HasSuffix(name, ".tgz"):
+		return readDetectionFromTarGz(filePath)
+	}
+	return nil, nil
+}
+
+// isDetectionFile : Reports whether an archive entry is the detection.json at the root of an extension, or of the
+// single directory archives commonly wrap their contents in
+func isDetectionFile(entryName string) bool {
+	parts := strings.Split(strings.TrimPrefix(path.Clean(entryName), "./"), "/")
+	return parts[len(parts)-1] == detectionFileName && len(parts) <= 2
+}
+
+func readDetectionFromZip(archivePath string) ([]byte, error) {
+	archive, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+	for _, file := range archive.File {
+		if !isDetectionFile(file.Name) {
+			continue
+		}
+		reader, err := file.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer reader.Close()
+		return ioutil.ReadAll(reader)
+	}
+	return nil, nil
+}
+
+func readDetectionFromTarGz(archivePath string) ([]byte, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gzipReader.Close()
+	tarReader := tar.NewReader(gzipReader)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if header.Typeflag == tar.TypeReg && isDetectionFile(header.Name) {
+			return ioutil.ReadAll(tarReader)
+		}
+	}
+}
+
+// parseDetectionRules : Parses one rule, or an array of rules, checking each names a language and files to detect
+func parseDetectionRules(contents []byte) ([]DetectionRule, error) {
+	rules := []DetectionRule{}
+	if trimmed := bytes.TrimSpace(contents); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(contents, &rules); err != nil {
+			return nil, err
+		}
+	} else {
+		var rule DetectionRule
+		if err := json.Unmarshal(contents, &rule); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	for _, rule := range rules {
+		if rule.Language == "" || len(rule.Files) == 0 {
+			return nil, errors.New("each rule needs a language and at least one file pattern")
+		}
+		for _, pattern := range append(rule.Files, contentFiles(rule)...) {
+			if _, err := filepath.Match(pattern, ""); err != nil {
+				return nil, errors.New("invalid file pattern " + pattern)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func contentFiles(rule DetectionRule) []string {
+	files := []string{}
+	for _, content := range rule.Contents {
+		files = append(files, content.File)
+	}
+	return files
+}
+
+// Matches : Reports whether a project has the files of a rule. Patterns are matched against the files at the root of
+// the project, or against a relative path when they contain a slash.
+func (rule DetectionRule) Matches(projectPath string) bool {
+	for _, pattern := range rule.Files {
+		matches, _ := filepath.Glob(filepath.Join(projectPath, filepath.FromSlash(pattern)))
+		if len(matches) == 0 {
+			return false
+		}
+	}
+	for _, content := range rule.Contents {
+		matches, _ := filepath.Glob(filepath.Join(projectPath, filepath.FromSlash(content.File)))
+		if len(matches) == 0 {
+			return false
+		}
+		for _, match := range matches {
+			contents, err := ioutil.ReadFile(match)
+			if err != nil || !strings.Contains(string(contents), content.Text) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matchDetectionRule : Returns the first rule a project matches, or nil
+func matchDetectionRule(rules []DetectionRule, projectPath string) *DetectionRule {
+	for i := range rules {
+		if rules[i].Matches(projectPath) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeZipExtension(t *testing.T, archivePath string, entryName string, contents string) {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zipWriter := zip.NewWriter(file)
+	entry, _ := zipWriter.Create(entryName)
+	entry.Write([]byte(contents))
+	zipWriter.Close()
+}
+
+func writeTarGzExtension(t *testing.T, archivePath string, entryName string, contents string) {
+	file, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	tarWriter.WriteHeader(&tar.Header{Name: entryName, Mode: 0644, Size: int64(len(contents)), Typeflag: tar.TypeReg})
+	tarWriter.Write([]byte(contents))
+	tarWriter.Close()
+	gzipWriter.Close()
+}
+
+func TestLoadDetectionRules(t *testing.T) {
+	rulesDir, err := ioutil.TempDir("", "detection-rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(rulesDir)
+
+	ioutil.WriteFile(filepath.Join(rulesDir, "a-rust.json"), []byte(`{"language":"rust","files":["Cargo.toml"]}`), 0644)
+	ioutil.WriteFile(filepath.Join(rulesDir, "b-dotnet.json"), []byte(`[{"language":"csharp","buildType":"docker","files":["*.csproj"],"priority":10}]`), 0644)
+	ioutil.WriteFile(filepath.Join(rulesDir, "c-invalid.json"), []byte(`{"language":"nofiles"}`), 0644)
+	ioutil.WriteFile(filepath.Join(rulesDir, "d-notes.txt"), []byte(`{"language":"text","files":["*"]}`), 0644)
+	os.MkdirAll(filepath.Join(rulesDir, "e-elixir"), 0755)
+	ioutil.WriteFile(filepath.Join(rulesDir, "e-elixir", "detection.json"), []byte(`{"language":"elixir","files":["mix.exs"]}`), 0644)
+	os.MkdirAll(filepath.Join(rulesDir, "f-empty"), 0755)
+	writeZipExtension(t, filepath.Join(rulesDir, "g-kotlin.zip"), "kotlin/detection.json", `{"language":"kotlin","files":["build.gradle.kts"]}`)
+	writeTarGzExtension(t, filepath.Join(rulesDir, "h-php.tar.gz"), "detection.json", `{"language":"php","files":["composer.json"]}`)
+	ioutil.WriteFile(filepath.Join(rulesDir, "i-corrupt.tgz"), []byte(`not an archive`), 0644)
+
+	rules := loadDetectionRules(rulesDir)
+	languages := []string{}
+	for _, rule := range rules {
+		languages = append(languages, rule.Language)
+	}
+	assert.Equal(t, []string{"csharp", "rust", "elixir", "kotlin", "php"}, languages)
+	assert.Equal(t, filepath.Join(rulesDir, "a-rust.json"), rules[1].Source)
+
+	assert.Empty(t, loadDetectionRules(filepath.Join(rulesDir, "missing")))
+}
+
+func TestDetectionRuleMatches(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "detection-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	ioutil.WriteFile(filepath.Join(projectPath, "Cargo.toml"), []byte("[dependencies]\nrocket = \"0.4\"\n"), 0644)
+	os.MkdirAll(filepath.Join(projectPath, "src"), 0755)
+	ioutil.WriteFile(filepath.Join(projectPath, "src", "main.rs"), []byte("fn main() {}"), 0644)
+
+	tests := map[string]struct {
+		rule DetectionRule
+		want bool
+	}{
+		"file exists":           {DetectionRule{Files: []string{"Cargo.toml"}}, true},
+		"pattern in directory":  {DetectionRule{Files: []string{"src/*.rs"}}, true},
+		"every file must exist": {DetectionRule{Files: []string{"Cargo.toml", "Dockerfile"}}, false},
+		"file contains text":    {DetectionRule{Files: []string{"Cargo.toml"}, Contents: []DetectionContent{{"Cargo.toml", "rocket"}}}, true},
+		"file lacks text":       {DetectionRule{Files: []string{"Cargo.toml"}, Contents: []DetectionContent{{"Cargo.toml", "actix"}}}, false},
+		"content file missing":  {DetectionRule{Files: []string{"Cargo.toml"}, Contents: []DetectionContent{{"Rocket.toml", "port"}}}, false},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, test.rule.Matches(projectPath))
+		})
+	}
+}
+
+func TestDetermineProjectInfoWithDetectionRules(t *testing.T) {
+	home, err := ioutil.TempDir("", "detection-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	os.MkdirAll(getDetectionRulesDir(), 0755)
+	ioutil.WriteFile(filepath.Join(getDetectionRulesDir(), "rust.json"), []byte(`{"language":"rust","files":["Cargo.toml"]}`), 0644)
+	ioutil.WriteFile(filepath.Join(getDetectionRulesDir(), "quarkus.json"), []byte(`{"language":"java","buildType":"quarkus","files":["pom.xml"],"contents":[{"file":"pom.xml","text":"io.quarkus"}]}`), 0644)
+
+	projectPath := filepath.Join(home, "project")
+	os.MkdirAll(projectPath, 0755)
+
+	t.Run("detects a stack Codewind does not know", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, "Cargo.toml"), []byte(""), 0644)
+		defer os.Remove(filepath.Join(projectPath, "Cargo.toml"))
+		language, buildType := determineProjectInfo(projectPath)
+		assert.Equal(t, "rust", language)
+		assert.Equal(t, "docker", buildType)
+	})
+
+	t.Run("overrides the built-in detection", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, "pom.xml"), []byte("<groupId>io.quarkus</groupId>"), 0644)
+		defer os.Remove(filepath.Join(projectPath, "pom.xml"))
+		language, buildType := determineProjectInfo(projectPath)
+		assert.Equal(t, "java", language)
+		assert.Equal(t, "quarkus", buildType)
+	})
+
+	t.Run("falls back to the built-in detection", func(t *testing.T) {
+		ioutil.WriteFile(filepath.Join(projectPath, "package.json"), []byte("{}"), 0644)
+		defer os.Remove(filepath.Join(projectPath, "package.json"))
+		language, buildType := determineProjectInfo(projectPath)
+		assert.Equal(t, "javascript", language)
+		assert.Equal(t, "nodejs", buildType)
+	})
+}
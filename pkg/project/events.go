@@ -0,0 +1,186 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// EventsOptions : The events to read from PFE
+	EventsOptions struct {
+		// ProjectID only passes the events of one project, when set
+		ProjectID string
+		// Follow reads events until the context is cancelled, instead of returning after the first one
+		Follow bool
+	}
+
+	// ProjectEvent : An event sent by PFE, such as projectStatusChanged, projectChanged or log-update
+	ProjectEvent struct {
+		Event     string          `json:"event"`
+		ProjectID string          `json:"projectID,omitempty"`
+		Time      int64           `json:"time"`
+		Data      json.RawMessage `json:"data,omitempty"`
+	}
+
+	// engineOpen is the first packet of an Engine.IO connection
+	engineOpen struct {
+		PingInterval int64 `json:"pingInterval"`
+	}
+)
+
+// eventsPath is the Socket.IO endpoint of PFE, served over a WebSocket with Engine.IO version 3
+const eventsPath = "/socket.io/?EIO=3&transport=websocket"
+
+// eventsNamespace is the Socket.IO namespace PFE sends its UI events to
+const eventsNamespace = "/default"
+
+// Engine.IO and Socket.IO packet types used by PFE
+const (
+	engineOpenPacket    = '0'
+	engineClosePacket   = '1'
+	enginePingPacket    = '2'
+	engineMessagePacket = '4'
+	socketConnectPacket = '0'
+	socketEventPacket   = '2'
+	socketErrorPacket   = '4'
+)
+
+// WatchEvents : Reads the events PFE sends to its UI, passing each one to handle. Returns after the first event, or
+// with Follow, once the context is cancelled. Fails if PFE closes the event stream.
+func WatchEvents(ctx context.Context, httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, options EventsOptions, handle func(ProjectEvent)) *ProjectError {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	socketURL := strings.TrimSuffix(conURL, "/") + eventsPath
+	ws, secErr := sechttp.DialWebSocket(ctx, httpClient, socketURL, conInfo)
+	if secErr != nil {
+		return &ProjectError{errOpRequest, secErr, secErr.Desc}
+	}
+	defer ws.Close()
+
+	for {
+		message, err := ws.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return &ProjectError{errOpEvents, err, textEventsClosed + ": " + err.Error()}
+		}
+		if len(message) == 0 {
+			continue
+		}
+		switch message[0] {
+		case engineOpenPacket:
+			open := engineOpen{}
+			json.Unmarshal(message[1:], &open)
+			if open.PingInterval > 0 {
+				go pingEvents(ctx, ws, time.Duration(open.PingInterval)*time.Millisecond)
+			}
+			// The root namespace is joined on connecting, PFE sends its events to another one
+			if err := ws.WriteMessage([]byte(string(engineMessagePacket) + string(socketConnectPacket) + eventsNamespace + ",")); err != nil {
+				return &ProjectError{errOpEvents, err, err.Error()}
+			}
+		case engineClosePacket:
+			err := i18n.Errorf(msgEventsClosed, textEventsClosed)
+			return &ProjectError{errOpEvents, err, textEventsClosed}
+		case engineMessagePacket:
+			event, projErr := parseSocketPacket(message[1:])
+			if projErr != nil {
+				return projErr
+			}
+			if event == nil || (options.ProjectID != "" && event.ProjectID != options.ProjectID) {
+				continue
+			}
+			handle(*event)
+			if !options.Follow {
+				return nil
+			}
+		}
+	}
+}
+
+// pingEvents pings PFE at the interval it asked for, as it closes connections that stop pinging
+func pingEvents(ctx context.Context, ws *sechttp.WebSocket, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if ws.WriteMessage([]byte{enginePingPacket}) != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseSocketPacket reads a Socket.IO packet, such as 2/default,["projectChanged",{...}], returning the event it
+// carries. Returns nil for packets that are not events of the PFE namespace, and an error for a namespace error.
+func parseSocketPacket(packet []byte) (*ProjectEvent, *ProjectError) {
+	if len(packet) == 0 {
+		return nil, nil
+	}
+	packetType := packet[0]
+	body := string(packet[1:])
+	namespace := "/"
+	if strings.HasPrefix(body, "/") {
+		end := strings.IndexByte(body, ',')
+		if end < 0 {
+			end = len(body)
+			body += ","
+		}
+		namespace = body[:end]
+		body = body[end+1:]
+	}
+	// Skip the ID of packets waiting for an acknowledgement
+	body = strings.TrimLeft(body, "0123456789")
+
+	if namespace != eventsNamespace {
+		return nil, nil
+	}
+	switch packetType {
+	case socketErrorPacket:
+		reason := body
+		json.Unmarshal([]byte(body), &reason)
+		err := i18n.Errorf(msgEventsRefused, textEventsRefused, reason)
+		return nil, &ProjectError{errOpEvents, err, err.Error()}
+	case socketEventPacket:
+		var args []json.RawMessage
+		if err := json.Unmarshal([]byte(body), &args); err != nil || len(args) == 0 {
+			return nil, nil
+		}
+		event := ProjectEvent{Time: time.Now().UnixNano() / int64(time.Millisecond)}
+		if json.Unmarshal(args[0], &event.Event) != nil {
+			return nil, nil
+		}
+		if len(args) > 1 {
+			event.Data = args[1]
+			project := struct {
+				ProjectID string `json:"projectID"`
+			}{}
+			json.Unmarshal(args[1], &project)
+			event.ProjectID = project.ProjectID
+		}
+		return &event, nil
+	}
+	return nil, nil
+}
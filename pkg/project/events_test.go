@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/base64"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// socketServer upgrades requests to a WebSocket and sends the Engine.IO packets given, in unmasked text frames
+func socketServer(packets ...string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		hash := sha1.Sum([]byte(r.Header.Get("Sec-WebSocket-Key") + "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"))
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(hash[:]) + "\r\n\r\n")
+		for _, packet := range packets {
+			rw.Write(append([]byte{0x81, byte(len(packet))}, packet...))
+		}
+		rw.Flush()
+		io.Copy(ioutil.Discard, conn)
+	}))
+}
+
+func TestParseSocketPacket(t *testing.T) {
+	t.Run("reads an event of the PFE namespace", func(t *testing.T) {
+		event, err := parseSocketPacket([]byte(`2/default,["projectChanged",{"projectID":"p1","status":"success"}]`))
+		assert.Nil(t, err)
+		if assert.NotNil(t, event) {
+			assert.Equal(t, "projectChanged", event.Event)
+			assert.Equal(t, "p1", event.ProjectID)
+			assert.JSONEq(t, `{"projectID":"p1","status":"success"}`, string(event.Data))
+		}
+	})
+
+	t.Run("skips the ID of an event waiting for an acknowledgement", func(t *testing.T) {
+		event, _ := parseSocketPacket([]byte(`2/default,12["log-update",{"projectID":"p2"}]`))
+		if assert.NotNil(t, event) {
+			assert.Equal(t, "log-update", event.Event)
+		}
+	})
+
+	t.Run("ignores other namespaces and packets", func(t *testing.T) {
+		for _, packet := range []string{`2["projectChanged",{}]`, `0/default,`, `0`, `2/default,not json`} {
+			event, err := parseSocketPacket([]byte(packet))
+			assert.Nil(t, event, packet)
+			assert.Nil(t, err, packet)
+		}
+	})
+
+	t.Run("fails when the namespace is refused", func(t *testing.T) {
+		_, err := parseSocketPacket([]byte(`4/default,"Invalid namespace"`))
+		if assert.NotNil(t, err) {
+			assert.Equal(t, errOpEvents, err.Op)
+			assert.Contains(t, err.Desc, "Invalid namespace")
+		}
+	})
+}
+
+func TestWatchEvents(t *testing.T) {
+	packets := []string{
+		`0{"sid":"a","pingInterval":25000}`,
+		`40`,
+		`40/default,`,
+		`42/default,["projectStatusChanged",{"projectID":"p1"}]`,
+		`42/default,["projectChanged",{"projectID":"p2"}]`,
+		`1`,
+	}
+
+	t.Run("returns after the first event of the project", func(t *testing.T) {
+		server := socketServer(packets...)
+		defer server.Close()
+
+		var events []ProjectEvent
+		err := WatchEvents(context.Background(), &http.Client{}, &mockConnection, server.URL, EventsOptions{ProjectID: "p2"}, func(event ProjectEvent) {
+			events = append(events, event)
+		})
+		assert.Nil(t, err)
+		if assert.Len(t, events, 1) {
+			assert.Equal(t, "projectChanged", events[0].Event)
+		}
+	})
+
+	t.Run("follows events until PFE closes the stream", func(t *testing.T) {
+		server := socketServer(packets...)
+		defer server.Close()
+
+		var events []ProjectEvent
+		err := WatchEvents(context.Background(), &http.Client{}, &mockConnection, server.URL, EventsOptions{Follow: true}, func(event ProjectEvent) {
+			events = append(events, event)
+		})
+		if assert.NotNil(t, err) {
+			assert.Equal(t, errOpEvents, err.Op)
+		}
+		assert.Len(t, events, 2)
+	})
+}
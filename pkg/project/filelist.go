@@ -2,11 +2,11 @@ package project
 
 import (
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 )
@@ -28,7 +28,7 @@ func GetProjectFileList(httpClient utils.HTTPClient, connection *connections.Con
 
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		respErr := errors.New(textAPINotFound)
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
 		return nil, &ProjectError{errOpNotFound, respErr, textAPINotFound}
 	}
 
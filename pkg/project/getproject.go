@@ -2,11 +2,11 @@ package project
 
 import (
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 )
@@ -20,6 +20,21 @@ type (
 		Host           string `json:"host"`
 		LocationOnDisk string `json:"locOnDisk"`
 		AppStatus      string `json:"appStatus"`
+		BuildStatus    string `json:"buildStatus"`
+		LastBuild      int64  `json:"lastbuild"`
+		LastSync       int64  `json:"lastUploadTime"`
+		StartMode      string `json:"startMode,omitempty"`
+		PodName        string `json:"podName,omitempty"`
+		Namespace      string `json:"namespace,omitempty"`
+		Ports          *Ports `json:"ports,omitempty"`
+	}
+
+	// Ports : The ports of a running project, the exposed ports being mapped to the host for local projects
+	Ports struct {
+		InternalPort      string `json:"internalPort,omitempty"`
+		ExposedPort       string `json:"exposedPort,omitempty"`
+		InternalDebugPort string `json:"internalDebugPort,omitempty"`
+		ExposedDebugPort  string `json:"exposedDebugPort,omitempty"`
 	}
 )
 
@@ -38,7 +53,7 @@ func GetProjectFromID(httpClient utils.HTTPClient, connection *connections.Conne
 
 	defer resp.Body.Close()
 	if resp.StatusCode == http.StatusNotFound {
-		respErr := errors.New(textAPINotFound)
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
 		return nil, &ProjectError{errOpNotFound, respErr, textAPINotFound}
 	}
 
@@ -66,7 +81,7 @@ func GetProjectIDFromName(httpClient utils.HTTPClient, connection *connections.C
 			return project.ProjectID, nil
 		}
 	}
-	respErr := errors.New(textAPINotFound)
+	respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
 	return "", &ProjectError{errOpNotFound, respErr, textAPINotFound}
 }
 
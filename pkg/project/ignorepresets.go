@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"sort"
+	"strings"
+
+	logr "github.com/sirupsen/logrus"
+)
+
+// ignorePresets are the dependency and build output paths of languages and build tools. A project ignores them by
+// naming its presets in the ignorePreset of its .cw-settings, rather than listing every path in ignoredPaths. Paths
+// starting with **/ are ignored at any depth, so that the modules of nested packages are not synced either.
+var ignorePresets = map[string][]string{
+	"node": {
+		"**/node_modules",
+		"**/npm-debug.log*",
+		"**/yarn-debug.log*",
+		"**/yarn-error.log*",
+		"coverage",
+		".nyc_output",
+	},
+	"java-maven": {
+		"**/target",
+		"**/.classpath",
+		"**/.project",
+		"**/.settings",
+		"**/.factorypath",
+	},
+	"java-gradle": {
+		"**/.gradle",
+		"**/build",
+		"out",
+		"**/.classpath",
+		"**/.project",
+		"**/.settings",
+	},
+	"python": {
+		"**/__pycache__",
+		"**/*.pyc",
+		"**/*.pyo",
+		"**/.pytest_cache",
+		"**/.mypy_cache",
+		"**/*.egg-info",
+		".eggs",
+		".tox",
+		".venv",
+		"venv",
+	},
+	"go": {
+		"bin",
+		"**/*.test",
+		"**/*.prof",
+	},
+}
+
+// expandIgnorePreset : Returns the ignored paths of the comma separated presets of a .cw-settings, warning of
+// presets that do not exist
+func expandIgnorePreset(preset string) []string {
+	var ignoredPaths []string
+	for _, name := range strings.Split(preset, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		paths, found := ignorePresets[name]
+		if !found {
+			logr.Warnf("Unknown ignore preset %v, expected one of %v", name, strings.Join(ignorePresetNames(), ", "))
+			continue
+		}
+		ignoredPaths = append(ignoredPaths, paths...)
+	}
+	return ignoredPaths
+}
+
+func ignorePresetNames() []string {
+	names := []string{}
+	for name := range ignorePresets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,54 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnorePresets(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "ignore-presets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	settingsFile := filepath.Join(projectPath, ".cw-settings")
+
+	t.Run("success case - preset paths are ignored before the ignored paths of the project", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(`{"ignorePreset":"node","ignoredPaths":["*.log"]}`), 0644)
+		ignoredPaths := retrieveIgnoredPathsList(projectPath)
+		assert.Equal(t, append(append([]string{}, ignorePresets["node"]...), "*.log"), ignoredPaths)
+		assert.True(t, ignoreFileOrDirectory("node_modules", true, ignoredPaths))
+		assert.True(t, ignoreFileOrDirectory("client/node_modules", true, ignoredPaths))
+		assert.False(t, ignoreFileOrDirectory("src", true, ignoredPaths))
+	})
+
+	t.Run("success case - presets are combined, and unknown presets are skipped", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(`{"ignorePreset":"python, rust,go"}`), 0644)
+		ignoredPaths := retrieveIgnoredPathsList(projectPath)
+		assert.Equal(t, append(append([]string{}, ignorePresets["python"]...), ignorePresets["go"]...), ignoredPaths)
+		assert.True(t, ignoreFileOrDirectory("app/models/__pycache__", true, ignoredPaths))
+		assert.True(t, ignoreFileOrDirectory("bin", true, ignoredPaths))
+	})
+
+	t.Run("success case - every preset ignores paths", func(t *testing.T) {
+		for _, name := range ignorePresetNames() {
+			assert.NotEmpty(t, expandIgnorePreset(name), name)
+		}
+		assert.Nil(t, expandIgnorePreset(""))
+	})
+}
@@ -17,8 +17,11 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"regexp"
+	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 )
@@ -46,6 +49,39 @@ type (
 	}
 )
 
+// validLinkEnvName matches the names PFE can inject as environment variables into a project container
+var validLinkEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ValidateLinkEnvName checks a link environment variable name can be set in a project container
+func ValidateLinkEnvName(envName string) *ProjectError {
+	if !validLinkEnvName.MatchString(envName) {
+		err := i18n.Errorf(msgInvalidLinkEnv, textInvalidLinkEnv)
+		return &ProjectError{errOpInvalidOptions, err, textInvalidLinkEnv}
+	}
+	return nil
+}
+
+// ValidateProjectLink checks a project can be linked to a target project under the environment variable name.
+// PFE only injects the URLs of projects it builds itself, so both must be bound to the same connection.
+func ValidateProjectLink(conID string, projectID string, targetProjectID string, envName string) *ProjectError {
+	if projErr := ValidateLinkEnvName(envName); projErr != nil {
+		return projErr
+	}
+	if projectID == targetProjectID {
+		err := i18n.Errorf(msgLinkToSelf, textLinkToSelf)
+		return &ProjectError{errOpInvalidOptions, err, textLinkToSelf}
+	}
+	targetConID, projErr := GetConnectionID(targetProjectID)
+	if projErr != nil {
+		return projErr
+	}
+	if !strings.EqualFold(targetConID, conID) {
+		err := i18n.Errorf(msgLinkConnection, textLinkConnection, targetConID, conID)
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	return nil
+}
+
 // GetProjectLinks calls the project links API on PFE with a POST request
 func GetProjectLinks(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) ([]Link, *ProjectError) {
 	requestURL := conURL + "/api/v1/projects/" + projectID + "/links"
@@ -138,13 +174,13 @@ func handleProjectLinkResponse(req *http.Request, conInfo *connections.Connectio
 	if resp.StatusCode != successCode {
 		var respErr error
 		if resp.StatusCode == http.StatusBadRequest {
-			respErr = handlePFEErrorMessage(byteArray, textInvalidRequest)
+			respErr = handlePFEErrorMessage(byteArray, i18n.Errorf(msgInvalidRequest, textInvalidRequest))
 		} else if resp.StatusCode == http.StatusNotFound {
-			respErr = handlePFEErrorMessage(byteArray, textProjectLinkUnknownNotFound)
+			respErr = handlePFEErrorMessage(byteArray, i18n.Errorf(msgProjectLinkUnknownNotFound, textProjectLinkUnknownNotFound))
 		} else if resp.StatusCode == http.StatusConflict {
-			respErr = handlePFEErrorMessage(byteArray, textProjectLinkConflict)
+			respErr = handlePFEErrorMessage(byteArray, i18n.Errorf(msgProjectLinkConflict, textProjectLinkConflict))
 		} else {
-			respErr = errors.New(textUnknownResponseCode)
+			respErr = i18n.Errorf(msgUnknownResponseCode, textUnknownResponseCode)
 		}
 		return nil, &ProjectError{errOpResponse, respErr, respErr.Error()}
 	}
@@ -152,12 +188,12 @@ func handleProjectLinkResponse(req *http.Request, conInfo *connections.Connectio
 	return byteArray, nil
 }
 
-func handlePFEErrorMessage(byteArray []byte, defaultMessage string) error {
+func handlePFEErrorMessage(byteArray []byte, defaultErr error) error {
 	var projectLinkError LinkError
 	jsonErr := json.Unmarshal(byteArray, &projectLinkError)
 	if jsonErr != nil {
-		// if the message body is not a ProjectLinkError in PFE, send defaultMessage
-		return errors.New(defaultMessage)
+		// if the message body is not a ProjectLinkError in PFE, send defaultErr
+		return defaultErr
 	}
 
 	return errors.New(projectLinkError.Message)
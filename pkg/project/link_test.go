@@ -14,20 +14,20 @@ package project
 import (
 	"bytes"
 	"encoding/json"
-	"errors"
 	"io/ioutil"
 	"net/http"
 	"testing"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/stretchr/testify/assert"
 )
 
-var errInvalidRequest = errors.New(textInvalidRequest)
-var errUnknownNotFound = errors.New(textProjectLinkUnknownNotFound)
-var errConflict = errors.New(textProjectLinkConflict)
-var errUnknownHTTPCode = errors.New(textUnknownResponseCode)
+var errInvalidRequest = i18n.Errorf(msgInvalidRequest, textInvalidRequest)
+var errUnknownNotFound = i18n.Errorf(msgProjectLinkUnknownNotFound, textProjectLinkUnknownNotFound)
+var errConflict = i18n.Errorf(msgProjectLinkConflict, textProjectLinkConflict)
+var errUnknownHTTPCode = i18n.Errorf(msgUnknownResponseCode, textUnknownResponseCode)
 
 var projectLinkCreateUpdateDeleteTests = []struct {
 	name       string
@@ -106,3 +106,23 @@ func TestDeleteProjectLinks(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateProjectLink(t *testing.T) {
+	t.Run("Expect success - environment variable names a container accepts", func(t *testing.T) {
+		for _, envName := range []string{"BACKEND_URL", "_url", "service2"} {
+			assert.Nil(t, ValidateLinkEnvName(envName))
+		}
+	})
+
+	t.Run("Expect failure - environment variable names a container rejects", func(t *testing.T) {
+		for _, envName := range []string{"", "2SERVICE", "BACKEND-URL", "BACKEND URL"} {
+			projErr := ValidateLinkEnvName(envName)
+			assert.Equal(t, errOpInvalidOptions, projErr.Op)
+		}
+	})
+
+	t.Run("Expect failure - project linked to itself", func(t *testing.T) {
+		projErr := ValidateProjectLink("local", "dummyProjectID", "dummyProjectID", "BACKEND_URL")
+		assert.Equal(t, textLinkToSelf, projErr.Desc)
+	})
+}
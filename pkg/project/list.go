@@ -0,0 +1,151 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+)
+
+type (
+	// ListOptions : Filters the projects returned by a list
+	ListOptions struct {
+		Language string
+		State    string
+	}
+
+	// ListedProject : A project with the connection it is bound to. Registered projects have a connection file on
+	// this machine, and missing projects have one although their connection no longer reports them.
+	ListedProject struct {
+		Project
+		ConnectionID string `json:"conid"`
+		Registered   bool   `json:"registered"`
+		Missing      bool   `json:"missing,omitempty"`
+	}
+)
+
+// stateMissing is the state of a project that is registered on this machine but not reported by its connection
+const stateMissing = "missing"
+
+// ListProjects : Lists the projects of a connection, or of every connection when conID is "all", keyed by
+// connection ID. Each connection reports the state of its projects, and projects registered on this machine for a
+// connection that does not report them are listed as missing. A connection that cannot be reached is reported in the
+// errors of the result when listing every connection.
+func ListProjects(conID string, options ListOptions) (*connections.BatchResult, *ProjectError) {
+	registry := readProjectRegistry()
+	list := func(connection *connections.Connection) (interface{}, error) {
+		conURL, conErr := config.PFEOriginFromConnection(connection)
+		if conErr != nil {
+			return nil, conErr
+		}
+		projects, getAllErr := GetAll(sechttp.Client(), connection, conURL)
+		if getAllErr != nil {
+			return nil, getAllErr
+		}
+		return FilterProjects(connection.ID, projects, registry, options), nil
+	}
+
+	if connections.IsAllConnections(conID) {
+		result, conErr := connections.ForEachConnection(list)
+		if conErr != nil {
+			return nil, &ProjectError{conErr.Op, conErr.Err, conErr.Desc}
+		}
+		return result, nil
+	}
+
+	connection, conErr := connections.GetConnectionByID(conID)
+	if conErr != nil {
+		return nil, &ProjectError{conErr.Op, conErr.Err, conErr.Desc}
+	}
+	conURL, configErr := config.PFEOriginFromConnection(connection)
+	if configErr != nil {
+		return nil, &ProjectError{configErr.Op, configErr.Err, configErr.Desc}
+	}
+	projects, getAllErr := GetAll(sechttp.Client(), connection, conURL)
+	if getAllErr != nil {
+		return nil, getAllErr
+	}
+	listed := FilterProjects(connection.ID, projects, registry, options)
+	return &connections.BatchResult{Connections: map[string]interface{}{connection.ID: listed}}, nil
+}
+
+// FilterProjects : Merges the projects reported by a connection with those registered for it on this machine, keeping
+// those with the language and state asked for, sorted by name
+func FilterProjects(conID string, projects []Project, registry map[string]string, options ListOptions) []ListedProject {
+	listed := []ListedProject{}
+	reported := map[string]bool{}
+	for _, project := range projects {
+		reported[project.ProjectID] = true
+		_, registered := registry[project.ProjectID]
+		listed = append(listed, ListedProject{Project: project, ConnectionID: conID, Registered: registered})
+	}
+	for projectID, registeredConID := range registry {
+		if registeredConID == conID && !reported[projectID] {
+			listed = append(listed, ListedProject{Project: Project{ProjectID: projectID}, ConnectionID: conID, Registered: true, Missing: true})
+		}
+	}
+
+	filtered := []ListedProject{}
+	for _, project := range listed {
+		if options.Language != "" && !strings.EqualFold(project.Language, options.Language) {
+			continue
+		}
+		if options.State != "" && !matchesState(project, options.State) {
+			continue
+		}
+		filtered = append(filtered, project)
+	}
+	sort.SliceStable(filtered, func(i, j int) bool {
+		if filtered[i].Name != filtered[j].Name {
+			return strings.ToLower(filtered[i].Name) < strings.ToLower(filtered[j].Name)
+		}
+		return filtered[i].ProjectID < filtered[j].ProjectID
+	})
+	return filtered
+}
+
+// State : Returns the application state of a listed project, or missing when its connection does not report it
+func (project ListedProject) State() string {
+	if project.Missing {
+		return stateMissing
+	}
+	return project.AppStatus
+}
+
+// matchesState : Reports whether a project is in a state, where running is another name for started
+func matchesState(project ListedProject, state string) bool {
+	if strings.EqualFold(state, "running") {
+		state = "started"
+	}
+	return strings.EqualFold(project.State(), state)
+}
+
+// readProjectRegistry : Reads the connection files of the projects bound on this machine, returning the connection
+// ID of each project. Files that cannot be read are skipped.
+func readProjectRegistry() map[string]string {
+	return connections.GetProjectConnections()
+}
+
+// BoundProjectIDs : Returns the IDs of the projects bound on this machine, read from the project registry without
+// contacting any connection
+func BoundProjectIDs() []string {
+	projectIDs := []string{}
+	for projectID := range readProjectRegistry() {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+	return projectIDs
+}
@@ -0,0 +1,89 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterProjects(t *testing.T) {
+	projects := []Project{
+		{ProjectID: "id-node", Name: "nodeproject", Language: "nodejs", AppStatus: "started"},
+		{ProjectID: "id-java", Name: "javaproject", Language: "java", AppStatus: "stopped"},
+		{ProjectID: "id-go", Name: "goproject", Language: "go", AppStatus: "started"},
+	}
+	registry := map[string]string{"id-node": "local", "id-gone": "local", "id-remote": "remote"}
+
+	ids := func(listed []ListedProject) []string {
+		ids := []string{}
+		for _, project := range listed {
+			ids = append(ids, project.ProjectID)
+		}
+		return ids
+	}
+
+	t.Run("lists reported and missing projects, sorted by name", func(t *testing.T) {
+		listed := FilterProjects("local", projects, registry, ListOptions{})
+		assert.Equal(t, []string{"id-gone", "id-go", "id-java", "id-node"}, ids(listed))
+		assert.True(t, listed[0].Missing)
+		assert.True(t, listed[0].Registered)
+		assert.Equal(t, "missing", listed[0].State())
+		assert.False(t, listed[1].Registered)
+		assert.True(t, listed[3].Registered)
+		assert.Equal(t, "local", listed[3].ConnectionID)
+	})
+
+	t.Run("filters by language ignoring case", func(t *testing.T) {
+		listed := FilterProjects("local", projects, registry, ListOptions{Language: "Java"})
+		assert.Equal(t, []string{"id-java"}, ids(listed))
+	})
+
+	t.Run("filters by state, where running means started", func(t *testing.T) {
+		listed := FilterProjects("local", projects, registry, ListOptions{State: "running"})
+		assert.Equal(t, []string{"id-go", "id-node"}, ids(listed))
+		listed = FilterProjects("local", projects, registry, ListOptions{State: "missing"})
+		assert.Equal(t, []string{"id-gone"}, ids(listed))
+	})
+
+	t.Run("combines filters", func(t *testing.T) {
+		listed := FilterProjects("local", projects, registry, ListOptions{Language: "go", State: "stopped"})
+		assert.Empty(t, listed)
+	})
+}
+
+func TestReadProjectRegistry(t *testing.T) {
+	home, err := ioutil.TempDir("", "project-registry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	assert.Empty(t, readProjectRegistry())
+
+	configDir := getProjectConnectionConfigDir()
+	os.MkdirAll(configDir, 0755)
+	ioutil.WriteFile(filepath.Join(configDir, "id-local.json"), []byte(`{"id":"local"}`), 0644)
+	ioutil.WriteFile(filepath.Join(configDir, "id-remote.json"), []byte(`{"id":"remote"}`), 0644)
+	ioutil.WriteFile(filepath.Join(configDir, "id-invalid.json"), []byte(`not json`), 0644)
+	ioutil.WriteFile(filepath.Join(configDir, "notes.txt"), []byte(`{"id":"local"}`), 0644)
+
+	assert.Equal(t, map[string]string{"id-local": "local", "id-remote": "remote"}, readProjectRegistry())
+	assert.Equal(t, []string{"id-local", "id-remote"}, BoundProjectIDs())
+}
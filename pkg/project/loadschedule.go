@@ -0,0 +1,365 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+type (
+	// LoadTestProfile : The load test config a scheduled run overrides, leaving the rest of the config of the project
+	LoadTestProfile struct {
+		Path              string `json:"path,omitempty"`
+		RequestsPerSecond int    `json:"requestsPerSecond,omitempty"`
+		Concurrency       int    `json:"concurrency,omitempty"`
+		MaxSeconds        int    `json:"maxSeconds,omitempty"`
+	}
+
+	// LoadTestSchedule : Load runs of a project started on a cron schedule, by a CronJob in the workspace of a remote
+	// connection, or by the daemon for the local connection
+	LoadTestSchedule struct {
+		Name         string                  `json:"name"`
+		ProjectID    string                  `json:"projectID"`
+		ConnectionID string                  `json:"connectionID"`
+		Schedule     string                  `json:"schedule"`
+		Description  string                  `json:"description,omitempty"`
+		Profile      *LoadTestProfile        `json:"profile,omitempty"`
+		Archive      string                  `json:"archive,omitempty"`
+		CronJob      *remote.LoadTestCronJob `json:"cronJob,omitempty"`
+		LastRun      int64                   `json:"lastRun,omitempty"`
+	}
+
+	// LoadTestScheduleRun : The outcome of a scheduled load run started by the daemon
+	LoadTestScheduleRun struct {
+		Name    string           `json:"name"`
+		Status  string           `json:"status"`
+		Results *LoadTestResults `json:"results,omitempty"`
+		Error   string           `json:"error,omitempty"`
+	}
+)
+
+// createLoadTestCronJob, deleteLoadTestCronJob and loadTestCronJobLastRun manage the CronJobs of remote schedules,
+// replaced in tests
+var createLoadTestCronJob = remote.CreateLoadTestCronJob
+var deleteLoadTestCronJob = remote.DeleteLoadTestCronJob
+var loadTestCronJobLastRun = remote.LoadTestCronJobLastRun
+
+// loadTestScheduleConnection returns a connection and the URL of its PFE, replaced in tests
+var loadTestScheduleConnection = func(conID string) (*connections.Connection, string, *ProjectError) {
+	conInfo, conErr := connections.GetConnectionByID(conID)
+	if conErr != nil {
+		return nil, "", &ProjectError{conErr.Op, conErr.Err, conErr.Desc}
+	}
+	conURL, configErr := config.PFEOriginFromConnection(conInfo)
+	if configErr != nil {
+		return nil, "", &ProjectError{configErr.Op, configErr.Err, configErr.Desc}
+	}
+	return conInfo, conURL, nil
+}
+
+// getLoadTestSchedulesFile : Get the file load test schedules are kept in
+func getLoadTestSchedulesFile() string {
+	return path.Join(getCodewindDir(), "loadtest-schedules.json")
+}
+
+// AddLoadTestSchedule : Schedules load runs of a project. Remote connections get a CronJob in their workspace, which
+// runs in the time zone of the cluster; load runs on the local connection are started by the daemon. The daemon
+// archives the results of each run of either under the archive directory of the schedule.
+func AddLoadTestSchedule(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, schedule LoadTestSchedule) (*LoadTestSchedule, *ProjectError) {
+	if len(validation.IsDNS1123Label(schedule.Name)) > 0 {
+		err := i18n.Errorf(msgInvalidScheduleName, textInvalidScheduleName)
+		return nil, &ProjectError{errOpInvalidOptions, err, textInvalidScheduleName}
+	}
+	if _, err := parseCronSchedule(schedule.Schedule); err != nil {
+		return nil, &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	schedules, projErr := GetLoadTestSchedules()
+	if projErr != nil {
+		return nil, projErr
+	}
+	for _, existing := range schedules {
+		if existing.Name == schedule.Name {
+			err := i18n.Errorf(msgScheduleExists, textScheduleExists, schedule.Name)
+			return nil, &ProjectError{errOpConflict, err, err.Error()}
+		}
+	}
+
+	schedule.ConnectionID = conInfo.ID
+	if schedule.Archive == "" {
+		schedule.Archive = filepath.Join(getCodewindDir(), "loadtest-archive", schedule.Name)
+	}
+	// Runs start, and are archived, from the next time the schedule is due
+	schedule.LastRun = time.Now().UnixNano() / int64(time.Millisecond)
+	if conInfo.ID != "local" {
+		loadTestConfig, projErr := getScheduledLoadTestConfig(httpClient, conInfo, conURL, schedule.ProjectID, schedule.Profile)
+		if projErr != nil {
+			return nil, projErr
+		}
+		cronJob, remInstErr := createLoadTestCronJob(&remote.LoadTestCronJobOptions{
+			GatekeeperURL: conInfo.URL,
+			Name:          schedule.Name,
+			ProjectID:     schedule.ProjectID,
+			Schedule:      schedule.Schedule,
+			Description:   schedule.Description,
+			Config:        loadTestConfig,
+		}, nil)
+		if remInstErr != nil {
+			return nil, &ProjectError{errOpLoadTestSchedule, remInstErr, remInstErr.Desc}
+		}
+		schedule.CronJob = cronJob
+	}
+
+	if projErr := saveLoadTestSchedules(append(schedules, schedule)); projErr != nil {
+		if schedule.CronJob != nil {
+			deleteLoadTestCronJob(schedule.CronJob.Namespace, schedule.CronJob.Name, nil)
+		}
+		return nil, projErr
+	}
+	return &schedule, nil
+}
+
+// GetLoadTestSchedules : Returns the load test schedules, none when none have been added
+func GetLoadTestSchedules() ([]LoadTestSchedule, *ProjectError) {
+	schedules := []LoadTestSchedule{}
+	contents, err := ioutil.ReadFile(getLoadTestSchedulesFile())
+	if os.IsNotExist(err) {
+		return schedules, nil
+	}
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	if err := json.Unmarshal(contents, &schedules); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return schedules, nil
+}
+
+// RemoveLoadTestSchedule : Stops scheduled load runs, removing the CronJob of a remote schedule. Archived results of
+// local runs are kept.
+func RemoveLoadTestSchedule(name string) *ProjectError {
+	schedules, projErr := GetLoadTestSchedules()
+	if projErr != nil {
+		return projErr
+	}
+	for i, schedule := range schedules {
+		if schedule.Name != name {
+			continue
+		}
+		if schedule.CronJob != nil {
+			remInstErr := deleteLoadTestCronJob(schedule.CronJob.Namespace, schedule.CronJob.Name, nil)
+			if remInstErr != nil {
+				return &ProjectError{errOpLoadTestSchedule, remInstErr, remInstErr.Desc}
+			}
+		}
+		return saveLoadTestSchedules(append(schedules[:i], schedules[i+1:]...))
+	}
+	err := i18n.Errorf(msgScheduleNotFound, textScheduleNotFound, name)
+	return &ProjectError{errOpNotFound, err, err.Error()}
+}
+
+// RunDueLoadTestSchedules : Starts the load runs of local schedules due since their last run, waiting for each to
+// finish and archiving its results. A schedule missed several times while the daemon was stopped runs once. The
+// results of runs started by the CronJobs of remote schedules are archived once they finish.
+func RunDueLoadTestSchedules(httpClient utils.HTTPClient, now time.Time) []LoadTestScheduleRun {
+	schedules, projErr := GetLoadTestSchedules()
+	if projErr != nil {
+		logr.Errorf("Unable to read load test schedules: %v", projErr.Desc)
+		return nil
+	}
+	runs := []LoadTestScheduleRun{}
+	for _, schedule := range schedules {
+		if schedule.CronJob != nil {
+			if run := archiveRemoteLoadTestRun(httpClient, schedule); run != nil {
+				runs = append(runs, *run)
+			}
+			continue
+		}
+		if !loadTestScheduleDue(schedule, now) {
+			continue
+		}
+		// The run is recorded before it starts, so that a failing run waits for the next time it is due
+		if projErr := recordLoadTestRun(schedule.Name, now); projErr != nil {
+			logr.Errorf("Unable to record the load run of schedule %v: %v", schedule.Name, projErr.Desc)
+			continue
+		}
+		run := LoadTestScheduleRun{Name: schedule.Name, Status: "OK"}
+		results, projErr := runScheduledLoadTest(httpClient, schedule, now)
+		if projErr != nil {
+			run.Status = "Failed"
+			run.Error = projErr.Desc
+		}
+		run.Results = results
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// archiveRemoteLoadTestRun : Archives the results of the latest run started by the CronJob of a remote schedule, in
+// a directory named after the time it started, once the run has finished. Returns nil when there is no new run, or
+// it is still going.
+func archiveRemoteLoadTestRun(httpClient utils.HTTPClient, schedule LoadTestSchedule) *LoadTestScheduleRun {
+	started, remInstErr := loadTestCronJobLastRun(schedule.CronJob.Namespace, schedule.CronJob.Name, nil)
+	if remInstErr != nil {
+		logr.Errorf("Unable to find the load runs of schedule %v: %v", schedule.Name, remInstErr.Desc)
+		return nil
+	}
+	if started.IsZero() || started.UnixNano()/int64(time.Millisecond) <= schedule.LastRun {
+		return nil
+	}
+	conInfo, conURL, projErr := loadTestScheduleConnection(schedule.ConnectionID)
+	if projErr != nil {
+		return &LoadTestScheduleRun{Name: schedule.Name, Status: "Failed", Error: projErr.Desc}
+	}
+	status, projErr := GetLoadTestStatus(httpClient, conInfo, conURL, schedule.ProjectID)
+	if projErr != nil {
+		return &LoadTestScheduleRun{Name: schedule.Name, Status: "Failed", Error: projErr.Desc}
+	}
+	if loadTestActive(status.Status) {
+		return nil
+	}
+
+	// The run is recorded before its results are downloaded, so that failing downloads are not retried every interval
+	if projErr := recordLoadTestRun(schedule.Name, started); projErr != nil {
+		logr.Errorf("Unable to record the load run of schedule %v: %v", schedule.Name, projErr.Desc)
+		return nil
+	}
+	archive := schedule.Archive
+	if archive == "" {
+		// Remote schedules added before their runs were archived have no archive directory
+		archive = filepath.Join(getCodewindDir(), "loadtest-archive", schedule.Name)
+	}
+	run := LoadTestScheduleRun{Name: schedule.Name, Status: "OK"}
+	results, projErr := DownloadLoadTestResults(httpClient, conInfo, conURL, schedule.ProjectID, filepath.Join(archive, started.Local().Format("20060102-150405")))
+	if projErr != nil {
+		run.Status = "Failed"
+		run.Error = projErr.Desc
+		return &run
+	}
+	results.Status = status.Status
+	run.Results = results
+	return &run
+}
+
+// loadTestScheduleDue reports whether a schedule has been due since its last run, in the time zone of now
+func loadTestScheduleDue(schedule LoadTestSchedule, now time.Time) bool {
+	cron, err := parseCronSchedule(schedule.Schedule)
+	if err != nil {
+		return false
+	}
+	next := cron.next(time.Unix(0, schedule.LastRun*int64(time.Millisecond)).In(now.Location()))
+	return !next.IsZero() && !next.After(now)
+}
+
+// runScheduledLoadTest : Writes the load test config of a schedule, then runs a load test and archives its results in
+// a directory named after the time it was due
+func runScheduledLoadTest(httpClient utils.HTTPClient, schedule LoadTestSchedule, now time.Time) (*LoadTestResults, *ProjectError) {
+	conInfo, conURL, projErr := loadTestScheduleConnection(schedule.ConnectionID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	loadTestConfig, projErr := getScheduledLoadTestConfig(httpClient, conInfo, conURL, schedule.ProjectID, schedule.Profile)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if loadTestConfig != nil {
+		if projErr := writeLoadTestConfig(httpClient, conInfo, conURL, schedule.ProjectID, loadTestConfig); projErr != nil {
+			return nil, projErr
+		}
+	}
+	if projErr := StartLoadTest(httpClient, conInfo, conURL, schedule.ProjectID, schedule.Description); projErr != nil {
+		return nil, projErr
+	}
+	status, projErr := WaitForLoadTest(httpClient, conInfo, conURL, schedule.ProjectID, DefaultLoadTestTimeout)
+	if projErr != nil {
+		return nil, projErr
+	}
+	results, projErr := DownloadLoadTestResults(httpClient, conInfo, conURL, schedule.ProjectID, filepath.Join(schedule.Archive, now.Format("20060102-150405")))
+	if projErr != nil {
+		return nil, projErr
+	}
+	results.Status = status.Status
+	return results, nil
+}
+
+// getScheduledLoadTestConfig : Returns the load test config of a project with the fields of a profile overridden, or
+// nil when the profile overrides none, so that runs use the config of the project as it is then
+func getScheduledLoadTestConfig(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, profile *LoadTestProfile) ([]byte, *ProjectError) {
+	if profile == nil || *profile == (LoadTestProfile{}) {
+		return nil, nil
+	}
+	body, projErr := getProjectJSON(httpClient, conInfo, conURL+"/api/v1/projects/"+projectID+"/loadtest/config")
+	if projErr != nil {
+		return nil, projErr
+	}
+	loadTestConfig := map[string]interface{}{}
+	if err := json.Unmarshal(body, &loadTestConfig); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	overrides, _ := json.Marshal(profile)
+	json.Unmarshal(overrides, &loadTestConfig)
+	merged, _ := json.Marshal(loadTestConfig)
+	return merged, nil
+}
+
+// writeLoadTestConfig : Replaces the load test config of a project
+func writeLoadTestConfig(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, loadTestConfig []byte) *ProjectError {
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/loadtest/config", bytes.NewBuffer(loadTestConfig))
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return sendLoadTestRequest(httpClient, conInfo, req)
+}
+
+// recordLoadTestRun : Sets the last run of a schedule, rereading the schedules so that changes made since are kept
+func recordLoadTestRun(name string, now time.Time) *ProjectError {
+	schedules, projErr := GetLoadTestSchedules()
+	if projErr != nil {
+		return projErr
+	}
+	for i := range schedules {
+		if schedules[i].Name == name {
+			schedules[i].LastRun = now.UnixNano() / int64(time.Millisecond)
+		}
+	}
+	return saveLoadTestSchedules(schedules)
+}
+
+// saveLoadTestSchedules : Writes the load test schedules
+func saveLoadTestSchedules(schedules []LoadTestSchedule) *ProjectError {
+	contents, err := json.MarshalIndent(schedules, "", "  ")
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	if err := os.MkdirAll(getCodewindDir(), 0755); err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	if err := ioutil.WriteFile(getLoadTestSchedulesFile(), contents, 0644); err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
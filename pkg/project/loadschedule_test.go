@@ -0,0 +1,186 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/kubernetes"
+)
+
+func TestLoadTestSchedules(t *testing.T) {
+	home, err := ioutil.TempDir("", "loadtest-schedules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	var createdOptions *remote.LoadTestCronJobOptions
+	deleted := []string{}
+	createLoadTestCronJob = func(options *remote.LoadTestCronJobOptions, clientset kubernetes.Interface) (*remote.LoadTestCronJob, *remote.RemInstError) {
+		createdOptions = options
+		return &remote.LoadTestCronJob{Namespace: "test1", WorkspaceID: "wid1", Name: "codewind-loadtest-wid1-" + options.Name}, nil
+	}
+	deleteLoadTestCronJob = func(namespace string, name string, clientset kubernetes.Interface) *remote.RemInstError {
+		deleted = append(deleted, namespace+"/"+name)
+		return nil
+	}
+	defer func() {
+		createLoadTestCronJob = remote.CreateLoadTestCronJob
+		deleteLoadTestCronJob = remote.DeleteLoadTestCronJob
+	}()
+	remoteConnection := connections.Connection{ID: "remote1", URL: "https://codewind.example.com"}
+
+	t.Run("success case - remote schedule creates a CronJob in the workspace of the connection", func(t *testing.T) {
+		schedule, projErr := AddLoadTestSchedule(nil, &remoteConnection, "", LoadTestSchedule{Name: "nightly", ProjectID: "pid1", Schedule: "0 2 * * *"})
+		assert.Nil(t, projErr)
+		assert.Equal(t, "remote1", schedule.ConnectionID)
+		assert.Equal(t, "codewind-loadtest-wid1-nightly", schedule.CronJob.Name)
+		assert.Equal(t, filepath.Join(home, ".codewind", "loadtest-archive", "nightly"), schedule.Archive)
+		assert.Equal(t, "https://codewind.example.com", createdOptions.GatekeeperURL)
+		assert.Nil(t, createdOptions.Config)
+	})
+
+	t.Run("success case - the profile is merged into the load test config of the project", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{
+			"GET /api/v1/projects/pid1/loadtest/config": {{http.StatusOK, `{"path":"/","concurrency":20,"maxSeconds":60}`}},
+		}}
+		loadTestConfig, projErr := getScheduledLoadTestConfig(mockClient, &mockConnection, "", "pid1", &LoadTestProfile{Path: "/api", Concurrency: 50})
+		assert.Nil(t, projErr)
+		assert.JSONEq(t, `{"path":"/api","concurrency":50,"maxSeconds":60}`, string(loadTestConfig))
+	})
+
+	t.Run("success case - local schedule is archived under the Codewind directory", func(t *testing.T) {
+		schedule, projErr := AddLoadTestSchedule(nil, &mockConnection, "", LoadTestSchedule{Name: "hourly", ProjectID: "pid2", Schedule: "@hourly"})
+		assert.Nil(t, projErr)
+		assert.Nil(t, schedule.CronJob)
+		assert.Equal(t, filepath.Join(home, ".codewind", "loadtest-archive", "hourly"), schedule.Archive)
+		assert.NotZero(t, schedule.LastRun)
+	})
+
+	t.Run("error case - schedule names are unique", func(t *testing.T) {
+		_, projErr := AddLoadTestSchedule(nil, &mockConnection, "", LoadTestSchedule{Name: "nightly", ProjectID: "pid2", Schedule: "@daily"})
+		assert.Equal(t, errOpConflict, projErr.Op)
+	})
+
+	t.Run("error case - invalid name or schedule", func(t *testing.T) {
+		_, projErr := AddLoadTestSchedule(nil, &mockConnection, "", LoadTestSchedule{Name: "Nightly", ProjectID: "pid2", Schedule: "@daily"})
+		assert.Equal(t, textInvalidScheduleName, projErr.Desc)
+		_, projErr = AddLoadTestSchedule(nil, &mockConnection, "", LoadTestSchedule{Name: "often", ProjectID: "pid2", Schedule: "* * *"})
+		assert.Equal(t, textInvalidCronSchedule, projErr.Desc)
+	})
+
+	t.Run("success case - removing a remote schedule removes its CronJob", func(t *testing.T) {
+		assert.Nil(t, RemoveLoadTestSchedule("nightly"))
+		assert.Equal(t, []string{"test1/codewind-loadtest-wid1-nightly"}, deleted)
+		schedules, projErr := GetLoadTestSchedules()
+		assert.Nil(t, projErr)
+		assert.Len(t, schedules, 1)
+		assert.Equal(t, "hourly", schedules[0].Name)
+	})
+
+	t.Run("error case - removing an unknown schedule", func(t *testing.T) {
+		projErr := RemoveLoadTestSchedule("nightly")
+		assert.Equal(t, errOpNotFound, projErr.Op)
+	})
+}
+
+func TestRunDueLoadTestSchedules(t *testing.T) {
+	home, err := ioutil.TempDir("", "loadtest-runs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+	loadTestPollInterval = time.Millisecond
+	originalConnection := loadTestScheduleConnection
+	loadTestScheduleConnection = func(conID string) (*connections.Connection, string, *ProjectError) {
+		return &mockConnection, "", nil
+	}
+	defer func() { loadTestScheduleConnection = originalConnection }()
+	remoteLastRun := time.Time{}
+	loadTestCronJobLastRun = func(namespace string, name string, clientset kubernetes.Interface) (time.Time, *remote.RemInstError) {
+		return remoteLastRun, nil
+	}
+	defer func() { loadTestCronJobLastRun = remote.LoadTestCronJobLastRun }()
+
+	lastRun := time.Date(2020, time.April, 15, 1, 0, 0, 0, time.UTC)
+	saveLoadTestSchedules([]LoadTestSchedule{
+		{Name: "nightly", ProjectID: "pid1", ConnectionID: "local", Schedule: "0 2 * * *", Archive: filepath.Join(home, "nightly"),
+			Profile: &LoadTestProfile{Concurrency: 5}, LastRun: lastRun.UnixNano() / int64(time.Millisecond)},
+		{Name: "weekly", ProjectID: "pid1", ConnectionID: "local", Schedule: "@weekly", Archive: filepath.Join(home, "weekly"),
+			LastRun: lastRun.UnixNano() / int64(time.Millisecond)},
+		{Name: "remote", ProjectID: "pid2", ConnectionID: "remote1", Schedule: "* * * * *", Archive: filepath.Join(home, "remote"),
+			LastRun: lastRun.UnixNano() / int64(time.Millisecond), CronJob: &remote.LoadTestCronJob{Namespace: "test1", Name: "codewind-loadtest-wid1-remote"}},
+	})
+	mockClient := &mockLoadRunner{responses: map[string][]mockResponse{
+		"GET /api/v1/projects/pid1/loadtest/config":  {{http.StatusOK, `{"path":"/"}`}},
+		"POST /api/v1/projects/pid1/loadtest/config": {{http.StatusOK, ""}},
+		"POST /api/v1/projects/pid1/loadtest":        {{http.StatusAccepted, ""}},
+		"GET /api/v1/projects/pid1/loadtest":         {{http.StatusOK, `{"status":"running"}`}, {http.StatusOK, `{"status":"completed"}`}},
+		"GET /api/v1/projects/pid1/metrics/cpu":      {{http.StatusOK, `[]`}},
+		"GET /api/v1/projects/pid2/loadtest":         {{http.StatusOK, `{"status":"running"}`}, {http.StatusOK, `{"status":"completed"}`}},
+		"GET /api/v1/projects/pid2/metrics/cpu":      {{http.StatusOK, `[]`}},
+	}}
+
+	t.Run("success case - runs the local schedules due and archives their results", func(t *testing.T) {
+		now := time.Date(2020, time.April, 15, 2, 0, 30, 0, time.UTC)
+		runs := RunDueLoadTestSchedules(mockClient, now)
+		assert.Equal(t, []LoadTestScheduleRun{{
+			Name:    "nightly",
+			Status:  "OK",
+			Results: &LoadTestResults{Status: "completed", Directory: filepath.Join(home, "nightly", "20200415-020030"), Metrics: []string{filepath.Join(home, "nightly", "20200415-020030", "metrics-cpu.json")}},
+		}}, runs)
+		assert.Equal(t, 1, mockClient.requests["POST /api/v1/projects/pid1/loadtest/config"])
+
+		schedules, _ := GetLoadTestSchedules()
+		assert.Equal(t, now.UnixNano()/int64(time.Millisecond), schedules[0].LastRun)
+		assert.Equal(t, lastRun.UnixNano()/int64(time.Millisecond), schedules[1].LastRun)
+	})
+
+	t.Run("success case - a schedule is not run again until it is next due", func(t *testing.T) {
+		runs := RunDueLoadTestSchedules(mockClient, time.Date(2020, time.April, 15, 2, 1, 0, 0, time.UTC))
+		assert.Empty(t, runs)
+	})
+
+	t.Run("success case - archives the results of a remote run once it finishes", func(t *testing.T) {
+		remoteLastRun = time.Date(2020, time.April, 15, 2, 0, 5, 0, time.UTC)
+		runs := RunDueLoadTestSchedules(mockClient, time.Date(2020, time.April, 15, 2, 2, 0, 0, time.UTC))
+		assert.Empty(t, runs)
+
+		runs = RunDueLoadTestSchedules(mockClient, time.Date(2020, time.April, 15, 2, 3, 0, 0, time.UTC))
+		directory := filepath.Join(home, "remote", remoteLastRun.Local().Format("20060102-150405"))
+		assert.Equal(t, []LoadTestScheduleRun{{
+			Name:    "remote",
+			Status:  "OK",
+			Results: &LoadTestResults{Status: "completed", Directory: directory, Metrics: []string{filepath.Join(directory, "metrics-cpu.json")}},
+		}}, runs)
+		schedules, _ := GetLoadTestSchedules()
+		assert.Equal(t, remoteLastRun.UnixNano()/int64(time.Millisecond), schedules[2].LastRun)
+
+		runs = RunDueLoadTestSchedules(mockClient, time.Date(2020, time.April, 15, 2, 4, 0, 0, time.UTC))
+		assert.Empty(t, runs)
+	})
+}
@@ -0,0 +1,221 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LoadTestParameters : The request to start a load run
+	LoadTestParameters struct {
+		Description string `json:"description,omitempty"`
+	}
+
+	// LoadTestStatus : The state of the load runner of a project
+	LoadTestStatus struct {
+		Status string `json:"status"`
+	}
+
+	// LoadTestResults : The files load run results were downloaded to
+	LoadTestResults struct {
+		Status     string   `json:"status"`
+		Directory  string   `json:"directory"`
+		Comparison string   `json:"comparison,omitempty"`
+		Metrics    []string `json:"metrics"`
+	}
+)
+
+// DefaultLoadTestTimeout is how long a load run is waited for
+const DefaultLoadTestTimeout = 30 * time.Minute
+
+// loadTestPollInterval is how often the load runner is asked whether a run has finished
+var loadTestPollInterval = 5 * time.Second
+
+// loadTestMetricTypes are the metrics the performance dashboard collects during a load run
+var loadTestMetricTypes = []string{"cpu", "memory", "gc", "http"}
+
+// loadTestActive reports whether the load runner is running a load test, rather than idle or finished
+func loadTestActive(status string) bool {
+	switch status {
+	case "preparing", "starting", "running", "collecting":
+		return true
+	}
+	return false
+}
+
+// StartLoadTest : Starts a load run against a project, with the load test configuration of the project
+func StartLoadTest(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, description string) *ProjectError {
+	payload, _ := json.Marshal(LoadTestParameters{Description: description})
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/loadtest", bytes.NewBuffer(payload))
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	return sendLoadTestRequest(httpClient, conInfo, req)
+}
+
+// CancelLoadTest : Cancels the load run of a project
+func CancelLoadTest(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) *ProjectError {
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/loadtest/cancel", nil)
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	return sendLoadTestRequest(httpClient, conInfo, req)
+}
+
+func sendLoadTestRequest(httpClient utils.HTTPClient, conInfo *connections.Connection, req *http.Request) *ProjectError {
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusAccepted, http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	case http.StatusConflict:
+		respErr := i18n.Errorf(msgLoadTestConflict, textLoadTestConflict)
+		return &ProjectError{errOpConflict, respErr, textLoadTestConflict}
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	respErr := fmt.Errorf("Load run request failed with status code %d: %s", resp.StatusCode, string(body))
+	return &ProjectError{errOpResponse, respErr, respErr.Error()}
+}
+
+// GetLoadTestStatus : Returns the state of the load runner of a project
+func GetLoadTestStatus(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) (*LoadTestStatus, *ProjectError) {
+	body, projErr := getProjectJSON(httpClient, conInfo, conURL+"/api/v1/projects/"+projectID+"/loadtest")
+	if projErr != nil {
+		return nil, projErr
+	}
+	var status LoadTestStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return &status, nil
+}
+
+// WaitForLoadTest : Polls the load runner of a project until the run started finishes, returning its final status,
+// or returns an error after the timeout. The runner going back to idle after being active also ends the run.
+func WaitForLoadTest(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, timeout time.Duration) (*LoadTestStatus, *ProjectError) {
+	deadline := time.Now().Add(timeout)
+	seenActive := false
+	for {
+		status, projErr := GetLoadTestStatus(httpClient, conInfo, conURL, projectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+		active := loadTestActive(status.Status)
+		if !active && (status.Status != "idle" || seenActive) {
+			return status, nil
+		}
+		seenActive = seenActive || active
+		if time.Now().After(deadline) {
+			err := i18n.Errorf(msgLoadTestTimeout, textLoadTestTimeout)
+			return nil, &ProjectError{errOpLoadTestTimeout, err, textLoadTestTimeout}
+		}
+		time.Sleep(loadTestPollInterval)
+	}
+}
+
+// DownloadLoadTestResults : Writes the metrics collected during the load runs of a project, and the comparison of the
+// latest run with the one before it, to JSON files in a directory. Metrics the project does not collect are skipped.
+func DownloadLoadTestResults(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, directory string) (*LoadTestResults, *ProjectError) {
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	results := LoadTestResults{Status: "OK", Directory: directory, Metrics: []string{}}
+	projectURL := conURL + "/api/v1/projects/" + projectID
+
+	for _, metricType := range loadTestMetricTypes {
+		body, projErr := getProjectJSON(httpClient, conInfo, projectURL+"/metrics/"+metricType)
+		if projErr != nil {
+			if projErr.Op == errOpNotFound {
+				continue
+			}
+			return nil, projErr
+		}
+		fileName, projErr := writeResultFile(directory, "metrics-"+metricType+".json", body)
+		if projErr != nil {
+			return nil, projErr
+		}
+		results.Metrics = append(results.Metrics, fileName)
+	}
+
+	// A comparison needs two runs, so is missing after the first
+	body, projErr := getProjectJSON(httpClient, conInfo, projectURL+"/compare")
+	if projErr != nil && projErr.Op != errOpNotFound {
+		return nil, projErr
+	}
+	if projErr == nil {
+		results.Comparison, projErr = writeResultFile(directory, "comparison.json", body)
+		if projErr != nil {
+			return nil, projErr
+		}
+	}
+	return &results, nil
+}
+
+// writeResultFile : Writes JSON to a file in a directory, indenting it for reading
+func writeResultFile(directory string, fileName string, body []byte) (string, *ProjectError) {
+	var indented bytes.Buffer
+	if json.Indent(&indented, body, "", "  ") != nil {
+		indented.Reset()
+		indented.Write(body)
+	}
+	path := filepath.Join(directory, fileName)
+	if err := ioutil.WriteFile(path, indented.Bytes(), 0644); err != nil {
+		return "", &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return path, nil
+}
+
+// getProjectJSON : Returns the body of a successful GET request to PFE
+func getProjectJSON(httpClient utils.HTTPClient, conInfo *connections.Connection, requestURL string) ([]byte, *ProjectError) {
+	req, err := http.NewRequest("GET", requestURL, nil)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return nil, &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	}
+	if resp.StatusCode != http.StatusOK {
+		respErr := fmt.Errorf("Request failed with status code %d: %s", resp.StatusCode, string(body))
+		return nil, &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+	return body, nil
+}
@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLoadRunner responds to each request path with the next of its responses, repeating the last one
+type mockLoadRunner struct {
+	responses map[string][]mockResponse
+	requests  map[string]int
+}
+
+type mockResponse struct {
+	status int
+	body   string
+}
+
+func (c *mockLoadRunner) Do(req *http.Request) (*http.Response, error) {
+	if c.requests == nil {
+		c.requests = map[string]int{}
+	}
+	path := req.Method + " " + req.URL.Path
+	responses, ok := c.responses[path]
+	response := mockResponse{http.StatusNotFound, ""}
+	if ok {
+		response = responses[len(responses)-1]
+		if c.requests[path] < len(responses) {
+			response = responses[c.requests[path]]
+		}
+	}
+	c.requests[path]++
+	return &http.Response{StatusCode: response.status, Body: ioutil.NopCloser(bytes.NewBufferString(response.body))}, nil
+}
+
+func Test_StartLoadTest(t *testing.T) {
+	t.Run("success case - load run accepted", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{"POST /api/v1/projects/mockID/loadtest": {{http.StatusAccepted, ""}}}}
+		err := StartLoadTest(mockClient, &mockConnection, "", "mockID", "nightly")
+		assert.Nil(t, err)
+	})
+
+	t.Run("error case - load run already in progress", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{"POST /api/v1/projects/mockID/loadtest": {{http.StatusConflict, ""}}}}
+		err := StartLoadTest(mockClient, &mockConnection, "", "mockID", "")
+		assert.Equal(t, errOpConflict, err.Op)
+	})
+}
+
+func Test_WaitForLoadTest(t *testing.T) {
+	loadTestPollInterval = time.Millisecond
+	statusPath := "GET /api/v1/projects/mockID/loadtest"
+
+	t.Run("success case - waits for the run to complete", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{statusPath: {
+			{http.StatusOK, `{"status":"idle"}`},
+			{http.StatusOK, `{"status":"running"}`},
+			{http.StatusOK, `{"status":"collecting"}`},
+			{http.StatusOK, `{"status":"completed"}`},
+		}}}
+		status, err := WaitForLoadTest(mockClient, &mockConnection, "", "mockID", time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, "completed", status.Status)
+		assert.Equal(t, 4, mockClient.requests[statusPath])
+	})
+
+	t.Run("success case - runner returning to idle ends the run", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{statusPath: {
+			{http.StatusOK, `{"status":"running"}`},
+			{http.StatusOK, `{"status":"idle"}`},
+		}}}
+		status, err := WaitForLoadTest(mockClient, &mockConnection, "", "mockID", time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, "idle", status.Status)
+	})
+
+	t.Run("success case - returns a cancelled run", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{statusPath: {{http.StatusOK, `{"status":"cancelled"}`}}}}
+		status, err := WaitForLoadTest(mockClient, &mockConnection, "", "mockID", time.Second)
+		assert.Nil(t, err)
+		assert.Equal(t, "cancelled", status.Status)
+	})
+
+	t.Run("error case - times out", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{statusPath: {{http.StatusOK, `{"status":"running"}`}}}}
+		status, err := WaitForLoadTest(mockClient, &mockConnection, "", "mockID", 5*time.Millisecond)
+		assert.Nil(t, status)
+		assert.Equal(t, errOpLoadTestTimeout, err.Op)
+	})
+}
+
+func Test_DownloadLoadTestResults(t *testing.T) {
+	dir, err := ioutil.TempDir("", "loadtest-results")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	t.Run("success case - writes the metrics collected and the comparison", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{
+			"GET /api/v1/projects/mockID/metrics/cpu":  {{http.StatusOK, `[{"time":1}]`}},
+			"GET /api/v1/projects/mockID/metrics/http": {{http.StatusOK, `[{"time":2}]`}},
+			"GET /api/v1/projects/mockID/compare":      {{http.StatusOK, `{"cpu":{"delta":1}}`}},
+		}}
+		results, projErr := DownloadLoadTestResults(mockClient, &mockConnection, "", "mockID", filepath.Join(dir, "all"))
+		assert.Nil(t, projErr)
+		assert.Equal(t, []string{filepath.Join(dir, "all", "metrics-cpu.json"), filepath.Join(dir, "all", "metrics-http.json")}, results.Metrics)
+		assert.Equal(t, filepath.Join(dir, "all", "comparison.json"), results.Comparison)
+		contents, _ := ioutil.ReadFile(results.Metrics[0])
+		assert.Equal(t, "[\n  {\n    \"time\": 1\n  }\n]", string(contents))
+	})
+
+	t.Run("success case - no comparison after the first run", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{
+			"GET /api/v1/projects/mockID/metrics/cpu": {{http.StatusOK, `[]`}},
+		}}
+		results, projErr := DownloadLoadTestResults(mockClient, &mockConnection, "", "mockID", filepath.Join(dir, "first"))
+		assert.Nil(t, projErr)
+		assert.Equal(t, "", results.Comparison)
+		assert.Len(t, results.Metrics, 1)
+	})
+
+	t.Run("error case - PFE fails", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{
+			"GET /api/v1/projects/mockID/metrics/cpu": {{http.StatusInternalServerError, `failed`}},
+		}}
+		_, projErr := DownloadLoadTestResults(mockClient, &mockConnection, "", "mockID", filepath.Join(dir, "failed"))
+		assert.Equal(t, errOpResponse, projErr.Op)
+	})
+}
@@ -0,0 +1,199 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// LogInfo : A log file of a project
+	LogInfo struct {
+		LogName          string `json:"logName"`
+		WorkspaceLogPath string `json:"workspaceLogPath,omitempty"`
+	}
+
+	// ProjectLogs : The build and application logs of a project
+	ProjectLogs struct {
+		Build []LogInfo `json:"build"`
+		App   []LogInfo `json:"app"`
+	}
+
+	// LogOptions : Which logs of a project to print, and whether to keep printing what is added to them
+	LogOptions struct {
+		Type   string
+		Follow bool
+	}
+)
+
+// logPollInterval is how often logs are checked for new output when following them
+var logPollInterval = time.Second
+
+// GetProjectLogs : Lists the logs of a project
+func GetProjectLogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) (*ProjectLogs, *ProjectError) {
+	req, err := http.NewRequest("GET", conURL+"/api/v1/projects/"+projectID+"/logs", nil)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return nil, &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	}
+	if resp.StatusCode != http.StatusOK {
+		respErr := fmt.Errorf("Unable to list project logs, status code %d", resp.StatusCode)
+		return nil, &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+
+	byteArray, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	var logs ProjectLogs
+	if err := json.Unmarshal(byteArray, &logs); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return &logs, nil
+}
+
+// StreamLogs : Writes the logs of a project to out, the build logs then the app logs unless a type is given. When
+// following, the logs are checked for new output, and for logs that start, such as when a new build begins, until stop
+// is closed. A log that is replaced by a shorter one is printed again from its start.
+func StreamLogs(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, options LogOptions, out io.Writer, stop <-chan struct{}) *ProjectError {
+	if options.Type != "" && options.Type != "app" && options.Type != "build" {
+		err := errors.New("log type must be app or build")
+		return &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+
+	tail := newLogTail()
+	for {
+		projErr := tail.printNew(httpClient, conInfo, conURL, projectID, options.Type, out)
+		if projErr != nil {
+			return projErr
+		}
+		if !options.Follow {
+			return nil
+		}
+		select {
+		case <-stop:
+			return nil
+		case <-time.After(logPollInterval):
+		}
+	}
+}
+
+// logTail : How much of each log of a project has been printed, so that only new output is printed
+type logTail struct {
+	offsets map[string]int64
+	lastLog string
+}
+
+func newLogTail() *logTail {
+	return &logTail{offsets: map[string]int64{}}
+}
+
+// printNew writes the output added to the logs of a project since they were last printed, the build logs then the
+// app logs unless a type is given
+func (tail *logTail) printNew(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, onlyType string, out io.Writer) *ProjectError {
+	logs, projErr := GetProjectLogs(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return projErr
+	}
+	for _, logType := range []string{"build", "app"} {
+		if onlyType != "" && onlyType != logType {
+			continue
+		}
+		logInfos := logs.Build
+		if logType == "app" {
+			logInfos = logs.App
+		}
+		for _, logInfo := range logInfos {
+			key := logType + "/" + logInfo.LogName
+			output, offset, projErr := readLog(httpClient, conInfo, conURL, projectID, logType, logInfo.LogName, tail.offsets[key])
+			if projErr != nil {
+				return projErr
+			}
+			tail.offsets[key] = offset
+			if len(output) == 0 {
+				continue
+			}
+			// Name the log when the output switches between logs, as tail does
+			if key != tail.lastLog {
+				fmt.Fprintf(out, "==> %v <==\n", key)
+				tail.lastLog = key
+			}
+			out.Write(output)
+		}
+	}
+	return nil
+}
+
+// readLog : Returns the output of a log from an offset, and the offset to read from next. Only the new output is sent
+// when PFE supports range requests.
+func readLog(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, logType string, logName string, offset int64) ([]byte, int64, *ProjectError) {
+	logURL := conURL + "/api/v1/projects/" + projectID + "/logs/" + logType + "/" + url.PathEscape(logName)
+	req, err := http.NewRequest("GET", logURL, nil)
+	if err != nil {
+		return nil, offset, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, offset, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		// The log has not been written yet
+		return nil, 0, nil
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The log is shorter than what was read, so it was replaced
+		return readLog(httpClient, conInfo, conURL, projectID, logType, logName, 0)
+	case http.StatusOK, http.StatusPartialContent:
+	default:
+		respErr := fmt.Errorf("Unable to read the %v log, status code %d", logName, resp.StatusCode)
+		return nil, offset, &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+
+	output, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, offset, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	if resp.StatusCode == http.StatusPartialContent {
+		return output, offset + int64(len(output)), nil
+	}
+	// The whole log was sent, skip what has been printed unless the log was replaced
+	if int64(len(output)) < offset {
+		return output, int64(len(output)), nil
+	}
+	return output[offset:], int64(len(output)), nil
+}
@@ -0,0 +1,120 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// mockLogServer serves the logs of a project, supporting range requests
+type mockLogServer struct {
+	list     string
+	logs     map[string]string
+	requests int
+	// update is called after each listing of the logs, to change them between polls
+	update func(server *mockLogServer)
+}
+
+func (c *mockLogServer) Do(req *http.Request) (*http.Response, error) {
+	respond := func(status int, body string) (*http.Response, error) {
+		return &http.Response{StatusCode: status, Body: ioutil.NopCloser(bytes.NewBufferString(body))}, nil
+	}
+	path := strings.TrimPrefix(req.URL.Path, "/api/v1/projects/mockID/logs")
+	if path == "" {
+		c.requests++
+		list := c.list
+		if c.update != nil {
+			c.update(c)
+		}
+		return respond(http.StatusOK, list)
+	}
+	contents, ok := c.logs[strings.TrimPrefix(path, "/")]
+	if !ok {
+		return respond(http.StatusNotFound, "")
+	}
+	rangeHeader := req.Header.Get("Range")
+	if rangeHeader == "" {
+		return respond(http.StatusOK, contents)
+	}
+	offset, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rangeHeader, "bytes="), "-"))
+	if offset > len(contents) {
+		return respond(http.StatusRequestedRangeNotSatisfiable, "")
+	}
+	return respond(http.StatusPartialContent, contents[offset:])
+}
+
+const mockLogList = `{"build":[{"logName":"docker.build"}],"app":[{"logName":"app"}]}`
+
+func Test_StreamLogs(t *testing.T) {
+	t.Run("success case - prints the build then the app logs", func(t *testing.T) {
+		mockClient := &mockLogServer{list: mockLogList, logs: map[string]string{"build/docker.build": "building\n", "app/app": "listening\n"}}
+		var out bytes.Buffer
+		err := StreamLogs(mockClient, &mockConnection, "", "mockID", LogOptions{}, &out, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "==> build/docker.build <==\nbuilding\n==> app/app <==\nlistening\n", out.String())
+	})
+
+	t.Run("success case - prints only the logs of the type given", func(t *testing.T) {
+		mockClient := &mockLogServer{list: mockLogList, logs: map[string]string{"build/docker.build": "building\n", "app/app": "listening\n"}}
+		var out bytes.Buffer
+		err := StreamLogs(mockClient, &mockConnection, "", "mockID", LogOptions{Type: "app"}, &out, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "==> app/app <==\nlistening\n", out.String())
+	})
+
+	t.Run("success case - skips logs that have not been written", func(t *testing.T) {
+		mockClient := &mockLogServer{list: mockLogList, logs: map[string]string{"app/app": "listening\n"}}
+		var out bytes.Buffer
+		err := StreamLogs(mockClient, &mockConnection, "", "mockID", LogOptions{}, &out, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, "==> app/app <==\nlistening\n", out.String())
+	})
+
+	t.Run("success case - follows new output, and logs that are replaced", func(t *testing.T) {
+		logPollInterval = time.Millisecond
+		stop := make(chan struct{})
+		mockClient := &mockLogServer{list: `{"build":[{"logName":"docker.build"}],"app":[]}`, logs: map[string]string{"build/docker.build": "step 1\n"}}
+		mockClient.update = func(server *mockLogServer) {
+			switch server.requests {
+			case 2:
+				server.logs["build/docker.build"] += "step 2\n"
+			case 3:
+				server.logs["build/docker.build"] = "new\n"
+			case 4:
+				close(stop)
+			}
+		}
+		var out bytes.Buffer
+		err := StreamLogs(mockClient, &mockConnection, "", "mockID", LogOptions{Type: "build", Follow: true}, &out, stop)
+		assert.Nil(t, err)
+		assert.Equal(t, "==> build/docker.build <==\nstep 1\nstep 2\nnew\n", out.String())
+	})
+
+	t.Run("error case - rejects an unknown log type", func(t *testing.T) {
+		err := StreamLogs(&mockLogServer{}, &mockConnection, "", "mockID", LogOptions{Type: "deploy"}, &bytes.Buffer{}, nil)
+		assert.Equal(t, errOpInvalidOptions, err.Op)
+	})
+
+	t.Run("error case - project not found", func(t *testing.T) {
+		mockClient := &mockLogServer{list: mockLogList}
+		err := StreamLogs(mockClient, &mockConnection, "", "otherID", LogOptions{}, &bytes.Buffer{}, nil)
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
@@ -0,0 +1,37 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// longPathPrefix turns off the MAX_PATH (260 character) limit of the Windows file APIs for a path, which deep
+// node_modules trees easily exceed
+const longPathPrefix = `\\?\`
+
+// longPath returns the absolute form of a path with the long path prefix, or the path as it is if it cannot be made
+// absolute. Windows does not clean prefixed paths, so the path is cleaned first, and UNC paths use the UNC form.
+func longPath(path string) string {
+	if strings.HasPrefix(path, longPathPrefix) {
+		return path
+	}
+	absolute, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	if strings.HasPrefix(absolute, `\\`) {
+		return longPathPrefix + `UNC\` + absolute[2:]
+	}
+	return longPathPrefix + absolute
+}
@@ -0,0 +1,239 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+type (
+	// ProjectMirror : A binding of the files of a project to another connection, synced along with the project
+	ProjectMirror struct {
+		ConnectionID string `json:"conid"`
+		ProjectID    string `json:"projectID"`
+		// LastSync is the UNIX time in milliseconds of the last successful sync of the mirror
+		LastSync int64 `json:"lastSync,omitempty"`
+	}
+
+	// MirrorSyncResult : The outcome of syncing a project, or one of its mirrors, to its connection
+	MirrorSyncResult struct {
+		ConnectionID string        `json:"conid"`
+		ProjectID    string        `json:"projectID"`
+		Response     *SyncResponse `json:"response,omitempty"`
+		Error        string        `json:"error,omitempty"`
+	}
+
+	// MirrorSyncResponse : The outcome of syncing a project and its mirrors, the project first
+	MirrorSyncResponse struct {
+		Status  string             `json:"status"`
+		Results []MirrorSyncResult `json:"results"`
+	}
+)
+
+// mirrorSync syncs each connection of a mirror sync, replaced in tests
+var mirrorSync = SyncContext
+
+// getProjectMirrorsDir : Get the directory the mirrors of projects are recorded in, one file for each project
+func getProjectMirrorsDir() string {
+	return path.Join(getCodewindDir(), "mirrors")
+}
+
+// BindMirror : Binds the files of a project to another connection as a mirror of the project, so that syncing the
+// project with its mirrors keeps both connections up to date. A project has at most one mirror on each connection.
+func BindMirror(projectPath string, name string, language string, projectType string, conID string, projectID string) (*BindResponse, *ProjectError) {
+	projectConID, projErr := GetConnectionID(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	mirrors, projErr := GetProjectMirrors(projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if conID == projectConID {
+		err := i18n.Errorf(msgMirrorConnection, textMirrorConnection, projectID, conID)
+		return nil, &ProjectError{errOpConflict, err, err.Error()}
+	}
+	for _, mirror := range mirrors {
+		if mirror.ConnectionID == conID {
+			err := i18n.Errorf(msgMirrorExists, textMirrorExists, projectID, conID, mirror.ProjectID)
+			return nil, &ProjectError{errOpConflict, err, err.Error()}
+		}
+	}
+
+	response, projErr := Bind(projectPath, name, language, projectType, conID)
+	if response == nil {
+		return nil, projErr
+	}
+	// The bind syncs every file, so later syncs of the mirror only upload the files changed since
+	mirror := ProjectMirror{ConnectionID: conID, ProjectID: response.ProjectID}
+	if projErr == nil && response.StatusCode == http.StatusOK {
+		mirror.LastSync = time.Now().UnixNano() / 1000000
+	}
+	if saveErr := saveProjectMirrors(projectID, append(mirrors, mirror)); saveErr != nil {
+		return response, saveErr
+	}
+	return response, projErr
+}
+
+// GetProjectMirrors : Returns the mirrors of a project, none when it has not been mirrored
+func GetProjectMirrors(projectID string) ([]ProjectMirror, *ProjectError) {
+	mirrors := []ProjectMirror{}
+	contents, err := ioutil.ReadFile(filepath.Join(getProjectMirrorsDir(), projectID+".json"))
+	if os.IsNotExist(err) {
+		return mirrors, nil
+	}
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	if err := json.Unmarshal(contents, &mirrors); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	return mirrors, nil
+}
+
+// RemoveProjectMirror : Stops syncing a mirror with its project. The mirror stays bound to its connection, to be
+// removed as any other project.
+func RemoveProjectMirror(projectID string, conID string) *ProjectError {
+	mirrors, projErr := GetProjectMirrors(projectID)
+	if projErr != nil {
+		return projErr
+	}
+	for i, mirror := range mirrors {
+		if mirror.ConnectionID == conID {
+			return saveProjectMirrors(projectID, append(mirrors[:i], mirrors[i+1:]...))
+		}
+	}
+	err := i18n.Errorf(msgMirrorNotFound, textMirrorNotFound, projectID, conID)
+	return &ProjectError{errOpNotFound, err, err.Error()}
+}
+
+// forgetProjectMirrors : Removes the mirrors of a project that has been removed, and the project from the mirrors of
+// any project it mirrors. Failing to do so does not fail the removal.
+func forgetProjectMirrors(projectID string) {
+	os.Remove(filepath.Join(getProjectMirrorsDir(), projectID+".json"))
+	files, _ := ioutil.ReadDir(getProjectMirrorsDir())
+	for _, file := range files {
+		mirroredID := strings.TrimSuffix(file.Name(), ".json")
+		mirrors, projErr := GetProjectMirrors(mirroredID)
+		if projErr != nil {
+			continue
+		}
+		kept := []ProjectMirror{}
+		for _, mirror := range mirrors {
+			if mirror.ProjectID != projectID {
+				kept = append(kept, mirror)
+			}
+		}
+		if len(kept) != len(mirrors) {
+			saveProjectMirrors(mirroredID, kept)
+		}
+	}
+}
+
+// SyncMirrors : Syncs a project and its mirrors at the same time, returning the outcome for each connection. The
+// project uploads the files changed since the LastSync of the options, and each mirror those changed since its own
+// last successful sync, so that a mirror that could not be reached catches up when it next syncs.
+func SyncMirrors(ctx context.Context, options SyncOptions) (*MirrorSyncResponse, *ProjectError) {
+	mirrors, projErr := GetProjectMirrors(options.ProjectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	conID := options.ConnectionID
+	if conID == "" {
+		conID, projErr = GetConnectionID(options.ProjectID)
+		if projErr != nil {
+			return nil, projErr
+		}
+	}
+
+	targets := []SyncOptions{options}
+	targets[0].ConnectionID = conID
+	results := []MirrorSyncResult{{ConnectionID: conID, ProjectID: options.ProjectID}}
+	for _, mirror := range mirrors {
+		target := options
+		target.ProjectID, target.ConnectionID, target.LastSync = mirror.ProjectID, mirror.ConnectionID, mirror.LastSync
+		targets = append(targets, target)
+		results = append(results, MirrorSyncResult{ConnectionID: mirror.ConnectionID, ProjectID: mirror.ProjectID})
+	}
+
+	started := time.Now().UnixNano() / 1000000
+	var wg sync.WaitGroup
+	for i := range targets {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			response, syncErr := mirrorSync(ctx, targets[i])
+			results[i].Response = response
+			if syncErr != nil {
+				results[i].Error = syncErr.Desc
+			} else if response.StatusCode != http.StatusOK {
+				results[i].Error = response.Status
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	failed := []string{}
+	for i, result := range results {
+		if result.Error != "" {
+			failed = append(failed, result.ConnectionID)
+		} else if i > 0 {
+			mirrors[i-1].LastSync = started
+		}
+	}
+	if len(mirrors) > 0 {
+		saveProjectMirrors(options.ProjectID, mirrors)
+	}
+	response := MirrorSyncResponse{Status: "OK", Results: results}
+	if ctx.Err() != nil {
+		return &response, &ProjectError{errOpSyncCancelled, i18n.Errorf(msgSyncCancelled, textSyncCancelled), textSyncCancelled}
+	}
+	if len(failed) > 0 {
+		response.Status = "Failed"
+		err := i18n.Errorf(msgMirrorSyncFailed, textMirrorSyncFailed, strings.Join(failed, ", "))
+		return &response, &ProjectError{errOpSyncMirror, err, err.Error()}
+	}
+	return &response, nil
+}
+
+// saveProjectMirrors : Writes the mirrors of a project, removing the file once it has none
+func saveProjectMirrors(projectID string, mirrors []ProjectMirror) *ProjectError {
+	mirrorsFile := filepath.Join(getProjectMirrorsDir(), projectID+".json")
+	if len(mirrors) == 0 {
+		if err := os.Remove(mirrorsFile); err != nil && !os.IsNotExist(err) {
+			return &ProjectError{errOpFileDelete, err, err.Error()}
+		}
+		return nil
+	}
+	contents, err := json.MarshalIndent(mirrors, "", "  ")
+	if err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	if err := os.MkdirAll(getProjectMirrorsDir(), 0755); err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	if err := ioutil.WriteFile(mirrorsFile, contents, 0644); err != nil {
+		return &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return nil
+}
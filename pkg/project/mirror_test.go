@@ -0,0 +1,148 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProjectMirrors(t *testing.T) {
+	home, err := ioutil.TempDir("", "mirror-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+
+	t.Run("success case - project without mirrors", func(t *testing.T) {
+		mirrors, projErr := GetProjectMirrors("mockID")
+		assert.Nil(t, projErr)
+		assert.Empty(t, mirrors)
+	})
+
+	t.Run("success case - removes a mirror", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 0}, {"remote2", "mirror2", 0}})
+		projErr := RemoveProjectMirror("mockID", "remote1")
+		assert.Nil(t, projErr)
+		mirrors, _ := GetProjectMirrors("mockID")
+		assert.Equal(t, []ProjectMirror{{"remote2", "mirror2", 0}}, mirrors)
+	})
+
+	t.Run("success case - removing the last mirror removes the file", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 0}})
+		RemoveProjectMirror("mockID", "remote1")
+		files, _ := ioutil.ReadDir(getProjectMirrorsDir())
+		assert.Len(t, files, 0)
+	})
+
+	t.Run("error case - connection without a mirror", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 0}})
+		defer saveProjectMirrors("mockID", nil)
+		projErr := RemoveProjectMirror("mockID", "remote2")
+		assert.Equal(t, errOpNotFound, projErr.Op)
+	})
+
+	t.Run("success case - forgets a removed project and the projects it mirrors", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 0}})
+		saveProjectMirrors("otherID", []ProjectMirror{{"remote1", "mockID", 0}, {"remote2", "mirror2", 0}})
+		defer saveProjectMirrors("otherID", nil)
+		forgetProjectMirrors("mockID")
+		mirrors, _ := GetProjectMirrors("mockID")
+		assert.Empty(t, mirrors)
+		mirrors, _ = GetProjectMirrors("otherID")
+		assert.Equal(t, []ProjectMirror{{"remote2", "mirror2", 0}}, mirrors)
+	})
+}
+
+func TestSyncMirrors(t *testing.T) {
+	home, err := ioutil.TempDir("", "mirror-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+	originalSync := mirrorSync
+	defer func() { mirrorSync = originalSync }()
+
+	options := SyncOptions{Path: "/project", ProjectID: "mockID", ConnectionID: "local", LastSync: 100}
+
+	t.Run("success case - syncs the project and each mirror from its last sync", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 50}, {"remote2", "mirror2", 0}})
+		var lock sync.Mutex
+		synced := map[string]SyncOptions{}
+		mirrorSync = func(ctx context.Context, target SyncOptions) (*SyncResponse, *ProjectError) {
+			lock.Lock()
+			defer lock.Unlock()
+			synced[target.ConnectionID] = target
+			return &SyncResponse{Status: "Success", StatusCode: http.StatusOK}, nil
+		}
+		response, projErr := SyncMirrors(context.Background(), options)
+		assert.Nil(t, projErr)
+		assert.Equal(t, "OK", response.Status)
+		assert.Len(t, response.Results, 3)
+		assert.Equal(t, MirrorSyncResult{"local", "mockID", &SyncResponse{Status: "Success", StatusCode: http.StatusOK}, ""}, response.Results[0])
+		assert.Equal(t, options, synced["local"])
+		assert.Equal(t, "mirror1", synced["remote1"].ProjectID)
+		assert.Equal(t, int64(50), synced["remote1"].LastSync)
+		assert.Equal(t, "/project", synced["remote2"].Path)
+		assert.Equal(t, int64(0), synced["remote2"].LastSync)
+		mirrors, _ := GetProjectMirrors("mockID")
+		assert.True(t, mirrors[0].LastSync > 50)
+		assert.True(t, mirrors[1].LastSync > 0)
+	})
+
+	t.Run("error case - a connection that fails keeps its last sync", func(t *testing.T) {
+		saveProjectMirrors("mockID", []ProjectMirror{{"remote1", "mirror1", 50}, {"remote2", "mirror2", 50}})
+		mirrorSync = func(ctx context.Context, target SyncOptions) (*SyncResponse, *ProjectError) {
+			switch target.ConnectionID {
+			case "remote1":
+				err := errors.New("connection refused")
+				return nil, &ProjectError{errOpSync, err, err.Error()}
+			case "remote2":
+				return &SyncResponse{Status: "Failed", StatusCode: http.StatusInternalServerError}, nil
+			}
+			return &SyncResponse{Status: "Success", StatusCode: http.StatusOK}, nil
+		}
+		response, projErr := SyncMirrors(context.Background(), options)
+		assert.Equal(t, errOpSyncMirror, projErr.Op)
+		assert.Equal(t, "sync failed on connections remote1, remote2", projErr.Desc)
+		assert.Equal(t, "Failed", response.Status)
+		assert.Equal(t, "", response.Results[0].Error)
+		assert.Equal(t, "connection refused", response.Results[1].Error)
+		assert.Equal(t, "Failed", response.Results[2].Error)
+		mirrors, _ := GetProjectMirrors("mockID")
+		assert.Equal(t, []ProjectMirror{{"remote1", "mirror1", 50}, {"remote2", "mirror2", 50}}, mirrors)
+	})
+
+	t.Run("error case - cancelled sync", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		mirrorSync = func(ctx context.Context, target SyncOptions) (*SyncResponse, *ProjectError) {
+			return nil, &ProjectError{errOpSyncCancelled, ctx.Err(), textSyncCancelled}
+		}
+		response, projErr := SyncMirrors(ctx, options)
+		assert.Equal(t, errOpSyncCancelled, projErr.Op)
+		assert.Len(t, response.Results, 3)
+	})
+}
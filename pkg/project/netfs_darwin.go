@@ -0,0 +1,34 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import "syscall"
+
+// networkFilesystemTypes are the names of the network file systems statfs reports, whose modification times and
+// change notifications cannot be relied on
+var networkFilesystemTypes = map[string]bool{"nfs": true, "smbfs": true, "afpfs": true, "webdav": true, "cifs": true}
+
+// isNetworkFilesystem reports whether a path is on a network file system
+func isNetworkFilesystem(path string) bool {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	name := []byte{}
+	for _, c := range stat.Fstypename {
+		if c == 0 {
+			break
+		}
+		name = append(name, byte(c))
+	}
+	return networkFilesystemTypes[string(name)]
+}
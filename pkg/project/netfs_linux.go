@@ -0,0 +1,37 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import "syscall"
+
+// networkFilesystemTypes are the magic numbers of the network file systems statfs reports, whose modification times
+// and change notifications cannot be relied on: NFS, SMB, CIFS, SMB2, AFS, Coda, 9P (used by WSL 2 for Windows
+// drives) and Ceph
+var networkFilesystemTypes = map[uint32]bool{
+	0x6969:     true,
+	0x517B:     true,
+	0xFF534D42: true,
+	0xFE534D42: true,
+	0x5346414F: true,
+	0x73757245: true,
+	0x01021997: true,
+	0x00C36400: true,
+}
+
+// isNetworkFilesystem reports whether a path is on a network file system
+func isNetworkFilesystem(path string) bool {
+	stat := syscall.Statfs_t{}
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return false
+	}
+	return networkFilesystemTypes[uint32(stat.Type)]
+}
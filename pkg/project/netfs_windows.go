@@ -0,0 +1,42 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+// driveRemote is the type GetDriveType gives mapped network drives
+const driveRemote = 4
+
+var getDriveType = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+// isNetworkFilesystem reports whether a path is on a network share, given by a UNC path or a mapped drive
+func isNetworkFilesystem(path string) bool {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return false
+	}
+	volume := filepath.VolumeName(absPath)
+	if strings.HasPrefix(volume, `\\`) {
+		return true
+	}
+	root, err := syscall.UTF16PtrFromString(volume + `\`)
+	if err != nil || getDriveType.Find() != nil {
+		return false
+	}
+	driveType, _, _ := getDriveType.Call(uintptr(unsafe.Pointer(root)))
+	return driveType == driveRemote
+}
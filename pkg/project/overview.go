@@ -0,0 +1,123 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/config"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+const (
+	// ConnectionHealthy : Codewind responded on the connection
+	ConnectionHealthy = "healthy"
+	// ConnectionUnreachable : Codewind did not respond on the connection
+	ConnectionUnreachable = "unreachable"
+)
+
+type (
+	// Overview : The health and bound projects of every connection
+	Overview struct {
+		Connections []ConnectionOverview `json:"connections"`
+	}
+
+	// ConnectionOverview : The health and bound projects of a single connection
+	ConnectionOverview struct {
+		ID       string    `json:"id"`
+		Label    string    `json:"label"`
+		URL      string    `json:"url"`
+		Health   string    `json:"health"`
+		Version  string    `json:"version,omitempty"`
+		Projects []Project `json:"projects"`
+		Error    string    `json:"error,omitempty"`
+	}
+)
+
+// overviewTimeout is how long the overview waits to connect to a connection, or for it to respond, before reporting it
+// as unreachable
+const overviewTimeout = 5 * time.Second
+
+// GetOverview : Gathers the health and bound projects of each given connection, querying the connections at the same
+// time. Connection errors are reported in the overview rather than returned, so that as many connections as possible
+// are shown, and requests are not retried, so that an unreachable connection does not hold up the overview.
+func GetOverview(httpClient utils.HTTPClient, connectionsList []connections.Connection) Overview {
+	overview := Overview{Connections: make([]ConnectionOverview, len(connectionsList))}
+	var wg sync.WaitGroup
+	for i, connection := range connectionsList {
+		wg.Add(1)
+		go func(i int, connection connections.Connection) {
+			defer wg.Done()
+			overview.Connections[i] = getConnectionOverview(httpClient, overviewConnection(connection))
+		}(i, connection)
+	}
+	wg.Wait()
+	return overview
+}
+
+// overviewConnection returns a copy of a connection whose requests are not retried, and give up after
+// overviewTimeout unless the connection sets shorter timeouts
+func overviewConnection(connection connections.Connection) connections.Connection {
+	noRetries := 0
+	connection.Retries = &noRetries
+	timeouts := sechttp.ConnectionTimeouts(&connection)
+	if timeouts.Connect > overviewTimeout {
+		connection.ConnectTimeout = overviewTimeout.String()
+	}
+	if timeouts.Read > overviewTimeout {
+		connection.ReadTimeout = overviewTimeout.String()
+	}
+	return connection
+}
+
+func getConnectionOverview(httpClient utils.HTTPClient, connection connections.Connection) ConnectionOverview {
+	conOverview := ConnectionOverview{
+		ID:       connection.ID,
+		Label:    connection.Label,
+		URL:      connection.URL,
+		Health:   ConnectionUnreachable,
+		Projects: []Project{},
+	}
+
+	conURL, conErr := config.PFEOriginFromConnection(&connection)
+	if conErr != nil {
+		conOverview.Error = conErr.Desc
+		return conOverview
+	}
+	conOverview.URL = conURL
+
+	version, versionErr := apiroutes.GetPFEVersionFromConnection(&connection, conURL, httpClient)
+	if versionErr != nil {
+		conOverview.Error = versionErr.Error()
+		return conOverview
+	}
+	if version == "" {
+		conOverview.Error = textNoCodewind
+		return conOverview
+	}
+	conOverview.Health = ConnectionHealthy
+	conOverview.Version = version
+
+	projects, projErr := GetAll(httpClient, &connection, conURL)
+	if projErr != nil {
+		conOverview.Error = projErr.Desc
+		return conOverview
+	}
+	if projects != nil {
+		conOverview.Projects = projects
+	}
+	return conOverview
+}
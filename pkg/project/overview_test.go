@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitingClient answers requests once the expected number of them are waiting, so it only answers requests that are
+// sent at the same time
+type waitingClient struct {
+	sync.Mutex
+	requests int
+	expected int
+	waiting  chan struct{}
+}
+
+func (c *waitingClient) Do(req *http.Request) (*http.Response, error) {
+	c.Lock()
+	c.requests++
+	if c.requests == c.expected {
+		close(c.waiting)
+	}
+	c.Unlock()
+	select {
+	case <-c.waiting:
+	case <-time.After(5 * time.Second):
+		return nil, errors.New("requests were not sent at the same time")
+	}
+	return &http.Response{StatusCode: http.StatusServiceUnavailable, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+}
+
+func TestGetOverview(t *testing.T) {
+	// resolve the local PFE origin without querying docker
+	os.Setenv("CHE_API_EXTERNAL", "true")
+	defer os.Unsetenv("CHE_API_EXTERNAL")
+
+	mockConnections := []connections.Connection{{ID: "local", Label: "Codewind local connection"}}
+
+	t.Run("success case - healthy connection reports its projects", func(t *testing.T) {
+		projectList := []Project{
+			{ProjectID: "1234", Name: "App1", AppStatus: "started", BuildStatus: "success", LastSync: 1580000000000},
+		}
+		mockClient := &apiroutes.MockMultipleResponses{
+			MockResponses: []apiroutes.MockResponse{
+				{StatusCode: http.StatusOK, Body: apiroutes.CreateMockResponseBody(apiroutes.EnvResponse{Version: "0.9.0"})},
+				{StatusCode: http.StatusOK, Body: apiroutes.CreateMockResponseBody(projectList)},
+			},
+		}
+		overview := GetOverview(mockClient, mockConnections)
+		assert.Len(t, overview.Connections, 1)
+		assert.Equal(t, ConnectionHealthy, overview.Connections[0].Health)
+		assert.Equal(t, "0.9.0", overview.Connections[0].Version)
+		assert.Equal(t, projectList, overview.Connections[0].Projects)
+		assert.Empty(t, overview.Connections[0].Error)
+	})
+
+	t.Run("fail case - unresponsive connection is reported as unreachable", func(t *testing.T) {
+		mockClient := &apiroutes.MockMultipleResponses{
+			MockResponses: []apiroutes.MockResponse{
+				{StatusCode: http.StatusServiceUnavailable, Body: apiroutes.CreateMockResponseBody(nil)},
+			},
+		}
+		overview := GetOverview(mockClient, mockConnections)
+		assert.Len(t, overview.Connections, 1)
+		assert.Equal(t, ConnectionUnreachable, overview.Connections[0].Health)
+		assert.Equal(t, []Project{}, overview.Connections[0].Projects)
+		assert.Equal(t, textNoCodewind, overview.Connections[0].Error)
+	})
+
+	t.Run("fail case - connections are queried at the same time and not retried", func(t *testing.T) {
+		mockClient := &waitingClient{expected: 2, waiting: make(chan struct{})}
+		overview := GetOverview(mockClient, []connections.Connection{{ID: "local", Label: "first"}, {ID: "local", Label: "second"}})
+		assert.Len(t, overview.Connections, 2)
+		assert.Equal(t, "first", overview.Connections[0].Label)
+		assert.Equal(t, "second", overview.Connections[1].Label)
+		for _, conOverview := range overview.Connections {
+			assert.Equal(t, ConnectionUnreachable, conOverview.Health)
+			assert.Equal(t, textNoCodewind, conOverview.Error)
+		}
+		assert.Equal(t, 2, mockClient.requests)
+	})
+}
+
+func TestOverviewConnection(t *testing.T) {
+	t.Run("success case - requests are not retried and time out quickly", func(t *testing.T) {
+		got := overviewConnection(connections.Connection{ID: "remote1", ReadTimeout: "1m"})
+		assert.Equal(t, 0, *got.Retries)
+		assert.Equal(t, "5s", got.ConnectTimeout)
+		assert.Equal(t, "5s", got.ReadTimeout)
+	})
+
+	t.Run("success case - shorter timeouts of the connection are kept", func(t *testing.T) {
+		got := overviewConnection(connections.Connection{ID: "remote1", ConnectTimeout: "2s"})
+		assert.Equal(t, "2s", got.ConnectTimeout)
+		assert.Equal(t, "5s", got.ReadTimeout)
+	})
+}
@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// PortForwardResult : The local address a project application is reached at
+type PortForwardResult struct {
+	Status        string `json:"status"`
+	StatusMessage string `json:"status_message"`
+	ProjectID     string `json:"projectID"`
+	AppAddress    string `json:"appAddress"`
+}
+
+// GetAppTarget : Returns where the application port of a running project is reached. Projects of remote connections
+// are reached through their pod, on the port the application listens on inside it.
+func GetAppTarget(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) (*PortTarget, *ProjectError) {
+	project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	return appTargetOf(project, conInfo.ID != "local")
+}
+
+func appTargetOf(project *Project, remote bool) (*PortTarget, *ProjectError) {
+	if status := strings.ToLower(project.AppStatus); status != "starting" && status != "started" {
+		err := i18n.Errorf(msgAppNotRunning, textAppNotRunning)
+		return nil, &ProjectError{errOpNotRunning, err, textAppNotRunning}
+	}
+	if project.Ports != nil {
+		if remote {
+			port, err := strconv.Atoi(project.Ports.InternalPort)
+			if err == nil && project.PodName != "" && project.Namespace != "" {
+				return &PortTarget{PodName: project.PodName, Namespace: project.Namespace, Port: port}, nil
+			}
+		} else if port, err := strconv.Atoi(project.Ports.ExposedPort); err == nil {
+			return &PortTarget{Host: "localhost", Port: port}, nil
+		}
+	}
+	err := i18n.Errorf(msgAppPortUnknown, textAppPortUnknown)
+	return nil, &ProjectError{errOpNotFound, err, textAppPortUnknown}
+}
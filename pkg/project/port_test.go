@@ -0,0 +1,69 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_AppTargetOf(t *testing.T) {
+	appPorts := &Ports{InternalPort: "3000", ExposedPort: "32770"}
+	tests := map[string]struct {
+		project Project
+		remote  bool
+		want    *PortTarget
+		wantOp  string
+	}{
+		"local project exposes its application port": {
+			project: Project{AppStatus: "started", Ports: appPorts},
+			want:    &PortTarget{Host: "localhost", Port: 32770},
+		},
+		"remote project is reached through its pod": {
+			project: Project{AppStatus: "Started", Ports: appPorts, PodName: "pod", Namespace: "ns"},
+			remote:  true,
+			want:    &PortTarget{PodName: "pod", Namespace: "ns", Port: 3000},
+		},
+		"project is stopped": {
+			project: Project{AppStatus: "stopped", Ports: appPorts},
+			wantOp:  errOpNotRunning,
+		},
+		"application port not reported": {
+			project: Project{AppStatus: "started"},
+			wantOp:  errOpNotFound,
+		},
+		"remote pod not reported": {
+			project: Project{AppStatus: "started", Ports: appPorts},
+			remote:  true,
+			wantOp:  errOpNotFound,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			target, projErr := appTargetOf(&test.project, test.remote)
+			assert.Equal(t, test.want, target)
+			if test.wantOp == "" {
+				assert.Nil(t, projErr)
+			} else {
+				assert.Equal(t, test.wantOp, projErr.Op)
+			}
+		})
+	}
+}
+
+func Test_GetAppTarget(t *testing.T) {
+	mockClient := &mockProjectSequence{projects: []string{`{"projectID":"mockID","appStatus":"started","ports":{"exposedPort":"32770"}}`}}
+	target, projErr := GetAppTarget(mockClient, &mockConnection, "mockURL", "mockID")
+	assert.Nil(t, projErr)
+	assert.Equal(t, &PortTarget{Host: "localhost", Port: 32770}, target)
+}
@@ -0,0 +1,160 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// ProfilingRun : A load run of a project, whose profiling data can be downloaded
+	ProfilingRun struct {
+		// Time is when the run started, in milliseconds, and identifies the run
+		Time        int64  `json:"time"`
+		Description string `json:"description,omitempty"`
+	}
+
+	// ProfilingDownload : The file the profiling data of a load run was downloaded to
+	ProfilingDownload struct {
+		Status string       `json:"status"`
+		Run    ProfilingRun `json:"run"`
+		File   string       `json:"file"`
+		Size   int64        `json:"size"`
+	}
+
+	// metricsEntry is the part of an entry of the metrics of a project that describes its load run
+	metricsEntry struct {
+		Time int64  `json:"time"`
+		Desc string `json:"desc"`
+	}
+)
+
+// ListProfilingRuns : Returns the load runs of a project, oldest first. The performance container profiles the
+// project during each run, as .hcd health center data for Java and JSON for Node.js.
+func ListProfilingRuns(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string) ([]ProfilingRun, *ProjectError) {
+	runs := map[int64]ProfilingRun{}
+	for _, metricType := range loadTestMetricTypes {
+		body, projErr := getProjectJSON(httpClient, conInfo, conURL+"/api/v1/projects/"+projectID+"/metrics/"+metricType)
+		if projErr != nil {
+			if projErr.Op == errOpNotFound {
+				continue
+			}
+			return nil, projErr
+		}
+		entries := []metricsEntry{}
+		if err := json.Unmarshal(body, &entries); err != nil {
+			return nil, &ProjectError{errOpResponse, err, err.Error()}
+		}
+		for _, entry := range entries {
+			if run, ok := runs[entry.Time]; !ok || run.Description == "" {
+				runs[entry.Time] = ProfilingRun{Time: entry.Time, Description: entry.Desc}
+			}
+		}
+	}
+
+	list := []ProfilingRun{}
+	for _, run := range runs {
+		list = append(list, run)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Time < list[j].Time })
+	return list, nil
+}
+
+// DownloadProfilingData : Downloads the raw profiling data of a load run to a directory, keeping the name PFE gives
+// the file. The latest run is downloaded when runTime is 0.
+func DownloadProfilingData(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, runTime int64, directory string) (*ProfilingDownload, *ProjectError) {
+	runs, projErr := ListProfilingRuns(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	run, found := ProfilingRun{}, false
+	for _, candidate := range runs {
+		if runTime == 0 || candidate.Time == runTime {
+			run, found = candidate, true
+		}
+	}
+	if !found {
+		err := i18n.Errorf(msgNoProfilingRun, textNoProfilingRun)
+		return nil, &ProjectError{errOpNotFound, err, textNoProfilingRun}
+	}
+
+	req, err := http.NewRequest("GET", conURL+"/api/v1/projects/"+projectID+"/profiling/"+strconv.FormatInt(run.Time, 10), nil)
+	if err != nil {
+		return nil, &ProjectError{errOpRequest, err, err.Error()}
+	}
+	// The download is streamed, so a large file is only stopped by the read timeout if it stalls
+	resp, httpSecError := sechttp.DispatchHTTPRequestContext(sechttp.WithoutRequestTimeout(req.Context()), httpClient, req, conInfo)
+	if httpSecError != nil {
+		return nil, &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		err := i18n.Errorf(msgNoProfilingData, textNoProfilingData)
+		return nil, &ProjectError{errOpNotFound, err, textNoProfilingData}
+	default:
+		respErr := fmt.Errorf("Request failed with status code %d", resp.StatusCode)
+		return nil, &ProjectError{errOpResponse, respErr, respErr.Error()}
+	}
+
+	if err := os.MkdirAll(directory, 0755); err != nil {
+		return nil, &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	// Profiling data can be large, so it is streamed to a file that is only renamed once complete
+	body := bufio.NewReader(resp.Body)
+	file := filepath.Join(directory, profilingFileName(resp.Header, body, run))
+	staged, err := os.Create(file + ".download")
+	if err != nil {
+		return nil, &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	size, err := io.Copy(staged, body)
+	staged.Close()
+	if err == nil {
+		err = os.Rename(staged.Name(), file)
+	}
+	if err != nil {
+		os.Remove(staged.Name())
+		return nil, &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return &ProfilingDownload{Status: "OK", Run: run, File: file, Size: size}, nil
+}
+
+// profilingFileName returns the name of the file PFE sent, or a name for the run ending in .json when the data is
+// JSON and .hcd otherwise
+func profilingFileName(header http.Header, body *bufio.Reader, run ProfilingRun) string {
+	if _, params, err := mime.ParseMediaType(header.Get("Content-Disposition")); err == nil {
+		name := filepath.Base(params["filename"])
+		if name != "." && name != ".." && name != string(filepath.Separator) {
+			return name
+		}
+	}
+	name := "profiling-" + strconv.FormatInt(run.Time, 10)
+	if first, err := body.Peek(1); err == nil && (first[0] == '{' || first[0] == '[') {
+		return name + ".json"
+	}
+	return name + ".hcd"
+}
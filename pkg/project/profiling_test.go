@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bufio"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// profilingResponses are the metrics of two load runs, each reported by more than one type of metric
+var profilingResponses = map[string][]mockResponse{
+	"GET /api/v1/projects/mockID/metrics/cpu":    {{http.StatusOK, `[{"container":"a","time":1580000000000,"desc":"first","value":{}},{"time":1590000000000,"desc":""}]`}},
+	"GET /api/v1/projects/mockID/metrics/memory": {{http.StatusOK, `[{"time":1590000000000,"desc":"second"}]`}},
+	"GET /api/v1/projects/mockID/metrics/gc":     {{http.StatusOK, `[]`}},
+}
+
+func Test_ListProfilingRuns(t *testing.T) {
+	mockClient := &mockLoadRunner{responses: profilingResponses}
+	runs, err := ListProfilingRuns(mockClient, &mockConnection, "", "mockID")
+	assert.Nil(t, err)
+	assert.Equal(t, []ProfilingRun{{1580000000000, "first"}, {1590000000000, "second"}}, runs)
+}
+
+func Test_DownloadProfilingData(t *testing.T) {
+	directory, err := ioutil.TempDir("", "profiling")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(directory)
+
+	responses := map[string][]mockResponse{
+		"GET /api/v1/projects/mockID/profiling/1580000000000": {{http.StatusOK, "hcd data"}},
+		"GET /api/v1/projects/mockID/profiling/1590000000000": {{http.StatusOK, `{"nodes":[]}`}},
+	}
+	for path, response := range profilingResponses {
+		responses[path] = response
+	}
+
+	t.Run("success case - downloads the latest run", func(t *testing.T) {
+		download, projErr := DownloadProfilingData(&mockLoadRunner{responses: responses}, &mockConnection, "", "mockID", 0, directory)
+		assert.Nil(t, projErr)
+		assert.Equal(t, int64(1590000000000), download.Run.Time)
+		assert.Equal(t, filepath.Join(directory, "profiling-1590000000000.json"), download.File)
+		contents, _ := ioutil.ReadFile(download.File)
+		assert.Equal(t, `{"nodes":[]}`, string(contents))
+		assert.Equal(t, int64(len(contents)), download.Size)
+	})
+
+	t.Run("success case - downloads the run given", func(t *testing.T) {
+		download, projErr := DownloadProfilingData(&mockLoadRunner{responses: responses}, &mockConnection, "", "mockID", 1580000000000, directory)
+		assert.Nil(t, projErr)
+		assert.Equal(t, filepath.Join(directory, "profiling-1580000000000.hcd"), download.File)
+	})
+
+	t.Run("error case - run not found", func(t *testing.T) {
+		_, projErr := DownloadProfilingData(&mockLoadRunner{responses: responses}, &mockConnection, "", "mockID", 1, directory)
+		assert.Equal(t, errOpNotFound, projErr.Op)
+		assert.Equal(t, textNoProfilingRun, projErr.Desc)
+	})
+
+	t.Run("error case - run has no profiling data", func(t *testing.T) {
+		delete(responses, "GET /api/v1/projects/mockID/profiling/1590000000000")
+		_, projErr := DownloadProfilingData(&mockLoadRunner{responses: responses}, &mockConnection, "", "mockID", 0, directory)
+		assert.Equal(t, textNoProfilingData, projErr.Desc)
+	})
+}
+
+func Test_profilingFileName(t *testing.T) {
+	run := ProfilingRun{Time: 1580000000000}
+	tests := map[string]struct {
+		disposition string
+		body        string
+		expected    string
+	}{
+		"name given by PFE":               {`attachment; filename="app-2020.hcd"`, "{", "app-2020.hcd"},
+		"directories of the name dropped": {`attachment; filename="../../app.hcd"`, "", "app.hcd"},
+		"JSON data without a name":        {"", "[", "profiling-1580000000000.json"},
+		"other data without a name":       {"", "PK", "profiling-1580000000000.hcd"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			header := http.Header{}
+			if test.disposition != "" {
+				header.Set("Content-Disposition", test.disposition)
+			}
+			body := bufio.NewReader(strings.NewReader(test.body))
+			assert.Equal(t, test.expected, profilingFileName(header, body, run))
+		})
+	}
+}
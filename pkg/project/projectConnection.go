@@ -13,17 +13,35 @@ package project
 
 import (
 	"errors"
-	"net/http"
 	"os"
 	"path"
 	"runtime"
+	"sync"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 )
 
-// GetConnectionID : Gets the the connectionID for a given projectID
+// discoveredConnections caches the connection IDs of projects found by asking the connections for their projects,
+// keyed by project ID, so that commands working through many projects ask only once per project
+var discoveredConnections sync.Map
+
+// GetConnectionID : Gets the the connectionID for a given projectID. The connection recorded when the project was
+// bound on this machine is used if it is still configured, otherwise the active connections are asked for the project.
 func GetConnectionID(projectID string) (string, *ProjectError) {
+	if conID, ok := connections.GetProjectConnectionID(projectID); ok {
+		if conInfo, conErr := connections.GetConnectionByID(conID); conErr == nil {
+			return conInfo.ID, nil
+		}
+	}
+	if conID, ok := discoveredConnections.Load(projectID); ok {
+		if conInfo, conErr := connections.GetConnectionByID(conID.(string)); conErr == nil {
+			return conInfo.ID, nil
+		}
+		discoveredConnections.Delete(projectID)
+	}
+
 	allConnections, getConConfigErr := connections.GetConnectionsConfig()
 	if getConConfigErr != nil {
 		return "", &ProjectError{errOpConNotFound, getConConfigErr, getConConfigErr.Error()}
@@ -43,7 +61,7 @@ func GetConnectionID(projectID string) (string, *ProjectError) {
 			continue
 		}
 
-		projects, getAllErr := GetAll(http.DefaultClient, conInfo, conURL)
+		projects, getAllErr := GetAll(sechttp.Client(), conInfo, conURL)
 		if getAllErr != nil {
 			// Skip the connection if it's not running (remote will error here)
 			continue
@@ -51,6 +69,7 @@ func GetConnectionID(projectID string) (string, *ProjectError) {
 
 		for _, project := range projects {
 			if project.ProjectID == projectID {
+				discoveredConnections.Store(projectID, currentConID)
 				return currentConID, nil
 			}
 		}
@@ -64,6 +83,7 @@ func GetConnectionID(projectID string) (string, *ProjectError) {
 func RemoveConnectionFile(projectID string) *ProjectError {
 	// delete file
 	var err = os.Remove(getConnectionFilename(projectID))
+	connections.InvalidateProjectConnection(projectID)
 	if err != nil {
 		return &ProjectError{errOpFileDelete, err, err.Error()}
 	}
@@ -72,6 +92,11 @@ func RemoveConnectionFile(projectID string) *ProjectError {
 
 // getProjectConnectionConfigDir : Get directory path to the connection file
 func getProjectConnectionConfigDir() string {
+	return path.Join(getCodewindDir(), "config", "connections")
+}
+
+// getCodewindDir : Get the Codewind directory in the home directory, or in the projects root for Codewind on Che
+func getCodewindDir() string {
 	val, isSet := os.LookupEnv("CHE_API_EXTERNAL")
 	homeDir := ""
 	if isSet && (val != "") {
@@ -90,7 +115,7 @@ func getProjectConnectionConfigDir() string {
 			homeDir = os.Getenv("HOME")
 		}
 	}
-	return path.Join(homeDir, ".codewind", "config", "connections")
+	return path.Join(homeDir, ".codewind")
 }
 
 // getConnectionFilename : Get full file path of connection file
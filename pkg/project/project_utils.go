@@ -45,8 +45,20 @@ const (
 	errOpInvalidOptions     = "proj_options_invalid"
 	errOpSync               = "proj_sync"
 	errOpSyncRef            = "proj_sync_ref"
+	errOpSyncMaintenance    = "proj_sync_maintenance"
+	errOpSyncCase           = "proj_sync_case"
+	errOpSyncCancelled      = "proj_sync_cancelled"
 	errOpWriteCwSettings    = "proj_write_cw_settings"
 	errOpInvalidCredentials = "invalid_git_credentials"
+	errOpDebugTimeout       = "proj_debug_timeout"
+	errOpLoadTestTimeout    = "proj_loadtest_timeout"
+	errOpNotRunning         = "proj_not_running"
+	errOpRename             = "proj_rename"
+	errOpBuildFailed        = "proj_build_failed"
+	errOpBuildTimeout       = "proj_build_timeout"
+	errOpEvents             = "proj_events"
+	errOpLoadTestSchedule   = "proj_loadtest_schedule"
+	errOpSyncMirror         = "proj_sync_mirror"
 )
 
 const (
@@ -59,6 +71,7 @@ const (
 	textAPINotFound                = "unable to find requested resource on Codewind server"
 	textNoProjects                 = "unable to find any codewind projects"
 	textUpgradeError               = "error occurred upgrading projects"
+	textUpgradeNoDetails           = "Unable to upgrade project, failed to determine project details"
 	textNoProjectPath              = "project path not given"
 	textProjectPathDoesNotExist    = "given project path does not exist"
 	textProjectPathNonEmpty        = "Non empty directory provided"
@@ -66,6 +79,81 @@ const (
 	textProjectLinkUnknownNotFound = "unknown 404 returned from Codewind server"
 	textProjectLinkConflict        = "project link env is already in use"
 	textInvalidRequest             = "request parameters are invalid"
+	textMaintenanceMode            = "Codewind is unavailable, such as in maintenance mode, sync stopped - try again later"
+	textDebugTimeout               = "timed out waiting for the project to start in debug mode"
+	textLoadTestConflict           = "a load run is already in progress for this project"
+	textLoadTestTimeout            = "timed out waiting for the load run to finish"
+	textBuildConflict              = "a build is already in progress for this project"
+	textBuildFailed                = "build finished with status %s"
+	textBuildTimeout               = "timed out waiting for the build to finish"
+	textAppNotRunning              = "project is not running, start it before forwarding its port"
+	textAppPortUnknown             = "project has not reported its application port"
+	textInvalidProjectName         = "project name must only contain letters, numbers, '.', '_' and '-'"
+	textCaseCollision              = "%s differs only by case from %s, so it was not synced"
+	textSyncCancelled              = "sync cancelled, the upload was not completed"
+	textInvalidLinkEnv             = "link environment variable must start with a letter or '_', and only contain letters, numbers and '_'"
+	textLinkToSelf                 = "a project cannot be linked to itself"
+	textLinkConnection             = "target project is bound to connection %s, but links can only be made between projects on connection %s"
+	textInvalidSetting             = "settings must be of the form key=value, key+=value or key-=value"
+	textEventsClosed               = "Codewind closed the event stream"
+	textEventsRefused              = "Codewind refused the event stream: %s"
+	textNoProfilingRun             = "no load run found for the project, run a load test to profile it"
+	textNoProfilingData            = "no profiling data was collected during the load run"
+	textInvalidChangeDetection     = "change detection must be auto, mtime or poll"
+	textInvalidWatchInterval       = "watch interval must be greater than 0"
+	textInvalidCronSchedule        = "schedule must be five cron fields (minute hour day-of-month month day-of-week) or a macro such as @daily"
+	textInvalidScheduleName        = "schedule name must consist of lower case alphanumeric characters or '-', and start and end with an alphanumeric character"
+	textScheduleExists             = "a load test schedule named %s already exists"
+	textScheduleNotFound           = "no load test schedule named %s"
+	textMirrorConnection           = "project %s is bound to connection %s, mirrors must be bound to other connections"
+	textMirrorExists               = "project %s is already mirrored on connection %s, by project %s"
+	textMirrorNotFound             = "project %s has no mirror on connection %s"
+	textMirrorSyncFailed           = "sync failed on connections %s"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgDupName                    = "project.dup_name"
+	msgInvalidType                = "project.invalid_type"
+	msgAPINotFound                = "project.api_not_found"
+	msgUpgradeError               = "project.upgrade_error"
+	msgNoProjectPath              = "project.no_project_path"
+	msgProjectPathDoesNotExist    = "project.project_path_does_not_exist"
+	msgProjectPathNonEmpty        = "project.project_path_non_empty"
+	msgUnknownResponseCode        = "project.unknown_response_code"
+	msgProjectLinkUnknownNotFound = "project.project_link_unknown_not_found"
+	msgProjectLinkConflict        = "project.project_link_conflict"
+	msgInvalidRequest             = "project.invalid_request"
+	msgMaintenanceMode            = "project.maintenance_mode"
+	msgDebugTimeout               = "project.debug_timeout"
+	msgLoadTestConflict           = "project.load_test_conflict"
+	msgLoadTestTimeout            = "project.load_test_timeout"
+	msgBuildConflict              = "project.build_conflict"
+	msgBuildFailed                = "project.build_failed"
+	msgBuildTimeout               = "project.build_timeout"
+	msgAppNotRunning              = "project.app_not_running"
+	msgAppPortUnknown             = "project.app_port_unknown"
+	msgInvalidProjectName         = "project.invalid_project_name"
+	msgInvalidSetting             = "project.invalid_setting"
+	msgEventsClosed               = "project.events_closed"
+	msgEventsRefused              = "project.events_refused"
+	msgNoProfilingRun             = "project.no_profiling_run"
+	msgNoProfilingData            = "project.no_profiling_data"
+	msgCaseCollision              = "project.case_collision"
+	msgSyncCancelled              = "project.sync_cancelled"
+	msgInvalidLinkEnv             = "project.invalid_link_env"
+	msgLinkToSelf                 = "project.link_to_self"
+	msgLinkConnection             = "project.link_connection"
+	msgInvalidChangeDetection     = "project.invalid_change_detection"
+	msgInvalidWatchInterval       = "project.invalid_watch_interval"
+	msgInvalidCronSchedule        = "project.invalid_cron_schedule"
+	msgInvalidScheduleName        = "project.invalid_schedule_name"
+	msgScheduleExists             = "project.schedule_exists"
+	msgScheduleNotFound           = "project.schedule_not_found"
+	msgMirrorConnection           = "project.mirror_connection"
+	msgMirrorExists               = "project.mirror_exists"
+	msgMirrorNotFound             = "project.mirror_not_found"
+	msgMirrorSyncFailed           = "project.mirror_sync_failed"
 )
 
 // ProjectError : Error formatted in JSON containing an errorOp and a description from
@@ -12,62 +12,101 @@
 package project
 
 import (
-	"net/http"
 	"os"
 	"strings"
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/urfave/cli"
 )
 
-// RemoveProject : Unbind a project from Codewind and delete json connection file
-func RemoveProject(c *cli.Context) *ProjectError {
-	projectID := strings.TrimSpace(c.String("id"))
-	deleteFiles := c.Bool("delete")
+// RemoveResult : What was removed along with a project
+type RemoveResult struct {
+	ProjectID          string `json:"projectID"`
+	DeploymentRemoved  bool   `json:"deploymentRemoved"`
+	RemoteFilesRemoved bool   `json:"remoteFilesRemoved"`
+	LocalFilesRemoved  bool   `json:"localFilesRemoved"`
+	LocalPath          string `json:"localPath,omitempty"`
+}
+
+// RemoveOptions : The project to remove, and what to remove along with it
+type RemoveOptions struct {
+	ProjectID string
+	// DeleteFiles also deletes the local project files
+	DeleteFiles bool
+	UnbindOptions
+}
+
+// RemoveProject : Removes the project given by the --id flag, see Remove
+func RemoveProject(c *cli.Context) (*RemoveResult, *ProjectError) {
+	return Remove(RemoveOptions{
+		ProjectID:   strings.TrimSpace(c.String("id")),
+		DeleteFiles: c.Bool("delete"),
+		UnbindOptions: UnbindOptions{
+			KeepDeployment: c.Bool("keep-deployment"),
+			KeepFiles:      c.Bool("keep-files"),
+		},
+	})
+}
+
+// Remove : Unbind a project from Codewind and delete json connection file. Unless kept, PFE deletes the
+// deployment of the project and the files synced to it. The local project files are deleted only if asked.
+func Remove(removeOptions RemoveOptions) (*RemoveResult, *ProjectError) {
+	projectID := removeOptions.ProjectID
+	deleteFiles := removeOptions.DeleteFiles
+	options := removeOptions.UnbindOptions
 	projectPath := ""
 
 	// Get the connection for this project
 	conID, conErr := GetConnectionID(projectID)
 	if conErr != nil {
-		return conErr
+		return nil, conErr
 	}
 
 	conInfo, conInfoErr := connections.GetConnectionByID(conID)
 	if conInfoErr != nil {
-		return &ProjectError{conInfoErr.Op, conInfoErr.Err, conInfoErr.Desc}
+		return nil, &ProjectError{conInfoErr.Op, conInfoErr.Err, conInfoErr.Desc}
 	}
 
 	conURL, configErr := config.PFEOriginFromConnection(conInfo)
 	if configErr != nil {
-		return &ProjectError{configErr.Op, configErr.Err, configErr.Desc}
+		return nil, &ProjectError{configErr.Op, configErr.Err, configErr.Desc}
 	}
 
 	// If we are deleting the source, retrieve project to find out the path
 	if deleteFiles {
-		project, projErr := GetProjectFromID(http.DefaultClient, conInfo, conURL, projectID)
+		project, projErr := GetProjectFromID(sechttp.Client(), conInfo, conURL, projectID)
 		if projErr != nil {
-			return projErr
+			return nil, projErr
 		}
 		projectPath = project.LocationOnDisk
 	}
 
 	// Unbind the project from codewind
-	projError := Unbind(http.DefaultClient, conInfo, conURL, projectID)
+	projError := Unbind(sechttp.Client(), conInfo, conURL, projectID, options)
 	if projError != nil {
-		return projError
+		return nil, projError
+	}
+	result := RemoveResult{
+		ProjectID:          projectID,
+		DeploymentRemoved:  !options.KeepDeployment,
+		RemoteFilesRemoved: !options.KeepFiles,
 	}
 
 	// Delete the associated connection file
 	// We can ignore errors as we are no longer creating this file
 	RemoveConnectionFile(projectID)
+	forgetProjectMirrors(projectID)
 
 	// Delete the source if the flag is set
 	if deleteFiles {
 		var err = os.RemoveAll(projectPath)
 		if err != nil {
-			return &ProjectError{errOpFileDelete, err, err.Error()}
+			return nil, &ProjectError{errOpFileDelete, err, err.Error()}
 		}
+		result.LocalFilesRemoved = true
+		result.LocalPath = projectPath
 	}
-	return nil
+	return &result, nil
 }
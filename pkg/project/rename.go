@@ -0,0 +1,170 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"regexp"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/remote"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+type (
+	// RenameParameters : The request to rename a project
+	RenameParameters struct {
+		Name string `json:"name"`
+	}
+
+	// RenameResult : The names of a renamed project
+	RenameResult struct {
+		Status        string `json:"status"`
+		StatusMessage string `json:"status_message"`
+		ProjectID     string `json:"projectID"`
+		Name          string `json:"name"`
+		PreviousName  string `json:"previousName"`
+	}
+)
+
+// validProjectName matches the names Codewind gives projects, as project create does from their directory
+var validProjectName = regexp.MustCompile(`^[a-zA-Z0-9._-]+$`)
+
+// RenameProject : Renames a project in PFE, relabels the resources deployed for it on remote connections, and records
+// the new name in the connection file of the project. The connection file is only replaced once every other step has
+// succeeded, and the steps already done are undone when a later one fails, so a failed rename leaves the project as it was.
+func RenameProject(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, newName string) (*RenameResult, *ProjectError) {
+	if !validProjectName.MatchString(newName) {
+		err := i18n.Errorf(msgInvalidProjectName, textInvalidProjectName)
+		return nil, &ProjectError{errOpInvalidOptions, err, textInvalidProjectName}
+	}
+	project, projErr := GetProjectFromID(httpClient, conInfo, conURL, projectID)
+	if projErr != nil {
+		return nil, projErr
+	}
+	result := &RenameResult{Status: "OK", ProjectID: projectID, Name: newName, PreviousName: project.Name}
+	if project.Name == newName {
+		result.StatusMessage = "Project is already named " + newName
+		return result, nil
+	}
+
+	stagedFile, projErr := stageConnectionFileName(projectID, newName)
+	if projErr != nil {
+		return nil, projErr
+	}
+	discardStaged := func() {
+		if stagedFile != "" {
+			os.Remove(stagedFile)
+		}
+	}
+
+	projErr = setProjectName(httpClient, conInfo, conURL, projectID, newName)
+	if projErr != nil {
+		discardStaged()
+		return nil, projErr
+	}
+
+	relabel := conInfo.ID != "local" && project.Namespace != ""
+	// restoreName gives PFE, and the resources when they were relabelled, the previous name of the project
+	restoreName := func(relabelled bool) {
+		if rollbackErr := setProjectName(httpClient, conInfo, conURL, projectID, project.Name); rollbackErr != nil {
+			logr.Errorf("Unable to restore the name of project %v to %v: %v", projectID, project.Name, rollbackErr.Desc)
+		}
+		if relabelled {
+			if rollbackErr := remote.RelabelProject(nil, project.Namespace, projectID, project.Name); rollbackErr != nil {
+				logr.Errorf("Unable to restore the %v label of project %v: %v", remote.ProjectNameLabel, projectID, rollbackErr.Desc)
+			}
+		}
+	}
+
+	if relabel {
+		remInstErr := remote.RelabelProject(nil, project.Namespace, projectID, newName)
+		if remInstErr != nil {
+			discardStaged()
+			restoreName(false)
+			return nil, &ProjectError{errOpRename, remInstErr, remInstErr.Desc}
+		}
+	}
+
+	if stagedFile != "" {
+		err := os.Rename(stagedFile, getConnectionFilename(projectID))
+		connections.InvalidateProjectConnection(projectID)
+		if err != nil {
+			discardStaged()
+			restoreName(relabel)
+			return nil, &ProjectError{errOpFileWrite, err, err.Error()}
+		}
+	}
+	result.StatusMessage = "Project " + project.Name + " renamed to " + newName
+	return result, nil
+}
+
+// setProjectName : Asks PFE to rename a project
+func setProjectName(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, name string) *ProjectError {
+	payload, _ := json.Marshal(RenameParameters{Name: name})
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/rename", bytes.NewBuffer(payload))
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound:
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	case http.StatusConflict:
+		respErr := i18n.Errorf(msgDupName, textDupName)
+		return &ProjectError{errOpConflict, respErr, textDupName}
+	}
+	body, _ := ioutil.ReadAll(resp.Body)
+	respErr := fmt.Errorf("Rename request failed with status code %d: %s", resp.StatusCode, string(body))
+	return &ProjectError{errOpResponse, respErr, respErr.Error()}
+}
+
+// stageConnectionFileName : Writes the connection file of a project with its new name next to the current one,
+// returning the staged file to move over it, or "" when the project has no connection file on this machine.
+// Fields of the connection file other than the name are kept.
+func stageConnectionFileName(projectID string, name string) (string, *ProjectError) {
+	connectionFilename := getConnectionFilename(projectID)
+	contents, err := ioutil.ReadFile(connectionFilename)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	conFile := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &conFile); err != nil {
+		return "", &ProjectError{errOpFileParse, err, err.Error()}
+	}
+	conFile["name"] = name
+	updated, _ := json.MarshalIndent(conFile, "", "  ")
+	stagedFile := connectionFilename + ".rename"
+	if err := ioutil.WriteFile(stagedFile, updated, 0644); err != nil {
+		return "", &ProjectError{errOpFileWrite, err, err.Error()}
+	}
+	return stagedFile, nil
+}
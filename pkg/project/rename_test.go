@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_RenameProject(t *testing.T) {
+	home, err := ioutil.TempDir("", "rename-home")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(home)
+	originalHome := os.Getenv("HOME")
+	os.Setenv("HOME", home)
+	defer os.Setenv("HOME", originalHome)
+	os.MkdirAll(getProjectConnectionConfigDir(), 0755)
+
+	getPath := "GET /api/v1/projects/mockID/"
+	renamePath := "POST /api/v1/projects/mockID/rename"
+	project := mockResponse{http.StatusOK, `{"projectID":"mockID","name":"oldname"}`}
+
+	t.Run("success case - renames the project and its connection file", func(t *testing.T) {
+		ioutil.WriteFile(getConnectionFilename("mockID"), []byte(`{"id":"local"}`), 0644)
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{getPath: {project}, renamePath: {{http.StatusOK, ""}}}}
+		result, projErr := RenameProject(mockClient, &mockConnection, "", "mockID", "newname")
+		assert.Nil(t, projErr)
+		assert.Equal(t, "oldname", result.PreviousName)
+		assert.Equal(t, "newname", result.Name)
+		contents, _ := ioutil.ReadFile(getConnectionFilename("mockID"))
+		assert.JSONEq(t, `{"id":"local","name":"newname"}`, string(contents))
+		assert.Equal(t, map[string]string{"mockID": "local"}, readProjectRegistry())
+	})
+
+	t.Run("success case - project without a connection file", func(t *testing.T) {
+		os.Remove(getConnectionFilename("mockID"))
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{getPath: {project}, renamePath: {{http.StatusOK, ""}}}}
+		_, projErr := RenameProject(mockClient, &mockConnection, "", "mockID", "newname")
+		assert.Nil(t, projErr)
+		_, err := os.Stat(getConnectionFilename("mockID"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("success case - project already has the name", func(t *testing.T) {
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{getPath: {project}}}
+		_, projErr := RenameProject(mockClient, &mockConnection, "", "mockID", "oldname")
+		assert.Nil(t, projErr)
+		assert.Equal(t, 0, mockClient.requests[renamePath])
+	})
+
+	t.Run("error case - name in use leaves the connection file unchanged", func(t *testing.T) {
+		ioutil.WriteFile(getConnectionFilename("mockID"), []byte(`{"id":"local"}`), 0644)
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{getPath: {project}, renamePath: {{http.StatusConflict, ""}}}}
+		_, projErr := RenameProject(mockClient, &mockConnection, "", "mockID", "taken")
+		assert.Equal(t, errOpConflict, projErr.Op)
+		contents, _ := ioutil.ReadFile(getConnectionFilename("mockID"))
+		assert.Equal(t, `{"id":"local"}`, string(contents))
+		files, _ := ioutil.ReadDir(getProjectConnectionConfigDir())
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("error case - invalid name", func(t *testing.T) {
+		mockClient := &mockLoadRunner{}
+		_, projErr := RenameProject(mockClient, &mockConnection, "", "mockID", "new name")
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+		assert.Equal(t, 0, mockClient.requests[getPath])
+	})
+}
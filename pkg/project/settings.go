@@ -0,0 +1,291 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/sechttp"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// SettingsUpdate : A change to one setting of a project. Lists are set as a whole, or have a value added to or
+	// removed from them.
+	SettingsUpdate struct {
+		Key       string
+		Operation string
+		Value     string
+	}
+
+	// SettingsResult : The settings of a project after an update
+	SettingsResult struct {
+		Status        string                 `json:"status"`
+		StatusMessage string                 `json:"status_message"`
+		ProjectID     string                 `json:"projectID"`
+		SettingsFile  string                 `json:"settingsFile"`
+		Changed       map[string]interface{} `json:"changed"`
+	}
+)
+
+// Operations of a settings update
+const (
+	SettingsSet    = "set"
+	SettingsAdd    = "add"
+	SettingsRemove = "remove"
+)
+
+// settingKind is how the value of a setting is checked and stored in .cw-settings
+type settingKind int
+
+const (
+	settingPath settingKind = iota
+	settingPort
+	settingBool
+	settingSeconds
+	settingList
+	settingPreset
+)
+
+// settingKinds are the settings of .cw-settings that can be updated
+var settingKinds = map[string]settingKind{
+	"contextRoot":       settingPath,
+	"healthCheck":       settingPath,
+	"internalPort":      settingPort,
+	"internalDebugPort": settingPort,
+	"isHttps":           settingBool,
+	"statusPingTimeout": settingSeconds,
+	"ignoredPaths":      settingList,
+	"ignorePreset":      settingPreset,
+	"mavenProfiles":     settingList,
+	"mavenProperties":   settingList,
+}
+
+// ParseSettingsUpdate : Parses a settings update of the form key=value, or key+=value and key-=value to add a value
+// to, or remove one from, a list setting. A list is set from comma separated values.
+func ParseSettingsUpdate(update string) (SettingsUpdate, *ProjectError) {
+	separator := strings.Index(update, "=")
+	if separator < 1 {
+		err := i18n.Errorf(msgInvalidSetting, textInvalidSetting+": %s", update)
+		return SettingsUpdate{}, &ProjectError{errOpInvalidOptions, err, err.Error()}
+	}
+	parsed := SettingsUpdate{Key: update[:separator], Operation: SettingsSet, Value: update[separator+1:]}
+	switch {
+	case strings.HasSuffix(parsed.Key, "+"):
+		parsed.Key, parsed.Operation = strings.TrimSuffix(parsed.Key, "+"), SettingsAdd
+	case strings.HasSuffix(parsed.Key, "-"):
+		parsed.Key, parsed.Operation = strings.TrimSuffix(parsed.Key, "-"), SettingsRemove
+	}
+	parsed.Key = strings.TrimSpace(parsed.Key)
+	parsed.Value = strings.TrimSpace(parsed.Value)
+	return parsed, nil
+}
+
+// applySettingsUpdate : Checks an update against the kind of its setting, and applies it to the settings, returning
+// the new value of the setting
+func applySettingsUpdate(settings map[string]interface{}, update SettingsUpdate) (interface{}, error) {
+	kind, ok := settingKinds[update.Key]
+	if !ok {
+		return nil, fmt.Errorf("unknown setting %v, expected one of %v", update.Key, strings.Join(settingNames(), ", "))
+	}
+	if kind != settingList && update.Operation != SettingsSet {
+		return nil, fmt.Errorf("values can only be added to or removed from list settings, not %v", update.Key)
+	}
+
+	var value interface{}
+	switch kind {
+	case settingPath:
+		if update.Value != "" && !strings.HasPrefix(update.Value, "/") {
+			return nil, fmt.Errorf("%v must be a path starting with /", update.Key)
+		}
+		value = update.Value
+	case settingPort:
+		port, err := strconv.Atoi(update.Value)
+		if update.Value != "" && (err != nil || port < 1 || port > 65535) {
+			return nil, fmt.Errorf("%v must be a port number between 1 and 65535", update.Key)
+		}
+		value = update.Value
+	case settingBool:
+		enabled, err := strconv.ParseBool(update.Value)
+		if err != nil {
+			return nil, fmt.Errorf("%v must be true or false", update.Key)
+		}
+		value = enabled
+	case settingSeconds:
+		seconds, err := strconv.Atoi(update.Value)
+		if update.Value != "" && (err != nil || seconds < 1) {
+			return nil, fmt.Errorf("%v must be a whole number of seconds", update.Key)
+		}
+		value = update.Value
+	case settingList:
+		value = updateList(listValues(settings[update.Key]), update)
+	case settingPreset:
+		presets := updateList(nil, update)
+		for _, preset := range presets {
+			if _, found := ignorePresets[preset]; !found {
+				return nil, fmt.Errorf("%v must be one or more of %v", update.Key, strings.Join(ignorePresetNames(), ", "))
+			}
+		}
+		value = strings.Join(presets, ",")
+	}
+	settings[update.Key] = value
+	return value, nil
+}
+
+// listValues : Returns the values of a list setting, ignoring the empty placeholders written for new projects
+func listValues(setting interface{}) []string {
+	values := []string{}
+	items, _ := setting.([]interface{})
+	for _, item := range items {
+		if value, ok := item.(string); ok && value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func updateList(values []string, update SettingsUpdate) []string {
+	switch update.Operation {
+	case SettingsAdd:
+		if !stringInSlice(update.Value, values) {
+			values = append(values, update.Value)
+		}
+		return values
+	case SettingsRemove:
+		kept := []string{}
+		for _, value := range values {
+			if value != update.Value {
+				kept = append(kept, value)
+			}
+		}
+		return kept
+	}
+	values = []string{}
+	for _, value := range strings.Split(update.Value, ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+func settingNames() []string {
+	names := []string{}
+	for name := range settingKinds {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// UpdateProjectSettings : Applies updates to the .cw-settings of a project and pushes the changed settings to PFE.
+// Every update is checked before anything is written, and the file is replaced only once PFE accepts the change,
+// so an update that fails leaves .cw-settings as it was. Settings not being updated are kept as they are.
+func UpdateProjectSettings(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, projectPath string, updates []SettingsUpdate) (*SettingsResult, *ProjectError) {
+	settingsFile := filepath.Join(projectPath, ".cw-settings")
+	contents, err := ioutil.ReadFile(settingsFile)
+	if err != nil {
+		return nil, &ProjectError{errOpFileLoad, err, err.Error()}
+	}
+	settings := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &settings); err != nil {
+		return nil, &ProjectError{errOpFileParse, err, err.Error()}
+	}
+
+	changed := map[string]interface{}{}
+	for _, update := range updates {
+		value, err := applySettingsUpdate(settings, update)
+		if err != nil {
+			return nil, &ProjectError{errOpInvalidOptions, err, err.Error()}
+		}
+		changed[update.Key] = value
+	}
+
+	updated, _ := json.MarshalIndent(settings, "", "  ")
+	stagedFile := settingsFile + ".update"
+	if err := ioutil.WriteFile(stagedFile, updated, 0644); err != nil {
+		return nil, &ProjectError{errOpWriteCwSettings, err, err.Error()}
+	}
+	if projErr := pushProjectSettings(httpClient, conInfo, conURL, projectID, pfeSettings(settings, changed)); projErr != nil {
+		os.Remove(stagedFile)
+		return nil, projErr
+	}
+	if err := os.Rename(stagedFile, settingsFile); err != nil {
+		os.Remove(stagedFile)
+		return nil, &ProjectError{errOpWriteCwSettings, err, err.Error()}
+	}
+	return &SettingsResult{Status: "OK", StatusMessage: "Project settings updated", ProjectID: projectID, SettingsFile: settingsFile, Changed: changed}, nil
+}
+
+// pfeSettings : Returns the changed settings that are sent to PFE. PFE does not know ignore presets, so when the
+// presets or the ignored paths of a project change it is sent the paths of the presets with the ignored paths.
+func pfeSettings(settings map[string]interface{}, changed map[string]interface{}) map[string]interface{} {
+	_, presetChanged := changed["ignorePreset"]
+	_, pathsChanged := changed["ignoredPaths"]
+	if !presetChanged && !pathsChanged {
+		return changed
+	}
+	pushed := map[string]interface{}{}
+	for key, value := range changed {
+		if key != "ignorePreset" {
+			pushed[key] = value
+		}
+	}
+	preset, _ := settings["ignorePreset"].(string)
+	ignoredPaths := append([]string{}, expandIgnorePreset(preset)...)
+	// Ignored paths updated with the presets are already a list of values
+	if updated, ok := settings["ignoredPaths"].([]string); ok {
+		pushed["ignoredPaths"] = append(ignoredPaths, updated...)
+	} else {
+		pushed["ignoredPaths"] = append(ignoredPaths, listValues(settings["ignoredPaths"])...)
+	}
+	return pushed
+}
+
+// pushProjectSettings : Sends changed settings to PFE, which applies them to the running project
+func pushProjectSettings(httpClient utils.HTTPClient, conInfo *connections.Connection, conURL string, projectID string, changed map[string]interface{}) *ProjectError {
+	payload, _ := json.Marshal(changed)
+	req, err := http.NewRequest("POST", conURL+"/api/v1/projects/"+projectID+"/properties", bytes.NewBuffer(payload))
+	if err != nil {
+		return &ProjectError{errOpRequest, err, err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, httpSecError := sechttp.DispatchHTTPRequest(httpClient, req, conInfo)
+	if httpSecError != nil {
+		return &ProjectError{errOpRequest, httpSecError, httpSecError.Desc}
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusAccepted:
+		return nil
+	case http.StatusNotFound:
+		respErr := i18n.Errorf(msgAPINotFound, textAPINotFound)
+		return &ProjectError{errOpNotFound, respErr, textAPINotFound}
+	case http.StatusBadRequest:
+		respErr := fmt.Errorf("%v: %s", textInvalidRequest, string(body))
+		return &ProjectError{errOpInvalidOptions, respErr, respErr.Error()}
+	}
+	respErr := fmt.Errorf("Settings request failed with status code %d: %s", resp.StatusCode, string(body))
+	return &ProjectError{errOpResponse, respErr, respErr.Error()}
+}
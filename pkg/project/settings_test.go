@@ -0,0 +1,153 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ParseSettingsUpdate(t *testing.T) {
+	tests := map[string]struct {
+		update string
+		want   SettingsUpdate
+	}{
+		"set":                    {"internalPort=8080", SettingsUpdate{"internalPort", SettingsSet, "8080"}},
+		"add":                    {"ignoredPaths+=*.log", SettingsUpdate{"ignoredPaths", SettingsAdd, "*.log"}},
+		"remove":                 {"ignoredPaths-=*.log", SettingsUpdate{"ignoredPaths", SettingsRemove, "*.log"}},
+		"value containing equal": {"mavenProperties+=skipTests=true", SettingsUpdate{"mavenProperties", SettingsAdd, "skipTests=true"}},
+		"empty value":            {"contextRoot=", SettingsUpdate{"contextRoot", SettingsSet, ""}},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, projErr := ParseSettingsUpdate(test.update)
+			assert.Nil(t, projErr)
+			assert.Equal(t, test.want, got)
+		})
+	}
+
+	t.Run("error case - no value", func(t *testing.T) {
+		_, projErr := ParseSettingsUpdate("internalPort")
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+	})
+}
+
+func Test_ApplySettingsUpdate(t *testing.T) {
+	tests := map[string]struct {
+		update  SettingsUpdate
+		want    interface{}
+		wantErr bool
+	}{
+		"port":                      {update: SettingsUpdate{"internalPort", SettingsSet, "8080"}, want: "8080"},
+		"port out of range":         {update: SettingsUpdate{"internalPort", SettingsSet, "70000"}, wantErr: true},
+		"clearing a port":           {update: SettingsUpdate{"internalDebugPort", SettingsSet, ""}, want: ""},
+		"boolean":                   {update: SettingsUpdate{"isHttps", SettingsSet, "true"}, want: true},
+		"not a boolean":             {update: SettingsUpdate{"isHttps", SettingsSet, "yes"}, wantErr: true},
+		"path":                      {update: SettingsUpdate{"healthCheck", SettingsSet, "/health"}, want: "/health"},
+		"relative path":             {update: SettingsUpdate{"contextRoot", SettingsSet, "app"}, wantErr: true},
+		"seconds":                   {update: SettingsUpdate{"statusPingTimeout", SettingsSet, "30"}, want: "30"},
+		"list set":                  {update: SettingsUpdate{"mavenProfiles", SettingsSet, "dev, test"}, want: []string{"dev", "test"}},
+		"list add":                  {update: SettingsUpdate{"ignoredPaths", SettingsAdd, "*.tmp"}, want: []string{"*.log", "*.tmp"}},
+		"list add existing":         {update: SettingsUpdate{"ignoredPaths", SettingsAdd, "*.log"}, want: []string{"*.log"}},
+		"list remove":               {update: SettingsUpdate{"ignoredPaths", SettingsRemove, "*.log"}, want: []string{}},
+		"adding to a non list":      {update: SettingsUpdate{"internalPort", SettingsAdd, "8080"}, wantErr: true},
+		"unknown setting":           {update: SettingsUpdate{"port", SettingsSet, "8080"}, wantErr: true},
+		"placeholder list ignored":  {update: SettingsUpdate{"mavenProperties", SettingsAdd, "a=b"}, want: []string{"a=b"}},
+		"ignore preset":             {update: SettingsUpdate{"ignorePreset", SettingsSet, "node, java-maven"}, want: "node,java-maven"},
+		"clearing an ignore preset": {update: SettingsUpdate{"ignorePreset", SettingsSet, ""}, want: ""},
+		"unknown ignore preset":     {update: SettingsUpdate{"ignorePreset", SettingsSet, "rust"}, wantErr: true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			settings := map[string]interface{}{"ignoredPaths": []interface{}{"*.log"}, "mavenProperties": []interface{}{""}}
+			got, err := applySettingsUpdate(settings, test.update)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, test.want, got)
+			assert.Equal(t, test.want, settings[test.update.Key])
+		})
+	}
+}
+
+func Test_UpdateProjectSettings(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "settings-project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	settingsFile := filepath.Join(projectPath, ".cw-settings")
+	original := `{"contextRoot":"","internalPort":"3000","ignoredPaths":["*.log"],"custom":"kept"}`
+	propertiesPath := "POST /api/v1/projects/mockID/properties"
+
+	t.Run("success case - updates .cw-settings and PFE", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(original), 0644)
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{propertiesPath: {{http.StatusOK, ""}}}}
+		updates := []SettingsUpdate{{"internalPort", SettingsSet, "8080"}, {"ignoredPaths", SettingsAdd, "*.tmp"}}
+		result, projErr := UpdateProjectSettings(mockClient, &mockConnection, "", "mockID", projectPath, updates)
+		assert.Nil(t, projErr)
+		assert.Equal(t, map[string]interface{}{"internalPort": "8080", "ignoredPaths": []string{"*.log", "*.tmp"}}, result.Changed)
+		contents, _ := ioutil.ReadFile(settingsFile)
+		assert.JSONEq(t, `{"contextRoot":"","internalPort":"8080","ignoredPaths":["*.log","*.tmp"],"custom":"kept"}`, string(contents))
+		assert.Equal(t, 1, mockClient.requests[propertiesPath])
+	})
+
+	t.Run("success case - PFE is sent the ignored paths of an ignore preset", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(original), 0644)
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{propertiesPath: {{http.StatusOK, ""}}}}
+		result, projErr := UpdateProjectSettings(mockClient, &mockConnection, "", "mockID", projectPath, []SettingsUpdate{{"ignorePreset", SettingsSet, "go"}})
+		assert.Nil(t, projErr)
+		assert.Equal(t, map[string]interface{}{"ignorePreset": "go"}, result.Changed)
+		contents, _ := ioutil.ReadFile(settingsFile)
+		assert.JSONEq(t, `{"contextRoot":"","internalPort":"3000","ignoredPaths":["*.log"],"ignorePreset":"go","custom":"kept"}`, string(contents))
+	})
+
+	t.Run("success case - PFE is sent ignored paths after the paths of the ignore preset", func(t *testing.T) {
+		settings := map[string]interface{}{"ignorePreset": "go", "ignoredPaths": []string{"*.log"}}
+		pushed := pfeSettings(settings, map[string]interface{}{"ignoredPaths": []string{"*.log"}, "internalPort": "8080"})
+		assert.Equal(t, map[string]interface{}{"ignoredPaths": []string{"bin", "**/*.test", "**/*.prof", "*.log"}, "internalPort": "8080"}, pushed)
+		pushed = pfeSettings(map[string]interface{}{"ignorePreset": ""}, map[string]interface{}{"ignorePreset": ""})
+		assert.Equal(t, map[string]interface{}{"ignoredPaths": []string{}}, pushed)
+	})
+
+	t.Run("error case - PFE rejects the change", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(original), 0644)
+		mockClient := &mockLoadRunner{responses: map[string][]mockResponse{propertiesPath: {{http.StatusBadRequest, "bad port"}}}}
+		_, projErr := UpdateProjectSettings(mockClient, &mockConnection, "", "mockID", projectPath, []SettingsUpdate{{"internalPort", SettingsSet, "8080"}})
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+		contents, _ := ioutil.ReadFile(settingsFile)
+		assert.Equal(t, original, string(contents))
+		files, _ := ioutil.ReadDir(projectPath)
+		assert.Len(t, files, 1)
+	})
+
+	t.Run("error case - invalid update is not sent", func(t *testing.T) {
+		ioutil.WriteFile(settingsFile, []byte(original), 0644)
+		mockClient := &mockLoadRunner{}
+		_, projErr := UpdateProjectSettings(mockClient, &mockConnection, "", "mockID", projectPath, []SettingsUpdate{{"isHttps", SettingsSet, "maybe"}})
+		assert.Equal(t, errOpInvalidOptions, projErr.Op)
+		assert.Equal(t, 0, mockClient.requests[propertiesPath])
+	})
+
+	t.Run("error case - project has no .cw-settings", func(t *testing.T) {
+		os.Remove(settingsFile)
+		_, projErr := UpdateProjectSettings(&mockLoadRunner{}, &mockConnection, "", "mockID", projectPath, []SettingsUpdate{{"internalPort", SettingsSet, "8080"}})
+		assert.Equal(t, errOpFileLoad, projErr.Op)
+	})
+}
@@ -14,10 +14,12 @@ package project
 import (
 	"bytes"
 	"compress/zlib"
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"os"
@@ -27,8 +29,11 @@ import (
 
 	"github.com/eclipse/codewind-installer/pkg/config"
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/maintenance"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
 
@@ -46,14 +51,22 @@ type (
 		IsDirectory  bool   `json:"isDirectory"`
 		Mode         uint   `json:"mode"`
 		RelativePath string `json:"path"`
-		Message      string `json:"msg"`
+		// SHA256 is the hash of the file contents, sent when PFE supports deduplicated uploads
+		SHA256 string `json:"sha256,omitempty"`
+		// Reference is set when the contents are not sent, as PFE already has them from a file with the same hash
+		Reference bool `json:"reference,omitempty"`
+		// Message must stay the last field, as uploads stream the encoded file into the end of the JSON
+		Message string `json:"msg"`
 	}
 
 	// UploadedFile is the file to sync
 	UploadedFile struct {
-		FilePath   string `json:"filePath"`
-		Status     string `json:"status"`
-		StatusCode int    `json:"statusCode"`
+		FilePath     string `json:"filePath"`
+		Status       string `json:"status"`
+		StatusCode   int    `json:"statusCode"`
+		Deduplicated bool   `json:"deduplicated,omitempty"`
+		// maintenance is set when PFE stayed unavailable through the retries, as maintenance mode scaled it down
+		maintenance bool
 	}
 
 	// SyncResponse is the status of the file syncing
@@ -89,19 +102,62 @@ type (
 	refPaths struct {
 		RefPaths []refPath
 	}
+
+	// SyncOptions : The project to sync and the files to upload
+	SyncOptions struct {
+		Path      string
+		ProjectID string
+		// ConnectionID is the connection the project is bound to, found from the project ID when it is not set
+		ConnectionID string
+		// LastSync is the UNIX time in milliseconds of the previous sync, only files modified since are uploaded
+		LastSync int64
+		// ChangeDetection is how the files changed since the previous sync are found, auto when it is not set
+		ChangeDetection string
+		// ModifiedSince uploads the files modified at or after a time when set, whatever LastSync and ChangeDetection
+		// are, to repair a project whose previous syncs missed files
+		ModifiedSince time.Time
+	}
 )
 
-// SyncProject syncs a project with its remote connection
+// errMaintenanceMode is returned when uploads fail as PFE is unavailable, such as while the workspace is in maintenance mode
+var errMaintenanceMode = i18n.Errorf(msgMaintenanceMode, textMaintenanceMode)
+
+// SyncProject syncs the project given by the --path, --id and --time flags, see Sync
 func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
-	var currentSyncTime = time.Now().UnixNano() / 1000000
-	projectPath := strings.TrimSpace(c.String("path"))
-	projectID := strings.TrimSpace(c.String("id"))
-	synctime := int64(c.Int("time"))
+	return Sync(SyncOptions{
+		Path:      strings.TrimSpace(c.String("path")),
+		ProjectID: strings.TrimSpace(c.String("id")),
+		LastSync:  int64(c.Int("time")),
+	})
+}
 
-	conID, projErr := GetConnectionID(projectID)
+// Sync syncs a project with its remote connection, keeping a report of the sync
+func Sync(options SyncOptions) (*SyncResponse, *ProjectError) {
+	return SyncContext(context.Background(), options)
+}
 
-	if projErr != nil {
-		return nil, projErr
+// SyncContext syncs a project with its remote connection, keeping a report of the sync. Cancelling the context stops
+// the upload without completing it, so Codewind does not build the project from a partial sync.
+func SyncContext(ctx context.Context, options SyncOptions) (*SyncResponse, *ProjectError) {
+	started := time.Now()
+	response, syncErr := syncProject(ctx, options)
+	recordSyncReport(SyncReportsDir(), options.ProjectID, newSyncReport(started, response, syncErr))
+	return response, syncErr
+}
+
+func syncProject(ctx context.Context, options SyncOptions) (*SyncResponse, *ProjectError) {
+	var currentSyncTime = time.Now().UnixNano() / 1000000
+	projectPath := options.Path
+	projectID := options.ProjectID
+	synctime := options.LastSync
+
+	conID := options.ConnectionID
+	if conID == "" {
+		var projErr *ProjectError
+		conID, projErr = GetConnectionID(projectID)
+		if projErr != nil {
+			return nil, projErr
+		}
 	}
 
 	connection, conInfoErr := connections.GetConnectionByID(conID)
@@ -119,17 +175,17 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 	pathExists := utils.PathExists(projectPath)
 
 	if !pathExists {
-		projectInfo, err := GetProjectFromID(&http.Client{}, connection, conURL, projectID)
+		projectInfo, err := GetProjectFromID(sechttp.Client(), connection, conURL, projectID)
 		if err != nil {
 			return nil, err
 		}
-		newErr := fmt.Errorf(textProjectPathDoesNotExist)
+		newErr := i18n.Errorf(msgProjectPathDoesNotExist, textProjectPathDoesNotExist)
 
 		if projectPath != projectInfo.LocationOnDisk {
 			return nil, &ProjectError{errBadPath, newErr, newErr.Error()}
 		}
 
-		err = handleMissingProjectDir(&http.Client{}, connection, conURL, projectID)
+		err = handleMissingProjectDir(sechttp.Client(), connection, conURL, projectID)
 		if err != nil {
 			return nil, &ProjectError{errBadPath, err, err.Error()}
 		}
@@ -137,19 +193,37 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 		return nil, &ProjectError{errBadPath, newErr, newErr.Error()}
 	}
 
+	strategy, projErr := ResolveChangeDetection(options.ChangeDetection, projectPath)
+	if projErr != nil {
+		return nil, projErr
+	}
+	if !options.ModifiedSince.IsZero() {
+		strategy, synctime = ChangeDetectionMTime, options.ModifiedSince.UnixNano()/1000000-1
+	}
+	detector := newChangeDetector(strategy, SyncSnapshotsDir(), projectID)
+
 	// Sync all the necessary project files
-	syncInfo, syncErr := syncFiles(&http.Client{}, projectPath, projectID, conURL, synctime, connection)
+	syncInfo, syncErr := syncFilesDetecting(ctx, sechttp.Client(), projectPath, projectID, conURL, synctime, connection, detector)
+
+	// Back off if the deployment is in maintenance mode, the upload can't be completed until it is back
+	if syncErr != nil && (syncErr.Op == errOpSyncMaintenance || syncErr.Op == errOpSyncCancelled) {
+		return nil, syncErr
+	}
 
 	// Add a check here for files that have been imported into the project, compare lists of files
-	BeforeFileList, err := GetProjectFileList(&http.Client{}, connection, conURL, projectID)
+	BeforeFileList, err := GetProjectFileList(sechttp.Client(), connection, conURL, projectID)
 	if err == nil {
-		added := findNewFiles(&http.Client{}, projectID, BeforeFileList, syncInfo.fileList, projectPath, connection, conURL)
+		added := findNewFiles(ctx, sechttp.Client(), projectID, BeforeFileList, syncInfo.fileList, projectPath, connection, conURL)
 		// Add any new files to the modifiedList
 		for _, file := range added {
 			syncInfo.modifiedList = append(syncInfo.modifiedList, file)
 		}
 	}
 
+	if ctx.Err() != nil {
+		return nil, &ProjectError{errOpSyncCancelled, i18n.Errorf(msgSyncCancelled, textSyncCancelled), textSyncCancelled}
+	}
+
 	// Complete the upload
 	completeRequest := CompleteRequest{
 		FileList:      syncInfo.fileList,
@@ -157,7 +231,10 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 		ModifiedList:  syncInfo.modifiedList,
 		TimeStamp:     currentSyncTime,
 	}
-	completeStatus, completeStatusCode := completeUpload(&http.Client{}, projectID, completeRequest, connection, conURL)
+	completeStatus, completeStatusCode := completeUpload(sechttp.Client(), projectID, completeRequest, connection, conURL)
+	if completeStatusCode == http.StatusOK {
+		detector.record(SyncSnapshotsDir(), projectID, syncInfo.UploadedFileList)
+	}
 	response := SyncResponse{
 		UploadedFiles: syncInfo.UploadedFileList,
 		Status:        completeStatus,
@@ -167,7 +244,12 @@ func SyncProject(c *cli.Context) (*SyncResponse, *ProjectError) {
 	return &response, syncErr
 }
 
-func syncFiles(client utils.HTTPClient, projectPath string, projectID string, conURL string, synctime int64, connection *connections.Connection) (*SyncInfo, *ProjectError) {
+func syncFiles(ctx context.Context, client utils.HTTPClient, projectPath string, projectID string, conURL string, synctime int64, connection *connections.Connection) (*SyncInfo, *ProjectError) {
+	return syncFilesDetecting(ctx, client, projectPath, projectID, conURL, synctime, connection, newChangeDetector(ChangeDetectionMTime, "", projectID))
+}
+
+// syncFilesDetecting uploads the files of a project that the detector finds changed since the last sync
+func syncFilesDetecting(ctx context.Context, client utils.HTTPClient, projectPath string, projectID string, conURL string, synctime int64, connection *connections.Connection, detector *changeDetector) (*SyncInfo, *ProjectError) {
 	var fileList []string
 	var directoryList []string
 	var modifiedList []string
@@ -175,12 +257,23 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 
 	refPathsChanged := false
 
+	// syncedPaths maps the lower case relative paths synced to the paths themselves, to find paths that differ only by
+	// case. Those would overwrite each other on case insensitive file systems, so only the first one found is synced.
+	syncedPaths := map[string]string{}
+	collisionText := ""
+
+	dedup := newUploadDedup(client, connection, conURL)
+
 	// define a walker function
 	walker := func(path string, info walkerInfo, err error) error {
 		if err != nil {
 			return err
 			// TODO - How to handle *some* files being unreadable
 		}
+		// Stop walking once the sync is cancelled
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
 		// If it is the top level directory ignore it
 		if path == projectPath {
@@ -190,19 +283,34 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 		// use ToSlash to try and get both Windows and *NIX paths to be *NIX for pfe
 		relativePath := filepath.ToSlash(path[(len(projectPath) + 1):])
 
+		// collides reports a path that differs only by case from one already synced
+		collides := func() bool {
+			syncedPath, found := syncedPaths[strings.ToLower(relativePath)]
+			if !found {
+				syncedPaths[strings.ToLower(relativePath)] = relativePath
+				return false
+			}
+			message := i18n.NewMessage(msgCaseCollision, textCaseCollision, relativePath, syncedPath)
+			logr.Warnln(message.Translate())
+			collisionText += message.Error() + "\n"
+			return true
+		}
+
 		if !info.IsDir() {
 			shouldIgnore := ignoreFileOrDirectory(relativePath, false, info.IgnoredPaths)
-			if shouldIgnore {
+			if shouldIgnore || collides() {
 				return nil
 			}
 			// Create list of all files for a project
 			fileList = append(fileList, relativePath)
 
-			// get time file was modified in milliseconds since epoch
-			modifiedmillis := info.ModTime().UnixNano() / 1000000
 			// Has this file been modified since last sync
-			if modifiedmillis > info.LastSync {
-				uploadResponse := syncFile(&http.Client{}, projectID, projectPath, info.Path, connection, conURL)
+			if detector.changed(relativePath, info.FileInfo, info.LastSync) {
+				uploadResponse := dedup.syncFile(ctx, projectID, projectPath, info.Path)
+				// Stop walking if Codewind is in maintenance mode, rather than trying every remaining file
+				if uploadResponse.maintenance {
+					return errMaintenanceMode
+				}
 				uploadedFiles = append(uploadedFiles, uploadResponse)
 				// Create list of all modfied files
 				modifiedList = append(modifiedList, relativePath)
@@ -214,7 +322,7 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 			}
 		} else {
 			shouldIgnore := ignoreFileOrDirectory(relativePath, true, info.IgnoredPaths)
-			if shouldIgnore {
+			if shouldIgnore || collides() {
 				return filepath.SkipDir
 			}
 			directoryList = append(directoryList, relativePath)
@@ -233,8 +341,11 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 		cwCombinedIgnoredPathsList = append(cwCombinedIgnoredPathsList, refPath.To)
 	}
 
-	// first sync files that are physically in the project
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	// first sync files that are physically in the project. Walk the long form of the project path, so that
+	// Windows can read directories nested deeper than MAX_PATH, and give the walker paths under the project path.
+	walkRoot := longPath(projectPath)
+	err := filepath.Walk(walkRoot, func(path string, info os.FileInfo, err error) error {
+		path = projectPath + path[len(walkRoot):]
 		// use combined ignored paths here, files in the project that
 		// are also the target of a reference should not be synced
 		wInfo := walkerInfo{
@@ -245,6 +356,12 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 		}
 		return walker(path, wInfo, err)
 	})
+	if err == errMaintenanceMode {
+		return nil, &ProjectError{errOpSyncMaintenance, err, err.Error()}
+	}
+	if ctx.Err() != nil {
+		return nil, &ProjectError{errOpSyncCancelled, i18n.Errorf(msgSyncCancelled, textSyncCancelled), textSyncCancelled}
+	}
 	if err != nil {
 		text := fmt.Sprintf("error walking the path %q: %v\n", projectPath, err)
 		return nil, &ProjectError{errOpSync, errors.New(text), text}
@@ -262,7 +379,7 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 		}
 
 		// get info on the referenced file; skip invalid paths
-		info, err := os.Stat(from)
+		info, err := os.Stat(longPath(from))
 		if err != nil || info.IsDir() {
 			text := fmt.Sprintf("invalid file reference %q: %v\n", from, err)
 			errText += text
@@ -283,12 +400,21 @@ func syncFiles(client utils.HTTPClient, projectPath string, projectID string, co
 			lastSync,
 		}
 		// "To" path is relative to the project
-		walker(filepath.Join(projectPath, refPath.To), wInfo, nil)
+		if walker(filepath.Join(projectPath, refPath.To), wInfo, nil) == errMaintenanceMode {
+			return nil, &ProjectError{errOpSyncMaintenance, errMaintenanceMode, errMaintenanceMode.Error()}
+		}
+	}
+	if ctx.Err() != nil {
+		return nil, &ProjectError{errOpSyncCancelled, i18n.Errorf(msgSyncCancelled, textSyncCancelled), textSyncCancelled}
 	}
 
 	if errText != "" {
+		errText += collisionText
 		return &SyncInfo{fileList, directoryList, modifiedList, uploadedFiles}, &ProjectError{errOpSyncRef, errors.New(errText), errText}
 	}
+	if collisionText != "" {
+		return &SyncInfo{fileList, directoryList, modifiedList, uploadedFiles}, &ProjectError{errOpSyncCase, errors.New(collisionText), collisionText}
+	}
 
 	return &SyncInfo{fileList, directoryList, modifiedList, uploadedFiles}, nil
 }
@@ -298,14 +424,14 @@ func completeUpload(client utils.HTTPClient, projectID string, completeRequest C
 	jsonPayload, _ := json.Marshal(&completeRequest)
 	req, err := http.NewRequest("POST", uploadEndURL, bytes.NewBuffer(jsonPayload))
 	if err != nil {
-		fmt.Printf("error creating request %v\n", err)
+		logr.Errorf("Error creating the upload end request for project %v: %v", projectID, err)
 		return err.Error(), 0
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	resp, httpSecError := sechttp.DispatchHTTPRequest(client, req, conInfo)
 	if httpSecError != nil {
-		fmt.Printf("error making request  %v\n", httpSecError)
+		logr.Errorf("Error completing the upload of project %v: %v", projectID, httpSecError.Desc)
 		return httpSecError.Desc, 0
 	}
 	defer resp.Body.Close()
@@ -313,7 +439,17 @@ func completeUpload(client utils.HTTPClient, projectID string, completeRequest C
 	return resp.Status, resp.StatusCode
 }
 
-// Retrieve the ignoredPaths list from a .cw-settings file
+// IgnoredPaths : The paths that the .cw-settings file of a project excludes from syncs
+func IgnoredPaths(projectPath string) []string {
+	return retrieveIgnoredPathsList(projectPath)
+}
+
+// IsIgnoredPath : Reports whether a path relative to the project is excluded from syncs by the ignored paths
+func IsIgnoredPath(relativePath string, isDir bool, ignoredPaths []string) bool {
+	return ignoreFileOrDirectory(relativePath, isDir, ignoredPaths)
+}
+
+// Retrieve the ignoredPaths list from a .cw-settings file, after the paths of its ignore presets
 func retrieveIgnoredPathsList(projectPath string) []string {
 	cwSettingsPath := filepath.Join(projectPath, ".cw-settings")
 	var cwSettingsIgnoredPathsList []string
@@ -323,6 +459,9 @@ func retrieveIgnoredPathsList(projectPath string) []string {
 		err = json.Unmarshal(plan, &cwSettingsJSON)
 		if err == nil {
 			cwSettingsIgnoredPathsList = cwSettingsJSON.IgnoredPaths
+			if presetPaths := expandIgnorePreset(cwSettingsJSON.IgnorePreset); len(presetPaths) > 0 {
+				cwSettingsIgnoredPathsList = append(presetPaths, cwSettingsIgnoredPathsList...)
+			}
 		}
 	}
 	return cwSettingsIgnoredPathsList
@@ -343,15 +482,63 @@ func retrieveRefPathsList(projectPath string) []refPath {
 	return cwRefPathsList
 }
 
+// defaultIgnoredPaths are the operating system and editor files that are not synced, at any depth in a project.
+// Syncing them would upload files no build needs, and editors change them often enough to start needless syncs.
+var defaultIgnoredPaths = []struct {
+	pattern string
+	isDir   bool
+}{
+	{".DS_Store", false},
+	{"._*", false},
+	{"Thumbs.db", false},
+	{"desktop.ini", false},
+	{"*.swp", false},
+	{"*.swo", false},
+	{"*~", false},
+	{".#*", false},
+	{".idea", true},
+	{".vscode", true},
+}
+
+// ignoredByDefault reports whether the name of a file or directory matches a default ignored path. A project syncs
+// it again with an ignored path of "!" followed by a pattern matching the name, such as "!.vscode".
+func ignoredByDefault(name string, isDir bool, cwSettingsIgnoredPathsList []string) bool {
+	baseName := name[strings.LastIndex(name, "/")+1:]
+	for _, ignored := range defaultIgnoredPaths {
+		if matched, _ := filepath.Match(ignored.pattern, baseName); !matched || ignored.isDir != isDir {
+			continue
+		}
+		for _, fileName := range cwSettingsIgnoredPathsList {
+			if !strings.HasPrefix(fileName, "!") {
+				continue
+			}
+			if included, _ := filepath.Match(fileName[1:], baseName); included {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
 func ignoreFileOrDirectory(name string, isDir bool, cwSettingsIgnoredPathsList []string) bool {
+	if ignoredByDefault(name, isDir, cwSettingsIgnoredPathsList) {
+		return true
+	}
 	isFileInIgnoredList := false
 	for _, fileName := range cwSettingsIgnoredPathsList {
-		fileName = filepath.Clean(fileName)
+		// skip the default ignored paths the project syncs again
+		if strings.HasPrefix(fileName, "!") {
+			continue
+		}
+		// a path starting with **/ is ignored at any depth in the project
+		anyDepth := strings.HasPrefix(fileName, "**/")
+		fileName = filepath.Clean(strings.TrimPrefix(fileName, "**/"))
 		// remove preceding slash from older versions of cw-settings
 		if strings.HasPrefix(fileName, "/") {
 			fileName = string([]rune(fileName)[1:])
 		}
-		matched, err := filepath.Match(fileName, name)
+		matched, err := matchIgnoredPath(fileName, name, anyDepth)
 		if err != nil {
 			return false
 		}
@@ -363,6 +550,22 @@ func ignoreFileOrDirectory(name string, isDir bool, cwSettingsIgnoredPathsList [
 	return isFileInIgnoredList
 }
 
+// matchIgnoredPath reports whether a name matches an ignored path, or with anyDepth whether the name or the path
+// within any of its parent directories does
+func matchIgnoredPath(pattern string, name string, anyDepth bool) (bool, error) {
+	for {
+		matched, err := filepath.Match(pattern, name)
+		if err != nil || matched || !anyDepth {
+			return matched, err
+		}
+		slash := strings.Index(name, "/")
+		if slash < 0 {
+			return false, nil
+		}
+		name = name[slash+1:]
+	}
+}
+
 // handleMissingProjectDir : Respond to a local project dir not existing
 func handleMissingProjectDir(httpClient utils.HTTPClient, connection *connections.Connection, url, projectID string) *ProjectError {
 	req, requestErr := http.NewRequest("POST", url+"/api/v1/projects/"+projectID+"/missingLocalDir", nil)
@@ -384,12 +587,12 @@ func handleMissingProjectDir(httpClient utils.HTTPClient, connection *connection
 	return nil
 }
 
-func findNewFiles(client utils.HTTPClient, projectID string, beforefiles []string, afterfiles []string, projectPath string, connection *connections.Connection, conURL string) []string {
+func findNewFiles(ctx context.Context, client utils.HTTPClient, projectID string, beforefiles []string, afterfiles []string, projectPath string, connection *connections.Connection, conURL string) []string {
 	var newfiles []string
 	for _, filename := range afterfiles {
 		if !existsIn(filename, beforefiles) {
 			fullPath := filepath.Join(projectPath, filename)
-			syncFile(&http.Client{}, projectID, projectPath, fullPath, connection, conURL)
+			syncFile(ctx, sechttp.Client(), projectID, projectPath, fullPath, connection, conURL)
 			newfiles = append(newfiles, filename)
 		}
 	}
@@ -405,7 +608,13 @@ func existsIn(value string, slice []string) bool {
 	return false
 }
 
-func syncFile(client utils.HTTPClient, projectID string, projectPath string, path string, connection *connections.Connection, conURL string) UploadedFile {
+func syncFile(ctx context.Context, client utils.HTTPClient, projectID string, projectPath string, path string, connection *connections.Connection, conURL string) UploadedFile {
+	return uploadFile(ctx, client, projectID, projectPath, path, connection, conURL, "", false)
+}
+
+// uploadFile uploads a file to PFE. A file with a hash is uploaded so PFE keeps its contents by the hash, or only
+// refers to contents PFE already has when it is a reference.
+func uploadFile(ctx context.Context, client utils.HTTPClient, projectID string, projectPath string, path string, connection *connections.Connection, conURL string, hash string, reference bool) UploadedFile {
 	// use ToSlash to try and get both Windows and *NIX paths to be *NIX for pfe
 	relativePath := filepath.ToSlash(path[(len(projectPath) + 1):])
 	uploadResponse := UploadedFile{
@@ -414,13 +623,7 @@ func syncFile(client utils.HTTPClient, projectID string, projectPath string, pat
 		StatusCode: 0,
 	}
 	// Retrieve file info
-	fileStat, err := os.Stat(path)
-	if err != nil {
-		return uploadResponse
-	}
-
-	fileContent, err := ioutil.ReadFile(path)
-	// Return here if there is an error reading the file
+	fileStat, err := os.Stat(longPath(path))
 	if err != nil {
 		return uploadResponse
 	}
@@ -429,33 +632,99 @@ func syncFile(client utils.HTTPClient, projectID string, projectPath string, pat
 		IsDirectory:  fileStat.IsDir(),
 		Mode:         uint(fileStat.Mode().Perm()),
 		RelativePath: relativePath,
+		SHA256:       hash,
+		Reference:    reference,
 		Message:      "",
 	}
-
-	var buffer bytes.Buffer
-	zWriter := zlib.NewWriter(&buffer)
-	zWriter.Write([]byte(fileContent))
-
-	zWriter.Close()
-	encoded := base64.StdEncoding.EncodeToString(buffer.Bytes())
-	fileUploadBody.Message = encoded
-
-	buf := new(bytes.Buffer)
-	json.NewEncoder(buf).Encode(fileUploadBody)
+	getBody := uploadBody(path, fileUploadBody)
+	if reference {
+		message, _ := json.Marshal(fileUploadBody)
+		getBody = func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(message)), nil
+		}
+	}
+	body, err := getBody()
+	// Return here if there is an error reading the file
+	if err != nil {
+		return uploadResponse
+	}
 
 	projectUploadURL := conURL + "/api/v1/projects/" + projectID + "/upload"
 	// TODO - How do we handle partial success?
-	request, err := http.NewRequest("PUT", projectUploadURL, bytes.NewReader(buf.Bytes()))
+	request, err := http.NewRequest("PUT", projectUploadURL, body)
+	if err != nil {
+		body.Close()
+		return uploadResponse
+	}
+	// Let the request be retried by reading the file again
+	request.GetBody = getBody
+	// Stop the upload if the request is not sent, closing the file. Retries replace the body, so close the last one.
+	defer func() { request.Body.Close() }()
 	request.Header.Set("Content-Type", "application/json")
-	resp, httpSecError := sechttp.DispatchHTTPRequest(client, request, connection)
+	uploadCtx := ctx
+	if !reference {
+		// File contents are streamed, so a large file is only stopped by the read timeout if it stalls
+		uploadCtx = sechttp.WithoutRequestTimeout(ctx)
+	}
+	resp, httpSecError := sechttp.DispatchHTTPRequestContext(uploadCtx, client, request, connection)
 
 	if httpSecError != nil {
 		return uploadResponse
 	}
+	// Read the body to the end so that the connection can be reused for the next file
 	defer resp.Body.Close()
+	io.Copy(ioutil.Discard, resp.Body)
+	// The upload has been retried, so a 503 that remains means PFE is scaled down for maintenance rather than restarting
 	return UploadedFile{
-		FilePath:   relativePath,
-		Status:     resp.Status,
-		StatusCode: resp.StatusCode,
+		FilePath:     relativePath,
+		Status:       resp.Status,
+		StatusCode:   resp.StatusCode,
+		Deduplicated: reference,
+		maintenance:  maintenance.Unavailable(resp),
+	}
+}
+
+// uploadBody returns a function opening the body of a file upload, which streams the JSON message as the file is read,
+// compressed and base64 encoded, so the memory used by an upload does not grow with the size of the file.
+// The message is built like a FileUploadMsg whose Message is the encoded file.
+func uploadBody(path string, fileUploadBody FileUploadMsg) func() (io.ReadCloser, error) {
+	return func() (io.ReadCloser, error) {
+		file, err := os.Open(longPath(path))
+		if err != nil {
+			return nil, err
+		}
+		reader, writer := io.Pipe()
+		go func() {
+			defer file.Close()
+			writer.CloseWithError(writeUploadMessage(writer, file, fileUploadBody))
+		}()
+		return reader, nil
+	}
+}
+
+// writeUploadMessage writes the JSON of a file upload message, encoding the contents of the file as its message
+func writeUploadMessage(writer io.Writer, file io.Reader, fileUploadBody FileUploadMsg) error {
+	fileUploadBody.Message = ""
+	header, err := json.Marshal(fileUploadBody)
+	if err != nil {
+		return err
+	}
+	// The message is the last field, so the encoded file is written between the quotes that end the JSON
+	messageEnd := []byte("\"}")
+	if _, err := writer.Write(bytes.TrimSuffix(header, messageEnd)); err != nil {
+		return err
+	}
+	encoder := base64.NewEncoder(base64.StdEncoding, writer)
+	zWriter := zlib.NewWriter(encoder)
+	if _, err := io.Copy(zWriter, file); err != nil {
+		return err
+	}
+	if err := zWriter.Close(); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
 	}
+	_, err = writer.Write(append(messageEnd, '\n'))
+	return err
 }
@@ -13,16 +13,22 @@ package project
 
 import (
 	"bytes"
+	"compress/zlib"
+	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path"
 	"testing"
 	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/maintenance"
 	"github.com/eclipse/codewind-installer/pkg/security"
 	"github.com/stretchr/testify/assert"
 )
@@ -148,6 +154,60 @@ func TestIgnoreFileOrDirectory(t *testing.T) {
 			shouldBeIgnored:  true,
 			ignoredPathsList: []string{".idea"},
 		},
+		"success case: file called .DS_Store in a subdirectory should be ignored by default": {
+			name:             "src/.DS_Store",
+			isDir:            false,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{},
+		},
+		"success case: editor swap and backup files should be ignored by default": {
+			name:             "src/.app.js.swp",
+			isDir:            false,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{},
+		},
+		"success case: file ending in ~ should be ignored by default": {
+			name:             "src/app.js~",
+			isDir:            false,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{},
+		},
+		"success case: directory called .vscode should be ignored by default": {
+			name:             ".vscode",
+			isDir:            true,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{},
+		},
+		"success case: directory called .vscode should not be ignored when .cw-settings includes it again": {
+			name:             ".vscode",
+			isDir:            true,
+			shouldBeIgnored:  false,
+			ignoredPathsList: []string{"!.vscode"},
+		},
+		"success case: including a default ignored path again does not include the others": {
+			name:             "Thumbs.db",
+			isDir:            false,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{"!.vscode"},
+		},
+		"success case: node_modules in a subdirectory should be ignored by a path starting with **/": {
+			name:             "packages/web/node_modules",
+			isDir:            true,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{"**/node_modules"},
+		},
+		"success case: node_modules in a subdirectory should not be ignored by a path without **/": {
+			name:             "packages/web/node_modules",
+			isDir:            true,
+			shouldBeIgnored:  false,
+			ignoredPathsList: []string{"node_modules"},
+		},
+		"success case: a path with directories starting with **/ should be ignored at any depth": {
+			name:             "services/api/src/generated",
+			isDir:            true,
+			shouldBeIgnored:  true,
+			ignoredPathsList: []string{"**/src/generated"},
+		},
 	}
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
@@ -192,7 +252,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(mockProjectPath, "test"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(context.Background(), mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -210,7 +270,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(mockProjectPath, "testfile"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(context.Background(), mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -229,7 +289,7 @@ func TestSyncFiles(t *testing.T) {
 		ioutil.WriteFile(path.Join(newDirPath, "test"), []byte{}, 0644)
 		ioutil.WriteFile(path.Join(mockProjectPath, ".cw-settings"), cwSettingsFile, 0644)
 
-		got, err := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
+		got, err := syncFiles(context.Background(), mockClient, mockProjectPath, "mockID", "dummyURL", 0, &mockConnection)
 		if err != nil {
 			t.Errorf("syncFiles() failed with error: %s", err)
 		}
@@ -262,7 +322,7 @@ func TestSyncFiles(t *testing.T) {
 		time.Sleep(1 * time.Second)
 		ioutil.WriteFile(modTestPath, newContent, 0644)
 
-		got, _ := syncFiles(mockClient, mockProjectPath, "mockID", "dummyURL", modifiedTime, &mockConnection)
+		got, _ := syncFiles(context.Background(), mockClient, mockProjectPath, "mockID", "dummyURL", modifiedTime, &mockConnection)
 
 		expectedFileList := []string{".cw-settings", "nested-dir/testmod", "nested-dir/testnomod"}
 		expectedDirList := []string{"nested-dir"}
@@ -378,3 +438,188 @@ func cleanupTestFolder(t *testing.T, testFolder string) {
 		fmt.Println("Error removing test dir, you may need to remove manually")
 	}
 }
+
+func TestRecordSyncReport(t *testing.T) {
+	reportsDir, err := ioutil.TempDir("", "sync-reports")
+	assert.Nil(t, err)
+	defer os.RemoveAll(reportsDir)
+
+	t.Run("keeps the files that failed to upload and the error of a sync", func(t *testing.T) {
+		response := &SyncResponse{Status: "Failed", StatusCode: 500, UploadedFiles: []UploadedFile{
+			{FilePath: "package.json", Status: "OK", StatusCode: 200},
+			{FilePath: "server.js", Status: "Request Entity Too Large", StatusCode: 413},
+		}}
+		syncErr := &ProjectError{errOpSyncRef, nil, "invalid file reference"}
+		recordSyncReport(reportsDir, "project-id", newSyncReport(time.Now(), response, syncErr))
+
+		contents, err := ioutil.ReadFile(path.Join(reportsDir, "project-id.json"))
+		assert.Nil(t, err)
+		reports := []SyncReport{}
+		assert.Nil(t, json.Unmarshal(contents, &reports))
+		assert.Len(t, reports, 1)
+		assert.Equal(t, 2, reports[0].Uploaded)
+		assert.Equal(t, []UploadedFile{response.UploadedFiles[1]}, reports[0].Failed)
+		assert.Equal(t, errOpSyncRef+": invalid file reference", reports[0].Error)
+	})
+
+	t.Run("keeps only the most recent syncs", func(t *testing.T) {
+		for i := 0; i < maxSyncReports+5; i++ {
+			recordSyncReport(reportsDir, "project-id", newSyncReport(time.Now(), &SyncResponse{Status: fmt.Sprint(i)}, nil))
+		}
+		contents, err := ioutil.ReadFile(path.Join(reportsDir, "project-id.json"))
+		assert.Nil(t, err)
+		reports := []SyncReport{}
+		assert.Nil(t, json.Unmarshal(contents, &reports))
+		assert.Len(t, reports, maxSyncReports)
+		assert.Equal(t, fmt.Sprint(maxSyncReports+4), reports[maxSyncReports-1].Status)
+	})
+}
+
+func TestUploadBody(t *testing.T) {
+	dir, err := ioutil.TempDir("", "upload-body")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	contents := bytes.Repeat([]byte("<codewind & sync>\n"), 50000)
+	filePath := path.Join(dir, "large.txt")
+	ioutil.WriteFile(filePath, contents, 0644)
+	msg := FileUploadMsg{Mode: 0644, RelativePath: "src/<large>.txt"}
+
+	readMessage := func(getBody func() (io.ReadCloser, error)) []byte {
+		body, err := getBody()
+		assert.Nil(t, err)
+		defer body.Close()
+		streamed, err := ioutil.ReadAll(body)
+		assert.Nil(t, err)
+		return streamed
+	}
+
+	t.Run("streams the same message as encoding it in memory", func(t *testing.T) {
+		var compressed bytes.Buffer
+		zWriter := zlib.NewWriter(&compressed)
+		zWriter.Write(contents)
+		zWriter.Close()
+		buffered := msg
+		buffered.Message = base64.StdEncoding.EncodeToString(compressed.Bytes())
+		expected := new(bytes.Buffer)
+		json.NewEncoder(expected).Encode(buffered)
+
+		assert.Equal(t, expected.String(), string(readMessage(uploadBody(filePath, msg))))
+	})
+
+	t.Run("reads the file again for every body, so uploads can be retried", func(t *testing.T) {
+		getBody := uploadBody(filePath, msg)
+		assert.Equal(t, readMessage(getBody), readMessage(getBody))
+	})
+
+	t.Run("fails to open a file that does not exist", func(t *testing.T) {
+		_, err := uploadBody(path.Join(dir, "missing.txt"), msg)()
+		assert.True(t, os.IsNotExist(err))
+	})
+}
+
+func TestSyncFilesCaseCollisions(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "sync-case")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	os.MkdirAll(path.Join(projectPath, "src"), 0777)
+	if _, err := os.Stat(path.Join(projectPath, "Src")); err == nil {
+		t.Skip("skipping on a case insensitive file system")
+	}
+	os.MkdirAll(path.Join(projectPath, "Src"), 0777)
+	ioutil.WriteFile(path.Join(projectPath, "src", "app.js"), []byte{}, 0644)
+	ioutil.WriteFile(path.Join(projectPath, "Src", "app.js"), []byte{}, 0644)
+	ioutil.WriteFile(path.Join(projectPath, "README.md"), []byte{}, 0644)
+	ioutil.WriteFile(path.Join(projectPath, "readme.md"), []byte{}, 0644)
+	body := ioutil.NopCloser(bytes.NewReader([]byte{}))
+	mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusOK, Body: body}
+
+	got, projErr := syncFiles(context.Background(), mockClient, projectPath, "mockID", "dummyURL", 0, &connections.Connection{ID: "local"})
+	assert.Equal(t, []string{"README.md", "Src/app.js"}, got.fileList)
+	assert.Equal(t, []string{"Src"}, got.directoryList)
+	assert.Equal(t, errOpSyncCase, projErr.Op)
+	assert.Equal(t, "readme.md differs only by case from README.md, so it was not synced\nsrc differs only by case from Src, so it was not synced\n", projErr.Desc)
+}
+
+func TestSyncFilesCancelled(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "sync-cancel")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	for _, file := range []string{"a.js", "b.js", "c.js"} {
+		ioutil.WriteFile(path.Join(projectPath, file), []byte("contents"), 0644)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	uploads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			uploads++
+			cancel()
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	got, projErr := syncFiles(ctx, http.DefaultClient, projectPath, "mockID", server.URL, 0, &connections.Connection{ID: "local"})
+	assert.Nil(t, got)
+	assert.Equal(t, errOpSyncCancelled, projErr.Op)
+	assert.Equal(t, 1, uploads)
+}
+
+func TestSyncFilesMaintenance(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "sync-maintenance")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	ioutil.WriteFile(path.Join(projectPath, "a.js"), []byte("contents"), 0644)
+	retries := 0
+	connection := &connections.Connection{ID: "local", Retries: &retries}
+
+	t.Run("error case - PFE is unavailable during maintenance", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		_, projErr := syncFiles(context.Background(), http.DefaultClient, projectPath, "mockID", server.URL, 0, connection)
+		assert.Equal(t, errOpSyncMaintenance, projErr.Op)
+	})
+
+	t.Run("error case - uploads announced as in maintenance are not retried", func(t *testing.T) {
+		uploads := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Method == "PUT" {
+				uploads++
+			}
+			w.Header().Set(maintenance.Header, "true")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		retrying := &connections.Connection{ID: "local", RetryBackoff: "1ms"}
+		_, projErr := syncFiles(context.Background(), http.DefaultClient, projectPath, "mockID", server.URL, 0, retrying)
+		assert.Equal(t, errOpSyncMaintenance, projErr.Op)
+		assert.Equal(t, 1, uploads)
+	})
+
+	t.Run("success case - PFE becoming available again during the retries", func(t *testing.T) {
+		unavailable := 1
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if unavailable > 0 {
+				unavailable--
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		retrying := &connections.Connection{ID: "local", RetryBackoff: "1ms"}
+		got, projErr := syncFiles(context.Background(), http.DefaultClient, projectPath, "mockID", server.URL, 0, retrying)
+		assert.Nil(t, projErr)
+		assert.Equal(t, []UploadedFile{{FilePath: "a.js", Status: "200 OK", StatusCode: http.StatusOK}}, got.UploadedFileList)
+	})
+}
@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"os"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// uploadDedup uploads the files of a sync, sending the contents of files with the same contents only once when PFE
+// supports it. Vendored and generated copies of the same files then cost a small reference each, not a full upload.
+type uploadDedup struct {
+	client     utils.HTTPClient
+	connection *connections.Connection
+	conURL     string
+	// supported is nil until PFE is asked whether it supports deduplicated uploads, on the first upload of the sync
+	supported *bool
+	// uploaded are the hashes of the contents uploaded in this sync
+	uploaded map[string]bool
+}
+
+func newUploadDedup(client utils.HTTPClient, connection *connections.Connection, conURL string) *uploadDedup {
+	return &uploadDedup{client: client, connection: connection, conURL: conURL, uploaded: map[string]bool{}}
+}
+
+// syncFile uploads a file, sending only a reference to contents already uploaded in this sync. A reference PFE does
+// not know, such as after PFE restarted, is followed by a full upload.
+func (dedup *uploadDedup) syncFile(ctx context.Context, projectID string, projectPath string, path string) UploadedFile {
+	if !dedup.isSupported() {
+		return syncFile(ctx, dedup.client, projectID, projectPath, path, dedup.connection, dedup.conURL)
+	}
+	hash, err := fileSHA256(path)
+	if err != nil {
+		return syncFile(ctx, dedup.client, projectID, projectPath, path, dedup.connection, dedup.conURL)
+	}
+	if dedup.uploaded[hash] {
+		response := uploadFile(ctx, dedup.client, projectID, projectPath, path, dedup.connection, dedup.conURL, hash, true)
+		if response.StatusCode != http.StatusNotFound {
+			return response
+		}
+		logr.Tracef("Codewind does not have the contents of %v, uploading them again", response.FilePath)
+	}
+	response := uploadFile(ctx, dedup.client, projectID, projectPath, path, dedup.connection, dedup.conURL, hash, false)
+	if response.StatusCode == http.StatusOK {
+		dedup.uploaded[hash] = true
+	}
+	return response
+}
+
+// isSupported reports whether PFE supports deduplicated uploads, asking it the first time
+func (dedup *uploadDedup) isSupported() bool {
+	if dedup.supported == nil {
+		capabilities, err := apiroutes.GetPFECapabilities(dedup.connection, dedup.conURL, dedup.client)
+		if err != nil {
+			logr.Tracef("Unable to get the capabilities of Codewind, uploading every file: %v", err)
+		}
+		supported := apiroutes.HasCapability(capabilities, apiroutes.CapabilityUploadDedup)
+		dedup.supported = &supported
+	}
+	return *dedup.supported
+}
+
+// fileSHA256 returns the hex encoded SHA-256 hash of the contents of a file, reading it in blocks
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(longPath(path))
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,118 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockDedupPFE is a PFE that records the files uploaded to it and keeps their contents by hash when it supports
+// deduplicated uploads
+type mockDedupPFE struct {
+	mutex        sync.Mutex
+	capabilities []string
+	blobs        map[string]bool
+	uploads      []FileUploadMsg
+}
+
+func (pfe *mockDedupPFE) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pfe.mutex.Lock()
+	defer pfe.mutex.Unlock()
+	if r.URL.Path == "/api/v1/environment" {
+		json.NewEncoder(w).Encode(apiroutes.EnvResponse{Capabilities: pfe.capabilities})
+		return
+	}
+	var upload FileUploadMsg
+	json.NewDecoder(r.Body).Decode(&upload)
+	pfe.uploads = append(pfe.uploads, upload)
+	if upload.Reference && !pfe.blobs[upload.SHA256] {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if upload.SHA256 != "" {
+		pfe.blobs[upload.SHA256] = true
+	}
+}
+
+func TestUploadDedup(t *testing.T) {
+	projectPath, err := ioutil.TempDir("", "sync-dedup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(projectPath)
+	os.MkdirAll(filepath.Join(projectPath, "vendor"), 0777)
+	ioutil.WriteFile(filepath.Join(projectPath, "a.js"), []byte("shared"), 0644)
+	ioutil.WriteFile(filepath.Join(projectPath, "b.js"), []byte("unique"), 0644)
+	ioutil.WriteFile(filepath.Join(projectPath, "vendor", "a.js"), []byte("shared"), 0644)
+	connection := &connections.Connection{ID: "local"}
+
+	syncAll := func(pfe *mockDedupPFE) []UploadedFile {
+		server := httptest.NewServer(pfe)
+		defer server.Close()
+		dedup := newUploadDedup(http.DefaultClient, connection, server.URL)
+		uploaded := []UploadedFile{}
+		for _, file := range []string{"a.js", "b.js", "vendor/a.js"} {
+			uploaded = append(uploaded, dedup.syncFile(context.Background(), "mockID", projectPath, filepath.Join(projectPath, file)))
+		}
+		return uploaded
+	}
+
+	t.Run("uploads the contents shared by several files once", func(t *testing.T) {
+		pfe := &mockDedupPFE{capabilities: []string{apiroutes.CapabilityUploadDedup}, blobs: map[string]bool{}}
+		uploaded := syncAll(pfe)
+		assert.Equal(t, []bool{false, false, true}, []bool{uploaded[0].Deduplicated, uploaded[1].Deduplicated, uploaded[2].Deduplicated})
+		assert.Len(t, pfe.uploads, 3)
+		assert.NotEmpty(t, pfe.uploads[0].Message)
+		assert.Equal(t, pfe.uploads[0].SHA256, pfe.uploads[2].SHA256)
+		assert.NotEqual(t, pfe.uploads[0].SHA256, pfe.uploads[1].SHA256)
+		assert.True(t, pfe.uploads[2].Reference)
+		assert.Empty(t, pfe.uploads[2].Message)
+		assert.Equal(t, "vendor/a.js", pfe.uploads[2].RelativePath)
+	})
+
+	t.Run("uploads the contents again when PFE no longer has them", func(t *testing.T) {
+		pfe := &mockDedupPFE{capabilities: []string{apiroutes.CapabilityUploadDedup}, blobs: map[string]bool{}}
+		server := httptest.NewServer(pfe)
+		defer server.Close()
+		dedup := newUploadDedup(http.DefaultClient, connection, server.URL)
+		dedup.syncFile(context.Background(), "mockID", projectPath, filepath.Join(projectPath, "a.js"))
+		pfe.blobs = map[string]bool{}
+		uploaded := dedup.syncFile(context.Background(), "mockID", projectPath, filepath.Join(projectPath, "vendor", "a.js"))
+		assert.Equal(t, http.StatusOK, uploaded.StatusCode)
+		assert.False(t, uploaded.Deduplicated)
+		assert.Len(t, pfe.uploads, 3)
+		assert.NotEmpty(t, pfe.uploads[2].Message)
+	})
+
+	t.Run("uploads every file to PFE without deduplicated uploads", func(t *testing.T) {
+		pfe := &mockDedupPFE{blobs: map[string]bool{}}
+		syncAll(pfe)
+		assert.Len(t, pfe.uploads, 3)
+		for _, upload := range pfe.uploads {
+			assert.Empty(t, upload.SHA256)
+			assert.False(t, upload.Reference)
+			assert.NotEmpty(t, upload.Message)
+		}
+	})
+}
@@ -0,0 +1,82 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path"
+	"time"
+)
+
+// maxSyncReports is how many of the most recent syncs of a project are kept
+const maxSyncReports = 20
+
+// SyncReport : The outcome of one sync of a project, kept so that failed syncs can be diagnosed afterwards
+type SyncReport struct {
+	Time       string         `json:"time"`
+	DurationMS int64          `json:"durationMs"`
+	Status     string         `json:"status"`
+	StatusCode int            `json:"statusCode"`
+	Uploaded   int            `json:"uploaded"`
+	Failed     []UploadedFile `json:"failed,omitempty"`
+	Error      string         `json:"error,omitempty"`
+}
+
+// SyncReportsDir : Returns the directory the recent sync reports of each project are kept in
+func SyncReportsDir() string {
+	return path.Join(getCodewindDir(), "sync-reports")
+}
+
+// newSyncReport summarises a sync that started at a time
+func newSyncReport(started time.Time, response *SyncResponse, syncErr *ProjectError) SyncReport {
+	report := SyncReport{
+		Time:       started.UTC().Format(time.RFC3339),
+		DurationMS: time.Since(started).Nanoseconds() / int64(time.Millisecond),
+	}
+	if response != nil {
+		report.Status = response.Status
+		report.StatusCode = response.StatusCode
+		report.Uploaded = len(response.UploadedFiles)
+		for _, file := range response.UploadedFiles {
+			if file.StatusCode != 200 {
+				report.Failed = append(report.Failed, file)
+			}
+		}
+	}
+	if syncErr != nil {
+		report.Error = syncErr.Op + ": " + syncErr.Desc
+	}
+	return report
+}
+
+// recordSyncReport adds a report to those kept for a project, dropping the oldest beyond maxSyncReports. Failing to
+// keep a report does not fail the sync.
+func recordSyncReport(reportsDir string, projectID string, report SyncReport) {
+	reportsFile := path.Join(reportsDir, projectID+".json")
+	reports := []SyncReport{}
+	if contents, err := ioutil.ReadFile(reportsFile); err == nil {
+		json.Unmarshal(contents, &reports)
+	}
+	reports = append(reports, report)
+	if len(reports) > maxSyncReports {
+		reports = reports[len(reports)-maxSyncReports:]
+	}
+	contents, err := json.MarshalIndent(reports, "", "  ")
+	if err != nil {
+		return
+	}
+	if os.MkdirAll(reportsDir, 0755) == nil {
+		ioutil.WriteFile(reportsFile, contents, 0644)
+	}
+}
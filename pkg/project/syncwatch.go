@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package project
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// DefaultWatchInterval is how often a watched project is polled for changes
+const DefaultWatchInterval = 2 * time.Second
+
+// watchSync syncs a watched project, replaced by tests
+var watchSync = SyncContext
+
+// WatchSync : Syncs a project, then polls it for changes at an interval, syncing it again each time its files change
+// until the context is done. Each sync is passed to synced. A sync that fails is tried again at the next poll. Only the
+// first sync uploads the files modified since the ModifiedSince of the options.
+func WatchSync(ctx context.Context, options SyncOptions, interval time.Duration, synced func(*SyncResponse, *ProjectError)) *ProjectError {
+	if interval <= 0 {
+		err := i18n.Errorf(msgInvalidWatchInterval, textInvalidWatchInterval)
+		return &ProjectError{errOpInvalidOptions, err, textInvalidWatchInterval}
+	}
+	strategy, projErr := ResolveChangeDetection(options.ChangeDetection, options.Path)
+	if projErr != nil {
+		return projErr
+	}
+	options.ChangeDetection = strategy
+
+	var previous syncSnapshot
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		current := scanProject(options.Path)
+		if previous == nil || !current.equal(previous) {
+			started := time.Now().UnixNano() / 1000000
+			response, syncErr := watchSync(ctx, options)
+			if ctx.Err() != nil {
+				return nil
+			}
+			synced(response, syncErr)
+			if response != nil && response.StatusCode == http.StatusOK {
+				previous, options.LastSync = current, started
+				options.ModifiedSince = time.Time{}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -14,15 +14,34 @@ package project
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/sechttp"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 )
 
+// UnbindOptions : What PFE keeps when a project is unbound. By default the deployment of the project and the files
+// synced to PFE are deleted.
+type UnbindOptions struct {
+	KeepDeployment bool
+	KeepFiles      bool
+}
+
 // Unbind a project from Codewind
-func Unbind(httpClient utils.HTTPClient, connection *connections.Connection, url, projectID string) *ProjectError {
-	req, err := http.NewRequest("POST", url+"/api/v1/projects/"+projectID+"/unbind", nil)
+func Unbind(httpClient utils.HTTPClient, connection *connections.Connection, pfeURL, projectID string, options UnbindOptions) *ProjectError {
+	query := url.Values{}
+	if options.KeepDeployment {
+		query.Set("keepDeployment", "true")
+	}
+	if options.KeepFiles {
+		query.Set("keepFiles", "true")
+	}
+	unbindURL := pfeURL + "/api/v1/projects/" + projectID + "/unbind"
+	if len(query) > 0 {
+		unbindURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest("POST", unbindURL, nil)
 	if err != nil {
 		return &ProjectError{errOpUnbind, err, err.Error()}
 	}
@@ -22,13 +22,23 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// mockUnbindClient records the URL of the request sent
+type mockUnbindClient struct {
+	requestURL string
+}
+
+func (c *mockUnbindClient) Do(req *http.Request) (*http.Response, error) {
+	c.requestURL = req.URL.String()
+	return &http.Response{StatusCode: http.StatusAccepted, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+}
+
 func TestUnbind(t *testing.T) {
 	mockConnection := connections.Connection{ID: "local"}
 
 	body := ioutil.NopCloser(bytes.NewReader([]byte("")))
 	t.Run("Expect success - project unbinds", func(t *testing.T) {
 		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusAccepted, Body: body}
-		err := Unbind(mockClient, &mockConnection, "dummyurl", "mockID")
+		err := Unbind(mockClient, &mockConnection, "dummyurl", "mockID", UnbindOptions{})
 		if err != nil {
 			t.Errorf("Unbind() failed with error %s", err)
 		}
@@ -36,7 +46,26 @@ func TestUnbind(t *testing.T) {
 
 	t.Run("Expect failure - pfe returns non 202 status", func(t *testing.T) {
 		mockClient := &security.ClientMockAuthenticate{StatusCode: http.StatusBadRequest, Body: body}
-		err := Unbind(mockClient, &mockConnection, "dummyurl", "mockID")
+		err := Unbind(mockClient, &mockConnection, "dummyurl", "mockID", UnbindOptions{})
 		assert.Error(t, err)
 	})
+	t.Run("Expect PFE to be asked to keep the deployment and files", func(t *testing.T) {
+		tests := map[string]struct {
+			options UnbindOptions
+			wantURL string
+		}{
+			"default removes everything": {UnbindOptions{}, "dummyurl/api/v1/projects/mockID/unbind"},
+			"keep deployment":            {UnbindOptions{KeepDeployment: true}, "dummyurl/api/v1/projects/mockID/unbind?keepDeployment=true"},
+			"keep files":                 {UnbindOptions{KeepFiles: true}, "dummyurl/api/v1/projects/mockID/unbind?keepFiles=true"},
+			"keep both":                  {UnbindOptions{KeepDeployment: true, KeepFiles: true}, "dummyurl/api/v1/projects/mockID/unbind?keepDeployment=true&keepFiles=true"},
+		}
+		for name, test := range tests {
+			t.Run(name, func(t *testing.T) {
+				mockClient := &mockUnbindClient{}
+				err := Unbind(mockClient, &mockConnection, "dummyurl", "mockID", test.options)
+				assert.Nil(t, err)
+				assert.Equal(t, test.wantURL, mockClient.requestURL)
+			})
+		}
+	})
 }
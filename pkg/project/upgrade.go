@@ -17,6 +17,12 @@ import (
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 )
 
 // UpgradeProjects : Upgrades Projects
@@ -39,7 +45,7 @@ func UpgradeProjects(oldDir string) (*map[string]interface{}, *ProjectError) {
 
 	filepath.Walk(projectDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
-			err = errors.New(textUpgradeError)
+			err = i18n.Errorf(msgUpgradeError, textUpgradeError)
 			return &ProjectError{errOpFileParse, err, textUpgradeError}
 		}
 
@@ -81,7 +87,7 @@ func UpgradeProjects(oldDir string) (*map[string]interface{}, *ProjectError) {
 			} else {
 				errResponse := make(map[string]string)
 				errResponse["projectName"] = name
-				errResponse["error"] = "Unable to upgrade project, failed to determine project details"
+				errResponse["error"] = textUpgradeNoDetails
 				migrationStatus["failed"] = append(migrationStatus["failed"].([]interface{}), &errResponse)
 			}
 		}
@@ -89,3 +95,226 @@ func UpgradeProjects(oldDir string) (*map[string]interface{}, *ProjectError) {
 	})
 	return &migrationStatus, nil
 }
+
+type (
+	// UpgradeWorkspaceOptions : The workspace of an older version of Codewind to migrate, and the connection its
+	// projects are bound to
+	UpgradeWorkspaceOptions struct {
+		// Workspace is the directory of the older workspace, LegacyWorkspaceDir() by default
+		Workspace string
+		ConID     string
+		// DryRun reports what would be migrated, without changing any files or binding any projects
+		DryRun bool
+	}
+
+	// WorkspaceUpgrade : The outcome of migrating a workspace
+	WorkspaceUpgrade struct {
+		Status    string           `json:"status"`
+		Workspace string           `json:"workspace"`
+		Layout    string           `json:"layout"`
+		Migrated  int              `json:"migrated"`
+		Skipped   int              `json:"skipped"`
+		Failed    int              `json:"failed"`
+		Projects  []ProjectUpgrade `json:"projects"`
+	}
+
+	// ProjectUpgrade : The outcome of migrating one project of a workspace
+	ProjectUpgrade struct {
+		Name      string `json:"name"`
+		Path      string `json:"path"`
+		Language  string `json:"language"`
+		BuildType string `json:"projectType"`
+		ProjectID string `json:"projectID,omitempty"`
+		// Settings are the settings moved from the project metadata into .cw-settings
+		Settings []string `json:"settings,omitempty"`
+		Status   string   `json:"status"`
+		Error    string   `json:"error,omitempty"`
+	}
+)
+
+// Layouts of a workspace
+const (
+	// LayoutProjectInfo is the layout of Codewind 0.x, in which projects were created in the workspace and PFE kept
+	// their metadata in .projects/<name>.inf
+	LayoutProjectInfo = "project-info"
+	// LayoutCurrent is a workspace without metadata to migrate, as projects are now bound from anywhere
+	LayoutCurrent = "current"
+)
+
+// Statuses of a migrated project
+const (
+	UpgradeMigrated = "migrated"
+	UpgradePending  = "pending"
+	UpgradeSkipped  = "skipped"
+	UpgradeFailed   = "failed"
+)
+
+// migratedInfoSuffix is added to the metadata of migrated projects, which keeps it for reference while stopping it
+// being migrated again
+const migratedInfoSuffix = ".migrated"
+
+// LegacyWorkspaceDir : Returns the directory Codewind 0.x created projects in, which newer versions no longer use
+func LegacyWorkspaceDir() string {
+	if runtime.GOOS == "windows" {
+		return "C:\\codewind-workspace"
+	}
+	return filepath.Join(os.Getenv("HOME"), "codewind-workspace")
+}
+
+// DetectWorkspaceLayout : Returns the layout of a workspace, and the metadata files of its projects that have not
+// been migrated yet
+func DetectWorkspaceLayout(workspace string) (string, []string, *ProjectError) {
+	info, err := os.Stat(workspace)
+	if err != nil {
+		return "", nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	if !info.IsDir() {
+		err = errors.New(workspace + " is not a directory")
+		return "", nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	infoFiles, _ := filepath.Glob(filepath.Join(workspace, ".projects", "*.inf"))
+	if len(infoFiles) == 0 {
+		return LayoutCurrent, []string{}, nil
+	}
+	sort.Strings(infoFiles)
+	return LayoutProjectInfo, infoFiles, nil
+}
+
+// UpgradeWorkspace : Migrates the projects of a workspace created by an older version of Codewind. The settings
+// each project had in its metadata are written to a new .cw-settings, or a Microclimate .mc-settings is renamed, and
+// the project is bound again where it is. A project failing to migrate does not stop the others, and its metadata
+// is kept so that it is tried again the next time. Projects whose name is already bound are skipped.
+// Only the project metadata is migrated. The codewind-workspace volume holds what PFE builds for bound projects, so it
+// is rebuilt as projects are bound again, and cwctl upgrades the connections in ~/.codewind/config each time it starts.
+func UpgradeWorkspace(options UpgradeWorkspaceOptions) (*WorkspaceUpgrade, *ProjectError) {
+	workspace := options.Workspace
+	if workspace == "" {
+		workspace = LegacyWorkspaceDir()
+	}
+	workspace, err := filepath.Abs(workspace)
+	if err != nil {
+		return nil, &ProjectError{errBadPath, err, err.Error()}
+	}
+	layout, infoFiles, projErr := DetectWorkspaceLayout(workspace)
+	if projErr != nil {
+		return nil, projErr
+	}
+
+	result := WorkspaceUpgrade{Status: "OK", Workspace: workspace, Layout: layout, Projects: []ProjectUpgrade{}}
+	for _, infoFile := range infoFiles {
+		upgrade := upgradeProject(workspace, infoFile, options)
+		switch upgrade.Status {
+		case UpgradeMigrated:
+			result.Migrated++
+		case UpgradeSkipped:
+			result.Skipped++
+		case UpgradeFailed:
+			result.Failed++
+		}
+		result.Projects = append(result.Projects, upgrade)
+	}
+	if result.Failed > 0 {
+		result.Status = "error"
+	}
+	return &result, nil
+}
+
+// upgradeProject migrates the project described by a metadata file
+func upgradeProject(workspace string, infoFile string, options UpgradeWorkspaceOptions) ProjectUpgrade {
+	upgrade := ProjectUpgrade{Name: strings.TrimSuffix(filepath.Base(infoFile), ".inf"), Status: UpgradeFailed}
+	contents, err := ioutil.ReadFile(infoFile)
+	if err != nil {
+		upgrade.Error = err.Error()
+		return upgrade
+	}
+	info := map[string]interface{}{}
+	if err := json.Unmarshal(contents, &info); err != nil {
+		upgrade.Error = err.Error()
+		return upgrade
+	}
+	if name, ok := info["name"].(string); ok && name != "" {
+		upgrade.Name = name
+	}
+	upgrade.Language, _ = info["language"].(string)
+	upgrade.BuildType, _ = info["projectType"].(string)
+	upgrade.Path = filepath.Join(workspace, upgrade.Name)
+	if upgrade.Language == "" || upgrade.BuildType == "" {
+		upgrade.Error = textUpgradeNoDetails
+		return upgrade
+	}
+	if _, err := os.Stat(upgrade.Path); err != nil {
+		upgrade.Error = err.Error()
+		return upgrade
+	}
+
+	settings, settingsErr := migrateSettings(upgrade.Path, info, upgrade.BuildType, options.DryRun)
+	upgrade.Settings = settings
+	if settingsErr != nil {
+		upgrade.Error = settingsErr.Error()
+		return upgrade
+	}
+	if options.DryRun {
+		upgrade.Status = UpgradePending
+		return upgrade
+	}
+
+	response, projErr := bindProject(upgrade.Path, upgrade.Name, upgrade.Language, upgrade.BuildType, options.ConID)
+	if response != nil {
+		upgrade.ProjectID = response.ProjectID
+	}
+	if projErr != nil {
+		upgrade.Error = projErr.Desc
+		if projErr.Op == errOpConflict {
+			upgrade.Status = UpgradeSkipped
+		}
+		return upgrade
+	}
+	upgrade.Status = UpgradeMigrated
+	os.Rename(infoFile, infoFile+migratedInfoSuffix)
+	return upgrade
+}
+
+// migrateSettings writes the settings of a project's metadata to a new .cw-settings, returning the settings written.
+// A project with a .cw-settings keeps it, and a Microclimate .mc-settings is renamed instead.
+func migrateSettings(projectPath string, info map[string]interface{}, buildType string, dryRun bool) ([]string, error) {
+	settingsFile := filepath.Join(projectPath, ".cw-settings")
+	if _, err := os.Stat(settingsFile); err == nil {
+		return nil, nil
+	}
+	legacyFile := filepath.Join(projectPath, ".mc-settings")
+	if _, err := os.Stat(legacyFile); err == nil {
+		if dryRun {
+			return []string{".mc-settings"}, nil
+		}
+		return []string{".mc-settings"}, os.Rename(legacyFile, settingsFile)
+	}
+
+	settings := map[string]interface{}{}
+	defaults, _ := json.Marshal(addNonDefaultFieldsToCwSettings(CWSettings{IgnoredPaths: []string{}}, buildType))
+	json.Unmarshal(defaults, &settings)
+	// Ports were kept together in the metadata, settings now name them
+	if ports, ok := info["ports"].(map[string]interface{}); ok {
+		for _, name := range []string{"internalPort", "internalDebugPort"} {
+			if _, found := info[name]; !found && ports[name] != nil {
+				info[name] = ports[name]
+			}
+		}
+	}
+	migrated := []string{}
+	for _, name := range settingNames() {
+		value, ok := info[name]
+		if !ok || value == nil {
+			continue
+		}
+		if number, isNumber := value.(float64); isNumber {
+			value = strconv.FormatFloat(number, 'f', -1, 64)
+		}
+		settings[name] = value
+		migrated = append(migrated, name)
+	}
+	if dryRun {
+		return migrated, nil
+	}
+	contents, _ := json.MarshalIndent(settings, "", "  ")
+	return migrated, ioutil.WriteFile(settingsFile, contents, 0644)
+}
@@ -12,7 +12,12 @@
 package project
 
 import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
 	"path"
+	"path/filepath"
 	"runtime"
 	"testing"
 
@@ -110,3 +115,111 @@ func Test_UpgradeProjects(t *testing.T) {
 	}
 
 }
+
+// writeLegacyWorkspace writes a Codewind 0.x workspace, with the metadata of each project in .projects
+func writeLegacyWorkspace(t *testing.T) string {
+	workspace, err := ioutil.TempDir("", "codewind-workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		".projects/node.inf":          `{"name": "node", "language": "nodejs", "projectType": "nodejs", "contextRoot": "/api", "isHttps": true, "ports": {"internalPort": 3000}}`,
+		".projects/micro.inf":         `{"name": "micro", "language": "java", "projectType": "liberty"}`,
+		".projects/taken.inf":         `{"name": "taken", "language": "go", "projectType": "docker"}`,
+		".projects/missing.inf":       `{"name": "missing", "language": "go", "projectType": "docker"}`,
+		".projects/done.inf.migrated": `{"name": "done", "language": "go", "projectType": "docker"}`,
+		"node/package.json":           "{}",
+		"micro/.mc-settings":          `{"contextRoot": "/micro"}`,
+		"taken/Dockerfile":            "FROM scratch",
+	}
+	for name, content := range files {
+		file := filepath.Join(workspace, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(file), 0755)
+		if err := ioutil.WriteFile(file, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return workspace
+}
+
+func TestUpgradeWorkspace(t *testing.T) {
+	workspace := writeLegacyWorkspace(t)
+	defer os.RemoveAll(workspace)
+	defer func() { bindProject = Bind }()
+	bound := []string{}
+	bindProject = func(projectPath string, name string, language string, projectType string, conID string) (*BindResponse, *ProjectError) {
+		bound = append(bound, name+"@"+conID)
+		if name == "taken" {
+			err := errors.New(textDupName)
+			return nil, &ProjectError{errOpConflict, err, textDupName}
+		}
+		return &BindResponse{ProjectID: name + "-id"}, nil
+	}
+
+	t.Run("dry run changes nothing", func(t *testing.T) {
+		result, projErr := UpgradeWorkspace(UpgradeWorkspaceOptions{Workspace: workspace, ConID: "local", DryRun: true})
+		assert.Nil(t, projErr)
+		assert.Equal(t, LayoutProjectInfo, result.Layout)
+		assert.Empty(t, bound)
+		assert.Equal(t, 1, result.Failed)
+		for _, upgrade := range result.Projects {
+			if upgrade.Name != "missing" {
+				assert.Equal(t, UpgradePending, upgrade.Status, upgrade.Name)
+			}
+		}
+		_, err := os.Stat(filepath.Join(workspace, "node", ".cw-settings"))
+		assert.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("projects are migrated and bound again", func(t *testing.T) {
+		result, projErr := UpgradeWorkspace(UpgradeWorkspaceOptions{Workspace: workspace, ConID: "local"})
+		assert.Nil(t, projErr)
+		assert.Equal(t, "error", result.Status)
+		assert.Equal(t, []string{"micro@local", "node@local", "taken@local"}, bound)
+		assert.Equal(t, 2, result.Migrated)
+		assert.Equal(t, 1, result.Skipped)
+		assert.Equal(t, 1, result.Failed)
+
+		statuses := map[string]string{}
+		for _, upgrade := range result.Projects {
+			statuses[upgrade.Name] = upgrade.Status
+		}
+		assert.Equal(t, map[string]string{"micro": UpgradeMigrated, "missing": UpgradeFailed, "node": UpgradeMigrated, "taken": UpgradeSkipped}, statuses)
+		assert.Equal(t, []string{".mc-settings"}, result.Projects[0].Settings)
+		assert.Equal(t, []string{"contextRoot", "internalPort", "isHttps"}, result.Projects[2].Settings)
+
+		contents, err := ioutil.ReadFile(filepath.Join(workspace, "node", ".cw-settings"))
+		assert.Nil(t, err)
+		settings := map[string]interface{}{}
+		json.Unmarshal(contents, &settings)
+		assert.Equal(t, "/api", settings["contextRoot"])
+		assert.Equal(t, "3000", settings["internalPort"])
+		assert.Equal(t, true, settings["isHttps"])
+		assert.Contains(t, settings, "internalDebugPort")
+		_, err = os.Stat(filepath.Join(workspace, "micro", ".cw-settings"))
+		assert.Nil(t, err)
+	})
+
+	t.Run("migrated projects are not migrated again", func(t *testing.T) {
+		bound = []string{}
+		result, projErr := UpgradeWorkspace(UpgradeWorkspaceOptions{Workspace: workspace, ConID: "local"})
+		assert.Nil(t, projErr)
+		assert.Equal(t, []string{"taken@local"}, bound)
+		assert.Len(t, result.Projects, 2)
+	})
+}
+
+func TestDetectWorkspaceLayout(t *testing.T) {
+	layout, infoFiles, projErr := DetectWorkspaceLayout("../../resources/workspaces/empty")
+	assert.Nil(t, projErr)
+	assert.Equal(t, LayoutCurrent, layout)
+	assert.Empty(t, infoFiles)
+
+	layout, infoFiles, projErr = DetectWorkspaceLayout("../../resources/workspaces/valid-projects")
+	assert.Nil(t, projErr)
+	assert.Equal(t, LayoutProjectInfo, layout)
+	assert.Len(t, infoFiles, 1)
+
+	_, _, projErr = DetectWorkspaceLayout("../../resources/workspaces/does-not-exist")
+	assert.NotNil(t, projErr)
+}
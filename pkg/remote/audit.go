@@ -0,0 +1,185 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"bufio"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type (
+	// AuditOptions : The deployment whose gatekeeper access logs are read, and the window to read
+	AuditOptions struct {
+		Namespace   string
+		WorkspaceID string
+		Since       time.Time
+		// Until ends the window, which ends now when it is not set
+		Until time.Time
+		// User only reports the requests of one user, when set
+		User string
+	}
+
+	// AuditEntry : A request the gatekeeper let through or refused
+	AuditEntry struct {
+		Time   time.Time `json:"time"`
+		User   string    `json:"user,omitempty"`
+		Client string    `json:"client"`
+		Method string    `json:"method"`
+		Path   string    `json:"path"`
+		Status int       `json:"status"`
+		Pod    string    `json:"pod"`
+	}
+
+	// AuditUser : The requests of one user over the window. Requests without a user are reported as anonymous.
+	AuditUser struct {
+		User      string    `json:"user"`
+		Requests  int       `json:"requests"`
+		Denied    int       `json:"denied"`
+		FirstSeen time.Time `json:"firstSeen"`
+		LastSeen  time.Time `json:"lastSeen"`
+	}
+
+	// AuditReport : The requests made to a deployment over a window, oldest first, and who made them
+	AuditReport struct {
+		WorkspaceID string       `json:"workspaceID"`
+		Since       time.Time    `json:"since"`
+		Until       time.Time    `json:"until"`
+		Users       []AuditUser  `json:"users"`
+		Entries     []AuditEntry `json:"entries,omitempty"`
+	}
+)
+
+// AnonymousUser names the requests made without logging in
+const AnonymousUser = "anonymous"
+
+// accessLogLine matches the common and combined log formats written by the gatekeeper for each request, such as
+// ::ffff:10.1.1.1 - developer [10/Oct/2020:13:55:36 +0000] "GET /api/v1/projects HTTP/1.1" 200 2326
+var accessLogLine = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "(\S+) (\S+)[^"]*" (\d{3}) `)
+
+// accessLogTime is the layout of the time of an access log line
+const accessLogTime = "02/Jan/2006:15:04:05 -0700"
+
+// AuditGatekeeper : Reads the access logs of the gatekeeper pods of a deployment, reporting the requests made during
+// the window and the users that made them. Requests are only kept for as long as Kubernetes keeps the logs of the
+// pods, so restarted pods lose them.
+func AuditGatekeeper(options *AuditOptions, clientset kubernetes.Interface) (*AuditReport, *RemInstError) {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	pods, remInstErr := client.findComponentPods(options.Namespace, options.WorkspaceID, "gatekeeper")
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	report := AuditReport{WorkspaceID: options.WorkspaceID, Since: options.Since, Until: options.Until, Entries: []AuditEntry{}}
+	if report.Until.IsZero() {
+		report.Until = time.Now()
+	}
+	podLogOptions := corev1.PodLogOptions{}
+	if !options.Since.IsZero() {
+		sinceSeconds := int64(time.Since(options.Since).Seconds()) + 1
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+	for _, pod := range pods {
+		stream, err := client.clientset.CoreV1().Pods(options.Namespace).GetLogs(pod.GetName(), &podLogOptions).Stream()
+		if err != nil {
+			return nil, &RemInstError{errOpLogs, err, err.Error()}
+		}
+		entries, err := readAccessLog(stream, pod.GetName(), options, report.Until)
+		stream.Close()
+		if err != nil {
+			return nil, &RemInstError{errOpLogs, err, err.Error()}
+		}
+		report.Entries = append(report.Entries, entries...)
+	}
+	sort.SliceStable(report.Entries, func(i, j int) bool { return report.Entries[i].Time.Before(report.Entries[j].Time) })
+	report.Users = auditUsers(report.Entries)
+	return &report, nil
+}
+
+// readAccessLog returns the requests of a pod's log made during the window, by the user of the options if one is
+// given. Lines that are not requests, such as start up messages, are skipped.
+func readAccessLog(log io.Reader, pod string, options *AuditOptions, until time.Time) ([]AuditEntry, error) {
+	entries := []AuditEntry{}
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		entry, ok := parseAccessLogLine(scanner.Text())
+		if !ok || entry.Time.Before(options.Since) || entry.Time.After(until) {
+			continue
+		}
+		if options.User != "" && entry.User != options.User {
+			continue
+		}
+		entry.Pod = pod
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// parseAccessLogLine reads a request from a line of an access log
+func parseAccessLogLine(line string) (AuditEntry, bool) {
+	match := accessLogLine.FindStringSubmatch(line)
+	if match == nil {
+		return AuditEntry{}, false
+	}
+	requestTime, err := time.Parse(accessLogTime, match[3])
+	if err != nil {
+		return AuditEntry{}, false
+	}
+	status, _ := strconv.Atoi(match[6])
+	entry := AuditEntry{Time: requestTime, Client: match[1], Method: match[4], Path: match[5], Status: status}
+	if match[2] != "-" {
+		entry.User = match[2]
+	}
+	return entry, true
+}
+
+// auditUsers summarises the requests of each user, ordered by name
+func auditUsers(entries []AuditEntry) []AuditUser {
+	users := map[string]*AuditUser{}
+	for _, entry := range entries {
+		name := entry.User
+		if name == "" {
+			name = AnonymousUser
+		}
+		user, ok := users[name]
+		if !ok {
+			user = &AuditUser{User: name, FirstSeen: entry.Time}
+			users[name] = user
+		}
+		user.Requests++
+		if entry.Status == 401 || entry.Status == 403 {
+			user.Denied++
+		}
+		if entry.Time.Before(user.FirstSeen) {
+			user.FirstSeen = entry.Time
+		}
+		if entry.Time.After(user.LastSeen) {
+			user.LastSeen = entry.Time
+		}
+	}
+	summary := []AuditUser{}
+	for _, user := range users {
+		summary = append(summary, *user)
+	}
+	sort.Slice(summary, func(i, j int) bool { return summary[i].User < summary[j].User })
+	return summary
+}
@@ -0,0 +1,87 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const gatekeeperLog = `Codewind Gatekeeper listening on port 9096
+::ffff:10.1.1.1 - developer [10/Oct/2020:13:55:36 +0000] "GET /api/v1/projects HTTP/1.1" 200 2326 "-" "cwctl"
+::ffff:10.1.1.2 - - [10/Oct/2020:14:00:00 +0000] "GET /api/v1/environment HTTP/1.1" 401 12
+::ffff:10.1.1.1 - developer [10/Oct/2020:15:30:00 +0000] "POST /api/v1/projects/abc/build HTTP/1.1" 202 0 "-" "cwctl"
+::ffff:10.1.1.3 - admin [11/Oct/2020:09:00:00 +0000] "DELETE /api/v1/projects/abc HTTP/1.1" 200 0
+`
+
+func TestParseAccessLogLine(t *testing.T) {
+	entry, ok := parseAccessLogLine(`::ffff:10.1.1.1 - developer [10/Oct/2020:13:55:36 +0100] "GET /api/v1/projects?x=1 HTTP/1.1" 200 2326 "-" "cwctl"`)
+	assert.True(t, ok)
+	assert.Equal(t, AuditEntry{
+		Time:   time.Date(2020, time.October, 10, 12, 55, 36, 0, time.UTC),
+		User:   "developer",
+		Client: "::ffff:10.1.1.1",
+		Method: "GET",
+		Path:   "/api/v1/projects?x=1",
+		Status: 200,
+	}, AuditEntry{entry.Time.UTC(), entry.User, entry.Client, entry.Method, entry.Path, entry.Status, entry.Pod})
+
+	_, ok = parseAccessLogLine("Codewind Gatekeeper listening on port 9096")
+	assert.False(t, ok)
+	_, ok = parseAccessLogLine(`10.1.1.1 - - [yesterday] "GET / HTTP/1.1" 200 0`)
+	assert.False(t, ok)
+}
+
+func TestReadAccessLog(t *testing.T) {
+	since := time.Date(2020, time.October, 10, 13, 0, 0, 0, time.UTC)
+	until := time.Date(2020, time.October, 10, 23, 0, 0, 0, time.UTC)
+
+	t.Run("success case - keeps the requests in the window", func(t *testing.T) {
+		entries, err := readAccessLog(strings.NewReader(gatekeeperLog), "gatekeeper-1", &AuditOptions{Since: since}, until)
+		assert.Nil(t, err)
+		if assert.Len(t, entries, 3) {
+			assert.Equal(t, "gatekeeper-1", entries[0].Pod)
+			assert.Equal(t, "", entries[1].User)
+			assert.Equal(t, "/api/v1/projects/abc/build", entries[2].Path)
+		}
+	})
+
+	t.Run("success case - keeps the requests of a user", func(t *testing.T) {
+		entries, err := readAccessLog(strings.NewReader(gatekeeperLog), "gatekeeper-1", &AuditOptions{User: "developer"}, until)
+		assert.Nil(t, err)
+		assert.Len(t, entries, 2)
+	})
+}
+
+func TestAuditUsers(t *testing.T) {
+	entries, _ := readAccessLog(strings.NewReader(gatekeeperLog), "gatekeeper-1", &AuditOptions{}, time.Now())
+	users := auditUsers(entries)
+	assert.Equal(t, []string{"admin", AnonymousUser, "developer"}, []string{users[0].User, users[1].User, users[2].User})
+	assert.Equal(t, 1, users[1].Denied)
+	assert.Equal(t, 2, users[2].Requests)
+	assert.True(t, users[2].FirstSeen.Before(users[2].LastSeen))
+}
+
+func TestAuditGatekeeper(t *testing.T) {
+	gatekeeperLabels := map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID1"}
+	pod := generateMockPod("gatekeeper", gatekeeperLabels, time.Now())
+	clientset := fake.NewSimpleClientset(&corev1.PodList{Items: []corev1.Pod{pod}})
+
+	// The fake clientset of this client-go cannot stream logs, so only finding the gatekeeper is tested here
+	_, err := AuditGatekeeper(&AuditOptions{Namespace: "test1", WorkspaceID: "WID2"}, clientset)
+	assert.Equal(t, errOpNotFound, err.Op)
+}
@@ -0,0 +1,124 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"strconv"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// hostnameTopologyKey is the node label replicas are spread across, so each replica prefers a different node
+const hostnameTopologyKey = "kubernetes.io/hostname"
+
+// InstalledReplicasAnnotation records the replicas a deployment was installed with, so that resuming a paused
+// workspace or turning maintenance off scales it back to them
+const InstalledReplicasAnnotation = "codewind.eclipse.org/installed-replicas"
+
+// setReplicas sets the replicas of a deployment, recording them in InstalledReplicasAnnotation. Deployments with more
+// than one replica prefer to schedule each replica on a different node, in addition to any affinity given at install,
+// so that a single node drain or failure leaves the other replicas running. Zero keeps the single replica of a
+// default install.
+func setReplicas(deployment *appsv1.Deployment, replicas int32) {
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	installed := replicas
+	if installed < 1 {
+		installed = 1
+	}
+	annotations[InstalledReplicasAnnotation] = strconv.Itoa(int(installed))
+	deployment.SetAnnotations(annotations)
+	if replicas < 2 {
+		return
+	}
+	deployment.Spec.Replicas = &replicas
+
+	affinity := &corev1.Affinity{}
+	if deployment.Spec.Template.Spec.Affinity != nil {
+		// The install affinity is shared by every deployment, so change a copy
+		affinity = deployment.Spec.Template.Spec.Affinity.DeepCopy()
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.PodAntiAffinity = &corev1.PodAntiAffinity{}
+	}
+	affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution = append(
+		affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution,
+		corev1.WeightedPodAffinityTerm{
+			Weight: 100,
+			PodAffinityTerm: corev1.PodAffinityTerm{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: deployment.Spec.Selector.MatchLabels},
+				TopologyKey:   hostnameTopologyKey,
+			},
+		},
+	)
+	deployment.Spec.Template.Spec.Affinity = affinity
+}
+
+// installedReplicas returns the replicas a deployment was installed with, which is one for the components and
+// installs without a recorded count
+func installedReplicas(deployment *appsv1.Deployment) int32 {
+	installed, err := strconv.ParseInt(deployment.GetAnnotations()[InstalledReplicasAnnotation], 10, 32)
+	if err != nil || installed < 1 {
+		return 1
+	}
+	return int32(installed)
+}
+
+// deployPodDisruptionBudget : Limit voluntary disruptions, such as node drains, to one replica of a component at a
+// time. Components with a single replica have no budget, as it would block node drains altogether.
+func deployPodDisruptionBudget(clientset kubernetes.Interface, codewind Codewind, prefix string, replicas int32) error {
+	if replicas < 2 {
+		return nil
+	}
+	budget := generatePodDisruptionBudget(codewind, prefix)
+	logr.Infof("Deploying Codewind pod disruption budget '%v'\n", budget.GetName())
+	_, err := clientset.PolicyV1beta1().PodDisruptionBudgets(codewind.Namespace).Create(&budget)
+	if err != nil {
+		logr.Errorf("Error: Unable to create pod disruption budget '%v': %v\n", budget.GetName(), err)
+		return err
+	}
+	return nil
+}
+
+// generatePodDisruptionBudget returns a budget allowing one pod of a workspace component to be unavailable
+func generatePodDisruptionBudget(codewind Codewind, prefix string) policyv1beta1.PodDisruptionBudget {
+	labels := map[string]string{
+		"app":               prefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	maxUnavailable := intstr.FromInt(1)
+	return policyv1beta1.PodDisruptionBudget{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "PodDisruptionBudget",
+			APIVersion: "policy/v1beta1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prefix + "-" + codewind.WorkspaceID,
+			Namespace: codewind.Namespace,
+			Labels:    ownedLabels(codewind, labels),
+		},
+		Spec: policyv1beta1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetReplicas(t *testing.T) {
+	t.Run("success case - a single replica is left unchanged", func(t *testing.T) {
+		deployment := generateGatekeeperDeploy(MockCodewind, &DeployOptions{})
+		assert.Equal(t, int32(1), *deployment.Spec.Replicas)
+		assert.Nil(t, deployment.Spec.Template.Spec.Affinity)
+		assert.Equal(t, "1", deployment.GetAnnotations()[InstalledReplicasAnnotation])
+	})
+
+	t.Run("success case - replicas prefer different nodes", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.PerformanceReplicas = 3
+		deployment := generatePerformanceDeploy(codewind)
+		assert.Equal(t, int32(3), *deployment.Spec.Replicas)
+		assert.Equal(t, "3", deployment.GetAnnotations()[InstalledReplicasAnnotation])
+		terms := deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution
+		assert.Len(t, terms, 1)
+		assert.Equal(t, hostnameTopologyKey, terms[0].PodAffinityTerm.TopologyKey)
+		assert.Equal(t, map[string]string{"app": PerformancePrefix, "codewindWorkspace": MockCodewind.WorkspaceID}, terms[0].PodAffinityTerm.LabelSelector.MatchLabels)
+	})
+
+	t.Run("success case - the install affinity is kept and not changed", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.GatekeeperReplicas = 2
+		codewind.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{{Weight: 1}},
+		}}
+		deployment := generateGatekeeperDeploy(codewind, &DeployOptions{})
+		assert.Len(t, deployment.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 2)
+		assert.Len(t, codewind.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution, 1)
+	})
+}
+
+func TestGatekeeperIngressAffinity(t *testing.T) {
+	ingress := generateIngressGatekeeper(MockCodewind)
+	assert.NotContains(t, ingress.GetAnnotations(), "nginx.ingress.kubernetes.io/affinity")
+
+	codewind := MockCodewind
+	codewind.GatekeeperReplicas = 2
+	ingress = generateIngressGatekeeper(codewind)
+	assert.Equal(t, "cookie", ingress.GetAnnotations()["nginx.ingress.kubernetes.io/affinity"])
+}
+
+func TestDeployPodDisruptionBudget(t *testing.T) {
+	t.Run("success case - no budget for a single replica", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		err := deployPodDisruptionBudget(clientset, MockCodewind, GatekeeperPrefix, 1)
+		assert.Nil(t, err)
+		budgets, _ := clientset.PolicyV1beta1().PodDisruptionBudgets(MockCodewind.Namespace).List(metav1.ListOptions{})
+		assert.Empty(t, budgets.Items)
+	})
+
+	t.Run("success case - one replica may be unavailable at a time", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		err := deployPodDisruptionBudget(clientset, MockCodewind, GatekeeperPrefix, 2)
+		assert.Nil(t, err)
+		budgets, _ := clientset.PolicyV1beta1().PodDisruptionBudgets(MockCodewind.Namespace).List(metav1.ListOptions{})
+		assert.Len(t, budgets.Items, 1)
+		budget := budgets.Items[0]
+		assert.Equal(t, GatekeeperPrefix+"-"+MockCodewind.WorkspaceID, budget.GetName())
+		assert.Equal(t, 1, budget.Spec.MaxUnavailable.IntValue())
+		assert.Equal(t, map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": MockCodewind.WorkspaceID}, budget.Spec.Selector.MatchLabels)
+	})
+}
@@ -0,0 +1,315 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// BackupOptions : Workspace backup and restore options
+type BackupOptions struct {
+	Namespace   string
+	WorkspaceID string
+	File        string
+}
+
+const (
+	// backupHelperPrefix is the prefix of the pod used to read and write the workspace PVC
+	backupHelperPrefix = "codewind-backup"
+
+	// backupMountPath is where the workspace PVC is mounted in the helper pod
+	backupMountPath = "/workspace"
+
+	// backupPodWaitAttempts is the number of pod watches to wait for the helper pod to start
+	backupPodWaitAttempts = 5
+
+	// backupHelperUser is the user the helper pod runs as when PFE runs as non-root without naming its user, as the
+	// helper image would otherwise run as root
+	backupHelperUser = int64(1001)
+)
+
+// backupHelperCommand keeps the helper pod running until it is deleted, exiting as soon as it is asked to stop
+var backupHelperCommand = []string{"sh", "-c", "trap 'exit 0' TERM; while true; do sleep 1; done"}
+
+// BackupWorkspace streams a gzipped tarball of the contents of the workspace PVC to a local file
+func BackupWorkspace(backupOptions *BackupOptions) *RemInstError {
+	file, err := os.Create(backupOptions.File)
+	if err != nil {
+		return &RemInstError{errOpBackup, err, err.Error()}
+	}
+	defer file.Close()
+
+	command := []string{"tar", "czf", "-", "-C", backupMountPath, "."}
+	remInstErr := runBackupHelper(backupOptions, command, nil, file)
+	if remInstErr != nil {
+		file.Close()
+		os.Remove(backupOptions.File)
+		return remInstErr
+	}
+	logr.Infof("Workspace %v backed up to %v\n", backupOptions.WorkspaceID, backupOptions.File)
+	return nil
+}
+
+// RestoreWorkspace unpacks a tarball created by BackupWorkspace into the workspace PVC, which may belong to a
+// different install, namespace or cluster from the one that was backed up
+func RestoreWorkspace(backupOptions *BackupOptions) *RemInstError {
+	file, err := os.Open(backupOptions.File)
+	if err != nil {
+		return &RemInstError{errOpBackup, err, err.Error()}
+	}
+	defer file.Close()
+
+	command := []string{"tar", "xzf", "-", "-C", backupMountPath}
+	remInstErr := runBackupHelper(backupOptions, command, file, nil)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	logr.Infof("Workspace %v restored from %v\n", backupOptions.WorkspaceID, backupOptions.File)
+	return nil
+}
+
+// runBackupHelper starts a helper pod with the workspace PVC mounted, runs command in it, then removes the pod
+func runBackupHelper(backupOptions *BackupOptions, command []string, stdin io.Reader, stdout io.Writer) *RemInstError {
+	config, err := GetKubeConfig()
+	if err != nil {
+		logr.Infof("Unable to retrieve Kubernetes Config %v\n", err)
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	client := K8sAPI{clientset: clientset}
+
+	pvcName, remInstErr := client.findWorkspacePVC(backupOptions.Namespace, backupOptions.WorkspaceID)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	pfe := client.findWorkspacePFE(backupOptions.Namespace, backupOptions.WorkspaceID)
+	warnIfNotInMaintenance(pfe)
+
+	codewindInstance := Codewind{Namespace: backupOptions.Namespace, WorkspaceID: backupOptions.WorkspaceID}
+	security := PodSecurity{}
+	if pfe != nil {
+		codewindInstance.OwnerLabels = installOwnerLabels(pfe.GetLabels())
+		security = backupPodSecurity(pfe.Spec.Template.Spec.SecurityContext)
+	}
+	pod := generateBackupPod(codewindInstance, pvcName, security)
+
+	logr.Infof("Starting helper pod '%v'\n", pod.GetName())
+	err = createPod(clientset, &pod, security)
+	if err != nil {
+		return &RemInstError{errOpBackup, err, err.Error()}
+	}
+	// The helper pod runs until it is deleted, so delete it once the copy has finished or failed
+	defer func() {
+		logr.Infof("Removing helper pod '%v'\n", pod.GetName())
+		err := clientset.CoreV1().Pods(backupOptions.Namespace).Delete(pod.GetName(), nil)
+		if err != nil {
+			logr.Warnf("Unable to remove helper pod '%v', use 'kubectl delete pod %v -n %v' to remove it: %v\n", pod.GetName(), pod.GetName(), backupOptions.Namespace, err)
+		}
+	}()
+
+	podSearch := "codewindWorkspace=" + backupOptions.WorkspaceID + ",app=" + backupHelperPrefix
+	ready := false
+	for attempt := 0; !ready && attempt < backupPodWaitAttempts; attempt++ {
+		ready = WaitForPodReady(clientset, codewindInstance, podSearch, pod.GetName())
+	}
+	if !ready {
+		err = errors.New(errBackupPodNotReady)
+		return &RemInstError{errOpBackup, err, err.Error() + ": " + pod.GetName()}
+	}
+
+	err = execInPod(config, clientset, pod, command, stdin, stdout)
+	if err != nil {
+		return &RemInstError{errOpBackup, err, err.Error()}
+	}
+	return nil
+}
+
+// findWorkspacePVC returns the name of the codewind workspace PVC for the given workspace
+func (client K8sAPI) findWorkspacePVC(namespace string, workspaceID string) (string, *RemInstError) {
+	labelSelector := "app=" + PFEPrefix + ",codewindWorkspace=" + workspaceID
+	pvcList, err := client.clientset.CoreV1().PersistentVolumeClaims(namespace).List(metav1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return "", &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if len(pvcList.Items) == 0 {
+		err = errors.New(errTargetNotFound)
+		return "", &RemInstError{errOpNotFound, err, err.Error() + ": " + labelSelector}
+	}
+	return pvcList.Items[0].GetName(), nil
+}
+
+// findWorkspacePFE returns the PFE deployment of a workspace, or nil if it has none
+func (client K8sAPI) findWorkspacePFE(namespace string, workspaceID string) *appsv1.Deployment {
+	deployments, err := client.clientset.AppsV1().Deployments(namespace).List(metav1.ListOptions{
+		LabelSelector: "app=" + PFEPrefix + ",codewindWorkspace=" + workspaceID,
+	})
+	if err != nil || len(deployments.Items) == 0 {
+		return nil
+	}
+	return &deployments.Items[0]
+}
+
+// warnIfNotInMaintenance logs a warning if PFE may be writing to the workspace while it is copied
+func warnIfNotInMaintenance(pfe *appsv1.Deployment) {
+	if pfe != nil && pfe.GetAnnotations()[MaintenanceAnnotation] != "true" {
+		logr.Warnf("Codewind PFE '%v' is running, use 'cwctl remote maintenance on' first for a consistent copy\n", pfe.GetName())
+	}
+}
+
+// installOwnerLabels returns the ownership labels of the install that created a resource, so that the helper pod is
+// removed with the install if it is left behind
+func installOwnerLabels(labels map[string]string) map[string]string {
+	owner := map[string]string{}
+	for _, label := range []string{InstallIDLabel, CwctlVersionLabel, InstalledAtLabel} {
+		if value, ok := labels[label]; ok {
+			owner[label] = value
+		}
+	}
+	return owner
+}
+
+// backupPodSecurity returns the security settings of the helper pod, which runs as the user and group PFE runs as so
+// that it can read and write the files PFE keeps in the workspace. When PFE runs as non-root, as it does with the
+// restricted pod security profile, the helper pod meets the restricted profile too.
+func backupPodSecurity(pfeContext *corev1.PodSecurityContext) PodSecurity {
+	if pfeContext == nil {
+		return PodSecurity{}
+	}
+	security := PodSecurity{RunAsUser: pfeContext.RunAsUser, FSGroup: pfeContext.FSGroup}
+	if pfeContext.RunAsNonRoot == nil || !*pfeContext.RunAsNonRoot {
+		return security
+	}
+	if security.RunAsUser == nil {
+		user := backupHelperUser
+		security.RunAsUser = &user
+	}
+	return resolvePodSecurity(PodSecurityRestricted, security)
+}
+
+// createPod creates a pod with the seccomp profile of its security settings, posting it as JSON in the same way as
+// createDeployment
+func createPod(clientset kubernetes.Interface, pod *corev1.Pod, security PodSecurity) error {
+	if security.SeccompProfile == "" {
+		_, err := clientset.CoreV1().Pods(pod.GetNamespace()).Create(pod)
+		return err
+	}
+	body, err := withSeccompProfile(pod, security.SeccompProfile, "spec")
+	if err != nil {
+		return err
+	}
+	return clientset.CoreV1().RESTClient().Post().
+		Namespace(pod.GetNamespace()).
+		Resource("pods").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do().
+		Error()
+}
+
+// generateBackupPod returns a pod which mounts the workspace PVC and stays idle until it is deleted, so that commands
+// can be run against it. It is labelled as part of the install, and has the security settings given.
+func generateBackupPod(codewind Codewind, pvcName string, security PodSecurity) corev1.Pod {
+	labels := map[string]string{
+		"app":               backupHelperPrefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	pod := corev1.Pod{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Pod",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      backupHelperPrefix + "-" + codewind.WorkspaceID,
+			Namespace: codewind.Namespace,
+			Labels:    ownedLabels(codewind, labels),
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Volumes: []corev1.Volume{
+				{
+					Name: "shared-workspace",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvcName,
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    backupHelperPrefix,
+					Image:   BackupHelperImage,
+					Command: backupHelperCommand,
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "shared-workspace",
+							MountPath: backupMountPath,
+						},
+					},
+				},
+			},
+		},
+	}
+	setPodSpecSecurity(&pod.Spec, security)
+	return pod
+}
+
+// execInPod runs a command in the first container of a pod, connecting the given streams to it
+func execInPod(config *restclient.Config, clientset *kubernetes.Clientset, pod corev1.Pod, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod.GetName()).
+		Namespace(pod.GetNamespace()).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: pod.Spec.Containers[0].Name,
+			Command:   command,
+			Stdin:     stdin != nil,
+			Stdout:    stdout != nil,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	if err != nil {
+		return err
+	}
+
+	var stderr bytes.Buffer
+	err = executor.Stream(remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: &stderr,
+	})
+	if err != nil && stderr.Len() > 0 {
+		return errors.New(err.Error() + ": " + strings.TrimSpace(stderr.String()))
+	}
+	return err
+}
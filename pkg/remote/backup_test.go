@@ -0,0 +1,104 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFindWorkspacePVC(t *testing.T) {
+	pvc := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "codewind-pfe-pvc-WID1",
+			Namespace: "test1",
+			Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": "WID1"},
+		},
+	}
+	client := K8sAPI{clientset: fake.NewSimpleClientset(&corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{pvc}})}
+
+	t.Run("success case - returns the workspace PVC", func(t *testing.T) {
+		pvcName, err := client.findWorkspacePVC("test1", "WID1")
+		assert.Nil(t, err)
+		assert.Equal(t, "codewind-pfe-pvc-WID1", pvcName)
+	})
+
+	t.Run("fail case - workspace has no PVC", func(t *testing.T) {
+		pvcName, err := client.findWorkspacePVC("test1", "WID2")
+		assert.Empty(t, pvcName)
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
+
+func TestGenerateBackupPod(t *testing.T) {
+	t.Run("success case - helper pod mounts the workspace PVC", func(t *testing.T) {
+		pod := generateBackupPod(MockCodewind, MockCodewind.PVCName, PodSecurity{})
+		assert.Equal(t, backupHelperPrefix+"-"+MockCodewind.WorkspaceID, pod.GetName())
+		assert.Equal(t, MockCodewind.Namespace, pod.GetNamespace())
+		assert.Equal(t, MockCodewind.WorkspaceID, pod.GetLabels()["codewindWorkspace"])
+		assert.Equal(t, MockCodewind.PVCName, pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+		assert.Equal(t, BackupHelperImage, pod.Spec.Containers[0].Image)
+		assert.Equal(t, backupMountPath, pod.Spec.Containers[0].VolumeMounts[0].MountPath)
+		assert.Equal(t, backupHelperCommand, pod.Spec.Containers[0].Command)
+		assert.Nil(t, pod.Spec.SecurityContext)
+	})
+
+	t.Run("success case - helper pod is labelled as part of the install and has its security settings", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.OwnerLabels = map[string]string{InstallIDLabel: "install1"}
+		user := int64(1001)
+		pod := generateBackupPod(codewind, codewind.PVCName, resolvePodSecurity(PodSecurityRestricted, PodSecurity{RunAsUser: &user}))
+		assert.Equal(t, "install1", pod.GetLabels()[InstallIDLabel])
+		assert.Equal(t, backupHelperPrefix, pod.GetLabels()["app"])
+		assert.True(t, *pod.Spec.SecurityContext.RunAsNonRoot)
+		assert.Equal(t, user, *pod.Spec.SecurityContext.RunAsUser)
+		assert.False(t, *pod.Spec.Containers[0].SecurityContext.AllowPrivilegeEscalation)
+		assert.Equal(t, []corev1.Capability{"ALL"}, pod.Spec.Containers[0].SecurityContext.Capabilities.Drop)
+	})
+}
+
+func TestBackupPodSecurity(t *testing.T) {
+	user, group, nonRoot, root := int64(1002), int64(1003), true, false
+
+	t.Run("success case - PFE without a security context", func(t *testing.T) {
+		assert.Equal(t, PodSecurity{}, backupPodSecurity(nil))
+	})
+
+	t.Run("success case - PFE running as root keeps its user and group", func(t *testing.T) {
+		security := backupPodSecurity(&corev1.PodSecurityContext{RunAsNonRoot: &root, FSGroup: &group})
+		assert.Equal(t, PodSecurity{FSGroup: &group}, security)
+	})
+
+	t.Run("success case - PFE running as non-root gives the restricted profile with its user and group", func(t *testing.T) {
+		security := backupPodSecurity(&corev1.PodSecurityContext{RunAsNonRoot: &nonRoot, RunAsUser: &user, FSGroup: &group})
+		assert.Equal(t, SeccompRuntimeDefault, security.SeccompProfile)
+		assert.Equal(t, user, *security.RunAsUser)
+		assert.Equal(t, group, *security.FSGroup)
+		assert.Equal(t, []string{"ALL"}, security.DropCapabilities)
+	})
+
+	t.Run("success case - PFE running as non-root without a user runs the helper as the default user", func(t *testing.T) {
+		security := backupPodSecurity(&corev1.PodSecurityContext{RunAsNonRoot: &nonRoot})
+		assert.Equal(t, backupHelperUser, *security.RunAsUser)
+		assert.True(t, *security.RunAsNonRoot)
+	})
+}
+
+func TestInstallOwnerLabels(t *testing.T) {
+	labels := map[string]string{"app": PFEPrefix, InstallIDLabel: "install1", CwctlVersionLabel: "0.14.0"}
+	assert.Equal(t, map[string]string{InstallIDLabel: "install1", CwctlVersionLabel: "0.14.0"}, installOwnerLabels(labels))
+	assert.Empty(t, installOwnerLabels(map[string]string{"app": PFEPrefix}))
+}
@@ -0,0 +1,122 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	logr "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+const (
+	// CertManagerAPIVersion is the cert-manager API used for Certificate resources
+	CertManagerAPIVersion = "cert-manager.io/v1alpha2"
+
+	// CertIssuerKindIssuer is a namespaced cert-manager issuer
+	CertIssuerKindIssuer = "Issuer"
+
+	// CertIssuerKindClusterIssuer is a cluster wide cert-manager issuer
+	CertIssuerKindClusterIssuer = "ClusterIssuer"
+)
+
+// certificateResource identifies cert-manager Certificates for the dynamic client
+var certificateResource = schema.GroupVersionResource{Group: "cert-manager.io", Version: "v1alpha2", Resource: "certificates"}
+
+// generateCertManagerCertificate returns a cert-manager Certificate which stores a certificate for dnsNames in secretName,
+// signed by the issuer given in the deploy options. The first name is the common name. cert-manager renews the
+// certificate before it expires.
+func generateCertManagerCertificate(codewind Codewind, deployOptions *DeployOptions, name string, secretName string, dnsNames []string, labels map[string]string) unstructured.Unstructured {
+	issuerKind := deployOptions.CertIssuerKind
+	if issuerKind == "" {
+		issuerKind = CertIssuerKindIssuer
+	}
+
+	certificateNames := []interface{}{}
+	for _, dnsName := range dnsNames {
+		certificateNames = append(certificateNames, dnsName)
+	}
+
+	objectLabels := map[string]interface{}{}
+	for key, value := range ownedLabels(codewind, labels) {
+		objectLabels[key] = value
+	}
+
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": CertManagerAPIVersion,
+			"kind":       "Certificate",
+			"metadata": map[string]interface{}{
+				"name":      name + "-" + codewind.WorkspaceID,
+				"namespace": codewind.Namespace,
+				"labels":    objectLabels,
+			},
+			"spec": map[string]interface{}{
+				"secretName": secretName + "-" + codewind.WorkspaceID,
+				"commonName": dnsNames[0],
+				"dnsNames":   certificateNames,
+				"issuerRef": map[string]interface{}{
+					"name": deployOptions.CertIssuer,
+					"kind": issuerKind,
+				},
+			},
+		},
+	}
+}
+
+// createCertManagerCertificate creates the given cert-manager Certificate in the cluster
+func createCertManagerCertificate(config *restclient.Config, certificate unstructured.Unstructured) error {
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	_, err = dynamicClient.Resource(certificateResource).Namespace(certificate.GetNamespace()).Create(&certificate, metav1.CreateOptions{})
+	return err
+}
+
+// deleteCertManagerCertificates removes the cert-manager Certificates matching the label selector, along with the
+// secrets cert-manager created for them, since cert-manager does not label the secrets it manages
+func deleteCertManagerCertificates(config *restclient.Config, remoteRemovalOptions *RemoveDeploymentOptions, clientset *kubernetes.Clientset, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return phase, err
+	}
+	certificates := dynamicClient.Resource(certificateResource).Namespace(remoteRemovalOptions.Namespace)
+	certificateList, err := certificates.List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		// cert-manager is not installed, so there is nothing to remove
+		return phase, err
+	}
+	if certificateList != nil && len(certificateList.Items) > 0 {
+		phase = ResourceFound
+		for _, certificate := range certificateList.Items {
+			secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+			err := certificates.Delete(certificate.GetName(), nil)
+			if err != nil {
+				phase = ResourceRemoveFailed
+				continue
+			}
+			phase = ResourceRemoved
+			if secretName != "" {
+				err = clientset.CoreV1().Secrets(remoteRemovalOptions.Namespace).Delete(secretName, nil)
+				if err != nil {
+					logr.Tracef("Unable to remove certificate secret '%v': %v", secretName, err)
+				}
+			}
+		}
+	}
+	return phase, nil
+}
@@ -0,0 +1,51 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestGenerateCertManagerCertificate(t *testing.T) {
+	t.Run("success case - gatekeeper certificate uses the requested issuer", func(t *testing.T) {
+		deployOptions := DeployOptions{CertIssuer: "letsencrypt", CertIssuerKind: CertIssuerKindClusterIssuer}
+		certificate := generateGatekeeperCertificate(MockCodewind, &deployOptions)
+
+		assert.Equal(t, CertManagerAPIVersion, certificate.GetAPIVersion())
+		assert.Equal(t, "Certificate", certificate.GetKind())
+		assert.Equal(t, "certificate-codewind-tls-"+MockCodewind.WorkspaceID, certificate.GetName())
+		assert.Equal(t, MockCodewind.Namespace, certificate.GetNamespace())
+		assert.Equal(t, GatekeeperPrefix, certificate.GetLabels()["app"])
+
+		secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		assert.Equal(t, "secret-codewind-tls-"+MockCodewind.WorkspaceID, secretName)
+		dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+		assert.Equal(t, []string{MockCodewind.GatekeeperHost}, dnsNames)
+		issuerName, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "name")
+		assert.Equal(t, "letsencrypt", issuerName)
+		issuerKind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+		assert.Equal(t, CertIssuerKindClusterIssuer, issuerKind)
+	})
+
+	t.Run("success case - keycloak certificate defaults to a namespaced issuer", func(t *testing.T) {
+		deployOptions := DeployOptions{CertIssuer: "ca-issuer"}
+		certificate := generateKeycloakCertificate(MockCodewind, &deployOptions)
+
+		secretName, _, _ := unstructured.NestedString(certificate.Object, "spec", "secretName")
+		assert.Equal(t, "secret-keycloak-tls-"+MockCodewind.WorkspaceID, secretName)
+		issuerKind, _, _ := unstructured.NestedString(certificate.Object, "spec", "issuerRef", "kind")
+		assert.Equal(t, CertIssuerKindIssuer, issuerKind)
+	})
+}
@@ -30,11 +30,12 @@ func SetupKeycloak(codewindInstance Codewind, deployOptions *DeployOptions) erro
 		useExistingKeycloak = true
 	}
 
-	// Access role to be created and added to user account
+	// Access role to be created and added to user account, and a role granting read-only access
 	accessRoleName := "codewind-" + codewindInstance.WorkspaceID
+	readOnlyRoleName := accessRoleName + "-readonly"
 
 	// Construct keycloak authentication URL or use the supplied flag
-	authURL := KeycloakPrefix + codewindInstance.Ingress
+	authURL := codewindInstance.KeycloakHost
 	if deployOptions.KeycloakTLSSecure {
 		authURL = "https://" + authURL
 	} else {
@@ -49,7 +50,7 @@ func SetupKeycloak(codewindInstance Codewind, deployOptions *DeployOptions) erro
 	if deployOptions.GateKeeperTLSSecure {
 		gateKeeperProtocol = "https://"
 	}
-	gatekeeperPublicURL := gateKeeperProtocol + GatekeeperPrefix + codewindInstance.Ingress
+	gatekeeperPublicURL := gateKeeperProtocol + codewindInstance.GatekeeperHost
 
 	// Wait for the Keycloak service to respond
 	logr.Infoln("Waiting for Keycloak to start")
@@ -97,6 +98,21 @@ func SetupKeycloak(codewindInstance Codewind, deployOptions *DeployOptions) erro
 		utils.PrettyPrintJSON(secErr)
 		return secErr.Err
 	}
+	secErr = configureKeycloakAccessRole(deployOptions, authURL, tokens, readOnlyRoleName)
+	if secErr != nil {
+		utils.PrettyPrintJSON(secErr)
+		return secErr.Err
+	}
+	secErr = configureKeycloakAccessGroup(deployOptions, authURL, tokens, accessRoleName+"-admins", accessRoleName)
+	if secErr != nil {
+		utils.PrettyPrintJSON(secErr)
+		return secErr.Err
+	}
+	secErr = configureKeycloakAccessGroup(deployOptions, authURL, tokens, accessRoleName+"-viewers", readOnlyRoleName)
+	if secErr != nil {
+		utils.PrettyPrintJSON(secErr)
+		return secErr.Err
+	}
 
 	secErr = configureKeycloakUser(deployOptions, authURL, tokens)
 	if secErr != nil {
@@ -191,6 +207,23 @@ func configureKeycloakAccessRole(deployOptions *DeployOptions, authURL string, t
 	return nil
 }
 
+// configureKeycloakAccessGroup creates a group whose members are granted an access role for this deployment
+func configureKeycloakAccessGroup(deployOptions *DeployOptions, authURL string, tokens *security.AuthToken, groupName string, accessRoleName string) *security.SecError {
+	logr.Infof("Creating access group '%v' in realm '%v'", groupName, deployOptions.KeycloakRealm)
+	groupFlagset := flag.NewFlagSet("setupGroup", 0)
+	groupFlagset.String("host", authURL, "doc")
+	groupFlagset.String("realm", deployOptions.KeycloakRealm, "doc")
+	groupFlagset.String("group", groupName, "doc")
+	groupFlagset.String("role", accessRoleName, "doc")
+	groupFlagset.String("accesstoken", tokens.AccessToken, "doc")
+	c := cli.NewContext(nil, groupFlagset, nil)
+	secErr := security.SecGroupCreate(http.DefaultClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	return security.SecGroupAddRole(http.DefaultClient, c)
+}
+
 func configureKeycloakUser(deployOptions *DeployOptions, authURL string, tokens *security.AuthToken) *security.SecError {
 
 	// Check if user is already registered
@@ -29,6 +29,9 @@ const (
 	// GatekeeperPrefix is the prefix for all gatekeeper related resources: deployment and service
 	GatekeeperPrefix = "codewind-gatekeeper"
 
+	// LoadTestPrefix is the prefix for the CronJobs which start scheduled load runs
+	LoadTestPrefix = "codewind-loadtest"
+
 	// PFEImage is the docker image that will be used in the Codewind-PFE pod
 	PFEImage = "eclipse/codewind-pfe-amd64"
 
@@ -53,6 +56,15 @@ const (
 	// GatekeeperImageTag is the image tag associated with the docker image that's used for Codewind-Gatekeeper
 	GatekeeperImageTag = "latest"
 
+	// BackupHelperImage is the docker image used by the pod which copies the workspace PVC contents
+	BackupHelperImage = "busybox:1.31"
+
+	// LoadTestImage is the docker image used by the CronJobs which start scheduled load runs
+	LoadTestImage = "curlimages/curl:7.72.0"
+
+	// LoadTestUser is the user the load test image runs as, which it names rather than numbers
+	LoadTestUser = int64(100)
+
 	// ImagePullPolicy is the pull policy used for all containers in Codewind, defaults to Always
 	ImagePullPolicy = corev1.PullAlways
 
@@ -12,16 +12,17 @@
 package remote
 
 import (
+	"context"
 	"errors"
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/remote/kube"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/uuid"
@@ -33,6 +34,7 @@ import (
 type DeployOptions struct {
 	Namespace             string
 	IngressDomain         string
+	WildcardDomain        string
 	KeycloakUser          string
 	KeycloakPassword      string
 	KeycloakDevUser       string
@@ -48,22 +50,98 @@ type DeployOptions struct {
 	CodewindSessionSecret string
 	ClientSecret          string
 	CodewindPVCSize       string
+	StorageClass          string
+	KeycloakStorageClass  string
+	KeycloakPVCSize       string
 	LogLevel              string
+	CertIssuer            string
+	CertIssuerKind        string
+	Wait                  bool
+	IngressClass          string
+	IngressAnnotations    map[string]string
+	GatekeeperHost        string
+	NetworkPolicies       bool
+	Metrics               bool
+	Timeout               time.Duration
+	PFEResources          corev1.ResourceRequirements
+	PerformanceResources  corev1.ResourceRequirements
+	GatekeeperResources   corev1.ResourceRequirements
+	KeycloakResources     corev1.ResourceRequirements
+	NodeSelector          map[string]string
+	Tolerations           []corev1.Toleration
+	Affinity              *corev1.Affinity
+	PerformanceReplicas   int32
+	GatekeeperReplicas    int32
+	PodSecurityProfile    string
+	PFESecurity           PodSecurity
+	PerformanceSecurity   PodSecurity
+	GatekeeperSecurity    PodSecurity
+	KeycloakSecurity      PodSecurity
+	NamespaceLabels       map[string]string
+	NamespaceAnnotations  map[string]string
+
+	// Existing secrets, such as those created by External Secrets or Sealed Secrets, holding the Keycloak admin
+	// credentials and the TLS certificates, used instead of generating them
+	KeycloakAdminSecret string
+	GatekeeperTLSSecret string
+	KeycloakTLSSecret   string
+
+	// Container images, each overriding the default or environment variable image when set
+	PFEImage         string
+	PerformanceImage string
+	GatekeeperImage  string
+	KeycloakImage    string
+
+	// ImageTag pins the default images to one version, and AllowMixedVersions permits components of different versions
+	ImageTag           string
+	AllowMixedVersions bool
+
+	// SkipPreflight starts creating resources without first checking the cluster can run the install
+	SkipPreflight bool
+
+	// Events receives the progress of the install, when set
+	Events Events
+}
+
+// GetKeycloakStorageClass returns the storage class for the Keycloak PVC, which defaults to the workspace storage class
+func (deployOptions *DeployOptions) GetKeycloakStorageClass() string {
+	if deployOptions.KeycloakStorageClass != "" {
+		return deployOptions.KeycloakStorageClass
+	}
+	return deployOptions.StorageClass
 }
 
 // DeploymentResult : Ingress root URLs
 type DeploymentResult struct {
 	GatekeeperURL string
 	KeycloakURL   string
+	InstallID     string
 }
 
 // DeployRemote : InstallRemote
 func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemInstError) {
+	return DeployRemoteContext(context.Background(), remoteDeployOptions)
+}
+
+// DeployRemoteContext : InstallRemote, stopping between steps and while waiting for components once the context is
+// done. The resources created before the install stopped keep its install ID, so they can be removed by it.
+func DeployRemoteContext(ctx context.Context, remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemInstError) {
+	err := ValidateExistingSecrets(remoteDeployOptions)
+	if err != nil {
+		return nil, &RemInstError{errOpExistingSecret, err, err.Error()}
+	}
+	err = ValidateIngressHosts(remoteDeployOptions)
+	if err != nil {
+		return nil, &RemInstError{errOpIngressHost, err, err.Error()}
+	}
+
 	config, err := GetKubeConfig()
 	if err != nil {
 		logr.Infof("Unable to retrieve Kubernetes Config %v\n", err)
 		return nil, &RemInstError{errOpNotFound, err, err.Error()}
 	}
+	events := eventsOrNone(remoteDeployOptions.Events)
+	watchResources(config, events)
 
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -77,21 +155,34 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 		namespace = kube.GetCurrentNamespace()
 	}
 
+	ownerLabels := generateOwnerLabels(time.Now())
+	logr.Infof("Install ID: %v\n", ownerLabels[InstallIDLabel])
+	cancelled := func() *RemInstError {
+		return installCancelled(ctx, namespace, ownerLabels[InstallIDLabel])
+	}
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	// Determine if we're running on OpenShift or not.
+	onOpenShift := kube.DetectOpenShift(config)
+	logr.Infof("Running on openshift: %t\n", onOpenShift)
+
+	if !remoteDeployOptions.SkipPreflight {
+		events.OnPhaseChange("Running preflight checks", 0)
+		if remInstErr := runPreflight(clientset, remoteDeployOptions, namespace, onOpenShift).failure(); remInstErr != nil {
+			return nil, remInstErr
+		}
+	}
+
 	// Check if namespace exists
+	events.OnPhaseChange("Checking the namespace", 0)
 	logr.Infof("Checking namespace %v exists\n", namespace)
 	_, err = clientset.CoreV1().Namespaces().Get(namespace, v1.GetOptions{})
 	if err != nil {
 		logr.Infof("Creating %v namespace\n", namespace)
 		// create the namespace
-		deploymentNamespace := corev1.Namespace{
-			TypeMeta: metav1.TypeMeta{
-				Kind:       "Namespace",
-				APIVersion: "v1",
-			},
-			ObjectMeta: metav1.ObjectMeta{
-				Name: namespace,
-			},
-		}
+		deploymentNamespace := generateNamespace(namespace, ownerLabels, remoteDeployOptions.NamespaceLabels, remoteDeployOptions.NamespaceAnnotations)
 
 		// insert the namespace
 		requestedNamespace, err := clientset.CoreV1().Namespaces().Create(&deploymentNamespace)
@@ -99,10 +190,39 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 			logr.Errorf("Unable to create %v namespace: %v", namespace, err)
 			return nil, &RemInstError{errOpCreateNamespace, err, err.Error()}
 		}
+	} else if len(remoteDeployOptions.NamespaceLabels) > 0 || len(remoteDeployOptions.NamespaceAnnotations) > 0 {
+		logr.Warnf("Namespace %v already exists, its labels and annotations are left unchanged\n", namespace)
 	}
 
 	logr.Infof("Using namespace : %v\n", namespace)
-	pfeImage, performanceImage, keycloakImage, gatekeeperImage := GetImages()
+
+	remInstErr := resolveExistingSecrets(ctx, clientset, namespace, remoteDeployOptions)
+	if cancelledErr := cancelled(); cancelledErr != nil {
+		return nil, cancelledErr
+	}
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	// Check the PVCs being created can be provisioned
+	events.OnPhaseChange("Checking storage and images", 5)
+	storageClasses := []string{}
+	if remoteDeployOptions.KeycloakURL == "" {
+		storageClasses = append(storageClasses, remoteDeployOptions.GetKeycloakStorageClass())
+	}
+	if !remoteDeployOptions.KeycloakOnly {
+		storageClasses = append(storageClasses, remoteDeployOptions.StorageClass)
+	}
+	remInstErr = checkStorageClasses(clientset, storageClasses...)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	pfeImage, performanceImage, keycloakImage, gatekeeperImage := resolveImages(remoteDeployOptions)
+	remInstErr = checkImageVersions(remoteDeployOptions, pfeImage, performanceImage, keycloakImage, gatekeeperImage)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
 
 	logr.Infoln("Container images : ")
 	logr.Infoln(pfeImage)
@@ -110,20 +230,16 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 	logr.Infoln(keycloakImage)
 	logr.Infoln(gatekeeperImage)
 
-	// Determine if we're running on OpenShift or not.
-	onOpenShift := kube.DetectOpenShift(config)
-	logr.Infof("Running on openshift: %t\n", onOpenShift)
-
 	workspaceID := strings.ToLower(strconv.FormatInt(utils.CreateTimestamp(), 36))
 
 	// append workspaceID to the client name
 	remoteDeployOptions.KeycloakClient = remoteDeployOptions.KeycloakClient + "-" + workspaceID
 
 	// Get the ingress host
-	ingressDomain := remoteDeployOptions.IngressDomain
+	ingressDomain := resolveIngressDomain(remoteDeployOptions)
 
 	// Use a supplied ingress if one was not installed
-	if remoteDeployOptions.IngressDomain == "" && !onOpenShift {
+	if ingressDomain == "" && !onOpenShift {
 		logr.Infof("Attempting to discover Ingress Domain")
 		svcList := clientset.CoreV1().Services("ingress-nginx")
 		svc, err := svcList.List(v1.ListOptions{})
@@ -168,10 +284,51 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 		Ingress:            "-" + workspaceID + "." + ingressDomain,
 		RequestedIngress:   ingressDomain,
 		OnOpenShift:        onOpenShift,
+		GatekeeperHost:     GatekeeperPrefix + "-" + workspaceID + "." + ingressDomain,
+		KeycloakHost:       KeycloakPrefix + "-" + workspaceID + "." + ingressDomain,
+		WildcardDomain:     remoteDeployOptions.WildcardDomain,
+		IngressClass:       remoteDeployOptions.IngressClass,
+		IngressAnnotations: remoteDeployOptions.IngressAnnotations,
+		Metrics:            remoteDeployOptions.Metrics,
+
+		PFEResources:         remoteDeployOptions.PFEResources,
+		PerformanceResources: remoteDeployOptions.PerformanceResources,
+		GatekeeperResources:  remoteDeployOptions.GatekeeperResources,
+		KeycloakResources:    remoteDeployOptions.KeycloakResources,
+
+		NodeSelector: remoteDeployOptions.NodeSelector,
+		Tolerations:  remoteDeployOptions.Tolerations,
+		Affinity:     remoteDeployOptions.Affinity,
+
+		PerformanceReplicas: remoteDeployOptions.PerformanceReplicas,
+		GatekeeperReplicas:  remoteDeployOptions.GatekeeperReplicas,
+
+		PodSecurityProfile:  remoteDeployOptions.PodSecurityProfile,
+		PFESecurity:         remoteDeployOptions.PFESecurity,
+		PerformanceSecurity: remoteDeployOptions.PerformanceSecurity,
+		GatekeeperSecurity:  remoteDeployOptions.GatekeeperSecurity,
+		KeycloakSecurity:    remoteDeployOptions.KeycloakSecurity,
+
+		OwnerLabels: ownerLabels,
+
+		KeycloakAdminSecret: remoteDeployOptions.KeycloakAdminSecret,
+		GatekeeperTLSSecret: remoteDeployOptions.GatekeeperTLSSecret,
+		KeycloakTLSSecret:   remoteDeployOptions.KeycloakTLSSecret,
 	}
 
-	gatekeeperURL := GatekeeperPrefix + codewindInstance.Ingress
-	keycloakURL := KeycloakPrefix + codewindInstance.Ingress
+	if remoteDeployOptions.GatekeeperHost != "" {
+		codewindInstance.GatekeeperHost = remoteDeployOptions.GatekeeperHost
+	}
+	if remoteDeployOptions.KeycloakHost != "" {
+		codewindInstance.KeycloakHost = remoteDeployOptions.KeycloakHost
+	}
+
+	gatekeeperURL := codewindInstance.GatekeeperHost
+	keycloakURL := codewindInstance.KeycloakHost
+
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
 
 	// Create the Codewind service account
 	if !remoteDeployOptions.KeycloakOnly {
@@ -186,6 +343,7 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 
 	// If we are not using an existing Keycloak, deploy one now
 	if remoteDeployOptions.KeycloakURL == "" {
+		events.OnPhaseChange("Deploying Keycloak", 10)
 		keycloakServiceAccountTemplate := CreateKeycloakServiceAcct(codewindInstance, remoteDeployOptions)
 		_, err = clientset.CoreV1().ServiceAccounts(namespace).Create(&keycloakServiceAccountTemplate)
 		if err != nil {
@@ -198,13 +356,24 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 			logr.Errorln("Codewind Keycloak failed, exiting...")
 			os.Exit(1)
 		}
-		podSearch := "codewindWorkspace=" + codewindInstance.WorkspaceID + ",app=" + KeycloakPrefix
-		ready := false
-		for !ready {
-			ready = WaitForPodReady(clientset, codewindInstance, podSearch, KeycloakPrefix+"-"+codewindInstance.WorkspaceID)
+		if remoteDeployOptions.NetworkPolicies {
+			err = DeployKeycloakNetworkPolicy(clientset, codewindInstance)
+			if err != nil {
+				logr.Errorln("Codewind Keycloak network policy deployment failed, exiting...")
+				os.Exit(1)
+			}
+		}
+		events.OnPhaseChange("Waiting for Keycloak", 20)
+		remInstErr = waitForComponent(ctx, clientset, codewindInstance, remoteDeployOptions, KeycloakPrefix)
+		if remInstErr != nil {
+			return nil, remInstErr
 		}
 	}
 
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
+	events.OnPhaseChange("Configuring Keycloak", 35)
 	err = SetupKeycloak(codewindInstance, remoteDeployOptions)
 	if err != nil {
 		logr.Errorln("Codewind Keycloak configuration failed, exiting...")
@@ -214,44 +383,78 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 	if remoteDeployOptions.KeycloakOnly {
 		deploymentResult := DeploymentResult{
 			KeycloakURL: keycloakURL,
+			InstallID:   ownerLabels[InstallIDLabel],
 		}
+		events.OnPhaseChange("Installed", 100)
 		return &deploymentResult, nil
 	}
 
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
+	events.OnPhaseChange("Deploying PFE", 40)
+	if remoteDeployOptions.NetworkPolicies {
+		err = DeployNetworkPolicies(clientset, codewindInstance)
+		if err != nil {
+			logr.Errorln("Codewind network policy deployment failed, exiting...")
+			os.Exit(1)
+		}
+	}
+
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
 	err = DeployPFE(config, clientset, codewindInstance, remoteDeployOptions)
 	if err != nil {
 		logr.Errorln("Codewind deployment failed, exiting...")
 		os.Exit(1)
 	}
 
-	podSearch := "codewindWorkspace=" + codewindInstance.WorkspaceID + ",app=" + PFEPrefix
-	ready := false
-	for !ready {
-		ready = WaitForPodReady(clientset, codewindInstance, podSearch, PFEPrefix+"-"+codewindInstance.WorkspaceID)
+	events.OnPhaseChange("Waiting for PFE", 50)
+	remInstErr = waitForComponent(ctx, clientset, codewindInstance, remoteDeployOptions, PFEPrefix)
+	if remInstErr != nil {
+		return nil, remInstErr
 	}
 
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
+	events.OnPhaseChange("Deploying Performance", 65)
 	err = DeployPerformance(clientset, codewindInstance, remoteDeployOptions)
 	if err != nil {
 		logr.Errorln("Codewind deployment failed, exiting...")
 		os.Exit(1)
 	}
 
-	podSearch = "codewindWorkspace=" + codewindInstance.WorkspaceID + ",app=" + PerformancePrefix
-	ready = false
-	for !ready {
-		ready = WaitForPodReady(clientset, codewindInstance, podSearch, PerformancePrefix+"-"+codewindInstance.WorkspaceID)
+	events.OnPhaseChange("Waiting for Performance", 70)
+	remInstErr = waitForComponent(ctx, clientset, codewindInstance, remoteDeployOptions, PerformancePrefix)
+	if remInstErr != nil {
+		return nil, remInstErr
 	}
 
+	if remInstErr := cancelled(); remInstErr != nil {
+		return nil, remInstErr
+	}
+	events.OnPhaseChange("Deploying Gatekeeper", 80)
 	err = DeployGatekeeper(config, clientset, codewindInstance, remoteDeployOptions)
 	if err != nil {
 		logr.Errorln("Codewind Gatekeeper deployment failed, exiting...")
 		os.Exit(1)
 	}
 
-	podSearch = "codewindWorkspace=" + codewindInstance.WorkspaceID + ",app=" + GatekeeperPrefix
-	ready = false
-	for !ready {
-		ready = WaitForPodReady(clientset, codewindInstance, podSearch, GatekeeperPrefix+"-"+codewindInstance.WorkspaceID)
+	events.OnPhaseChange("Waiting for Gatekeeper", 85)
+	remInstErr = waitForComponent(ctx, clientset, codewindInstance, remoteDeployOptions, GatekeeperPrefix)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	if remoteDeployOptions.Metrics {
+		events.OnPhaseChange("Deploying service monitors", 95)
+		err = DeployServiceMonitors(config, clientset.Discovery(), codewindInstance)
+		if err != nil {
+			logr.Errorln("Codewind ServiceMonitor deployment failed, exiting...")
+			os.Exit(1)
+		}
 	}
 
 	if remoteDeployOptions.GateKeeperTLSSecure {
@@ -269,7 +472,9 @@ func DeployRemote(remoteDeployOptions *DeployOptions) (*DeploymentResult, *RemIn
 	deploymentResult := DeploymentResult{
 		GatekeeperURL: gatekeeperURL,
 		KeycloakURL:   keycloakURL,
+		InstallID:     ownerLabels[InstallIDLabel],
 	}
 
+	events.OnPhaseChange("Installed", 100)
 	return &deploymentResult, nil
 }
@@ -0,0 +1,330 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"io/ioutil"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// DeployConfig : A remote install described in a YAML or JSON file, as an alternative to the install flags
+type DeployConfig struct {
+	Namespace            string                 `json:"namespace"`
+	NamespaceLabels      map[string]string      `json:"namespaceLabels,omitempty"`
+	NamespaceAnnotations map[string]string      `json:"namespaceAnnotations,omitempty"`
+	Session              string                 `json:"session,omitempty"`
+	Images               DeployConfigImages     `json:"images,omitempty"`
+	Resources            DeployConfigResources  `json:"resources,omitempty"`
+	Ingress              DeployConfigIngress    `json:"ingress,omitempty"`
+	Keycloak             DeployConfigKeycloak   `json:"keycloak,omitempty"`
+	Storage              DeployConfigStorage    `json:"storage,omitempty"`
+	Certificates         DeployConfigCerts      `json:"certificates,omitempty"`
+	Secrets              DeployConfigSecrets    `json:"secrets,omitempty"`
+	Scheduling           DeployConfigScheduling `json:"scheduling,omitempty"`
+	Replicas             DeployConfigReplicas   `json:"replicas,omitempty"`
+	PodSecurity          DeployConfigSecurity   `json:"podSecurity,omitempty"`
+	NetworkPolicies      bool                   `json:"networkPolicies,omitempty"`
+	Metrics              bool                   `json:"metrics,omitempty"`
+	Wait                 bool                   `json:"wait,omitempty"`
+	Timeout              string                 `json:"timeout,omitempty"`
+}
+
+// DeployConfigImages : Container images to deploy instead of the defaults
+type DeployConfigImages struct {
+	PFE         string `json:"pfe,omitempty"`
+	Performance string `json:"performance,omitempty"`
+	Gatekeeper  string `json:"gatekeeper,omitempty"`
+	Keycloak    string `json:"keycloak,omitempty"`
+
+	// Channel or Tag pin the default images to one version, and AllowMixedVersions permits components of different
+	// versions
+	Channel            string `json:"channel,omitempty"`
+	Tag                string `json:"tag,omitempty"`
+	AllowMixedVersions bool   `json:"allowMixedVersions,omitempty"`
+}
+
+// DeployConfigResources : Container resource requests and limits for each component
+type DeployConfigResources struct {
+	PFE         corev1.ResourceRequirements `json:"pfe,omitempty"`
+	Performance corev1.ResourceRequirements `json:"performance,omitempty"`
+	Gatekeeper  corev1.ResourceRequirements `json:"gatekeeper,omitempty"`
+	Keycloak    corev1.ResourceRequirements `json:"keycloak,omitempty"`
+}
+
+// DeployConfigIngress : How the Gatekeeper and Keycloak are exposed
+type DeployConfigIngress struct {
+	Domain         string            `json:"domain,omitempty"`
+	Class          string            `json:"class,omitempty"`
+	Annotations    map[string]string `json:"annotations,omitempty"`
+	GatekeeperHost string            `json:"gatekeeperHost,omitempty"`
+	KeycloakHost   string            `json:"keycloakHost,omitempty"`
+	WildcardDomain string            `json:"wildcardDomain,omitempty"`
+}
+
+// DeployConfigKeycloak : Authentication realm, client and users, or an existing Keycloak to use
+type DeployConfigKeycloak struct {
+	URL           string `json:"url,omitempty"`
+	Only          bool   `json:"only,omitempty"`
+	Realm         string `json:"realm,omitempty"`
+	Client        string `json:"client,omitempty"`
+	AdminUser     string `json:"adminUser,omitempty"`
+	AdminPassword string `json:"adminPassword,omitempty"`
+	DevUser       string `json:"devUser,omitempty"`
+	DevPassword   string `json:"devPassword,omitempty"`
+}
+
+// DeployConfigStorage : Workspace and Keycloak persistent volume settings
+type DeployConfigStorage struct {
+	Class           string `json:"class,omitempty"`
+	PVCSize         int    `json:"pvcSize,omitempty"`
+	KeycloakClass   string `json:"keycloakClass,omitempty"`
+	KeycloakPVCSize int    `json:"keycloakPVCSize,omitempty"`
+}
+
+// DeployConfigCerts : cert-manager issuer used instead of self-signed certificates
+type DeployConfigCerts struct {
+	Issuer     string `json:"issuer,omitempty"`
+	IssuerKind string `json:"issuerKind,omitempty"`
+}
+
+// DeployConfigSecrets : Existing secrets holding the Keycloak admin credentials and TLS certificates, used instead of
+// generating them
+type DeployConfigSecrets struct {
+	KeycloakAdmin string `json:"keycloakAdmin,omitempty"`
+	GatekeeperTLS string `json:"gatekeeperTLS,omitempty"`
+	KeycloakTLS   string `json:"keycloakTLS,omitempty"`
+}
+
+// DeployConfigScheduling : Scheduling constraints applied to every Codewind deployment
+type DeployConfigScheduling struct {
+	NodeSelector map[string]string   `json:"nodeSelector,omitempty"`
+	Tolerations  []corev1.Toleration `json:"tolerations,omitempty"`
+	Affinity     *corev1.Affinity    `json:"affinity,omitempty"`
+}
+
+// DeployConfigReplicas : Number of replicas of the components that can run more than one, for high availability
+type DeployConfigReplicas struct {
+	Performance int32 `json:"performance,omitempty"`
+	Gatekeeper  int32 `json:"gatekeeper,omitempty"`
+}
+
+// DeployConfigSecurity : Pod security profile, and the security context settings of each component overriding it
+type DeployConfigSecurity struct {
+	Profile     string      `json:"profile,omitempty"`
+	PFE         PodSecurity `json:"pfe,omitempty"`
+	Performance PodSecurity `json:"performance,omitempty"`
+	Gatekeeper  PodSecurity `json:"gatekeeper,omitempty"`
+	Keycloak    PodSecurity `json:"keycloak,omitempty"`
+}
+
+// LoadDeployConfig reads and validates a deployment config file. Unknown fields and values of the wrong type are
+// rejected so that mistakes in the file are reported rather than silently ignored.
+func LoadDeployConfig(filename string) (*DeployConfig, error) {
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.New(errBadDeployConfig + ": " + err.Error())
+	}
+	config := DeployConfig{}
+	err = yaml.UnmarshalStrict(contents, &config)
+	if err != nil {
+		return nil, errors.New(errBadDeployConfig + ": " + err.Error())
+	}
+	problems := config.Validate()
+	if len(problems) > 0 {
+		return nil, errors.New(errBadDeployConfig + ": " + strings.Join(problems, ", "))
+	}
+	return &config, nil
+}
+
+// Validate returns a description of each invalid setting in the config
+func (config DeployConfig) Validate() []string {
+	problems := []string{}
+	if config.Namespace == "" {
+		problems = append(problems, "namespace is required")
+	}
+	for key, value := range config.NamespaceLabels {
+		if _, err := ParseNamespaceLabels([]string{key + "=" + value}); err != nil {
+			problems = append(problems, "namespaceLabels."+key+" is not a valid label")
+		}
+	}
+	for key := range config.NamespaceAnnotations {
+		if _, err := ParseNamespaceAnnotations([]string{key + "="}); err != nil {
+			problems = append(problems, "namespaceAnnotations."+key+" is not a valid annotation key")
+		}
+	}
+	if config.Storage.PVCSize < 0 || config.Storage.PVCSize > 999 {
+		problems = append(problems, "storage.pvcSize should be between 1 and 999 GB")
+	}
+	if config.Storage.KeycloakPVCSize < 0 || config.Storage.KeycloakPVCSize > 999 {
+		problems = append(problems, "storage.keycloakPVCSize should be between 1 and 999 GB")
+	}
+	if config.Certificates.IssuerKind != "" && config.Certificates.IssuerKind != CertIssuerKindIssuer && config.Certificates.IssuerKind != CertIssuerKindClusterIssuer {
+		problems = append(problems, "certificates.issuerKind should be Issuer or ClusterIssuer")
+	}
+	hosts := []struct {
+		name string
+		host string
+	}{
+		{"gatekeeperHost", config.Ingress.GatekeeperHost},
+		{"keycloakHost", config.Ingress.KeycloakHost},
+		{"wildcardDomain", config.Ingress.WildcardDomain},
+	}
+	for _, host := range hosts {
+		if host.host != "" && len(validation.IsDNS1123Subdomain(host.host)) > 0 {
+			problems = append(problems, "ingress."+host.name+" should be a valid DNS name")
+		}
+	}
+	if config.Ingress.WildcardDomain != "" && config.Ingress.Domain != "" {
+		problems = append(problems, "ingress.wildcardDomain replaces ingress.domain, set only one of them")
+	}
+	secrets := []struct {
+		name   string
+		secret string
+	}{
+		{"keycloakAdmin", config.Secrets.KeycloakAdmin},
+		{"gatekeeperTLS", config.Secrets.GatekeeperTLS},
+		{"keycloakTLS", config.Secrets.KeycloakTLS},
+	}
+	for _, secret := range secrets {
+		if secret.secret != "" && len(validation.IsDNS1123Subdomain(secret.secret)) > 0 {
+			problems = append(problems, "secrets."+secret.name+" should be a valid secret name")
+		}
+	}
+	if config.Timeout != "" {
+		if _, err := time.ParseDuration(config.Timeout); err != nil {
+			problems = append(problems, "timeout should be a duration, for example 5m")
+		}
+	}
+	if _, err := utils.ResolveImageTag(config.Images.Channel, config.Images.Tag); err != nil {
+		problems = append(problems, "images: "+err.Error())
+	}
+	if config.Replicas.Performance < 0 {
+		problems = append(problems, "replicas.performance should be at least 1")
+	}
+	if config.Replicas.Gatekeeper < 0 {
+		problems = append(problems, "replicas.gatekeeper should be at least 1")
+	}
+	if config.PodSecurity.Profile != "" && config.PodSecurity.Profile != PodSecurityRestricted {
+		problems = append(problems, "podSecurity.profile should be restricted")
+	}
+	components := []struct {
+		name     string
+		security PodSecurity
+	}{
+		{"pfe", config.PodSecurity.PFE},
+		{"performance", config.PodSecurity.Performance},
+		{"gatekeeper", config.PodSecurity.Gatekeeper},
+		{"keycloak", config.PodSecurity.Keycloak},
+	}
+	for _, component := range components {
+		profile := component.security.SeccompProfile
+		if profile != "" && profile != SeccompRuntimeDefault && profile != SeccompUnconfined {
+			problems = append(problems, "podSecurity."+component.name+".seccompProfile should be RuntimeDefault or Unconfined")
+		}
+	}
+	for i, toleration := range config.Scheduling.Tolerations {
+		if toleration.Key == "" && toleration.Operator != corev1.TolerationOpExists {
+			problems = append(problems, "scheduling.tolerations["+strconv.Itoa(i)+"] needs a key unless its operator is Exists")
+		}
+	}
+	return problems
+}
+
+// DeployOptions converts the config into install options, applying the same defaults as the install flags
+func (config DeployConfig) DeployOptions() DeployOptions {
+	pvcSize := config.Storage.PVCSize
+	if pvcSize < 1 {
+		pvcSize = 1
+	}
+	keycloakPVCSize := config.Storage.KeycloakPVCSize
+	if keycloakPVCSize < 1 {
+		keycloakPVCSize = 1
+	}
+	ingressClass := config.Ingress.Class
+	if ingressClass == "" {
+		ingressClass = "nginx"
+	}
+	issuerKind := config.Certificates.IssuerKind
+	if issuerKind == "" {
+		issuerKind = CertIssuerKindIssuer
+	}
+	timeout := DefaultReadyTimeout
+	if config.Timeout != "" {
+		timeout, _ = time.ParseDuration(config.Timeout)
+	}
+	imageTag := ""
+	if config.Images.Channel != "" || config.Images.Tag != "" {
+		imageTag, _ = utils.ResolveImageTag(config.Images.Channel, config.Images.Tag)
+	}
+
+	return DeployOptions{
+		Namespace:             config.Namespace,
+		IngressDomain:         config.Ingress.Domain,
+		WildcardDomain:        config.Ingress.WildcardDomain,
+		KeycloakUser:          config.Keycloak.AdminUser,
+		KeycloakPassword:      config.Keycloak.AdminPassword,
+		KeycloakDevUser:       config.Keycloak.DevUser,
+		KeycloakDevPassword:   config.Keycloak.DevPassword,
+		KeycloakRealm:         config.Keycloak.Realm,
+		KeycloakClient:        config.Keycloak.Client,
+		KeycloakURL:           config.Keycloak.URL,
+		KeycloakOnly:          config.Keycloak.Only,
+		CodewindSessionSecret: config.Session,
+		CodewindPVCSize:       strconv.Itoa(pvcSize) + "Gi",
+		StorageClass:          config.Storage.Class,
+		KeycloakStorageClass:  config.Storage.KeycloakClass,
+		KeycloakPVCSize:       strconv.Itoa(keycloakPVCSize) + "Gi",
+		CertIssuer:            config.Certificates.Issuer,
+		CertIssuerKind:        issuerKind,
+		Wait:                  config.Wait,
+		Timeout:               timeout,
+		IngressClass:          ingressClass,
+		IngressAnnotations:    config.Ingress.Annotations,
+		GatekeeperHost:        config.Ingress.GatekeeperHost,
+		KeycloakHost:          config.Ingress.KeycloakHost,
+		NetworkPolicies:       config.NetworkPolicies,
+		Metrics:               config.Metrics,
+		PFEResources:          config.Resources.PFE,
+		PerformanceResources:  config.Resources.Performance,
+		GatekeeperResources:   config.Resources.Gatekeeper,
+		KeycloakResources:     config.Resources.Keycloak,
+		NodeSelector:          config.Scheduling.NodeSelector,
+		Tolerations:           config.Scheduling.Tolerations,
+		Affinity:              config.Scheduling.Affinity,
+		PerformanceReplicas:   config.Replicas.Performance,
+		GatekeeperReplicas:    config.Replicas.Gatekeeper,
+		PodSecurityProfile:    config.PodSecurity.Profile,
+		PFESecurity:           config.PodSecurity.PFE,
+		PerformanceSecurity:   config.PodSecurity.Performance,
+		GatekeeperSecurity:    config.PodSecurity.Gatekeeper,
+		KeycloakSecurity:      config.PodSecurity.Keycloak,
+		NamespaceLabels:       config.NamespaceLabels,
+		NamespaceAnnotations:  config.NamespaceAnnotations,
+		KeycloakAdminSecret:   config.Secrets.KeycloakAdmin,
+		GatekeeperTLSSecret:   config.Secrets.GatekeeperTLS,
+		KeycloakTLSSecret:     config.Secrets.KeycloakTLS,
+		PFEImage:              config.Images.PFE,
+		PerformanceImage:      config.Images.Performance,
+		GatekeeperImage:       config.Images.Gatekeeper,
+		KeycloakImage:         config.Images.Keycloak,
+		ImageTag:              imageTag,
+		AllowMixedVersions:    config.Images.AllowMixedVersions,
+	}
+}
@@ -0,0 +1,160 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const testDeployConfig = `namespace: codewind
+namespaceLabels:
+  pod-security.kubernetes.io/enforce: restricted
+images:
+  pfe: registry.example.com/codewind-pfe:0.14
+resources:
+  pfe:
+    requests:
+      cpu: 500m
+    limits:
+      memory: 4Gi
+ingress:
+  domain: 10.0.0.1.nip.io
+  annotations:
+    nginx.ingress.kubernetes.io/proxy-body-size: 100m
+keycloak:
+  realm: codewind
+  client: codewind
+storage:
+  class: nfs
+  pvcSize: 10
+secrets:
+  keycloakAdmin: keycloak-admin
+  gatekeeperTLS: codewind-tls
+scheduling:
+  nodeSelector:
+    pool: devtools
+  tolerations:
+  - key: dedicated
+    operator: Equal
+    value: devtools
+    effect: NoSchedule
+replicas:
+  gatekeeper: 2
+podSecurity:
+  profile: restricted
+  pfe:
+    fsGroup: 1001
+wait: true
+timeout: 5m
+`
+
+func writeDeployConfig(t *testing.T, dir string, name string, contents string) string {
+	filename := filepath.Join(dir, name)
+	err := ioutil.WriteFile(filename, []byte(contents), 0644)
+	assert.Nil(t, err)
+	return filename
+}
+
+func TestLoadDeployConfig(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "deployconfig")
+	defer os.RemoveAll(dir)
+
+	t.Run("success case - YAML config drives the install options", func(t *testing.T) {
+		config, err := LoadDeployConfig(writeDeployConfig(t, dir, "codewind-deploy.yaml", testDeployConfig))
+		assert.Nil(t, err)
+
+		options := config.DeployOptions()
+		assert.Equal(t, "codewind", options.Namespace)
+		assert.Equal(t, map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}, options.NamespaceLabels)
+		assert.Equal(t, "registry.example.com/codewind-pfe:0.14", options.PFEImage)
+		assert.Equal(t, "", options.PerformanceImage)
+		assert.Equal(t, resource.MustParse("500m"), options.PFEResources.Requests[corev1.ResourceCPU])
+		assert.Equal(t, "10.0.0.1.nip.io", options.IngressDomain)
+		assert.Equal(t, "nginx", options.IngressClass)
+		assert.Equal(t, "100m", options.IngressAnnotations["nginx.ingress.kubernetes.io/proxy-body-size"])
+		assert.Equal(t, "codewind", options.KeycloakRealm)
+		assert.Equal(t, "nfs", options.StorageClass)
+		assert.Equal(t, "10Gi", options.CodewindPVCSize)
+		assert.Equal(t, CertIssuerKindIssuer, options.CertIssuerKind)
+		assert.Equal(t, "keycloak-admin", options.KeycloakAdminSecret)
+		assert.Equal(t, "codewind-tls", options.GatekeeperTLSSecret)
+		assert.Equal(t, "", options.KeycloakTLSSecret)
+		assert.Equal(t, map[string]string{"pool": "devtools"}, options.NodeSelector)
+		assert.Equal(t, corev1.TaintEffectNoSchedule, options.Tolerations[0].Effect)
+		assert.Equal(t, int32(2), options.GatekeeperReplicas)
+		assert.Equal(t, int32(0), options.PerformanceReplicas)
+		assert.Equal(t, PodSecurityRestricted, options.PodSecurityProfile)
+		assert.Equal(t, int64(1001), *options.PFESecurity.FSGroup)
+		assert.True(t, options.Wait)
+		assert.Equal(t, 5*time.Minute, options.Timeout)
+	})
+
+	t.Run("success case - JSON config with defaults", func(t *testing.T) {
+		config, err := LoadDeployConfig(writeDeployConfig(t, dir, "codewind-deploy.json", `{"namespace": "codewind"}`))
+		assert.Nil(t, err)
+
+		options := config.DeployOptions()
+		assert.Equal(t, "1Gi", options.CodewindPVCSize)
+		assert.Equal(t, DefaultReadyTimeout, options.Timeout)
+	})
+
+	t.Run("success case - images tag pins the version", func(t *testing.T) {
+		config, err := LoadDeployConfig(writeDeployConfig(t, dir, "tag.yaml", "namespace: codewind\nimages:\n  tag: 0.9.0\n  allowMixedVersions: true\n"))
+		assert.Nil(t, err)
+
+		options := config.DeployOptions()
+		assert.Equal(t, "0.9.0", options.ImageTag)
+		assert.True(t, options.AllowMixedVersions)
+	})
+
+	t.Run("error case - images channel and tag", func(t *testing.T) {
+		_, err := LoadDeployConfig(writeDeployConfig(t, dir, "channel.yaml", "namespace: codewind\nimages:\n  channel: latest\n  tag: 0.9.0\n"))
+		assert.Contains(t, err.Error(), "images:")
+	})
+
+	t.Run("error case - unknown field", func(t *testing.T) {
+		_, err := LoadDeployConfig(writeDeployConfig(t, dir, "unknown.yaml", "namespace: codewind\nstorageClass: nfs\n"))
+		assert.Contains(t, err.Error(), errBadDeployConfig)
+		assert.Contains(t, err.Error(), "storageClass")
+	})
+
+	t.Run("error case - wrong type", func(t *testing.T) {
+		_, err := LoadDeployConfig(writeDeployConfig(t, dir, "type.yaml", "namespace: codewind\nstorage:\n  pvcSize: big\n"))
+		assert.Contains(t, err.Error(), errBadDeployConfig)
+	})
+
+	t.Run("error case - every invalid setting is reported", func(t *testing.T) {
+		_, err := LoadDeployConfig(writeDeployConfig(t, dir, "invalid.yaml", "storage:\n  pvcSize: 1000\ncertificates:\n  issuerKind: Other\nreplicas:\n  gatekeeper: -1\nnamespaceLabels:\n  team: dev tools\npodSecurity:\n  profile: baseline\n  gatekeeper:\n    seccompProfile: Strict\nsecrets:\n  keycloakTLS: Keycloak_TLS\ntimeout: soon\n"))
+		assert.Contains(t, err.Error(), "namespace is required")
+		assert.Contains(t, err.Error(), "storage.pvcSize")
+		assert.Contains(t, err.Error(), "certificates.issuerKind")
+		assert.Contains(t, err.Error(), "replicas.gatekeeper")
+		assert.Contains(t, err.Error(), "podSecurity.profile")
+		assert.Contains(t, err.Error(), "namespaceLabels.team")
+		assert.Contains(t, err.Error(), "podSecurity.gatekeeper.seccompProfile")
+		assert.Contains(t, err.Error(), "secrets.keycloakTLS")
+		assert.Contains(t, err.Error(), "timeout")
+	})
+
+	t.Run("error case - missing file", func(t *testing.T) {
+		_, err := LoadDeployConfig(filepath.Join(dir, "missing.yaml"))
+		assert.Contains(t, err.Error(), errBadDeployConfig)
+	})
+}
@@ -21,6 +21,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	extensionsv1 "k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -36,9 +37,6 @@ func DeployGatekeeper(config *restclient.Config, clientset *kubernetes.Clientset
 	gatekeeperDeploy := generateGatekeeperDeploy(codewindInstance, deployOptions)
 	gatekeeperSessionSecret := generateGatekeeperSessionSecret(codewindInstance, deployOptions)
 
-	serverKey, serverCert, _ := generateCertificate(GatekeeperPrefix+codewindInstance.Ingress, "Codewind Gatekeeper "+codewindInstance.WorkspaceID)
-	gatekeeperTLSSecret := generateGatekeeperTLSSecret(codewindInstance, serverKey, serverCert)
-
 	logr.Infoln("Deploying Codewind Gatekeeper Secrets")
 
 	_, err := clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&gatekeeperSecrets)
@@ -54,20 +52,40 @@ func DeployGatekeeper(config *restclient.Config, clientset *kubernetes.Clientset
 		return err
 	}
 
-	logr.Infoln("Deploying Codewind Gatekeeper TLS Secrets")
-	_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&gatekeeperTLSSecret)
-	if err != nil {
-		logr.Errorf("Error: Unable to create Codewind Gatekeeper TLS secrets: %v\n", err)
-		return err
+	if codewindInstance.GatekeeperTLSSecret != "" {
+		logr.Infof("Using existing Codewind Gatekeeper TLS secret '%v'\n", codewindInstance.GatekeeperTLSSecret)
+	} else if deployOptions.CertIssuer != "" {
+		logr.Infoln("Deploying Codewind Gatekeeper Certificate")
+		gatekeeperCertificate := generateGatekeeperCertificate(codewindInstance, deployOptions)
+		err = createCertManagerCertificate(config, gatekeeperCertificate)
+		if err != nil {
+			logr.Errorf("Error: Unable to create Codewind Gatekeeper certificate: %v\n", err)
+			return err
+		}
+	} else {
+		serverKey, serverCert, _ := generateCertificate(codewindInstance.certificateDNSNames(codewindInstance.GatekeeperHost), "Codewind Gatekeeper "+codewindInstance.WorkspaceID)
+		gatekeeperTLSSecret := generateGatekeeperTLSSecret(codewindInstance, serverKey, serverCert)
+
+		logr.Infoln("Deploying Codewind Gatekeeper TLS Secrets")
+		_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&gatekeeperTLSSecret)
+		if err != nil {
+			logr.Errorf("Error: Unable to create Codewind Gatekeeper TLS secrets: %v\n", err)
+			return err
+		}
 	}
 
 	logr.Infoln("Deploying Codewind Gatekeeper Deployment")
-	_, err = clientset.AppsV1().Deployments(deployOptions.Namespace).Create(&gatekeeperDeploy)
+	err = createDeployment(clientset, &gatekeeperDeploy, codewindInstance.podSecurity(GatekeeperPrefix))
 	if err != nil {
 		logr.Errorf("Error: Unable to create Codewind Gatekeeper deployment: %v\n", err)
 		return err
 	}
 
+	err = deployPodDisruptionBudget(clientset, codewindInstance, GatekeeperPrefix, codewindInstance.GatekeeperReplicas)
+	if err != nil {
+		return err
+	}
+
 	logr.Infoln("Deploying Codewind Gatekeeper Service")
 	_, err = clientset.CoreV1().Services(deployOptions.Namespace).Create(&gatekeeperService)
 	if err != nil {
@@ -111,10 +129,19 @@ func generateGatekeeperTLSSecret(codewind Codewind, pemPrivateKey string, pemPub
 		"tls.crt": pemPublicCert,
 		"tls.key": pemPrivateKey,
 	}
-	name := "secret-codewind-tls"
+	name := gatekeeperTLSName
 	return generateSecrets(codewind, name, secrets, labels)
 }
 
+// generateGatekeeperCertificate returns a cert-manager Certificate which provisions the Gatekeeper TLS secret
+func generateGatekeeperCertificate(codewind Codewind, deployOptions *DeployOptions) unstructured.Unstructured {
+	labels := map[string]string{
+		"app":               GatekeeperPrefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	return generateCertManagerCertificate(codewind, deployOptions, "certificate-codewind-tls", gatekeeperTLSName, codewind.certificateDNSNames(codewind.GatekeeperHost), labels)
+}
+
 func generateGatekeeperSessionSecret(codewind Codewind, deployOptions *DeployOptions) corev1.Secret {
 	labels := map[string]string{
 		"app":               GatekeeperPrefix,
@@ -123,7 +150,7 @@ func generateGatekeeperSessionSecret(codewind Codewind, deployOptions *DeployOpt
 	secrets := map[string]string{
 		"session_secret": deployOptions.CodewindSessionSecret,
 	}
-	name := "secret-codewind-session"
+	name := sessionSecretName
 	return generateSecrets(codewind, name, secrets, labels)
 }
 
@@ -135,7 +162,7 @@ func generateGatekeeperSecrets(codewind Codewind, deployOptions *DeployOptions)
 	secrets := map[string]string{
 		"client_secret": deployOptions.ClientSecret,
 	}
-	name := "secret-codewind-client"
+	name := clientSecretName
 	return generateSecrets(codewind, name, secrets, labels)
 }
 
@@ -149,7 +176,7 @@ func generateGatekeeperDeploy(codewind Codewind, deployOptions *DeployOptions) a
 		Name: "tls-certs",
 		VolumeSource: corev1.VolumeSource{
 			Secret: &corev1.SecretVolumeSource{
-				SecretName: "secret-codewind-tls" + "-" + codewind.WorkspaceID,
+				SecretName: codewind.gatekeeperTLSSecretName(),
 			},
 		},
 	}}
@@ -161,7 +188,10 @@ func generateGatekeeperDeploy(codewind Codewind, deployOptions *DeployOptions) a
 	}}
 
 	envVars := setGatekeeperEnvVars(codewind, deployOptions)
-	return generateDeployment(codewind, GatekeeperPrefix, codewind.GatekeeperImage, GatekeeperContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, false)
+	deployment := generateDeployment(codewind, GatekeeperPrefix, codewind.GatekeeperImage, GatekeeperContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, false, codewind.GatekeeperResources)
+	setReplicas(&deployment, codewind.GatekeeperReplicas)
+	setPodSecurity(&deployment, codewind.podSecurity(GatekeeperPrefix))
+	return deployment
 }
 
 func generateGatekeeperService(codewind Codewind) corev1.Service {
@@ -169,7 +199,11 @@ func generateGatekeeperService(codewind Codewind) corev1.Service {
 		"app":               GatekeeperPrefix,
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
-	return generateService(codewind, GatekeeperPrefix, GatekeeperContainerPort, labels)
+	service := generateService(codewind, GatekeeperPrefix, GatekeeperContainerPort, labels)
+	if codewind.Metrics {
+		service.SetAnnotations(generateScrapeAnnotations(GatekeeperContainerPort))
+	}
+	return service
 }
 
 // generateIngressGatekeeper returns a Kubernetes ingress for the Codewind Gatekeeper service
@@ -179,7 +213,7 @@ func generateIngressGatekeeper(codewind Codewind) extensionsv1.Ingress {
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
 
-	annotations := map[string]string{
+	defaultAnnotations := map[string]string{
 		"nginx.ingress.kubernetes.io/rewrite-target":     "/",
 		"ingress.bluemix.net/redirect-to-https":          "True",
 		"ingress.bluemix.net/ssl-services":               "ssl-service=" + GatekeeperPrefix + "-" + codewind.WorkspaceID,
@@ -187,6 +221,12 @@ func generateIngressGatekeeper(codewind Codewind) extensionsv1.Ingress {
 		"kubernetes.io/ingress.class":                    "nginx",
 		"nginx.ingress.kubernetes.io/force-ssl-redirect": "true",
 	}
+	if codewind.GatekeeperReplicas > 1 {
+		// Gatekeeper sessions are held by the replica that logged the user in, so keep each user on one replica.
+		// OpenShift routes are sticky by default.
+		defaultAnnotations["nginx.ingress.kubernetes.io/affinity"] = "cookie"
+	}
+	annotations := generateIngressAnnotations(codewind, defaultAnnotations)
 
 	return extensionsv1.Ingress{
 		TypeMeta: metav1.TypeMeta{
@@ -196,18 +236,18 @@ func generateIngressGatekeeper(codewind Codewind) extensionsv1.Ingress {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        GatekeeperPrefix + "-" + codewind.WorkspaceID,
 			Annotations: annotations,
-			Labels:      labels,
+			Labels:      ownedLabels(codewind, labels),
 		},
 		Spec: extensionsv1.IngressSpec{
 			TLS: []extensionsv1.IngressTLS{
 				{
-					Hosts:      []string{GatekeeperPrefix + codewind.Ingress},
-					SecretName: "secret-codewind-tls" + "-" + codewind.WorkspaceID,
+					Hosts:      []string{codewind.GatekeeperHost},
+					SecretName: codewind.gatekeeperTLSSecretName(),
 				},
 			},
 			Rules: []extensionsv1.IngressRule{
 				{
-					Host: GatekeeperPrefix + codewind.Ingress,
+					Host: codewind.GatekeeperHost,
 					IngressRuleValue: extensionsv1.IngressRuleValue{
 						HTTP: &extensionsv1.HTTPIngressRuleValue{
 							Paths: []extensionsv1.HTTPIngressPath{
@@ -244,8 +284,9 @@ func generateRouteGatekeeper(codewind Codewind) v1.Route {
 			APIVersion: "route.openshift.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   GatekeeperPrefix + "-" + codewind.WorkspaceID,
-			Labels: labels,
+			Name:        GatekeeperPrefix + "-" + codewind.WorkspaceID,
+			Labels:      ownedLabels(codewind, labels),
+			Annotations: codewind.IngressAnnotations,
 			// OwnerReferences: []metav1.OwnerReference{
 			// 	{
 			// 		APIVersion:         "apps/v1",
@@ -258,7 +299,7 @@ func generateRouteGatekeeper(codewind Codewind) v1.Route {
 			// },
 		},
 		Spec: v1.RouteSpec{
-			Host: GatekeeperPrefix + codewind.Ingress,
+			Host: codewind.GatekeeperHost,
 			Port: &v1.RoutePort{
 				TargetPort: intstr.FromInt(GatekeeperContainerPort),
 			},
@@ -277,7 +318,7 @@ func generateRouteGatekeeper(codewind Codewind) v1.Route {
 
 func setGatekeeperEnvVars(codewind Codewind, deployOptions *DeployOptions) []corev1.EnvVar {
 
-	keycloakURL := KeycloakPrefix + codewind.Ingress
+	keycloakURL := codewind.KeycloakHost
 
 	if deployOptions.KeycloakTLSSecure {
 		keycloakURL = "https://" + keycloakURL
@@ -304,7 +345,7 @@ func setGatekeeperEnvVars(codewind Codewind, deployOptions *DeployOptions) []cor
 		},
 		{
 			Name:  "GATEKEEPER_HOST",
-			Value: GatekeeperPrefix + codewind.Ingress,
+			Value: codewind.GatekeeperHost,
 		},
 		{
 			Name:  "REALM",
@@ -322,15 +363,19 @@ func setGatekeeperEnvVars(codewind Codewind, deployOptions *DeployOptions) []cor
 			Name:  "ACCESS_ROLE",
 			Value: "codewind-" + codewind.WorkspaceID,
 		},
+		{
+			Name:  "ACCESS_ROLE_READONLY",
+			Value: "codewind-" + codewind.WorkspaceID + "-readonly",
+		},
 		{
 			Name: "CLIENT_SECRET",
 			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-codewind-client" + "-" + codewind.WorkspaceID}, Key: "client_secret"}},
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: clientSecretName + "-" + codewind.WorkspaceID}, Key: "client_secret"}},
 		},
 		{
 			Name: "SESSION_SECRET",
 			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-codewind-session" + "-" + codewind.WorkspaceID}, Key: "session_secret"}},
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: sessionSecretName + "-" + codewind.WorkspaceID}, Key: "session_secret"}},
 		},
 		{
 			Name:  "PORTAL_HTTPS",
@@ -12,6 +12,7 @@
 package remote
 
 import (
+	"strconv"
 	"time"
 
 	logr "github.com/sirupsen/logrus"
@@ -27,6 +28,9 @@ type ExistingDeployment struct {
 	Version           string `json:"codewindVersion"`
 	InstallDate       string `json:"installTime"`
 	CodewindAuthRealm string `json:"codewindAuthRealm"`
+	GatekeeperURL     string `json:"gatekeeperURL"`
+	Age               string `json:"age"`
+	InstallID         string `json:"installID,omitempty"`
 }
 
 // K8sAPI is the k8s client called by the function
@@ -34,6 +38,26 @@ type K8sAPI struct {
 	clientset kubernetes.Interface
 }
 
+// newK8sAPI wraps the given clientset, or one built from the kube config when clientset is nil
+func newK8sAPI(clientset kubernetes.Interface) (K8sAPI, *RemInstError) {
+	client := K8sAPI{clientset: clientset}
+	if clientset != nil {
+		return client, nil
+	}
+
+	config, err := GetKubeConfig()
+	if err != nil {
+		logr.Infof("Unable to retrieve Kubernetes Config %v\n", err)
+		return client, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+
+	client.clientset, err = kubernetes.NewForConfig(config)
+	if err != nil {
+		return client, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	return client, nil
+}
+
 // GetExistingDeployments returns information about the remote installations of codewind, across all namespaces by default
 func GetExistingDeployments(namespace string, clientset kubernetes.Interface) ([]ExistingDeployment, *RemInstError) {
 	client := K8sAPI{}
@@ -62,14 +86,32 @@ func GetExistingDeployments(namespace string, clientset kubernetes.Interface) ([
 
 func (client K8sAPI) findDeployments(namespace string) ([]ExistingDeployment, *RemInstError) {
 	deployments, err := client.clientset.AppsV1().Deployments(namespace).List(v1.ListOptions{
-		LabelSelector: "app=codewind-pfe",
+		LabelSelector: "codewindWorkspace",
 	})
 	if err != nil {
 		return nil, &RemInstError{errOpNotFound, err, err.Error()}
 	}
 
+	// gatekeeper hosts, keyed by namespace and workspace ID
+	gatekeeperURLs := make(map[string]string)
+	for _, deployment := range deployments.Items {
+		if deployment.GetLabels()["app"] != GatekeeperPrefix {
+			continue
+		}
+		if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+			for _, e := range containers[0].Env {
+				if e.Name == "GATEKEEPER_HOST" {
+					gatekeeperURLs[deployment.GetNamespace()+"/"+deployment.GetLabels()["codewindWorkspace"]] = "https://" + e.Value
+				}
+			}
+		}
+	}
+
 	var RemoteInstalls []ExistingDeployment
 	for _, deployment := range deployments.Items {
+		if deployment.GetLabels()["app"] != PFEPrefix {
+			continue
+		}
 		installTime := deployment.GetCreationTimestamp().Format(time.RFC1123)
 		var keycloakAddress, cwVersion, authRealm string
 		// ensure there are containers in the list, to avoid index errors
@@ -88,16 +130,37 @@ func (client K8sAPI) findDeployments(namespace string) ([]ExistingDeployment, *R
 			}
 		}
 
+		workspaceID := deployment.GetLabels()["codewindWorkspace"]
 		deployInfo := ExistingDeployment{
 			Namespace:         deployment.GetNamespace(),
-			WorkspaceID:       deployment.GetLabels()["codewindWorkspace"],
+			WorkspaceID:       workspaceID,
 			CodewindURL:       keycloakAddress,
 			CodewindAuthRealm: authRealm,
 			Version:           cwVersion,
 			InstallDate:       installTime,
+			GatekeeperURL:     gatekeeperURLs[deployment.GetNamespace()+"/"+workspaceID],
+			Age:               formatAge(time.Since(deployment.GetCreationTimestamp().Time)),
+			InstallID:         deployment.GetLabels()[InstallIDLabel],
 		}
 		RemoteInstalls = append(RemoteInstalls, deployInfo)
 	}
 
 	return RemoteInstalls, nil
 }
+
+// formatAge returns a short, kubectl style representation of a duration, eg: 5d, 3h, 12m, 40s
+func formatAge(age time.Duration) string {
+	if age < 0 {
+		age = 0
+	}
+	switch {
+	case age >= 24*time.Hour:
+		return strconv.Itoa(int(age.Hours()/24)) + "d"
+	case age >= time.Hour:
+		return strconv.Itoa(int(age.Hours())) + "h"
+	case age >= time.Minute:
+		return strconv.Itoa(int(age.Minutes())) + "m"
+	default:
+		return strconv.Itoa(int(age.Seconds())) + "s"
+	}
+}
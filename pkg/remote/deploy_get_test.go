@@ -48,6 +48,7 @@ func newTestK8s() *K8sAPI {
 func generateMockDeployment(options MockDeploymentOptions) v1.Deployment {
 	return v1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
+			Name:              options.Labels["app"] + "-" + options.Labels["codewindWorkspace"],
 			Namespace:         options.Namespace,
 			CreationTimestamp: metav1.NewTime(options.CreationTimestamp),
 			Labels:            options.Labels,
@@ -67,7 +68,7 @@ func generateMockDeployment(options MockDeploymentOptions) v1.Deployment {
 }
 
 func TestSuccessfulDeployGet(t *testing.T) {
-	timeNow := time.Now()
+	timeNow := time.Now().Add(-2 * time.Hour)
 	mockDeploymentOptions1 := MockDeploymentOptions{
 		Namespace:         "test1",
 		CreationTimestamp: timeNow,
@@ -108,6 +109,18 @@ func TestSuccessfulDeployGet(t *testing.T) {
 		},
 	}
 
+	mockGatekeeperOptions1 := MockDeploymentOptions{
+		Namespace:         "test1",
+		CreationTimestamp: timeNow,
+		Labels:            map[string]string{"app": "codewind-gatekeeper", "codewindWorkspace": "WID1"},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "GATEKEEPER_HOST",
+				Value: "codewind-gatekeeper-WID1.nip.io",
+			},
+		},
+	}
+
 	ExistingDeployment1 := ExistingDeployment{
 		Namespace:         "test1",
 		WorkspaceID:       "WID1",
@@ -115,8 +128,12 @@ func TestSuccessfulDeployGet(t *testing.T) {
 		CodewindAuthRealm: "codewind",
 		InstallDate:       timeNow.Format(time.RFC1123),
 		Version:           "0.7.0",
+		Age:               "2h",
 	}
 
+	ExistingDeployment1WithGatekeeper := ExistingDeployment1
+	ExistingDeployment1WithGatekeeper.GatekeeperURL = "https://codewind-gatekeeper-WID1.nip.io"
+
 	ExistingDeployment2 := ExistingDeployment{
 		Namespace:         "test2",
 		WorkspaceID:       "WID2",
@@ -124,6 +141,7 @@ func TestSuccessfulDeployGet(t *testing.T) {
 		CodewindAuthRealm: "codewind",
 		InstallDate:       timeNow.Format(time.RFC1123),
 		Version:           "0.7.0",
+		Age:               "2h",
 	}
 
 	tests := map[string]struct {
@@ -146,6 +164,11 @@ func TestSuccessfulDeployGet(t *testing.T) {
 			inNamespace:           mockDeploymentOptions1.Namespace,
 			wantedDeploymentInfo:  []ExistingDeployment{ExistingDeployment1},
 		},
+		"1 namespace, with gatekeeper deployment": {
+			mockDeploymentOptions: []MockDeploymentOptions{mockDeploymentOptions1, mockGatekeeperOptions1},
+			inNamespace:           "",
+			wantedDeploymentInfo:  []ExistingDeployment{ExistingDeployment1WithGatekeeper},
+		},
 	}
 
 	for name, test := range tests {
@@ -175,6 +198,23 @@ func checkDeployments(t *testing.T, got, want []ExistingDeployment, err *RemInst
 	assert.EqualValues(t, got, want)
 }
 
+func TestFormatAge(t *testing.T) {
+	tests := map[string]struct {
+		age  time.Duration
+		want string
+	}{
+		"seconds": {age: 40 * time.Second, want: "40s"},
+		"minutes": {age: 12 * time.Minute, want: "12m"},
+		"hours":   {age: 3 * time.Hour, want: "3h"},
+		"days":    {age: 5 * 24 * time.Hour, want: "5d"},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, formatAge(test.age))
+		})
+	}
+}
+
 func Test_Deploy_Get_Error(t *testing.T) {
 	k8s := newTestK8s()
 	errorDesc := "Mock error getting deployments"
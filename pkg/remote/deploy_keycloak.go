@@ -22,6 +22,7 @@ import (
 	extensionsv1 "k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -33,9 +34,7 @@ func DeployKeycloak(config *restclient.Config, clientset *kubernetes.Clientset,
 	keycloakSecrets := generateKeycloakSecrets(codewindInstance, deployOptions)
 	keycloakService := generateKeycloakService(codewindInstance)
 	keycloakDeploy := generateKeycloakDeploy(codewindInstance)
-	serverKey, serverCert, _ := generateCertificate(KeycloakPrefix+codewindInstance.Ingress, "Codewind Keycloak")
-	keycloakTLSSecret := generateKeycloakTLSSecret(codewindInstance, serverKey, serverCert)
-	keycloakPVC := generateKeycloakPVC(codewindInstance, deployOptions, "")
+	keycloakPVC := generateKeycloakPVC(codewindInstance, deployOptions, deployOptions.GetKeycloakStorageClass())
 
 	logr.Infoln("Creating Codewind Keycloak PVC")
 	_, err := clientset.CoreV1().PersistentVolumeClaims(deployOptions.Namespace).Create(&keycloakPVC)
@@ -44,28 +43,47 @@ func DeployKeycloak(config *restclient.Config, clientset *kubernetes.Clientset,
 		return err
 	}
 
-	logr.Infoln("Deploying Codewind Keycloak Secrets")
-	_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&keycloakSecrets)
-	if err != nil {
-		logr.Errorf("Error: Unable to create Codewind Keycloak secrets: %v\n", err)
-		return err
+	if codewindInstance.KeycloakAdminSecret != "" {
+		logr.Infof("Using existing Codewind Keycloak admin secret '%v'\n", codewindInstance.KeycloakAdminSecret)
+	} else {
+		logr.Infoln("Deploying Codewind Keycloak Secrets")
+		_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&keycloakSecrets)
+		if err != nil {
+			logr.Errorf("Error: Unable to create Codewind Keycloak secrets: %v\n", err)
+			return err
+		}
 	}
 	_, err = clientset.CoreV1().Services(deployOptions.Namespace).Create(&keycloakService)
 	if err != nil {
 		logr.Errorf("Error: Unable to create Codewind Keycloak service: %v\n", err)
 		return err
 	}
-	_, err = clientset.AppsV1().Deployments(deployOptions.Namespace).Create(&keycloakDeploy)
+	err = createDeployment(clientset, &keycloakDeploy, codewindInstance.podSecurity(KeycloakPrefix))
 	if err != nil {
 		logr.Errorf("Error: Unable to create Codewind Keycloak deployment: %v\n", err)
 		return err
 	}
 
-	logr.Infoln("Deploying Codewind Keycloak TLS Secrets")
-	_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&keycloakTLSSecret)
-	if err != nil {
-		logr.Errorf("Error: Unable to create Codewind Keycloak TLS secrets: %v\n", err)
-		return err
+	if codewindInstance.KeycloakTLSSecret != "" {
+		logr.Infof("Using existing Codewind Keycloak TLS secret '%v'\n", codewindInstance.KeycloakTLSSecret)
+	} else if deployOptions.CertIssuer != "" {
+		logr.Infoln("Deploying Codewind Keycloak Certificate")
+		keycloakCertificate := generateKeycloakCertificate(codewindInstance, deployOptions)
+		err = createCertManagerCertificate(config, keycloakCertificate)
+		if err != nil {
+			logr.Errorf("Error: Unable to create Codewind Keycloak certificate: %v\n", err)
+			return err
+		}
+	} else {
+		serverKey, serverCert, _ := generateCertificate(codewindInstance.certificateDNSNames(codewindInstance.KeycloakHost), "Codewind Keycloak")
+		keycloakTLSSecret := generateKeycloakTLSSecret(codewindInstance, serverKey, serverCert)
+
+		logr.Infoln("Deploying Codewind Keycloak TLS Secrets")
+		_, err = clientset.CoreV1().Secrets(deployOptions.Namespace).Create(&keycloakTLSSecret)
+		if err != nil {
+			logr.Errorf("Error: Unable to create Codewind Keycloak TLS secrets: %v\n", err)
+			return err
+		}
 	}
 
 	// Expose Codewind over an ingress or route
@@ -104,20 +122,29 @@ func generateKeycloakTLSSecret(codewind Codewind, pemPrivateKey string, pemPubli
 		"app":               KeycloakPrefix,
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
-	name := "secret-keycloak-tls"
+	name := keycloakTLSName
 	return generateSecrets(codewind, name, secrets, labels)
 }
 
+// generateKeycloakCertificate returns a cert-manager Certificate which provisions the Keycloak TLS secret
+func generateKeycloakCertificate(codewind Codewind, deployOptions *DeployOptions) unstructured.Unstructured {
+	labels := map[string]string{
+		"app":               KeycloakPrefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	return generateCertManagerCertificate(codewind, deployOptions, "certificate-keycloak-tls", keycloakTLSName, codewind.certificateDNSNames(codewind.KeycloakHost), labels)
+}
+
 func generateKeycloakSecrets(codewind Codewind, deployOptions *DeployOptions) corev1.Secret {
 	secrets := map[string]string{
-		"keycloak-admin-user":     deployOptions.KeycloakUser,
-		"keycloak-admin-password": deployOptions.KeycloakPassword,
+		keycloakAdminUserKey:     deployOptions.KeycloakUser,
+		keycloakAdminPasswordKey: deployOptions.KeycloakPassword,
 	}
 	labels := map[string]string{
 		"app":               KeycloakPrefix,
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
-	name := "secret-keycloak-user"
+	name := keycloakUserSecretName
 	return generateSecrets(codewind, name, secrets, labels)
 }
 
@@ -128,7 +155,9 @@ func generateKeycloakDeploy(codewind Codewind) appsv1.Deployment {
 	}
 	volumes, volumeMounts := setKeycloakVolumes(codewind)
 	envVars := setKeycloakEnvVars(codewind)
-	return generateDeployment(codewind, KeycloakPrefix, codewind.KeycloakImage, KeycloakContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountKC, false)
+	deployment := generateDeployment(codewind, KeycloakPrefix, codewind.KeycloakImage, KeycloakContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountKC, false, codewind.KeycloakResources)
+	setPodSecurity(&deployment, codewind.podSecurity(KeycloakPrefix))
+	return deployment
 }
 
 func generateKeycloakService(codewind Codewind) corev1.Service {
@@ -146,12 +175,13 @@ func generateIngressKeycloak(codewind Codewind) extensionsv1.Ingress {
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
 
-	annotations := map[string]string{
+	defaultAnnotations := map[string]string{
 		"nginx.ingress.kubernetes.io/rewrite-target":     "/",
 		"nginx.ingress.kubernetes.io/backend-protocol":   "HTTP",
 		"nginx.ingress.kubernetes.io/force-ssl-redirect": "true",
 		"kubernetes.io/ingress.class":                    "nginx",
 	}
+	annotations := generateIngressAnnotations(codewind, defaultAnnotations)
 
 	return extensionsv1.Ingress{
 		TypeMeta: metav1.TypeMeta{
@@ -161,18 +191,18 @@ func generateIngressKeycloak(codewind Codewind) extensionsv1.Ingress {
 		ObjectMeta: metav1.ObjectMeta{
 			Name:        KeycloakPrefix + "-" + codewind.WorkspaceID,
 			Annotations: annotations,
-			Labels:      labels,
+			Labels:      ownedLabels(codewind, labels),
 		},
 		Spec: extensionsv1.IngressSpec{
 			TLS: []extensionsv1.IngressTLS{
 				{
-					Hosts:      []string{KeycloakPrefix + codewind.Ingress},
-					SecretName: "secret-keycloak-tls" + "-" + codewind.WorkspaceID,
+					Hosts:      []string{codewind.KeycloakHost},
+					SecretName: codewind.keycloakTLSSecretName(),
 				},
 			},
 			Rules: []extensionsv1.IngressRule{
 				{
-					Host: KeycloakPrefix + codewind.Ingress,
+					Host: codewind.KeycloakHost,
 					IngressRuleValue: extensionsv1.IngressRuleValue{
 						HTTP: &extensionsv1.HTTPIngressRuleValue{
 							Paths: []extensionsv1.HTTPIngressPath{
@@ -209,8 +239,9 @@ func generateKeycloakRoute(codewind Codewind) v1.Route {
 			APIVersion: "route.openshift.io/v1",
 		},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:   KeycloakPrefix + "-" + codewind.WorkspaceID,
-			Labels: labels,
+			Name:        KeycloakPrefix + "-" + codewind.WorkspaceID,
+			Labels:      ownedLabels(codewind, labels),
+			Annotations: codewind.IngressAnnotations,
 			// OwnerReferences: []metav1.OwnerReference{
 			// 	{
 			// 		APIVersion:         "apps/v1",
@@ -223,7 +254,7 @@ func generateKeycloakRoute(codewind Codewind) v1.Route {
 			// },
 		},
 		Spec: v1.RouteSpec{
-			Host: KeycloakPrefix + codewind.Ingress,
+			Host: codewind.KeycloakHost,
 			Port: &v1.RoutePort{
 				TargetPort: intstr.FromInt(KeycloakContainerPort),
 			},
@@ -245,12 +276,12 @@ func setKeycloakEnvVars(codewind Codewind) []corev1.EnvVar {
 		{
 			Name: "KEYCLOAK_USER",
 			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-keycloak-user" + "-" + codewind.WorkspaceID}, Key: "keycloak-admin-user"}},
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: codewind.keycloakAdminSecretName()}, Key: keycloakAdminUserKey}},
 		},
 		{
 			Name: "KEYCLOAK_PASSWORD",
 			ValueFrom: &corev1.EnvVarSource{
-				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "secret-keycloak-user" + "-" + codewind.WorkspaceID}, Key: "keycloak-admin-password"}},
+				SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: codewind.keycloakAdminSecretName()}, Key: keycloakAdminPasswordKey}},
 		},
 		{
 			Name:  "PROXY_ADDRESS_FORWARDING",
@@ -270,6 +301,11 @@ func generateKeycloakPVC(codewind Codewind, deployOptions *DeployOptions, storag
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
 
+	pvcSize := deployOptions.KeycloakPVCSize
+	if pvcSize == "" {
+		pvcSize = "1Gi"
+	}
+
 	pvc := corev1.PersistentVolumeClaim{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: "v1",
@@ -277,7 +313,7 @@ func generateKeycloakPVC(codewind Codewind, deployOptions *DeployOptions, storag
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   KeycloakPrefix + "-pvc-" + codewind.WorkspaceID,
-			Labels: labels,
+			Labels: ownedLabels(codewind, labels),
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{
@@ -285,7 +321,7 @@ func generateKeycloakPVC(codewind Codewind, deployOptions *DeployOptions, storag
 			},
 			Resources: corev1.ResourceRequirements{
 				Requests: corev1.ResourceList{
-					corev1.ResourceStorage: resource.MustParse("1Gi"),
+					corev1.ResourceStorage: resource.MustParse(pvcSize),
 				},
 			},
 		},
@@ -32,7 +32,7 @@ func CreateKeycloakServiceAcct(codewind Codewind, deployOptions *DeployOptions)
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   codewind.ServiceAccountKC,
-			Labels: labels,
+			Labels: ownedLabels(codewind, labels),
 		},
 		Secrets: nil,
 	}
@@ -0,0 +1,134 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	logr "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DeployNetworkPolicies : Restrict traffic so only the Gatekeeper reaches PFE and Performance, and the Gatekeeper
+// only accepts traffic on its service port, for clusters with default-deny namespaces
+func DeployNetworkPolicies(clientset kubernetes.Interface, codewindInstance Codewind) error {
+	policies := []networkingv1.NetworkPolicy{
+		generatePFENetworkPolicy(codewindInstance),
+		generatePerformanceNetworkPolicy(codewindInstance),
+		generateGatekeeperNetworkPolicy(codewindInstance),
+	}
+
+	for _, policy := range policies {
+		logr.Infof("Deploying Codewind network policy '%v'\n", policy.GetName())
+		_, err := clientset.NetworkingV1().NetworkPolicies(codewindInstance.Namespace).Create(&policy)
+		if err != nil {
+			logr.Errorf("Error: Unable to create network policy '%v': %v\n", policy.GetName(), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// DeployKeycloakNetworkPolicy : Allow traffic to a Keycloak deployed in a default-deny namespace
+func DeployKeycloakNetworkPolicy(clientset kubernetes.Interface, codewindInstance Codewind) error {
+	policy := generateKeycloakNetworkPolicy(codewindInstance)
+	logr.Infof("Deploying Codewind network policy '%v'\n", policy.GetName())
+	_, err := clientset.NetworkingV1().NetworkPolicies(codewindInstance.Namespace).Create(&policy)
+	if err != nil {
+		logr.Errorf("Error: Unable to create network policy '%v': %v\n", policy.GetName(), err)
+		return err
+	}
+	return nil
+}
+
+// generatePFENetworkPolicy allows traffic to PFE from the Gatekeeper, and from the jobs which start scheduled load
+// runs
+func generatePFENetworkPolicy(codewind Codewind) networkingv1.NetworkPolicy {
+	return generateNetworkPolicy(codewind, PFEPrefix, PFEContainerPort, []networkingv1.NetworkPolicyPeer{
+		workspacePeer(codewind, GatekeeperPrefix),
+		workspacePeer(codewind, LoadTestPrefix),
+	})
+}
+
+// generatePerformanceNetworkPolicy allows traffic to the Performance dashboard from the Gatekeeper, and from PFE
+// which calls the dashboard API when running load tests
+func generatePerformanceNetworkPolicy(codewind Codewind) networkingv1.NetworkPolicy {
+	return generateNetworkPolicy(codewind, PerformancePrefix, PerformanceContainerPort, []networkingv1.NetworkPolicyPeer{
+		workspacePeer(codewind, GatekeeperPrefix),
+		workspacePeer(codewind, PFEPrefix),
+	})
+}
+
+// generateGatekeeperNetworkPolicy allows traffic to the Gatekeeper service port only. The source is not restricted
+// since ingress controllers and OpenShift routers run in namespaces which vary between clusters.
+func generateGatekeeperNetworkPolicy(codewind Codewind) networkingv1.NetworkPolicy {
+	return generateNetworkPolicy(codewind, GatekeeperPrefix, GatekeeperContainerPort, nil)
+}
+
+// generateKeycloakNetworkPolicy allows traffic to the Keycloak service port, which is reached through its ingress
+func generateKeycloakNetworkPolicy(codewind Codewind) networkingv1.NetworkPolicy {
+	return generateNetworkPolicy(codewind, KeycloakPrefix, KeycloakContainerPort, nil)
+}
+
+// generateNetworkPolicy returns a policy accepting ingress to the pods of a component on the given port, from the
+// given peers or from anywhere when peers is nil
+func generateNetworkPolicy(codewind Codewind, prefix string, port int, peers []networkingv1.NetworkPolicyPeer) networkingv1.NetworkPolicy {
+	labels := map[string]string{
+		"app":               prefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	protocol := corev1.ProtocolTCP
+	policyPort := intstr.FromInt(port)
+
+	return networkingv1.NetworkPolicy{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "NetworkPolicy",
+			APIVersion: "networking.k8s.io/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      prefix + "-" + codewind.WorkspaceID,
+			Namespace: codewind.Namespace,
+			Labels:    ownedLabels(codewind, labels),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{
+				MatchLabels: labels,
+			},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress},
+			Ingress: []networkingv1.NetworkPolicyIngressRule{
+				{
+					Ports: []networkingv1.NetworkPolicyPort{
+						{
+							Protocol: &protocol,
+							Port:     &policyPort,
+						},
+					},
+					From: peers,
+				},
+			},
+		},
+	}
+}
+
+// workspacePeer selects the pods of a component in the same workspace
+func workspacePeer(codewind Codewind, prefix string) networkingv1.NetworkPolicyPeer {
+	return networkingv1.NetworkPolicyPeer{
+		PodSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{
+				"app":               prefix,
+				"codewindWorkspace": codewind.WorkspaceID,
+			},
+		},
+	}
+}
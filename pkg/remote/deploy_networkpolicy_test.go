@@ -0,0 +1,57 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGenerateNetworkPolicies(t *testing.T) {
+	t.Run("success case - PFE only accepts traffic from the gatekeeper and scheduled load runs", func(t *testing.T) {
+		policy := generatePFENetworkPolicy(MockCodewind)
+		assert.Equal(t, PFEPrefix+"-"+MockCodewind.WorkspaceID, policy.GetName())
+		assert.Equal(t, map[string]string{"app": PFEPrefix, "codewindWorkspace": MockCodewind.WorkspaceID}, policy.Spec.PodSelector.MatchLabels)
+		assert.Len(t, policy.Spec.Ingress, 1)
+		assert.Equal(t, int(PFEContainerPort), policy.Spec.Ingress[0].Ports[0].Port.IntValue())
+		assert.Len(t, policy.Spec.Ingress[0].From, 2)
+		assert.Equal(t, GatekeeperPrefix, policy.Spec.Ingress[0].From[0].PodSelector.MatchLabels["app"])
+		assert.Equal(t, LoadTestPrefix, policy.Spec.Ingress[0].From[1].PodSelector.MatchLabels["app"])
+	})
+
+	t.Run("success case - Performance accepts traffic from the gatekeeper and PFE", func(t *testing.T) {
+		policy := generatePerformanceNetworkPolicy(MockCodewind)
+		peers := []string{}
+		for _, peer := range policy.Spec.Ingress[0].From {
+			peers = append(peers, peer.PodSelector.MatchLabels["app"])
+		}
+		assert.Equal(t, []string{GatekeeperPrefix, PFEPrefix}, peers)
+	})
+
+	t.Run("success case - gatekeeper accepts traffic from anywhere on its port", func(t *testing.T) {
+		policy := generateGatekeeperNetworkPolicy(MockCodewind)
+		assert.Nil(t, policy.Spec.Ingress[0].From)
+		assert.Equal(t, int(GatekeeperContainerPort), policy.Spec.Ingress[0].Ports[0].Port.IntValue())
+	})
+}
+
+func TestDeployNetworkPolicies(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	err := DeployNetworkPolicies(clientset, MockCodewind)
+	assert.Nil(t, err)
+
+	policies, _ := clientset.NetworkingV1().NetworkPolicies(MockCodewind.Namespace).List(metav1.ListOptions{})
+	assert.Len(t, policies.Items, 3)
+}
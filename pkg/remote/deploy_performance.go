@@ -31,12 +31,12 @@ func DeployPerformance(clientset *kubernetes.Clientset, codewind Codewind, deplo
 		log.Errorf("Error: Unable to create Codewind Performance service: %v\n", err)
 		return err
 	}
-	_, err = clientset.AppsV1().Deployments(deployOptions.Namespace).Create(&performanceDeploy)
+	err = createDeployment(clientset, &performanceDeploy, codewind.podSecurity(PerformancePrefix))
 	if err != nil {
 		log.Errorf("Error: Unable to create Codewind Performance deployment: %v\n", err)
 		return err
 	}
-	return nil
+	return deployPodDisruptionBudget(clientset, codewind, PerformancePrefix, codewind.PerformanceReplicas)
 }
 
 func generatePerformanceDeploy(codewind Codewind) appsv1.Deployment {
@@ -48,7 +48,10 @@ func generatePerformanceDeploy(codewind Codewind) appsv1.Deployment {
 	volumes := []corev1.Volume{}
 	volumeMounts := []corev1.VolumeMount{}
 	envVars := setPerformanceEnvVars(codewind)
-	return generateDeployment(codewind, PerformancePrefix, codewind.PerformanceImage, PerformanceContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, false)
+	deployment := generateDeployment(codewind, PerformancePrefix, codewind.PerformanceImage, PerformanceContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, false, codewind.PerformanceResources)
+	setReplicas(&deployment, codewind.PerformanceReplicas)
+	setPodSecurity(&deployment, codewind.podSecurity(PerformancePrefix))
+	return deployment
 }
 
 func generatePerformanceService(codewind Codewind) corev1.Service {
@@ -102,11 +102,15 @@ func DeployPFE(config *restclient.Config, clientset *kubernetes.Clientset, codew
 		}
 	}
 
-	// Determine if we're running on OpenShift on IKS (and thus need to use the ibm-file-bronze storage class)
-	storageClass := ""
-	sc, err := clientset.StorageV1().StorageClasses().Get(ROKSStorageClass, metav1.GetOptions{})
-	if err == nil && sc != nil {
-		storageClass = sc.Name
+	// Use the requested storage class, otherwise determine if we're running on OpenShift on IKS (and thus need to use the ibm-file-bronze storage class)
+	storageClass := deployOptions.StorageClass
+	if storageClass == "" {
+		sc, err := clientset.StorageV1().StorageClasses().Get(ROKSStorageClass, metav1.GetOptions{})
+		if err == nil && sc != nil {
+			storageClass = sc.Name
+		}
+	}
+	if storageClass != "" {
 		logr.Infof("Setting storage class to %s\n", storageClass)
 	}
 
@@ -124,7 +128,7 @@ func DeployPFE(config *restclient.Config, clientset *kubernetes.Clientset, codew
 		logr.Errorf("Unable to create Codewind service: %v\n", err)
 		return err
 	}
-	_, err = clientset.AppsV1().Deployments(deployOptions.Namespace).Create(&deploy)
+	err = createDeployment(clientset, &deploy, codewindInstance.podSecurity(PFEPrefix))
 	if err != nil {
 		logr.Errorf("Unable to create Codewind deployment: %v\n", err)
 		return err
@@ -140,7 +144,9 @@ func generatePFEDeploy(codewind Codewind, deployOptions *DeployOptions) appsv1.D
 	}
 	volumes, volumeMounts := setPFEVolumes(codewind)
 	envVars := setPFEEnvVars(codewind, deployOptions)
-	return generateDeployment(codewind, PFEPrefix, codewind.PFEImage, PFEContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, true)
+	deployment := generateDeployment(codewind, PFEPrefix, codewind.PFEImage, PFEContainerPort, volumes, volumeMounts, envVars, labels, codewind.ServiceAccountName, true, codewind.PFEResources)
+	setPodSecurity(&deployment, codewind.podSecurity(PFEPrefix))
+	return deployment
 }
 
 // generatePFEService : creates a Kubernetes service
@@ -149,15 +155,16 @@ func generatePFEService(codewind Codewind) corev1.Service {
 		"app":               PFEPrefix,
 		"codewindWorkspace": codewind.WorkspaceID,
 	}
-	return generateService(codewind, PFEPrefix, PFEContainerPort, labels)
+	service := generateService(codewind, PFEPrefix, PFEContainerPort, labels)
+	if codewind.Metrics {
+		service.SetAnnotations(generateScrapeAnnotations(PFEContainerPort))
+	}
+	return service
 }
 
 func setPFEEnvVars(codewind Codewind, deployOptions *DeployOptions) []corev1.EnvVar {
 
-	authHost := deployOptions.KeycloakHost
-	if authHost == "" {
-		authHost = KeycloakPrefix + codewind.Ingress
-	}
+	authHost := codewind.KeycloakHost
 
 	return []corev1.EnvVar{
 		{
@@ -222,7 +229,7 @@ func setPFEEnvVars(codewind Codewind, deployOptions *DeployOptions) []corev1.Env
 		},
 		{
 			Name:  "CHE_INGRESS_HOST",
-			Value: GatekeeperPrefix + codewind.Ingress,
+			Value: codewind.GatekeeperHost,
 		},
 		{
 			Name:  "INGRESS_PREFIX",
@@ -261,7 +268,7 @@ func generateCodewindPVC(codewind Codewind, deployOptions *DeployOptions, storag
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   codewind.PVCName,
-			Labels: labels,
+			Labels: ownedLabels(codewind, labels),
 		},
 		Spec: corev1.PersistentVolumeClaimSpec{
 			AccessModes: []corev1.PersistentVolumeAccessMode{
@@ -137,7 +137,7 @@ func CreateCodewindRoleBindings(codewindInstance Codewind, deployOptions *Deploy
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   codewindRoleBindingName,
-			Labels: labels,
+			Labels: ownedLabels(codewindInstance, labels),
 		},
 		Subjects: []rbacv1.Subject{
 			rbacv1.Subject{
@@ -167,7 +167,7 @@ func CreateCodewindTektonClusterRoleBindings(codewindInstance Codewind, deployOp
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   roleBindingName,
-			Labels: labels,
+			Labels: ownedLabels(codewindInstance, labels),
 		},
 		Subjects: []rbacv1.Subject{
 			rbacv1.Subject{
@@ -32,7 +32,7 @@ func CreateCodewindServiceAcct(codewind Codewind, deployOptions *DeployOptions)
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			Name:   codewind.ServiceAccountName,
-			Labels: labels,
+			Labels: ownedLabels(codewind, labels),
 		},
 		Secrets: nil,
 	}
@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"strings"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DiscoveredDeployment : A remote deployment found in the current Kubernetes context, and the ID of the connection
+// that already points at its Gatekeeper, if there is one
+type DiscoveredDeployment struct {
+	ExistingDeployment
+	ConnectionID string `json:"connectionID,omitempty"`
+}
+
+// DiscoverDeployments returns the deployments whose Gatekeeper can be connected to. connectionURLs maps the URL of
+// each known connection to its ID, so that deployments which are already registered can be identified.
+func DiscoverDeployments(namespace string, clientset kubernetes.Interface, connectionURLs map[string]string) ([]DiscoveredDeployment, *RemInstError) {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	deployments, remInstErr := client.findDeployments(namespace)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	knownURLs := map[string]string{}
+	for url, conID := range connectionURLs {
+		knownURLs[normaliseURL(url)] = conID
+	}
+
+	discovered := []DiscoveredDeployment{}
+	for _, deployment := range deployments {
+		if deployment.GatekeeperURL == "" {
+			deployment.GatekeeperURL = client.findGatekeeperIngressURL(deployment.Namespace, deployment.WorkspaceID)
+		}
+		if deployment.GatekeeperURL == "" {
+			continue
+		}
+		discovered = append(discovered, DiscoveredDeployment{
+			ExistingDeployment: deployment,
+			ConnectionID:       knownURLs[normaliseURL(deployment.GatekeeperURL)],
+		})
+	}
+	return discovered, nil
+}
+
+// findGatekeeperIngressURL returns the URL of the Gatekeeper ingress of a workspace, for deployments whose Gatekeeper
+// does not record its host
+func (client K8sAPI) findGatekeeperIngressURL(namespace string, workspaceID string) string {
+	ingresses, err := client.clientset.ExtensionsV1beta1().Ingresses(namespace).List(v1.ListOptions{
+		LabelSelector: "app=" + GatekeeperPrefix + ",codewindWorkspace=" + workspaceID,
+	})
+	if err != nil {
+		return ""
+	}
+	for _, ingress := range ingresses.Items {
+		for _, rule := range ingress.Spec.Rules {
+			if rule.Host != "" {
+				return "https://" + rule.Host
+			}
+		}
+	}
+	return ""
+}
+
+// normaliseURL makes URLs which differ only by case or a trailing slash compare equal
+func normaliseURL(url string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(url)), "/")
+}
@@ -0,0 +1,58 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	extensionsv1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDiscoverDeployments(t *testing.T) {
+	pfe1 := generateMockDeployment(MockDeploymentOptions{Namespace: "team1", CreationTimestamp: time.Now(), Labels: map[string]string{"app": PFEPrefix, "codewindWorkspace": "wid1"}})
+	gatekeeper1 := generateMockDeployment(MockDeploymentOptions{Namespace: "team1", CreationTimestamp: time.Now(), Labels: map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "wid1"},
+		Env: []corev1.EnvVar{{Name: "GATEKEEPER_HOST", Value: "codewind-gatekeeper-wid1.example.com"}}})
+	pfe2 := generateMockDeployment(MockDeploymentOptions{Namespace: "team2", CreationTimestamp: time.Now(), Labels: map[string]string{"app": PFEPrefix, "codewindWorkspace": "wid2"}})
+	ingress2 := extensionsv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: GatekeeperPrefix + "-wid2", Namespace: "team2", Labels: map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "wid2"}},
+		Spec:       extensionsv1.IngressSpec{Rules: []extensionsv1.IngressRule{{Host: "codewind-gatekeeper-wid2.example.com"}}},
+	}
+	pfe3 := generateMockDeployment(MockDeploymentOptions{Namespace: "team3", CreationTimestamp: time.Now(), Labels: map[string]string{"app": PFEPrefix, "codewindWorkspace": "wid3"}})
+
+	clientset := fake.NewSimpleClientset(&pfe1, &gatekeeper1, &pfe2, &ingress2, &pfe3)
+	connectionURLs := map[string]string{"https://CODEWIND-GATEKEEPER-WID1.example.com/": "K8AB1AB1"}
+
+	discovered, remInstErr := DiscoverDeployments("", clientset, connectionURLs)
+	assert.Nil(t, remInstErr)
+
+	t.Run("Deployments without a Gatekeeper URL are left out", func(t *testing.T) {
+		assert.Len(t, discovered, 2)
+	})
+
+	byWorkspace := map[string]DiscoveredDeployment{}
+	for _, deployment := range discovered {
+		byWorkspace[deployment.WorkspaceID] = deployment
+	}
+	t.Run("A deployment with a matching connection is marked as registered", func(t *testing.T) {
+		assert.Equal(t, "https://codewind-gatekeeper-wid1.example.com", byWorkspace["wid1"].GatekeeperURL)
+		assert.Equal(t, "K8AB1AB1", byWorkspace["wid1"].ConnectionID)
+	})
+	t.Run("The Gatekeeper ingress is used when the Gatekeeper does not record its host", func(t *testing.T) {
+		assert.Equal(t, "https://codewind-gatekeeper-wid2.example.com", byWorkspace["wid2"].GatekeeperURL)
+		assert.Equal(t, "", byWorkspace["wid2"].ConnectionID)
+	})
+}
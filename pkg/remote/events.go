@@ -0,0 +1,152 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	restclient "k8s.io/client-go/rest"
+)
+
+// Events : Receives the progress of an install or removal, so that IDEs and other embedders can show it without
+// reading the log. The methods may be called from more than one goroutine at a time.
+type Events interface {
+	// OnPhaseChange is called as each step starts, with how much of the install or removal is done, from 0 to 100
+	OnPhaseChange(phase string, percent int)
+	// OnResourceCreated is called for each Kubernetes resource created, with its resource type, such as deployments,
+	// and its name
+	OnResourceCreated(resource string, name string)
+	// OnResourceRemoved is called for each Kubernetes resource removed, with its resource type and name
+	OnResourceRemoved(resource string, name string)
+}
+
+// noEvents discards the progress of installs and removals that are not given any events
+type noEvents struct{}
+
+func (noEvents) OnPhaseChange(string, int)        {}
+func (noEvents) OnResourceCreated(string, string) {}
+func (noEvents) OnResourceRemoved(string, string) {}
+
+// eventsOrNone returns the events given, or events that are discarded when there are none
+func eventsOrNone(events Events) Events {
+	if events == nil {
+		return noEvents{}
+	}
+	return events
+}
+
+// watchResources reports the resources created and removed through clients made from the Kubernetes config to the
+// events, by watching the requests sent to the API server
+func watchResources(config *restclient.Config, events Events) {
+	if _, ok := events.(noEvents); ok {
+		return
+	}
+	wrap := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &resourceWatcher{next: rt, events: events}
+	}
+}
+
+// resourceWatcher sends requests to the API server, reporting each one that created or removed a resource
+type resourceWatcher struct {
+	next   http.RoundTripper
+	events Events
+}
+
+func (watcher *resourceWatcher) RoundTrip(req *http.Request) (*http.Response, error) {
+	res, err := watcher.next.RoundTrip(req)
+	if err != nil || res.StatusCode < 200 || res.StatusCode > 299 {
+		return res, err
+	}
+	resource, name := resourcePath(req.URL.Path)
+	switch req.Method {
+	case http.MethodPost:
+		if resource == "" || name != "" {
+			return res, err
+		}
+		// The name of a created resource may be generated, so read it from the resource returned
+		body, readErr := ioutil.ReadAll(res.Body)
+		res.Body.Close()
+		res.Body = ioutil.NopCloser(bytes.NewReader(body))
+		if readErr != nil {
+			return res, err
+		}
+		created := struct {
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+		}{}
+		if json.Unmarshal(body, &created) == nil && created.Metadata.Name != "" {
+			watcher.events.OnResourceCreated(resource, created.Metadata.Name)
+		}
+	case http.MethodDelete:
+		if resource != "" && name != "" {
+			watcher.events.OnResourceRemoved(resource, name)
+		}
+	}
+	return res, err
+}
+
+// resourcePath returns the resource type and name of an API server path, such as deployments and codewind-pfe for
+// /apis/apps/v1/namespaces/codewind/deployments/codewind-pfe. The name is empty for a path to a collection, and the
+// resource type is empty for paths to anything else, such as subresources or the API groups.
+func resourcePath(path string) (string, string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	// Skip /api/<version> or /apis/<group>/<version>
+	switch {
+	case len(parts) >= 2 && parts[0] == "api":
+		parts = parts[2:]
+	case len(parts) >= 3 && parts[0] == "apis":
+		parts = parts[3:]
+	default:
+		return "", ""
+	}
+	// Namespaced resources are under namespaces/<namespace>, except the namespaces themselves
+	if len(parts) >= 3 && parts[0] == "namespaces" {
+		parts = parts[2:]
+	}
+	switch len(parts) {
+	case 1:
+		return parts[0], ""
+	case 2:
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
+
+// progress reports the phases of a run of steps, each step taking an equal share of the percentage done
+type progress struct {
+	events Events
+	total  int
+	mutex  sync.Mutex
+	done   int
+}
+
+// finished reports that a step has finished, with the phase the run is now in
+func (p *progress) finished(phase string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.done++
+	percent := 100
+	if p.total > 0 && p.done < p.total {
+		percent = p.done * 100 / p.total
+	}
+	p.events.OnPhaseChange(phase, percent)
+}
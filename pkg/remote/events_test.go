@@ -0,0 +1,140 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	restclient "k8s.io/client-go/rest"
+)
+
+// recordedEvents keeps the events reported to it, in the order they were reported
+type recordedEvents struct {
+	mutex   sync.Mutex
+	phases  []string
+	created []string
+	removed []string
+}
+
+func (events *recordedEvents) OnPhaseChange(phase string, percent int) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+	events.phases = append(events.phases, fmt.Sprintf("%s %d", phase, percent))
+}
+
+func (events *recordedEvents) OnResourceCreated(resource string, name string) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+	events.created = append(events.created, resource+"/"+name)
+}
+
+func (events *recordedEvents) OnResourceRemoved(resource string, name string) {
+	events.mutex.Lock()
+	defer events.mutex.Unlock()
+	events.removed = append(events.removed, resource+"/"+name)
+}
+
+func TestResourcePath(t *testing.T) {
+	tests := map[string]struct {
+		path     string
+		resource string
+		name     string
+	}{
+		"core collection":       {"/api/v1/namespaces/cw/services", "services", ""},
+		"core resource":         {"/api/v1/namespaces/cw/services/codewind-pfe", "services", "codewind-pfe"},
+		"group resource":        {"/apis/apps/v1/namespaces/cw/deployments/codewind-pfe", "deployments", "codewind-pfe"},
+		"cluster resource":      {"/apis/rbac.authorization.k8s.io/v1/clusterroles/codewind-role", "clusterroles", "codewind-role"},
+		"namespace":             {"/api/v1/namespaces/cw", "namespaces", "cw"},
+		"namespaces collection": {"/api/v1/namespaces", "namespaces", ""},
+		"subresource":           {"/apis/apps/v1/namespaces/cw/deployments/codewind-pfe/scale", "", ""},
+		"api groups":            {"/apis", "", ""},
+		"not the api":           {"/healthz", "", ""},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resource, resourceName := resourcePath(test.path)
+			assert.Equal(t, test.resource, resource)
+			assert.Equal(t, test.name, resourceName)
+		})
+	}
+}
+
+func TestWatchResources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/secrets"):
+			w.WriteHeader(http.StatusCreated)
+			w.Write([]byte(`{"metadata":{"name":"codewind-secret-abcde"}}`))
+		case r.Method == http.MethodPost:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"metadata":{"name":"codewind-pfe"}}`))
+		default:
+			w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	events := &recordedEvents{}
+	config := &restclient.Config{Host: server.URL}
+	watchResources(config, events)
+	transport, err := restclient.TransportFor(config)
+	if !assert.Nil(t, err) {
+		return
+	}
+	client := &http.Client{Transport: transport}
+
+	send := func(method string, path string) string {
+		req, _ := http.NewRequest(method, server.URL+path, strings.NewReader("{}"))
+		resp, err := client.Do(req)
+		if !assert.Nil(t, err) {
+			return ""
+		}
+		defer resp.Body.Close()
+		body, _ := ioutil.ReadAll(resp.Body)
+		return string(body)
+	}
+
+	t.Run("created resources are reported with the name returned", func(t *testing.T) {
+		body := send(http.MethodPost, "/api/v1/namespaces/cw/secrets")
+		assert.Equal(t, `{"metadata":{"name":"codewind-secret-abcde"}}`, body, "the response should still be readable")
+		assert.Equal(t, []string{"secrets/codewind-secret-abcde"}, events.created)
+	})
+	t.Run("failed creations are not reported", func(t *testing.T) {
+		send(http.MethodPost, "/apis/apps/v1/namespaces/cw/deployments")
+		assert.Len(t, events.created, 1)
+	})
+	t.Run("removed resources are reported", func(t *testing.T) {
+		send(http.MethodDelete, "/apis/apps/v1/namespaces/cw/deployments/codewind-pfe")
+		assert.Equal(t, []string{"deployments/codewind-pfe"}, events.removed)
+	})
+	t.Run("reads are not reported", func(t *testing.T) {
+		send(http.MethodGet, "/apis/apps/v1/namespaces/cw/deployments/codewind-pfe")
+		assert.Len(t, events.created, 1)
+		assert.Len(t, events.removed, 1)
+	})
+}
+
+func TestProgress(t *testing.T) {
+	events := &recordedEvents{}
+	done := &progress{events: events, total: 3}
+	done.finished("one")
+	done.finished("two")
+	done.finished("three")
+	assert.Equal(t, []string{"one 33", "two 66", "three 100"}, events.phases)
+}
@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	logr "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Keys of the Keycloak admin secret, whether generated by the install or given as an existing secret
+const (
+	keycloakAdminUserKey     = "keycloak-admin-user"
+	keycloakAdminPasswordKey = "keycloak-admin-password"
+)
+
+// secretPollInterval is how often an install checks whether an existing secret it needs has been created
+var secretPollInterval = 2 * time.Second
+
+// ValidateExistingSecrets returns an error when an existing secret name is invalid, or is given with the options it
+// replaces
+func ValidateExistingSecrets(deployOptions *DeployOptions) error {
+	names := []string{deployOptions.KeycloakAdminSecret, deployOptions.GatekeeperTLSSecret, deployOptions.KeycloakTLSSecret}
+	for _, name := range names {
+		if name != "" && len(validation.IsDNS1123Subdomain(name)) > 0 {
+			return errors.New(errBadSecretName + ": " + name)
+		}
+	}
+	if deployOptions.KeycloakAdminSecret != "" && (deployOptions.KeycloakUser != "" || deployOptions.KeycloakPassword != "") {
+		return errors.New(errExistingSecretConflict + ": the Keycloak admin secret replaces the Keycloak admin user and password")
+	}
+	if (deployOptions.GatekeeperTLSSecret != "" || deployOptions.KeycloakTLSSecret != "") && deployOptions.CertIssuer != "" {
+		return errors.New(errExistingSecretConflict + ": TLS secrets replace the certificates of the cert-manager issuer")
+	}
+	return nil
+}
+
+// resolveExistingSecrets waits for the existing secrets given at install to hold the keys Codewind reads, as a
+// controller such as External Secrets or Sealed Secrets may still be creating them, then reads the Keycloak admin
+// credentials from their secret so that Keycloak can be configured
+func resolveExistingSecrets(ctx context.Context, clientset kubernetes.Interface, namespace string, deployOptions *DeployOptions) *RemInstError {
+	timeout := deployOptions.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+
+	if deployOptions.KeycloakAdminSecret != "" {
+		secret, err := waitForSecret(ctx, clientset, namespace, deployOptions.KeycloakAdminSecret, []string{keycloakAdminUserKey, keycloakAdminPasswordKey}, timeout)
+		if err != nil {
+			return &RemInstError{errOpExistingSecret, err, err.Error()}
+		}
+		deployOptions.KeycloakUser = secretValue(secret.Data, secret.StringData, keycloakAdminUserKey)
+		deployOptions.KeycloakPassword = secretValue(secret.Data, secret.StringData, keycloakAdminPasswordKey)
+	}
+
+	tlsKeys := []string{corev1.TLSCertKey, corev1.TLSPrivateKeyKey}
+	for _, name := range []string{deployOptions.GatekeeperTLSSecret, deployOptions.KeycloakTLSSecret} {
+		if name == "" {
+			continue
+		}
+		_, err := waitForSecret(ctx, clientset, namespace, name, tlsKeys, timeout)
+		if err != nil {
+			return &RemInstError{errOpExistingSecret, err, err.Error()}
+		}
+	}
+	return nil
+}
+
+// waitForSecret polls until a secret exists with all of the keys, or returns an error after the timeout or once the
+// context is done
+func waitForSecret(ctx context.Context, clientset kubernetes.Interface, namespace string, name string, keys []string, timeout time.Duration) (*corev1.Secret, error) {
+	logr.Infof("Waiting for existing secret '%v'\n", name)
+	var secret *corev1.Secret
+	err := pollImmediateContext(ctx, secretPollInterval, timeout, func() (bool, error) {
+		found, err := clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			// The secret may not have been created yet, keep polling
+			logr.Tracef("Unable to get secret '%v': %v", name, err)
+			return false, nil
+		}
+		for _, key := range keys {
+			if secretValue(found.Data, found.StringData, key) == "" {
+				return false, nil
+			}
+		}
+		secret = found
+		return true, nil
+	})
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if err != nil {
+		return nil, errors.New(errSecretNotReady + ": " + name + " needs " + strings.Join(keys, ", "))
+	}
+	return secret, nil
+}
+
+// keycloakAdminSecretName returns the secret holding the Keycloak admin credentials
+func (codewind Codewind) keycloakAdminSecretName() string {
+	if codewind.KeycloakAdminSecret != "" {
+		return codewind.KeycloakAdminSecret
+	}
+	return keycloakUserSecretName + "-" + codewind.WorkspaceID
+}
+
+// gatekeeperTLSSecretName returns the secret holding the Gatekeeper TLS certificate and key
+func (codewind Codewind) gatekeeperTLSSecretName() string {
+	if codewind.GatekeeperTLSSecret != "" {
+		return codewind.GatekeeperTLSSecret
+	}
+	return gatekeeperTLSName + "-" + codewind.WorkspaceID
+}
+
+// keycloakTLSSecretName returns the secret holding the Keycloak TLS certificate and key
+func (codewind Codewind) keycloakTLSSecretName() string {
+	if codewind.KeycloakTLSSecret != "" {
+		return codewind.KeycloakTLSSecret
+	}
+	return keycloakTLSName + "-" + codewind.WorkspaceID
+}
@@ -0,0 +1,122 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestValidateExistingSecrets(t *testing.T) {
+	tests := map[string]struct {
+		options DeployOptions
+		want    string
+	}{
+		"no existing secrets": {
+			options: DeployOptions{KeycloakUser: "admin", KeycloakPassword: "password", CertIssuer: "letsencrypt"},
+		},
+		"existing secrets": {
+			options: DeployOptions{KeycloakAdminSecret: "keycloak-admin", GatekeeperTLSSecret: "codewind-tls", KeycloakTLSSecret: "keycloak-tls"},
+		},
+		"invalid secret name": {
+			options: DeployOptions{GatekeeperTLSSecret: "Codewind_TLS"},
+			want:    errBadSecretName,
+		},
+		"admin secret with admin password": {
+			options: DeployOptions{KeycloakAdminSecret: "keycloak-admin", KeycloakPassword: "password"},
+			want:    errExistingSecretConflict,
+		},
+		"TLS secret with cert-manager issuer": {
+			options: DeployOptions{KeycloakTLSSecret: "keycloak-tls", CertIssuer: "letsencrypt"},
+			want:    errExistingSecretConflict,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateExistingSecrets(&test.options)
+			if test.want == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.want)
+			}
+		})
+	}
+}
+
+func TestResolveExistingSecrets(t *testing.T) {
+	secretPollInterval = 10 * time.Millisecond
+
+	t.Run("success case - reads the Keycloak admin credentials", func(t *testing.T) {
+		client := fake.NewSimpleClientset(
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "keycloak-admin", Namespace: MockCodewind.Namespace},
+				Data:       map[string][]byte{keycloakAdminUserKey: []byte("admin"), keycloakAdminPasswordKey: []byte("password")},
+			},
+			&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "codewind-tls", Namespace: MockCodewind.Namespace},
+				Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert"), corev1.TLSPrivateKeyKey: []byte("key")},
+			},
+		)
+		deployOptions := DeployOptions{KeycloakAdminSecret: "keycloak-admin", GatekeeperTLSSecret: "codewind-tls", Timeout: 50 * time.Millisecond}
+		remInstErr := resolveExistingSecrets(context.Background(), client, MockCodewind.Namespace, &deployOptions)
+		assert.Nil(t, remInstErr)
+		assert.Equal(t, "admin", deployOptions.KeycloakUser)
+		assert.Equal(t, "password", deployOptions.KeycloakPassword)
+	})
+
+	t.Run("fail case - TLS secret without a key", func(t *testing.T) {
+		client := fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "keycloak-tls", Namespace: MockCodewind.Namespace},
+			Data:       map[string][]byte{corev1.TLSCertKey: []byte("cert")},
+		})
+		deployOptions := DeployOptions{KeycloakTLSSecret: "keycloak-tls", Timeout: 50 * time.Millisecond}
+		remInstErr := resolveExistingSecrets(context.Background(), client, MockCodewind.Namespace, &deployOptions)
+		assert.Equal(t, errOpExistingSecret, remInstErr.Op)
+		assert.Contains(t, remInstErr.Desc, errSecretNotReady+": keycloak-tls")
+	})
+
+	t.Run("fail case - secret not created", func(t *testing.T) {
+		deployOptions := DeployOptions{KeycloakAdminSecret: "keycloak-admin", Timeout: 50 * time.Millisecond}
+		remInstErr := resolveExistingSecrets(context.Background(), fake.NewSimpleClientset(), MockCodewind.Namespace, &deployOptions)
+		assert.Equal(t, errOpExistingSecret, remInstErr.Op)
+	})
+}
+
+func TestExistingSecretReferences(t *testing.T) {
+	codewind := MockCodewind
+	codewind.KeycloakAdminSecret = "keycloak-admin"
+	codewind.GatekeeperTLSSecret = "codewind-tls"
+	codewind.KeycloakTLSSecret = "keycloak-tls"
+
+	t.Run("success case - components read existing secrets", func(t *testing.T) {
+		gatekeeper := generateGatekeeperDeploy(codewind, &DeployOptions{})
+		assert.Equal(t, "codewind-tls", gatekeeper.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+		assert.Equal(t, "codewind-tls", generateIngressGatekeeper(codewind).Spec.TLS[0].SecretName)
+		assert.Equal(t, "keycloak-tls", generateIngressKeycloak(codewind).Spec.TLS[0].SecretName)
+		keycloak := generateKeycloakDeploy(codewind)
+		assert.Equal(t, "keycloak-admin", containerEnvSecret(&keycloak, "KEYCLOAK_USER"))
+		assert.Equal(t, "keycloak-admin", containerEnvSecret(&keycloak, "KEYCLOAK_PASSWORD"))
+	})
+
+	t.Run("success case - components read generated secrets by default", func(t *testing.T) {
+		gatekeeper := generateGatekeeperDeploy(MockCodewind, &DeployOptions{})
+		assert.Equal(t, "secret-codewind-tls-"+MockCodewind.WorkspaceID, gatekeeper.Spec.Template.Spec.Volumes[0].Secret.SecretName)
+		keycloak := generateKeycloakDeploy(MockCodewind)
+		assert.Equal(t, "secret-keycloak-user-"+MockCodewind.WorkspaceID, containerEnvSecret(&keycloak, "KEYCLOAK_PASSWORD"))
+	})
+}
@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// ValidateIngressHosts returns an error when the Gatekeeper or Keycloak hostname or the wildcard domain is not a valid
+// DNS name, or the wildcard domain is given with a different ingress domain
+func ValidateIngressHosts(deployOptions *DeployOptions) error {
+	hosts := []string{deployOptions.GatekeeperHost, deployOptions.WildcardDomain}
+	// The Keycloak host of an existing Keycloak is read from its URL
+	if deployOptions.KeycloakURL == "" {
+		hosts = append(hosts, deployOptions.KeycloakHost)
+	}
+	for _, host := range hosts {
+		if host != "" && len(validation.IsDNS1123Subdomain(host)) > 0 {
+			return i18n.Errorf(msgBadIngressHost, errBadIngressHost+": %s", host)
+		}
+	}
+	if deployOptions.WildcardDomain != "" && deployOptions.IngressDomain != "" && deployOptions.WildcardDomain != deployOptions.IngressDomain {
+		return i18n.Errorf(msgIngressDomainConflict, errIngressDomainConflict)
+	}
+	return nil
+}
+
+// resolveIngressDomain returns the domain the hostnames of an install are derived from, the wildcard domain when one
+// is given
+func resolveIngressDomain(deployOptions *DeployOptions) string {
+	if deployOptions.WildcardDomain != "" {
+		return deployOptions.WildcardDomain
+	}
+	return deployOptions.IngressDomain
+}
+
+// certificateDNSNames returns the names a certificate for a host is issued for. Hosts under the wildcard domain are
+// also issued the wildcard of the domain, so that the certificate stays valid for other hosts routed to the domain.
+func (codewind Codewind) certificateDNSNames(host string) []string {
+	dnsNames := []string{host}
+	if codewind.WildcardDomain != "" && strings.HasSuffix(host, "."+codewind.WildcardDomain) {
+		dnsNames = append(dnsNames, "*."+codewind.WildcardDomain)
+	}
+	return dnsNames
+}
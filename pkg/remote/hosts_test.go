@@ -0,0 +1,80 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestValidateIngressHosts(t *testing.T) {
+	tests := map[string]struct {
+		options DeployOptions
+		want    string
+	}{
+		"derived hosts": {
+			options: DeployOptions{IngressDomain: "10.0.0.1.nip.io"},
+		},
+		"explicit hosts under a wildcard domain": {
+			options: DeployOptions{GatekeeperHost: "codewind.apps.example.com", KeycloakHost: "auth.apps.example.com", WildcardDomain: "apps.example.com"},
+		},
+		"host of an existing Keycloak is not checked": {
+			options: DeployOptions{KeycloakURL: "https://auth.example.com", KeycloakHost: "auth.example.com:8443"},
+		},
+		"invalid Keycloak host": {
+			options: DeployOptions{KeycloakHost: "https://auth.example.com"},
+			want:    errBadIngressHost,
+		},
+		"wildcard domain with the wildcard": {
+			options: DeployOptions{WildcardDomain: "*.apps.example.com"},
+			want:    errBadIngressHost,
+		},
+		"wildcard domain with a different ingress domain": {
+			options: DeployOptions{WildcardDomain: "apps.example.com", IngressDomain: "10.0.0.1.nip.io"},
+			want:    errIngressDomainConflict,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateIngressHosts(&test.options)
+			if test.want == "" {
+				assert.Nil(t, err)
+			} else {
+				assert.Contains(t, err.Error(), test.want)
+			}
+		})
+	}
+}
+
+func TestCertificateDNSNames(t *testing.T) {
+	codewind := MockCodewind
+	codewind.WildcardDomain = "apps.example.com"
+
+	t.Run("success case - hosts under the wildcard domain are also issued its wildcard", func(t *testing.T) {
+		assert.Equal(t, []string{"auth.apps.example.com", "*.apps.example.com"}, codewind.certificateDNSNames("auth.apps.example.com"))
+
+		deployOptions := DeployOptions{CertIssuer: "letsencrypt"}
+		codewind.KeycloakHost = "auth.apps.example.com"
+		certificate := generateKeycloakCertificate(codewind, &deployOptions)
+		commonName, _, _ := unstructured.NestedString(certificate.Object, "spec", "commonName")
+		assert.Equal(t, "auth.apps.example.com", commonName)
+		dnsNames, _, _ := unstructured.NestedStringSlice(certificate.Object, "spec", "dnsNames")
+		assert.Equal(t, []string{"auth.apps.example.com", "*.apps.example.com"}, dnsNames)
+	})
+
+	t.Run("success case - hosts outside the wildcard domain are issued only their name", func(t *testing.T) {
+		assert.Equal(t, []string{"codewind.example.com"}, codewind.certificateDNSNames("codewind.example.com"))
+		assert.Equal(t, []string{"codewind.example.com"}, MockCodewind.certificateDNSNames("codewind.example.com"))
+	})
+}
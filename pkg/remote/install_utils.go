@@ -26,11 +26,66 @@ const (
 	errOpNotFound        = "rem_not_found"
 	errOpNoIngress       = "rem_no_ingress"
 	errOpCreateNamespace = "rem_create_namespace"
+	errOpScale           = "rem_scale"
+	errOpLogs            = "rem_logs"
+	errOpBackup          = "rem_backup"
+	errOpKeycloakShared  = "rem_keycloak_shared"
+	errOpReadyTimeout    = "rem_ready_timeout"
+	errOpStorageClass    = "rem_storage_class"
+	errOpPortForward     = "rem_port_forward"
+	errOpRelabel         = "rem_relabel"
+	errOpMixedVersions   = "rem_mixed_versions"
+	errOpRotate          = "rem_rotate_secrets"
+	errOpExistingSecret  = "rem_existing_secret"
+	errOpCancelled       = "rem_cancelled"
+	errOpPreflight       = "rem_preflight"
+	errOpIngressHost     = "rem_ingress_host"
+	errOpLoadTestJob     = "rem_loadtest_job"
 )
 
 const (
-	errTargetNotFound   = "Target deployment not found"
-	errNoIngressService = "Please check you have installed ingress-nginx into your Kubernetes environment or use the --ingress flag to set the domain"
+	errTargetNotFound         = "Target deployment not found"
+	errBadResourceSetting     = "Resource settings must be of the form requests.cpu=<quantity>, requests.memory=<quantity>, limits.cpu=<quantity> or limits.memory=<quantity>"
+	errBackupPodNotReady      = "Backup helper pod did not start"
+	errKeycloakShared         = "Keycloak is still in use by other Codewind workspaces, remove them first or use --force"
+	errReadyTimeout           = "Timed out waiting for deployment to be ready"
+	errBadAnnotation          = "Ingress annotations must be of the form key=value"
+	errBadReplicas            = "Replicas must not be negative"
+	errBadNodeSelector        = "Node selectors must be of the form key=value"
+	errBadToleration          = "Tolerations must be of the form key=value:effect or key:effect, where effect is NoSchedule, PreferNoSchedule or NoExecute"
+	errBadAffinity            = "Unable to read affinity file"
+	errBadPodSecurity         = "Pod security settings must be of the form key=value, where key is runAsNonRoot, runAsUser, fsGroup, seccompProfile, drop, privileged or allowPrivilegeEscalation"
+	errBadPodSecurityProfile  = "Pod security profile must be restricted"
+	errBadNamespaceLabel      = "Namespace labels must be of the form key=value, with a valid label key and value"
+	errBadNamespaceAnnotation = "Namespace annotations must be of the form key=value, with a valid annotation key"
+	errBadDeployConfig        = "Invalid deployment config"
+	errNoStorageClass         = "Storage class not found"
+	errNoDefaultStorage       = "The cluster has no default storage class, use --storage-class to choose one"
+	errUnknownComponent       = "Component must be one of pfe, performance, gatekeeper or keycloak"
+	errPodNotRunning          = "Timed out waiting for pod to be running"
+	errMixedVersions          = "Codewind components must be the same version, use --tag to pin them or --allow-mixed-versions to deploy them anyway. Found tags"
+	errNoKeycloakAdmin        = "Keycloak admin credentials not found, use --kadminuser and --kadminpass as Keycloak is not in the workspace"
+	errKeycloakNotInWorkspace = "The Keycloak admin password can only be rotated when Keycloak is in the workspace"
+	errExistingAdminSecret    = "The Keycloak admin password is in an existing secret managed outside Codewind, rotate it there"
+	errBadSecretName          = "Secret names must be valid Kubernetes resource names"
+	errExistingSecretConflict = "Existing secrets cannot be combined with the options they replace"
+	errSecretNotReady         = "Timed out waiting for existing secret"
+	errInstallCancelled       = "Install cancelled"
+	errPreflightFailed        = "Preflight checks failed, nothing was created"
+	errBadIngressHost         = "Ingress hostnames and the wildcard domain must be valid DNS names"
+	errIngressDomainConflict  = "The wildcard domain replaces the ingress domain, set only one of them"
+	errBadLoadTestJobName     = "Schedule names must be lowercase letters, digits and hyphens, starting and ending with a letter or digit, and short enough to name a CronJob"
+	errNoConnectionWorkspace  = "No Codewind workspace in the cluster has the Gatekeeper of the connection"
+	errNoIngressService       = "Please check you have installed ingress-nginx into your Kubernetes environment or use the --ingress flag to set the domain"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgPreflightFailed       = "remote.preflight_failed"
+	msgBadIngressHost        = "remote.bad_ingress_host"
+	msgIngressDomainConflict = "remote.ingress_domain_conflict"
+	msgBadLoadTestJobName    = "remote.bad_load_test_job_name"
+	msgNoConnectionWorkspace = "remote.no_connection_workspace"
 )
 
 // RemInstError : Error formatted in JSON containing an errorOp and a description from
@@ -12,8 +12,11 @@
 package kube
 
 import (
+	"io/ioutil"
 	"os"
+	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/globals"
 	log "github.com/sirupsen/logrus"
 
 	"k8s.io/client-go/discovery"
@@ -21,6 +24,25 @@ import (
 	"k8s.io/client-go/tools/clientcmd"
 )
 
+// serviceAccountNamespaceFile holds the namespace of the pod when running inside a cluster
+var serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// UseInClusterConfig reports whether to use the service account of the pod cwctl is running in. This is the case when
+// requested with --in-cluster, or when running in a pod (CI pipelines, operators) with no kubeconfig available.
+func UseInClusterConfig() bool {
+	if globals.UseInClusterConfig {
+		return true
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") == "" || os.Getenv("KUBERNETES_SERVICE_PORT") == "" {
+		return false
+	}
+	if kubeconfig := os.Getenv("KUBECONFIG"); kubeconfig != "" {
+		return false
+	}
+	_, err := os.Stat(clientcmd.RecommendedHomeFile)
+	return os.IsNotExist(err)
+}
+
 // GetKubeClientConfig retrieves the Kubernetes client config from the cluster
 func GetKubeClientConfig() clientcmd.ClientConfig {
 	// Retrieve the Kube client config
@@ -33,6 +55,14 @@ func GetKubeClientConfig() clientcmd.ClientConfig {
 
 // GetCurrentNamespace gets the current namespace in the Kubernetes context
 func GetCurrentNamespace() string {
+	if UseInClusterConfig() {
+		namespace, err := ioutil.ReadFile(serviceAccountNamespaceFile)
+		if err == nil {
+			return strings.TrimSpace(string(namespace))
+		}
+		log.Warnf("Unable to read the in-cluster namespace: %v\n", err)
+	}
+
 	// Instantiate loader for kubeconfig file.
 	kubeconfig := GetKubeClientConfig()
 	namespace, _, err := kubeconfig.Namespace()
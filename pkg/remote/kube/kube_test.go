@@ -0,0 +1,74 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package kube
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func setEnv(t *testing.T, envVars map[string]string) func() {
+	t.Helper()
+	saved := map[string]string{}
+	for name, value := range envVars {
+		saved[name] = os.Getenv(name)
+		os.Setenv(name, value)
+	}
+	return func() {
+		for name, value := range saved {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+func TestUseInClusterConfig(t *testing.T) {
+	t.Run("success case - --in-cluster flag forces in-cluster config", func(t *testing.T) {
+		globals.SetUseInClusterConfig(true)
+		defer globals.SetUseInClusterConfig(false)
+		assert.True(t, UseInClusterConfig())
+	})
+
+	t.Run("success case - not running in a pod", func(t *testing.T) {
+		reset := setEnv(t, map[string]string{"KUBERNETES_SERVICE_HOST": "", "KUBERNETES_SERVICE_PORT": ""})
+		defer reset()
+		assert.False(t, UseInClusterConfig())
+	})
+
+	t.Run("success case - KUBECONFIG takes precedence inside a pod", func(t *testing.T) {
+		reset := setEnv(t, map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1", "KUBERNETES_SERVICE_PORT": "443", "KUBECONFIG": "/tmp/kubeconfig"})
+		defer reset()
+		assert.False(t, UseInClusterConfig())
+	})
+}
+
+func TestGetCurrentNamespaceInCluster(t *testing.T) {
+	dir, err := ioutil.TempDir("", "kube")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	namespaceFile := filepath.Join(dir, "namespace")
+	ioutil.WriteFile(namespaceFile, []byte("codewind-ci\n"), 0644)
+
+	savedNamespaceFile := serviceAccountNamespaceFile
+	serviceAccountNamespaceFile = namespaceFile
+	defer func() { serviceAccountNamespaceFile = savedNamespaceFile }()
+
+	globals.SetUseInClusterConfig(true)
+	defer globals.SetUseInClusterConfig(false)
+
+	assert.Equal(t, "codewind-ci", GetCurrentNamespace())
+}
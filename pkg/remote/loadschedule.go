@@ -0,0 +1,255 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+)
+
+// loadTestScript starts a load run of a project from inside its workspace, first writing the load test config when
+// one is given. PFE serves a self-signed certificate on its service.
+const loadTestScript = `set -e
+if [ -n "$LOADTEST_CONFIG" ]; then
+  curl -sSfk -X POST -H 'Content-Type: application/json' -d "$LOADTEST_CONFIG" "$PROJECT_URL/loadtest/config"
+fi
+curl -sSfk -X POST -H 'Content-Type: application/json' -d "$LOADTEST_RUN" "$PROJECT_URL/loadtest"
+`
+
+// loadTestJobHistory is how many finished jobs of each outcome a load test CronJob keeps
+const loadTestJobHistory = int32(3)
+
+// LoadTestCronJobOptions : A CronJob starting load runs of a project in the workspace of a connection
+type LoadTestCronJobOptions struct {
+	GatekeeperURL string // URL of the connection, used to find its workspace
+	Name          string
+	ProjectID     string
+	Schedule      string // cron schedule, in the time zone of the cluster
+	Description   string
+	Config        []byte // load test config written before each run, empty to use the config of the project
+}
+
+// LoadTestCronJob : Where a load test CronJob was created
+type LoadTestCronJob struct {
+	Namespace   string `json:"namespace"`
+	WorkspaceID string `json:"workspaceID"`
+	Name        string `json:"name"`
+}
+
+// createCronJob creates a CronJob with the seccomp profile of its security settings, posting it as JSON in the same
+// way as createDeployment. Replaced in tests, as the fake clientset has no REST client to post with.
+var createCronJob = func(clientset kubernetes.Interface, cronJob *batchv1beta1.CronJob, security PodSecurity) error {
+	if security.SeccompProfile == "" {
+		_, err := clientset.BatchV1beta1().CronJobs(cronJob.GetNamespace()).Create(cronJob)
+		return err
+	}
+	body, err := withSeccompProfile(cronJob, security.SeccompProfile, "spec", "jobTemplate", "spec", "template", "spec")
+	if err != nil {
+		return err
+	}
+	return clientset.BatchV1beta1().RESTClient().Post().
+		Namespace(cronJob.GetNamespace()).
+		Resource("cronjobs").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do().
+		Error()
+}
+
+// loadTestPodSecurity returns the security settings of load test pods. They only call PFE, so they meet the
+// restricted profile whatever the profile of the install.
+func loadTestPodSecurity() PodSecurity {
+	user := LoadTestUser
+	return resolvePodSecurity(PodSecurityRestricted, PodSecurity{RunAsUser: &user})
+}
+
+// CreateLoadTestCronJob : Creates a CronJob in the workspace whose Gatekeeper is at the URL of a connection, which
+// starts load runs of a project on a schedule. The CronJob runs a small curl image with restricted pod security and
+// calls PFE on its service, so that no credentials are stored in the cluster. PFE keeps the results of each run,
+// which the daemon archives locally for comparison.
+func CreateLoadTestCronJob(options *LoadTestCronJobOptions, clientset kubernetes.Interface) (*LoadTestCronJob, *RemInstError) {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	discovered, remInstErr := DiscoverDeployments("", client.clientset, map[string]string{options.GatekeeperURL: "connection"})
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	var workspace *DiscoveredDeployment
+	for i := range discovered {
+		if discovered[i].ConnectionID != "" {
+			workspace = &discovered[i]
+		}
+	}
+	if workspace == nil {
+		err := i18n.Errorf(msgNoConnectionWorkspace, errNoConnectionWorkspace)
+		return nil, &RemInstError{errOpNotFound, err, err.Error() + ": " + options.GatekeeperURL}
+	}
+
+	cronJob := LoadTestCronJob{
+		Namespace:   workspace.Namespace,
+		WorkspaceID: workspace.WorkspaceID,
+		Name:        LoadTestPrefix + "-" + workspace.WorkspaceID + "-" + options.Name,
+	}
+	// Jobs are named after their CronJob with a suffix, so CronJob names are limited to 52 characters
+	if len(validation.IsDNS1123Label(options.Name)) > 0 || len(cronJob.Name) > 52 {
+		err := i18n.Errorf(msgBadLoadTestJobName, errBadLoadTestJobName)
+		return nil, &RemInstError{errOpLoadTestJob, err, err.Error() + ": " + options.Name}
+	}
+
+	pfeDeployments, err := client.clientset.AppsV1().Deployments(cronJob.Namespace).List(v1.ListOptions{
+		LabelSelector: "app=" + PFEPrefix + ",codewindWorkspace=" + cronJob.WorkspaceID,
+	})
+	if err != nil {
+		return nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if len(pfeDeployments.Items) == 0 || len(pfeDeployments.Items[0].Spec.Template.Spec.Containers) == 0 {
+		err = errors.New(errTargetNotFound)
+		return nil, &RemInstError{errOpNotFound, err, err.Error() + ": codewindWorkspace=" + cronJob.WorkspaceID}
+	}
+
+	security := loadTestPodSecurity()
+	job := generateLoadTestCronJob(pfeDeployments.Items[0], cronJob, options, security)
+	logr.Infof("Creating load test CronJob '%v' in namespace %v\n", cronJob.Name, cronJob.Namespace)
+	err = createCronJob(client.clientset, &job, security)
+	if err != nil {
+		return nil, &RemInstError{errOpLoadTestJob, err, err.Error()}
+	}
+	return &cronJob, nil
+}
+
+// DeleteLoadTestCronJob : Removes a load test CronJob and its jobs, succeeding when it has already been removed
+func DeleteLoadTestCronJob(namespace string, name string, clientset kubernetes.Interface) *RemInstError {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	propagation := v1.DeletePropagationBackground
+	err := client.clientset.BatchV1beta1().CronJobs(namespace).Delete(name, &v1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !k8serrors.IsNotFound(err) {
+		return &RemInstError{errOpLoadTestJob, err, err.Error()}
+	}
+	return nil
+}
+
+// LoadTestCronJobLastRun : Returns when the latest successful job of a load test CronJob finished, which is when
+// it started a load run on PFE, or the zero time when none has
+func LoadTestCronJobLastRun(namespace string, name string, clientset kubernetes.Interface) (time.Time, *RemInstError) {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return time.Time{}, remInstErr
+	}
+	jobs, err := client.clientset.BatchV1().Jobs(namespace).List(v1.ListOptions{LabelSelector: "app=" + LoadTestPrefix})
+	if err != nil {
+		return time.Time{}, &RemInstError{errOpLoadTestJob, err, err.Error()}
+	}
+	lastRun := time.Time{}
+	for _, job := range jobs.Items {
+		if job.Status.Succeeded == 0 || job.Status.CompletionTime == nil || !ownedByCronJob(job, name) {
+			continue
+		}
+		if job.Status.CompletionTime.Time.After(lastRun) {
+			lastRun = job.Status.CompletionTime.Time
+		}
+	}
+	return lastRun, nil
+}
+
+// ownedByCronJob reports whether a job was started by the CronJob of a name
+func ownedByCronJob(job batchv1.Job, name string) bool {
+	for _, owner := range job.GetOwnerReferences() {
+		if owner.Kind == "CronJob" && owner.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// generateLoadTestCronJob returns the CronJob starting load runs of a project, labelled as part of the workspace and
+// install of its PFE so that it is removed with them
+func generateLoadTestCronJob(pfe appsv1.Deployment, cronJob LoadTestCronJob, options *LoadTestCronJobOptions, security PodSecurity) batchv1beta1.CronJob {
+	labels := map[string]string{
+		"app":               LoadTestPrefix,
+		"codewindWorkspace": cronJob.WorkspaceID,
+		projectIDLabel:      options.ProjectID,
+	}
+	if installID := pfe.GetLabels()[InstallIDLabel]; installID != "" {
+		labels[InstallIDLabel] = installID
+	}
+	run := `{"description":` + strconv.Quote(options.Description) + `}`
+	projectURL := "https://" + PFEPrefix + "-" + cronJob.WorkspaceID + ":" + strconv.Itoa(PFEContainerPort) + "/api/v1/projects/" + options.ProjectID
+	backoffLimit := int32(0)
+	history := loadTestJobHistory
+	podSpec := corev1.PodSpec{
+		RestartPolicy:    corev1.RestartPolicyNever,
+		ImagePullSecrets: pfe.Spec.Template.Spec.ImagePullSecrets,
+		NodeSelector:     pfe.Spec.Template.Spec.NodeSelector,
+		Tolerations:      pfe.Spec.Template.Spec.Tolerations,
+		Containers: []corev1.Container{
+			{
+				Name:            LoadTestPrefix,
+				Image:           LoadTestImage,
+				ImagePullPolicy: corev1.PullIfNotPresent,
+				Command:         []string{"/bin/sh", "-c", loadTestScript},
+				Env: []corev1.EnvVar{
+					{Name: "PROJECT_URL", Value: projectURL},
+					{Name: "LOADTEST_CONFIG", Value: string(options.Config)},
+					{Name: "LOADTEST_RUN", Value: run},
+				},
+			},
+		},
+	}
+	setPodSpecSecurity(&podSpec, security)
+
+	return batchv1beta1.CronJob{
+		TypeMeta: v1.TypeMeta{
+			Kind:       "CronJob",
+			APIVersion: "batch/v1beta1",
+		},
+		ObjectMeta: v1.ObjectMeta{
+			Name:      cronJob.Name,
+			Namespace: cronJob.Namespace,
+			Labels:    labels,
+		},
+		Spec: batchv1beta1.CronJobSpec{
+			Schedule: options.Schedule,
+			// A run still going when the next is due is left to finish, as PFE runs one load test at a time
+			ConcurrencyPolicy:          batchv1beta1.ForbidConcurrent,
+			SuccessfulJobsHistoryLimit: &history,
+			FailedJobsHistoryLimit:     &history,
+			JobTemplate: batchv1beta1.JobTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: batchv1.JobSpec{
+					BackoffLimit: &backoffLimit,
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: v1.ObjectMeta{Labels: labels},
+						Spec:       podSpec,
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,155 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCreateLoadTestCronJob(t *testing.T) {
+	pfe := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": "wid1", InstallIDLabel: "install1"},
+	})
+	pfe.Spec.Template.Spec.Containers[0].Image = "eclipse/codewind-pfe-amd64:0.14.0"
+	gatekeeper := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "wid1"},
+		Env:       []corev1.EnvVar{{Name: "GATEKEEPER_HOST", Value: "codewind.example.com"}},
+	})
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{pfe, gatekeeper}})
+	}
+	var createdSecurity PodSecurity
+	originalCreateCronJob := createCronJob
+	createCronJob = func(clientset kubernetes.Interface, cronJob *batchv1beta1.CronJob, security PodSecurity) error {
+		createdSecurity = security
+		_, err := clientset.BatchV1beta1().CronJobs(cronJob.GetNamespace()).Create(cronJob)
+		return err
+	}
+	defer func() { createCronJob = originalCreateCronJob }()
+	options := LoadTestCronJobOptions{
+		GatekeeperURL: "https://codewind.example.com/",
+		Name:          "nightly",
+		ProjectID:     "pid1",
+		Schedule:      "0 2 * * *",
+		Description:   "nightly run",
+		Config:        []byte(`{"concurrency":20}`),
+	}
+
+	t.Run("success case - creates a CronJob in the workspace of the connection", func(t *testing.T) {
+		clientset := newClientset()
+		cronJob, remInstErr := CreateLoadTestCronJob(&options, clientset)
+		assert.Nil(t, remInstErr)
+		assert.Equal(t, LoadTestCronJob{Namespace: "test1", WorkspaceID: "wid1", Name: "codewind-loadtest-wid1-nightly"}, *cronJob)
+
+		created, err := clientset.BatchV1beta1().CronJobs("test1").Get(cronJob.Name, metav1.GetOptions{})
+		assert.Nil(t, err)
+		assert.Equal(t, "0 2 * * *", created.Spec.Schedule)
+		assert.Equal(t, "install1", created.GetLabels()[InstallIDLabel])
+		assert.Equal(t, "pid1", created.GetLabels()[projectIDLabel])
+		container := created.Spec.JobTemplate.Spec.Template.Spec.Containers[0]
+		assert.Equal(t, LoadTestImage, container.Image)
+		assert.Equal(t, []corev1.EnvVar{
+			{Name: "PROJECT_URL", Value: "https://codewind-pfe-wid1:9191/api/v1/projects/pid1"},
+			{Name: "LOADTEST_CONFIG", Value: `{"concurrency":20}`},
+			{Name: "LOADTEST_RUN", Value: `{"description":"nightly run"}`},
+		}, container.Env)
+	})
+
+	t.Run("success case - load test pods meet the restricted profile", func(t *testing.T) {
+		clientset := newClientset()
+		cronJob, _ := CreateLoadTestCronJob(&options, clientset)
+		assert.Equal(t, SeccompRuntimeDefault, createdSecurity.SeccompProfile)
+
+		created, _ := clientset.BatchV1beta1().CronJobs("test1").Get(cronJob.Name, metav1.GetOptions{})
+		podSpec := created.Spec.JobTemplate.Spec.Template.Spec
+		assert.True(t, *podSpec.SecurityContext.RunAsNonRoot)
+		assert.Equal(t, LoadTestUser, *podSpec.SecurityContext.RunAsUser)
+		containerSecurity := podSpec.Containers[0].SecurityContext
+		assert.False(t, *containerSecurity.AllowPrivilegeEscalation)
+		assert.Equal(t, []corev1.Capability{"ALL"}, containerSecurity.Capabilities.Drop)
+
+		body, err := withSeccompProfile(created, SeccompRuntimeDefault, "spec", "jobTemplate", "spec", "template", "spec")
+		assert.Nil(t, err)
+		assert.Contains(t, string(body), `"securityContext":{"runAsNonRoot":true,"runAsUser":100,"seccompProfile":{"type":"RuntimeDefault"}}`)
+	})
+
+	t.Run("success case - deleting a removed CronJob succeeds", func(t *testing.T) {
+		clientset := newClientset()
+		cronJob, _ := CreateLoadTestCronJob(&options, clientset)
+		assert.Nil(t, DeleteLoadTestCronJob(cronJob.Namespace, cronJob.Name, clientset))
+		assert.Nil(t, DeleteLoadTestCronJob(cronJob.Namespace, cronJob.Name, clientset))
+	})
+
+	t.Run("error case - no workspace has the Gatekeeper of the connection", func(t *testing.T) {
+		otherOptions := options
+		otherOptions.GatekeeperURL = "https://other.example.com"
+		_, remInstErr := CreateLoadTestCronJob(&otherOptions, newClientset())
+		assert.Equal(t, errOpNotFound, remInstErr.Op)
+	})
+
+	t.Run("error case - invalid schedule name", func(t *testing.T) {
+		badOptions := options
+		badOptions.Name = "Nightly_Run"
+		_, remInstErr := CreateLoadTestCronJob(&badOptions, newClientset())
+		assert.Equal(t, errOpLoadTestJob, remInstErr.Op)
+	})
+}
+
+func TestLoadTestCronJobLastRun(t *testing.T) {
+	newJob := func(name string, owner string, succeeded int32, completed time.Time) *batchv1.Job {
+		job := &batchv1.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            name,
+				Namespace:       "test1",
+				Labels:          map[string]string{"app": LoadTestPrefix},
+				OwnerReferences: []metav1.OwnerReference{{Kind: "CronJob", Name: owner}},
+			},
+			Status: batchv1.JobStatus{Succeeded: succeeded},
+		}
+		if !completed.IsZero() {
+			job.Status.CompletionTime = &metav1.Time{Time: completed}
+		}
+		return job
+	}
+	first := time.Date(2020, time.April, 15, 2, 0, 5, 0, time.UTC)
+	second := time.Date(2020, time.April, 16, 2, 0, 5, 0, time.UTC)
+
+	t.Run("success case - returns when the latest successful job of the CronJob finished", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(
+			newJob("nightly-1", "nightly", 1, first),
+			newJob("nightly-2", "nightly", 1, second),
+			newJob("nightly-3", "nightly", 0, time.Time{}),
+			newJob("weekly-1", "weekly", 1, second.Add(time.Hour)),
+		)
+		lastRun, remInstErr := LoadTestCronJobLastRun("test1", "nightly", clientset)
+		assert.Nil(t, remInstErr)
+		assert.True(t, second.Equal(lastRun))
+	})
+
+	t.Run("success case - a CronJob without finished jobs has not run", func(t *testing.T) {
+		lastRun, remInstErr := LoadTestCronJobLastRun("test1", "nightly", fake.NewSimpleClientset())
+		assert.Nil(t, remInstErr)
+		assert.True(t, lastRun.IsZero())
+	})
+}
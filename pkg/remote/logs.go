@@ -0,0 +1,120 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// LogOptions : Pod log options
+type LogOptions struct {
+	Namespace   string
+	WorkspaceID string
+	Component   string
+	Follow      bool
+	Since       time.Duration
+	Tail        int64
+}
+
+// componentPrefixes maps the component names accepted by the CLI to the app label of their resources
+var componentPrefixes = map[string]string{
+	"pfe":         PFEPrefix,
+	"performance": PerformancePrefix,
+	"gatekeeper":  GatekeeperPrefix,
+	"keycloak":    KeycloakPrefix,
+}
+
+// GetComponentPrefix returns the resource prefix for a component name such as pfe or gatekeeper
+func GetComponentPrefix(component string) (string, *RemInstError) {
+	prefix, ok := componentPrefixes[component]
+	if !ok {
+		err := errors.New(errUnknownComponent)
+		return "", &RemInstError{errOpNotFound, err, err.Error() + ": " + component}
+	}
+	return prefix, nil
+}
+
+// StreamLogs writes the logs of the pods running a remote Codewind component to out. When following, only the most
+// recently started pod is streamed, since a component normally runs a single replica.
+func StreamLogs(logOptions *LogOptions, clientset kubernetes.Interface, out io.Writer) *RemInstError {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return remInstErr
+	}
+
+	pods, remInstErr := client.findComponentPods(logOptions.Namespace, logOptions.WorkspaceID, logOptions.Component)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	if logOptions.Follow {
+		pods = pods[:1]
+	}
+
+	podLogOptions := corev1.PodLogOptions{Follow: logOptions.Follow}
+	if logOptions.Since > 0 {
+		sinceSeconds := int64(logOptions.Since.Seconds())
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+	if logOptions.Tail >= 0 {
+		podLogOptions.TailLines = &logOptions.Tail
+	}
+
+	for _, pod := range pods {
+		if len(pods) > 1 {
+			fmt.Fprintf(out, "==> %v <==\n", pod.GetName())
+		}
+		stream, err := client.clientset.CoreV1().Pods(logOptions.Namespace).GetLogs(pod.GetName(), &podLogOptions).Stream()
+		if err != nil {
+			return &RemInstError{errOpLogs, err, err.Error()}
+		}
+		_, err = io.Copy(out, stream)
+		stream.Close()
+		if err != nil {
+			return &RemInstError{errOpLogs, err, err.Error()}
+		}
+	}
+	return nil
+}
+
+// findComponentPods returns the pods of a component in a workspace, most recently created first
+func (client K8sAPI) findComponentPods(namespace string, workspaceID string, component string) ([]corev1.Pod, *RemInstError) {
+	prefix, remInstErr := GetComponentPrefix(component)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+
+	labelSelector := "app=" + prefix + ",codewindWorkspace=" + workspaceID
+	podList, err := client.clientset.CoreV1().Pods(namespace).List(v1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if len(podList.Items) == 0 {
+		err = errors.New(errTargetNotFound)
+		return nil, &RemInstError{errOpNotFound, err, err.Error() + ": " + labelSelector}
+	}
+
+	pods := podList.Items
+	sort.Slice(pods, func(i, j int) bool {
+		return pods[j].ObjectMeta.CreationTimestamp.Before(&pods[i].ObjectMeta.CreationTimestamp)
+	})
+	return pods, nil
+}
@@ -0,0 +1,64 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func generateMockPod(name string, labels map[string]string, created time.Time) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         "test1",
+			Labels:            labels,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+	}
+}
+
+func TestFindComponentPods(t *testing.T) {
+	now := time.Now()
+	gatekeeperLabels := map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID1"}
+	oldPod := generateMockPod("gatekeeper-old", gatekeeperLabels, now.Add(-time.Hour))
+	newPod := generateMockPod("gatekeeper-new", gatekeeperLabels, now)
+	pfePod := generateMockPod("pfe", map[string]string{"app": PFEPrefix, "codewindWorkspace": "WID1"}, now)
+
+	client := K8sAPI{clientset: fake.NewSimpleClientset(&corev1.PodList{Items: []corev1.Pod{oldPod, newPod, pfePod}})}
+
+	t.Run("success case - returns component pods newest first", func(t *testing.T) {
+		pods, err := client.findComponentPods("test1", "WID1", "gatekeeper")
+		assert.Nil(t, err)
+		assert.Len(t, pods, 2)
+		assert.Equal(t, "gatekeeper-new", pods[0].GetName())
+		assert.Equal(t, "gatekeeper-old", pods[1].GetName())
+	})
+
+	t.Run("fail case - no pods for the workspace", func(t *testing.T) {
+		pods, err := client.findComponentPods("test1", "WID2", "pfe")
+		assert.Nil(t, pods)
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+
+	t.Run("fail case - unknown component", func(t *testing.T) {
+		pods, err := client.findComponentPods("test1", "WID1", "database")
+		assert.Nil(t, pods)
+		assert.Equal(t, errOpNotFound, err.Op)
+		assert.Contains(t, err.Desc, errUnknownComponent)
+	})
+}
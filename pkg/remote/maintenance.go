@@ -0,0 +1,93 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"strconv"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaintenanceAnnotation is set on the deployments of a remote Codewind while it is in maintenance mode
+	MaintenanceAnnotation = "codewind.eclipse.org/maintenance"
+	// MaintenanceReplicasAnnotation records the replicas a deployment had when maintenance mode was turned on, so
+	// that turning it off scales the deployment back to them
+	MaintenanceReplicasAnnotation = "codewind.eclipse.org/maintenance-replicas"
+)
+
+// MaintenanceOptions : Maintenance mode options
+type MaintenanceOptions struct {
+	Namespace   string
+	WorkspaceID string
+	Enable      bool
+}
+
+// maintenanceComponents are the components which read or write project data, and so are scaled down during maintenance
+var maintenanceComponents = []string{PFEPrefix, PerformancePrefix}
+
+// SetMaintenanceMode scales the project affecting components of a remote Codewind down to zero replicas, or back up
+// to the replicas they had before, so that admins can safely perform storage operations on the workspace PVC. While
+// PFE is scaled down the Gatekeeper answers requests for it with a 503.
+func SetMaintenanceMode(maintenanceOptions *MaintenanceOptions, clientset kubernetes.Interface) *RemInstError {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return remInstErr
+	}
+
+	for _, component := range maintenanceComponents {
+		labelSelector := "app=" + component + ",codewindWorkspace=" + maintenanceOptions.WorkspaceID
+		remInstErr := client.updateDeployments(maintenanceOptions.Namespace, labelSelector, func(deployment *appsv1.Deployment) {
+			setDeploymentMaintenance(deployment, maintenanceOptions.Enable)
+		})
+		if remInstErr != nil {
+			return remInstErr
+		}
+	}
+	return nil
+}
+
+// setDeploymentMaintenance scales a deployment down for maintenance, recording its replicas, or back up to the
+// replicas recorded, falling back to those it was installed with. Turning maintenance on again keeps the replicas
+// recorded the first time.
+func setDeploymentMaintenance(deployment *appsv1.Deployment, enable bool) {
+	annotations := deployment.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	replicas := int32(0)
+	if enable {
+		if annotations[MaintenanceAnnotation] != "true" {
+			annotations[MaintenanceReplicasAnnotation] = strconv.Itoa(int(deploymentReplicas(deployment)))
+		}
+	} else {
+		replicas = installedReplicas(deployment)
+		if saved, err := strconv.ParseInt(annotations[MaintenanceReplicasAnnotation], 10, 32); err == nil && saved > 0 {
+			replicas = int32(saved)
+		}
+		delete(annotations, MaintenanceReplicasAnnotation)
+	}
+	annotations[MaintenanceAnnotation] = strconv.FormatBool(enable)
+	deployment.SetAnnotations(annotations)
+	deployment.Spec.Replicas = &replicas
+	logr.Infof("Scaling deployment '%v' to %v replicas\n", deployment.GetName(), replicas)
+}
+
+// deploymentReplicas returns the replicas of a deployment, which Kubernetes defaults to one
+func deploymentReplicas(deployment *appsv1.Deployment) int32 {
+	if deployment.Spec.Replicas == nil {
+		return 1
+	}
+	return *deployment.Spec.Replicas
+}
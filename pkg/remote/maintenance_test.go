@@ -0,0 +1,97 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSetMaintenanceMode(t *testing.T) {
+	pfeOptions := MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": "WID1"},
+	}
+	performanceOptions := MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": PerformancePrefix, "codewindWorkspace": "WID1"},
+	}
+	gatekeeperOptions := MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID1"},
+	}
+	setMaintenance := func(clientset *fake.Clientset, enable bool) {
+		err := SetMaintenanceMode(&MaintenanceOptions{Namespace: "test1", WorkspaceID: "WID1", Enable: enable}, clientset)
+		assert.Nil(t, err)
+	}
+	getDeployment := func(clientset *fake.Clientset, options MockDeploymentOptions) *v1.Deployment {
+		name := options.Labels["app"] + "-" + options.Labels["codewindWorkspace"]
+		got, _ := clientset.AppsV1().Deployments("test1").Get(name, metav1.GetOptions{})
+		return got
+	}
+
+	t.Run("success case - maintenance on scales components down and off restores their replicas", func(t *testing.T) {
+		pfeDeployment := generateMockDeployment(pfeOptions)
+		performanceDeployment := generateMockDeployment(performanceOptions)
+		replicas := int32(3)
+		performanceDeployment.Spec.Replicas = &replicas
+		clientset := fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{pfeDeployment, performanceDeployment}})
+
+		setMaintenance(clientset, true)
+		got := getDeployment(clientset, performanceOptions)
+		assert.Equal(t, int32(0), *got.Spec.Replicas)
+		assert.Equal(t, map[string]string{MaintenanceAnnotation: "true", MaintenanceReplicasAnnotation: "3"}, got.GetAnnotations())
+		assert.Equal(t, "1", getDeployment(clientset, pfeOptions).GetAnnotations()[MaintenanceReplicasAnnotation])
+
+		// Turning maintenance on again keeps the replicas recorded before it was first turned on
+		setMaintenance(clientset, true)
+		assert.Equal(t, "3", getDeployment(clientset, performanceOptions).GetAnnotations()[MaintenanceReplicasAnnotation])
+
+		setMaintenance(clientset, false)
+		got = getDeployment(clientset, performanceOptions)
+		assert.Equal(t, int32(3), *got.Spec.Replicas)
+		assert.Equal(t, map[string]string{MaintenanceAnnotation: "false"}, got.GetAnnotations())
+		assert.Equal(t, int32(1), *getDeployment(clientset, pfeOptions).Spec.Replicas)
+	})
+
+	t.Run("success case - maintenance off without recorded replicas scales components to one", func(t *testing.T) {
+		pfeDeployment := generateMockDeployment(pfeOptions)
+		clientset := fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{pfeDeployment, generateMockDeployment(performanceOptions)}})
+		setMaintenance(clientset, false)
+		assert.Equal(t, int32(1), *getDeployment(clientset, pfeOptions).Spec.Replicas)
+	})
+
+	t.Run("success case - maintenance off without recorded replicas scales components to their installed replicas", func(t *testing.T) {
+		performanceDeployment := generateMockDeployment(performanceOptions)
+		performanceDeployment.SetAnnotations(map[string]string{InstalledReplicasAnnotation: "2"})
+		clientset := fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{generateMockDeployment(pfeOptions), performanceDeployment}})
+		setMaintenance(clientset, false)
+		assert.Equal(t, int32(2), *getDeployment(clientset, performanceOptions).Spec.Replicas)
+	})
+
+	t.Run("success case - the Gatekeeper keeps running while maintenance is on", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{generateMockDeployment(pfeOptions), generateMockDeployment(performanceOptions), generateMockDeployment(gatekeeperOptions)}})
+		setMaintenance(clientset, true)
+		assert.Nil(t, getDeployment(clientset, gatekeeperOptions).Spec.Replicas)
+		assert.Empty(t, getDeployment(clientset, gatekeeperOptions).GetAnnotations())
+	})
+
+	t.Run("error case - workspace not found", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		err := SetMaintenanceMode(&MaintenanceOptions{Namespace: "test1", WorkspaceID: "WID1", Enable: true}, clientset)
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
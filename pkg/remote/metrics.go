@@ -0,0 +1,147 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"strconv"
+
+	logr "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	restclient "k8s.io/client-go/rest"
+)
+
+const (
+	// MetricsPath is the path at which PFE and the Gatekeeper serve Prometheus metrics
+	MetricsPath = "/metrics"
+
+	// PrometheusOperatorAPIVersion is the Prometheus Operator API used for ServiceMonitor resources
+	PrometheusOperatorAPIVersion = "monitoring.coreos.com/v1"
+)
+
+// serviceMonitorResource identifies Prometheus Operator ServiceMonitors for the dynamic client
+var serviceMonitorResource = schema.GroupVersionResource{Group: "monitoring.coreos.com", Version: "v1", Resource: "servicemonitors"}
+
+// generateScrapeAnnotations returns the annotations that ask an annotation based Prometheus to scrape a service
+func generateScrapeAnnotations(port int) map[string]string {
+	return map[string]string{
+		"prometheus.io/scrape": "true",
+		"prometheus.io/port":   strconv.Itoa(port),
+		"prometheus.io/path":   MetricsPath,
+		"prometheus.io/scheme": "https",
+	}
+}
+
+// generateServiceMonitor returns a Prometheus Operator ServiceMonitor for the service of the given component.
+// PFE and the Gatekeeper use self-signed certificates on their service ports, so certificate verification is skipped.
+func generateServiceMonitor(codewind Codewind, prefix string) unstructured.Unstructured {
+	labels := map[string]interface{}{
+		"app":               prefix,
+		"codewindWorkspace": codewind.WorkspaceID,
+	}
+	objectLabels := map[string]interface{}{}
+	for key, value := range ownedLabels(codewind, map[string]string{"app": prefix, "codewindWorkspace": codewind.WorkspaceID}) {
+		objectLabels[key] = value
+	}
+	return unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": PrometheusOperatorAPIVersion,
+			"kind":       "ServiceMonitor",
+			"metadata": map[string]interface{}{
+				"name":      prefix + "-" + codewind.WorkspaceID,
+				"namespace": codewind.Namespace,
+				"labels":    objectLabels,
+			},
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": labels,
+				},
+				"endpoints": []interface{}{
+					map[string]interface{}{
+						"port":   prefix + "-http",
+						"path":   MetricsPath,
+						"scheme": "https",
+						"tlsConfig": map[string]interface{}{
+							"insecureSkipVerify": true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// hasServiceMonitors reports whether the Prometheus Operator ServiceMonitor CRD is installed in the cluster
+func hasServiceMonitors(discoveryClient discovery.DiscoveryInterface) bool {
+	resources, err := discoveryClient.ServerResourcesForGroupVersion(PrometheusOperatorAPIVersion)
+	if err != nil || resources == nil {
+		return false
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == serviceMonitorResource.Resource {
+			return true
+		}
+	}
+	return false
+}
+
+// DeployServiceMonitors : Create ServiceMonitors for PFE and the Gatekeeper when the Prometheus Operator is installed
+func DeployServiceMonitors(config *restclient.Config, discoveryClient discovery.DiscoveryInterface, codewindInstance Codewind) error {
+	if !hasServiceMonitors(discoveryClient) {
+		logr.Infoln("Prometheus Operator not found, services are annotated for scraping only")
+		return nil
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return err
+	}
+	for _, prefix := range []string{PFEPrefix, GatekeeperPrefix} {
+		serviceMonitor := generateServiceMonitor(codewindInstance, prefix)
+		logr.Infof("Deploying Codewind ServiceMonitor '%v'\n", serviceMonitor.GetName())
+		_, err = dynamicClient.Resource(serviceMonitorResource).Namespace(codewindInstance.Namespace).Create(&serviceMonitor, metav1.CreateOptions{})
+		if err != nil {
+			logr.Errorf("Error: Unable to create ServiceMonitor '%v': %v\n", serviceMonitor.GetName(), err)
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteServiceMonitors removes the ServiceMonitors matching the label selector
+func deleteServiceMonitors(config *restclient.Config, remoteRemovalOptions *RemoveDeploymentOptions, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return phase, err
+	}
+	serviceMonitors := dynamicClient.Resource(serviceMonitorResource).Namespace(remoteRemovalOptions.Namespace)
+	serviceMonitorList, err := serviceMonitors.List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		// The Prometheus Operator is not installed, so there is nothing to remove
+		return phase, err
+	}
+	if serviceMonitorList != nil && len(serviceMonitorList.Items) > 0 {
+		phase = ResourceFound
+		for _, serviceMonitor := range serviceMonitorList.Items {
+			err := serviceMonitors.Delete(serviceMonitor.GetName(), nil)
+			if err != nil {
+				phase = ResourceRemoveFailed
+			} else {
+				phase = ResourceRemoved
+			}
+		}
+	}
+	return phase, nil
+}
@@ -0,0 +1,64 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGenerateServiceMonitor(t *testing.T) {
+	serviceMonitor := generateServiceMonitor(MockCodewind, PFEPrefix)
+	assert.Equal(t, "ServiceMonitor", serviceMonitor.GetKind())
+	assert.Equal(t, PFEPrefix+"-"+MockCodewind.WorkspaceID, serviceMonitor.GetName())
+
+	matchLabels, _, _ := unstructured.NestedStringMap(serviceMonitor.Object, "spec", "selector", "matchLabels")
+	assert.Equal(t, map[string]string{"app": PFEPrefix, "codewindWorkspace": MockCodewind.WorkspaceID}, matchLabels)
+
+	endpoints, _, _ := unstructured.NestedSlice(serviceMonitor.Object, "spec", "endpoints")
+	endpoint := endpoints[0].(map[string]interface{})
+	assert.Equal(t, PFEPrefix+"-http", endpoint["port"])
+	assert.Equal(t, MetricsPath, endpoint["path"])
+}
+
+func TestScrapeAnnotations(t *testing.T) {
+	codewind := MockCodewind
+	t.Run("success case - services are annotated when metrics are enabled", func(t *testing.T) {
+		codewind.Metrics = true
+		assert.Equal(t, "9191", generatePFEService(codewind).ObjectMeta.Annotations["prometheus.io/port"])
+		assert.Equal(t, "true", generateGatekeeperService(codewind).ObjectMeta.Annotations["prometheus.io/scrape"])
+	})
+
+	t.Run("success case - services are not annotated by default", func(t *testing.T) {
+		codewind.Metrics = false
+		assert.Empty(t, generatePFEService(codewind).ObjectMeta.Annotations)
+	})
+}
+
+func TestHasServiceMonitors(t *testing.T) {
+	t.Run("success case - Prometheus Operator installed", func(t *testing.T) {
+		discoveryClient := fake.NewSimpleClientset().Discovery().(*fakediscovery.FakeDiscovery)
+		discoveryClient.Resources = []*metav1.APIResourceList{
+			{GroupVersion: PrometheusOperatorAPIVersion, APIResources: []metav1.APIResource{{Name: "servicemonitors"}, {Name: "podmonitors"}}},
+		}
+		assert.True(t, hasServiceMonitors(discoveryClient))
+	})
+
+	t.Run("success case - Prometheus Operator not installed", func(t *testing.T) {
+		assert.False(t, hasServiceMonitors(fake.NewSimpleClientset().Discovery()))
+	})
+}
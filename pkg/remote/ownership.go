@@ -0,0 +1,109 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Ownership labels, set on every resource an install creates so that they can be found and removed together
+const (
+	// InstallIDLabel : Label holding the ID of the install that created a resource
+	InstallIDLabel = "codewind.eclipse.org/install-id"
+	// CwctlVersionLabel : Label holding the version of cwctl that created a resource
+	CwctlVersionLabel = "codewind.eclipse.org/cwctl-version"
+	// InstalledAtLabel : Label holding the UNIX time at which the install that created a resource started
+	InstalledAtLabel = "codewind.eclipse.org/installed-at"
+)
+
+// generateOwnerLabels returns the ownership labels of a new install
+func generateOwnerLabels(installedAt time.Time) map[string]string {
+	return map[string]string{
+		InstallIDLabel:    string(uuid.NewUUID()),
+		CwctlVersionLabel: appconstants.VersionNum,
+		InstalledAtLabel:  strconv.FormatInt(installedAt.Unix(), 10),
+	}
+}
+
+// ownedLabels returns the labels of a resource with the ownership labels of the install added. Selectors keep using
+// the labels alone, so that the resources of installs made before ownership labels still match.
+func ownedLabels(codewind Codewind, labels map[string]string) map[string]string {
+	if len(codewind.OwnerLabels) == 0 {
+		return labels
+	}
+	owned := map[string]string{}
+	for key, value := range labels {
+		owned[key] = value
+	}
+	for key, value := range codewind.OwnerLabels {
+		owned[key] = value
+	}
+	return owned
+}
+
+// generateNamespace returns the namespace to install into, with the labels and annotations given at install added to
+// the ownership labels
+func generateNamespace(name string, ownerLabels map[string]string, labels map[string]string, annotations map[string]string) corev1.Namespace {
+	namespaceLabels := map[string]string{}
+	for key, value := range ownerLabels {
+		namespaceLabels[key] = value
+	}
+	for key, value := range labels {
+		namespaceLabels[key] = value
+	}
+	return corev1.Namespace{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Namespace",
+			APIVersion: "v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Labels:      namespaceLabels,
+			Annotations: annotations,
+		},
+	}
+}
+
+// ParseNamespaceLabels converts a list of key=value strings into namespace labels, checking each is a valid label
+func ParseNamespaceLabels(keyValues []string) (map[string]string, error) {
+	labels := map[string]string{}
+	for _, keyValue := range keyValues {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 || len(validation.IsQualifiedName(parts[0])) > 0 || len(validation.IsValidLabelValue(parts[1])) > 0 {
+			return nil, errors.New(errBadNamespaceLabel + ": " + keyValue)
+		}
+		labels[parts[0]] = parts[1]
+	}
+	return labels, nil
+}
+
+// ParseNamespaceAnnotations converts a list of key=value strings into namespace annotations
+func ParseNamespaceAnnotations(keyValues []string) (map[string]string, error) {
+	annotations := map[string]string{}
+	for _, keyValue := range keyValues {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 || len(validation.IsQualifiedName(parts[0])) > 0 {
+			return nil, errors.New(errBadNamespaceAnnotation + ": " + keyValue)
+		}
+		annotations[parts[0]] = parts[1]
+	}
+	return annotations, nil
+}
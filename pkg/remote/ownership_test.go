@@ -0,0 +1,120 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestOwnerLabels(t *testing.T) {
+	codewind := MockCodewind
+	codewind.OwnerLabels = generateOwnerLabels(time.Unix(1600000000, 0))
+
+	t.Run("success case - ownership labels identify the install", func(t *testing.T) {
+		assert.NotEmpty(t, codewind.OwnerLabels[InstallIDLabel])
+		assert.Equal(t, appconstants.VersionNum, codewind.OwnerLabels[CwctlVersionLabel])
+		assert.Equal(t, "1600000000", codewind.OwnerLabels[InstalledAtLabel])
+		assert.NotEqual(t, codewind.OwnerLabels[InstallIDLabel], generateOwnerLabels(time.Now())[InstallIDLabel])
+	})
+
+	t.Run("success case - created resources carry the ownership labels but selectors do not", func(t *testing.T) {
+		deployment := generatePFEDeploy(codewind, &DeployOptions{})
+		assert.Equal(t, codewind.OwnerLabels[InstallIDLabel], deployment.GetLabels()[InstallIDLabel])
+		assert.Equal(t, PFEPrefix, deployment.GetLabels()["app"])
+		assert.NotContains(t, deployment.Spec.Selector.MatchLabels, InstallIDLabel)
+
+		service := generateGatekeeperService(codewind)
+		assert.Equal(t, codewind.OwnerLabels[InstallIDLabel], service.GetLabels()[InstallIDLabel])
+		assert.NotContains(t, service.Spec.Selector, InstallIDLabel)
+
+		monitor := generateServiceMonitor(codewind, PFEPrefix)
+		assert.Equal(t, codewind.OwnerLabels[InstallIDLabel], monitor.GetLabels()[InstallIDLabel])
+
+		bindings := CreateCodewindTektonClusterRoleBindings(codewind, &DeployOptions{}, "binding")
+		assert.Equal(t, codewind.OwnerLabels[InstallIDLabel], bindings.GetLabels()[InstallIDLabel])
+	})
+
+	t.Run("success case - installs without ownership labels keep their labels", func(t *testing.T) {
+		labels := map[string]string{"app": PFEPrefix}
+		assert.Equal(t, labels, ownedLabels(MockCodewind, labels))
+	})
+}
+
+func TestGenerateNamespace(t *testing.T) {
+	namespace := generateNamespace("codewind", map[string]string{InstallIDLabel: "id"}, map[string]string{"pod-security.kubernetes.io/enforce": "restricted"}, map[string]string{"owner": "dev team"})
+	assert.Equal(t, "codewind", namespace.GetName())
+	assert.Equal(t, map[string]string{InstallIDLabel: "id", "pod-security.kubernetes.io/enforce": "restricted"}, namespace.GetLabels())
+	assert.Equal(t, map[string]string{"owner": "dev team"}, namespace.GetAnnotations())
+}
+
+func TestParseNamespaceLabels(t *testing.T) {
+	t.Run("success case - parses labels and annotations", func(t *testing.T) {
+		labels, err := ParseNamespaceLabels([]string{"team=devtools", "pod-security.kubernetes.io/enforce=restricted"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"team": "devtools", "pod-security.kubernetes.io/enforce": "restricted"}, labels)
+
+		annotations, err := ParseNamespaceAnnotations([]string{"description=Shared Codewind for the dev team"})
+		assert.Nil(t, err)
+		assert.Equal(t, "Shared Codewind for the dev team", annotations["description"])
+	})
+
+	t.Run("error case - label values must be valid", func(t *testing.T) {
+		_, err := ParseNamespaceLabels([]string{"team=dev tools"})
+		assert.Contains(t, err.Error(), errBadNamespaceLabel)
+		_, err = ParseNamespaceLabels([]string{"team"})
+		assert.Contains(t, err.Error(), errBadNamespaceLabel)
+	})
+
+	t.Run("error case - annotation keys must be valid", func(t *testing.T) {
+		_, err := ParseNamespaceAnnotations([]string{"bad key=value"})
+		assert.Contains(t, err.Error(), errBadNamespaceAnnotation)
+	})
+}
+
+func TestFindInstallWorkspace(t *testing.T) {
+	deployment := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "codewind",
+		Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": "k39vwfk0", InstallIDLabel: "install1"},
+	})
+	clientset := fake.NewSimpleClientset(&deployment)
+
+	assert.Equal(t, "k39vwfk0", findInstallWorkspace(clientset, "codewind", "install1"))
+	assert.Equal(t, "", findInstallWorkspace(clientset, "codewind", "install2"))
+
+	t.Run("falls back to the service accounts of the install", func(t *testing.T) {
+		serviceAccount := corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{
+			Name:      "codewind-k39vwfk0",
+			Namespace: "codewind",
+			Labels:    map[string]string{"app": "codewind-k39vwfk0", "codewindWorkspace": "k39vwfk0", InstallIDLabel: "install1"},
+		}}
+		assert.Equal(t, "k39vwfk0", findInstallWorkspace(fake.NewSimpleClientset(&serviceAccount), "codewind", "install1"))
+	})
+}
+
+func TestServiceAccountSelector(t *testing.T) {
+	t.Run("selects the Codewind service account of the workspace", func(t *testing.T) {
+		options := &RemoveDeploymentOptions{WorkspaceID: "k39vwfk0"}
+		assert.Equal(t, "app=codewind-k39vwfk0,codewindWorkspace=k39vwfk0", serviceAccountSelector(options, "codewindWorkspace=k39vwfk0"))
+	})
+
+	t.Run("selects the service accounts of an install except Keycloak's", func(t *testing.T) {
+		options := &RemoveDeploymentOptions{WorkspaceID: "k39vwfk0", InstallID: "install1"}
+		assert.Equal(t, InstallIDLabel+"=install1,app!=keycloak-k39vwfk0", serviceAccountSelector(options, InstallIDLabel+"=install1"))
+	})
+}
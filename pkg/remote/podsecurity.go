@@ -0,0 +1,249 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// PodSecurityRestricted : Profile that makes every Codewind pod meet the restricted Pod Security Standard
+const PodSecurityRestricted = "restricted"
+
+// Seccomp profile types, as used in the seccompProfile field of a security context
+const (
+	SeccompRuntimeDefault = "RuntimeDefault"
+	SeccompUnconfined     = "Unconfined"
+)
+
+// PodSecurity : Security context settings of the pod and container of a component. Unset fields keep the settings
+// of the pod security profile, or the cluster defaults when there is no profile.
+type PodSecurity struct {
+	RunAsNonRoot             *bool    `json:"runAsNonRoot,omitempty"`
+	RunAsUser                *int64   `json:"runAsUser,omitempty"`
+	FSGroup                  *int64   `json:"fsGroup,omitempty"`
+	SeccompProfile           string   `json:"seccompProfile,omitempty"`
+	DropCapabilities         []string `json:"dropCapabilities,omitempty"`
+	Privileged               *bool    `json:"privileged,omitempty"`
+	AllowPrivilegeEscalation *bool    `json:"allowPrivilegeEscalation,omitempty"`
+}
+
+// ValidatePodSecurityProfile returns an error unless the profile is empty or restricted
+func ValidatePodSecurityProfile(profile string) error {
+	if profile != "" && profile != PodSecurityRestricted {
+		return errors.New(errBadPodSecurityProfile + ": " + profile)
+	}
+	return nil
+}
+
+// ParsePodSecurity converts a comma separated list of security settings, for example
+// "runAsNonRoot=true,runAsUser=1001,fsGroup=1001,seccompProfile=RuntimeDefault,drop=ALL", into the security settings
+// of a component. drop may be repeated to drop more than one capability. An empty string returns empty settings.
+func ParsePodSecurity(settings string) (PodSecurity, error) {
+	security := PodSecurity{}
+	if strings.TrimSpace(settings) == "" {
+		return security, nil
+	}
+
+	for _, setting := range strings.Split(settings, ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+		if len(keyValue) != 2 || keyValue[1] == "" {
+			return PodSecurity{}, errors.New(errBadPodSecurity + ": " + setting)
+		}
+		var err error
+		switch keyValue[0] {
+		case "runAsNonRoot":
+			security.RunAsNonRoot, err = parseBoolSetting(keyValue[1])
+		case "runAsUser":
+			security.RunAsUser, err = parseIDSetting(keyValue[1])
+		case "fsGroup":
+			security.FSGroup, err = parseIDSetting(keyValue[1])
+		case "seccompProfile":
+			security.SeccompProfile = keyValue[1]
+			if keyValue[1] != SeccompRuntimeDefault && keyValue[1] != SeccompUnconfined {
+				err = errors.New(errBadPodSecurity)
+			}
+		case "drop":
+			security.DropCapabilities = append(security.DropCapabilities, keyValue[1])
+		case "privileged":
+			security.Privileged, err = parseBoolSetting(keyValue[1])
+		case "allowPrivilegeEscalation":
+			security.AllowPrivilegeEscalation, err = parseBoolSetting(keyValue[1])
+		default:
+			err = errors.New(errBadPodSecurity)
+		}
+		if err != nil {
+			return PodSecurity{}, errors.New(errBadPodSecurity + ": " + setting)
+		}
+	}
+	return security, nil
+}
+
+func parseBoolSetting(value string) (*bool, error) {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, err
+	}
+	return &parsed, nil
+}
+
+func parseIDSetting(value string) (*int64, error) {
+	parsed, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || parsed < 0 {
+		return nil, errors.New(errBadPodSecurity)
+	}
+	return &parsed, nil
+}
+
+// resolvePodSecurity returns the settings of the profile, overridden by any settings given for the component
+func resolvePodSecurity(profile string, component PodSecurity) PodSecurity {
+	security := PodSecurity{}
+	if profile == PodSecurityRestricted {
+		runAsNonRoot, privileged, allowPrivilegeEscalation := true, false, false
+		security = PodSecurity{
+			RunAsNonRoot:             &runAsNonRoot,
+			SeccompProfile:           SeccompRuntimeDefault,
+			DropCapabilities:         []string{"ALL"},
+			Privileged:               &privileged,
+			AllowPrivilegeEscalation: &allowPrivilegeEscalation,
+		}
+	}
+	if component.RunAsNonRoot != nil {
+		security.RunAsNonRoot = component.RunAsNonRoot
+	}
+	if component.RunAsUser != nil {
+		security.RunAsUser = component.RunAsUser
+	}
+	if component.FSGroup != nil {
+		security.FSGroup = component.FSGroup
+	}
+	if component.SeccompProfile != "" {
+		security.SeccompProfile = component.SeccompProfile
+	}
+	if component.DropCapabilities != nil {
+		security.DropCapabilities = component.DropCapabilities
+	}
+	if component.Privileged != nil {
+		security.Privileged = component.Privileged
+	}
+	if component.AllowPrivilegeEscalation != nil {
+		security.AllowPrivilegeEscalation = component.AllowPrivilegeEscalation
+	}
+	return security
+}
+
+// setPodSecurity applies security settings to the pod and container of a deployment. The seccomp profile is not set
+// here, as the Kubernetes API this is built against predates the seccompProfile field, so createDeployment adds it.
+func setPodSecurity(deployment *appsv1.Deployment, security PodSecurity) {
+	setPodSpecSecurity(&deployment.Spec.Template.Spec, security)
+}
+
+// setPodSpecSecurity sets the security settings of a pod and its first container
+func setPodSpecSecurity(podSpec *corev1.PodSpec, security PodSecurity) {
+	if security.RunAsNonRoot != nil || security.RunAsUser != nil || security.FSGroup != nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{
+			RunAsNonRoot: security.RunAsNonRoot,
+			RunAsUser:    security.RunAsUser,
+			FSGroup:      security.FSGroup,
+		}
+	}
+
+	container := &podSpec.Containers[0]
+	if container.SecurityContext == nil {
+		container.SecurityContext = &corev1.SecurityContext{}
+	}
+	if security.Privileged != nil {
+		container.SecurityContext.Privileged = security.Privileged
+	}
+	if security.AllowPrivilegeEscalation != nil {
+		container.SecurityContext.AllowPrivilegeEscalation = security.AllowPrivilegeEscalation
+	}
+	if len(security.DropCapabilities) > 0 {
+		drop := []corev1.Capability{}
+		for _, capability := range security.DropCapabilities {
+			drop = append(drop, corev1.Capability(capability))
+		}
+		container.SecurityContext.Capabilities = &corev1.Capabilities{Drop: drop}
+	}
+}
+
+// createDeployment creates a deployment with the seccomp profile of its security settings. Deployments with a
+// seccomp profile are posted as JSON with the seccompProfile field added to the pod security context.
+func createDeployment(clientset kubernetes.Interface, deployment *appsv1.Deployment, security PodSecurity) error {
+	if security.SeccompProfile == "" {
+		_, err := clientset.AppsV1().Deployments(deployment.GetNamespace()).Create(deployment)
+		return err
+	}
+	body, err := deploymentWithSeccompProfile(deployment, security.SeccompProfile)
+	if err != nil {
+		return err
+	}
+	return clientset.AppsV1().RESTClient().Post().
+		Namespace(deployment.GetNamespace()).
+		Resource("deployments").
+		SetHeader("Content-Type", "application/json").
+		Body(body).
+		Do().
+		Error()
+}
+
+// deploymentWithSeccompProfile returns the JSON of a deployment with a seccomp profile set for its pods
+func deploymentWithSeccompProfile(deployment *appsv1.Deployment, profile string) ([]byte, error) {
+	return withSeccompProfile(deployment, profile, "spec", "template", "spec")
+}
+
+// withSeccompProfile returns the JSON of an object with a seccomp profile set in the security context of the pod spec
+// at the path of fields given
+func withSeccompProfile(object interface{}, profile string, podSpecPath ...string) ([]byte, error) {
+	body, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]interface{}{}
+	err = json.Unmarshal(body, &fields)
+	if err != nil {
+		return nil, err
+	}
+	podSpec := fields
+	for _, field := range podSpecPath {
+		podSpec = podSpec[field].(map[string]interface{})
+	}
+	securityContext, ok := podSpec["securityContext"].(map[string]interface{})
+	if !ok {
+		securityContext = map[string]interface{}{}
+		podSpec["securityContext"] = securityContext
+	}
+	securityContext["seccompProfile"] = map[string]interface{}{"type": profile}
+	return json.Marshal(fields)
+}
+
+// podSecurity returns the security settings of a workspace component, from the install profile and its own settings
+func (codewind Codewind) podSecurity(prefix string) PodSecurity {
+	component := PodSecurity{}
+	switch prefix {
+	case PFEPrefix:
+		component = codewind.PFESecurity
+	case PerformancePrefix:
+		component = codewind.PerformanceSecurity
+	case GatekeeperPrefix:
+		component = codewind.GatekeeperSecurity
+	case KeycloakPrefix:
+		component = codewind.KeycloakSecurity
+	}
+	return resolvePodSecurity(codewind.PodSecurityProfile, component)
+}
@@ -0,0 +1,127 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParsePodSecurity(t *testing.T) {
+	t.Run("success case - parses every setting", func(t *testing.T) {
+		security, err := ParsePodSecurity("runAsNonRoot=true,runAsUser=1001,fsGroup=2000,seccompProfile=RuntimeDefault,drop=NET_RAW,drop=CHOWN,allowPrivilegeEscalation=false")
+		assert.Nil(t, err)
+		assert.True(t, *security.RunAsNonRoot)
+		assert.Equal(t, int64(1001), *security.RunAsUser)
+		assert.Equal(t, int64(2000), *security.FSGroup)
+		assert.Equal(t, SeccompRuntimeDefault, security.SeccompProfile)
+		assert.Equal(t, []string{"NET_RAW", "CHOWN"}, security.DropCapabilities)
+		assert.False(t, *security.AllowPrivilegeEscalation)
+		assert.Nil(t, security.Privileged)
+	})
+
+	t.Run("success case - no settings", func(t *testing.T) {
+		security, err := ParsePodSecurity("")
+		assert.Nil(t, err)
+		assert.Equal(t, PodSecurity{}, security)
+	})
+
+	for _, settings := range []string{"runAsNonRoot", "runAsNonRoot=maybe", "runAsUser=-1", "seccompProfile=Strict", "readOnly=true", "drop="} {
+		t.Run("error case - "+settings, func(t *testing.T) {
+			_, err := ParsePodSecurity(settings)
+			assert.Contains(t, err.Error(), errBadPodSecurity)
+		})
+	}
+}
+
+func TestResolvePodSecurity(t *testing.T) {
+	t.Run("success case - no profile keeps the cluster defaults", func(t *testing.T) {
+		assert.Equal(t, PodSecurity{}, resolvePodSecurity("", PodSecurity{}))
+	})
+
+	t.Run("success case - component settings override the restricted profile", func(t *testing.T) {
+		runAsUser := int64(1001)
+		security := resolvePodSecurity(PodSecurityRestricted, PodSecurity{RunAsUser: &runAsUser, SeccompProfile: SeccompUnconfined})
+		assert.True(t, *security.RunAsNonRoot)
+		assert.Equal(t, int64(1001), *security.RunAsUser)
+		assert.Equal(t, SeccompUnconfined, security.SeccompProfile)
+		assert.Equal(t, []string{"ALL"}, security.DropCapabilities)
+		assert.False(t, *security.Privileged)
+	})
+
+	t.Run("error case - unknown profile", func(t *testing.T) {
+		assert.Nil(t, ValidatePodSecurityProfile(""))
+		assert.Contains(t, ValidatePodSecurityProfile("baseline").Error(), errBadPodSecurityProfile)
+	})
+}
+
+func TestSetPodSecurity(t *testing.T) {
+	t.Run("success case - PFE stays privileged without a profile", func(t *testing.T) {
+		deployment := generatePFEDeploy(MockCodewind, &DeployOptions{})
+		assert.Nil(t, deployment.Spec.Template.Spec.SecurityContext)
+		assert.True(t, *deployment.Spec.Template.Spec.Containers[0].SecurityContext.Privileged)
+	})
+
+	t.Run("success case - restricted profile locks down the pod and container", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.PodSecurityProfile = PodSecurityRestricted
+		fsGroup := int64(1001)
+		codewind.PFESecurity = PodSecurity{FSGroup: &fsGroup}
+		deployment := generatePFEDeploy(codewind, &DeployOptions{})
+
+		podSecurity := deployment.Spec.Template.Spec.SecurityContext
+		assert.True(t, *podSecurity.RunAsNonRoot)
+		assert.Equal(t, int64(1001), *podSecurity.FSGroup)
+		containerSecurity := deployment.Spec.Template.Spec.Containers[0].SecurityContext
+		assert.False(t, *containerSecurity.Privileged)
+		assert.False(t, *containerSecurity.AllowPrivilegeEscalation)
+		assert.Equal(t, []corev1.Capability{"ALL"}, containerSecurity.Capabilities.Drop)
+	})
+}
+
+func TestCreateDeployment(t *testing.T) {
+	t.Run("success case - deployments without a seccomp profile are created as they are", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		deployment := generatePerformanceDeploy(MockCodewind)
+		err := createDeployment(clientset, &deployment, PodSecurity{})
+		assert.Nil(t, err)
+		deployments, _ := clientset.AppsV1().Deployments(MockCodewind.Namespace).List(metav1.ListOptions{})
+		assert.Len(t, deployments.Items, 1)
+	})
+
+	t.Run("success case - the seccomp profile is added to the pod security context", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.PodSecurityProfile = PodSecurityRestricted
+		deployment := generateGatekeeperDeploy(codewind, &DeployOptions{})
+		body, err := deploymentWithSeccompProfile(&deployment, SeccompRuntimeDefault)
+		assert.Nil(t, err)
+
+		var posted struct {
+			Spec struct {
+				Template struct {
+					Spec struct {
+						SecurityContext map[string]interface{} `json:"securityContext"`
+					} `json:"spec"`
+				} `json:"template"`
+			} `json:"spec"`
+		}
+		assert.Nil(t, json.Unmarshal(body, &posted))
+		securityContext := posted.Spec.Template.Spec.SecurityContext
+		assert.Equal(t, map[string]interface{}{"type": SeccompRuntimeDefault}, securityContext["seccompProfile"])
+		assert.Equal(t, true, securityContext["runAsNonRoot"])
+	})
+}
@@ -0,0 +1,115 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	logr "github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+// PortForwardOptions : The pod port to forward, and the local port to listen on, where 0 chooses a free port
+type PortForwardOptions struct {
+	Namespace string
+	PodName   string
+	PodPort   int
+	LocalPort int
+	Timeout   time.Duration
+}
+
+// ForwardPodPort forwards a local port to a port of a pod, as kubectl port-forward does, once the pod is running.
+// ready is called with the local port when connections are accepted, and forwarding stops when stop is closed.
+func ForwardPodPort(options PortForwardOptions, stop <-chan struct{}, ready func(localPort int)) *RemInstError {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+
+	timeout := options.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	err = WaitForPodRunning(clientset, options.Namespace, options.PodName, timeout)
+	if err != nil {
+		return &RemInstError{errOpReadyTimeout, err, err.Error() + ": " + options.PodName}
+	}
+
+	transport, upgrader, err := spdy.RoundTripperFor(config)
+	if err != nil {
+		return &RemInstError{errOpPortForward, err, err.Error()}
+	}
+	requestURL := clientset.CoreV1().RESTClient().Post().
+		Resource("pods").Namespace(options.Namespace).Name(options.PodName).SubResource("portforward").URL()
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, requestURL)
+
+	readyChan := make(chan struct{})
+	ports := []string{fmt.Sprintf("%d:%d", options.LocalPort, options.PodPort)}
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"localhost"}, ports, stop, readyChan, ioutil.Discard, os.Stderr)
+	if err != nil {
+		return &RemInstError{errOpPortForward, err, err.Error()}
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		forwardErr <- forwarder.ForwardPorts()
+	}()
+	select {
+	case err = <-forwardErr:
+		if err == nil {
+			return nil
+		}
+		return &RemInstError{errOpPortForward, err, err.Error()}
+	case <-readyChan:
+	}
+
+	forwardedPorts, err := forwarder.GetPorts()
+	if err != nil {
+		return &RemInstError{errOpPortForward, err, err.Error()}
+	}
+	ready(int(forwardedPorts[0].Local))
+	if err = <-forwardErr; err != nil {
+		return &RemInstError{errOpPortForward, err, err.Error()}
+	}
+	return nil
+}
+
+// WaitForPodRunning polls a pod until it is running, or returns an error after the timeout
+func WaitForPodRunning(clientset kubernetes.Interface, namespace string, name string, timeout time.Duration) error {
+	err := wait.PollImmediate(deploymentPollInterval, timeout, func() (bool, error) {
+		pod, err := clientset.CoreV1().Pods(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			// The pod may not be visible yet, keep polling
+			logr.Tracef("Unable to get pod '%v': %v", name, err)
+			return false, nil
+		}
+		return pod.Status.Phase == corev1.PodRunning, nil
+	})
+	if err != nil {
+		return errors.New(errPodNotRunning)
+	}
+	return nil
+}
@@ -0,0 +1,372 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/remote/kube"
+	logr "github.com/sirupsen/logrus"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+type (
+	// PreflightCheck : The outcome of one check made before an install creates anything
+	PreflightCheck struct {
+		Name    string `json:"name"`
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		// Hint says how to fix a check that did not pass
+		Hint string `json:"hint,omitempty"`
+	}
+
+	// PreflightReport : The outcome of every preflight check. An install only starts when none failed.
+	PreflightReport struct {
+		Passed bool             `json:"passed"`
+		Checks []PreflightCheck `json:"checks"`
+	}
+
+	// permission is a verb the install needs on a type of resource
+	permission struct {
+		verb     string
+		group    string
+		resource string
+		// cluster permissions are checked across namespaces, rather than in the install namespace
+		cluster bool
+	}
+)
+
+// Statuses of a preflight check
+const (
+	PreflightPassed  = "passed"
+	PreflightWarning = "warning"
+	PreflightFailed  = "failed"
+)
+
+// Kubernetes versions the install works with. Ingresses are created with extensions/v1beta1, which Kubernetes stopped
+// serving in 1.22, while OpenShift clusters are exposed with routes instead.
+const (
+	minKubernetesMinor     = 11
+	maxIngressBetaMinor    = 21
+	supportedKubernetesMsg = "Kubernetes 1.11 to 1.21, or OpenShift"
+)
+
+// kubernetesMinor reads the minor version reported by the API server, which some providers suffix, as in 16+
+var kubernetesMinor = regexp.MustCompile(`^\d+`)
+
+// PreflightRemote : Runs the checks an install makes before creating anything, reporting each of them, so that a
+// cluster can be checked without installing
+func PreflightRemote(deployOptions *DeployOptions) (*PreflightReport, *RemInstError) {
+	config, err := GetKubeConfig()
+	if err != nil {
+		return nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	namespace := deployOptions.Namespace
+	if namespace == "" {
+		namespace = kube.GetCurrentNamespace()
+	}
+	return runPreflight(clientset, deployOptions, namespace, kube.DetectOpenShift(config)), nil
+}
+
+// runPreflight checks the version of the cluster, the permissions of the current user, the storage classes, the
+// ingress controller and the quotas of the namespace against what the install needs
+func runPreflight(clientset kubernetes.Interface, deployOptions *DeployOptions, namespace string, onOpenShift bool) *PreflightReport {
+	_, err := clientset.CoreV1().Namespaces().Get(namespace, v1.GetOptions{})
+	namespaceExists := err == nil || !apierrors.IsNotFound(err)
+
+	checks := []PreflightCheck{
+		checkServerVersion(clientset, onOpenShift),
+		checkPermissions(clientset, deployOptions, namespace, namespaceExists, onOpenShift),
+		checkStorage(clientset, deployOptions),
+		checkIngressController(clientset, deployOptions, onOpenShift),
+		checkQuotas(clientset, deployOptions, namespace, namespaceExists),
+	}
+	report := PreflightReport{Passed: true, Checks: checks}
+	for _, check := range checks {
+		if check.Status == PreflightFailed {
+			report.Passed = false
+		}
+		if check.Status != PreflightPassed {
+			logr.Warnf("Preflight %v %v: %v\n", check.Name, check.Status, check.Message)
+		}
+	}
+	return &report
+}
+
+// failure returns the error an install stops with when checks failed, describing each of them and how to fix it
+func (report *PreflightReport) failure() *RemInstError {
+	if report.Passed {
+		return nil
+	}
+	problems := []string{}
+	for _, check := range report.Checks {
+		if check.Status == PreflightFailed {
+			problems = append(problems, check.Message+". "+check.Hint)
+		}
+	}
+	err := i18n.Errorf(msgPreflightFailed, errPreflightFailed+": %s", strings.Join(problems, " "))
+	return &RemInstError{errOpPreflight, err, err.Error()}
+}
+
+func checkServerVersion(clientset kubernetes.Interface, onOpenShift bool) PreflightCheck {
+	check := PreflightCheck{Name: "version", Status: PreflightPassed}
+	info, err := clientset.Discovery().ServerVersion()
+	if err != nil {
+		check.Status, check.Message = PreflightWarning, "Unable to read the Kubernetes version: "+err.Error()
+		return check
+	}
+	check.Message = "Kubernetes " + info.GitVersion
+	major, _ := strconv.Atoi(info.Major)
+	minor, _ := strconv.Atoi(kubernetesMinor.FindString(info.Minor))
+	switch {
+	case major == 1 && minor < minKubernetesMinor:
+		check.Status, check.Hint = PreflightFailed, "Codewind needs "+supportedKubernetesMsg+", upgrade the cluster"
+	case major == 1 && minor > maxIngressBetaMinor && !onOpenShift:
+		check.Status, check.Hint = PreflightFailed, "The ingresses Codewind creates need "+supportedKubernetesMsg+", use a cluster of one of those versions"
+	}
+	if check.Status == PreflightFailed {
+		check.Message += " is not supported"
+	}
+	return check
+}
+
+// neededPermissions returns the verbs the install needs on each type of resource it creates
+func neededPermissions(deployOptions *DeployOptions, namespaceExists bool, onOpenShift bool) []permission {
+	needed := []permission{
+		{"create", "", "secrets", false},
+		{"create", "", "services", false},
+		{"create", "", "persistentvolumeclaims", false},
+		{"create", "", "serviceaccounts", false},
+		{"create", "apps", "deployments", false},
+	}
+	if !namespaceExists {
+		needed = append(needed, permission{"create", "", "namespaces", true})
+	}
+	if onOpenShift {
+		needed = append(needed, permission{"create", "route.openshift.io", "routes", false})
+	} else {
+		needed = append(needed, permission{"create", "extensions", "ingresses", false})
+	}
+	if deployOptions.CertIssuer != "" {
+		needed = append(needed, permission{"create", certificateResource.Group, certificateResource.Resource, false})
+	}
+	if deployOptions.KeycloakOnly {
+		return needed
+	}
+	needed = append(needed,
+		permission{"create", "rbac.authorization.k8s.io", "clusterroles", true},
+		permission{"create", "rbac.authorization.k8s.io", "clusterrolebindings", true},
+		permission{"create", "rbac.authorization.k8s.io", "rolebindings", false},
+	)
+	if deployOptions.NetworkPolicies {
+		needed = append(needed, permission{"create", "networking.k8s.io", "networkpolicies", false})
+	}
+	if deployOptions.Metrics {
+		needed = append(needed, permission{"create", serviceMonitorResource.Group, serviceMonitorResource.Resource, false})
+	}
+	if deployOptions.PerformanceReplicas > 1 || deployOptions.GatekeeperReplicas > 1 {
+		needed = append(needed, permission{"create", "policy", "poddisruptionbudgets", false})
+	}
+	return needed
+}
+
+// checkPermissions asks the API server whether the current user can create each type of resource the install does
+func checkPermissions(clientset kubernetes.Interface, deployOptions *DeployOptions, namespace string, namespaceExists bool, onOpenShift bool) PreflightCheck {
+	check := PreflightCheck{Name: "permissions", Status: PreflightPassed, Message: "The current user can create the resources of the install"}
+	denied := []string{}
+	for _, needed := range neededPermissions(deployOptions, namespaceExists, onOpenShift) {
+		attributes := authorizationv1.ResourceAttributes{Verb: needed.verb, Group: needed.group, Resource: needed.resource}
+		if !needed.cluster {
+			attributes.Namespace = namespace
+		}
+		review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(&authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attributes},
+		})
+		if err != nil {
+			check.Status, check.Message = PreflightWarning, "Unable to check the permissions of the current user: "+err.Error()
+			return check
+		}
+		if !review.Status.Allowed {
+			name := needed.resource
+			if needed.group != "" {
+				name += "." + needed.group
+			}
+			denied = append(denied, needed.verb+" "+name)
+		}
+	}
+	if len(denied) > 0 {
+		check.Status = PreflightFailed
+		check.Message = "The current user cannot " + strings.Join(denied, ", ")
+		check.Hint = "Ask a cluster administrator to grant these permissions in namespace " + namespace + ", or to install Codewind"
+	}
+	return check
+}
+
+func checkStorage(clientset kubernetes.Interface, deployOptions *DeployOptions) PreflightCheck {
+	check := PreflightCheck{Name: "storage", Status: PreflightPassed, Message: "The storage classes of the install exist"}
+	storageClasses := []string{}
+	if deployOptions.KeycloakURL == "" {
+		storageClasses = append(storageClasses, deployOptions.GetKeycloakStorageClass())
+	}
+	if !deployOptions.KeycloakOnly {
+		storageClasses = append(storageClasses, deployOptions.StorageClass)
+	}
+	if remInstErr := checkStorageClasses(clientset, storageClasses...); remInstErr != nil {
+		check.Status, check.Message = PreflightFailed, remInstErr.Desc
+		check.Hint = "Use --storage-class to choose one of the storage classes listed by kubectl get storageclass"
+	}
+	return check
+}
+
+func checkIngressController(clientset kubernetes.Interface, deployOptions *DeployOptions, onOpenShift bool) PreflightCheck {
+	check := PreflightCheck{Name: "ingress", Status: PreflightPassed}
+	if err := ValidateIngressHosts(deployOptions); err != nil {
+		check.Status, check.Message = PreflightFailed, err.Error()
+		check.Hint = "Use --gatekeeperhost, --keycloakhost and --wildcard-domain with DNS names, and either --wildcard-domain or --ingress"
+		return check
+	}
+	switch {
+	case deployOptions.WildcardDomain != "":
+		check.Message = "Using wildcard domain " + deployOptions.WildcardDomain
+	case deployOptions.IngressDomain != "":
+		check.Message = "Using ingress domain " + deployOptions.IngressDomain
+	case onOpenShift:
+		check.Message = "Codewind is exposed with OpenShift routes"
+	default:
+		services, err := clientset.CoreV1().Services("ingress-nginx").List(v1.ListOptions{})
+		if err != nil || len(services.Items) == 0 {
+			check.Status, check.Message = PreflightFailed, "No ingress controller found in namespace ingress-nginx"
+			check.Hint = errNoIngressService
+			return check
+		}
+		check.Message = "Using the ingress-nginx controller at " + services.Items[0].Spec.ClusterIP + ".nip.io"
+	}
+	return check
+}
+
+// componentRequirements returns the resource requirements of each pod the install runs
+func componentRequirements(deployOptions *DeployOptions) []corev1.ResourceRequirements {
+	pods := []corev1.ResourceRequirements{}
+	if deployOptions.KeycloakURL == "" {
+		pods = append(pods, deployOptions.KeycloakResources)
+	}
+	if deployOptions.KeycloakOnly {
+		return pods
+	}
+	pods = append(pods, deployOptions.PFEResources)
+	for replica := int32(0); replica < deployOptions.PerformanceReplicas || replica == 0; replica++ {
+		pods = append(pods, deployOptions.PerformanceResources)
+	}
+	for replica := int32(0); replica < deployOptions.GatekeeperReplicas || replica == 0; replica++ {
+		pods = append(pods, deployOptions.GatekeeperResources)
+	}
+	return pods
+}
+
+// installNeeds returns the amount of each quota resource the install uses
+func installNeeds(deployOptions *DeployOptions) corev1.ResourceList {
+	pods := componentRequirements(deployOptions)
+	needs := corev1.ResourceList{
+		corev1.ResourcePods:                   *resource.NewQuantity(int64(len(pods)), resource.DecimalSI),
+		corev1.ResourcePersistentVolumeClaims: *resource.NewQuantity(0, resource.DecimalSI),
+		corev1.ResourceRequestsStorage:        *resource.NewQuantity(0, resource.BinarySI),
+	}
+	addClaim := func(size string) {
+		quantity, err := resource.ParseQuantity(size)
+		if err != nil {
+			return
+		}
+		claims, storage := needs[corev1.ResourcePersistentVolumeClaims], needs[corev1.ResourceRequestsStorage]
+		claims.Add(*resource.NewQuantity(1, resource.DecimalSI))
+		storage.Add(quantity)
+		needs[corev1.ResourcePersistentVolumeClaims], needs[corev1.ResourceRequestsStorage] = claims, storage
+	}
+	if deployOptions.KeycloakURL == "" {
+		addClaim(deployOptions.KeycloakPVCSize)
+	}
+	if !deployOptions.KeycloakOnly {
+		addClaim(deployOptions.CodewindPVCSize)
+	}
+
+	for _, requirements := range pods {
+		for quotaName, list := range map[string]corev1.ResourceList{"requests.": requirements.Requests, "limits.": requirements.Limits} {
+			for name, quantity := range list {
+				total := needs[corev1.ResourceName(quotaName+string(name))]
+				total.Add(quantity)
+				needs[corev1.ResourceName(quotaName+string(name))] = total
+			}
+		}
+	}
+	return needs
+}
+
+// checkQuotas compares the headroom left by the resource quotas of the namespace with what the install uses
+func checkQuotas(clientset kubernetes.Interface, deployOptions *DeployOptions, namespace string, namespaceExists bool) PreflightCheck {
+	check := PreflightCheck{Name: "quota", Status: PreflightPassed, Message: "The quotas of namespace " + namespace + " leave room for the install"}
+	if !namespaceExists {
+		check.Message = "Namespace " + namespace + " will be created without quotas"
+		return check
+	}
+	quotas, err := clientset.CoreV1().ResourceQuotas(namespace).List(v1.ListOptions{})
+	if err != nil {
+		check.Status, check.Message = PreflightWarning, "Unable to read the quotas of namespace "+namespace+": "+err.Error()
+		return check
+	}
+
+	needs := installNeeds(deployOptions)
+	short, unset := []string{}, []string{}
+	for _, quota := range quotas.Items {
+		for name, hard := range quota.Status.Hard {
+			needName := name
+			// cpu and memory quotas limit requests
+			if name == corev1.ResourceCPU || name == corev1.ResourceMemory {
+				needName = corev1.ResourceName("requests." + string(name))
+			}
+			need, counted := needs[needName]
+			if !counted {
+				if strings.HasPrefix(string(needName), "requests.") || strings.HasPrefix(string(needName), "limits.") {
+					unset = append(unset, string(needName))
+				}
+				continue
+			}
+			headroom := hard.DeepCopy()
+			if used, ok := quota.Status.Used[name]; ok {
+				headroom.Sub(used)
+			}
+			if headroom.Cmp(need) < 0 {
+				short = append(short, fmt.Sprintf("%v needs %v but quota %v has %v left", name, need.String(), quota.GetName(), headroom.String()))
+			}
+		}
+	}
+	switch {
+	case len(short) > 0:
+		check.Status, check.Message = PreflightFailed, "Not enough quota in namespace "+namespace+": "+strings.Join(short, ", ")
+		check.Hint = "Ask a cluster administrator to raise the quota, or lower the resources and storage of the install"
+	case len(unset) > 0:
+		check.Status, check.Message = PreflightWarning, "Namespace "+namespace+" has quotas on "+strings.Join(unset, ", ")+", which the install does not set for every component"
+		check.Hint = "Set them with --pferesources, --perfresources, --gkresources and --kresources, unless a limit range gives defaults"
+	}
+	return check
+}
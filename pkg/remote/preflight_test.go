@@ -0,0 +1,169 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// preflightClientset returns a cluster of a Kubernetes version, with a default storage class and ingress-nginx, where
+// the current user cannot create the resources given
+func preflightClientset(minor string, denied map[string]bool, objects ...runtime.Object) *fake.Clientset {
+	ingress := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress-nginx", Namespace: "ingress-nginx"},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.1"},
+	}
+	objects = append(objects, generateMockStorageClass("standard", true), ingress)
+	clientset := fake.NewSimpleClientset(objects...)
+	clientset.Discovery().(*fakediscovery.FakeDiscovery).FakedServerVersion = &version.Info{Major: "1", Minor: minor, GitVersion: "v1." + minor + ".0"}
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review.Status.Allowed = !denied[review.Spec.ResourceAttributes.Resource]
+		return true, review, nil
+	})
+	return clientset
+}
+
+func findCheck(report *PreflightReport, name string) PreflightCheck {
+	for _, check := range report.Checks {
+		if check.Name == name {
+			return check
+		}
+	}
+	return PreflightCheck{}
+}
+
+func TestRunPreflight(t *testing.T) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "codewind"}}
+
+	t.Run("success case - every check passes", func(t *testing.T) {
+		report := runPreflight(preflightClientset("16", nil, namespace), &DeployOptions{}, "codewind", false)
+		assert.True(t, report.Passed)
+		assert.Nil(t, report.failure())
+		for _, check := range report.Checks {
+			assert.Equal(t, PreflightPassed, check.Status, check.Message)
+		}
+	})
+
+	t.Run("error case - versions without extensions/v1beta1 ingresses fail unless on OpenShift", func(t *testing.T) {
+		report := runPreflight(preflightClientset("22+", nil, namespace), &DeployOptions{}, "codewind", false)
+		assert.False(t, report.Passed)
+		assert.Equal(t, PreflightFailed, findCheck(report, "version").Status)
+
+		report = runPreflight(preflightClientset("22", nil, namespace), &DeployOptions{}, "codewind", true)
+		assert.Equal(t, PreflightPassed, findCheck(report, "version").Status)
+	})
+
+	t.Run("error case - versions before 1.11 fail", func(t *testing.T) {
+		report := runPreflight(preflightClientset("10", nil, namespace), &DeployOptions{}, "codewind", false)
+		assert.Equal(t, PreflightFailed, findCheck(report, "version").Status)
+	})
+
+	t.Run("error case - denied permissions are listed", func(t *testing.T) {
+		denied := map[string]bool{"clusterroles": true, "deployments": true}
+		report := runPreflight(preflightClientset("16", denied, namespace), &DeployOptions{}, "codewind", false)
+		check := findCheck(report, "permissions")
+		assert.Equal(t, PreflightFailed, check.Status)
+		assert.Equal(t, "The current user cannot create deployments.apps, create clusterroles.rbac.authorization.k8s.io", check.Message)
+		remInstErr := report.failure()
+		assert.Equal(t, errOpPreflight, remInstErr.Op)
+		assert.Contains(t, remInstErr.Desc, check.Message)
+	})
+
+	t.Run("error case - creating a missing namespace needs permission", func(t *testing.T) {
+		denied := map[string]bool{"namespaces": true}
+		report := runPreflight(preflightClientset("16", denied, namespace), &DeployOptions{}, "codewind", false)
+		assert.Equal(t, PreflightPassed, findCheck(report, "permissions").Status)
+
+		report = runPreflight(preflightClientset("16", denied), &DeployOptions{}, "codewind", false)
+		assert.Equal(t, PreflightFailed, findCheck(report, "permissions").Status)
+	})
+
+	t.Run("error case - storage class does not exist", func(t *testing.T) {
+		report := runPreflight(preflightClientset("16", nil, namespace), &DeployOptions{StorageClass: "nfs"}, "codewind", false)
+		check := findCheck(report, "storage")
+		assert.Equal(t, PreflightFailed, check.Status)
+		assert.Equal(t, errNoStorageClass+": nfs", check.Message)
+	})
+
+	t.Run("error case - no ingress controller", func(t *testing.T) {
+		clientset := preflightClientset("16", nil, namespace)
+		clientset.CoreV1().Services("ingress-nginx").Delete("ingress-nginx", nil)
+		report := runPreflight(clientset, &DeployOptions{}, "codewind", false)
+		assert.Equal(t, PreflightFailed, findCheck(report, "ingress").Status)
+
+		report = runPreflight(clientset, &DeployOptions{IngressDomain: "example.com"}, "codewind", false)
+		assert.Equal(t, PreflightPassed, findCheck(report, "ingress").Status)
+	})
+}
+
+func TestCheckQuotas(t *testing.T) {
+	quota := func(hard corev1.ResourceList, used corev1.ResourceList) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "codewind"},
+			Status:     corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+		}
+	}
+	deployOptions := &DeployOptions{CodewindPVCSize: "10Gi", KeycloakPVCSize: "1Gi"}
+
+	t.Run("success case - quota leaves room for the install", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(quota(corev1.ResourceList{
+			corev1.ResourcePods:            resource.MustParse("10"),
+			corev1.ResourceRequestsStorage: resource.MustParse("20Gi"),
+		}, nil))
+		assert.Equal(t, PreflightPassed, checkQuotas(clientset, deployOptions, "codewind", true).Status)
+	})
+
+	t.Run("error case - used quota leaves too little storage and pods", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(quota(corev1.ResourceList{
+			corev1.ResourcePods:            resource.MustParse("10"),
+			corev1.ResourceRequestsStorage: resource.MustParse("20Gi"),
+		}, corev1.ResourceList{
+			corev1.ResourcePods:            resource.MustParse("8"),
+			corev1.ResourceRequestsStorage: resource.MustParse("15Gi"),
+		}))
+		check := checkQuotas(clientset, deployOptions, "codewind", true)
+		assert.Equal(t, PreflightFailed, check.Status)
+		assert.Contains(t, check.Message, "pods needs 4 but quota quota has 2 left")
+		assert.Contains(t, check.Message, "requests.storage needs 11Gi but quota quota has 5Gi left")
+	})
+
+	t.Run("success case - cpu quota counts the requests of every component", func(t *testing.T) {
+		requests := corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")}}
+		options := &DeployOptions{PFEResources: requests, PerformanceResources: requests, GatekeeperResources: requests, KeycloakResources: requests}
+		clientset := fake.NewSimpleClientset(quota(corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse("1")}, nil))
+		check := checkQuotas(clientset, options, "codewind", true)
+		assert.Equal(t, PreflightFailed, check.Status)
+		assert.Contains(t, check.Message, "requests.cpu needs 2 but quota quota has 1 left")
+	})
+
+	t.Run("warning case - quota limits memory the install does not set", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(quota(corev1.ResourceList{corev1.ResourceLimitsMemory: resource.MustParse("4Gi")}, nil))
+		assert.Equal(t, PreflightWarning, checkQuotas(clientset, deployOptions, "codewind", true).Status)
+	})
+
+	t.Run("success case - a new namespace has no quotas", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset()
+		assert.Equal(t, PreflightPassed, checkQuotas(clientset, deployOptions, "codewind", false).Status)
+	})
+}
@@ -0,0 +1,142 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	logr "github.com/sirupsen/logrus"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// ProjectIDLabel is the label identifying the resources deployed for a project
+	ProjectIDLabel = "projectID"
+	// ProjectNameLabel is the label holding the name of a project on the resources deployed for it
+	ProjectNameLabel = "projectName"
+)
+
+var invalidLabelChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ProjectNameLabelValue returns a project name as a valid label value, replacing the characters labels cannot hold
+func ProjectNameLabelValue(projectName string) string {
+	value := invalidLabelChars.ReplaceAllString(projectName, "-")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return strings.Trim(value, "._-")
+}
+
+// RelabelProject sets the project name label on the deployments, services and pods of a project, so renamed projects
+// are found by their new name. Pod templates are left alone, so relabelling does not restart the project. Labels
+// already changed when relabelling fails are given back their previous value.
+func RelabelProject(clientset kubernetes.Interface, namespace string, projectID string, projectName string) *RemInstError {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return remInstErr
+	}
+	labelSelector := ProjectIDLabel + "=" + projectID
+	listOptions := v1.ListOptions{LabelSelector: labelSelector}
+	labelValue := ProjectNameLabelValue(projectName)
+
+	// setLabel labels an object with the new name, returning a function that gives the updated object its previous label
+	setLabel := func(object v1.Object) func(v1.Object) {
+		labels := object.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		previous, labelled := labels[ProjectNameLabel]
+		labels[ProjectNameLabel] = labelValue
+		object.SetLabels(labels)
+		logr.Infof("Labelling '%v' with %v=%v\n", object.GetName(), ProjectNameLabel, labelValue)
+		return func(updated v1.Object) {
+			labels := updated.GetLabels()
+			if labelled {
+				labels[ProjectNameLabel] = previous
+			} else {
+				delete(labels, ProjectNameLabel)
+			}
+			updated.SetLabels(labels)
+		}
+	}
+	var restores []func() error
+	fail := func(op string, err error) *RemInstError {
+		for i := len(restores) - 1; i >= 0; i-- {
+			if restoreErr := restores[i](); restoreErr != nil {
+				logr.Errorf("Unable to restore the %v label of project %v: %v", ProjectNameLabel, projectID, restoreErr)
+			}
+		}
+		return &RemInstError{op, err, err.Error()}
+	}
+
+	deploymentsClient := client.clientset.AppsV1().Deployments(namespace)
+	deployments, err := deploymentsClient.List(listOptions)
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if len(deployments.Items) == 0 {
+		err = errors.New(errTargetNotFound)
+		return &RemInstError{errOpNotFound, err, err.Error() + ": " + labelSelector}
+	}
+	for i := range deployments.Items {
+		restoreLabel := setLabel(&deployments.Items[i])
+		updated, err := deploymentsClient.Update(&deployments.Items[i])
+		if err != nil {
+			return fail(errOpRelabel, err)
+		}
+		restores = append(restores, func() error {
+			restoreLabel(updated)
+			_, err := deploymentsClient.Update(updated)
+			return err
+		})
+	}
+
+	servicesClient := client.clientset.CoreV1().Services(namespace)
+	services, err := servicesClient.List(listOptions)
+	if err != nil {
+		return fail(errOpNotFound, err)
+	}
+	for i := range services.Items {
+		restoreLabel := setLabel(&services.Items[i])
+		updated, err := servicesClient.Update(&services.Items[i])
+		if err != nil {
+			return fail(errOpRelabel, err)
+		}
+		restores = append(restores, func() error {
+			restoreLabel(updated)
+			_, err := servicesClient.Update(updated)
+			return err
+		})
+	}
+
+	podsClient := client.clientset.CoreV1().Pods(namespace)
+	pods, err := podsClient.List(listOptions)
+	if err != nil {
+		return fail(errOpNotFound, err)
+	}
+	for i := range pods.Items {
+		restoreLabel := setLabel(&pods.Items[i])
+		updated, err := podsClient.Update(&pods.Items[i])
+		if err != nil {
+			return fail(errOpRelabel, err)
+		}
+		restores = append(restores, func() error {
+			restoreLabel(updated)
+			_, err := podsClient.Update(updated)
+			return err
+		})
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestRelabelProject(t *testing.T) {
+	projectLabels := map[string]string{ProjectIDLabel: "PID1", ProjectNameLabel: "oldname"}
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(
+			&v1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cw-oldname-pid1", Namespace: "test1", Labels: projectLabels}},
+			&v1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cw-other", Namespace: "test1", Labels: map[string]string{ProjectIDLabel: "PID2"}}},
+			&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "cw-oldname-pid1", Namespace: "test1", Labels: projectLabels}},
+			&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cw-oldname-pid1-abc", Namespace: "test1", Labels: projectLabels}},
+		)
+	}
+
+	t.Run("success case - labels every resource of the project", func(t *testing.T) {
+		clientset := newClientset()
+		err := RelabelProject(clientset, "test1", "PID1", "new name")
+		assert.Nil(t, err)
+		deployment, _ := clientset.AppsV1().Deployments("test1").Get("cw-oldname-pid1", metav1.GetOptions{})
+		assert.Equal(t, "new-name", deployment.GetLabels()[ProjectNameLabel])
+		service, _ := clientset.CoreV1().Services("test1").Get("cw-oldname-pid1", metav1.GetOptions{})
+		assert.Equal(t, "new-name", service.GetLabels()[ProjectNameLabel])
+		pod, _ := clientset.CoreV1().Pods("test1").Get("cw-oldname-pid1-abc", metav1.GetOptions{})
+		assert.Equal(t, "new-name", pod.GetLabels()[ProjectNameLabel])
+		other, _ := clientset.AppsV1().Deployments("test1").Get("cw-other", metav1.GetOptions{})
+		assert.Equal(t, "", other.GetLabels()[ProjectNameLabel])
+	})
+
+	t.Run("error case - labels are restored when relabelling fails", func(t *testing.T) {
+		clientset := newClientset()
+		clientset.PrependReactor("update", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, nil, errors.New("pods are read-only")
+		})
+		err := RelabelProject(clientset, "test1", "PID1", "newname")
+		assert.Equal(t, errOpRelabel, err.Op)
+		deployment, _ := clientset.AppsV1().Deployments("test1").Get("cw-oldname-pid1", metav1.GetOptions{})
+		assert.Equal(t, "oldname", deployment.GetLabels()[ProjectNameLabel])
+		service, _ := clientset.CoreV1().Services("test1").Get("cw-oldname-pid1", metav1.GetOptions{})
+		assert.Equal(t, "oldname", service.GetLabels()[ProjectNameLabel])
+	})
+
+	t.Run("error case - project has no deployment", func(t *testing.T) {
+		err := RelabelProject(newClientset(), "test1", "PID3", "newname")
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+}
+
+func TestProjectNameLabelValue(t *testing.T) {
+	assert.Equal(t, "my-project", ProjectNameLabelValue("my project"))
+	assert.Equal(t, "project_1.0", ProjectNameLabelValue("-project_1.0/"))
+	assert.Len(t, ProjectNameLabelValue(strings.Repeat("a", 70)), 63)
+}
@@ -12,9 +12,16 @@
 package remote
 
 import (
+	"errors"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
 	"github.com/eclipse/codewind-installer/pkg/remote/kube"
 	routev1 "github.com/openshift/client-go/route/clientset/versioned/typed/route/v1"
 	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
@@ -24,6 +31,13 @@ import (
 type RemoveDeploymentOptions struct {
 	Namespace   string
 	WorkspaceID string
+	Force       bool
+
+	// InstallID selects the resources to remove by their ownership label instead of the workspace ID
+	InstallID string
+
+	// Events receives the progress of the removal, when set
+	Events Events
 }
 
 const (
@@ -41,6 +55,9 @@ const (
 	ResourceRemoveFailed = 5
 )
 
+// projectIDLabel is set by PFE on the deployments and services it creates for each project
+const projectIDLabel = "projectID"
+
 // RemovalResult : Status for each component
 type RemovalResult struct {
 
@@ -66,6 +83,10 @@ type RemovalResult struct {
 	StatusSecretsCodewind int
 	StatusSecretsKeycloak int
 
+	// cert-manager certificates
+	StatusCertificatesCodewind int
+	StatusCertificatesKeycloak int
+
 	// Service account
 	StatusServiceAccount int
 
@@ -80,6 +101,30 @@ type RemovalResult struct {
 	// Ingress/Routes
 	StatusIngressGatekeeper int
 	StatusIngressKeycloak   int
+
+	// Network policies
+	StatusNetworkPolicies int
+
+	// Prometheus Operator service monitors
+	StatusServiceMonitors int
+
+	// Pod disruption budgets of components with more than one replica
+	StatusPodDisruptionBudgets int
+
+	// CronJobs starting scheduled load runs
+	StatusLoadTestCronJobs int
+
+	// Helper pods left behind by an interrupted workspace backup or restore
+	StatusBackupHelperPods int
+
+	// Per-project workloads created by PFE, keyed by project ID
+	StatusProjects map[string]ProjectRemovalResult
+}
+
+// ProjectRemovalResult : Status of the workloads PFE created for a single project
+type ProjectRemovalResult struct {
+	StatusDeployments int
+	StatusServices    int
 }
 
 // RemoveRemote : Remove remote install from Kube
@@ -90,6 +135,8 @@ func RemoveRemote(remoteRemovalOptions *RemoveDeploymentOptions) (*RemovalResult
 		logr.Infof("Unable to retrieve Kubernetes Config %v\n", err)
 		return nil, &RemInstError{errOpNotFound, err, err.Error()}
 	}
+	events := eventsOrNone(remoteRemovalOptions.Events)
+	watchResources(config, events)
 
 	// Determine if we're running on OpenShift or not.
 	onOpenShift := kube.DetectOpenShift(config)
@@ -106,11 +153,17 @@ func RemoveRemote(remoteRemovalOptions *RemoveDeploymentOptions) (*RemovalResult
 		StatusDeploymentPFE:         ResourceNotProcessed,
 		StatusDeploymentPerformance: ResourceNotProcessed,
 		StatusSecretsCodewind:       ResourceNotProcessed,
+		StatusCertificatesCodewind:  ResourceNotProcessed,
 		StatusServiceAccount:        ResourceNotProcessed,
 		StatusRoleBindings:          ResourceNotProcessed,
 		StatusTektonRoleBindings:    ResourceNotProcessed,
 		StatusPVCCodewind:           ResourceNotProcessed,
 		StatusIngressGatekeeper:     ResourceNotProcessed,
+		StatusNetworkPolicies:       ResourceNotProcessed,
+		StatusServiceMonitors:       ResourceNotProcessed,
+		StatusPodDisruptionBudgets:  ResourceNotProcessed,
+		StatusLoadTestCronJobs:      ResourceNotProcessed,
+		StatusBackupHelperPods:      ResourceNotProcessed,
 	}
 
 	if err != nil {
@@ -133,51 +186,86 @@ func RemoveRemote(remoteRemovalOptions *RemoveDeploymentOptions) (*RemovalResult
 	}
 	logr.Infof("Found '%v' namespace\n", namespace)
 
-	logr.Trace("Removing Codewind deployments")
-	status, err := deleteDeployment(remoteRemovalOptions, clientset, "app="+PFEPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusDeploymentPFE = status
-	status, err = deleteDeployment(remoteRemovalOptions, clientset, "app="+PerformancePrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusDeploymentPerformance = status
-	status, err = deleteDeployment(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusDeploymentGatekeeper = status
-
-	logr.Trace("Removing Codewind services")
-	status, err = deleteService(remoteRemovalOptions, clientset, "app="+PFEPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServicePFE = status
-	status, err = deleteService(remoteRemovalOptions, clientset, "app="+PerformancePrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServicePerformance = status
-	status, err = deleteService(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServiceGatekeeper = status
-
-	logr.Trace("Removing Codewind secrets")
-	status, err = deleteSecrets(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusSecretsCodewind = status
-
-	logr.Trace("Removing Codewind PVC")
-	status, err = deletePVC(remoteRemovalOptions, clientset, "app="+PFEPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusPVCCodewind = status
-
-	logr.Trace("Removing Codewind role bindings")
-	status, err = deleteRoleBindings(remoteRemovalOptions, clientset, "codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusRoleBindings = status
-
-	logr.Trace("Removing Codewind Tekton role bindings")
-	status, err = deleteTektonClusterRoleBindings(remoteRemovalOptions, clientset, "app="+CodewindTektonClusterRoleBindingName+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusTektonRoleBindings = status
-
-	logr.Trace("Removing Codewind service account")
-	status, err = deleteServiceAccount(remoteRemovalOptions, clientset, "app=codewind-"+remoteRemovalOptions.WorkspaceID+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServiceAccount = status
-
-	if onOpenShift {
-		logr.Trace("Removing Codewind route")
-		status, err = deleteRoute(config, remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-		removalStatus.StatusIngressGatekeeper = status
-	} else {
-		logr.Trace("Removing Codewind ingress")
-		status, err = deleteIngress(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-		removalStatus.StatusIngressGatekeeper = status
+	// Select the resources of the install by its ownership label when an install ID is given, otherwise by workspace
+	owned := "codewindWorkspace=" + remoteRemovalOptions.WorkspaceID
+	if remoteRemovalOptions.InstallID != "" {
+		owned = InstallIDLabel + "=" + remoteRemovalOptions.InstallID
+		if remoteRemovalOptions.WorkspaceID == "" {
+			// PFE does not label the workloads it creates for projects with the install ID, so find them by workspace
+			remoteRemovalOptions.WorkspaceID = findInstallWorkspace(clientset, namespace, remoteRemovalOptions.InstallID)
+		}
 	}
+	workspace := "," + owned
+	// Remove every ingress and route of the workspace, not just the Gatekeeper one, leaving any Keycloak in place
+	workspaceExposures := owned + ",app!=" + KeycloakPrefix
+	events.OnPhaseChange("Removing Codewind", 0)
+	runRemovals(events, []removal{
+		{"Codewind PFE deployment", func() {
+			removalStatus.StatusDeploymentPFE, _ = deleteDeployment(remoteRemovalOptions, clientset, "app="+PFEPrefix+workspace)
+		}},
+		{"Codewind Performance deployment", func() {
+			removalStatus.StatusDeploymentPerformance, _ = deleteDeployment(remoteRemovalOptions, clientset, "app="+PerformancePrefix+workspace)
+		}},
+		{"Codewind Gatekeeper deployment", func() {
+			removalStatus.StatusDeploymentGatekeeper, _ = deleteDeployment(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+workspace)
+		}},
+		{"Codewind PFE service", func() {
+			removalStatus.StatusServicePFE, _ = deleteService(remoteRemovalOptions, clientset, "app="+PFEPrefix+workspace)
+		}},
+		{"Codewind Performance service", func() {
+			removalStatus.StatusServicePerformance, _ = deleteService(remoteRemovalOptions, clientset, "app="+PerformancePrefix+workspace)
+		}},
+		{"Codewind Gatekeeper service", func() {
+			removalStatus.StatusServiceGatekeeper, _ = deleteService(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+workspace)
+		}},
+		{"Codewind project workloads", func() {
+			if remoteRemovalOptions.WorkspaceID != "" {
+				removalStatus.StatusProjects = K8sAPI{clientset: clientset}.deleteProjectWorkloads(remoteRemovalOptions.Namespace, remoteRemovalOptions.WorkspaceID)
+			}
+		}},
+		{"Codewind secrets", func() {
+			removalStatus.StatusSecretsCodewind, _ = deleteSecrets(remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+workspace)
+		}},
+		{"Codewind certificates", func() {
+			removalStatus.StatusCertificatesCodewind, _ = deleteCertManagerCertificates(config, remoteRemovalOptions, clientset, "app="+GatekeeperPrefix+workspace)
+		}},
+		{"Codewind PVC", func() {
+			removalStatus.StatusPVCCodewind, _ = deletePVC(remoteRemovalOptions, clientset, "app="+PFEPrefix+workspace)
+		}},
+		{"Codewind role bindings", func() {
+			removalStatus.StatusRoleBindings, _ = deleteRoleBindings(remoteRemovalOptions, clientset, owned)
+		}},
+		{"Codewind Tekton role bindings", func() {
+			removalStatus.StatusTektonRoleBindings, _ = deleteTektonClusterRoleBindings(remoteRemovalOptions, clientset, "app="+CodewindTektonClusterRoleBindingName+workspace)
+		}},
+		{"Codewind service account", func() {
+			removalStatus.StatusServiceAccount, _ = deleteServiceAccount(remoteRemovalOptions, clientset, serviceAccountSelector(remoteRemovalOptions, owned))
+		}},
+		{"Codewind ingresses and routes", func() {
+			removalStatus.StatusIngressGatekeeper, _ = deleteIngress(remoteRemovalOptions, clientset, workspaceExposures)
+			if onOpenShift {
+				status, _ := deleteRoute(config, remoteRemovalOptions, clientset, workspaceExposures)
+				if status != ResourceNotFound {
+					removalStatus.StatusIngressGatekeeper = status
+				}
+			}
+		}},
+		{"Codewind network policies", func() {
+			removalStatus.StatusNetworkPolicies, _ = deleteNetworkPolicies(remoteRemovalOptions, clientset, workspaceExposures)
+		}},
+		{"Codewind service monitors", func() {
+			removalStatus.StatusServiceMonitors, _ = deleteServiceMonitors(config, remoteRemovalOptions, owned)
+		}},
+		{"Codewind pod disruption budgets", func() {
+			removalStatus.StatusPodDisruptionBudgets, _ = deletePodDisruptionBudgets(remoteRemovalOptions, clientset, workspaceExposures)
+		}},
+		{"Codewind load test CronJobs", func() {
+			removalStatus.StatusLoadTestCronJobs, _ = deleteLoadTestCronJobs(remoteRemovalOptions, clientset, "app="+LoadTestPrefix+workspace)
+		}},
+		{"Codewind backup helper pods", func() {
+			removalStatus.StatusBackupHelperPods, _ = deletePods(remoteRemovalOptions, clientset, "app="+backupHelperPrefix+workspace)
+		}},
+	})
 
 	logr.Info("Removal summary:")
 	logr.Infof("Codewind PFE Deployment: %v", getStatus(removalStatus.StatusDeploymentPFE))
@@ -188,14 +276,57 @@ func RemoveRemote(remoteRemovalOptions *RemoveDeploymentOptions) (*RemovalResult
 	logr.Infof("Codewind Gatekeeper Deployment: %v", getStatus(removalStatus.StatusDeploymentGatekeeper))
 	logr.Infof("Codewind Gatekeeper Service: %v", getStatus(removalStatus.StatusServiceGatekeeper))
 	logr.Infof("Codewind Gatekeeper Ingress: %v", getStatus(removalStatus.StatusIngressGatekeeper))
+	logr.Infof("Codewind Gatekeeper Certificates: %v", getStatus(removalStatus.StatusCertificatesCodewind))
 	logr.Infof("Codewind Role Bindings: %v", getStatus(removalStatus.StatusRoleBindings))
 	logr.Infof("Codewind Tekton Role Bindings: %v", getStatus(removalStatus.StatusTektonRoleBindings))
 	logr.Infof("Codewind Service Account: %v", getStatus(removalStatus.StatusServiceAccount))
+	logr.Infof("Codewind Network Policies: %v", getStatus(removalStatus.StatusNetworkPolicies))
+	logr.Infof("Codewind Service Monitors: %v", getStatus(removalStatus.StatusServiceMonitors))
+	logr.Infof("Codewind Pod Disruption Budgets: %v", getStatus(removalStatus.StatusPodDisruptionBudgets))
+	logr.Infof("Codewind Load Test CronJobs: %v", getStatus(removalStatus.StatusLoadTestCronJobs))
+	logr.Infof("Codewind Backup Helper Pods: %v", getStatus(removalStatus.StatusBackupHelperPods))
+	for _, projectID := range sortedProjectIDs(removalStatus.StatusProjects) {
+		projectStatus := removalStatus.StatusProjects[projectID]
+		logr.Infof("Codewind Project %v Deployments: %v", projectID, getStatus(projectStatus.StatusDeployments))
+		logr.Infof("Codewind Project %v Services: %v", projectID, getStatus(projectStatus.StatusServices))
+	}
 	logr.Infof("Kubernetes namespace: CWCTL will not remove the namespace automatically, use 'kubectl delete namespace %s' if you would like to remove it", remoteRemovalOptions.Namespace)
 
 	return &removalStatus, nil
 }
 
+// serviceAccountSelector selects the Codewind service account of the workspace. When removing by install ID, the
+// accounts are selected by the ownership label like the other resources, leaving the Keycloak account in place.
+func serviceAccountSelector(remoteRemovalOptions *RemoveDeploymentOptions, owned string) string {
+	if remoteRemovalOptions.InstallID == "" {
+		return "app=codewind-" + remoteRemovalOptions.WorkspaceID + "," + owned
+	}
+	return owned + ",app!=keycloak-" + remoteRemovalOptions.WorkspaceID
+}
+
+// findInstallWorkspace returns the workspace of the PFE deployment created by an install or, when the deployment was
+// already removed, of its service accounts. It returns an empty string if there are neither.
+func findInstallWorkspace(clientset kubernetes.Interface, namespace string, installID string) string {
+	deployments, err := clientset.AppsV1().Deployments(namespace).List(v1.ListOptions{
+		LabelSelector: "app=" + PFEPrefix + "," + InstallIDLabel + "=" + installID,
+	})
+	if err == nil && len(deployments.Items) > 0 {
+		return deployments.Items[0].GetLabels()["codewindWorkspace"]
+	}
+	serviceAccounts, err := clientset.CoreV1().ServiceAccounts(namespace).List(v1.ListOptions{
+		LabelSelector: InstallIDLabel + "=" + installID,
+	})
+	if err == nil {
+		for _, serviceAccount := range serviceAccounts.Items {
+			if workspaceID := serviceAccount.GetLabels()["codewindWorkspace"]; workspaceID != "" {
+				return workspaceID
+			}
+		}
+	}
+	logr.Warnf("No Codewind PFE deployment found for install %v, project workloads will not be removed\n", installID)
+	return ""
+}
+
 // RemoveRemoteKeycloak : Remove remote keycloak install from Kube
 func RemoveRemoteKeycloak(remoteRemovalOptions *RemoveDeploymentOptions) (*RemovalResult, *RemInstError) {
 	namespace := remoteRemovalOptions.Namespace
@@ -204,19 +335,22 @@ func RemoveRemoteKeycloak(remoteRemovalOptions *RemoveDeploymentOptions) (*Remov
 		logr.Infof("Unable to retrieve Kubernetes Config %v\n", err)
 		return nil, &RemInstError{errOpNotFound, err, err.Error()}
 	}
+	events := eventsOrNone(remoteRemovalOptions.Events)
+	watchResources(config, events)
 
 	// Determine if we're running on OpenShift or not.
 	onOpenShift := kube.DetectOpenShift(config)
 	logr.Infof("Running on Openshift: %t\n", onOpenShift)
 
 	removalStatus := RemovalResult{
-		StatusPODKeycloak:        ResourceNotProcessed,
-		StatusServiceKeycloak:    ResourceNotProcessed,
-		StatusDeploymentKeycloak: ResourceNotProcessed,
-		StatusSecretsKeycloak:    ResourceNotProcessed,
-		StatusServiceAccount:     ResourceNotProcessed,
-		StatusPVCKeycloak:        ResourceNotProcessed,
-		StatusIngressKeycloak:    ResourceNotProcessed,
+		StatusPODKeycloak:          ResourceNotProcessed,
+		StatusServiceKeycloak:      ResourceNotProcessed,
+		StatusDeploymentKeycloak:   ResourceNotProcessed,
+		StatusSecretsKeycloak:      ResourceNotProcessed,
+		StatusCertificatesKeycloak: ResourceNotProcessed,
+		StatusServiceAccount:       ResourceNotProcessed,
+		StatusPVCKeycloak:          ResourceNotProcessed,
+		StatusIngressKeycloak:      ResourceNotProcessed,
 	}
 
 	if err != nil {
@@ -239,35 +373,53 @@ func RemoveRemoteKeycloak(remoteRemovalOptions *RemoveDeploymentOptions) (*Remov
 	}
 	logr.Infof("Found '%v' namespace\n", namespace)
 
-	logr.Trace("Removing Keycloak deployment")
-	status, err := deleteDeployment(remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusDeploymentKeycloak = status
-
-	logr.Trace("Removing Keycloak service")
-	status, err = deleteService(remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServiceKeycloak = status
-
-	logr.Trace("Removing Keycloak secrets")
-	status, err = deleteSecrets(remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusSecretsKeycloak = status
-
-	logr.Trace("Removing Keycloak PVC")
-	status, err = deletePVC(remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusPVCKeycloak = status
-
-	logr.Trace("Removing Keycloak service account")
-	status, err = deleteServiceAccount(remoteRemovalOptions, clientset, "app=keycloak-"+remoteRemovalOptions.WorkspaceID+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-	removalStatus.StatusServiceAccount = status
-
-	if onOpenShift {
-		logr.Trace("Removing Keycloak route")
-		status, err = deleteRoute(config, remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-		removalStatus.StatusIngressKeycloak = status
-	} else {
-		logr.Trace("Removing Keycloak ingress")
-		status, err = deleteIngress(remoteRemovalOptions, clientset, "app="+KeycloakPrefix+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
-		removalStatus.StatusIngressKeycloak = status
+	// Check no other Codewind workspaces are authenticating against this Keycloak
+	sharedWith, remInstErr := K8sAPI{clientset: clientset}.findKeycloakUsers(remoteRemovalOptions.WorkspaceID)
+	if remInstErr != nil {
+		return nil, remInstErr
 	}
+	if len(sharedWith) > 0 {
+		if !remoteRemovalOptions.Force {
+			err := errors.New(errKeycloakShared)
+			return nil, &RemInstError{errOpKeycloakShared, err, err.Error() + ": " + strings.Join(sharedWith, ", ")}
+		}
+		logr.Warnf("Removing Keycloak still used by workspaces: %v\n", strings.Join(sharedWith, ", "))
+	}
+
+	keycloak := "app=" + KeycloakPrefix + ",codewindWorkspace=" + remoteRemovalOptions.WorkspaceID
+	events.OnPhaseChange("Removing Keycloak", 0)
+	runRemovals(events, []removal{
+		{"Keycloak deployment", func() {
+			removalStatus.StatusDeploymentKeycloak, _ = deleteDeployment(remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak service", func() {
+			removalStatus.StatusServiceKeycloak, _ = deleteService(remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak secrets", func() {
+			removalStatus.StatusSecretsKeycloak, _ = deleteSecrets(remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak certificates", func() {
+			removalStatus.StatusCertificatesKeycloak, _ = deleteCertManagerCertificates(config, remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak network policy", func() {
+			removalStatus.StatusNetworkPolicies, _ = deleteNetworkPolicies(remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak PVC", func() {
+			removalStatus.StatusPVCKeycloak, _ = deletePVC(remoteRemovalOptions, clientset, keycloak)
+		}},
+		{"Keycloak service account", func() {
+			removalStatus.StatusServiceAccount, _ = deleteServiceAccount(remoteRemovalOptions, clientset, "app=keycloak-"+remoteRemovalOptions.WorkspaceID+",codewindWorkspace="+remoteRemovalOptions.WorkspaceID)
+		}},
+		{"Keycloak ingress and route", func() {
+			removalStatus.StatusIngressKeycloak, _ = deleteIngress(remoteRemovalOptions, clientset, keycloak)
+			if onOpenShift {
+				status, _ := deleteRoute(config, remoteRemovalOptions, clientset, keycloak)
+				if status != ResourceNotFound {
+					removalStatus.StatusIngressKeycloak = status
+				}
+			}
+		}},
+	})
 
 	logr.Info("Removal summary:")
 	logr.Infof("Keycloak Deployment: %v", getStatus(removalStatus.StatusDeploymentKeycloak))
@@ -275,11 +427,173 @@ func RemoveRemoteKeycloak(remoteRemovalOptions *RemoveDeploymentOptions) (*Remov
 	logr.Infof("Keycloak PVC: %v", getStatus(removalStatus.StatusPVCKeycloak))
 	logr.Infof("Keycloak Ingress: %v", getStatus(removalStatus.StatusIngressKeycloak))
 	logr.Infof("Keycloak Secrets: %v", getStatus(removalStatus.StatusSecretsKeycloak))
+	logr.Infof("Keycloak Certificates: %v", getStatus(removalStatus.StatusCertificatesKeycloak))
 	logr.Infof("Keycloak Service Account: %v", getStatus(removalStatus.StatusServiceAccount))
+	logr.Infof("Keycloak Network Policy: %v", getStatus(removalStatus.StatusNetworkPolicies))
 	logr.Infof("Kubernetes namespace: CWCTL will not remove the namespace automatically, use 'kubectl delete namespace %s' if you would like to remove it", remoteRemovalOptions.Namespace)
 	return &removalStatus, nil
 }
 
+// findKeycloakUsers returns the IDs of the other workspaces, in any namespace, whose Gatekeeper authenticates against
+// the Keycloak deployed by the given workspace
+func (client K8sAPI) findKeycloakUsers(keycloakWorkspaceID string) ([]string, *RemInstError) {
+	gatekeepers, err := client.clientset.AppsV1().Deployments("").List(v1.ListOptions{
+		LabelSelector: "app=" + GatekeeperPrefix,
+	})
+	if err != nil {
+		return nil, &RemInstError{errOpNotFound, err, err.Error()}
+	}
+
+	// Keycloak may have been given its own hostname at install, which the Gatekeeper of its workspace authenticates
+	// against, rather than one derived from the workspace ID
+	keycloakHost := KeycloakPrefix + "-" + keycloakWorkspaceID + "."
+	for _, gatekeeper := range gatekeepers.Items {
+		if gatekeeper.GetLabels()["codewindWorkspace"] == keycloakWorkspaceID {
+			if authURL, err := url.Parse(gatekeeperAuthURL(gatekeeper)); err == nil && authURL.Host != "" {
+				keycloakHost = "//" + authURL.Host + "/"
+			}
+		}
+	}
+
+	users := []string{}
+	for _, gatekeeper := range gatekeepers.Items {
+		workspaceID := gatekeeper.GetLabels()["codewindWorkspace"]
+		if workspaceID == keycloakWorkspaceID {
+			continue
+		}
+		if strings.Contains(gatekeeperAuthURL(gatekeeper)+"/", keycloakHost) {
+			users = append(users, gatekeeper.GetNamespace()+"/"+workspaceID)
+		}
+	}
+	return users, nil
+}
+
+// gatekeeperAuthURL returns the URL of the Keycloak a Gatekeeper deployment authenticates against
+func gatekeeperAuthURL(gatekeeper appsv1.Deployment) string {
+	for _, container := range gatekeeper.Spec.Template.Spec.Containers {
+		for _, envVar := range container.Env {
+			if envVar.Name == "AUTH_URL" {
+				return envVar.Value
+			}
+		}
+	}
+	return ""
+}
+
+// removalConcurrency : The most kinds of resource removed from the cluster at the same time, each removal sending a
+// list request followed by a delete request for each resource found
+const removalConcurrency = 4
+
+// removal removes one kind of resource and records its status in the removal result
+type removal struct {
+	description string
+	remove      func()
+}
+
+// runRemovals runs removals a few at a time, returning once they have all finished, and reports each one finishing to
+// the events. Removals must record their status in different fields of the result. The order does not matter, as
+// Kubernetes keeps a PVC until the pods using it are gone, and removes the pods of a deleted deployment itself.
+func runRemovals(events Events, removals []removal) {
+	done := &progress{events: events, total: len(removals)}
+	slots := make(chan struct{}, removalConcurrency)
+	var wg sync.WaitGroup
+	for _, r := range removals {
+		wg.Add(1)
+		go func(r removal) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+			logr.Trace("Removing " + r.description)
+			r.remove()
+			done.finished("Removed " + r.description)
+		}(r)
+	}
+	wg.Wait()
+}
+
+// deleteProjectWorkloads removes the deployments and services PFE created for the projects bound to a workspace,
+// returning a status for each project ID found
+func (client K8sAPI) deleteProjectWorkloads(namespace string, workspaceID string) map[string]ProjectRemovalResult {
+	results := map[string]ProjectRemovalResult{}
+	listOptions := v1.ListOptions{LabelSelector: "codewindWorkspace=" + workspaceID + "," + projectIDLabel}
+
+	var mutex sync.Mutex
+	record := func(projectID string, err error, isDeployment bool) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		result := results[projectID]
+		if isDeployment {
+			result.StatusDeployments = mergeStatus(result.StatusDeployments, removalPhase(err))
+		} else {
+			result.StatusServices = mergeStatus(result.StatusServices, removalPhase(err))
+		}
+		results[projectID] = result
+	}
+
+	removals := []removal{}
+	deployments, err := client.clientset.AppsV1().Deployments(namespace).List(listOptions)
+	if err != nil {
+		logr.Errorf("Unable to list project deployments: %v\n", err)
+	} else {
+		for _, deployment := range deployments.Items {
+			name, projectID := deployment.GetName(), deployment.GetLabels()[projectIDLabel]
+			removals = append(removals, removal{"project deployment " + name, func() {
+				record(projectID, client.clientset.AppsV1().Deployments(namespace).Delete(name, nil), true)
+			}})
+		}
+	}
+
+	services, err := client.clientset.CoreV1().Services(namespace).List(listOptions)
+	if err != nil {
+		logr.Errorf("Unable to list project services: %v\n", err)
+	} else {
+		for _, service := range services.Items {
+			name, projectID := service.GetName(), service.GetLabels()[projectIDLabel]
+			removals = append(removals, removal{"project service " + name, func() {
+				record(projectID, client.clientset.CoreV1().Services(namespace).Delete(name, nil), false)
+			}})
+		}
+	}
+	runRemovals(noEvents{}, removals)
+
+	// Report what was missing for projects that only had one kind of workload
+	for projectID, result := range results {
+		if result.StatusDeployments == ResourceNotProcessed {
+			result.StatusDeployments = ResourceNotFound
+		}
+		if result.StatusServices == ResourceNotProcessed {
+			result.StatusServices = ResourceNotFound
+		}
+		results[projectID] = result
+	}
+	return results
+}
+
+// removalPhase converts the result of a delete call into a removal status
+func removalPhase(err error) int {
+	if err != nil {
+		return ResourceRemoveFailed
+	}
+	return ResourceRemoved
+}
+
+// mergeStatus keeps a failure sticky when a project has more than one resource of a kind
+func mergeStatus(current int, next int) int {
+	if current == ResourceRemoveFailed {
+		return current
+	}
+	return next
+}
+
+func sortedProjectIDs(projects map[string]ProjectRemovalResult) []string {
+	projectIDs := make([]string, 0, len(projects))
+	for projectID := range projects {
+		projectIDs = append(projectIDs, projectID)
+	}
+	sort.Strings(projectIDs)
+	return projectIDs
+}
+
 func getStatus(status int) string {
 	switch status {
 	case ResourceNotProcessed:
@@ -537,3 +851,89 @@ func deleteRoute(config *restclient.Config, remoteRemovalOptions *RemoveDeployme
 	}
 	return phase, nil
 }
+
+func deleteNetworkPolicies(remoteRemovalOptions *RemoveDeploymentOptions, clientset *kubernetes.Clientset, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	resourceList, err := clientset.NetworkingV1().NetworkPolicies(remoteRemovalOptions.Namespace).List(
+		v1.ListOptions{LabelSelector: labelSelector},
+	)
+	if err != nil {
+		return phase, err
+	}
+	if resourceList != nil && resourceList.Items != nil && len(resourceList.Items) > 0 {
+		phase = ResourceFound
+		for _, resource := range resourceList.Items {
+			err := clientset.NetworkingV1().NetworkPolicies(remoteRemovalOptions.Namespace).Delete(resource.GetObjectMeta().GetName(), nil)
+			if err != nil {
+				phase = ResourceRemoveFailed
+			} else {
+				phase = ResourceRemoved
+			}
+		}
+	} else {
+		phase = ResourceNotFound
+	}
+	return phase, nil
+}
+
+func deletePodDisruptionBudgets(remoteRemovalOptions *RemoveDeploymentOptions, clientset *kubernetes.Clientset, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	resourceList, err := clientset.PolicyV1beta1().PodDisruptionBudgets(remoteRemovalOptions.Namespace).List(
+		v1.ListOptions{LabelSelector: labelSelector},
+	)
+	if err != nil {
+		return phase, err
+	}
+	if resourceList != nil && resourceList.Items != nil && len(resourceList.Items) > 0 {
+		phase = ResourceFound
+		for _, resource := range resourceList.Items {
+			err := clientset.PolicyV1beta1().PodDisruptionBudgets(remoteRemovalOptions.Namespace).Delete(resource.GetObjectMeta().GetName(), nil)
+			if err != nil {
+				phase = ResourceRemoveFailed
+			} else {
+				phase = ResourceRemoved
+			}
+		}
+	} else {
+		phase = ResourceNotFound
+	}
+	return phase, nil
+}
+
+func deleteLoadTestCronJobs(remoteRemovalOptions *RemoveDeploymentOptions, clientset *kubernetes.Clientset, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	resourceList, err := clientset.BatchV1beta1().CronJobs(remoteRemovalOptions.Namespace).List(
+		v1.ListOptions{LabelSelector: labelSelector},
+	)
+	if err != nil {
+		return phase, err
+	}
+	if resourceList != nil && len(resourceList.Items) > 0 {
+		for _, resource := range resourceList.Items {
+			if DeleteLoadTestCronJob(remoteRemovalOptions.Namespace, resource.GetName(), clientset) != nil {
+				phase = ResourceRemoveFailed
+			} else {
+				phase = ResourceRemoved
+			}
+		}
+	}
+	return phase, nil
+}
+
+func deletePods(remoteRemovalOptions *RemoveDeploymentOptions, clientset kubernetes.Interface, labelSelector string) (int, error) {
+	phase := ResourceNotFound
+	resourceList, err := clientset.CoreV1().Pods(remoteRemovalOptions.Namespace).List(
+		v1.ListOptions{LabelSelector: labelSelector},
+	)
+	if err != nil {
+		return phase, err
+	}
+	for _, resource := range resourceList.Items {
+		if clientset.CoreV1().Pods(remoteRemovalOptions.Namespace).Delete(resource.GetName(), nil) != nil {
+			phase = ResourceRemoveFailed
+		} else {
+			phase = ResourceRemoved
+		}
+	}
+	return phase, nil
+}
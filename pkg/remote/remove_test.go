@@ -0,0 +1,148 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFindKeycloakUsers(t *testing.T) {
+	ownGatekeeper := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "KID1"},
+		Env:       []corev1.EnvVar{{Name: "AUTH_URL", Value: "https://codewind-keycloak-KID1.10.0.0.1.nip.io"}},
+	})
+	sharingGatekeeper := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test2",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID2"},
+		Env:       []corev1.EnvVar{{Name: "AUTH_URL", Value: "https://codewind-keycloak-KID1.10.0.0.1.nip.io"}},
+	})
+	otherGatekeeper := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID3"},
+		Env:       []corev1.EnvVar{{Name: "AUTH_URL", Value: "https://codewind-keycloak-WID3.10.0.0.1.nip.io"}},
+	})
+	client := K8sAPI{clientset: fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{ownGatekeeper, sharingGatekeeper, otherGatekeeper}})}
+
+	t.Run("success case - finds workspaces in other namespaces using the Keycloak", func(t *testing.T) {
+		users, err := client.findKeycloakUsers("KID1")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"test2/WID2"}, users)
+	})
+
+	t.Run("success case - Keycloak used only by its own workspace", func(t *testing.T) {
+		users, err := client.findKeycloakUsers("WID3")
+		assert.Nil(t, err)
+		assert.Empty(t, users)
+	})
+
+	t.Run("success case - finds workspaces using a Keycloak installed with its own hostname", func(t *testing.T) {
+		customGatekeeper := func(namespace string, workspaceID string, authURL string) v1.Deployment {
+			return generateMockDeployment(MockDeploymentOptions{
+				Namespace: namespace,
+				Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": workspaceID},
+				Env:       []corev1.EnvVar{{Name: "AUTH_URL", Value: authURL}},
+			})
+		}
+		client := K8sAPI{clientset: fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{
+			customGatekeeper("test1", "KID4", "https://auth.example.com"),
+			customGatekeeper("test2", "WID5", "https://auth.example.com/auth"),
+			customGatekeeper("test3", "WID6", "https://auth.example.com.other.org"),
+		}})}
+		users, err := client.findKeycloakUsers("KID4")
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"test2/WID5"}, users)
+	})
+}
+
+func TestDeleteProjectWorkloads(t *testing.T) {
+	projectLabels := func(workspaceID string, projectID string) map[string]string {
+		return map[string]string{"codewindWorkspace": workspaceID, projectIDLabel: projectID}
+	}
+	clientset := fake.NewSimpleClientset(
+		&v1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cw-nodeproject", Namespace: "test", Labels: projectLabels("WID1", "P1")}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "cw-nodeproject", Namespace: "test", Labels: projectLabels("WID1", "P1")}},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "cw-javaproject", Namespace: "test", Labels: projectLabels("WID1", "P2")}},
+		&v1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "cw-otherproject", Namespace: "test", Labels: projectLabels("WID2", "P3")}},
+		&v1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: PFEPrefix + "-WID1", Namespace: "test", Labels: map[string]string{"app": PFEPrefix, "codewindWorkspace": "WID1"}}},
+	)
+
+	results := K8sAPI{clientset: clientset}.deleteProjectWorkloads("test", "WID1")
+	assert.Equal(t, map[string]ProjectRemovalResult{
+		"P1": {StatusDeployments: ResourceRemoved, StatusServices: ResourceRemoved},
+		"P2": {StatusDeployments: ResourceNotFound, StatusServices: ResourceRemoved},
+	}, results)
+
+	deployments, _ := clientset.AppsV1().Deployments("test").List(metav1.ListOptions{})
+	names := []string{}
+	for _, deployment := range deployments.Items {
+		names = append(names, deployment.GetName())
+	}
+	assert.ElementsMatch(t, []string{"cw-otherproject", PFEPrefix + "-WID1"}, names)
+	assert.Equal(t, []string{"P1", "P2"}, sortedProjectIDs(results))
+}
+
+func TestDeletePods(t *testing.T) {
+	helperLabels := map[string]string{"app": backupHelperPrefix, "codewindWorkspace": "WID1", InstallIDLabel: "install1"}
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: backupHelperPrefix + "-WID1", Namespace: "test", Labels: helperLabels}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "cw-nodeproject", Namespace: "test", Labels: map[string]string{"codewindWorkspace": "WID1"}}},
+	)
+	options := &RemoveDeploymentOptions{Namespace: "test", InstallID: "install1"}
+
+	status, err := deletePods(options, clientset, "app="+backupHelperPrefix+","+InstallIDLabel+"=install1")
+	assert.Nil(t, err)
+	assert.Equal(t, ResourceRemoved, status)
+	pods, _ := clientset.CoreV1().Pods("test").List(metav1.ListOptions{})
+	assert.Len(t, pods.Items, 1)
+	assert.Equal(t, "cw-nodeproject", pods.Items[0].GetName())
+
+	status, _ = deletePods(options, clientset, "app="+backupHelperPrefix+","+InstallIDLabel+"=install1")
+	assert.Equal(t, ResourceNotFound, status)
+}
+
+func TestRunRemovals(t *testing.T) {
+	var mutex sync.Mutex
+	running, mostRunning, removed := 0, 0, 0
+	removals := []removal{}
+	for i := 0; i < 3*removalConcurrency; i++ {
+		removals = append(removals, removal{"resource", func() {
+			mutex.Lock()
+			running++
+			if running > mostRunning {
+				mostRunning = running
+			}
+			mutex.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mutex.Lock()
+			running--
+			removed++
+			mutex.Unlock()
+		}})
+	}
+
+	events := &recordedEvents{}
+	runRemovals(events, removals)
+	assert.Equal(t, 3*removalConcurrency, removed)
+	assert.Len(t, events.phases, 3*removalConcurrency)
+	assert.Equal(t, "Removed resource 100", events.phases[len(events.phases)-1])
+	assert.True(t, mostRunning > 1, "removals should run at the same time")
+	assert.True(t, mostRunning <= removalConcurrency, "at most %d removals should run at the same time", removalConcurrency)
+}
@@ -0,0 +1,68 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// ParseResourceRequirements converts a comma separated list of resource settings, for example
+// "requests.cpu=500m,requests.memory=1Gi,limits.cpu=1,limits.memory=2Gi", into container resource requirements.
+// An empty string returns empty requirements, leaving the cluster defaults in place.
+func ParseResourceRequirements(resources string) (corev1.ResourceRequirements, error) {
+	requirements := corev1.ResourceRequirements{}
+	if strings.TrimSpace(resources) == "" {
+		return requirements, nil
+	}
+
+	for _, setting := range strings.Split(resources, ",") {
+		keyValue := strings.SplitN(strings.TrimSpace(setting), "=", 2)
+		if len(keyValue) != 2 {
+			return corev1.ResourceRequirements{}, errors.New(errBadResourceSetting + ": " + setting)
+		}
+
+		keyParts := strings.SplitN(keyValue[0], ".", 2)
+		if len(keyParts) != 2 {
+			return corev1.ResourceRequirements{}, errors.New(errBadResourceSetting + ": " + setting)
+		}
+
+		resourceName := corev1.ResourceName(keyParts[1])
+		if resourceName != corev1.ResourceCPU && resourceName != corev1.ResourceMemory {
+			return corev1.ResourceRequirements{}, errors.New(errBadResourceSetting + ": " + setting)
+		}
+
+		quantity, err := resource.ParseQuantity(keyValue[1])
+		if err != nil {
+			return corev1.ResourceRequirements{}, errors.New(errBadResourceSetting + ": " + setting)
+		}
+
+		switch keyParts[0] {
+		case "requests":
+			if requirements.Requests == nil {
+				requirements.Requests = corev1.ResourceList{}
+			}
+			requirements.Requests[resourceName] = quantity
+		case "limits":
+			if requirements.Limits == nil {
+				requirements.Limits = corev1.ResourceList{}
+			}
+			requirements.Limits[resourceName] = quantity
+		default:
+			return corev1.ResourceRequirements{}, errors.New(errBadResourceSetting + ": " + setting)
+		}
+	}
+	return requirements, nil
+}
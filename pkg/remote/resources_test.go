@@ -0,0 +1,58 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestParseResourceRequirements(t *testing.T) {
+	t.Run("success case - empty string returns empty requirements", func(t *testing.T) {
+		requirements, err := ParseResourceRequirements("")
+		assert.Nil(t, err)
+		assert.Equal(t, corev1.ResourceRequirements{}, requirements)
+	})
+
+	t.Run("success case - requests and limits are parsed", func(t *testing.T) {
+		requirements, err := ParseResourceRequirements("requests.cpu=500m, requests.memory=1Gi,limits.cpu=1,limits.memory=2Gi")
+		expectedRequirements := corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		}
+		assert.Nil(t, err)
+		assert.Equal(t, expectedRequirements, requirements)
+	})
+
+	t.Run("fail cases - invalid settings are rejected", func(t *testing.T) {
+		invalidSettings := []string{
+			"cpu=500m",
+			"requests.cpu",
+			"requests.gpu=1",
+			"maximum.cpu=1",
+			"limits.memory=lots",
+		}
+		for _, setting := range invalidSettings {
+			_, err := ParseResourceRequirements(setting)
+			assert.Error(t, err, setting)
+		}
+	})
+}
@@ -0,0 +1,282 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+	"github.com/urfave/cli"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// RestartedAtAnnotation is set on the pod template of a deployment to restart its pods
+const RestartedAtAnnotation = "codewind.eclipse.org/restartedAt"
+
+// Names of the secrets holding the credentials of a workspace, suffixed with the workspace ID
+const (
+	clientSecretName       = "secret-codewind-client"
+	sessionSecretName      = "secret-codewind-session"
+	keycloakUserSecretName = "secret-keycloak-user"
+	gatekeeperTLSName      = "secret-codewind-tls"
+	keycloakTLSName        = "secret-keycloak-tls"
+)
+
+// RotateSecretsOptions : Secret rotation options
+type RotateSecretsOptions struct {
+	Namespace   string
+	WorkspaceID string
+	// AdminPassword also rotates the Keycloak admin password, which is only possible when Keycloak is in the workspace
+	AdminPassword bool
+	// KeycloakUser and KeycloakPassword are needed when Keycloak is not in the workspace, as its secret is not there
+	KeycloakUser     string
+	KeycloakPassword string
+	Timeout          time.Duration
+}
+
+// RotateSecretsResult : The secrets rotated, and the deployments restarted to use them
+type RotateSecretsResult struct {
+	GatekeeperURL string   `json:"gatekeeperURL"`
+	Rotated       []string `json:"rotated"`
+	Restarted     []string `json:"restarted"`
+	// KeycloakUser is the Keycloak admin user, and KeycloakPassword its new password when AdminPassword was set
+	KeycloakUser     string `json:"-"`
+	KeycloakPassword string `json:"-"`
+}
+
+// RotateSecrets replaces the Gatekeeper client and session secrets of a workspace, and optionally the Keycloak admin
+// password, then restarts Keycloak and the Gatekeeper in turn so they use them. Each secret is stored as soon as
+// Keycloak accepts it, so a failure part way leaves every stored secret valid and the rotation can be run again.
+func RotateSecrets(rotateOptions *RotateSecretsOptions, clientset kubernetes.Interface, httpClient utils.HTTPClient) (*RotateSecretsResult, *RemInstError) {
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	namespace := rotateOptions.Namespace
+	gatekeeperName := GatekeeperPrefix + "-" + rotateOptions.WorkspaceID
+
+	gatekeeper, err := client.clientset.AppsV1().Deployments(namespace).Get(gatekeeperName, metav1.GetOptions{})
+	if err != nil {
+		err = errors.New(errTargetNotFound)
+		return nil, &RemInstError{errOpNotFound, err, err.Error() + ": " + gatekeeperName}
+	}
+	env := containerEnv(gatekeeper)
+	result := &RotateSecretsResult{
+		GatekeeperURL:    "https://" + env["GATEKEEPER_HOST"],
+		Rotated:          []string{},
+		Restarted:        []string{},
+		KeycloakUser:     rotateOptions.KeycloakUser,
+		KeycloakPassword: rotateOptions.KeycloakPassword,
+	}
+
+	// Keycloak is in the workspace when its deployment is. Its admin credentials are in the secret generated by the
+	// install, or in an existing secret given at install, which is managed outside Codewind and so is not rotated.
+	keycloak, err := client.clientset.AppsV1().Deployments(namespace).Get(KeycloakPrefix+"-"+rotateOptions.WorkspaceID, metav1.GetOptions{})
+	keycloakInWorkspace := err == nil
+	adminSecretName := ""
+	if keycloakInWorkspace {
+		adminSecretName = containerEnvSecret(keycloak, "KEYCLOAK_PASSWORD")
+	}
+	if adminSecretName != "" && result.KeycloakUser == "" {
+		adminSecret, err := client.clientset.CoreV1().Secrets(namespace).Get(adminSecretName, metav1.GetOptions{})
+		if err == nil {
+			result.KeycloakUser = secretValue(adminSecret.Data, adminSecret.StringData, keycloakAdminUserKey)
+			result.KeycloakPassword = secretValue(adminSecret.Data, adminSecret.StringData, keycloakAdminPasswordKey)
+		}
+	}
+	if result.KeycloakUser == "" || result.KeycloakPassword == "" {
+		err = errors.New(errNoKeycloakAdmin)
+		return nil, &RemInstError{errOpRotate, err, err.Error()}
+	}
+	if rotateOptions.AdminPassword && !keycloakInWorkspace {
+		err = errors.New(errKeycloakNotInWorkspace)
+		return nil, &RemInstError{errOpRotate, err, err.Error()}
+	}
+	if rotateOptions.AdminPassword && adminSecretName != keycloakUserSecretName+"-"+rotateOptions.WorkspaceID {
+		err = errors.New(errExistingAdminSecret + ": " + adminSecretName)
+		return nil, &RemInstError{errOpRotate, err, err.Error()}
+	}
+
+	logr.Infoln("Authenticating to Keycloak at " + env["AUTH_URL"])
+	flagSet := flag.NewFlagSet("authentication", 0)
+	flagSet.String("host", env["AUTH_URL"], "doc")
+	flagSet.String("realm", security.KeycloakMasterRealm, "doc")
+	flagSet.String("username", result.KeycloakUser, "doc")
+	flagSet.String("password", result.KeycloakPassword, "doc")
+	flagSet.String("client", security.KeycloakAdminClientID, "doc")
+	tokens, secErr := security.SecAuthenticate(httpClient, cli.NewContext(nil, flagSet, nil), "", "")
+	if secErr != nil {
+		return nil, &RemInstError{errOpRotate, secErr.Err, secErr.Desc}
+	}
+
+	if rotateOptions.AdminPassword {
+		newPassword, err := randomSecret()
+		if err != nil {
+			return nil, &RemInstError{errOpRotate, err, err.Error()}
+		}
+		logr.Infof("Rotating the password of Keycloak admin user '%v'\n", result.KeycloakUser)
+		passwordFlagSet := flag.NewFlagSet("updateUser", 0)
+		passwordFlagSet.String("host", env["AUTH_URL"], "doc")
+		passwordFlagSet.String("realm", security.KeycloakMasterRealm, "doc")
+		passwordFlagSet.String("name", result.KeycloakUser, "doc")
+		passwordFlagSet.String("newpw", newPassword, "doc")
+		passwordFlagSet.String("accesstoken", tokens.AccessToken, "doc")
+		secErr = security.SecUserSetPW(cli.NewContext(nil, passwordFlagSet, nil))
+		if secErr != nil {
+			return nil, &RemInstError{errOpRotate, secErr.Err, secErr.Desc}
+		}
+		result.KeycloakPassword = newPassword
+		remInstErr = client.updateSecret(namespace, adminSecretName, keycloakAdminPasswordKey, newPassword)
+		if remInstErr != nil {
+			return nil, remInstErr
+		}
+		result.Rotated = append(result.Rotated, keycloakAdminPasswordKey)
+	}
+
+	logr.Infof("Rotating the secret of Keycloak client '%v'\n", env["CLIENT_ID"])
+	clientFlagSet := flag.NewFlagSet("regenerateClientSecret", 0)
+	clientFlagSet.String("host", env["AUTH_URL"], "doc")
+	clientFlagSet.String("realm", env["REALM"], "doc")
+	clientFlagSet.String("clientid", env["CLIENT_ID"], "doc")
+	clientFlagSet.String("accesstoken", tokens.AccessToken, "doc")
+	clientSecret, secErr := security.SecClientRegenerateSecret(httpClient, cli.NewContext(nil, clientFlagSet, nil))
+	if secErr != nil {
+		return nil, &RemInstError{errOpRotate, secErr.Err, secErr.Desc}
+	}
+	remInstErr = client.updateSecret(namespace, clientSecretName+"-"+rotateOptions.WorkspaceID, "client_secret", clientSecret.Secret)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	result.Rotated = append(result.Rotated, "client_secret")
+
+	sessionSecret, err := randomSecret()
+	if err != nil {
+		return nil, &RemInstError{errOpRotate, err, err.Error()}
+	}
+	remInstErr = client.updateSecret(namespace, sessionSecretName+"-"+rotateOptions.WorkspaceID, "session_secret", sessionSecret)
+	if remInstErr != nil {
+		return nil, remInstErr
+	}
+	result.Rotated = append(result.Rotated, "session_secret")
+
+	// Keycloak is restarted first so that its environment matches the new admin password, and is ready before the
+	// Gatekeeper restarts, as the Gatekeeper reads the Keycloak configuration on startup
+	restarts := []string{gatekeeperName}
+	if rotateOptions.AdminPassword {
+		restarts = []string{KeycloakPrefix + "-" + rotateOptions.WorkspaceID, gatekeeperName}
+	}
+	timeout := rotateOptions.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	for _, deploymentName := range restarts {
+		remInstErr = client.restartDeployment(namespace, deploymentName, timeout)
+		if remInstErr != nil {
+			return result, remInstErr
+		}
+		result.Restarted = append(result.Restarted, deploymentName)
+	}
+	return result, nil
+}
+
+// containerEnv returns the literal environment variables of the first container of a deployment
+func containerEnv(deployment *appsv1.Deployment) map[string]string {
+	env := map[string]string{}
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		for _, e := range containers[0].Env {
+			env[e.Name] = e.Value
+		}
+	}
+	return env
+}
+
+// containerEnvSecret returns the secret an environment variable of the first container of a deployment is read from
+func containerEnvSecret(deployment *appsv1.Deployment, name string) string {
+	if containers := deployment.Spec.Template.Spec.Containers; len(containers) > 0 {
+		for _, e := range containers[0].Env {
+			if e.Name == name && e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+				return e.ValueFrom.SecretKeyRef.Name
+			}
+		}
+	}
+	return ""
+}
+
+// secretValue returns a value of a secret, which is in StringData until the secret has been written to the cluster
+func secretValue(data map[string][]byte, stringData map[string]string, key string) string {
+	if value, ok := data[key]; ok {
+		return string(value)
+	}
+	return stringData[key]
+}
+
+// randomSecret returns 32 random bytes, hex encoded
+func randomSecret() (string, error) {
+	secret := make([]byte, 32)
+	_, err := rand.Read(secret)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// updateSecret sets one value of an existing secret
+func (client K8sAPI) updateSecret(namespace string, name string, key string, value string) *RemInstError {
+	secret, err := client.clientset.CoreV1().Secrets(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	delete(secret.Data, key)
+	if secret.StringData == nil {
+		secret.StringData = map[string]string{}
+	}
+	secret.StringData[key] = value
+
+	logr.Infof("Updating secret '%v'\n", name)
+	_, err = client.clientset.CoreV1().Secrets(namespace).Update(secret)
+	if err != nil {
+		return &RemInstError{errOpRotate, err, err.Error()}
+	}
+	return nil
+}
+
+// restartDeployment replaces the pods of a deployment by changing an annotation of its pod template, then waits
+// for the rollout to complete
+func (client K8sAPI) restartDeployment(namespace string, name string, timeout time.Duration) *RemInstError {
+	deployment, err := client.clientset.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if deployment.Spec.Template.Annotations == nil {
+		deployment.Spec.Template.Annotations = map[string]string{}
+	}
+	deployment.Spec.Template.Annotations[RestartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+	logr.Infof("Restarting deployment '%v'\n", name)
+	_, err = client.clientset.AppsV1().Deployments(namespace).Update(deployment)
+	if err != nil {
+		return &RemInstError{errOpRotate, err, err.Error()}
+	}
+	err = WaitForDeploymentReady(client.clientset, namespace, name, timeout)
+	if err != nil {
+		return &RemInstError{errOpReadyTimeout, errors.New(errReadyTimeout), errReadyTimeout + ": " + name}
+	}
+	return nil
+}
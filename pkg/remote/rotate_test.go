@@ -0,0 +1,181 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// newMockRotationKeycloak returns a Keycloak admin API which records the requests made to it
+func newMockRotationKeycloak(requests map[string]string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+		switch r.Method + " " + r.URL.Path {
+		case "POST /auth/realms/master/protocol/openid-connect/token":
+			w.Write([]byte(`{"access_token": "admintoken", "refresh_token": "refreshtoken"}`))
+		case "GET /auth/admin/realms/master/users":
+			w.Write([]byte(`[{"id": "u1", "username": "admin"}]`))
+		case "PUT /auth/admin/realms/master/users/u1/reset-password":
+			w.WriteHeader(http.StatusNoContent)
+		case "GET /auth/admin/realms/codewind/clients":
+			w.Write([]byte(`[{"id": "c1", "clientId": "codewind-backend"}]`))
+		case "POST /auth/admin/realms/codewind/clients/c1/client-secret":
+			w.Write([]byte(`{"type": "secret", "value": "newclientsecret"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+// generateMockRotationWorkspace returns the ready Keycloak and Gatekeeper deployments of a workspace, and its secrets.
+// Keycloak reads its admin credentials from adminSecret when set, as though it were given at install.
+func generateMockRotationWorkspace(authURL string, withKeycloak bool, adminSecret string) *fake.Clientset {
+	ready := appsv1.DeploymentStatus{Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1}
+	gatekeeper := generateMockDeployment(MockDeploymentOptions{
+		Namespace: MockCodewind.Namespace,
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": MockCodewind.WorkspaceID},
+		Env: []corev1.EnvVar{
+			{Name: "AUTH_URL", Value: authURL},
+			{Name: "REALM", Value: "codewind"},
+			{Name: "CLIENT_ID", Value: "codewind-backend"},
+			{Name: "GATEKEEPER_HOST", Value: "codewind-gatekeeper-" + MockCodewind.WorkspaceID + ".10.0.0.1.nip.io"},
+		},
+	})
+	gatekeeper.Status = ready
+	objects := []runtime.Object{
+		&gatekeeper,
+		secretWith(clientSecretName, "client_secret", "oldclientsecret"),
+		secretWith(sessionSecretName, "session_secret", "oldsessionsecret"),
+	}
+	if withKeycloak {
+		codewind := MockCodewind
+		codewind.KeycloakAdminSecret = adminSecret
+		keycloak := generateKeycloakDeploy(codewind)
+		keycloak.Status = ready
+		keycloakSecret := secretWith(keycloakUserSecretName, keycloakAdminPasswordKey, "oldpassword")
+		keycloakSecret.Data[keycloakAdminUserKey] = []byte("admin")
+		if adminSecret != "" {
+			keycloakSecret.ObjectMeta.Name = adminSecret
+		}
+		objects = append(objects, &keycloak, keycloakSecret)
+	}
+	return fake.NewSimpleClientset(objects...)
+}
+
+func secretWith(name string, key string, value string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name + "-" + MockCodewind.WorkspaceID, Namespace: MockCodewind.Namespace},
+		Data:       map[string][]byte{key: []byte(value)},
+	}
+}
+
+func getSecretValue(t *testing.T, client *fake.Clientset, name string, key string) string {
+	secret, err := client.CoreV1().Secrets(MockCodewind.Namespace).Get(name+"-"+MockCodewind.WorkspaceID, metav1.GetOptions{})
+	assert.Nil(t, err)
+	return secretValue(secret.Data, secret.StringData, key)
+}
+
+func TestRotateSecrets(t *testing.T) {
+	rotateOptions := RotateSecretsOptions{Namespace: MockCodewind.Namespace, WorkspaceID: MockCodewind.WorkspaceID}
+
+	t.Run("success case - rotates the gatekeeper secrets and restarts the gatekeeper", func(t *testing.T) {
+		requests := map[string]string{}
+		server := newMockRotationKeycloak(requests)
+		defer server.Close()
+		client := generateMockRotationWorkspace(server.URL, true, "")
+
+		result, remInstErr := RotateSecrets(&rotateOptions, client, http.DefaultClient)
+		assert.Nil(t, remInstErr)
+		assert.Equal(t, []string{"client_secret", "session_secret"}, result.Rotated)
+		assert.Equal(t, []string{GatekeeperPrefix + "-" + MockCodewind.WorkspaceID}, result.Restarted)
+		assert.Equal(t, "https://codewind-gatekeeper-"+MockCodewind.WorkspaceID+".10.0.0.1.nip.io", result.GatekeeperURL)
+		assert.Contains(t, requests["POST /auth/realms/master/protocol/openid-connect/token"], "password=oldpassword")
+		assert.NotContains(t, requests, "PUT /auth/admin/realms/master/users/u1/reset-password")
+
+		assert.Equal(t, "newclientsecret", getSecretValue(t, client, clientSecretName, "client_secret"))
+		sessionSecret := getSecretValue(t, client, sessionSecretName, "session_secret")
+		assert.Len(t, sessionSecret, 64)
+		assert.Equal(t, "oldpassword", getSecretValue(t, client, keycloakUserSecretName, keycloakAdminPasswordKey))
+
+		gatekeeper, _ := client.AppsV1().Deployments(MockCodewind.Namespace).Get(GatekeeperPrefix+"-"+MockCodewind.WorkspaceID, metav1.GetOptions{})
+		assert.NotEmpty(t, gatekeeper.Spec.Template.Annotations[RestartedAtAnnotation])
+	})
+
+	t.Run("success case - rotates the admin password and restarts keycloak before the gatekeeper", func(t *testing.T) {
+		requests := map[string]string{}
+		server := newMockRotationKeycloak(requests)
+		defer server.Close()
+		client := generateMockRotationWorkspace(server.URL, true, "")
+
+		adminOptions := rotateOptions
+		adminOptions.AdminPassword = true
+		result, remInstErr := RotateSecrets(&adminOptions, client, http.DefaultClient)
+		assert.Nil(t, remInstErr)
+		assert.Equal(t, []string{keycloakAdminPasswordKey, "client_secret", "session_secret"}, result.Rotated)
+		assert.Equal(t, []string{KeycloakPrefix + "-" + MockCodewind.WorkspaceID, GatekeeperPrefix + "-" + MockCodewind.WorkspaceID}, result.Restarted)
+		assert.Equal(t, "admin", result.KeycloakUser)
+		assert.Contains(t, requests["PUT /auth/admin/realms/master/users/u1/reset-password"], result.KeycloakPassword)
+		assert.Equal(t, result.KeycloakPassword, getSecretValue(t, client, keycloakUserSecretName, keycloakAdminPasswordKey))
+	})
+
+	t.Run("fail case - keycloak outside the workspace needs admin credentials", func(t *testing.T) {
+		client := generateMockRotationWorkspace("https://keycloak.example.com", false, "")
+		_, remInstErr := RotateSecrets(&rotateOptions, client, http.DefaultClient)
+		assert.Equal(t, errOpRotate, remInstErr.Op)
+		assert.Equal(t, errNoKeycloakAdmin, remInstErr.Desc)
+	})
+
+	t.Run("fail case - admin password of keycloak outside the workspace", func(t *testing.T) {
+		client := generateMockRotationWorkspace("https://keycloak.example.com", false, "")
+		externalOptions := rotateOptions
+		externalOptions.AdminPassword = true
+		externalOptions.KeycloakUser = "admin"
+		externalOptions.KeycloakPassword = "password"
+		_, remInstErr := RotateSecrets(&externalOptions, client, http.DefaultClient)
+		assert.Equal(t, errKeycloakNotInWorkspace, remInstErr.Desc)
+	})
+
+	t.Run("success case - reads the admin credentials from an existing secret", func(t *testing.T) {
+		requests := map[string]string{}
+		server := newMockRotationKeycloak(requests)
+		defer server.Close()
+		client := generateMockRotationWorkspace(server.URL, true, "keycloak-admin")
+
+		_, remInstErr := RotateSecrets(&rotateOptions, client, http.DefaultClient)
+		assert.Nil(t, remInstErr)
+		assert.Contains(t, requests["POST /auth/realms/master/protocol/openid-connect/token"], "password=oldpassword")
+	})
+
+	t.Run("fail case - admin password in an existing secret", func(t *testing.T) {
+		client := generateMockRotationWorkspace("https://keycloak.example.com", true, "keycloak-admin")
+		adminOptions := rotateOptions
+		adminOptions.AdminPassword = true
+		_, remInstErr := RotateSecrets(&adminOptions, client, http.DefaultClient)
+		assert.Equal(t, errExistingAdminSecret+": keycloak-admin", remInstErr.Desc)
+	})
+
+	t.Run("fail case - workspace not found", func(t *testing.T) {
+		_, remInstErr := RotateSecrets(&rotateOptions, fake.NewSimpleClientset(), http.DefaultClient)
+		assert.Equal(t, errOpNotFound, remInstErr.Op)
+	})
+}
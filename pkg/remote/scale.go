@@ -0,0 +1,107 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ScaleOptions : Scale options, an empty Component scales every component in the workspace. Installed scales each
+// component back to the replicas it was installed with instead of to Replicas.
+type ScaleOptions struct {
+	Namespace   string
+	WorkspaceID string
+	Component   string
+	Replicas    int32
+	Installed   bool
+}
+
+// scaleComponents are the components scaled when no single component is requested
+var scaleComponents = []string{"pfe", "performance", "gatekeeper", "keycloak"}
+
+// ScaleRemote sets the number of replicas of one, or all, of the components of a remote Codewind.
+// Scaling to zero pauses the deployment without removing any of its resources.
+func ScaleRemote(scaleOptions *ScaleOptions, clientset kubernetes.Interface) *RemInstError {
+	if scaleOptions.Replicas < 0 {
+		err := errors.New(errBadReplicas)
+		return &RemInstError{errOpScale, err, err.Error()}
+	}
+
+	client, remInstErr := newK8sAPI(clientset)
+	if remInstErr != nil {
+		return remInstErr
+	}
+
+	components := scaleComponents
+	if scaleOptions.Component != "" {
+		components = []string{scaleOptions.Component}
+	}
+
+	scaled := false
+	for _, component := range components {
+		prefix, remInstErr := GetComponentPrefix(component)
+		if remInstErr != nil {
+			return remInstErr
+		}
+		labelSelector := "app=" + prefix + ",codewindWorkspace=" + scaleOptions.WorkspaceID
+		remInstErr = client.updateDeployments(scaleOptions.Namespace, labelSelector, func(deployment *appsv1.Deployment) {
+			replicas := scaleOptions.Replicas
+			if scaleOptions.Installed {
+				replicas = installedReplicas(deployment)
+			}
+			deployment.Spec.Replicas = &replicas
+			logr.Infof("Scaling deployment '%v' to %v replicas\n", deployment.GetName(), replicas)
+		})
+		if remInstErr == nil {
+			scaled = true
+			continue
+		}
+		// Keycloak may be external to the workspace, so only fail on components which were asked for by name
+		if remInstErr.Op != errOpNotFound || scaleOptions.Component != "" {
+			return remInstErr
+		}
+	}
+
+	if !scaled {
+		err := errors.New(errTargetNotFound)
+		return &RemInstError{errOpNotFound, err, err.Error() + ": codewindWorkspace=" + scaleOptions.WorkspaceID}
+	}
+	return nil
+}
+
+// updateDeployments changes each of the deployments matching the label selector, failing if there are none
+func (client K8sAPI) updateDeployments(namespace string, labelSelector string, update func(*appsv1.Deployment)) *RemInstError {
+	deployments, err := client.clientset.AppsV1().Deployments(namespace).List(v1.ListOptions{
+		LabelSelector: labelSelector,
+	})
+	if err != nil {
+		return &RemInstError{errOpNotFound, err, err.Error()}
+	}
+	if len(deployments.Items) == 0 {
+		err = errors.New(errTargetNotFound)
+		return &RemInstError{errOpNotFound, err, err.Error() + ": " + labelSelector}
+	}
+	for i := range deployments.Items {
+		deployment := &deployments.Items[i]
+		update(deployment)
+		if _, err := client.clientset.AppsV1().Deployments(namespace).Update(deployment); err != nil {
+			logr.Errorf("Unable to update deployment '%v': %v\n", deployment.GetName(), err)
+			return &RemInstError{errOpScale, err, err.Error()}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleRemote(t *testing.T) {
+	pfeDeployment := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": "WID1"},
+	})
+	gatekeeperDeployment := generateMockDeployment(MockDeploymentOptions{
+		Namespace: "test1",
+		Labels:    map[string]string{"app": GatekeeperPrefix, "codewindWorkspace": "WID1"},
+	})
+	newClientset := func() *fake.Clientset {
+		return fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{pfeDeployment, gatekeeperDeployment}})
+	}
+	getReplicas := func(clientset *fake.Clientset, deployment v1.Deployment) *int32 {
+		got, _ := clientset.AppsV1().Deployments("test1").Get(deployment.GetName(), metav1.GetOptions{})
+		return got.Spec.Replicas
+	}
+
+	t.Run("success case - scales a single component", func(t *testing.T) {
+		clientset := newClientset()
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID1", Component: "pfe", Replicas: 3}, clientset)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(3), *getReplicas(clientset, pfeDeployment))
+		// the mock deployments have no replicas set until they are scaled
+		assert.Nil(t, getReplicas(clientset, gatekeeperDeployment))
+	})
+
+	t.Run("success case - scales every component found in the workspace", func(t *testing.T) {
+		clientset := newClientset()
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID1", Replicas: 0}, clientset)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(0), *getReplicas(clientset, pfeDeployment))
+		assert.Equal(t, int32(0), *getReplicas(clientset, gatekeeperDeployment))
+	})
+
+	t.Run("success case - scales every component back to its installed replicas", func(t *testing.T) {
+		installedGatekeeper := gatekeeperDeployment.DeepCopy()
+		installedGatekeeper.SetAnnotations(map[string]string{InstalledReplicasAnnotation: "3"})
+		clientset := fake.NewSimpleClientset(&v1.DeploymentList{Items: []v1.Deployment{pfeDeployment, *installedGatekeeper}})
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID1", Installed: true}, clientset)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(1), *getReplicas(clientset, pfeDeployment))
+		assert.Equal(t, int32(3), *getReplicas(clientset, gatekeeperDeployment))
+	})
+
+	t.Run("error case - requested component not found", func(t *testing.T) {
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID1", Component: "keycloak", Replicas: 1}, newClientset())
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+
+	t.Run("error case - workspace not found", func(t *testing.T) {
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID2", Replicas: 1}, newClientset())
+		assert.Equal(t, errOpNotFound, err.Op)
+	})
+
+	t.Run("error case - negative replicas", func(t *testing.T) {
+		err := ScaleRemote(&ScaleOptions{Namespace: "test1", WorkspaceID: "WID1", Replicas: -1}, newClientset())
+		assert.Equal(t, errOpScale, err.Op)
+	})
+}
@@ -0,0 +1,88 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"io/ioutil"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// ParseNodeSelector converts a list of key=value strings into a node selector, returning nil when none are given
+func ParseNodeSelector(keyValues []string) (map[string]string, error) {
+	if len(keyValues) == 0 {
+		return nil, nil
+	}
+	nodeSelector := map[string]string{}
+	for _, keyValue := range keyValues {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.New(errBadNodeSelector + ": " + keyValue)
+		}
+		nodeSelector[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+	return nodeSelector, nil
+}
+
+// ParseTolerations converts a list of taints in kubectl form, for example "dedicated=devtools:NoSchedule", into
+// tolerations. A taint without a value tolerates any value of its key.
+func ParseTolerations(taints []string) ([]corev1.Toleration, error) {
+	tolerations := []corev1.Toleration{}
+	for _, taint := range taints {
+		keyValueEffect := strings.SplitN(taint, ":", 2)
+		if len(keyValueEffect) != 2 {
+			return nil, errors.New(errBadToleration + ": " + taint)
+		}
+
+		effect := corev1.TaintEffect(keyValueEffect[1])
+		if effect != corev1.TaintEffectNoSchedule && effect != corev1.TaintEffectPreferNoSchedule && effect != corev1.TaintEffectNoExecute {
+			return nil, errors.New(errBadToleration + ": " + taint)
+		}
+
+		toleration := corev1.Toleration{Operator: corev1.TolerationOpExists, Effect: effect}
+		keyValue := strings.SplitN(keyValueEffect[0], "=", 2)
+		toleration.Key = keyValue[0]
+		if toleration.Key == "" {
+			return nil, errors.New(errBadToleration + ": " + taint)
+		}
+		if len(keyValue) == 2 {
+			toleration.Operator = corev1.TolerationOpEqual
+			toleration.Value = keyValue[1]
+		}
+		tolerations = append(tolerations, toleration)
+	}
+	if len(tolerations) == 0 {
+		return nil, nil
+	}
+	return tolerations, nil
+}
+
+// LoadAffinity reads a pod affinity specification, in the YAML or JSON form used in a pod spec's affinity field,
+// from the given file. An empty filename returns no affinity.
+func LoadAffinity(filename string) (*corev1.Affinity, error) {
+	if filename == "" {
+		return nil, nil
+	}
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return nil, errors.New(errBadAffinity + ": " + err.Error())
+	}
+	affinity := corev1.Affinity{}
+	err = yaml.UnmarshalStrict(contents, &affinity)
+	if err != nil {
+		return nil, errors.New(errBadAffinity + ": " + err.Error())
+	}
+	return &affinity, nil
+}
@@ -0,0 +1,97 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseNodeSelector(t *testing.T) {
+	t.Run("success case - parses key=value pairs", func(t *testing.T) {
+		nodeSelector, err := ParseNodeSelector([]string{"pool=devtools", "kubernetes.io/arch=amd64"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{"pool": "devtools", "kubernetes.io/arch": "amd64"}, nodeSelector)
+	})
+
+	t.Run("success case - no selectors returns nil", func(t *testing.T) {
+		nodeSelector, err := ParseNodeSelector(nil)
+		assert.Nil(t, err)
+		assert.Nil(t, nodeSelector)
+	})
+
+	t.Run("error case - missing value", func(t *testing.T) {
+		_, err := ParseNodeSelector([]string{"pool"})
+		assert.Contains(t, err.Error(), errBadNodeSelector)
+	})
+}
+
+func TestParseTolerations(t *testing.T) {
+	t.Run("success case - parses taints with and without values", func(t *testing.T) {
+		tolerations, err := ParseTolerations([]string{"dedicated=devtools:NoSchedule", "gpu:NoExecute"})
+		assert.Nil(t, err)
+		assert.Equal(t, []corev1.Toleration{
+			{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "devtools", Effect: corev1.TaintEffectNoSchedule},
+			{Key: "gpu", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+		}, tolerations)
+	})
+
+	t.Run("error case - unknown effect", func(t *testing.T) {
+		_, err := ParseTolerations([]string{"dedicated=devtools:Never"})
+		assert.Contains(t, err.Error(), errBadToleration)
+	})
+
+	t.Run("error case - missing effect", func(t *testing.T) {
+		_, err := ParseTolerations([]string{"dedicated=devtools"})
+		assert.Contains(t, err.Error(), errBadToleration)
+	})
+}
+
+func TestLoadAffinity(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "affinity")
+	defer os.RemoveAll(dir)
+
+	t.Run("success case - reads a YAML affinity", func(t *testing.T) {
+		filename := filepath.Join(dir, "affinity.yaml")
+		ioutil.WriteFile(filename, []byte(`nodeAffinity:
+  requiredDuringSchedulingIgnoredDuringExecution:
+    nodeSelectorTerms:
+    - matchExpressions:
+      - key: pool
+        operator: In
+        values: ["devtools"]
+`), 0644)
+		affinity, err := LoadAffinity(filename)
+		assert.Nil(t, err)
+		terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+		assert.Equal(t, "pool", terms[0].MatchExpressions[0].Key)
+		assert.Equal(t, []string{"devtools"}, terms[0].MatchExpressions[0].Values)
+	})
+
+	t.Run("error case - unknown field", func(t *testing.T) {
+		filename := filepath.Join(dir, "bad.yaml")
+		ioutil.WriteFile(filename, []byte("nodeAffinityy: {}\n"), 0644)
+		_, err := LoadAffinity(filename)
+		assert.Contains(t, err.Error(), errBadAffinity)
+	})
+
+	t.Run("success case - no file returns nil", func(t *testing.T) {
+		affinity, err := LoadAffinity("")
+		assert.Nil(t, err)
+		assert.Nil(t, affinity)
+	})
+}
@@ -0,0 +1,78 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+
+	logr "github.com/sirupsen/logrus"
+	storagev1 "k8s.io/api/storage/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Annotations which mark a storage class as the one used by PVCs that do not request a class
+var defaultStorageClassAnnotations = []string{
+	"storageclass.kubernetes.io/is-default-class",
+	"storageclass.beta.kubernetes.io/is-default-class",
+}
+
+// checkStorageClasses verifies that PVCs using each of the given storage classes can be provisioned, where an empty
+// class means the cluster default. Without this check, installs wait forever on Pending PVCs. When storage classes
+// cannot be listed, for example because of RBAC, the check is skipped.
+func checkStorageClasses(clientset kubernetes.Interface, storageClasses ...string) *RemInstError {
+	classList, err := clientset.StorageV1().StorageClasses().List(v1.ListOptions{})
+	if err != nil {
+		logr.Warnf("Unable to check storage classes: %v\n", err)
+		return nil
+	}
+
+	for _, storageClass := range storageClasses {
+		if storageClass == "" {
+			if findDefaultStorageClass(classList.Items) == "" {
+				err := errors.New(errNoDefaultStorage)
+				return &RemInstError{errOpStorageClass, err, err.Error()}
+			}
+			continue
+		}
+		if !hasStorageClass(classList.Items, storageClass) {
+			err := errors.New(errNoStorageClass + ": " + storageClass)
+			return &RemInstError{errOpStorageClass, err, err.Error()}
+		}
+	}
+	return nil
+}
+
+// findDefaultStorageClass returns the name of the default storage class, or the ROKS class that PFE uses
+// automatically, or an empty string if there is neither
+func findDefaultStorageClass(storageClasses []storagev1.StorageClass) string {
+	for _, storageClass := range storageClasses {
+		for _, annotation := range defaultStorageClassAnnotations {
+			if storageClass.GetAnnotations()[annotation] == "true" {
+				return storageClass.GetName()
+			}
+		}
+	}
+	if hasStorageClass(storageClasses, ROKSStorageClass) {
+		return ROKSStorageClass
+	}
+	return ""
+}
+
+func hasStorageClass(storageClasses []storagev1.StorageClass, name string) bool {
+	for _, storageClass := range storageClasses {
+		if storageClass.GetName() == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func generateMockStorageClass(name string, isDefault bool) *storagev1.StorageClass {
+	storageClass := storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	if isDefault {
+		storageClass.SetAnnotations(map[string]string{"storageclass.kubernetes.io/is-default-class": "true"})
+	}
+	return &storageClass
+}
+
+func TestCheckStorageClasses(t *testing.T) {
+	t.Run("success case - requested and default classes exist", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(generateMockStorageClass("standard", true), generateMockStorageClass("nfs", false))
+		assert.Nil(t, checkStorageClasses(clientset, "", "nfs"))
+	})
+
+	t.Run("error case - requested class does not exist", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(generateMockStorageClass("standard", true))
+		remInstErr := checkStorageClasses(clientset, "nfs")
+		assert.Equal(t, errOpStorageClass, remInstErr.Op)
+		assert.Equal(t, errNoStorageClass+": nfs", remInstErr.Desc)
+	})
+
+	t.Run("error case - no default class", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(generateMockStorageClass("nfs", false))
+		remInstErr := checkStorageClasses(clientset, "nfs", "")
+		assert.Equal(t, errNoDefaultStorage, remInstErr.Desc)
+	})
+
+	t.Run("success case - ROKS class is used when there is no default", func(t *testing.T) {
+		clientset := fake.NewSimpleClientset(generateMockStorageClass(ROKSStorageClass, false))
+		assert.Nil(t, checkStorageClasses(clientset, ""))
+	})
+}
+
+func pvcStorage(pvc corev1.PersistentVolumeClaim) string {
+	storage := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return storage.String()
+}
+
+func TestGenerateKeycloakPVC(t *testing.T) {
+	t.Run("success case - defaults to 1Gi with the workspace storage class", func(t *testing.T) {
+		deployOptions := &DeployOptions{StorageClass: "nfs"}
+		pvc := generateKeycloakPVC(MockCodewind, deployOptions, deployOptions.GetKeycloakStorageClass())
+		assert.Equal(t, "1Gi", pvcStorage(pvc))
+		assert.Equal(t, "nfs", *pvc.Spec.StorageClassName)
+	})
+
+	t.Run("success case - Keycloak size and storage class override the defaults", func(t *testing.T) {
+		deployOptions := &DeployOptions{StorageClass: "nfs", KeycloakStorageClass: "block", KeycloakPVCSize: "5Gi"}
+		pvc := generateKeycloakPVC(MockCodewind, deployOptions, deployOptions.GetKeycloakStorageClass())
+		assert.Equal(t, "5Gi", pvcStorage(pvc))
+		assert.Equal(t, "block", *pvc.Spec.StorageClassName)
+	})
+}
@@ -31,4 +31,6 @@ var MockCodewind = Codewind{
 	Ingress:            "ingress",
 	RequestedIngress:   "test-requested-ingress",
 	OnOpenShift:        false,
+	GatekeeperHost:     "codewind-gatekeeperingress",
+	KeycloakHost:       "codewind-keycloakingress",
 }
@@ -11,7 +11,10 @@
 
 package remote
 
-import "k8s.io/apimachinery/pkg/types"
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
 
 // Codewind represents a Codewind instance: name, namespace, volume, serviceaccount, and pull secrets
 type Codewind struct {
@@ -34,6 +37,43 @@ type Codewind struct {
 	Ingress            string
 	RequestedIngress   string // resolved where possible or set by cli flag
 	OnOpenShift        bool
+	GatekeeperHost     string            // derived from the ingress domain unless set by cli flag
+	KeycloakHost       string            // derived from the ingress domain unless set by cli flag
+	WildcardDomain     string            // added to the certificates of hosts under it as *.<domain>
+	IngressClass       string            // defaults to nginx
+	IngressAnnotations map[string]string // added to, or overriding, the default ingress annotations
+	Metrics            bool              // annotate services for Prometheus scraping
+
+	// Container resource requests and limits, empty to use the cluster defaults
+	PFEResources         corev1.ResourceRequirements
+	PerformanceResources corev1.ResourceRequirements
+	GatekeeperResources  corev1.ResourceRequirements
+	KeycloakResources    corev1.ResourceRequirements
+
+	// Scheduling constraints applied to every Codewind deployment
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+	Affinity     *corev1.Affinity
+
+	// Replicas of the Performance dashboard and Gatekeeper, zero for a single replica. With more than one, the
+	// replicas are spread across nodes and protected by pod disruption budgets.
+	PerformanceReplicas int32
+	GatekeeperReplicas  int32
+
+	// Pod security profile, empty or restricted, and the security settings of each component overriding it
+	PodSecurityProfile  string
+	PFESecurity         PodSecurity
+	PerformanceSecurity PodSecurity
+	GatekeeperSecurity  PodSecurity
+	KeycloakSecurity    PodSecurity
+
+	// Ownership labels set on every resource the install creates, identifying the install
+	OwnerLabels map[string]string
+
+	// Existing secrets used instead of the ones the install generates, empty to generate them
+	KeycloakAdminSecret string
+	GatekeeperTLSSecret string
+	KeycloakTLSSecret   string
 }
 
 // ServiceAccountPatch contains an array of imagePullSecrets that will be patched into a Kubernetes service account
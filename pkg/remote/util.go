@@ -13,17 +13,21 @@ package remote
 
 import (
 	"bytes"
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
+	"errors"
 	"math/big"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/eclipse/codewind-installer/pkg/remote/kube"
 	logr "github.com/sirupsen/logrus"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -32,6 +36,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 // GetImages returns the images that are to be used for PFE and the Performance dashboard in Codewind
@@ -73,6 +78,16 @@ func GetKubeConfig() (*rest.Config, error) {
 	var config *rest.Config
 	var err error
 
+	// Use the pod service account when running inside the cluster
+	if kube.UseInClusterConfig() {
+		config, err = rest.InClusterConfig()
+		if err != nil {
+			logr.Infof("Unable to retrieve in-cluster Kubernetes Config %v\n", err)
+			return nil, &RemInstError{errOpNotFound, err, err.Error()}
+		}
+		return config, nil
+	}
+
 	// Use KUBECONFIG environment variable if set
 	kubeconfig, ok := os.LookupEnv("KUBECONFIG")
 	if ok && kubeconfig != "" {
@@ -116,7 +131,7 @@ func getHomeDir() string {
 
 // generateDeployment returns a Kubernetes deployment object with the given name for the given image.
 // Additionally, volume/volumemounts and env vars can be specified.
-func generateDeployment(codewind Codewind, name string, image string, port int, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount, envVars []corev1.EnvVar, labels map[string]string, serviceAccountName string, privileged bool) appsv1.Deployment {
+func generateDeployment(codewind Codewind, name string, image string, port int, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount, envVars []corev1.EnvVar, labels map[string]string, serviceAccountName string, privileged bool, resources corev1.ResourceRequirements) appsv1.Deployment {
 
 	//blockOwnerDeletion := true
 	//controller := true
@@ -129,7 +144,7 @@ func generateDeployment(codewind Codewind, name string, image string, port int,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name + "-" + codewind.WorkspaceID,
 			Namespace: codewind.Namespace,
-			Labels:    labels,
+			Labels:    ownedLabels(codewind, labels),
 			// OwnerReferences: []metav1.OwnerReference{
 			// 	{
 			// 		APIVersion:         "apps/v1",
@@ -153,6 +168,9 @@ func generateDeployment(codewind Codewind, name string, image string, port int,
 				Spec: corev1.PodSpec{
 					ServiceAccountName: serviceAccountName,
 					Volumes:            volumes,
+					NodeSelector:       codewind.NodeSelector,
+					Tolerations:        codewind.Tolerations,
+					Affinity:           codewind.Affinity,
 					Containers: []corev1.Container{
 						{
 							Name:            name,
@@ -163,6 +181,7 @@ func generateDeployment(codewind Codewind, name string, image string, port int,
 							},
 							VolumeMounts: volumeMounts,
 							Env:          envVars,
+							Resources:    resources,
 							Ports: []corev1.ContainerPort{
 								{
 									ContainerPort: int32(port),
@@ -186,13 +205,42 @@ func generateSecrets(codewind Codewind, name string, secrets map[string]string,
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name + "-" + codewind.WorkspaceID,
 			Namespace: codewind.Namespace,
-			Labels:    labels,
+			Labels:    ownedLabels(codewind, labels),
 		},
 		StringData: secrets,
 	}
 	return secret
 }
 
+// ParseIngressAnnotations converts a list of key=value strings into ingress annotations
+func ParseIngressAnnotations(keyValues []string) (map[string]string, error) {
+	annotations := map[string]string{}
+	for _, keyValue := range keyValues {
+		parts := strings.SplitN(keyValue, "=", 2)
+		if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+			return nil, errors.New(errBadAnnotation + ": " + keyValue)
+		}
+		annotations[strings.TrimSpace(parts[0])] = parts[1]
+	}
+	return annotations, nil
+}
+
+// generateIngressAnnotations returns the default annotations of an ingress, with the ingress class and any custom
+// annotations requested at install time applied on top
+func generateIngressAnnotations(codewind Codewind, defaults map[string]string) map[string]string {
+	annotations := map[string]string{}
+	for key, value := range defaults {
+		annotations[key] = value
+	}
+	if codewind.IngressClass != "" {
+		annotations["kubernetes.io/ingress.class"] = codewind.IngressClass
+	}
+	for key, value := range codewind.IngressAnnotations {
+		annotations[key] = value
+	}
+	return annotations
+}
+
 // generateService returns a Kubernetes service object with the given name, exposed over the specified port
 // for the container with the given labels.
 func generateService(codewind Codewind, name string, port int, labels map[string]string) corev1.Service {
@@ -206,7 +254,7 @@ func generateService(codewind Codewind, name string, port int, labels map[string
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      name + "-" + codewind.WorkspaceID,
 			Namespace: codewind.Namespace,
-			Labels:    labels,
+			Labels:    ownedLabels(codewind, labels),
 			// OwnerReferences: []metav1.OwnerReference{
 			// 	{
 			// 		APIVersion:         "apps/v1",
@@ -231,7 +279,8 @@ func generateService(codewind Codewind, name string, port int, labels map[string
 	return service
 }
 
-func generateCertificate(dnsName string, certTitle string) (string, string, error) {
+func generateCertificate(dnsNames []string, certTitle string) (string, string, error) {
+	dnsName := dnsNames[0]
 	template := x509.Certificate{
 		SerialNumber: big.NewInt(time.Now().UnixNano() / 1000000),
 		Subject: pkix.Name{
@@ -242,7 +291,7 @@ func generateCertificate(dnsName string, certTitle string) (string, string, erro
 		KeyUsage:              x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
 		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
 		BasicConstraintsValid: true,
-		DNSNames:              []string{dnsName},
+		DNSNames:              dnsNames,
 	}
 
 	logr.Println("Creating " + dnsName + " server Key")
@@ -289,7 +338,12 @@ func pemBlockForKey(privateKey interface{}) *pem.Block {
 }
 
 // WaitForPodReady : Wait for pod to enter the running phase
-func WaitForPodReady(clientset *kubernetes.Clientset, codewindInstance Codewind, labelSelector string, podName string) bool {
+func WaitForPodReady(clientset kubernetes.Interface, codewindInstance Codewind, labelSelector string, podName string) bool {
+	return waitForPodReady(context.Background(), clientset, codewindInstance, labelSelector, podName)
+}
+
+// waitForPodReady waits for pod to enter the running phase, returning false when the watch ends or the context is done
+func waitForPodReady(ctx context.Context, clientset kubernetes.Interface, codewindInstance Codewind, labelSelector string, podName string) bool {
 
 	logr.Infof("Waiting for pod: %v", podName)
 	var waitTime int64 = 30
@@ -306,7 +360,14 @@ func WaitForPodReady(clientset *kubernetes.Clientset, codewindInstance Codewind,
 	lastReason := ""
 	changeEvent := watcher.ResultChan()
 	for {
-		event, channelOk := <-changeEvent
+		var event watch.Event
+		var channelOk bool
+		select {
+		case <-ctx.Done():
+			watcher.Stop()
+			return false
+		case event, channelOk = <-changeEvent:
+		}
 		if !channelOk {
 			// Channel closed, no event value. (Watch probably timed out.)
 			logr.Warnf("Watch for Pod %v ended. Timeout or connection error.", podName)
@@ -98,6 +98,7 @@ type testParamaterOptions = struct {
 	envVars            []corev1.EnvVar
 	serviceAccountName string
 	privileged         bool
+	resources          corev1.ResourceRequirements
 }
 
 var defaultParams = testParamaterOptions{
@@ -111,12 +112,13 @@ var defaultParams = testParamaterOptions{
 	envVars:            []corev1.EnvVar{},
 	serviceAccountName: "sac-name",
 	privileged:         false,
+	resources:          corev1.ResourceRequirements{},
 }
 
 func TestGenerateDeployment(t *testing.T) {
 	t.Run("success case - returns correct deployment", func(t *testing.T) {
 		replicas := int32(1)
-		deployment := generateDeployment(MockCodewind, defaultParams.name, defaultParams.image, defaultParams.port, defaultParams.volumes, defaultParams.volumeMounts, defaultParams.envVars, defaultParams.labels, defaultParams.serviceAccountName, defaultParams.privileged)
+		deployment := generateDeployment(MockCodewind, defaultParams.name, defaultParams.image, defaultParams.port, defaultParams.volumes, defaultParams.volumeMounts, defaultParams.envVars, defaultParams.labels, defaultParams.serviceAccountName, defaultParams.privileged, defaultParams.resources)
 		expectedDeployment := appsv1.Deployment{
 			TypeMeta: metav1.TypeMeta{
 				Kind:       "Deployment",
@@ -149,6 +151,7 @@ func TestGenerateDeployment(t *testing.T) {
 								},
 								VolumeMounts: defaultParams.volumeMounts,
 								Env:          defaultParams.envVars,
+								Resources:    defaultParams.resources,
 								Ports: []corev1.ContainerPort{
 									{
 										ContainerPort: int32(defaultParams.port),
@@ -209,3 +212,48 @@ func TestGenerateService(t *testing.T) {
 		assert.Equal(t, expectedService, service)
 	})
 }
+
+func TestParseIngressAnnotations(t *testing.T) {
+	t.Run("success case - key value pairs are parsed", func(t *testing.T) {
+		annotations, err := ParseIngressAnnotations([]string{"traefik.ingress.kubernetes.io/router.tls=true", "alb.ingress.kubernetes.io/scheme=internet-facing"})
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]string{
+			"traefik.ingress.kubernetes.io/router.tls": "true",
+			"alb.ingress.kubernetes.io/scheme":         "internet-facing",
+		}, annotations)
+	})
+
+	t.Run("fail case - annotation without a value", func(t *testing.T) {
+		_, err := ParseIngressAnnotations([]string{"traefik.ingress.kubernetes.io/router.tls"})
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateIngressAnnotations(t *testing.T) {
+	defaults := map[string]string{
+		"kubernetes.io/ingress.class":                  "nginx",
+		"nginx.ingress.kubernetes.io/backend-protocol": "HTTPS",
+	}
+
+	t.Run("success case - defaults are used when nothing is customized", func(t *testing.T) {
+		annotations := generateIngressAnnotations(MockCodewind, defaults)
+		assert.Equal(t, defaults, annotations)
+	})
+
+	t.Run("success case - class and custom annotations override the defaults", func(t *testing.T) {
+		codewind := MockCodewind
+		codewind.IngressClass = "traefik"
+		codewind.IngressAnnotations = map[string]string{
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"traefik.ingress.kubernetes.io/router.tls":     "true",
+		}
+		annotations := generateIngressAnnotations(codewind, defaults)
+		assert.Equal(t, map[string]string{
+			"kubernetes.io/ingress.class":                  "traefik",
+			"nginx.ingress.kubernetes.io/backend-protocol": "HTTP",
+			"traefik.ingress.kubernetes.io/router.tls":     "true",
+		}, annotations)
+		// the defaults must not be modified
+		assert.Equal(t, "nginx", defaults["kubernetes.io/ingress.class"])
+	})
+}
@@ -0,0 +1,76 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"errors"
+	"sort"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// resolveImages returns the PFE, performance, Keycloak and Gatekeeper images to deploy. The image tag of the options
+// pins the default and environment variable images to one version, while images set in the options are used as they
+// are.
+func resolveImages(deployOptions *DeployOptions) (string, string, string, string) {
+	pfeImage, performanceImage, keycloakImage, gatekeeperImage := GetImages()
+	if deployOptions.ImageTag != "" {
+		pfeImage = utils.WithImageTag(pfeImage, deployOptions.ImageTag)
+		performanceImage = utils.WithImageTag(performanceImage, deployOptions.ImageTag)
+		keycloakImage = utils.WithImageTag(keycloakImage, deployOptions.ImageTag)
+		gatekeeperImage = utils.WithImageTag(gatekeeperImage, deployOptions.ImageTag)
+	}
+	if deployOptions.PFEImage != "" {
+		pfeImage = deployOptions.PFEImage
+	}
+	if deployOptions.PerformanceImage != "" {
+		performanceImage = deployOptions.PerformanceImage
+	}
+	if deployOptions.KeycloakImage != "" {
+		keycloakImage = deployOptions.KeycloakImage
+	}
+	if deployOptions.GatekeeperImage != "" {
+		gatekeeperImage = deployOptions.GatekeeperImage
+	}
+	return pfeImage, performanceImage, keycloakImage, gatekeeperImage
+}
+
+// checkImageVersions returns an error if the components being deployed are not all the same version, as Codewind
+// only supports components from the same release working together
+func checkImageVersions(deployOptions *DeployOptions, pfeImage, performanceImage, keycloakImage, gatekeeperImage string) *RemInstError {
+	if deployOptions.AllowMixedVersions {
+		return nil
+	}
+	images := []string{}
+	if !deployOptions.KeycloakOnly {
+		images = append(images, pfeImage, performanceImage, gatekeeperImage)
+	}
+	if deployOptions.KeycloakURL == "" {
+		images = append(images, keycloakImage)
+	}
+
+	tags := map[string]bool{}
+	for _, image := range images {
+		tags[utils.ImageTag(image)] = true
+	}
+	if len(tags) < 2 {
+		return nil
+	}
+	found := []string{}
+	for tag := range tags {
+		found = append(found, tag)
+	}
+	sort.Strings(found)
+	err := errors.New(errMixedVersions + ": " + strings.Join(found, ", "))
+	return &RemInstError{errOpMixedVersions, err, err.Error()}
+}
@@ -0,0 +1,67 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveImages(t *testing.T) {
+	resetEnvVars := setTestEnvVars(t, map[string]string{"PFE_TAG": "0.8.0"})
+	defer resetEnvVars()
+
+	t.Run("the image tag pins the default and environment variable images", func(t *testing.T) {
+		pfe, performance, keycloak, gatekeeper := resolveImages(&DeployOptions{ImageTag: "0.9.0"})
+		assert.Equal(t, PFEImage+":0.9.0", pfe)
+		assert.Equal(t, PerformanceImage+":0.9.0", performance)
+		assert.Equal(t, KeycloakImage+":0.9.0", keycloak)
+		assert.Equal(t, GatekeeperImage+":0.9.0", gatekeeper)
+	})
+
+	t.Run("images set in the options are used as they are", func(t *testing.T) {
+		pfe, _, _, _ := resolveImages(&DeployOptions{ImageTag: "0.9.0", PFEImage: "registry.example.com/pfe:dev"})
+		assert.Equal(t, "registry.example.com/pfe:dev", pfe)
+	})
+
+	t.Run("without an image tag the environment variables apply", func(t *testing.T) {
+		pfe, performance, _, _ := resolveImages(&DeployOptions{})
+		assert.Equal(t, PFEImage+":0.8.0", pfe)
+		assert.Equal(t, PerformanceImage+":"+PerformanceTag, performance)
+	})
+}
+
+func TestCheckImageVersions(t *testing.T) {
+	t.Run("success case - one version", func(t *testing.T) {
+		err := checkImageVersions(&DeployOptions{}, "pfe:0.9.0", "performance:0.9.0", "keycloak:0.9.0", "gatekeeper:0.9.0")
+		assert.Nil(t, err)
+	})
+
+	t.Run("error case - mixed versions", func(t *testing.T) {
+		err := checkImageVersions(&DeployOptions{}, "pfe:0.9.0", "performance", "keycloak:0.9.0", "gatekeeper:0.9.0")
+		assert.Equal(t, errOpMixedVersions, err.Op)
+		assert.Contains(t, err.Desc, "0.9.0, latest")
+	})
+
+	t.Run("success case - mixed versions allowed", func(t *testing.T) {
+		err := checkImageVersions(&DeployOptions{AllowMixedVersions: true}, "pfe:dev", "performance:0.9.0", "keycloak:0.9.0", "gatekeeper:0.9.0")
+		assert.Nil(t, err)
+	})
+
+	t.Run("success case - components not deployed are not checked", func(t *testing.T) {
+		err := checkImageVersions(&DeployOptions{KeycloakURL: "https://keycloak.example.com"}, "pfe:0.9.0", "performance:0.9.0", "keycloak:0.8.0", "gatekeeper:0.9.0")
+		assert.Nil(t, err)
+		err = checkImageVersions(&DeployOptions{KeycloakOnly: true}, "pfe:0.8.0", "performance:0.9.0", "keycloak:0.9.0", "gatekeeper:0.9.0")
+		assert.Nil(t, err)
+	})
+}
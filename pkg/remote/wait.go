@@ -0,0 +1,140 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	logr "github.com/sirupsen/logrus"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// DefaultReadyTimeout is how long an install waits for each component to become ready when --wait is set
+const DefaultReadyTimeout = 10 * time.Minute
+
+// deploymentPollInterval is how often the rollout status of a deployment is checked
+var deploymentPollInterval = 2 * time.Second
+
+// waitForComponent blocks until the component deployment is available. With the wait option set this follows the
+// rollout status of the deployment and fails with the pod events once the timeout is exceeded, otherwise it waits
+// indefinitely for the pod to start running. Either wait stops when the context is done.
+func waitForComponent(ctx context.Context, clientset kubernetes.Interface, codewindInstance Codewind, deployOptions *DeployOptions, prefix string) *RemInstError {
+	podSearch := "codewindWorkspace=" + codewindInstance.WorkspaceID + ",app=" + prefix
+	deploymentName := prefix + "-" + codewindInstance.WorkspaceID
+
+	if !deployOptions.Wait {
+		ready := false
+		for !ready {
+			if remInstErr := installCancelled(ctx, codewindInstance.Namespace, codewindInstance.OwnerLabels[InstallIDLabel]); remInstErr != nil {
+				return remInstErr
+			}
+			ready = waitForPodReady(ctx, clientset, codewindInstance, podSearch, deploymentName)
+		}
+		return nil
+	}
+
+	timeout := deployOptions.Timeout
+	if timeout <= 0 {
+		timeout = DefaultReadyTimeout
+	}
+	logr.Infof("Waiting up to %v for deployment '%v' to be ready\n", timeout, deploymentName)
+	err := waitForDeploymentReady(ctx, clientset, codewindInstance.Namespace, deploymentName, timeout)
+	if remInstErr := installCancelled(ctx, codewindInstance.Namespace, codewindInstance.OwnerLabels[InstallIDLabel]); remInstErr != nil {
+		return remInstErr
+	}
+	if err != nil {
+		events := getPodEvents(clientset, codewindInstance.Namespace, podSearch)
+		desc := errReadyTimeout + ": " + deploymentName
+		if len(events) > 0 {
+			desc = desc + "\n" + strings.Join(events, "\n")
+		}
+		return &RemInstError{errOpReadyTimeout, errors.New(errReadyTimeout), desc}
+	}
+	return nil
+}
+
+// WaitForDeploymentReady polls a deployment until its rollout is complete, or returns an error after the timeout
+func WaitForDeploymentReady(clientset kubernetes.Interface, namespace string, name string, timeout time.Duration) error {
+	return waitForDeploymentReady(context.Background(), clientset, namespace, name, timeout)
+}
+
+// waitForDeploymentReady polls a deployment until its rollout is complete, or returns an error after the timeout or
+// once the context is done
+func waitForDeploymentReady(ctx context.Context, clientset kubernetes.Interface, namespace string, name string, timeout time.Duration) error {
+	return pollImmediateContext(ctx, deploymentPollInterval, timeout, func() (bool, error) {
+		deployment, err := clientset.AppsV1().Deployments(namespace).Get(name, metav1.GetOptions{})
+		if err != nil {
+			// The deployment may not be visible yet, keep polling
+			logr.Tracef("Unable to get deployment '%v': %v", name, err)
+			return false, nil
+		}
+		return isDeploymentReady(deployment), nil
+	})
+}
+
+// pollImmediateContext polls a condition like wait.PollImmediate, also giving up as soon as the context is done
+func pollImmediateContext(ctx context.Context, interval time.Duration, timeout time.Duration, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(interval, condition, ctx.Done())
+}
+
+// installCancelled returns an error naming the install ID to remove the resources created by, once the context of an
+// install is done
+func installCancelled(ctx context.Context, namespace string, installID string) *RemInstError {
+	if ctx.Err() == nil {
+		return nil
+	}
+	desc := errInstallCancelled + ", remove the resources it created with: cwctl remove remote --namespace " + namespace + " --install-id " + installID
+	return &RemInstError{errOpCancelled, ctx.Err(), desc}
+}
+
+// isDeploymentReady reports whether every replica of the latest revision of a deployment is updated and available
+func isDeploymentReady(deployment *appsv1.Deployment) bool {
+	if deployment.Status.ObservedGeneration < deployment.GetGeneration() {
+		return false
+	}
+	replicas := int32(1)
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+	return deployment.Status.UpdatedReplicas >= replicas &&
+		deployment.Status.AvailableReplicas >= replicas &&
+		deployment.Status.Replicas == deployment.Status.UpdatedReplicas
+}
+
+// getPodEvents returns the events recorded for the pods matching the label selector, to explain why they are not ready
+func getPodEvents(clientset kubernetes.Interface, namespace string, labelSelector string) []string {
+	events := []string{}
+	pods, err := clientset.CoreV1().Pods(namespace).List(metav1.ListOptions{LabelSelector: labelSelector})
+	if err != nil {
+		return events
+	}
+	for _, pod := range pods.Items {
+		podEvents, err := clientset.CoreV1().Events(namespace).List(metav1.ListOptions{
+			FieldSelector: "involvedObject.name=" + pod.GetName(),
+		})
+		if err != nil {
+			continue
+		}
+		for _, event := range podEvents.Items {
+			events = append(events, pod.GetName()+": "+event.Type+" "+event.Reason+" - "+event.Message)
+		}
+	}
+	return events
+}
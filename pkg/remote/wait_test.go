@@ -0,0 +1,135 @@
+/*******************************************************************************
+* Copyright (c) 2020 IBM Corporation and others.
+* All rights reserved. This program and the accompanying materials
+* are made available under the terms of the Eclipse Public License v2.0
+* which accompanies this distribution, and is available at
+* http://www.eclipse.org/legal/epl-v20.html
+*
+* Contributors:
+*     IBM Corporation - initial API and implementation
+*******************************************************************************/
+
+package remote
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func generateMockRollout(name string, replicas int32, status appsv1.DeploymentStatus) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  MockCodewind.Namespace,
+			Generation: 2,
+		},
+		Spec:   appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: status,
+	}
+}
+
+func TestIsDeploymentReady(t *testing.T) {
+	tests := map[string]struct {
+		status appsv1.DeploymentStatus
+		want   bool
+	}{
+		"rollout complete": {
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			want:   true,
+		},
+		"new generation not observed yet": {
+			status: appsv1.DeploymentStatus{ObservedGeneration: 1, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1},
+			want:   false,
+		},
+		"replica not available": {
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 0},
+			want:   false,
+		},
+		"old replica still running": {
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 2, UpdatedReplicas: 1, AvailableReplicas: 1},
+			want:   false,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, isDeploymentReady(generateMockRollout("test", 1, test.status)))
+		})
+	}
+}
+
+func TestWaitForComponent(t *testing.T) {
+	deploymentPollInterval = 10 * time.Millisecond
+	deployOptions := DeployOptions{Wait: true, Timeout: 50 * time.Millisecond}
+	deploymentName := PFEPrefix + "-" + MockCodewind.WorkspaceID
+
+	t.Run("success case - deployment is ready", func(t *testing.T) {
+		deployment := generateMockRollout(deploymentName, 1, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1, AvailableReplicas: 1})
+		err := waitForComponent(context.Background(), fake.NewSimpleClientset(deployment), MockCodewind, &deployOptions, PFEPrefix)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error case - timeout reports pod events", func(t *testing.T) {
+		deployment := generateMockRollout(deploymentName, 1, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1})
+		pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+			Name:      "pfe-pod",
+			Namespace: MockCodewind.Namespace,
+			Labels:    map[string]string{"app": PFEPrefix, "codewindWorkspace": MockCodewind.WorkspaceID},
+		}}
+		event := &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "pfe-pod.1", Namespace: MockCodewind.Namespace},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "pfe-pod"},
+			Type:           "Warning",
+			Reason:         "FailedScheduling",
+			Message:        "0/1 nodes are available",
+		}
+		err := waitForComponent(context.Background(), fake.NewSimpleClientset(deployment, pod, event), MockCodewind, &deployOptions, PFEPrefix)
+		assert.Equal(t, errOpReadyTimeout, err.Op)
+		assert.Contains(t, err.Desc, deploymentName)
+		assert.Contains(t, err.Desc, "pfe-pod: Warning FailedScheduling - 0/1 nodes are available")
+	})
+
+	t.Run("error case - cancelled install stops waiting", func(t *testing.T) {
+		deployment := generateMockRollout(deploymentName, 1, appsv1.DeploymentStatus{ObservedGeneration: 2, Replicas: 1, UpdatedReplicas: 1})
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		waitOptions := DeployOptions{Wait: true, Timeout: time.Minute}
+		err := waitForComponent(ctx, fake.NewSimpleClientset(deployment), MockCodewind, &waitOptions, PFEPrefix)
+		assert.Equal(t, errOpCancelled, err.Op)
+		assert.Contains(t, err.Desc, "--install-id")
+
+		err = waitForComponent(ctx, fake.NewSimpleClientset(deployment), MockCodewind, &DeployOptions{}, PFEPrefix)
+		assert.Equal(t, errOpCancelled, err.Op)
+	})
+}
+
+func TestWaitForPodRunning(t *testing.T) {
+	deploymentPollInterval = 10 * time.Millisecond
+	newPod := func(phase corev1.PodPhase) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "project-pod", Namespace: MockCodewind.Namespace},
+			Status:     corev1.PodStatus{Phase: phase},
+		}
+	}
+
+	t.Run("success case - pod is running", func(t *testing.T) {
+		err := WaitForPodRunning(fake.NewSimpleClientset(newPod(corev1.PodRunning)), MockCodewind.Namespace, "project-pod", 50*time.Millisecond)
+		assert.Nil(t, err)
+	})
+
+	t.Run("error case - pod is pending", func(t *testing.T) {
+		err := WaitForPodRunning(fake.NewSimpleClientset(newPod(corev1.PodPending)), MockCodewind.Namespace, "project-pod", 50*time.Millisecond)
+		assert.EqualError(t, err, errPodNotRunning)
+	})
+
+	t.Run("error case - pod does not exist", func(t *testing.T) {
+		err := WaitForPodRunning(fake.NewSimpleClientset(), MockCodewind.Namespace, "project-pod", 50*time.Millisecond)
+		assert.EqualError(t, err, errPodNotRunning)
+	})
+}
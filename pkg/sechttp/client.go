@@ -0,0 +1,77 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// ClientOptions : Connection pool settings of the shared HTTP client
+type ClientOptions struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	IdleConnTimeout     time.Duration
+}
+
+// DefaultClientOptions keeps enough idle connections to each host for the parallel file uploads of a project sync
+var DefaultClientOptions = ClientOptions{
+	MaxIdleConns:        100,
+	MaxIdleConnsPerHost: 20,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+var sharedClient = struct {
+	sync.Mutex
+	client  *http.Client
+	options ClientOptions
+}{options: DefaultClientOptions}
+
+// ConfigureClient : Replace the connection pool settings of the shared HTTP client
+func ConfigureClient(options ClientOptions) {
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+	sharedClient.options = options
+	sharedClient.client = nil
+}
+
+// Client : Returns the HTTP client shared by project and API requests, so that connections and TLS sessions are
+// reused rather than made again for every request. It is created on first use, after the global flags that change
+// http.DefaultTransport, such as --insecure, have been applied.
+func Client() *http.Client {
+	sharedClient.Lock()
+	defer sharedClient.Unlock()
+	if sharedClient.client == nil {
+		sharedClient.client = &http.Client{Transport: newPooledTransport(sharedClient.options)}
+	}
+	return sharedClient.client
+}
+
+// newPooledTransport copies the settings of http.DefaultTransport, replacing its pool limits and adding the default
+// timeouts
+func newPooledTransport(options ClientOptions) *http.Transport {
+	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
+		DialContext:         timeoutDialContext(DefaultTimeouts),
+		MaxIdleConns:        options.MaxIdleConns,
+		MaxIdleConnsPerHost: options.MaxIdleConnsPerHost,
+		IdleConnTimeout:     options.IdleConnTimeout,
+	}
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		transport.Proxy = defaultTransport.Proxy
+		transport.TLSClientConfig = defaultTransport.TLSClientConfig
+		transport.TLSHandshakeTimeout = defaultTransport.TLSHandshakeTimeout
+		transport.ExpectContinueTimeout = defaultTransport.ExpectContinueTimeout
+	}
+	return transport
+}
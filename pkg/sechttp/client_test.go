@@ -0,0 +1,44 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("the same client is returned for every request", func(t *testing.T) {
+		assert.Same(t, Client(), Client())
+	})
+
+	t.Run("the pool limits come from the client options", func(t *testing.T) {
+		transport := Client().Transport.(*http.Transport)
+		assert.Equal(t, DefaultClientOptions.MaxIdleConnsPerHost, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, DefaultClientOptions.MaxIdleConns, transport.MaxIdleConns)
+	})
+
+	t.Run("configuring the client replaces it with one using the new limits", func(t *testing.T) {
+		previous := Client()
+		ConfigureClient(ClientOptions{MaxIdleConns: 10, MaxIdleConnsPerHost: 5, IdleConnTimeout: time.Minute})
+		defer ConfigureClient(DefaultClientOptions)
+
+		client := Client()
+		assert.False(t, previous == client)
+		transport := client.Transport.(*http.Transport)
+		assert.Equal(t, 5, transport.MaxIdleConnsPerHost)
+		assert.Equal(t, time.Minute, transport.IdleConnTimeout)
+	})
+}
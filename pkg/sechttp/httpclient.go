@@ -12,10 +12,12 @@
 package sechttp
 
 import (
+	"context"
 	"errors"
 	"flag"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/security"
@@ -24,14 +26,59 @@ import (
 	"github.com/urfave/cli"
 )
 
-// DispatchHTTPRequest : Perform an HTTP request against PFE with token based authentication
+// DispatchHTTPRequest : Perform an HTTP request against PFE with token based authentication, until the context of
+// the request is done
 // Returns: HTTPResponse, HTTPSecError
 func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Request, connection *connections.Connection) (*http.Response, *HTTPSecError) {
+	return DispatchHTTPRequestContext(originalRequest.Context(), httpClient, originalRequest, connection)
+}
+
+// DispatchHTTPRequestContext : Perform an HTTP request against PFE with token based authentication. Cancelling the
+// context stops the request, and any retry waiting to be sent, returning a tx_cancelled error.
+// Returns: HTTPResponse, HTTPSecError
+func DispatchHTTPRequestContext(ctx context.Context, httpClient utils.HTTPClient, originalRequest *http.Request, connection *connections.Connection) (*http.Response, *HTTPSecError) {
+	if ctx != originalRequest.Context() {
+		// The copy shares the headers and body of the original, so retries still rewind the body being sent
+		originalRequest = originalRequest.WithContext(ctx)
+	}
 
 	logr.Tracef("Request URL: %v %v\n", originalRequest.Method, originalRequest.URL)
 
+	httpClient, transportErr := ConnectionHTTPClient(httpClient, connection)
+	if transportErr != nil {
+		return nil, transportErr
+	}
+
+	// send retries the request while it fails with a transient error, tracing every attempt when --trace-http is set
+	retryPolicy := ConnectionRetryPolicy(connection)
+	canRetry := retryableRequest(originalRequest)
+	send := func(accessToken string) (*http.Response, *HTTPSecError) {
+		for retry := 1; ; retry++ {
+			if ctx.Err() != nil {
+				return nil, cancelledError(ctx)
+			}
+			start := time.Now()
+			response, err := sendRequest(httpClient, originalRequest, accessToken)
+			traceRequest(connection.ID, originalRequest, response, err, time.Since(start))
+			if err != nil && ctx.Err() != nil {
+				return nil, cancelledError(ctx)
+			}
+			if !canRetry || retry > retryPolicy.MaxRetries || !transientFailure(response, err) {
+				return response, err
+			}
+			if rewindErr := rewindRequest(originalRequest); rewindErr != nil {
+				return response, err
+			}
+			discardResponse(response)
+			delay := retryPolicy.backoff(retry)
+			logr.Tracef("Transient failure, retry %v of %v in %v", retry, retryPolicy.MaxRetries, delay)
+			traceDecision(connection.ID, "transient failure, retry %v of %v in %v", retry, retryPolicy.MaxRetries, delay)
+			retrySleep(ctx, delay)
+		}
+	}
+
 	if strings.ToLower(connection.ID) == "local" {
-		response, err := sendRequest(httpClient, originalRequest, "")
+		response, err := send("")
 		if err == nil {
 			logr.Tracef("Received HTTP Status code: %v\n", response.StatusCode)
 			return response, nil
@@ -39,6 +86,9 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 		logr.Tracef("Unable to contact server : %v\n", err)
 		return nil, err
 	}
+	if ctx.Err() != nil {
+		return nil, cancelledError(ctx)
+	}
 
 	// Should be a 401 (bearer only) but is infact a 302 (Redirect to a login page)
 	keycloakLoginErrorStatus := http.StatusFound
@@ -51,43 +101,48 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 
 	if accessToken == "" {
 		logr.Traceln("Access token not found in keychain")
+		traceDecision(connection.ID, "no access token in the keyring, refreshing")
+	} else if tokenExpiring(accessToken) {
+		logr.Traceln("Access token found in keychain but is about to expire")
+		traceDecision(connection.ID, "access token is about to expire, refreshing")
 	} else {
 		logr.Traceln("Access token found in keychain, trying request")
-		response, err := sendRequest(httpClient, originalRequest, accessToken)
+		response, err := send(accessToken)
 		if err == nil && response.StatusCode != keycloakLoginErrorStatus {
 			logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
 			return response, nil
 		}
-		logr.Tracef(" Request failed: %v", err.Desc)
+		if err != nil {
+			logr.Tracef(" Request failed: %v", err.Desc)
+			traceDecision(connection.ID, "request failed with the cached access token, refreshing")
+		} else {
+			traceDecision(connection.ID, "redirected to the login page, access token rejected, refreshing")
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil, cancelledError(ctx)
 	}
 
 	// Try refreshing the access token with our cached refresh token
-	logr.Tracef("Retrieving a refresh token from the keychain")
-	refreshToken, _ := security.GetSecretFromKeyring(conID, "refresh_token")
-	if refreshToken == "" {
-		logr.Tracef("Refresh token not found in keychain")
-	} else {
-		logr.Tracef("Try refreshing the access token with our cached refresh token")
-		tokens, secError := security.SecRefreshAccessToken(httpClient, connection, refreshToken)
-		if secError != nil {
-			logr.Tracef("Failed refreshing access token %v : %v\n", secError.Op, secError.Desc)
-		}
-		if tokens != nil {
-			logr.Tracef("New access token received")
-			accessToken = tokens.AccessToken
-			logr.Tracef("Trying the original request again with the new access_token")
-			response, err := sendRequest(httpClient, originalRequest, accessToken)
-			if err == nil && response.StatusCode != keycloakLoginErrorStatus {
-				logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
-				return response, nil
-			}
+	if newAccessToken, ok := refreshAccessToken(httpClient, connection, conID, accessToken); ok {
+		logr.Tracef("Trying the original request again with the new access_token")
+		response, err := send(newAccessToken)
+		if err == nil && response.StatusCode != keycloakLoginErrorStatus {
+			logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
+			return response, nil
 		}
 	}
+	if ctx.Err() != nil {
+		return nil, cancelledError(ctx)
+	}
+	traceDecision(connection.ID, "re-authenticating with the password in the keyring")
 
 	logr.Tracef("Re-authenticate using cached credentials from the keychain")
 	password, keyErr := security.GetSecretFromKeyring(conID, strings.ToLower(connection.Username))
 	if keyErr != nil {
 		logr.Tracef("ERROR:  %v\n", keyErr.Error())
+		traceDecision(connection.ID, "no password in the keyring, giving up")
 		err := errors.New(errMissingPassword)
 		return nil, &HTTPSecError{errOpNoPassword, err, err.Error()}
 	}
@@ -104,17 +159,21 @@ func DispatchHTTPRequest(httpClient utils.HTTPClient, originalRequest *http.Requ
 	if secError != nil {
 		// Bailing out, user cant authenticate
 		logr.Tracef("Bailing out, user can not authenticate")
+		traceDecision(connection.ID, "authentication failed: %v", secError.Desc)
 		return nil, &HTTPSecError{errOpAuthFailed, secError.Err, secError.Desc}
 	}
 
 	// Try to access the resource again with the new access token
 	logr.Tracef("Try to access the resource again with the new access token")
-	response, err := sendRequest(httpClient, originalRequest, tokens.AccessToken)
+	response, err := send(tokens.AccessToken)
 
 	if err == nil {
 		logr.Tracef("Received HTTP Status code: %v", response.StatusCode)
 		return response, nil
 	}
+	if ctx.Err() != nil {
+		return nil, cancelledError(ctx)
+	}
 
 	// No other methods of authentication left to try, tell the user and give up
 	logr.Tracef("No other methods of authentication left to try, tell the user and give up")
@@ -132,11 +191,23 @@ func sendRequest(httpClient utils.HTTPClient, originalRequest *http.Request, acc
 		originalRequest.Header.Set("cache-control", "no-cache")
 	}
 
-	// send request
-	res, err := httpClient.Do(originalRequest)
+	// send request, within the global --timeout for each attempt
+	request, cancel := withRequestTimeout(originalRequest)
+	res, err := httpClient.Do(request)
 	if err != nil {
+		if cancel != nil {
+			cancel()
+		}
 		logr.Tracef("sendRequest: REQUEST FAILED")
 		return nil, &HTTPSecError{errOpNoConnection, err, err.Error()}
 	}
+	if cancel != nil {
+		res.Body = &cancelOnClose{res.Body, cancel}
+	}
 	return res, nil
 }
+
+// cancelledError returns the error of a request stopped because its context is done
+func cancelledError(ctx context.Context) *HTTPSecError {
+	return &HTTPSecError{errOpCancelled, ctx.Err(), errRequestCancelled + ": " + ctx.Err().Error()}
+}
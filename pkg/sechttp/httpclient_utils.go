@@ -21,15 +21,26 @@ type HTTPSecError struct {
 }
 
 const (
-	errOpNoConnection = "tx_connection"
-	errOpAuthFailed   = "tx_auth"
-	errOpFailed       = "tx_failed"
-	errOpNoPassword   = "tx_nopassword"
+	errOpNoConnection  = "tx_connection"
+	errOpAuthFailed    = "tx_auth"
+	errOpFailed        = "tx_failed"
+	errOpNoPassword    = "tx_nopassword"
+	errOpBadProxy      = "tx_proxy"
+	errOpBadCACert     = "tx_cacert"
+	errOpBadClientCert = "tx_clientcert"
+	errOpCancelled     = "tx_cancelled"
+	errOpWebSocket     = "tx_websocket"
 )
 
 const (
 	errConnetionNotFound = "Cant find a valid connection"
 	errMissingPassword   = "Unable to find password in keychain"
+	errBadProxy          = "Invalid connection proxy"
+	errBadCACert         = "Invalid connection CA bundle"
+	errBadClientCert     = "Invalid connection client certificate"
+	errRequestCancelled  = "Request cancelled"
+	errWebSocketRefused  = "The server did not accept the WebSocket connection"
+	errWebSocketProtocol = "The server broke the WebSocket protocol"
 )
 
 // HTTPSecError : Error formatted in JSON containing an errorOp and a description from
@@ -0,0 +1,151 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/maintenance"
+)
+
+// RetryPolicy : How requests failing with a transient error, such as while the Gatekeeper restarts, are retried
+type RetryPolicy struct {
+	MaxRetries     int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy is used for connections that do not set their own retries or backoff
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:     3,
+	InitialBackoff: 500 * time.Millisecond,
+	MaxBackoff:     8 * time.Second,
+}
+
+// IdempotencyKeyHeader marks a request that is safe to retry although its method is not idempotent
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// retrySleep waits between retries, returning early when the context is done, and is replaced in tests
+var retrySleep = func(ctx context.Context, delay time.Duration) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
+
+// ConnectionRetryPolicy : Returns the retry policy of a connection, the default for settings it does not change
+func ConnectionRetryPolicy(connection *connections.Connection) RetryPolicy {
+	policy := DefaultRetryPolicy
+	if connection.Retries != nil {
+		policy.MaxRetries = *connection.Retries
+	}
+	if backoff, err := time.ParseDuration(connection.RetryBackoff); err == nil && backoff > 0 {
+		policy.InitialBackoff = backoff
+		if policy.MaxBackoff < backoff {
+			policy.MaxBackoff = backoff
+		}
+	}
+	return policy
+}
+
+// backoff returns the delay before the given retry, doubling from the initial backoff up to the maximum
+func (policy RetryPolicy) backoff(retry int) time.Duration {
+	delay := policy.InitialBackoff
+	for i := 1; i < retry && delay < policy.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > policy.MaxBackoff {
+		return policy.MaxBackoff
+	}
+	return delay
+}
+
+// retryableRequest reports whether a request can be sent again: its method must be idempotent, or it must carry an
+// idempotency key, and its body must be able to be read again
+func retryableRequest(req *http.Request) bool {
+	if req.Body != nil && req.Body != http.NoBody && req.GetBody == nil {
+		return false
+	}
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	}
+	return req.Header.Get(IdempotencyKeyHeader) != ""
+}
+
+// transientFailure reports whether a request failed in a way that is likely to succeed if sent again shortly: the
+// connection was refused, reset or timed out, or a proxy in front of PFE could not reach it. Responses announcing
+// maintenance mode are not transient.
+func transientFailure(res *http.Response, secErr *HTTPSecError) bool {
+	if secErr == nil {
+		if maintenance.Announced(res) {
+			return false
+		}
+		switch res.StatusCode {
+		case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			return true
+		}
+		return false
+	}
+	err := secErr.Err
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return true
+	}
+	// Unwrap the url.Error, net.OpError and os.SyscallError reporting why the connection failed
+	for {
+		switch wrapped := err.(type) {
+		case *url.Error:
+			err = wrapped.Err
+			continue
+		case *net.OpError:
+			err = wrapped.Err
+			continue
+		case *os.SyscallError:
+			err = wrapped.Err
+			continue
+		}
+		break
+	}
+	return err == syscall.ECONNRESET || err == syscall.ECONNREFUSED || err == io.EOF || err == io.ErrUnexpectedEOF
+}
+
+// rewindRequest replaces a request body that has been sent with a new copy, so the request can be sent again
+func rewindRequest(req *http.Request) error {
+	if req.GetBody == nil {
+		return nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return err
+	}
+	req.Body = body
+	return nil
+}
+
+// discardResponse reads a response that will not be returned to the end, so the connection can be reused
+func discardResponse(res *http.Response) {
+	if res != nil && res.Body != nil {
+		io.Copy(ioutil.Discard, res.Body)
+		res.Body.Close()
+	}
+}
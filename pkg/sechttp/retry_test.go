@@ -0,0 +1,183 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/maintenance"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionRetryPolicy(t *testing.T) {
+	t.Run("connections without retry settings use the default policy", func(t *testing.T) {
+		assert.Equal(t, DefaultRetryPolicy, ConnectionRetryPolicy(&connections.Connection{}))
+	})
+
+	t.Run("connections can change the retries and backoff", func(t *testing.T) {
+		retries := 0
+		policy := ConnectionRetryPolicy(&connections.Connection{Retries: &retries, RetryBackoff: "10s"})
+		assert.Equal(t, 0, policy.MaxRetries)
+		assert.Equal(t, 10*time.Second, policy.InitialBackoff)
+		assert.Equal(t, 10*time.Second, policy.MaxBackoff)
+	})
+
+	t.Run("the backoff doubles up to the maximum", func(t *testing.T) {
+		policy := RetryPolicy{MaxRetries: 5, InitialBackoff: time.Second, MaxBackoff: 3 * time.Second}
+		assert.Equal(t, time.Second, policy.backoff(1))
+		assert.Equal(t, 2*time.Second, policy.backoff(2))
+		assert.Equal(t, 3*time.Second, policy.backoff(3))
+		assert.Equal(t, 3*time.Second, policy.backoff(5))
+	})
+}
+
+func TestDispatchHTTPRequestRetries(t *testing.T) {
+	failures := 0
+	bodies := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	delays := []time.Duration{}
+	originalSleep := retrySleep
+	retrySleep = func(_ context.Context, delay time.Duration) { delays = append(delays, delay) }
+	defer func() { retrySleep = originalSleep }()
+	local := &connections.Connection{ID: "local"}
+	reset := func(failing int) {
+		failures = failing
+		bodies = []string{}
+		delays = []time.Duration{}
+	}
+
+	t.Run("idempotent requests are retried with backoff until they succeed", func(t *testing.T) {
+		reset(2)
+		req, _ := http.NewRequest("PUT", server.URL+"/api/v1/projects/123/upload", bytes.NewReader([]byte("file")))
+		resp, err := DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"file", "file", "file"}, bodies)
+		assert.Equal(t, []time.Duration{500 * time.Millisecond, time.Second}, delays)
+	})
+
+	t.Run("the last failure is returned once the retries are used up", func(t *testing.T) {
+		reset(10)
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, err := DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, bodies, DefaultRetryPolicy.MaxRetries+1)
+	})
+
+	t.Run("requests which are not idempotent are only retried with an idempotency key", func(t *testing.T) {
+		reset(1)
+		req, _ := http.NewRequest("POST", server.URL, bytes.NewBufferString("{}"))
+		resp, _ := DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, bodies, 1)
+
+		reset(1)
+		req, _ = http.NewRequest("POST", server.URL, bytes.NewBufferString("{}"))
+		req.Header.Set(IdempotencyKeyHeader, "bind-123")
+		resp, _ = DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, []string{"{}", "{}"}, bodies)
+	})
+
+	t.Run("connections can turn retries off", func(t *testing.T) {
+		reset(1)
+		retries := 0
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		resp, _ := DispatchHTTPRequest(http.DefaultClient, req, &connections.Connection{ID: "local", Retries: &retries})
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, delays, 0)
+	})
+
+	t.Run("responses announcing maintenance mode are not retried", func(t *testing.T) {
+		reset(0)
+		maintenanceServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			bodies = append(bodies, "")
+			w.Header().Set(maintenance.Header, "true")
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer maintenanceServer.Close()
+		req, _ := http.NewRequest("GET", maintenanceServer.URL, nil)
+		resp, err := DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+		assert.Len(t, bodies, 1)
+		assert.Len(t, delays, 0)
+	})
+
+	t.Run("refused connections are retried", func(t *testing.T) {
+		reset(0)
+		req, _ := http.NewRequest("GET", "http://127.0.0.1:1/api", nil)
+		_, err := DispatchHTTPRequest(http.DefaultClient, req, local)
+		assert.NotNil(t, err)
+		assert.Len(t, delays, DefaultRetryPolicy.MaxRetries)
+	})
+}
+
+func TestDispatchHTTPRequestContext(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	local := &connections.Connection{ID: "local"}
+
+	t.Run("a cancelled context stops the retries", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		originalSleep := retrySleep
+		retrySleep = func(context.Context, time.Duration) { cancel() }
+		defer func() { retrySleep = originalSleep }()
+
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		_, err := DispatchHTTPRequestContext(ctx, http.DefaultClient, req, local)
+		assert.NotNil(t, err)
+		assert.Equal(t, errOpCancelled, err.Op)
+		assert.Equal(t, 1, requests)
+	})
+
+	t.Run("a request is not sent once its context is done", func(t *testing.T) {
+		requests = 0
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		_, err := DispatchHTTPRequest(http.DefaultClient, req.WithContext(ctx), local)
+		assert.NotNil(t, err)
+		assert.Equal(t, errOpCancelled, err.Op)
+		assert.Equal(t, 0, requests)
+	})
+
+	t.Run("the wait before a retry ends when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		start := time.Now()
+		retrySleep(ctx, time.Minute)
+		assert.True(t, time.Since(start) < time.Second)
+	})
+}
@@ -0,0 +1,126 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+)
+
+// Timeouts : How long requests wait on the network before failing, so that a half-open connection cannot stall a
+// command, such as a project sync, forever
+type Timeouts struct {
+	// Connect is how long to wait for a network connection to be made
+	Connect time.Duration
+	// Read is how long a request can go without reading or writing any data on its connection
+	Read time.Duration
+}
+
+// DefaultTimeouts are used for connections that do not set their own timeouts
+var DefaultTimeouts = Timeouts{
+	Connect: 30 * time.Second,
+	Read:    2 * time.Minute,
+}
+
+// ConnectionTimeouts : Returns the timeouts of a connection, the default for settings it does not change
+func ConnectionTimeouts(connection *connections.Connection) Timeouts {
+	timeouts := DefaultTimeouts
+	if connect, err := time.ParseDuration(connection.ConnectTimeout); err == nil && connect > 0 {
+		timeouts.Connect = connect
+	}
+	if read, err := time.ParseDuration(connection.ReadTimeout); err == nil && read > 0 {
+		timeouts.Read = read
+	}
+	return timeouts
+}
+
+// dialContextFunc is the signature of http.Transport.DialContext
+type dialContextFunc func(ctx context.Context, network string, address string) (net.Conn, error)
+
+// timeoutDialContext returns a dial function that gives up connecting after the connect timeout, and returns
+// connections that fail a read or write once the read timeout passes without any data
+func timeoutDialContext(timeouts Timeouts) dialContextFunc {
+	dial := (&net.Dialer{KeepAlive: 30 * time.Second}).DialContext
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok && defaultTransport.DialContext != nil {
+		dial = defaultTransport.DialContext
+	}
+	return func(ctx context.Context, network string, address string) (net.Conn, error) {
+		dialCtx, cancel := context.WithTimeout(ctx, timeouts.Connect)
+		defer cancel()
+		conn, err := dial(dialCtx, network, address)
+		if err != nil {
+			return nil, err
+		}
+		return &idleTimeoutConn{Conn: conn, timeout: timeouts.Read}, nil
+	}
+}
+
+// idleTimeoutConn moves the deadline of a connection forward before every read and write, so transfers that keep
+// making progress are never cut short, but a peer that stops responding is. Writes also move the read deadline, as
+// the response is read while a request body is still being sent.
+type idleTimeoutConn struct {
+	net.Conn
+	timeout time.Duration
+}
+
+func (conn *idleTimeoutConn) Read(b []byte) (int, error) {
+	if err := conn.Conn.SetReadDeadline(time.Now().Add(conn.timeout)); err != nil {
+		return 0, err
+	}
+	return conn.Conn.Read(b)
+}
+
+func (conn *idleTimeoutConn) Write(b []byte) (int, error) {
+	if err := conn.Conn.SetDeadline(time.Now().Add(conn.timeout)); err != nil {
+		return 0, err
+	}
+	return conn.Conn.Write(b)
+}
+
+// noRequestTimeoutKey marks the context of requests that the global --timeout does not apply to
+type noRequestTimeoutKey struct{}
+
+// WithoutRequestTimeout : Returns a context whose requests are not given the deadline of the global --timeout. It is
+// for streams that last as long as the user wants, such as WebSockets, and for large transfers, which the read
+// timeout of the connection still stops if they stall.
+func WithoutRequestTimeout(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noRequestTimeoutKey{}, true)
+}
+
+// withRequestTimeout returns a copy of a request that must finish within the global --timeout, and the function
+// releasing its deadline, or the request and nil when there is no deadline to set
+func withRequestTimeout(req *http.Request) (*http.Request, context.CancelFunc) {
+	if globals.RequestTimeout <= 0 || req.Context().Value(noRequestTimeoutKey{}) != nil {
+		return req, nil
+	}
+	ctx, cancel := context.WithTimeout(req.Context(), globals.RequestTimeout)
+	return req.WithContext(ctx), cancel
+}
+
+// cancelOnClose releases the deadline of a request once the body of its response is closed, so that the deadline
+// also covers reading the response
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (body *cancelOnClose) Close() error {
+	err := body.ReadCloser.Close()
+	body.cancel()
+	return err
+}
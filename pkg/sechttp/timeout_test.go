@@ -0,0 +1,136 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectionTimeouts(t *testing.T) {
+	t.Run("uses the default timeouts for a connection without its own", func(t *testing.T) {
+		assert.Equal(t, DefaultTimeouts, ConnectionTimeouts(&connections.Connection{}))
+	})
+
+	t.Run("uses the timeouts of the connection", func(t *testing.T) {
+		timeouts := ConnectionTimeouts(&connections.Connection{ConnectTimeout: "5s", ReadTimeout: "1m"})
+		assert.Equal(t, 5*time.Second, timeouts.Connect)
+		assert.Equal(t, time.Minute, timeouts.Read)
+	})
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	// A listener that accepts connections but never responds, as a half-open connection would
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go func() {
+		var conns []net.Conn
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				for _, conn := range conns {
+					conn.Close()
+				}
+				return
+			}
+			conns = append(conns, conn)
+		}
+	}()
+	url := "http://" + listener.Addr().String()
+
+	t.Run("fails a request that reads nothing for the read timeout", func(t *testing.T) {
+		client, _ := ConnectionHTTPClient(&http.Client{}, &connections.Connection{ReadTimeout: "100ms"})
+		start := time.Now()
+		_, err := client.(*http.Client).Get(url)
+		assert.NotNil(t, err)
+		assert.True(t, err.(net.Error).Timeout())
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+
+	t.Run("fails a request attempt that misses the deadline of the command", func(t *testing.T) {
+		globals.SetRequestTimeout(100 * time.Millisecond)
+		defer globals.SetRequestTimeout(0)
+		retries := 0
+		req, _ := http.NewRequest("GET", url, nil)
+		start := time.Now()
+		_, secErr := DispatchHTTPRequest(&http.Client{}, req, &connections.Connection{ID: "local", Retries: &retries})
+		assert.NotNil(t, secErr)
+		assert.True(t, secErr.Err.(net.Error).Timeout())
+		assert.True(t, time.Since(start) < 5*time.Second)
+	})
+
+	t.Run("the deadline of the command covers reading the response", func(t *testing.T) {
+		globals.SetRequestTimeout(time.Second)
+		defer globals.SetRequestTimeout(0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.(http.Flusher).Flush()
+			time.Sleep(50 * time.Millisecond)
+			w.Write([]byte("done"))
+		}))
+		defer server.Close()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res, secErr := DispatchHTTPRequest(&http.Client{}, req, &connections.Connection{ID: "local"})
+		if assert.Nil(t, secErr) {
+			body, err := ioutil.ReadAll(res.Body)
+			res.Body.Close()
+			assert.Nil(t, err)
+			assert.Equal(t, "done", string(body))
+		}
+	})
+
+	t.Run("streaming requests are not given the deadline of the command", func(t *testing.T) {
+		globals.SetRequestTimeout(100 * time.Millisecond)
+		defer globals.SetRequestTimeout(0)
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+			w.Write([]byte("done"))
+		}))
+		defer server.Close()
+		req, _ := http.NewRequest("GET", server.URL, nil)
+		res, secErr := DispatchHTTPRequestContext(WithoutRequestTimeout(context.Background()), &http.Client{}, req, &connections.Connection{ID: "local"})
+		if assert.Nil(t, secErr) {
+			res.Body.Close()
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+		}
+	})
+
+	t.Run("does not fail a slow response that keeps sending data", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			for i := 0; i < 5; i++ {
+				w.Write([]byte("."))
+				w.(http.Flusher).Flush()
+				time.Sleep(50 * time.Millisecond)
+			}
+		}))
+		defer server.Close()
+		client, _ := ConnectionHTTPClient(&http.Client{}, &connections.Connection{ReadTimeout: "200ms"})
+		res, err := client.(*http.Client).Get(server.URL)
+		if assert.Nil(t, err) {
+			defer res.Body.Close()
+			body, err := ioutil.ReadAll(res.Body)
+			assert.Nil(t, err)
+			assert.Equal(t, ".....", string(body))
+		}
+	})
+}
@@ -0,0 +1,87 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	logr "github.com/sirupsen/logrus"
+)
+
+// tokenRefreshSkew is how long before its expiry an access token is refreshed, allowing for clock differences
+// between the CLI and Keycloak and for the time taken by the request itself
+const tokenRefreshSkew = 30 * time.Second
+
+var (
+	tokenLocksMutex sync.Mutex
+	tokenLocks      = map[string]*sync.Mutex{}
+)
+
+// tokenLock returns the lock serializing token refreshes for a connection
+func tokenLock(conID string) *sync.Mutex {
+	tokenLocksMutex.Lock()
+	defer tokenLocksMutex.Unlock()
+	lock, ok := tokenLocks[conID]
+	if !ok {
+		lock = &sync.Mutex{}
+		tokenLocks[conID] = lock
+	}
+	return lock
+}
+
+// tokenExpiring reports whether an access token expires within the refresh skew. Tokens without a readable
+// expiry are assumed to be valid and are only refreshed once PFE rejects them.
+func tokenExpiring(token string) bool {
+	expiry, ok := security.TokenExpiry(token)
+	return ok && time.Now().Add(tokenRefreshSkew).After(expiry)
+}
+
+// refreshAccessToken exchanges the cached refresh token for a new access token. Refreshes for the same connection
+// are serialized, and a request finding that another has already replaced staleToken uses that token instead of
+// refreshing again. Returns false if no new token could be obtained.
+func refreshAccessToken(httpClient utils.HTTPClient, connection *connections.Connection, conID string, staleToken string) (string, bool) {
+	lock := tokenLock(conID)
+	lock.Lock()
+	defer lock.Unlock()
+
+	accessToken, _ := security.GetSecretFromKeyring(conID, "access_token")
+	if accessToken != "" && accessToken != staleToken && !tokenExpiring(accessToken) {
+		logr.Tracef("Access token was refreshed by another request")
+		traceDecision(connection.ID, "using the access token refreshed by another request")
+		return accessToken, true
+	}
+
+	logr.Tracef("Retrieving a refresh token from the keychain")
+	refreshToken, _ := security.GetSecretFromKeyring(conID, "refresh_token")
+	if refreshToken == "" {
+		logr.Tracef("Refresh token not found in keychain")
+		traceDecision(connection.ID, "no refresh token in the keyring")
+		return "", false
+	}
+
+	logr.Tracef("Try refreshing the access token with our cached refresh token")
+	tokens, secError := security.SecRefreshAccessToken(httpClient, connection, refreshToken)
+	if secError != nil {
+		logr.Tracef("Failed refreshing access token %v : %v\n", secError.Op, secError.Desc)
+		traceDecision(connection.ID, "refreshing the access token failed: %v", secError.Desc)
+	}
+	if tokens == nil {
+		return "", false
+	}
+	logr.Tracef("New access token received")
+	traceDecision(connection.ID, "access token refreshed, retrying")
+	return tokens.AccessToken, true
+}
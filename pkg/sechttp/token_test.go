@@ -0,0 +1,126 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/stretchr/testify/assert"
+)
+
+// MockTokenServer answers token requests with a new access token, and every other request with 200
+type MockTokenServer struct {
+	refreshes int32
+	expiresIn time.Duration
+}
+
+// Do makes a http request
+func (c *MockTokenServer) Do(req *http.Request) (*http.Response, error) {
+	if !strings.HasSuffix(req.URL.Path, "/protocol/openid-connect/token") {
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(nil))}, nil
+	}
+	count := atomic.AddInt32(&c.refreshes, 1)
+	tokens, _ := json.Marshal(&security.AuthToken{
+		AccessToken:  mockToken(time.Now().Add(c.expiresIn), int(count)),
+		RefreshToken: "mockRefreshToken",
+	})
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(tokens))}, nil
+}
+
+// mockToken builds an unsigned token expiring at the given time
+func mockToken(expiry time.Time, id int) string {
+	claims := `{"exp":` + strconv.FormatInt(expiry.Unix(), 10) + `,"jti":"` + strconv.Itoa(id) + `"}`
+	return "header." + base64.RawURLEncoding.EncodeToString([]byte(claims)) + ".signature"
+}
+
+func Test_TokenExpiring(t *testing.T) {
+	t.Run("A token expiring within the skew is refreshed", func(t *testing.T) {
+		assert.True(t, tokenExpiring(mockToken(time.Now().Add(tokenRefreshSkew/2), 0)))
+		assert.True(t, tokenExpiring(mockToken(time.Now().Add(-time.Minute), 0)))
+	})
+	t.Run("A token with time left, or no readable expiry, is used as is", func(t *testing.T) {
+		assert.False(t, tokenExpiring(mockToken(time.Now().Add(time.Hour), 0)))
+		assert.False(t, tokenExpiring("mockAccessToken"))
+	})
+}
+
+func TestDispatchHTTPRequestRefreshesTokens(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping testing in short mode")
+	}
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	os.Remove(security.GetPathToInsecureKeyring())
+	defer os.Remove(security.GetPathToInsecureKeyring())
+
+	connections.ResetConnectionsFile()
+	defer connections.ResetConnectionsFile()
+	connection := connections.Connection{ID: "TOKENCON", Label: "Tokens", URL: "https://codewind.token.remote", AuthURL: "https://auth.token.remote", Realm: "remoteRealm", ClientID: "remoteClient", Username: "developer"}
+	connections.ImportConnection(connection)
+	conID := strings.ToLower(connection.ID)
+
+	t.Run("An access token about to expire is refreshed before the request is sent", func(t *testing.T) {
+		security.StoreSecretInKeyring(conID, "access_token", mockToken(time.Now().Add(5*time.Second), 0))
+		security.StoreSecretInKeyring(conID, "refresh_token", "mockRefreshToken")
+		mockClient := &MockTokenServer{expiresIn: time.Hour}
+
+		resp, err := DispatchHTTPRequest(mockClient, httptest.NewRequest("GET", "/", nil), &connection)
+		assert.Nil(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, int32(1), mockClient.refreshes)
+
+		accessToken, _ := security.GetSecretFromKeyring(conID, "access_token")
+		assert.False(t, tokenExpiring(accessToken))
+	})
+
+	t.Run("An access token with time left is not refreshed", func(t *testing.T) {
+		security.StoreSecretInKeyring(conID, "access_token", mockToken(time.Now().Add(time.Hour), 0))
+		mockClient := &MockTokenServer{expiresIn: time.Hour}
+
+		_, err := DispatchHTTPRequest(mockClient, httptest.NewRequest("GET", "/", nil), &connection)
+		assert.Nil(t, err)
+		assert.Equal(t, int32(0), mockClient.refreshes)
+	})
+
+	t.Run("Concurrent requests share a single refresh", func(t *testing.T) {
+		security.StoreSecretInKeyring(conID, "access_token", mockToken(time.Now().Add(-time.Minute), 0))
+		mockClient := &MockTokenServer{expiresIn: time.Hour}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := DispatchHTTPRequest(mockClient, httptest.NewRequest("GET", "/", nil), &connection)
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, int32(1), mockClient.refreshes)
+	})
+}
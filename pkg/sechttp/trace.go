@@ -0,0 +1,96 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/globals"
+)
+
+// traceOutput is where HTTP traces are written, away from the command output on stdout, and is replaced in tests
+var traceOutput io.Writer = os.Stderr
+
+var traceMutex sync.Mutex
+
+// sanitizedHeaders carry credentials, so only their presence is traced
+var sanitizedHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// traceRequest writes the method, URL, status and latency of a request sent to a connection
+func traceRequest(conID string, req *http.Request, res *http.Response, secErr *HTTPSecError, latency time.Duration) {
+	if !globals.TraceHTTP {
+		return
+	}
+	outcome := ""
+	if secErr != nil {
+		outcome = "failed: " + secErr.Desc
+	} else {
+		outcome = res.Status
+		if outcome == "" {
+			outcome = fmt.Sprint(res.StatusCode)
+		}
+	}
+	lines := []string{fmt.Sprintf("%v %v -> %v (%v)", req.Method, req.URL, outcome, latency.Round(time.Millisecond))}
+	if globals.TraceHTTPHeaders {
+		lines = append(lines, traceHeaders("> ", req.Header)...)
+		if res != nil {
+			lines = append(lines, traceHeaders("< ", res.Header)...)
+		}
+	}
+	writeTrace(conID, lines...)
+}
+
+// traceDecision writes why a request is being retried or its credentials replaced
+func traceDecision(conID string, format string, args ...interface{}) {
+	if !globals.TraceHTTP {
+		return
+	}
+	writeTrace(conID, fmt.Sprintf(format, args...))
+}
+
+// traceHeaders formats headers in name order, replacing the values of those carrying credentials
+func traceHeaders(prefix string, header http.Header) []string {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	lines := []string{}
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if sanitizedHeaders[http.CanonicalHeaderKey(name)] {
+			value = "[REDACTED]"
+		}
+		lines = append(lines, prefix+name+": "+value)
+	}
+	return lines
+}
+
+func writeTrace(conID string, lines ...string) {
+	traceMutex.Lock()
+	defer traceMutex.Unlock()
+	for _, line := range lines {
+		fmt.Fprintf(traceOutput, "[http %v] %v\n", conID, line)
+	}
+}
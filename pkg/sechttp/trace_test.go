@@ -0,0 +1,86 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraceHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Set-Cookie", "session=secret")
+		w.Header().Set("X-Request-Id", "abc")
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+	output := &bytes.Buffer{}
+	originalOutput := traceOutput
+	traceOutput = output
+	defer func() {
+		traceOutput = originalOutput
+		globals.SetTraceHTTP(false, false)
+	}()
+
+	dispatch := func() {
+		req, _ := http.NewRequest("GET", server.URL+"/api/v1/projects", nil)
+		req.Header.Set("Authorization", "bearer secret")
+		_, err := DispatchHTTPRequest(http.DefaultClient, req, &connections.Connection{ID: "local"})
+		assert.Nil(t, err)
+	}
+
+	t.Run("nothing is traced unless enabled", func(t *testing.T) {
+		output.Reset()
+		globals.SetTraceHTTP(false, false)
+		dispatch()
+		assert.Equal(t, "", output.String())
+	})
+
+	t.Run("traces the method, URL and status of each request", func(t *testing.T) {
+		output.Reset()
+		globals.SetTraceHTTP(true, false)
+		dispatch()
+		assert.Contains(t, output.String(), "[http local] GET "+server.URL+"/api/v1/projects -> 202 Accepted (")
+		assert.NotContains(t, output.String(), "X-Request-Id")
+	})
+
+	t.Run("traces headers with credentials removed", func(t *testing.T) {
+		output.Reset()
+		globals.SetTraceHTTP(false, true)
+		dispatch()
+		assert.Contains(t, output.String(), "[http local] > Authorization: [REDACTED]\n")
+		assert.Contains(t, output.String(), "[http local] < Set-Cookie: [REDACTED]\n")
+		assert.Contains(t, output.String(), "[http local] < X-Request-Id: abc\n")
+		assert.NotContains(t, output.String(), "secret")
+	})
+
+	t.Run("traces failed requests", func(t *testing.T) {
+		output.Reset()
+		globals.SetTraceHTTP(true, false)
+		originalSleep := retrySleep
+		retrySleep = func(context.Context, time.Duration) {}
+		defer func() { retrySleep = originalSleep }()
+		req, _ := http.NewRequest("GET", "http://127.0.0.1:1/api", nil)
+		_, err := DispatchHTTPRequest(http.DefaultClient, req, &connections.Connection{ID: "local"})
+		assert.NotNil(t, err)
+		assert.Contains(t, output.String(), "[http local] GET http://127.0.0.1:1/api -> failed: ")
+		assert.Contains(t, output.String(), "[http local] transient failure, retry 1 of 3 in 500ms\n")
+	})
+}
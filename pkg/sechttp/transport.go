@@ -0,0 +1,179 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/security"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"golang.org/x/net/http/httpproxy"
+)
+
+// connectionTransports caches a transport for each proxy and TLS configuration so that connections are reused
+var connectionTransports = struct {
+	sync.Mutex
+	transports map[string]cachedTransport
+}{transports: map[string]cachedTransport{}}
+
+// cachedTransport is a transport with the versions of the certificate files it was built from, see certFilesVersion
+type cachedTransport struct {
+	transport    *http.Transport
+	certsVersion string
+}
+
+// ConnectionHTTPClient : Returns a client that sends requests using the proxy, TLS and timeout settings of the
+// connection. The connection proxy is used instead of HTTP_PROXY and HTTPS_PROXY, and its no-proxy hosts are added to
+// NO_PROXY. Its CA bundle is trusted in addition to the system certificate authorities, and its client certificate is
+// presented to servers requiring mutual TLS. Certificate files replaced in place, such as when they are rotated, are
+// loaded again. Connections without these settings, and clients other than *http.Client such as test mocks, are
+// returned unchanged.
+func ConnectionHTTPClient(httpClient utils.HTTPClient, connection *connections.Connection) (utils.HTTPClient, *HTTPSecError) {
+	client, ok := httpClient.(*http.Client)
+	if !ok {
+		return httpClient, nil
+	}
+	if connection.ProxyURL == "" && connection.NoProxy == "" && connection.CACert == "" && !connection.InsecureSkipVerify && connection.ClientCert == "" && connection.ConnectTimeout == "" && connection.ReadTimeout == "" {
+		return httpClient, nil
+	}
+	baseTransport, ok := client.Transport.(*http.Transport)
+	if client.Transport == nil {
+		baseTransport, ok = http.DefaultTransport.(*http.Transport)
+	}
+	if !ok {
+		return httpClient, nil
+	}
+
+	config := httpproxy.FromEnvironment()
+	if connection.ProxyURL != "" {
+		if _, err := url.Parse(connection.ProxyURL); err != nil {
+			proxyErr := errors.New(errBadProxy + ": " + err.Error())
+			return nil, &HTTPSecError{errOpBadProxy, proxyErr, proxyErr.Error()}
+		}
+		proxyURL, secErr := security.SecProxyURL(connection)
+		if secErr != nil {
+			return nil, &HTTPSecError{errOpBadProxy, secErr, secErr.Desc}
+		}
+		config.HTTPProxy = proxyURL
+		config.HTTPSProxy = proxyURL
+	}
+	if connection.NoProxy != "" {
+		config.NoProxy = strings.Trim(connection.NoProxy+","+config.NoProxy, ",")
+	}
+
+	timeouts := ConnectionTimeouts(connection)
+	key := config.HTTPProxy + "|" + config.HTTPSProxy + "|" + config.NoProxy + "|" + connection.CACert + "|" + strconv.FormatBool(connection.InsecureSkipVerify) + "|" + connection.ClientCert + "|" + connection.ClientKey + "|" + timeouts.Connect.String() + "|" + timeouts.Read.String()
+	certsVersion := certFilesVersion(connection)
+	connectionTransports.Lock()
+	defer connectionTransports.Unlock()
+	cached, found := connectionTransports.transports[key]
+	transport := cached.transport
+	if !found || cached.certsVersion != certsVersion {
+		tlsConfig, tlsErr := connectionTLSConfig(baseTransport.TLSClientConfig, connection)
+		if tlsErr != nil {
+			return nil, tlsErr
+		}
+		proxyFunc := config.ProxyFunc()
+		transport = &http.Transport{
+			Proxy: func(req *http.Request) (*url.URL, error) {
+				return proxyFunc(req.URL)
+			},
+			DialContext:           timeoutDialContext(timeouts),
+			TLSClientConfig:       tlsConfig,
+			TLSHandshakeTimeout:   baseTransport.TLSHandshakeTimeout,
+			MaxIdleConns:          baseTransport.MaxIdleConns,
+			MaxIdleConnsPerHost:   baseTransport.MaxIdleConnsPerHost,
+			IdleConnTimeout:       baseTransport.IdleConnTimeout,
+			ExpectContinueTimeout: baseTransport.ExpectContinueTimeout,
+		}
+		if found {
+			// The certificate files have changed, so stop reusing connections made with the old ones
+			cached.transport.CloseIdleConnections()
+		}
+		connectionTransports.transports[key] = cachedTransport{transport: transport, certsVersion: certsVersion}
+	}
+
+	connectionClient := *client
+	connectionClient.Transport = transport
+	return &connectionClient, nil
+}
+
+// certFilesVersion returns the modification times and sizes of the CA bundle, client certificate and key files of a
+// connection, which change when a file is replaced
+func certFilesVersion(connection *connections.Connection) string {
+	version := ""
+	for _, path := range []string{connection.CACert, connection.ClientCert, connection.ClientKey} {
+		if info, err := os.Stat(path); path != "" && err == nil {
+			version += strconv.FormatInt(info.ModTime().UnixNano(), 10) + ":" + strconv.FormatInt(info.Size(), 10)
+		}
+		version += "|"
+	}
+	return version
+}
+
+// ConnectionTLSConfig : Returns the TLS settings used for requests to the connection
+func ConnectionTLSConfig(connection *connections.Connection) (*tls.Config, *HTTPSecError) {
+	var base *tls.Config
+	if defaultTransport, ok := http.DefaultTransport.(*http.Transport); ok {
+		base = defaultTransport.TLSClientConfig
+	}
+	return connectionTLSConfig(base, connection)
+}
+
+// connectionTLSConfig adds the CA bundle, client certificate and verification setting of the connection to a copy
+// of the base config
+func connectionTLSConfig(base *tls.Config, connection *connections.Connection) (*tls.Config, *HTTPSecError) {
+	tlsConfig := &tls.Config{}
+	if base != nil {
+		tlsConfig = base.Clone()
+	}
+	if connection.InsecureSkipVerify {
+		tlsConfig.InsecureSkipVerify = true
+	}
+	if connection.ClientCert != "" {
+		certificate, err := tls.LoadX509KeyPair(connection.ClientCert, connection.ClientKey)
+		if err != nil {
+			certErr := errors.New(errBadClientCert + ": " + err.Error())
+			return nil, &HTTPSecError{errOpBadClientCert, certErr, certErr.Error()}
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+	if connection.CACert == "" {
+		return tlsConfig, nil
+	}
+
+	contents, err := ioutil.ReadFile(connection.CACert)
+	if err != nil {
+		certErr := errors.New(errBadCACert + ": " + err.Error())
+		return nil, &HTTPSecError{errOpBadCACert, certErr, certErr.Error()}
+	}
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+	if !rootCAs.AppendCertsFromPEM(contents) {
+		certErr := errors.New(errBadCACert + ": " + connection.CACert + " does not contain any PEM encoded certificates")
+		return nil, &HTTPSecError{errOpBadCACert, certErr, certErr.Error()}
+	}
+	tlsConfig.RootCAs = rootCAs
+	return tlsConfig, nil
+}
@@ -0,0 +1,236 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// WebSocket opcodes, from RFC 6455
+const (
+	wsContinuation = 0x0
+	wsText         = 0x1
+	wsBinary       = 0x2
+	wsClose        = 0x8
+	wsPing         = 0x9
+	wsPong         = 0xa
+)
+
+// wsAcceptGUID is appended to the key of the handshake by the server, proving it understood the request
+const wsAcceptGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// wsMaxMessageSize stops a broken or hostile server making the client buffer an unbounded message
+const wsMaxMessageSize = 16 << 20
+
+// WebSocket : A WebSocket connection to PFE, for reading the events it sends. Messages can be written while another
+// goroutine is reading.
+type WebSocket struct {
+	conn       io.ReadWriteCloser
+	reader     *bufio.Reader
+	writeMutex sync.Mutex
+	closeOnce  sync.Once
+	closed     chan struct{}
+}
+
+// DialWebSocket : Opens a WebSocket to an http or https URL of a connection, with the same authentication, proxy,
+// TLS and retries as DispatchHTTPRequest. Cancelling the context closes the WebSocket.
+func DialWebSocket(ctx context.Context, httpClient utils.HTTPClient, url string, connection *connections.Connection) (*WebSocket, *HTTPSecError) {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, &HTTPSecError{errOpWebSocket, err, err.Error()}
+	}
+	key := base64.StdEncoding.EncodeToString(nonce)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, &HTTPSecError{errOpWebSocket, err, err.Error()}
+	}
+	req.Header.Set("Connection", "Upgrade")
+	req.Header.Set("Upgrade", "websocket")
+	req.Header.Set("Sec-WebSocket-Version", "13")
+	req.Header.Set("Sec-WebSocket-Key", key)
+
+	// The WebSocket stays open for as long as the caller reads from it, so the global --timeout does not apply
+	resp, secErr := DispatchHTTPRequestContext(WithoutRequestTimeout(ctx), httpClient, req, connection)
+	if secErr != nil {
+		return nil, secErr
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != wsAccept(key) {
+		resp.Body.Close()
+		err := fmt.Errorf("%s: %s", errWebSocketRefused, resp.Status)
+		return nil, &HTTPSecError{errOpWebSocket, err, err.Error()}
+	}
+	// Since Go 1.12 the body of a 101 response is the connection itself
+	conn, ok := resp.Body.(io.ReadWriteCloser)
+	if !ok {
+		resp.Body.Close()
+		err := errors.New(errWebSocketRefused)
+		return nil, &HTTPSecError{errOpWebSocket, err, err.Error()}
+	}
+
+	ws := newWebSocket(conn)
+	go func() {
+		select {
+		case <-ctx.Done():
+			ws.Close()
+		case <-ws.closed:
+		}
+	}()
+	return ws, nil
+}
+
+// wsAccept returns the Sec-WebSocket-Accept header a server answers a handshake key with
+func wsAccept(key string) string {
+	hash := sha1.Sum([]byte(key + wsAcceptGUID))
+	return base64.StdEncoding.EncodeToString(hash[:])
+}
+
+func newWebSocket(conn io.ReadWriteCloser) *WebSocket {
+	return &WebSocket{conn: conn, reader: bufio.NewReader(conn), closed: make(chan struct{})}
+}
+
+// ReadMessage : Returns the next text or binary message, answering pings while waiting for it. Returns io.EOF once
+// the server closes the WebSocket.
+func (ws *WebSocket) ReadMessage() ([]byte, error) {
+	var message []byte
+	for {
+		fin, opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return nil, err
+		}
+		switch opcode {
+		case wsClose:
+			ws.Close()
+			return nil, io.EOF
+		case wsPing:
+			if err := ws.writeFrame(wsPong, payload); err != nil {
+				return nil, err
+			}
+			continue
+		case wsPong:
+			continue
+		case wsText, wsBinary, wsContinuation:
+			if len(message)+len(payload) > wsMaxMessageSize {
+				return nil, errors.New(errWebSocketProtocol)
+			}
+			message = append(message, payload...)
+			if fin {
+				return message, nil
+			}
+		default:
+			return nil, errors.New(errWebSocketProtocol)
+		}
+	}
+}
+
+// WriteMessage : Sends a text message
+func (ws *WebSocket) WriteMessage(message []byte) error {
+	return ws.writeFrame(wsText, message)
+}
+
+// Close : Closes the WebSocket, telling the server when it is still open
+func (ws *WebSocket) Close() error {
+	var err error
+	ws.closeOnce.Do(func() {
+		ws.writeFrame(wsClose, nil)
+		close(ws.closed)
+		err = ws.conn.Close()
+	})
+	return err
+}
+
+// readFrame reads one frame, unmasking it if the server masked it
+func (ws *WebSocket) readFrame() (bool, byte, []byte, error) {
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(ws.reader, header); err != nil {
+		return false, 0, nil, err
+	}
+	fin := header[0]&0x80 != 0
+	opcode := header[0] & 0x0f
+	masked := header[1]&0x80 != 0
+	length := uint64(header[1] & 0x7f)
+	switch length {
+	case 126:
+		extended := make([]byte, 2)
+		if _, err := io.ReadFull(ws.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(extended))
+	case 127:
+		extended := make([]byte, 8)
+		if _, err := io.ReadFull(ws.reader, extended); err != nil {
+			return false, 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(extended)
+	}
+	if length > wsMaxMessageSize {
+		return false, 0, nil, errors.New(errWebSocketProtocol)
+	}
+	var mask []byte
+	if masked {
+		mask = make([]byte, 4)
+		if _, err := io.ReadFull(ws.reader, mask); err != nil {
+			return false, 0, nil, err
+		}
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return false, 0, nil, err
+	}
+	if masked {
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+	}
+	return fin, opcode, payload, nil
+}
+
+// writeFrame sends a whole message in one frame, masked as clients must
+func (ws *WebSocket) writeFrame(opcode byte, payload []byte) error {
+	ws.writeMutex.Lock()
+	defer ws.writeMutex.Unlock()
+
+	frame := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		frame = append(frame, 0x80|byte(length))
+	case length <= 0xffff:
+		frame = append(frame, 0x80|126, 0, 0)
+		binary.BigEndian.PutUint16(frame[2:], uint16(length))
+	default:
+		frame = append(frame, 0x80|127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(frame[2:], uint64(length))
+	}
+	mask := make([]byte, 4)
+	if _, err := rand.Read(mask); err != nil {
+		return err
+	}
+	frame = append(frame, mask...)
+	for i, b := range payload {
+		frame = append(frame, b^mask[i%4])
+	}
+	_, err := ws.conn.Write(frame)
+	return err
+}
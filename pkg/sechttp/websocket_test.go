@@ -0,0 +1,120 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package sechttp
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// webSocketServer accepts WebSocket handshakes, passing each connection to serve
+func webSocketServer(serve func(conn net.Conn)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Upgrade") != "websocket" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conn, rw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n")
+		rw.WriteString("Sec-WebSocket-Accept: " + wsAccept(r.Header.Get("Sec-WebSocket-Key")) + "\r\n\r\n")
+		rw.Flush()
+		serve(conn)
+	}))
+}
+
+// serverFrame returns an unmasked frame, as servers send them
+func serverFrame(fin bool, opcode byte, payload string) []byte {
+	first := opcode
+	if fin {
+		first |= 0x80
+	}
+	return append([]byte{first, byte(len(payload))}, payload...)
+}
+
+func TestDialWebSocket(t *testing.T) {
+	connection := &connections.Connection{ID: "local"}
+
+	t.Run("reads messages, answers pings and sees the server closing", func(t *testing.T) {
+		received := make(chan string, 2)
+		server := webSocketServer(func(conn net.Conn) {
+			conn.Write(serverFrame(false, wsText, "hello "))
+			conn.Write(serverFrame(true, wsPing, "are you there"))
+			conn.Write(serverFrame(true, wsContinuation, "world"))
+			// The server reads the masked frames of the client
+			peer := newWebSocket(conn)
+			for i := 0; i < 2; i++ {
+				_, opcode, payload, err := peer.readFrame()
+				if err != nil {
+					return
+				}
+				received <- fmt.Sprintf("%d:%s", opcode, payload)
+			}
+			conn.Write(serverFrame(true, wsClose, ""))
+		})
+		defer server.Close()
+
+		ws, secErr := DialWebSocket(context.Background(), &http.Client{}, server.URL, connection)
+		if !assert.Nil(t, secErr) {
+			return
+		}
+		defer ws.Close()
+		message, err := ws.ReadMessage()
+		assert.Nil(t, err)
+		assert.Equal(t, "hello world", string(message))
+		assert.Nil(t, ws.WriteMessage([]byte("hi")))
+		assert.Equal(t, "10:are you there", <-received, "the ping should be answered with a pong")
+		assert.Equal(t, "1:hi", <-received)
+		_, err = ws.ReadMessage()
+		assert.Equal(t, io.EOF, err)
+	})
+
+	t.Run("fails when the server does not upgrade the connection", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer server.Close()
+
+		_, secErr := DialWebSocket(context.Background(), &http.Client{}, server.URL, connection)
+		if assert.NotNil(t, secErr) {
+			assert.Equal(t, errOpWebSocket, secErr.Op)
+		}
+	})
+
+	t.Run("cancelling the context closes the WebSocket", func(t *testing.T) {
+		server := webSocketServer(func(conn net.Conn) {
+			io.Copy(ioutil.Discard, conn)
+		})
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ws, secErr := DialWebSocket(ctx, &http.Client{}, server.URL, connection)
+		if !assert.Nil(t, secErr) {
+			return
+		}
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err := ws.ReadMessage()
+		assert.NotNil(t, err)
+	})
+}
@@ -12,13 +12,17 @@
 package security
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
 )
@@ -28,6 +32,7 @@ type AuthToken struct {
 	AccessToken     string `json:"access_token"`
 	ExpiresIn       int    `json:"expires_in"`
 	RefreshToken    string `json:"refresh_token"`
+	IDToken         string `json:"id_token,omitempty"`
 	TokenType       string `json:"token_type"`
 	NotBeforePolicy int    `json:"not-before-policy"`
 	SessionState    string `json:"session_state"`
@@ -106,17 +111,30 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 		client = connectionClient
 	}
 
+	// Connections to another OpenID Connect provider have no realm, and find their token endpoint by discovery
+	useProvider := connection != nil && connection.Provider == connections.ProviderOIDC && cliHostname == "" && connectionRealm == ""
+
 	// Pre-flight check
 
-	if hostname == "" || realm == "" || username == "" || password == "" || client == "" {
-		err := errors.New(textInvalidOptions)
+	if hostname == "" || (realm == "" && !useProvider) || username == "" || password == "" || client == "" {
+		err := i18n.Errorf(msgInvalidOptions, textInvalidOptions)
 		return nil, &SecError{errOpCLICommand, err, err.Error()}
 	}
 
 	// build REST request
-	url := hostname + "/auth/realms/" + realm + "/protocol/openid-connect/token"
-	payload := strings.NewReader("grant_type=password&client_id=" + client + "&username=" + username + "&password=" + password)
-	req, err := http.NewRequest("POST", url, payload)
+	tokenURL := hostname + "/auth/realms/" + realm + "/protocol/openid-connect/token"
+	scope := ""
+	if useProvider {
+		provider := ProviderForConnection(connection)
+		endpoints, secErr := provider.Endpoints(httpClient)
+		if secErr != nil {
+			return nil, secErr
+		}
+		tokenURL = endpoints.TokenEndpoint
+		scope = "&scope=" + url.QueryEscape(provider.Scope())
+	}
+	payload := strings.NewReader("grant_type=password&client_id=" + client + "&username=" + username + "&password=" + password + scope)
+	req, err := http.NewRequest("POST", tokenURL, payload)
 	if err != nil {
 		return nil, &SecError{errOpConnection, err, err.Error()}
 	}
@@ -144,7 +162,7 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 		kcError := errors.New(string(keycloakAPIError.Error))
 		return nil, &SecError{errOpResponse, kcError, kcError.Error()}
 	case httpCode == http.StatusServiceUnavailable:
-		txtError := errors.New(textAuthIsDown)
+		txtError := i18n.Errorf(msgAuthIsDown, textAuthIsDown)
 		return nil, &SecError{errOpResponse, txtError, txtError.Error()}
 	case httpCode != http.StatusOK:
 		err = errors.New(string(body))
@@ -155,7 +173,7 @@ func SecAuthenticate(httpClient utils.HTTPClient, c *cli.Context, connectionReal
 	authToken := AuthToken{}
 	err = json.Unmarshal([]byte(body), &authToken)
 	if err != nil {
-		return nil, &SecError{errOpResponseFormat, err, textUnableToParse}
+		return nil, &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
 	}
 
 	// store access and refresh tokens in keyring if a connection is known
@@ -206,57 +224,30 @@ func SecRefreshTokens(httpClient utils.HTTPClient, c *cli.Context) (*AuthToken,
 
 // SecRefreshAccessToken : Obtain an access token using a refresh token
 func SecRefreshAccessToken(httpClient utils.HTTPClient, connection *connections.Connection, refreshToken string) (*AuthToken, *SecError) {
-
-	// build REST request
-	url := connection.AuthURL + "/auth/realms/" + connection.Realm + "/protocol/openid-connect/token"
-
-	payload := strings.NewReader("grant_type=refresh_token&client_id=" + connection.ClientID + "&refresh_token=" + refreshToken)
-	req, err := http.NewRequest("POST", url, payload)
-	if err != nil {
-		return nil, &SecError{errOpConnection, err, err.Error()}
+	payload := url.Values{
+		"grant_type":    {"refresh_token"},
+		"client_id":     {connection.ClientID},
+		"refresh_token": {refreshToken},
 	}
-	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
-	req.Header.Add("Cache-Control", "no-cache")
-	req.Header.Add("cache-control", "no-cache")
+	return requestConnectionTokens(httpClient, connection, payload)
+}
 
-	// send request
-	res, err := httpClient.Do(req)
-	if err != nil {
-		return nil, &SecError{errOpConnection, err, err.Error()}
+// TokenExpiry : Read the expiry time from the claims of an access token. The signature is not verified, the
+// Gatekeeper does that, so the result is only suitable for deciding when to refresh the token.
+func TokenExpiry(token string) (time.Time, bool) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, false
 	}
-	defer res.Body.Close()
-	body, err := ioutil.ReadAll(res.Body)
-
-	// Handle special case http status codes
-	switch httpCode := res.StatusCode; {
-	case httpCode == http.StatusBadRequest, httpCode == http.StatusUnauthorized:
-		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
-		kcError := errors.New(string(keycloakAPIError.ErrorDescription))
-		return nil, &SecError{keycloakAPIError.Error, kcError, kcError.Error()}
-	case httpCode != http.StatusOK:
-		err = errors.New(string(body))
-		return nil, &SecError{errOpResponse, err, err.Error()}
-	}
-
-	// Parse and return AuthToken
-	authToken := AuthToken{}
-	err = json.Unmarshal([]byte(body), &authToken)
-
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
 	if err != nil {
-		// re-save the access and refresh token
-		secErr := SecKeyUpdate(connection.ID, "access_token", authToken.AccessToken)
-		if secErr != nil {
-			return &authToken, secErr
-		}
-		secErr = SecKeyUpdate(connection.ID, "refresh_token", authToken.RefreshToken)
-
-		if secErr != nil {
-			return &authToken, secErr
-		}
-
-		respErr := errors.New(string(body))
-		return nil, &SecError{errOpResponse, respErr, respErr.Error()}
+		return time.Time{}, false
 	}
-
-	return &authToken, nil
+	claims := struct {
+		Expiry int64 `json:"exp"`
+	}{}
+	if json.Unmarshal(payload, &claims) != nil || claims.Expiry == 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(claims.Expiry, 0), true
 }
@@ -13,6 +13,7 @@ package security
 
 import (
 	"bytes"
+	"encoding/base64"
 	"encoding/json"
 	"flag"
 	"io/ioutil"
@@ -124,3 +125,18 @@ func Test_Authenticate(t *testing.T) {
 		keyring.Delete(strings.ToLower(KeyringServiceName+"."+testConnection), "refresh_token")
 	})
 }
+
+func Test_TokenExpiry(t *testing.T) {
+	t.Run("Reads the expiry from the token claims", func(t *testing.T) {
+		claims := base64.RawURLEncoding.EncodeToString([]byte(`{"exp":1577836800}`))
+		expiry, ok := TokenExpiry("header." + claims + ".signature")
+		assert.True(t, ok)
+		assert.Equal(t, int64(1577836800), expiry.Unix())
+	})
+	t.Run("Ignores values which are not tokens", func(t *testing.T) {
+		_, ok := TokenExpiry("")
+		assert.False(t, ok)
+		_, ok = TokenExpiry("a.b.c")
+		assert.False(t, ok)
+	})
+}
@@ -16,8 +16,11 @@ import (
 	"errors"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
 )
 
@@ -55,20 +58,24 @@ func SecClientCreate(c *cli.Context) *SecError {
 
 	// build the payload (JSON)
 	type PayloadClient struct {
-		DirectAccessGrantsEnabled bool      `json:"directAccessGrantsEnabled"`
-		PublicClient              bool      `json:"publicClient"`
-		ClientID                  string    `json:"clientId"`
-		Name                      string    `json:"name"`
-		RedirectUris              [1]string `json:"redirectUris"`
+		DirectAccessGrantsEnabled bool              `json:"directAccessGrantsEnabled"`
+		PublicClient              bool              `json:"publicClient"`
+		ClientID                  string            `json:"clientId"`
+		Name                      string            `json:"name"`
+		RedirectUris              []string          `json:"redirectUris"`
+		Attributes                map[string]string `json:"attributes"`
 	}
 	tempClient := &PayloadClient{
 		DirectAccessGrantsEnabled: true,
 		PublicClient:              true,
 		ClientID:                  newclient,
 		Name:                      newclient,
+		// allow seclogin --device-flow on machines without a browser
+		Attributes: map[string]string{"oauth2.device.authorization.grant.enabled": "true"},
 	}
 
-	tempClient.RedirectUris = [...]string{redirectURL}
+	// the loopback redirect receives the authorization code for seclogin --browser
+	tempClient.RedirectUris = []string{redirectURL, "http://127.0.0.1:*"}
 	jsonClient, err := json.Marshal(tempClient)
 	payload := strings.NewReader(string(jsonClient))
 	req, err := http.NewRequest("POST", url, payload)
@@ -210,6 +217,32 @@ func SecClientGetSecret(c *cli.Context) (*RegisteredClientSecret, *SecError) {
 	return &registeredClientSecret, nil
 }
 
+// SecClientRegenerateSecret : Replace the secret of a confidential client with a new one generated by Keycloak.
+// The old secret stops working straight away, so anything using it must be given the new secret returned.
+func SecClientRegenerateSecret(httpClient utils.HTTPClient, c *cli.Context) (*RegisteredClientSecret, *SecError) {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return nil, secErr
+	}
+
+	registeredClients := []RegisteredClient{}
+	secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/clients?clientId="+url.QueryEscape(strings.TrimSpace(c.String("clientid"))), accesstoken, nil, &registeredClients)
+	if secErr != nil {
+		return nil, secErr
+	}
+	if len(registeredClients) == 0 {
+		err := i18n.Errorf(msgClientNotFound, textClientNotFound)
+		return nil, &SecError{errOpNotFound, err, err.Error()}
+	}
+
+	registeredClientSecret := RegisteredClientSecret{}
+	secErr = keycloakAdminRequest(httpClient, "POST", realmURL+"/clients/"+registeredClients[0].ID+"/client-secret", accesstoken, nil, &registeredClientSecret)
+	if secErr != nil {
+		return nil, secErr
+	}
+	return &registeredClientSecret, nil
+}
+
 // SecClientAppendURL : Append an additional url to the whitelist
 func SecClientAppendURL(c *cli.Context, gatekeeperURL string) *SecError {
 
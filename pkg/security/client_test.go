@@ -0,0 +1,52 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClientRegenerateSecret(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer admintoken", r.Header.Get("Authorization"))
+		switch r.Method + " " + r.URL.Path {
+		case "GET /auth/admin/realms/codewind/clients":
+			if r.URL.Query().Get("clientId") == "codewind-backend" {
+				w.Write([]byte(`[{"id": "c1", "clientId": "codewind-backend"}]`))
+				return
+			}
+			w.Write([]byte(`[]`))
+		case "POST /auth/admin/realms/codewind/clients/c1/client-secret":
+			w.Write([]byte(`{"type": "secret", "value": "newsecret"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("success case - returns the new secret", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "clientid": "codewind-backend"})
+		secret, secErr := SecClientRegenerateSecret(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Equal(t, "newsecret", secret.Secret)
+	})
+
+	t.Run("fail case - client not found", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "clientid": "unknown"})
+		_, secErr := SecClientRegenerateSecret(http.DefaultClient, c)
+		assert.Equal(t, textClientNotFound, secErr.Desc)
+	})
+}
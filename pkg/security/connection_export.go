@@ -0,0 +1,239 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"golang.org/x/crypto/scrypt"
+)
+
+// ConnectionExportVersion : Version of the connection export file format
+const ConnectionExportVersion = 1
+
+// Lengths of the salt and key used to encrypt exported credentials
+const (
+	exportSaltLength = 16
+	exportKeyLength  = 32
+)
+
+// ConnectionExport : Connection definitions, and optionally their credentials, which can be imported elsewhere
+type ConnectionExport struct {
+	SchemaVersion int                  `json:"schemaversion"`
+	Connections   []ExportedConnection `json:"connections"`
+}
+
+// ExportedConnection : A connection and its password, and the credentials of its proxy, encrypted with the export
+// passphrase
+type ExportedConnection struct {
+	connections.Connection
+	Credentials      string `json:"credentials,omitempty"`
+	ProxyCredentials string `json:"proxycredentials,omitempty"`
+}
+
+// SecConnectionExport : Export the given connections, or every remote connection when none are given.
+// Passwords are only read from the keyring and included when a passphrase is supplied to encrypt them.
+func SecConnectionExport(connectionIDs []string, passphrase string) (*ConnectionExport, *SecError) {
+	allConnections, conErr := connections.GetAllConnections()
+	if conErr != nil {
+		return nil, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+	}
+
+	selected := []connections.Connection{}
+	if len(connectionIDs) == 0 {
+		for _, connection := range allConnections {
+			if !strings.EqualFold(connection.ID, "local") {
+				selected = append(selected, connection)
+			}
+		}
+	} else {
+		for _, connectionID := range connectionIDs {
+			connection, conErr := connections.GetConnectionByID(strings.TrimSpace(connectionID))
+			if conErr != nil {
+				return nil, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+			}
+			if strings.EqualFold(connection.ID, "local") {
+				err := errors.New("Local is a required connection that cannot be exported")
+				return nil, &SecError{errOpCLICommand, err, err.Error()}
+			}
+			selected = append(selected, *connection)
+		}
+	}
+
+	export := ConnectionExport{SchemaVersion: ConnectionExportVersion, Connections: []ExportedConnection{}}
+	for _, connection := range selected {
+		// Proxies saved by earlier versions have their credentials in the URL, which are never exported unencrypted
+		proxyURL, proxyCredentials := connections.SplitProxyURL(connection.ProxyURL)
+		connection.ProxyURL = proxyURL
+		if proxyCredentials != "" {
+			connection.ProxyAuth = true
+		} else if passphrase != "" && connection.ProxyAuth {
+			secret, secErr := GetSecretFromKeyring(connection.ID, connections.ProxyCredentialsSecret)
+			if secErr != nil && !IsSecretNotFoundError(secErr) {
+				return nil, secErr
+			}
+			proxyCredentials = secret
+		}
+		exported := ExportedConnection{Connection: connection}
+		if passphrase != "" && proxyCredentials != "" {
+			encrypted, secErr := encryptCredentials(proxyCredentials, passphrase)
+			if secErr != nil {
+				return nil, secErr
+			}
+			exported.ProxyCredentials = encrypted
+		}
+		if passphrase != "" && connection.Username != "" {
+			password, secErr := GetSecretFromKeyring(connection.ID, strings.ToLower(connection.Username))
+			if secErr != nil && !IsSecretNotFoundError(secErr) {
+				return nil, secErr
+			}
+			if password != "" {
+				credentials, secErr := encryptCredentials(password, passphrase)
+				if secErr != nil {
+					return nil, secErr
+				}
+				exported.Credentials = credentials
+			}
+		}
+		export.Connections = append(export.Connections, exported)
+	}
+	return &export, nil
+}
+
+// SecConnectionImport : Add the connections from an export, storing any included passwords in the keyring.
+// Every password is decrypted before any connection is added so that a wrong passphrase leaves the config unchanged.
+func SecConnectionImport(export *ConnectionExport, passphrase string) ([]connections.Connection, *SecError) {
+	if export.SchemaVersion < 1 || export.SchemaVersion > ConnectionExportVersion {
+		err := i18n.Errorf(msgBadConExport, textBadConExport)
+		return nil, &SecError{errOpConConfig, err, err.Error()}
+	}
+
+	passwords := make([]string, len(export.Connections))
+	proxyCredentials := make([]string, len(export.Connections))
+	for i, exported := range export.Connections {
+		_, proxyCredentials[i] = connections.SplitProxyURL(exported.ProxyURL)
+		if exported.Credentials == "" && exported.ProxyCredentials == "" {
+			continue
+		}
+		if passphrase == "" {
+			err := errors.New("A passphrase is required to import the credentials of connection " + exported.ID)
+			return nil, &SecError{errOpCLICommand, err, err.Error()}
+		}
+		if exported.Credentials != "" {
+			password, secErr := decryptCredentials(exported.Credentials, passphrase)
+			if secErr != nil {
+				return nil, secErr
+			}
+			passwords[i] = password
+		}
+		if exported.ProxyCredentials != "" {
+			credentials, secErr := decryptCredentials(exported.ProxyCredentials, passphrase)
+			if secErr != nil {
+				return nil, secErr
+			}
+			proxyCredentials[i] = credentials
+		}
+	}
+
+	imported := []connections.Connection{}
+	for i, exported := range export.Connections {
+		conErr := connections.ImportConnection(exported.Connection)
+		if conErr != nil {
+			return imported, &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+		}
+		if passwords[i] != "" {
+			secErr := StoreSecretInKeyring(strings.ToUpper(exported.ID), strings.ToLower(exported.Username), passwords[i])
+			if secErr != nil {
+				return imported, secErr
+			}
+		}
+		if proxyCredentials[i] != "" {
+			secErr := StoreSecretInKeyring(strings.ToUpper(exported.ID), connections.ProxyCredentialsSecret, proxyCredentials[i])
+			if secErr != nil {
+				return imported, secErr
+			}
+		}
+		exported.Connection.ProxyURL, _ = connections.SplitProxyURL(exported.ProxyURL)
+		imported = append(imported, exported.Connection)
+	}
+	return imported, nil
+}
+
+// encryptCredentials encrypts a password with AES-GCM using a key derived from the passphrase, returning the
+// salt, nonce and ciphertext base64 encoded
+func encryptCredentials(password string, passphrase string) (string, *SecError) {
+	salt := make([]byte, exportSaltLength)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return "", &SecError{errOpKeyring, err, err.Error()}
+	}
+	gcm, secErr := exportCipher(passphrase, salt)
+	if secErr != nil {
+		return "", secErr
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", &SecError{errOpKeyring, err, err.Error()}
+	}
+	sealed := gcm.Seal(nil, nonce, []byte(password), nil)
+	payload := append(append(salt, nonce...), sealed...)
+	return base64.StdEncoding.EncodeToString(payload), nil
+}
+
+// decryptCredentials reverses encryptCredentials, failing if the passphrase is wrong or the credentials were altered
+func decryptCredentials(credentials string, passphrase string) (string, *SecError) {
+	payload, err := base64.StdEncoding.DecodeString(credentials)
+	if err != nil || len(payload) < exportSaltLength {
+		err = i18n.Errorf(msgBadConExport, textBadConExport)
+		return "", &SecError{errOpConConfig, err, err.Error()}
+	}
+	salt := payload[:exportSaltLength]
+	gcm, secErr := exportCipher(passphrase, salt)
+	if secErr != nil {
+		return "", secErr
+	}
+	if len(payload) < exportSaltLength+gcm.NonceSize() {
+		err = i18n.Errorf(msgBadConExport, textBadConExport)
+		return "", &SecError{errOpConConfig, err, err.Error()}
+	}
+	nonce := payload[exportSaltLength : exportSaltLength+gcm.NonceSize()]
+	password, err := gcm.Open(nil, nonce, payload[exportSaltLength+gcm.NonceSize():], nil)
+	if err != nil {
+		err = i18n.Errorf(msgBadPassphrase, textBadPassphrase)
+		return "", &SecError{errOpPassword, err, err.Error()}
+	}
+	return string(password), nil
+}
+
+// exportCipher derives an AES-256 key from the passphrase and salt with scrypt
+func exportCipher(passphrase string, salt []byte) (cipher.AEAD, *SecError) {
+	key, err := scrypt.Key([]byte(passphrase), salt, 32768, 8, 1, exportKeyLength)
+	if err != nil {
+		return nil, &SecError{errOpKeyring, err, err.Error()}
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, &SecError{errOpKeyring, err, err.Error()}
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, &SecError{errOpKeyring, err, err.Error()}
+	}
+	return gcm, nil
+}
@@ -0,0 +1,92 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ExportCredentials(t *testing.T) {
+	t.Run("Credentials decrypt with the passphrase used to encrypt them", func(t *testing.T) {
+		credentials, secErr := encryptCredentials(testPassword, "export-passphrase")
+		assert.Nil(t, secErr)
+		assert.NotContains(t, credentials, testPassword)
+
+		password, secErr := decryptCredentials(credentials, "export-passphrase")
+		assert.Nil(t, secErr)
+		assert.Equal(t, testPassword, password)
+	})
+
+	t.Run("Encrypting the same password twice gives different credentials", func(t *testing.T) {
+		first, _ := encryptCredentials(testPassword, "export-passphrase")
+		second, _ := encryptCredentials(testPassword, "export-passphrase")
+		assert.NotEqual(t, first, second)
+	})
+
+	t.Run("Credentials do not decrypt with a different passphrase", func(t *testing.T) {
+		credentials, _ := encryptCredentials(testPassword, "export-passphrase")
+		password, secErr := decryptCredentials(credentials, "wrong-passphrase")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, errOpPassword, secErr.Op)
+		assert.Equal(t, "", password)
+	})
+
+	t.Run("Malformed credentials are rejected", func(t *testing.T) {
+		_, secErr := decryptCredentials("not-base64!", "export-passphrase")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, errOpConConfig, secErr.Op)
+
+		_, secErr = decryptCredentials("c2hvcnQ=", "export-passphrase")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, errOpConConfig, secErr.Op)
+	})
+}
+
+func Test_ConnectionImport(t *testing.T) {
+	t.Run("An export with an unknown schema version is rejected", func(t *testing.T) {
+		export := ConnectionExport{SchemaVersion: ConnectionExportVersion + 1}
+		imported, secErr := SecConnectionImport(&export, "")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, textBadConExport, secErr.Desc)
+		assert.Nil(t, imported)
+	})
+
+	t.Run("Credentials cannot be imported without a passphrase", func(t *testing.T) {
+		credentials, _ := encryptCredentials(testPassword, "export-passphrase")
+		export := ConnectionExport{
+			SchemaVersion: ConnectionExportVersion,
+			Connections: []ExportedConnection{
+				{Connection: connections.Connection{ID: "EXPORTED", Username: testUsername}, Credentials: credentials},
+			},
+		}
+		_, secErr := SecConnectionImport(&export, "")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, errOpCLICommand, secErr.Op)
+	})
+
+	t.Run("A wrong passphrase fails before any connection is added", func(t *testing.T) {
+		credentials, _ := encryptCredentials(testPassword, "export-passphrase")
+		export := ConnectionExport{
+			SchemaVersion: ConnectionExportVersion,
+			Connections: []ExportedConnection{
+				{Connection: connections.Connection{ID: "EXPORTED", Username: testUsername}, Credentials: credentials},
+			},
+		}
+		imported, secErr := SecConnectionImport(&export, "wrong-passphrase")
+		assert.NotNil(t, secErr)
+		assert.Equal(t, errOpPassword, secErr.Op)
+		assert.Nil(t, imported)
+	})
+}
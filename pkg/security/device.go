@@ -0,0 +1,147 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// DeviceAuthorization : The codes Keycloak issues to start a device authorization login
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceGrantType identifies a device code token request
+const deviceGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// Errors returned by Keycloak while a device authorization is waiting for the user
+const (
+	deviceAuthorizationPending = "authorization_pending"
+	deviceSlowDown             = "slow_down"
+)
+
+// devicePollSleep waits between token requests, and is replaced in tests
+var devicePollSleep = time.Sleep
+
+// SecDeviceAuthorize : Start a device authorization login for the connection, returning the code the user enters
+// at the verification URL
+func SecDeviceAuthorize(httpClient utils.HTTPClient, connection *connections.Connection) (*DeviceAuthorization, *SecError) {
+	provider := ProviderForConnection(connection)
+	endpoints, secErr := provider.Endpoints(httpClient)
+	if secErr != nil {
+		return nil, secErr
+	}
+	if endpoints.DeviceAuthorizationEndpoint == "" {
+		err := i18n.Errorf(msgNoDeviceFlow, textNoDeviceFlow)
+		return nil, &SecError{errOpCLICommand, err, err.Error()}
+	}
+	payload := url.Values{"client_id": {connection.ClientID}, "scope": {provider.Scope()}}
+	body, secErr := postTokenForm(httpClient, endpoints.DeviceAuthorizationEndpoint, payload)
+	if secErr != nil {
+		return nil, secErr
+	}
+	authorization := DeviceAuthorization{}
+	err := json.Unmarshal(body, &authorization)
+	if err != nil || authorization.DeviceCode == "" {
+		return nil, &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
+	}
+	if authorization.Interval < 1 {
+		authorization.Interval = 5
+	}
+	return &authorization, nil
+}
+
+// SecDevicePollToken : Poll the identity provider until the user approves or denies the device authorization, or it
+// expires. The tokens issued are saved to the keyring for the connection.
+func SecDevicePollToken(httpClient utils.HTTPClient, connection *connections.Connection, authorization *DeviceAuthorization) (*AuthToken, *SecError) {
+	payload := url.Values{
+		"grant_type":  {deviceGrantType},
+		"client_id":   {connection.ClientID},
+		"device_code": {authorization.DeviceCode},
+	}
+	interval := time.Duration(authorization.Interval) * time.Second
+	deadline := time.Now().Add(time.Duration(authorization.ExpiresIn) * time.Second)
+
+	for {
+		devicePollSleep(interval)
+		authToken, secErr := requestConnectionTokens(httpClient, connection, payload)
+		if secErr != nil && authToken == nil {
+			switch secErr.Op {
+			case deviceAuthorizationPending:
+			case deviceSlowDown:
+				interval += 5 * time.Second
+			default:
+				return nil, secErr
+			}
+			if authorization.ExpiresIn > 0 && time.Now().After(deadline) {
+				err := i18n.Errorf(msgDeviceExpired, textDeviceExpired)
+				return nil, &SecError{errOpCLICommand, err, err.Error()}
+			}
+			continue
+		}
+		return authToken, secErr
+	}
+}
+
+// postTokenForm sends a form to an OpenID Connect endpoint, returning the response body or the error from the
+// identity provider, whose Op is the OAuth error code
+func postTokenForm(httpClient utils.HTTPClient, endpoint string, payload url.Values) ([]byte, *SecError) {
+	req, err := http.NewRequest("POST", endpoint, strings.NewReader(payload.Encode()))
+	if err != nil {
+		return nil, &SecError{errOpConnection, err, err.Error()}
+	}
+	req.Header.Add("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Add("Accept", "application/json")
+	req.Header.Add("Cache-Control", "no-cache")
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return nil, &SecError{errOpConnection, err, err.Error()}
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, &SecError{errOpResponse, err, err.Error()}
+	}
+
+	switch httpCode := res.StatusCode; {
+	case httpCode == http.StatusBadRequest, httpCode == http.StatusUnauthorized, httpCode == http.StatusForbidden:
+		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
+		kcError := errors.New(keycloakAPIError.ErrorDescription)
+		if keycloakAPIError.ErrorDescription == "" {
+			kcError = errors.New(keycloakAPIError.Error)
+		}
+		return nil, &SecError{keycloakAPIError.Error, kcError, kcError.Error()}
+	case httpCode == http.StatusServiceUnavailable:
+		txtError := i18n.Errorf(msgAuthIsDown, textAuthIsDown)
+		return nil, &SecError{errOpResponse, txtError, txtError.Error()}
+	case httpCode < 200 || httpCode > 299:
+		err = errors.New(string(body))
+		return nil, &SecError{errOpResponse, err, err.Error()}
+	}
+	return body, nil
+}
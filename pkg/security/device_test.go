@@ -0,0 +1,106 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+// mockResponse : A status code and body returned by ClientMockSequence
+type mockResponse struct {
+	StatusCode int
+	Body       string
+}
+
+// ClientMockSequence : Client Mock returning each response in turn, and recording the requests made
+type ClientMockSequence struct {
+	Responses []mockResponse
+	Requests  []*http.Request
+}
+
+// Do : perform do function
+func (c *ClientMockSequence) Do(req *http.Request) (*http.Response, error) {
+	response := c.Responses[len(c.Requests)]
+	c.Requests = append(c.Requests, req)
+	return &http.Response{
+		StatusCode: response.StatusCode,
+		Body:       ioutil.NopCloser(bytes.NewBufferString(response.Body)),
+	}, nil
+}
+
+func Test_DeviceLogin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping testing in short mode")
+	}
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	os.Remove(GetPathToInsecureKeyring())
+	defer os.Remove(GetPathToInsecureKeyring())
+
+	waits := []time.Duration{}
+	devicePollSleep = func(d time.Duration) { waits = append(waits, d) }
+	defer func() { devicePollSleep = time.Sleep }()
+
+	connection := connections.Connection{ID: testConnection, AuthURL: "https://auth.remote", Realm: "codewind", ClientID: "codewind-cli"}
+
+	t.Run("Returns the user code and verification URL", func(t *testing.T) {
+		mockClient := &ClientMockSequence{Responses: []mockResponse{
+			{http.StatusOK, `{"device_code":"device","user_code":"ABCD-EFGH","verification_uri":"https://auth.remote/device","expires_in":600}`},
+		}}
+		authorization, secErr := SecDeviceAuthorize(mockClient, &connection)
+		assert.Nil(t, secErr)
+		assert.Equal(t, "ABCD-EFGH", authorization.UserCode)
+		assert.Equal(t, "https://auth.remote/device", authorization.VerificationURI)
+		assert.Equal(t, 5, authorization.Interval)
+		assert.Equal(t, "https://auth.remote/auth/realms/codewind/protocol/openid-connect/auth/device", mockClient.Requests[0].URL.String())
+	})
+
+	t.Run("Polls until the login is approved, slowing down when asked, and saves the tokens", func(t *testing.T) {
+		waits = []time.Duration{}
+		mockClient := &ClientMockSequence{Responses: []mockResponse{
+			{http.StatusBadRequest, `{"error":"authorization_pending"}`},
+			{http.StatusBadRequest, `{"error":"slow_down"}`},
+			{http.StatusOK, `{"access_token":"device_access","refresh_token":"device_refresh"}`},
+		}}
+		authorization := DeviceAuthorization{DeviceCode: "device", ExpiresIn: 600, Interval: 1}
+		tokens, secErr := SecDevicePollToken(mockClient, &connection, &authorization)
+		assert.Nil(t, secErr)
+		assert.Equal(t, "device_access", tokens.AccessToken)
+		assert.Equal(t, []time.Duration{time.Second, time.Second, 6 * time.Second}, waits)
+
+		accessToken, _ := GetSecretFromKeyring(testConnection, "access_token")
+		assert.Equal(t, "device_access", accessToken)
+		refreshToken, _ := GetSecretFromKeyring(testConnection, "refresh_token")
+		assert.Equal(t, "device_refresh", refreshToken)
+	})
+
+	t.Run("Stops when the login is denied", func(t *testing.T) {
+		mockClient := &ClientMockSequence{Responses: []mockResponse{
+			{http.StatusBadRequest, `{"error":"access_denied","error_description":"The end user denied the authorization request"}`},
+		}}
+		authorization := DeviceAuthorization{DeviceCode: "device", ExpiresIn: 600, Interval: 1}
+		tokens, secErr := SecDevicePollToken(mockClient, &connection, &authorization)
+		assert.Nil(t, tokens)
+		assert.Equal(t, "access_denied", secErr.Op)
+		assert.Len(t, mockClient.Requests, 1)
+	})
+}
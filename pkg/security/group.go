@@ -0,0 +1,139 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// Group : A Keycloak group. Users in a group are granted the roles mapped to it.
+type Group struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Path string `json:"path"`
+}
+
+// SecGroupCreate : Create a new group in a realm
+func SecGroupCreate(httpClient utils.HTTPClient, c *cli.Context) *SecError {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	payload, _ := json.Marshal(Group{Name: strings.TrimSpace(c.String("group"))})
+	return keycloakAdminRequest(httpClient, "POST", realmURL+"/groups", accesstoken, bytes.NewReader(payload), nil)
+}
+
+// SecGroupList : List the top level groups of a realm
+func SecGroupList(httpClient utils.HTTPClient, c *cli.Context) ([]Group, *SecError) {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return nil, secErr
+	}
+	groups := []Group{}
+	secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/groups", accesstoken, nil, &groups)
+	if secErr != nil {
+		return nil, secErr
+	}
+	return groups, nil
+}
+
+// SecGroupAddUser : Add an existing user to an existing group
+func SecGroupAddUser(httpClient utils.HTTPClient, c *cli.Context) *SecError {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	group, secErr := getGroupByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("group")))
+	if secErr != nil {
+		return secErr
+	}
+	user, secErr := getUserByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("name")))
+	if secErr != nil {
+		return secErr
+	}
+	return keycloakAdminRequest(httpClient, "PUT", realmURL+"/users/"+user.ID+"/groups/"+group.ID, accesstoken, nil, nil)
+}
+
+// SecGroupRemoveUser : Remove a user from a group
+func SecGroupRemoveUser(httpClient utils.HTTPClient, c *cli.Context) *SecError {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	group, secErr := getGroupByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("group")))
+	if secErr != nil {
+		return secErr
+	}
+	user, secErr := getUserByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("name")))
+	if secErr != nil {
+		return secErr
+	}
+	return keycloakAdminRequest(httpClient, "DELETE", realmURL+"/users/"+user.ID+"/groups/"+group.ID, accesstoken, nil, nil)
+}
+
+// SecGroupAddRole : Map an existing realm role to a group, granting it to every member
+func SecGroupAddRole(httpClient utils.HTTPClient, c *cli.Context) *SecError {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	group, secErr := getGroupByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("group")))
+	if secErr != nil {
+		return secErr
+	}
+	role := Role{}
+	secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/roles/"+url.PathEscape(strings.TrimSpace(c.String("role"))), accesstoken, nil, &role)
+	if secErr != nil {
+		return secErr
+	}
+	payload, _ := json.Marshal([]Role{role})
+	return keycloakAdminRequest(httpClient, "POST", realmURL+"/groups/"+group.ID+"/role-mappings/realm", accesstoken, bytes.NewReader(payload), nil)
+}
+
+// getGroupByName returns the top level group with the given name
+func getGroupByName(httpClient utils.HTTPClient, realmURL string, accesstoken string, groupName string) (*Group, *SecError) {
+	groups := []Group{}
+	secErr := keycloakAdminRequest(httpClient, "GET", realmURL+"/groups?search="+url.QueryEscape(groupName), accesstoken, nil, &groups)
+	if secErr != nil {
+		return nil, secErr
+	}
+	for _, group := range groups {
+		if group.Name == groupName {
+			return &group, nil
+		}
+	}
+	err := i18n.Errorf(msgGroupNotFound, textGroupNotFound)
+	return nil, &SecError{errOpNotFound, err, err.Error()}
+}
+
+// getUserByName returns the user with exactly the given username, as Keycloak searches usernames by prefix
+func getUserByName(httpClient utils.HTTPClient, realmURL string, accesstoken string, username string) (*RegisteredUser, *SecError) {
+	users := []RegisteredUser{}
+	secErr := keycloakAdminRequest(httpClient, "GET", realmURL+"/users?username="+url.QueryEscape(username), accesstoken, nil, &users)
+	if secErr != nil {
+		return nil, secErr
+	}
+	for _, user := range users {
+		if strings.EqualFold(user.Username, username) {
+			return &user, nil
+		}
+	}
+	err := i18n.Errorf(msgUserNotFound, textUserNotFound)
+	return nil, &SecError{errOpNotFound, err, err.Error()}
+}
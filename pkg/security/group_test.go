@@ -0,0 +1,75 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Groups(t *testing.T) {
+	requests := map[string]string{}
+	responses := map[string]string{
+		"GET /auth/admin/realms/codewind/groups":                  `[{"id": "g1", "name": "codewind-abc123-viewers", "path": "/codewind-abc123-viewers"}]`,
+		"GET /auth/admin/realms/codewind/users":                   `[{"id": "u1", "username": "developer"}]`,
+		"GET /auth/admin/realms/codewind/roles/codewind-readonly": `{"id": "r1", "name": "codewind-readonly"}`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer admintoken", r.Header.Get("Authorization"))
+		body, _ := ioutil.ReadAll(r.Body)
+		requests[r.Method+" "+r.URL.Path] = string(body)
+		if response, found := responses[r.Method+" "+r.URL.Path]; found {
+			w.Write([]byte(response))
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	t.Run("success case - lists groups", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken"})
+		groups, secErr := SecGroupList(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Equal(t, []Group{{ID: "g1", Name: "codewind-abc123-viewers", Path: "/codewind-abc123-viewers"}}, groups)
+	})
+
+	t.Run("success case - creates a group", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "group": "codewind-abc123-admins"})
+		secErr := SecGroupCreate(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Contains(t, requests["POST /auth/admin/realms/codewind/groups"], `"name":"codewind-abc123-admins"`)
+	})
+
+	t.Run("success case - adds a user to a group", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "group": "codewind-abc123-viewers", "name": "developer"})
+		secErr := SecGroupAddUser(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Contains(t, requests, "PUT /auth/admin/realms/codewind/users/u1/groups/g1")
+	})
+
+	t.Run("success case - maps a role to a group", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "group": "codewind-abc123-viewers", "role": "codewind-readonly"})
+		secErr := SecGroupAddRole(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Contains(t, requests["POST /auth/admin/realms/codewind/groups/g1/role-mappings/realm"], `"id":"r1"`)
+	})
+
+	t.Run("fail case - group not found", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "group": "codewind-abc123", "name": "developer"})
+		secErr := SecGroupAddUser(http.DefaultClient, c)
+		assert.Equal(t, textGroupNotFound, secErr.Desc)
+	})
+}
@@ -15,7 +15,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
@@ -23,6 +22,7 @@ import (
 
 	"github.com/eclipse/codewind-installer/pkg/connections"
 	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 	"github.com/zalando/go-keyring"
 )
 
@@ -78,62 +78,86 @@ func SecKeyGetSecret(connectionID, username string) (string, *SecError) {
 	return secret, nil
 }
 
-// StoreSecretInKeyring stores the secret in either the system keyring or our insecure keyring.
-func StoreSecretInKeyring(connectionID, uName, pass string) *SecError {
-	service := connectionIDToService(connectionID)
-	if globals.UseInsecureKeyring {
-		_, statErr := os.Stat(GetPathToInsecureKeyring())
-		if os.IsNotExist(statErr) {
-			mkdirErr := os.MkdirAll(insecureKeyringDir, 0600)
-			if mkdirErr != nil {
-				return &SecError{errOpInsecureKeyring, mkdirErr, mkdirErr.Error()}
-			}
-			_, openFileErr := os.OpenFile(GetPathToInsecureKeyring(), os.O_CREATE, 0600)
-			if openFileErr != nil {
-				return &SecError{errOpInsecureKeyring, openFileErr, openFileErr.Error()}
-			}
+// SecProxyCredentialsUpdate : Stores the user and password of the proxy URL given to a connection in the keyring, as
+// the connections file only keeps the URL without them. A proxy URL without credentials removes any stored earlier.
+func SecProxyCredentialsUpdate(connectionID string, proxyURL string) *SecError {
+	_, credentials := connections.SplitProxyURL(proxyURL)
+	if credentials == "" {
+		secErr := DeleteSecretFromKeyring(connectionID, connections.ProxyCredentialsSecret)
+		if secErr != nil && !IsSecretNotFoundError(secErr) {
+			return secErr
 		}
+		return nil
+	}
+	return StoreSecretInKeyring(connectionID, connections.ProxyCredentialsSecret, credentials)
+}
 
-		existingSecrets := []KeyringSecret{}
-		file, readErr := ioutil.ReadFile(GetPathToInsecureKeyring())
-		if readErr != nil {
-			return &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
-		}
-		if len(file) != 0 {
-			unmarshalErr := json.Unmarshal([]byte(file), &existingSecrets)
-			if unmarshalErr != nil {
-				return &SecError{errOpInsecureKeyring, unmarshalErr, unmarshalErr.Error()}
-			}
-		}
-		newSecret := KeyringSecret{
-			Service:  []byte(service),
-			Username: []byte(uName),
-			Password: []byte(pass),
+// SecProxyURL : Returns the proxy URL of a connection with the user and password kept in the keyring, or the saved
+// URL when it has none
+func SecProxyURL(connection *connections.Connection) (string, *SecError) {
+	if !connection.ProxyAuth || connection.ProxyURL == "" {
+		return connection.ProxyURL, nil
+	}
+	credentials, secErr := GetSecretFromKeyring(connection.ID, connections.ProxyCredentialsSecret)
+	if secErr != nil {
+		if IsSecretNotFoundError(secErr) {
+			return connection.ProxyURL, nil
 		}
-		indexOfSecretToUpdate := -1
+		return "", secErr
+	}
+	return connections.ProxyURLWithCredentials(connection.ProxyURL, credentials), nil
+}
 
-		for i, existingSecret := range existingSecrets {
-			if doSecretsMatch(existingSecret, newSecret) {
-				indexOfSecretToUpdate = i
-			}
-		}
-		if indexOfSecretToUpdate > -1 {
-			// remove existing secret
-			existingSecrets = append(existingSecrets[:indexOfSecretToUpdate], existingSecrets[indexOfSecretToUpdate+1:]...)
+// StoreSecretInKeyring stores the secret in the system keyring, our insecure keyring, or both.
+func StoreSecretInKeyring(connectionID, uName, pass string) *SecError {
+	service := connectionIDToService(connectionID)
+	if globals.CredentialStore != globals.CredentialStoreFile {
+		secErr := storeSecretInSystemKeyring(service, uName, pass)
+		if secErr != nil {
+			return secErr
 		}
-		secrets := append(existingSecrets, newSecret)
-		body, marshallErr := json.MarshalIndent(secrets, "", "\t")
-		if marshallErr != nil {
-			return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
+	}
+	if globals.CredentialStore != globals.CredentialStoreKeychain {
+		return storeSecretInInsecureKeyring(service, uName, pass)
+	}
+	return nil
+}
+
+// GetSecretFromKeyring gets the secret from the system keyring, our insecure keyring, or the first of them to hold it.
+func GetSecretFromKeyring(connectionID, uName string) (string, *SecError) {
+	service := connectionIDToService(connectionID)
+	switch globals.CredentialStore {
+	case globals.CredentialStoreFile:
+		return getSecretFromInsecureKeyring(service, uName)
+	case globals.CredentialStoreBoth:
+		secret, secErr := getSecretFromSystemKeyring(service, uName)
+		if secErr == nil {
+			return secret, nil
 		}
-		writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
-		if writeErr != nil {
-			return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
+		return getSecretFromInsecureKeyring(service, uName)
+	}
+	return getSecretFromSystemKeyring(service, uName)
+}
+
+// DeleteSecretFromKeyring deletes the secret from the system keyring, our insecure keyring, or both.
+// When using both, it succeeds if the secret was deleted from either.
+func DeleteSecretFromKeyring(connectionID, uName string) *SecError {
+	service := connectionIDToService(connectionID)
+	switch globals.CredentialStore {
+	case globals.CredentialStoreFile:
+		return deleteSecretFromInsecureKeyring(service, uName)
+	case globals.CredentialStoreBoth:
+		systemErr := deleteSecretFromSystemKeyring(service, uName)
+		fileErr := deleteSecretFromInsecureKeyring(service, uName)
+		if systemErr != nil && fileErr != nil {
+			return systemErr
 		}
 		return nil
 	}
+	return deleteSecretFromSystemKeyring(service, uName)
+}
 
-	// else store it in system keyring
+func storeSecretInSystemKeyring(service, uName, pass string) *SecError {
 	err := keyring.Set(service, uName, pass)
 	if err != nil {
 		return &SecError{errOpKeyring, err, err.Error()}
@@ -141,30 +165,11 @@ func StoreSecretInKeyring(connectionID, uName, pass string) *SecError {
 	return nil
 }
 
-// GetSecretFromKeyring gets the secret from either the system keyring or our insecure keyring.
-func GetSecretFromKeyring(connectionID, uName string) (string, *SecError) {
-	service := connectionIDToService(connectionID)
-	if globals.UseInsecureKeyring {
-		secrets, readErr := readInsecureKeyring()
-		if readErr != nil {
-			return "", readErr
-		}
-		for _, secret := range secrets {
-			sameService := string(secret.Service) == service
-			sameUsername := string(secret.Username) == uName
-			matchingSecret := sameService && sameUsername
-			if matchingSecret {
-				return string(secret.Password), nil
-			}
-		}
-		err := fmt.Errorf(textSecretNotFound, service+"."+uName)
-		return "", &SecError{errOpInsecureKeyring, err, err.Error()}
-	}
-	// else get from system keyring
+func getSecretFromSystemKeyring(service, uName string) (string, *SecError) {
 	secret, err := keyring.Get(service, uName)
 	if err != nil {
 		if err == keyring.ErrNotFound {
-			errNotFound := fmt.Errorf(textSecretNotFound, service+"."+uName)
+			errNotFound := i18n.Errorf(msgSecretNotFound, textSecretNotFound, service+"."+uName)
 			return "", &SecError{errOpKeyringSecretNotFound, errNotFound, errNotFound.Error()}
 		}
 		return "", &SecError{errOpKeyring, err, err.Error()}
@@ -172,54 +177,121 @@ func GetSecretFromKeyring(connectionID, uName string) (string, *SecError) {
 	return secret, nil
 }
 
-// DeleteSecretFromKeyring deletes the secret from either the system keyring or our insecure keyring.
-func DeleteSecretFromKeyring(connectionID, uName string) *SecError {
-	service := connectionIDToService(connectionID)
-	if globals.UseInsecureKeyring {
-		secrets, readErr := readInsecureKeyring()
-		if readErr != nil {
-			return readErr
+func deleteSecretFromSystemKeyring(service, uName string) *SecError {
+	err := keyring.Delete(service, uName)
+	if err != nil {
+		if err == keyring.ErrNotFound {
+			errNotFound := i18n.Errorf(msgSecretNotFound, textSecretNotFound, service+"."+uName)
+			return &SecError{errOpKeyringSecretNotFound, errNotFound, errNotFound.Error()}
+		}
+		return &SecError{errOpKeyring, err, err.Error()}
+	}
+	return nil
+}
+
+func storeSecretInInsecureKeyring(service, uName, pass string) *SecError {
+	_, statErr := os.Stat(GetPathToInsecureKeyring())
+	if os.IsNotExist(statErr) {
+		mkdirErr := os.MkdirAll(insecureKeyringDir, 0600)
+		if mkdirErr != nil {
+			return &SecError{errOpInsecureKeyring, mkdirErr, mkdirErr.Error()}
 		}
-		indexOfSecretToDelete := -1
-		for i, secret := range secrets {
-			sameService := string(secret.Service) == service
-			sameUsername := string(secret.Username) == uName
-			matchingSecret := sameService && sameUsername
-			if matchingSecret {
-				indexOfSecretToDelete = i
-			}
+		_, openFileErr := os.OpenFile(GetPathToInsecureKeyring(), os.O_CREATE, 0600)
+		if openFileErr != nil {
+			return &SecError{errOpInsecureKeyring, openFileErr, openFileErr.Error()}
 		}
-		if indexOfSecretToDelete == -1 {
-			err := fmt.Errorf(textSecretNotFound, service+"."+uName)
-			return &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+
+	existingSecrets := []KeyringSecret{}
+	file, readErr := ioutil.ReadFile(GetPathToInsecureKeyring())
+	if readErr != nil {
+		return &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
+	}
+	if len(file) != 0 {
+		unmarshalErr := json.Unmarshal([]byte(file), &existingSecrets)
+		if unmarshalErr != nil {
+			return &SecError{errOpInsecureKeyring, unmarshalErr, unmarshalErr.Error()}
+		}
+	}
+	newSecret := KeyringSecret{
+		Service:  []byte(service),
+		Username: []byte(uName),
+		Password: []byte(pass),
+	}
+	indexOfSecretToUpdate := -1
+
+	for i, existingSecret := range existingSecrets {
+		if doSecretsMatch(existingSecret, newSecret) {
+			indexOfSecretToUpdate = i
 		}
+	}
+	if indexOfSecretToUpdate > -1 {
 		// remove existing secret
-		secrets = append(secrets[:indexOfSecretToDelete], secrets[indexOfSecretToDelete+1:]...)
-		if len(secrets) == 0 {
-			err := os.Remove(GetPathToInsecureKeyring())
-			if err != nil {
-				return &SecError{errOpInsecureKeyring, err, err.Error()}
-			}
-			return nil
+		existingSecrets = append(existingSecrets[:indexOfSecretToUpdate], existingSecrets[indexOfSecretToUpdate+1:]...)
+	}
+	secrets := append(existingSecrets, newSecret)
+	body, marshallErr := json.MarshalIndent(secrets, "", "\t")
+	if marshallErr != nil {
+		return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
+	}
+	writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
+	if writeErr != nil {
+		return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
+	}
+	return nil
+}
+
+func getSecretFromInsecureKeyring(service, uName string) (string, *SecError) {
+	secrets, readErr := readInsecureKeyring()
+	if readErr != nil {
+		return "", readErr
+	}
+	for _, secret := range secrets {
+		sameService := string(secret.Service) == service
+		sameUsername := string(secret.Username) == uName
+		matchingSecret := sameService && sameUsername
+		if matchingSecret {
+			return string(secret.Password), nil
 		}
-		body, marshallErr := json.MarshalIndent(secrets, "", "\t")
-		if marshallErr != nil {
-			return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
+	}
+	err := i18n.Errorf(msgSecretNotFound, textSecretNotFound, service+"."+uName)
+	return "", &SecError{errOpInsecureKeyring, err, err.Error()}
+}
+
+func deleteSecretFromInsecureKeyring(service, uName string) *SecError {
+	secrets, readErr := readInsecureKeyring()
+	if readErr != nil {
+		return readErr
+	}
+	indexOfSecretToDelete := -1
+	for i, secret := range secrets {
+		sameService := string(secret.Service) == service
+		sameUsername := string(secret.Username) == uName
+		matchingSecret := sameService && sameUsername
+		if matchingSecret {
+			indexOfSecretToDelete = i
 		}
-		writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
-		if writeErr != nil {
-			return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
+	}
+	if indexOfSecretToDelete == -1 {
+		err := i18n.Errorf(msgSecretNotFound, textSecretNotFound, service+"."+uName)
+		return &SecError{errOpInsecureKeyring, err, err.Error()}
+	}
+	// remove existing secret
+	secrets = append(secrets[:indexOfSecretToDelete], secrets[indexOfSecretToDelete+1:]...)
+	if len(secrets) == 0 {
+		err := os.Remove(GetPathToInsecureKeyring())
+		if err != nil {
+			return &SecError{errOpInsecureKeyring, err, err.Error()}
 		}
 		return nil
 	}
-	// else delete from system keyring
-	err := keyring.Delete(service, uName)
-	if err != nil {
-		if err == keyring.ErrNotFound {
-			errNotFound := fmt.Errorf(textSecretNotFound, service+"."+uName)
-			return &SecError{errOpKeyringSecretNotFound, errNotFound, errNotFound.Error()}
-		}
-		return &SecError{errOpKeyring, err, err.Error()}
+	body, marshallErr := json.MarshalIndent(secrets, "", "\t")
+	if marshallErr != nil {
+		return &SecError{errOpInsecureKeyring, marshallErr, marshallErr.Error()}
+	}
+	writeErr := ioutil.WriteFile(GetPathToInsecureKeyring(), body, 0644)
+	if writeErr != nil {
+		return &SecError{errOpInsecureKeyring, writeErr, writeErr.Error()}
 	}
 	return nil
 }
@@ -228,7 +300,7 @@ func readInsecureKeyring() ([]KeyringSecret, *SecError) {
 	file, readErr := ioutil.ReadFile(GetPathToInsecureKeyring())
 	if readErr != nil {
 		if os.IsNotExist(readErr) {
-			err := errors.New(textKeyringNotFound)
+			err := i18n.Errorf(msgKeyringNotFound, textKeyringNotFound)
 			return nil, &SecError{errOpInsecureKeyring, err, err.Error()}
 		}
 		return nil, &SecError{errOpInsecureKeyring, readErr, readErr.Error()}
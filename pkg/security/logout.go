@@ -0,0 +1,75 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"net/url"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// oauthInvalidGrant is returned by the identity provider when a refresh token has already expired or been revoked
+const oauthInvalidGrant = "invalid_grant"
+
+// SecLogout : End the session of a connection, leaving the connection itself in place. The refresh token is revoked
+// at the identity provider, then the cached tokens, and optionally the stored password, are removed from the keyring.
+// The tokens are removed even when the provider cannot be reached, so a failure to revoke the token is returned
+// separately from a failure to update the keyring.
+func SecLogout(httpClient utils.HTTPClient, connection *connections.Connection, forgetPassword bool) (revokeErr *SecError, secErr *SecError) {
+	refreshToken, readErr := GetSecretFromKeyring(connection.ID, "refresh_token")
+	if readErr == nil && refreshToken != "" {
+		revokeErr = revokeRefreshToken(httpClient, connection, refreshToken)
+	}
+
+	secrets := []string{"access_token", "refresh_token"}
+	if forgetPassword && connection.Username != "" {
+		secrets = append(secrets, connection.Username)
+	}
+	for _, secret := range secrets {
+		secErr = DeleteSecretFromKeyring(connection.ID, secret)
+		if secErr != nil && !IsSecretNotFoundError(secErr) {
+			return revokeErr, secErr
+		}
+	}
+	return revokeErr, nil
+}
+
+// revokeRefreshToken uses the token revocation endpoint of the identity provider if it has one, otherwise ending the
+// session as Keycloak does when given the refresh token
+func revokeRefreshToken(httpClient utils.HTTPClient, connection *connections.Connection, refreshToken string) *SecError {
+	endpoints, secErr := ProviderForConnection(connection).Endpoints(httpClient)
+	if secErr != nil {
+		return secErr
+	}
+	if endpoints.RevocationEndpoint != "" {
+		payload := url.Values{
+			"client_id":       {connection.ClientID},
+			"token":           {refreshToken},
+			"token_type_hint": {"refresh_token"},
+		}
+		_, secErr = postTokenForm(httpClient, endpoints.RevocationEndpoint, payload)
+		return secErr
+	}
+	if endpoints.EndSessionEndpoint == "" {
+		return nil
+	}
+	payload := url.Values{
+		"client_id":     {connection.ClientID},
+		"refresh_token": {refreshToken},
+	}
+	_, secErr = postTokenForm(httpClient, endpoints.EndSessionEndpoint, payload)
+	if secErr != nil && secErr.Op == oauthInvalidGrant {
+		return nil
+	}
+	return secErr
+}
@@ -0,0 +1,86 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Logout(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping testing in short mode")
+	}
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	os.Remove(GetPathToInsecureKeyring())
+	defer os.Remove(GetPathToInsecureKeyring())
+
+	connection := connections.Connection{ID: testConnection, AuthURL: "https://auth.remote", Realm: "codewind", ClientID: "codewind-cli", Username: "developer"}
+	storeSession := func() {
+		StoreSecretInKeyring(testConnection, "access_token", "access")
+		StoreSecretInKeyring(testConnection, "refresh_token", "refresh")
+		StoreSecretInKeyring(testConnection, "developer", "pAss%!")
+	}
+
+	t.Run("Revokes the refresh token and removes the cached tokens, keeping the password", func(t *testing.T) {
+		storeSession()
+		mockClient := &ClientMockSequence{Responses: []mockResponse{{http.StatusNoContent, ""}}}
+		revokeErr, secErr := SecLogout(mockClient, &connection, false)
+		assert.Nil(t, revokeErr)
+		assert.Nil(t, secErr)
+		assert.Equal(t, "https://auth.remote/auth/realms/codewind/protocol/openid-connect/logout", mockClient.Requests[0].URL.String())
+		mockClient.Requests[0].ParseForm()
+		assert.Equal(t, "refresh", mockClient.Requests[0].PostForm.Get("refresh_token"))
+
+		_, secErr = GetSecretFromKeyring(testConnection, "access_token")
+		assert.True(t, IsSecretNotFoundError(secErr))
+		_, secErr = GetSecretFromKeyring(testConnection, "refresh_token")
+		assert.True(t, IsSecretNotFoundError(secErr))
+		password, _ := GetSecretFromKeyring(testConnection, "developer")
+		assert.Equal(t, "pAss%!", password)
+	})
+
+	t.Run("Removes the tokens and password when the token has already expired", func(t *testing.T) {
+		storeSession()
+		mockClient := &ClientMockSequence{Responses: []mockResponse{{http.StatusBadRequest, `{"error":"invalid_grant","error_description":"Token is not active"}`}}}
+		revokeErr, secErr := SecLogout(mockClient, &connection, true)
+		assert.Nil(t, revokeErr)
+		assert.Nil(t, secErr)
+		_, secErr = GetSecretFromKeyring(testConnection, "developer")
+		assert.True(t, IsSecretNotFoundError(secErr))
+	})
+
+	t.Run("Removes the tokens but reports when Keycloak is unavailable", func(t *testing.T) {
+		storeSession()
+		mockClient := &ClientMockSequence{Responses: []mockResponse{{http.StatusServiceUnavailable, ""}}}
+		revokeErr, secErr := SecLogout(mockClient, &connection, false)
+		assert.Equal(t, textAuthIsDown, revokeErr.Desc)
+		assert.Nil(t, secErr)
+		_, secErr = GetSecretFromKeyring(testConnection, "refresh_token")
+		assert.True(t, IsSecretNotFoundError(secErr))
+	})
+
+	t.Run("Does not contact Keycloak when not logged in", func(t *testing.T) {
+		mockClient := &ClientMockSequence{}
+		revokeErr, secErr := SecLogout(mockClient, &connection, false)
+		assert.Nil(t, revokeErr)
+		assert.Nil(t, secErr)
+		assert.Len(t, mockClient.Requests, 0)
+	})
+}
@@ -0,0 +1,182 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// PKCEChallenge : A Proof Key for Code Exchange. The challenge is sent with the authorization request and the
+// verifier with the token request, so no client secret is needed to redeem the authorization code.
+type PKCEChallenge struct {
+	Verifier  string
+	Challenge string
+	Method    string
+}
+
+// pkceCallbackPath is where the browser is redirected to once the user has logged in
+const pkceCallbackPath = "/callback"
+
+// pkceLoginTimeout is how long to wait for the user to log in, and is replaced in tests
+var pkceLoginTimeout = 5 * time.Minute
+
+// NewPKCEChallenge : Generate a random code verifier and its S256 challenge
+func NewPKCEChallenge() (*PKCEChallenge, *SecError) {
+	verifier, secErr := randomURLString(32)
+	if secErr != nil {
+		return nil, secErr
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return &PKCEChallenge{
+		Verifier:  verifier,
+		Challenge: base64.RawURLEncoding.EncodeToString(sum[:]),
+		Method:    "S256",
+	}, nil
+}
+
+// SecAuthorizationCodeURL : The URL the user opens in a browser to log in to the connection
+func SecAuthorizationCodeURL(httpClient utils.HTTPClient, connection *connections.Connection, redirectURI string, state string, pkce *PKCEChallenge) (string, *SecError) {
+	provider := ProviderForConnection(connection)
+	endpoints, secErr := provider.Endpoints(httpClient)
+	if secErr != nil {
+		return "", secErr
+	}
+	query := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {connection.ClientID},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {provider.Scope()},
+		"state":                 {state},
+		"code_challenge":        {pkce.Challenge},
+		"code_challenge_method": {pkce.Method},
+	}
+	return endpoints.AuthorizationEndpoint + "?" + query.Encode(), nil
+}
+
+// SecExchangeAuthorizationCode : Redeem an authorization code for tokens using the PKCE verifier. The tokens are
+// saved to the keyring, and any password stored for the connection user is removed as it is no longer needed.
+func SecExchangeAuthorizationCode(httpClient utils.HTTPClient, connection *connections.Connection, code string, redirectURI string, pkce *PKCEChallenge) (*AuthToken, *SecError) {
+	payload := url.Values{
+		"grant_type":    {"authorization_code"},
+		"client_id":     {connection.ClientID},
+		"code":          {code},
+		"redirect_uri":  {redirectURI},
+		"code_verifier": {pkce.Verifier},
+	}
+	authToken, secErr := requestConnectionTokens(httpClient, connection, payload)
+	if secErr != nil {
+		return authToken, secErr
+	}
+	if connection.Username != "" {
+		secErr = DeleteSecretFromKeyring(connection.ID, connection.Username)
+		if secErr != nil && !IsSecretNotFoundError(secErr) {
+			return authToken, secErr
+		}
+	}
+	return authToken, nil
+}
+
+// SecPKCELogin : Log in to the connection in a browser using the authorization code flow with PKCE. A listener on
+// the loopback interface receives the redirect from the identity provider, and openURL is called with the URL the user must open.
+func SecPKCELogin(httpClient utils.HTTPClient, connection *connections.Connection, openURL func(string)) (*AuthToken, *SecError) {
+	pkce, secErr := NewPKCEChallenge()
+	if secErr != nil {
+		return nil, secErr
+	}
+	state, secErr := randomURLString(16)
+	if secErr != nil {
+		return nil, secErr
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, &SecError{errOpConnection, err, err.Error()}
+	}
+	redirectURI := "http://" + listener.Addr().String() + pkceCallbackPath
+
+	type callbackResult struct {
+		code   string
+		secErr *SecError
+	}
+	results := make(chan callbackResult, 1)
+	mux := http.NewServeMux()
+	mux.HandleFunc(pkceCallbackPath, func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		result := callbackResult{code: query.Get("code")}
+		switch {
+		case query.Get("state") != state:
+			err := i18n.Errorf(msgBadLoginState, textBadLoginState)
+			result.secErr = &SecError{errOpResponse, err, err.Error()}
+		case query.Get("error") != "":
+			err := errors.New(query.Get("error_description"))
+			if query.Get("error_description") == "" {
+				err = errors.New(query.Get("error"))
+			}
+			result.secErr = &SecError{query.Get("error"), err, err.Error()}
+		case result.code == "":
+			err := i18n.Errorf(msgUnableToParse, textUnableToParse)
+			result.secErr = &SecError{errOpResponseFormat, err, err.Error()}
+		}
+		if result.secErr != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintln(w, "Login failed, return to the terminal for details.")
+		} else {
+			fmt.Fprintln(w, "Login successful, you can close this window.")
+		}
+		select {
+		case results <- result:
+		default:
+		}
+	})
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	loginURL, secErr := SecAuthorizationCodeURL(httpClient, connection, redirectURI, state, pkce)
+	if secErr != nil {
+		return nil, secErr
+	}
+	openURL(loginURL)
+
+	select {
+	case result := <-results:
+		if result.secErr != nil {
+			return nil, result.secErr
+		}
+		return SecExchangeAuthorizationCode(httpClient, connection, result.code, redirectURI, pkce)
+	case <-time.After(pkceLoginTimeout):
+		err := i18n.Errorf(msgLoginTimeout, textLoginTimeout)
+		return nil, &SecError{errOpCLICommand, err, err.Error()}
+	}
+}
+
+// randomURLString returns size random bytes encoded for use in a URL
+func randomURLString(size int) (string, *SecError) {
+	buf := make([]byte, size)
+	_, err := rand.Read(buf)
+	if err != nil {
+		return "", &SecError{errOpCreate, err, err.Error()}
+	}
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
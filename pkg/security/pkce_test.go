@@ -0,0 +1,125 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/globals"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewPKCEChallenge(t *testing.T) {
+	pkce, secErr := NewPKCEChallenge()
+	assert.Nil(t, secErr)
+	assert.Equal(t, "S256", pkce.Method)
+	assert.Len(t, pkce.Verifier, 43)
+	sum := sha256.Sum256([]byte(pkce.Verifier))
+	assert.Equal(t, base64.RawURLEncoding.EncodeToString(sum[:]), pkce.Challenge)
+
+	another, _ := NewPKCEChallenge()
+	assert.NotEqual(t, pkce.Verifier, another.Verifier)
+}
+
+func Test_PKCELogin(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping testing in short mode")
+	}
+	originalUseInsecureKeyring := globals.UseInsecureKeyring
+	globals.SetUseInsecureKeyring(true)
+	defer globals.SetUseInsecureKeyring(originalUseInsecureKeyring)
+	os.Remove(GetPathToInsecureKeyring())
+	defer os.Remove(GetPathToInsecureKeyring())
+
+	connection := connections.Connection{ID: testConnection, AuthURL: "https://auth.remote", Realm: "codewind", ClientID: "codewind-cli", Username: "developer"}
+
+	// followRedirect plays the part of the browser, sending Keycloak's redirect back to the CLI
+	followRedirect := func(params func(url.Values) url.Values) func(string) {
+		return func(loginURL string) {
+			parsed, _ := url.Parse(loginURL)
+			query := parsed.Query()
+			callback, _ := url.Parse(query.Get("redirect_uri"))
+			callback.RawQuery = params(query).Encode()
+			go func() {
+				res, err := http.Get(callback.String())
+				if err == nil {
+					ioutil.ReadAll(res.Body)
+					res.Body.Close()
+				}
+			}()
+		}
+	}
+
+	t.Run("Sends the challenge, then the verifier with the code, and removes the stored password", func(t *testing.T) {
+		StoreSecretInKeyring(testConnection, "developer", "pAss%!")
+		var loginQuery url.Values
+		mockClient := &ClientMockSequence{Responses: []mockResponse{
+			{http.StatusOK, `{"access_token":"pkce_access","refresh_token":"pkce_refresh"}`},
+		}}
+		tokens, secErr := SecPKCELogin(mockClient, &connection, followRedirect(func(query url.Values) url.Values {
+			loginQuery = query
+			return url.Values{"code": {"authcode"}, "state": {query.Get("state")}}
+		}))
+		assert.Nil(t, secErr)
+		assert.Equal(t, "pkce_access", tokens.AccessToken)
+		assert.Equal(t, "S256", loginQuery.Get("code_challenge_method"))
+		assert.Equal(t, "codewind-cli", loginQuery.Get("client_id"))
+
+		mockClient.Requests[0].ParseForm()
+		form := mockClient.Requests[0].PostForm
+		assert.Equal(t, "authorization_code", form.Get("grant_type"))
+		assert.Equal(t, "authcode", form.Get("code"))
+		assert.Equal(t, loginQuery.Get("redirect_uri"), form.Get("redirect_uri"))
+		assert.Empty(t, form.Get("client_secret"))
+		sum := sha256.Sum256([]byte(form.Get("code_verifier")))
+		assert.Equal(t, loginQuery.Get("code_challenge"), base64.RawURLEncoding.EncodeToString(sum[:]))
+
+		accessToken, _ := GetSecretFromKeyring(testConnection, "access_token")
+		assert.Equal(t, "pkce_access", accessToken)
+		_, secErr = GetSecretFromKeyring(testConnection, "developer")
+		assert.True(t, IsSecretNotFoundError(secErr))
+	})
+
+	t.Run("Rejects a redirect with the wrong state", func(t *testing.T) {
+		mockClient := &ClientMockSequence{}
+		tokens, secErr := SecPKCELogin(mockClient, &connection, followRedirect(func(query url.Values) url.Values {
+			return url.Values{"code": {"authcode"}, "state": {"forged"}}
+		}))
+		assert.Nil(t, tokens)
+		assert.Equal(t, textBadLoginState, secErr.Desc)
+		assert.Len(t, mockClient.Requests, 0)
+	})
+
+	t.Run("Returns the error when the login is denied", func(t *testing.T) {
+		tokens, secErr := SecPKCELogin(&ClientMockSequence{}, &connection, followRedirect(func(query url.Values) url.Values {
+			return url.Values{"error": {"access_denied"}, "state": {query.Get("state")}}
+		}))
+		assert.Nil(t, tokens)
+		assert.Equal(t, "access_denied", secErr.Op)
+	})
+
+	t.Run("Times out when the browser never returns", func(t *testing.T) {
+		pkceLoginTimeout = 10 * time.Millisecond
+		defer func() { pkceLoginTimeout = 5 * time.Minute }()
+		tokens, secErr := SecPKCELogin(&ClientMockSequence{}, &connection, func(string) {})
+		assert.Nil(t, tokens)
+		assert.Equal(t, textLoginTimeout, secErr.Desc)
+	})
+}
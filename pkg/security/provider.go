@@ -0,0 +1,305 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+// ProviderEndpoints : The OpenID Connect endpoints of an identity provider, as published in its discovery document
+type ProviderEndpoints struct {
+	Issuer                      string `json:"issuer"`
+	AuthorizationEndpoint       string `json:"authorization_endpoint"`
+	TokenEndpoint               string `json:"token_endpoint"`
+	DeviceAuthorizationEndpoint string `json:"device_authorization_endpoint"`
+	EndSessionEndpoint          string `json:"end_session_endpoint"`
+	RevocationEndpoint          string `json:"revocation_endpoint"`
+	JWKSURI                     string `json:"jwks_uri"`
+}
+
+// TokenProvider : An identity provider issuing the tokens used to reach a connection
+type TokenProvider interface {
+	// Endpoints returns the endpoints used to obtain, refresh and revoke tokens
+	Endpoints(httpClient utils.HTTPClient) (*ProviderEndpoints, *SecError)
+	// Scope returns the scope requested when logging in
+	Scope() string
+	// VerifyIDToken checks an ID token was issued by the provider for the client
+	VerifyIDToken(httpClient utils.HTTPClient, idToken string, clientID string) *SecError
+}
+
+// ProviderForConnection : The identity provider of a connection, the bundled Keycloak unless the connection names
+// another OpenID Connect issuer
+func ProviderForConnection(connection *connections.Connection) TokenProvider {
+	if connection.Provider == connections.ProviderOIDC {
+		return &OIDCProvider{Issuer: connection.AuthURL}
+	}
+	return &KeycloakProvider{AuthURL: connection.AuthURL, Realm: connection.Realm}
+}
+
+// KeycloakProvider : A Keycloak realm, whose endpoints are known without discovery
+type KeycloakProvider struct {
+	AuthURL string
+	Realm   string
+}
+
+// Endpoints : The OpenID Connect endpoints of the realm
+func (p *KeycloakProvider) Endpoints(httpClient utils.HTTPClient) (*ProviderEndpoints, *SecError) {
+	realmURL := p.AuthURL + "/auth/realms/" + p.Realm
+	return &ProviderEndpoints{
+		Issuer:                      realmURL,
+		AuthorizationEndpoint:       realmURL + "/protocol/openid-connect/auth",
+		TokenEndpoint:               realmURL + "/protocol/openid-connect/token",
+		DeviceAuthorizationEndpoint: realmURL + "/protocol/openid-connect/auth/device",
+		EndSessionEndpoint:          realmURL + "/protocol/openid-connect/logout",
+		JWKSURI:                     realmURL + "/protocol/openid-connect/certs",
+	}, nil
+}
+
+// Scope : Keycloak issues refresh tokens without requesting offline access
+func (p *KeycloakProvider) Scope() string {
+	return "openid"
+}
+
+// VerifyIDToken : Tokens issued by Keycloak are verified by the Gatekeeper, which shares the realm
+func (p *KeycloakProvider) VerifyIDToken(httpClient utils.HTTPClient, idToken string, clientID string) *SecError {
+	return nil
+}
+
+// OIDCProvider : A generic OpenID Connect provider such as Okta, Azure AD or Dex, found using its discovery document
+type OIDCProvider struct {
+	Issuer    string
+	endpoints *ProviderEndpoints
+}
+
+// Endpoints : Read the endpoints from the discovery document of the issuer
+func (p *OIDCProvider) Endpoints(httpClient utils.HTTPClient) (*ProviderEndpoints, *SecError) {
+	if p.endpoints != nil {
+		return p.endpoints, nil
+	}
+	issuer := strings.TrimSuffix(p.Issuer, "/")
+	endpoints := ProviderEndpoints{}
+	secErr := getProviderJSON(httpClient, issuer+"/.well-known/openid-configuration", &endpoints)
+	if secErr != nil {
+		return nil, secErr
+	}
+	if strings.TrimSuffix(endpoints.Issuer, "/") != issuer || endpoints.TokenEndpoint == "" {
+		err := i18n.Errorf(msgBadDiscovery, textBadDiscovery)
+		return nil, &SecError{errOpResponseFormat, err, err.Error()}
+	}
+	p.endpoints = &endpoints
+	return p.endpoints, nil
+}
+
+// Scope : Offline access is requested as most providers only then issue a refresh token
+func (p *OIDCProvider) Scope() string {
+	return "openid offline_access"
+}
+
+// VerifyIDToken : Check the signature of an ID token against the keys published by the issuer, and that it was
+// issued to the client and has not expired
+func (p *OIDCProvider) VerifyIDToken(httpClient utils.HTTPClient, idToken string, clientID string) *SecError {
+	endpoints, secErr := p.Endpoints(httpClient)
+	if secErr != nil {
+		return secErr
+	}
+	parts := strings.Split(idToken, ".")
+	if len(parts) != 3 {
+		return badIDToken()
+	}
+	header := struct {
+		Algorithm string `json:"alg"`
+		KeyID     string `json:"kid"`
+	}{}
+	claims := struct {
+		Issuer   string          `json:"iss"`
+		Audience json.RawMessage `json:"aud"`
+		Expiry   int64           `json:"exp"`
+	}{}
+	if decodeJWTPart(parts[0], &header) != nil || decodeJWTPart(parts[1], &claims) != nil {
+		return badIDToken()
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return badIDToken()
+	}
+
+	keys := struct {
+		Keys []jsonWebKey `json:"keys"`
+	}{}
+	secErr = getProviderJSON(httpClient, endpoints.JWKSURI, &keys)
+	if secErr != nil {
+		return secErr
+	}
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	verified := false
+	for _, key := range keys.Keys {
+		if header.KeyID != "" && key.KeyID != header.KeyID {
+			continue
+		}
+		if key.verify(header.Algorithm, digest[:], signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return badIDToken()
+	}
+
+	audiences := []string{}
+	if json.Unmarshal(claims.Audience, &audiences) != nil {
+		audience := ""
+		json.Unmarshal(claims.Audience, &audience)
+		audiences = []string{audience}
+	}
+	issuedToClient := false
+	for _, audience := range audiences {
+		issuedToClient = issuedToClient || audience == clientID
+	}
+	if claims.Issuer != endpoints.Issuer || !issuedToClient || time.Now().After(time.Unix(claims.Expiry, 0)) {
+		return badIDToken()
+	}
+	return nil
+}
+
+// jsonWebKey : A public key published by an identity provider to verify the tokens it signs
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	N       string `json:"n"`
+	E       string `json:"e"`
+	Curve   string `json:"crv"`
+	X       string `json:"x"`
+	Y       string `json:"y"`
+}
+
+// verify checks an RS256 or ES256 signature of the digest
+func (key jsonWebKey) verify(algorithm string, digest []byte, signature []byte) bool {
+	switch {
+	case algorithm == "RS256" && key.KeyType == "RSA":
+		n, nErr := base64.RawURLEncoding.DecodeString(key.N)
+		e, eErr := base64.RawURLEncoding.DecodeString(key.E)
+		if nErr != nil || eErr != nil {
+			return false
+		}
+		publicKey := &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, digest, signature) == nil
+	case algorithm == "ES256" && key.KeyType == "EC" && key.Curve == "P-256":
+		x, xErr := base64.RawURLEncoding.DecodeString(key.X)
+		y, yErr := base64.RawURLEncoding.DecodeString(key.Y)
+		if xErr != nil || yErr != nil || len(signature) != 64 {
+			return false
+		}
+		publicKey := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		return ecdsa.Verify(publicKey, digest, new(big.Int).SetBytes(signature[:32]), new(big.Int).SetBytes(signature[32:]))
+	}
+	return false
+}
+
+// decodeJWTPart decodes the header or claims of a JSON Web Token
+func decodeJWTPart(part string, result interface{}) error {
+	decoded, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(decoded, result)
+}
+
+func badIDToken() *SecError {
+	err := i18n.Errorf(msgBadIDToken, textBadIDToken)
+	return &SecError{errOpResponse, err, err.Error()}
+}
+
+// getProviderJSON reads a JSON document published by an identity provider
+func getProviderJSON(httpClient utils.HTTPClient, documentURL string, result interface{}) *SecError {
+	if _, err := url.ParseRequestURI(documentURL); err != nil {
+		return &SecError{errOpHostname, err, err.Error()}
+	}
+	req, err := http.NewRequest("GET", documentURL, nil)
+	if err != nil {
+		return &SecError{errOpConnection, err, err.Error()}
+	}
+	req.Header.Add("Accept", "application/json")
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return &SecError{errOpConnection, err, err.Error()}
+	}
+	defer res.Body.Close()
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return &SecError{errOpResponse, err, err.Error()}
+	}
+	if res.StatusCode != http.StatusOK {
+		err = errors.New(http.StatusText(res.StatusCode) + ": " + documentURL)
+		return &SecError{errOpResponse, err, err.Error()}
+	}
+	err = json.Unmarshal(body, result)
+	if err != nil {
+		return &SecError{errOpResponseFormat, err, err.Error()}
+	}
+	return nil
+}
+
+// requestConnectionTokens sends a token request to the identity provider of the connection. The tokens issued are
+// verified, then saved to the keyring for the connection. Errors from the provider are returned unchanged, with the
+// OAuth error code as their Op.
+func requestConnectionTokens(httpClient utils.HTTPClient, connection *connections.Connection, payload url.Values) (*AuthToken, *SecError) {
+	provider := ProviderForConnection(connection)
+	endpoints, secErr := provider.Endpoints(httpClient)
+	if secErr != nil {
+		return nil, secErr
+	}
+	body, secErr := postTokenForm(httpClient, endpoints.TokenEndpoint, payload)
+	if secErr != nil {
+		return nil, secErr
+	}
+
+	authToken := AuthToken{}
+	err := json.Unmarshal(body, &authToken)
+	if err != nil {
+		return nil, &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
+	}
+	if authToken.IDToken != "" {
+		secErr = provider.VerifyIDToken(httpClient, authToken.IDToken, connection.ClientID)
+		if secErr != nil {
+			return nil, secErr
+		}
+	}
+	secErr = SecKeyUpdate(connection.ID, "access_token", authToken.AccessToken)
+	if secErr != nil {
+		return &authToken, secErr
+	}
+	// Some providers keep the refresh token, only issuing a new one when it is close to expiring
+	if authToken.RefreshToken != "" {
+		secErr = SecKeyUpdate(connection.ID, "refresh_token", authToken.RefreshToken)
+		if secErr != nil {
+			return &authToken, secErr
+		}
+	}
+	return &authToken, nil
+}
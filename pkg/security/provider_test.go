@@ -0,0 +1,138 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/stretchr/testify/assert"
+)
+
+// newMockOIDCProvider returns a server publishing a discovery document and the public key of signingKey
+func newMockOIDCProvider(t *testing.T, signingKey *rsa.PrivateKey) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/.well-known/openid-configuration":
+			json.NewEncoder(w).Encode(ProviderEndpoints{
+				Issuer:                server.URL,
+				AuthorizationEndpoint: server.URL + "/authorize",
+				TokenEndpoint:         server.URL + "/token",
+				RevocationEndpoint:    server.URL + "/revoke",
+				JWKSURI:               server.URL + "/keys",
+			})
+		case "/keys":
+			json.NewEncoder(w).Encode(map[string][]jsonWebKey{"keys": {{
+				KeyType: "RSA",
+				KeyID:   "key1",
+				N:       base64.RawURLEncoding.EncodeToString(signingKey.N.Bytes()),
+				E:       base64.RawURLEncoding.EncodeToString(big.NewInt(int64(signingKey.E)).Bytes()),
+			}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	return server
+}
+
+// signIDToken returns an RS256 JSON Web Token with the claims
+func signIDToken(signingKey *rsa.PrivateKey, claims map[string]interface{}) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "kid": "key1"})
+	payload, _ := json.Marshal(claims)
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, signingKey, crypto.SHA256, digest[:])
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func Test_ProviderForConnection(t *testing.T) {
+	keycloak := ProviderForConnection(&connections.Connection{AuthURL: "https://auth.remote", Realm: "codewind"})
+	endpoints, secErr := keycloak.Endpoints(http.DefaultClient)
+	assert.Nil(t, secErr)
+	assert.Equal(t, "https://auth.remote/auth/realms/codewind/protocol/openid-connect/token", endpoints.TokenEndpoint)
+	assert.Equal(t, "openid", keycloak.Scope())
+
+	oidc := ProviderForConnection(&connections.Connection{AuthURL: "https://dex.remote", Provider: connections.ProviderOIDC})
+	assert.Equal(t, &OIDCProvider{Issuer: "https://dex.remote"}, oidc)
+}
+
+func Test_OIDCProvider(t *testing.T) {
+	signingKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server := newMockOIDCProvider(t, signingKey)
+	defer server.Close()
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{"iss": server.URL, "aud": "codewind-cli", "exp": time.Now().Add(time.Hour).Unix()}
+	}
+
+	t.Run("Reads the endpoints from the discovery document", func(t *testing.T) {
+		provider := &OIDCProvider{Issuer: server.URL + "/"}
+		endpoints, secErr := provider.Endpoints(http.DefaultClient)
+		assert.Nil(t, secErr)
+		assert.Equal(t, server.URL+"/token", endpoints.TokenEndpoint)
+		assert.Equal(t, server.URL+"/revoke", endpoints.RevocationEndpoint)
+		assert.Equal(t, "", endpoints.DeviceAuthorizationEndpoint)
+	})
+
+	t.Run("Rejects a discovery document for another issuer", func(t *testing.T) {
+		provider := &OIDCProvider{Issuer: server.URL + "/tenant"}
+		_, secErr := provider.Endpoints(http.DefaultClient)
+		assert.NotNil(t, secErr)
+	})
+
+	t.Run("Accepts an ID token signed by the issuer for the client", func(t *testing.T) {
+		provider := &OIDCProvider{Issuer: server.URL}
+		secErr := provider.VerifyIDToken(http.DefaultClient, signIDToken(signingKey, validClaims()), "codewind-cli")
+		assert.Nil(t, secErr)
+
+		claims := validClaims()
+		claims["aud"] = []string{"other", "codewind-cli"}
+		secErr = provider.VerifyIDToken(http.DefaultClient, signIDToken(signingKey, claims), "codewind-cli")
+		assert.Nil(t, secErr)
+	})
+
+	t.Run("Rejects ID tokens with a bad signature, audience, issuer or expiry", func(t *testing.T) {
+		provider := &OIDCProvider{Issuer: server.URL}
+		otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+		secErr := provider.VerifyIDToken(http.DefaultClient, signIDToken(otherKey, validClaims()), "codewind-cli")
+		assert.Equal(t, textBadIDToken, secErr.Desc)
+
+		secErr = provider.VerifyIDToken(http.DefaultClient, signIDToken(signingKey, validClaims()), "another-client")
+		assert.Equal(t, textBadIDToken, secErr.Desc)
+
+		claims := validClaims()
+		claims["iss"] = "https://attacker.example.com"
+		secErr = provider.VerifyIDToken(http.DefaultClient, signIDToken(signingKey, claims), "codewind-cli")
+		assert.Equal(t, textBadIDToken, secErr.Desc)
+
+		claims = validClaims()
+		claims["exp"] = time.Now().Add(-time.Minute).Unix()
+		secErr = provider.VerifyIDToken(http.DefaultClient, signIDToken(signingKey, claims), "codewind-cli")
+		assert.Equal(t, textBadIDToken, secErr.Desc)
+	})
+
+	t.Run("Reports a provider without device authorization", func(t *testing.T) {
+		connection := connections.Connection{AuthURL: server.URL, Provider: connections.ProviderOIDC, ClientID: "codewind-cli"}
+		_, secErr := SecDeviceAuthorize(http.DefaultClient, &connection)
+		assert.Equal(t, textNoDeviceFlow, secErr.Desc)
+	})
+}
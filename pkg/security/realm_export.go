@@ -0,0 +1,199 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/urfave/cli"
+)
+
+// RealmExport : A Keycloak realm representation including its clients, roles and users. It is kept as generic JSON
+// so that every realm setting survives an export and import, whichever Keycloak version produced it.
+type RealmExport map[string]interface{}
+
+// exportUserPageSize is the number of users fetched from Keycloak in each request
+const exportUserPageSize = 100
+
+// SecRealmExport : Export a realm, with its clients, roles and users, from Keycloak
+// User passwords cannot be read from Keycloak so are not exported, confidential client secrets are.
+func SecRealmExport(httpClient utils.HTTPClient, c *cli.Context) (RealmExport, *SecError) {
+	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
+	realm := strings.TrimSpace(c.String("realm"))
+	accesstoken := strings.TrimSpace(c.String("accesstoken"))
+	realmURL := hostname + "/auth/admin/realms/" + url.PathEscape(realm)
+
+	export := RealmExport{}
+	secErr := keycloakAdminRequest(httpClient, "POST", realmURL+"/partial-export?exportClients=true&exportGroupsAndRoles=true", accesstoken, nil, &export)
+	if secErr != nil {
+		return nil, secErr
+	}
+
+	// The partial export masks client secrets, so read them individually
+	clients, _ := export["clients"].([]interface{})
+	for _, item := range clients {
+		client, ok := item.(map[string]interface{})
+		if !ok || client["publicClient"] == true || client["bearerOnly"] == true || client["id"] == nil {
+			continue
+		}
+		secret := RegisteredClientSecret{}
+		secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/clients/"+client["id"].(string)+"/client-secret", accesstoken, nil, &secret)
+		if secErr != nil {
+			return nil, secErr
+		}
+		client["secret"] = secret.Secret
+	}
+
+	users, secErr := exportRealmUsers(httpClient, realmURL, accesstoken)
+	if secErr != nil {
+		return nil, secErr
+	}
+	export["users"] = users
+	return export, nil
+}
+
+// exportRealmUsers returns every user of the realm with their role mappings and groups, in the form accepted when
+// creating a realm
+func exportRealmUsers(httpClient utils.HTTPClient, realmURL string, accesstoken string) ([]map[string]interface{}, *SecError) {
+	users := []map[string]interface{}{}
+	for first := 0; ; first += exportUserPageSize {
+		page := []map[string]interface{}{}
+		secErr := keycloakAdminRequest(httpClient, "GET", realmURL+"/users?first="+strconv.Itoa(first)+"&max="+strconv.Itoa(exportUserPageSize), accesstoken, nil, &page)
+		if secErr != nil {
+			return nil, secErr
+		}
+		users = append(users, page...)
+		if len(page) < exportUserPageSize {
+			break
+		}
+	}
+
+	type roleMapping struct {
+		Name string `json:"name"`
+	}
+	type roleMappings struct {
+		RealmMappings  []roleMapping `json:"realmMappings"`
+		ClientMappings map[string]struct {
+			Mappings []roleMapping `json:"mappings"`
+		} `json:"clientMappings"`
+	}
+	type group struct {
+		Path string `json:"path"`
+	}
+
+	for _, user := range users {
+		userID, _ := user["id"].(string)
+		mappings := roleMappings{}
+		secErr := keycloakAdminRequest(httpClient, "GET", realmURL+"/users/"+userID+"/role-mappings", accesstoken, nil, &mappings)
+		if secErr != nil {
+			return nil, secErr
+		}
+		realmRoles := []string{}
+		for _, role := range mappings.RealmMappings {
+			realmRoles = append(realmRoles, role.Name)
+		}
+		clientRoles := map[string][]string{}
+		for clientID, client := range mappings.ClientMappings {
+			for _, role := range client.Mappings {
+				clientRoles[clientID] = append(clientRoles[clientID], role.Name)
+			}
+		}
+		user["realmRoles"] = realmRoles
+		user["clientRoles"] = clientRoles
+
+		groups := []group{}
+		secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/users/"+userID+"/groups", accesstoken, nil, &groups)
+		if secErr != nil {
+			return nil, secErr
+		}
+		groupPaths := []string{}
+		for _, group := range groups {
+			groupPaths = append(groupPaths, group.Path)
+		}
+		user["groups"] = groupPaths
+
+		// Read only details which Keycloak rejects or ignores on import
+		delete(user, "access")
+		delete(user, "disableableCredentialTypes")
+	}
+	return users, nil
+}
+
+// SecRealmImport : Create a realm in Keycloak from an export, optionally renaming it
+func SecRealmImport(httpClient utils.HTTPClient, c *cli.Context, export RealmExport) *SecError {
+	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
+	newRealm := strings.TrimSpace(c.String("newrealm"))
+	accesstoken := strings.TrimSpace(c.String("accesstoken"))
+
+	if newRealm != "" {
+		export["id"] = newRealm
+		export["realm"] = newRealm
+	}
+	if export["realm"] == nil || export["realm"] == "" {
+		err := i18n.Errorf(msgBadRealmExport, textBadRealmExport)
+		return &SecError{errOpCLICommand, err, err.Error()}
+	}
+
+	payload, err := json.Marshal(export)
+	if err != nil {
+		return &SecError{errOpResponseFormat, err, err.Error()}
+	}
+	return keycloakAdminRequest(httpClient, "POST", hostname+"/auth/admin/realms", accesstoken, bytes.NewReader(payload), nil)
+}
+
+// keycloakAdminRequest sends a request to the Keycloak admin API, decoding any JSON response into result
+func keycloakAdminRequest(httpClient utils.HTTPClient, method string, requestURL string, accesstoken string, payload io.Reader, result interface{}) *SecError {
+	req, err := http.NewRequest(method, requestURL, payload)
+	if err != nil {
+		return &SecError{errOpConnection, err, err.Error()}
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("Cache-Control", "no-cache")
+	req.Header.Add("Authorization", "Bearer "+accesstoken)
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return &SecError{errOpConnection, err, err.Error()}
+	}
+	defer res.Body.Close()
+	body, _ := ioutil.ReadAll(res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		keycloakAPIError := parseKeycloakError(string(body), res.StatusCode)
+		if keycloakAPIError.ErrorDescription == "" {
+			keycloakAPIError.ErrorDescription = keycloakAPIError.Error
+		}
+		if keycloakAPIError.ErrorDescription == "" {
+			keycloakAPIError.ErrorDescription = http.StatusText(res.StatusCode)
+		}
+		kcError := errors.New(keycloakAPIError.ErrorDescription)
+		return &SecError{errOpResponse, kcError, kcError.Error()}
+	}
+
+	if result != nil {
+		err = json.Unmarshal(body, result)
+		if err != nil {
+			return &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"encoding/json"
+	"flag"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/urfave/cli"
+)
+
+// newMockKeycloak returns a server answering the admin API requests made by a realm export, recording any realm
+// created by an import
+func newMockKeycloak(t *testing.T, created *RealmExport) *httptest.Server {
+	responses := map[string]string{
+		"POST /auth/admin/realms/codewind/partial-export": `{"realm": "codewind", "clients": [
+			{"id": "c1", "clientId": "codewind-backend", "secret": "**********"},
+			{"id": "c2", "clientId": "codewind-cli", "publicClient": true}
+		]}`,
+		"GET /auth/admin/realms/codewind/clients/c1/client-secret": `{"type": "secret", "value": "s3cret"}`,
+		"GET /auth/admin/realms/codewind/users":                    `[{"id": "u1", "username": "developer", "access": {"manage": true}}]`,
+		"GET /auth/admin/realms/codewind/users/u1/role-mappings": `{
+			"realmMappings": [{"name": "codewind-abc123"}],
+			"clientMappings": {"account": {"mappings": [{"name": "view-profile"}]}}
+		}`,
+		"GET /auth/admin/realms/codewind/users/u1/groups": `[{"path": "/developers"}]`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer admintoken", r.Header.Get("Authorization"))
+		if r.Method == "POST" && r.URL.Path == "/auth/admin/realms" {
+			body, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(body, created)
+			w.WriteHeader(http.StatusCreated)
+			return
+		}
+		response, found := responses[r.Method+" "+r.URL.Path]
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error": "Realm not found."}`))
+			return
+		}
+		w.Write([]byte(response))
+	}))
+}
+
+func newRealmContext(values map[string]string) *cli.Context {
+	set := flag.NewFlagSet("tests", 0)
+	for name, value := range values {
+		set.String(name, value, "doc")
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func Test_RealmExport(t *testing.T) {
+	server := newMockKeycloak(t, nil)
+	defer server.Close()
+
+	t.Run("success case - exports clients with secrets and users with their roles", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken"})
+		export, secErr := SecRealmExport(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+
+		clients := export["clients"].([]interface{})
+		assert.Equal(t, "s3cret", clients[0].(map[string]interface{})["secret"])
+		assert.Nil(t, clients[1].(map[string]interface{})["secret"])
+
+		users := export["users"].([]map[string]interface{})
+		assert.Len(t, users, 1)
+		assert.Equal(t, []string{"codewind-abc123"}, users[0]["realmRoles"])
+		assert.Equal(t, map[string][]string{"account": {"view-profile"}}, users[0]["clientRoles"])
+		assert.Equal(t, []string{"/developers"}, users[0]["groups"])
+		assert.Nil(t, users[0]["access"])
+	})
+
+	t.Run("error case - unknown realm", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "missing", "accesstoken": "admintoken"})
+		_, secErr := SecRealmExport(http.DefaultClient, c)
+		assert.Equal(t, errOpResponse, secErr.Op)
+		assert.Equal(t, "Realm not found.", secErr.Desc)
+	})
+}
+
+func Test_RealmImport(t *testing.T) {
+	created := RealmExport{}
+	server := newMockKeycloak(t, &created)
+	defer server.Close()
+
+	t.Run("success case - imports the realm under a new name", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "newrealm": "codewind2", "accesstoken": "admintoken"})
+		secErr := SecRealmImport(http.DefaultClient, c, RealmExport{"id": "codewind", "realm": "codewind", "enabled": true})
+		assert.Nil(t, secErr)
+		assert.Equal(t, RealmExport{"id": "codewind2", "realm": "codewind2", "enabled": true}, created)
+	})
+
+	t.Run("error case - export without a realm name", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "accesstoken": "admintoken"})
+		secErr := SecRealmImport(http.DefaultClient, c, RealmExport{"enabled": true})
+		assert.Equal(t, textBadRealmExport, secErr.Desc)
+	})
+}
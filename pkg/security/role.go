@@ -18,6 +18,8 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 	"github.com/urfave/cli"
 )
 
@@ -89,6 +91,20 @@ func SecRoleCreate(c *cli.Context) *SecError {
 	return nil
 }
 
+// SecRoleList : List the roles of a realm
+func SecRoleList(httpClient utils.HTTPClient, c *cli.Context) ([]Role, *SecError) {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return nil, secErr
+	}
+	roles := []Role{}
+	secErr = keycloakAdminRequest(httpClient, "GET", realmURL+"/roles", accesstoken, nil, &roles)
+	if secErr != nil {
+		return nil, secErr
+	}
+	return roles, nil
+}
+
 func getRoleByName(c *cli.Context, roleName string) (*Role, *SecError) {
 
 	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
@@ -130,7 +146,7 @@ func getRoleByName(c *cli.Context, roleName string) (*Role, *SecError) {
 	var role *Role
 	err = json.Unmarshal([]byte(body), &role)
 	if err != nil {
-		return nil, &SecError{errOpResponseFormat, err, textUnableToParse}
+		return nil, &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
 	}
 
 	// found role
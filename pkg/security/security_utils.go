@@ -57,12 +57,44 @@ const (
 const (
 	textBadPassword     = "Passwords must not contains quoted characters"
 	textUserNotFound    = "Registered User not found"
+	textGroupNotFound   = "Group not found"
+	textClientNotFound  = "Registered Client not found"
 	textUnableToParse   = "Unable to parse Keycloak response"
 	textInvalidOptions  = "Invalid or missing command line options"
 	textAuthIsDown      = "Authentication service unavailable"
 	textNotFoundSuffix  = "not found in keyring"
 	textSecretNotFound  = "Secret %s " + textNotFoundSuffix
 	textKeyringNotFound = "Keyring not found"
+	textBadRealmExport  = "Realm export does not name a realm"
+	textBadConExport    = "Connection export is not in a supported format"
+	textBadPassphrase   = "Unable to decrypt credentials, check the passphrase"
+	textDeviceExpired   = "The device code expired before the login was approved"
+	textNoDeviceFlow    = "The identity provider does not support device authorization"
+	textBadDiscovery    = "The identity provider discovery document does not match its issuer"
+	textBadIDToken      = "The ID token was not issued by the identity provider for this client"
+	textBadLoginState   = "The login response does not match the request, try logging in again"
+	textLoginTimeout    = "Timed out waiting for the login to complete in the browser"
+)
+
+// IDs of the messages in the i18n catalogs
+const (
+	msgUserNotFound    = "security.user_not_found"
+	msgGroupNotFound   = "security.group_not_found"
+	msgClientNotFound  = "security.client_not_found"
+	msgUnableToParse   = "security.unable_to_parse"
+	msgInvalidOptions  = "security.invalid_options"
+	msgAuthIsDown      = "security.auth_is_down"
+	msgSecretNotFound  = "security.secret_not_found"
+	msgKeyringNotFound = "security.keyring_not_found"
+	msgBadRealmExport  = "security.bad_realm_export"
+	msgBadConExport    = "security.bad_con_export"
+	msgBadPassphrase   = "security.bad_passphrase"
+	msgDeviceExpired   = "security.device_expired"
+	msgNoDeviceFlow    = "security.no_device_flow"
+	msgBadDiscovery    = "security.bad_discovery"
+	msgBadIDToken      = "security.bad_id_token"
+	msgBadLoginState   = "security.bad_login_state"
+	msgLoginTimeout    = "security.login_timeout"
 )
 
 // SecError : Error formatted in JSON containing an errorOp and a description from
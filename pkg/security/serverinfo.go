@@ -17,6 +17,8 @@ import (
 	"io/ioutil"
 	"net/http"
 	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/i18n"
 )
 
 // RegisteredTheme : A Keycloak theme
@@ -80,7 +82,7 @@ func GetServerInfo(keycloakHostname string, accesstoken string) (*ServerInfo, *S
 	serverInfo := ServerInfo{}
 	err = json.Unmarshal([]byte(body), &serverInfo)
 	if err != nil {
-		return nil, &SecError{errOpResponseFormat, err, textUnableToParse}
+		return nil, &SecError{errOpResponseFormat, i18n.Errorf(msgUnableToParse, textUnableToParse), textUnableToParse}
 	}
 	return &serverInfo, nil
 }
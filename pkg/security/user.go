@@ -14,10 +14,16 @@ package security
 import (
 	"encoding/json"
 	"errors"
+	"flag"
 	"io/ioutil"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
 
+	"github.com/eclipse/codewind-installer/pkg/connections"
+	"github.com/eclipse/codewind-installer/pkg/i18n"
+	"github.com/eclipse/codewind-installer/pkg/utils"
 	logr "github.com/sirupsen/logrus"
 	"github.com/urfave/cli"
 )
@@ -29,26 +35,26 @@ type RegisteredUsers struct {
 
 // RegisteredUser : details of a registered user
 type RegisteredUser struct {
-	ID       string `json:"id"`
-	Username string `json:"username"`
+	ID        string `json:"id"`
+	Username  string `json:"username"`
+	Email     string `json:"email,omitempty"`
+	FirstName string `json:"firstName,omitempty"`
+	LastName  string `json:"lastName,omitempty"`
+	Enabled   bool   `json:"enabled"`
 }
 
 // SecUserCreate : Create a new realm in Keycloak
 func SecUserCreate(c *cli.Context) *SecError {
 
-	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
-	realm := strings.TrimSpace(c.String("realm"))
-	accesstoken := strings.TrimSpace(c.String("accesstoken"))
-	targetUsername := strings.TrimSpace(c.String("name"))
-
-	// authenticate if needed
-	if accesstoken == "" {
-		authToken, err := SecAuthenticate(http.DefaultClient, c, KeycloakMasterRealm, KeycloakAdminClientID)
-		if err != nil || authToken == nil {
-			return err
-		}
-		accesstoken = authToken.AccessToken
+	hostname, realm, secErr := keycloakAdminTarget(c)
+	if secErr != nil {
+		return secErr
+	}
+	accesstoken, secErr := keycloakAdminToken(http.DefaultClient, c, hostname)
+	if secErr != nil {
+		return secErr
 	}
+	targetUsername := strings.TrimSpace(c.String("name"))
 
 	// build REST request
 	url := hostname + "/auth/admin/realms/" + realm + "/users"
@@ -97,19 +103,15 @@ func SecUserCreate(c *cli.Context) *SecError {
 // SecUserGet : Get user from Keycloak
 func SecUserGet(c *cli.Context) (*RegisteredUser, *SecError) {
 
-	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
-	realm := strings.TrimSpace(c.String("realm"))
-	accesstoken := strings.TrimSpace(c.String("accesstoken"))
-	searchName := strings.TrimSpace(c.String("name"))
-
-	// authenticate if needed
-	if accesstoken == "" {
-		authToken, err := SecAuthenticate(http.DefaultClient, c, KeycloakMasterRealm, KeycloakAdminClientID)
-		if err != nil || authToken == nil {
-			return nil, err
-		}
-		accesstoken = authToken.AccessToken
+	hostname, realm, secErr := keycloakAdminTarget(c)
+	if secErr != nil {
+		return nil, secErr
+	}
+	accesstoken, secErr := keycloakAdminToken(http.DefaultClient, c, hostname)
+	if secErr != nil {
+		return nil, secErr
 	}
+	searchName := strings.TrimSpace(c.String("name"))
 
 	// build REST request
 	url := hostname + "/auth/admin/realms/" + realm + "/users?username=" + searchName
@@ -149,7 +151,7 @@ func SecUserGet(c *cli.Context) (*RegisteredUser, *SecError) {
 	}
 
 	// user not found
-	errNotFound := errors.New(textUserNotFound)
+	errNotFound := i18n.Errorf(msgUserNotFound, textUserNotFound)
 	return nil, &SecError{errOpNotFound, errNotFound, errNotFound.Error()}
 
 }
@@ -157,19 +159,15 @@ func SecUserGet(c *cli.Context) (*RegisteredUser, *SecError) {
 // SecUserSetPW : Resets the users password in keycloak to a new one supplied
 func SecUserSetPW(c *cli.Context) *SecError {
 
-	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
-	realm := strings.TrimSpace(c.String("realm"))
-	accesstoken := strings.TrimSpace(c.String("accesstoken"))
-	newPassword := strings.TrimSpace(c.String("newpw"))
-
-	// authenticate if needed
-	if accesstoken == "" {
-		authToken, err := SecAuthenticate(http.DefaultClient, c, KeycloakMasterRealm, KeycloakAdminClientID)
-		if err != nil || authToken == nil {
-			return err
-		}
-		accesstoken = authToken.AccessToken
+	hostname, realm, secErr := keycloakAdminTarget(c)
+	if secErr != nil {
+		return secErr
 	}
+	accesstoken, secErr := keycloakAdminToken(http.DefaultClient, c, hostname)
+	if secErr != nil {
+		return secErr
+	}
+	newPassword := strings.TrimSpace(c.String("newpw"))
 
 	registeredUser, secError := SecUserGet(c)
 	if secError != nil {
@@ -270,3 +268,97 @@ func SecUserAddRole(c *cli.Context) *SecError {
 
 	return nil
 }
+
+// SecUserList : List the users of a realm
+func SecUserList(httpClient utils.HTTPClient, c *cli.Context) ([]RegisteredUser, *SecError) {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return nil, secErr
+	}
+
+	users := []RegisteredUser{}
+	for first := 0; ; first += exportUserPageSize {
+		page := []RegisteredUser{}
+		secErr := keycloakAdminRequest(httpClient, "GET", realmURL+"/users?first="+strconv.Itoa(first)+"&max="+strconv.Itoa(exportUserPageSize), accesstoken, nil, &page)
+		if secErr != nil {
+			return nil, secErr
+		}
+		users = append(users, page...)
+		if len(page) < exportUserPageSize {
+			break
+		}
+	}
+	return users, nil
+}
+
+// SecUserDelete : Remove a user from a realm
+func SecUserDelete(httpClient utils.HTTPClient, c *cli.Context) *SecError {
+	realmURL, accesstoken, secErr := keycloakAdminRealm(httpClient, c)
+	if secErr != nil {
+		return secErr
+	}
+	user, secErr := getUserByName(httpClient, realmURL, accesstoken, strings.TrimSpace(c.String("name")))
+	if secErr != nil {
+		return secErr
+	}
+	logr.Tracef("Removing user '%v' : %v", user.Username, user.ID)
+	return keycloakAdminRequest(httpClient, "DELETE", realmURL+"/users/"+user.ID, accesstoken, nil, nil)
+}
+
+// keycloakAdminRealm returns the admin API URL of the realm to manage, and an admin access token for it
+func keycloakAdminRealm(httpClient utils.HTTPClient, c *cli.Context) (string, string, *SecError) {
+	hostname, realm, secErr := keycloakAdminTarget(c)
+	if secErr != nil {
+		return "", "", secErr
+	}
+	accesstoken, secErr := keycloakAdminToken(httpClient, c, hostname)
+	if secErr != nil {
+		return "", "", secErr
+	}
+	return hostname + "/auth/admin/realms/" + url.PathEscape(realm), accesstoken, nil
+}
+
+// keycloakAdminTarget returns the Keycloak host and realm to manage. Either may be given on the command
+// line, otherwise they are those of the connection given by --conid.
+func keycloakAdminTarget(c *cli.Context) (string, string, *SecError) {
+	hostname := strings.TrimSpace(strings.ToLower(c.String("host")))
+	realm := strings.TrimSpace(c.String("realm"))
+	connectionID := strings.TrimSpace(c.String("conid"))
+	if connectionID != "" && (hostname == "" || realm == "") {
+		connection, conErr := connections.GetConnectionByID(connectionID)
+		if conErr != nil {
+			return "", "", &SecError{errOpConConfig, conErr.Err, conErr.Desc}
+		}
+		if hostname == "" {
+			hostname = connection.AuthURL
+		}
+		if realm == "" {
+			realm = connection.Realm
+		}
+	}
+	if hostname == "" || realm == "" {
+		err := i18n.Errorf(msgInvalidOptions, textInvalidOptions)
+		return "", "", &SecError{errOpCLICommand, err, err.Error()}
+	}
+	return hostname, realm, nil
+}
+
+// keycloakAdminToken returns the admin access token given on the command line, or one obtained from the master realm
+// using the admin username and password. The connection is not passed on, so its cached tokens are left alone.
+func keycloakAdminToken(httpClient utils.HTTPClient, c *cli.Context, hostname string) (string, *SecError) {
+	accesstoken := strings.TrimSpace(c.String("accesstoken"))
+	if accesstoken != "" {
+		return accesstoken, nil
+	}
+	flagSet := flag.NewFlagSet("adminAuthentication", 0)
+	flagSet.String("host", hostname, "doc")
+	flagSet.String("realm", KeycloakMasterRealm, "doc")
+	flagSet.String("client", KeycloakAdminClientID, "doc")
+	flagSet.String("username", c.String("username"), "doc")
+	flagSet.String("password", c.String("password"), "doc")
+	authToken, secErr := SecAuthenticate(httpClient, cli.NewContext(nil, flagSet, nil), "", "")
+	if secErr != nil {
+		return "", secErr
+	}
+	return authToken.AccessToken, nil
+}
@@ -0,0 +1,63 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_UserListAndDelete(t *testing.T) {
+	deleted := []string{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer admintoken", r.Header.Get("Authorization"))
+		switch {
+		case r.Method == "GET" && r.URL.Query().Get("username") != "":
+			w.Write([]byte(`[{"id": "u2", "username": "developer2"}, {"id": "u1", "username": "developer"}]`))
+		case r.Method == "GET":
+			w.Write([]byte(`[{"id": "u1", "username": "developer", "email": "dev@example.com", "enabled": true}]`))
+		case r.Method == "DELETE":
+			deleted = append(deleted, r.URL.Path)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	defer server.Close()
+
+	t.Run("success case - lists the users of the realm", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken"})
+		users, secErr := SecUserList(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Equal(t, []RegisteredUser{{ID: "u1", Username: "developer", Email: "dev@example.com", Enabled: true}}, users)
+	})
+
+	t.Run("success case - removes only the user with a matching name", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "name": "developer"})
+		secErr := SecUserDelete(http.DefaultClient, c)
+		assert.Nil(t, secErr)
+		assert.Equal(t, []string{"/auth/admin/realms/codewind/users/u1"}, deleted)
+	})
+
+	t.Run("fail case - user not found", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"host": server.URL, "realm": "codewind", "accesstoken": "admintoken", "name": "dev"})
+		secErr := SecUserDelete(http.DefaultClient, c)
+		assert.Equal(t, errOpNotFound, secErr.Op)
+	})
+
+	t.Run("fail case - no host or connection", func(t *testing.T) {
+		c := newRealmContext(map[string]string{"realm": "codewind", "accesstoken": "admintoken"})
+		_, secErr := SecUserList(http.DefaultClient, c)
+		assert.Equal(t, errOpCLICommand, secErr.Op)
+	})
+}
@@ -0,0 +1,245 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package telemetry
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+const (
+	// EndpointEnvVar overrides the endpoint events are sent to
+	EndpointEnvVar = "CW_TELEMETRY_ENDPOINT"
+
+	settingsFile = "telemetry.json"
+	queueFile    = "telemetry-queue.json"
+	// batchSize is how many events are queued before they are sent, and maxQueued how many are kept while the
+	// endpoint cannot be reached
+	batchSize = 20
+	maxQueued = 500
+	// sendTimeout keeps a slow endpoint from holding up the command that sends a batch
+	sendTimeout = 3 * time.Second
+)
+
+type (
+	// Settings : Whether usage metrics are collected, where they are sent and the random ID they are sent with
+	Settings struct {
+		Enabled  bool   `json:"enabled"`
+		Endpoint string `json:"endpoint,omitempty"`
+		ID       string `json:"id,omitempty"`
+	}
+
+	// Event : One run of a command. It holds nothing that identifies the user, their projects or connections.
+	Event struct {
+		ID         string `json:"id"`
+		Command    string `json:"command"`
+		Time       string `json:"time"`
+		DurationMS int64  `json:"durationMs"`
+		Success    bool   `json:"success"`
+		ErrorOp    string `json:"errorOp,omitempty"`
+		Version    string `json:"version"`
+		OS         string `json:"os"`
+		Arch       string `json:"arch"`
+	}
+
+	// run is the command being recorded
+	run struct {
+		dir      string
+		settings Settings
+		event    Event
+		started  time.Time
+	}
+)
+
+// current is the run of this process, nil when metrics are not being collected
+var current *run
+
+// LoadSettings : Reads the telemetry settings from a directory, metrics being off until they are turned on
+func LoadSettings(dir string) (Settings, error) {
+	settings := Settings{}
+	contents, err := ioutil.ReadFile(filepath.Join(dir, settingsFile))
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, err
+	}
+	err = json.Unmarshal(contents, &settings)
+	return settings, err
+}
+
+// saveSettings writes the telemetry settings to a directory
+func saveSettings(dir string, settings Settings) error {
+	contents, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(dir, settingsFile), contents, 0644)
+}
+
+// Endpoint : Returns the endpoint events are sent to, the environment variable taking precedence over the settings
+func Endpoint(settings Settings) string {
+	if endpoint := os.Getenv(EndpointEnvVar); endpoint != "" {
+		return endpoint
+	}
+	return settings.Endpoint
+}
+
+// Enable : Turns on the collection of usage metrics, keeping the endpoint already set unless another is given. A
+// random ID is created the first time, so that events from one installation can be grouped without identifying it.
+func Enable(dir string, endpoint string) (Settings, error) {
+	settings, err := LoadSettings(dir)
+	if err != nil {
+		return settings, err
+	}
+	if endpoint != "" {
+		parsed, err := url.Parse(endpoint)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return settings, errors.New("Telemetry endpoint " + endpoint + " is not an http or https URL")
+		}
+		settings.Endpoint = endpoint
+	}
+	if Endpoint(settings) == "" {
+		return settings, errors.New("No telemetry endpoint is set, give one with --endpoint or " + EndpointEnvVar)
+	}
+	if settings.ID == "" {
+		id := make([]byte, 16)
+		if _, err := rand.Read(id); err != nil {
+			return settings, err
+		}
+		settings.ID = hex.EncodeToString(id)
+	}
+	settings.Enabled = true
+	return settings, saveSettings(dir, settings)
+}
+
+// Disable : Turns off the collection of usage metrics and discards the events not yet sent
+func Disable(dir string) (Settings, error) {
+	settings, err := LoadSettings(dir)
+	if err != nil {
+		return settings, err
+	}
+	settings.Enabled = false
+	if err := saveSettings(dir, settings); err != nil {
+		return settings, err
+	}
+	os.Remove(filepath.Join(dir, queueFile))
+	return settings, nil
+}
+
+// Queued : Returns how many events are waiting to be sent
+func Queued(dir string) int {
+	return len(readQueue(dir))
+}
+
+// Start : Begins recording a run of a command, when usage metrics are turned on
+func Start(dir string, command string, version string) {
+	current = nil
+	settings, err := LoadSettings(dir)
+	if err != nil || !settings.Enabled || Endpoint(settings) == "" {
+		return
+	}
+	started := time.Now()
+	current = &run{
+		dir:      dir,
+		settings: settings,
+		started:  started,
+		event: Event{
+			ID:      settings.ID,
+			Command: command,
+			Time:    started.UTC().Format(time.RFC3339),
+			Version: version,
+			OS:      runtime.GOOS,
+			Arch:    runtime.GOARCH,
+		},
+	}
+}
+
+// Failed : Notes the operation code of the error a command is failing with
+func Failed(op string) {
+	if current != nil {
+		current.event.ErrorOp = op
+	}
+}
+
+// Finish : Records the outcome of the command being run, sending the queued events once there is a batch of them.
+// Metrics never fail a command, so errors are ignored and unsent events kept for the next batch.
+func Finish(success bool) {
+	if current == nil {
+		return
+	}
+	r := current
+	current = nil
+	r.event.Success = success && r.event.ErrorOp == ""
+	r.event.DurationMS = time.Since(r.started).Nanoseconds() / int64(time.Millisecond)
+
+	events := append(readQueue(r.dir), r.event)
+	if len(events) >= batchSize && send(Endpoint(r.settings), events) == nil {
+		events = nil
+	}
+	writeQueue(r.dir, events)
+}
+
+// readQueue returns the events waiting to be sent
+func readQueue(dir string) []Event {
+	events := []Event{}
+	if contents, err := ioutil.ReadFile(filepath.Join(dir, queueFile)); err == nil {
+		json.Unmarshal(contents, &events)
+	}
+	return events
+}
+
+// writeQueue keeps the events waiting to be sent, dropping the oldest beyond maxQueued
+func writeQueue(dir string, events []Event) {
+	queuePath := filepath.Join(dir, queueFile)
+	if len(events) == 0 {
+		os.Remove(queuePath)
+		return
+	}
+	if len(events) > maxQueued {
+		events = events[len(events)-maxQueued:]
+	}
+	if contents, err := json.Marshal(events); err == nil {
+		ioutil.WriteFile(queuePath, contents, 0644)
+	}
+}
+
+// send posts a batch of events to the endpoint as a JSON array
+func send(endpoint string, events []Event) error {
+	body, err := json.Marshal(events)
+	if err != nil {
+		return err
+	}
+	client := &http.Client{Timeout: sendTimeout}
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New("Telemetry endpoint returned " + resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package telemetry
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSettings(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telemetry")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	os.Unsetenv(EndpointEnvVar)
+
+	t.Run("metrics are off until turned on", func(t *testing.T) {
+		settings, err := LoadSettings(dir)
+		assert.Nil(t, err)
+		assert.False(t, settings.Enabled)
+	})
+
+	t.Run("turning on needs an endpoint", func(t *testing.T) {
+		_, err := Enable(dir, "")
+		assert.NotNil(t, err)
+		_, err = Enable(dir, "not-a-url")
+		assert.NotNil(t, err)
+	})
+
+	t.Run("turning on creates an ID that is kept", func(t *testing.T) {
+		settings, err := Enable(dir, "http://localhost:1234/events")
+		assert.Nil(t, err)
+		assert.True(t, settings.Enabled)
+		assert.Len(t, settings.ID, 32)
+
+		settings, err = Enable(dir, "")
+		assert.Nil(t, err)
+		assert.Equal(t, "http://localhost:1234/events", settings.Endpoint)
+		loaded, _ := LoadSettings(dir)
+		assert.Equal(t, settings, loaded)
+	})
+
+	t.Run("the environment variable overrides the endpoint", func(t *testing.T) {
+		os.Setenv(EndpointEnvVar, "https://example.com/events")
+		defer os.Unsetenv(EndpointEnvVar)
+		assert.Equal(t, "https://example.com/events", Endpoint(Settings{Endpoint: "http://localhost:1234/events"}))
+	})
+
+	t.Run("turning off discards queued events", func(t *testing.T) {
+		writeQueue(dir, []Event{{Command: "status"}})
+		settings, err := Disable(dir)
+		assert.Nil(t, err)
+		assert.False(t, settings.Enabled)
+		assert.Equal(t, 0, Queued(dir))
+	})
+}
+
+func TestRecording(t *testing.T) {
+	dir, err := ioutil.TempDir("", "telemetry")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+	os.Unsetenv(EndpointEnvVar)
+
+	var received [][]Event
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		events := []Event{}
+		json.NewDecoder(r.Body).Decode(&events)
+		received = append(received, events)
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	t.Run("nothing is recorded while metrics are off", func(t *testing.T) {
+		Start(dir, "status", "x.x.dev")
+		Finish(true)
+		assert.Equal(t, 0, Queued(dir))
+	})
+
+	t.Run("records the command, outcome and error operation", func(t *testing.T) {
+		_, err := Enable(dir, server.URL)
+		assert.Nil(t, err)
+		Start(dir, "project sync", "x.x.dev")
+		Failed("sync_bad_response")
+		Finish(false)
+
+		events := readQueue(dir)
+		assert.Len(t, events, 1)
+		assert.Equal(t, "project sync", events[0].Command)
+		assert.False(t, events[0].Success)
+		assert.Equal(t, "sync_bad_response", events[0].ErrorOp)
+		assert.Empty(t, received)
+	})
+
+	t.Run("keeps a batch the endpoint does not accept", func(t *testing.T) {
+		status = http.StatusInternalServerError
+		for i := Queued(dir); i < batchSize; i++ {
+			Start(dir, "status", "x.x.dev")
+			Finish(true)
+		}
+		assert.Len(t, received, 1)
+		assert.Equal(t, batchSize, Queued(dir))
+	})
+
+	t.Run("sends a batch and empties the queue", func(t *testing.T) {
+		status = http.StatusOK
+		Start(dir, "status", "x.x.dev")
+		Finish(true)
+		assert.Len(t, received, 2)
+		assert.Len(t, received[1], batchSize+1)
+		assert.Equal(t, 0, Queued(dir))
+	})
+}
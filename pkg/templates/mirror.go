@@ -0,0 +1,215 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+)
+
+type (
+	// MirrorOptions describes where a template repository is copied from and published to
+	MirrorOptions struct {
+		IndexURL       string
+		OutputDir      string
+		UploadURL      string
+		BaseURL        string
+		GitCredentials *utils.GitCredentials
+	}
+
+	// MirrorResult describes a mirrored template repository
+	MirrorResult struct {
+		IndexLocation string             `json:"index"`
+		Templates     []MirroredTemplate `json:"templates"`
+	}
+
+	// MirroredTemplate is a template copied to the mirror, with the location it was copied from
+	MirroredTemplate struct {
+		Name     string `json:"name"`
+		Source   string `json:"source"`
+		Location string `json:"location"`
+	}
+)
+
+// MirrorTemplateRepo copies a template repository, its index.json and the starter of every template, to a directory
+// or an HTTP endpoint that accepts PUT requests, such as a generic Artifactory or Nexus repository. The location of
+// each template in the index is rewritten to the base URL the mirror will be served from, which defaults to the
+// upload URL. Other fields in the index are kept as they are.
+// Starters published as tar.gz archives are copied unchanged, and those published as GitHub repositories are
+// archived from their master branch.
+func MirrorTemplateRepo(options MirrorOptions) (*MirrorResult, error) {
+	indexURL, err := url.ParseRequestURI(options.IndexURL)
+	if err != nil || !indexURL.IsAbs() {
+		return nil, fmt.Errorf("Error: '%s' is not a valid URL", options.IndexURL)
+	}
+	if (options.OutputDir == "") == (options.UploadURL == "") {
+		return nil, errors.New("Error: give either an output directory or an upload URL")
+	}
+	baseURL := options.BaseURL
+	if baseURL == "" {
+		baseURL = options.UploadURL
+	}
+	if _, err := url.ParseRequestURI(baseURL); err != nil {
+		return nil, fmt.Errorf("Error: '%s' is not a valid base URL for the mirror", baseURL)
+	}
+	baseURL = strings.TrimSuffix(baseURL, "/")
+
+	stagingDir := options.OutputDir
+	if stagingDir == "" {
+		stagingDir, err = ioutil.TempDir("", "codewind-mirror")
+		if err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(stagingDir)
+	} else if err := os.MkdirAll(stagingDir, 0755); err != nil {
+		return nil, err
+	}
+
+	indexPath := filepath.Join(stagingDir, indexFileName)
+	if err := utils.DownloadFile(indexURL, indexPath); err != nil {
+		return nil, err
+	}
+	contents, err := ioutil.ReadFile(indexPath)
+	if err != nil {
+		return nil, err
+	}
+	// Entries are kept as maps so that fields the CLI does not know about are mirrored too
+	index := []map[string]interface{}{}
+	if err := json.Unmarshal(contents, &index); err != nil {
+		return nil, fmt.Errorf("Error: %s is not a template repository index: %s", options.IndexURL, err)
+	}
+
+	result := MirrorResult{Templates: []MirroredTemplate{}}
+	archives := []string{}
+	usedNames := map[string]bool{}
+	for _, entry := range index {
+		name, _ := entry["displayName"].(string)
+		source, _ := entry["location"].(string)
+		if source == "" {
+			continue
+		}
+		archiveName := uniqueArchiveName(templateSlug(name), usedNames)
+		err := mirrorStarter(source, filepath.Join(stagingDir, archiveName), options.GitCredentials)
+		if err != nil {
+			return nil, fmt.Errorf("Error: unable to mirror template %s from %s: %s", name, source, err)
+		}
+		location := baseURL + "/" + archiveName
+		entry["location"] = location
+		archives = append(archives, archiveName)
+		result.Templates = append(result.Templates, MirroredTemplate{Name: name, Source: source, Location: location})
+	}
+
+	contents, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(indexPath, contents, 0644); err != nil {
+		return nil, err
+	}
+	result.IndexLocation = indexPath
+	if options.UploadURL == "" {
+		return &result, nil
+	}
+
+	// Upload the index last, so the mirror never lists a template whose starter is missing
+	uploadURL := strings.TrimSuffix(options.UploadURL, "/")
+	for _, fileName := range append(archives, indexFileName) {
+		if err := uploadFile(filepath.Join(stagingDir, fileName), uploadURL+"/"+fileName); err != nil {
+			return nil, err
+		}
+	}
+	result.IndexLocation = baseURL + "/" + indexFileName
+	return &result, nil
+}
+
+// uniqueArchiveName returns the archive file name for a template, numbering templates whose names are the same
+func uniqueArchiveName(slug string, usedNames map[string]bool) string {
+	if slug == "" {
+		slug = "template"
+	}
+	name := slug
+	for i := 2; usedNames[name]; i++ {
+		name = slug + "-" + strconv.Itoa(i)
+	}
+	usedNames[name] = true
+	return name + ".tar.gz"
+}
+
+// mirrorStarter copies the starter of a template to a tar.gz archive
+func mirrorStarter(source string, archivePath string, gitCredentials *utils.GitCredentials) error {
+	sourceURL, err := url.ParseRequestURI(source)
+	if err != nil {
+		return err
+	}
+	if utils.IsTarGzURL(sourceURL) && gitCredentials == nil {
+		return utils.DownloadFile(sourceURL, archivePath)
+	}
+
+	extractDir, err := ioutil.TempDir("", "codewind-mirror-template")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(extractDir)
+	if err := utils.DownloadFromURLThenExtract(source, extractDir, gitCredentials); err != nil {
+		return err
+	}
+	_, err = archiveDirectory(extractDir, archivePath, func(string, os.FileInfo) bool { return false })
+	return err
+}
+
+// uploadFile sends a file to an HTTP endpoint with a PUT request. Credentials in the URL are sent using basic
+// authentication.
+func uploadFile(filePath string, uploadURL string) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PUT", uploadURL, file)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = info.Size()
+	if strings.HasSuffix(filePath, ".json") {
+		req.Header.Set("Content-Type", "application/json")
+	} else {
+		req.Header.Set("Content-Type", "application/gzip")
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// Do not report credentials given in the upload URL
+		if parsed, parseErr := url.Parse(uploadURL); parseErr == nil {
+			parsed.User = nil
+			uploadURL = parsed.String()
+		}
+		return fmt.Errorf("Error: upload of %s failed with status code %d", uploadURL, resp.StatusCode)
+	}
+	return nil
+}
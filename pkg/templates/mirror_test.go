@@ -0,0 +1,91 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMirrorTemplateRepo(t *testing.T) {
+	var source *httptest.Server
+	source = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/index.json":
+			w.Write([]byte(`[
+				{"displayName": "Node.js Express", "language": "nodejs", "location": "` + source.URL + `/node.tar.gz", "links": {"self": "/node"}},
+				{"displayName": "Node.js Express", "language": "nodejs", "location": "` + source.URL + `/node.tar.gz"}
+			]`))
+		case "/node.tar.gz":
+			w.Write([]byte("starter archive"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer source.Close()
+
+	t.Run("copies the index and starters to a directory, rewriting their locations", func(t *testing.T) {
+		outputDir, _ := ioutil.TempDir("", "mirror")
+		defer os.RemoveAll(outputDir)
+		result, err := MirrorTemplateRepo(MirrorOptions{IndexURL: source.URL + "/index.json", OutputDir: outputDir, BaseURL: "https://mirror.internal/templates/"})
+		require.Nil(t, err)
+		assert.Equal(t, []MirroredTemplate{
+			{Name: "Node.js Express", Source: source.URL + "/node.tar.gz", Location: "https://mirror.internal/templates/node-js-express.tar.gz"},
+			{Name: "Node.js Express", Source: source.URL + "/node.tar.gz", Location: "https://mirror.internal/templates/node-js-express-2.tar.gz"},
+		}, result.Templates)
+
+		starter, _ := ioutil.ReadFile(filepath.Join(outputDir, "node-js-express.tar.gz"))
+		assert.Equal(t, "starter archive", string(starter))
+		contents, _ := ioutil.ReadFile(filepath.Join(outputDir, "index.json"))
+		index := []map[string]interface{}{}
+		require.Nil(t, json.Unmarshal(contents, &index))
+		assert.Equal(t, "https://mirror.internal/templates/node-js-express.tar.gz", index[0]["location"])
+		assert.Equal(t, map[string]interface{}{"self": "/node"}, index[0]["links"])
+	})
+
+	t.Run("uploads the starters then the index to an HTTP endpoint", func(t *testing.T) {
+		uploads := []string{}
+		target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			username, password, _ := r.BasicAuth()
+			assert.Equal(t, "PUT", r.Method)
+			assert.Equal(t, "deployer:secret", username+":"+password)
+			uploads = append(uploads, r.URL.Path)
+			w.WriteHeader(http.StatusCreated)
+		}))
+		defer target.Close()
+		uploadURL := "http://deployer:secret@" + target.Listener.Addr().String() + "/repo"
+		result, err := MirrorTemplateRepo(MirrorOptions{IndexURL: source.URL + "/index.json", UploadURL: uploadURL, BaseURL: "https://mirror.internal/repo"})
+		require.Nil(t, err)
+		assert.Equal(t, []string{"/repo/node-js-express.tar.gz", "/repo/node-js-express-2.tar.gz", "/repo/index.json"}, uploads)
+		assert.Equal(t, "https://mirror.internal/repo/index.json", result.IndexLocation)
+	})
+
+	t.Run("fails when the index cannot be downloaded", func(t *testing.T) {
+		outputDir, _ := ioutil.TempDir("", "mirror")
+		defer os.RemoveAll(outputDir)
+		_, err := MirrorTemplateRepo(MirrorOptions{IndexURL: source.URL + "/missing.json", OutputDir: outputDir, BaseURL: "https://mirror.internal"})
+		assert.NotNil(t, err)
+	})
+
+	t.Run("needs either an output directory or an upload URL", func(t *testing.T) {
+		_, err := MirrorTemplateRepo(MirrorOptions{IndexURL: source.URL + "/index.json", BaseURL: "https://mirror.internal"})
+		assert.NotNil(t, err)
+	})
+}
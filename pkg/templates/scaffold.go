@@ -0,0 +1,253 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/project"
+)
+
+type (
+	// IndexEntry is a template in the index.json of a template repository
+	IndexEntry struct {
+		DisplayName  string     `json:"displayName"`
+		Description  string     `json:"description"`
+		Language     string     `json:"language"`
+		ProjectType  string     `json:"projectType"`
+		ProjectStyle string     `json:"projectStyle,omitempty"`
+		Location     string     `json:"location"`
+		Links        IndexLinks `json:"links"`
+	}
+
+	// IndexLinks holds the links of a template in a template repository index
+	IndexLinks struct {
+		Self string `json:"self"`
+	}
+
+	// ScaffoldOptions describes the template to create from a project
+	ScaffoldOptions struct {
+		ProjectPath  string
+		OutputDir    string
+		BaseURL      string
+		Name         string
+		Description  string
+		Language     string
+		ProjectType  string
+		ProjectStyle string
+	}
+
+	// ScaffoldResult describes the template created from a project
+	ScaffoldResult struct {
+		IndexPath   string     `json:"index"`
+		ArchivePath string     `json:"archive"`
+		Files       int        `json:"files"`
+		Template    IndexEntry `json:"template"`
+	}
+)
+
+// indexFileName is the file PFE reads the templates of a repository from
+const indexFileName = "index.json"
+
+var nonSlugCharacters = regexp.MustCompile("[^a-z0-9]+")
+
+// ScaffoldTemplate creates a template repository from a project: the project files are archived as the starter
+// for new projects, and the template is added to the index.json in the output directory, replacing a template of
+// the same name. The repository can then be published at the base URL and added with "templates repos add".
+// Files that the .cw-settings of the project excludes from syncs are left out of the archive, along with .git.
+func ScaffoldTemplate(options ScaffoldOptions) (*ScaffoldResult, error) {
+	info, err := os.Stat(options.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("Error: '%s' is not a project directory", options.ProjectPath)
+	}
+	if _, err := url.ParseRequestURI(options.BaseURL); err != nil {
+		return nil, fmt.Errorf("Error: '%s' is not a valid URL", options.BaseURL)
+	}
+	projectPath, err := filepath.Abs(options.ProjectPath)
+	if err != nil {
+		return nil, err
+	}
+	outputDir, err := filepath.Abs(options.OutputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := newIndexEntry(projectPath, options)
+	slug := templateSlug(entry.DisplayName)
+	if slug == "" {
+		return nil, errors.New("Error: the template name must contain letters or numbers")
+	}
+	entry.Location = strings.TrimSuffix(options.BaseURL, "/") + "/" + slug + ".tar.gz"
+	entry.Links.Self = "/" + slug
+
+	err = os.MkdirAll(outputDir, 0755)
+	if err != nil {
+		return nil, err
+	}
+	archivePath := filepath.Join(outputDir, slug+".tar.gz")
+	files, err := archiveProject(projectPath, archivePath, outputDir)
+	if err != nil {
+		os.Remove(archivePath)
+		return nil, err
+	}
+	indexPath := filepath.Join(outputDir, indexFileName)
+	err = addToIndex(indexPath, entry)
+	if err != nil {
+		return nil, err
+	}
+	return &ScaffoldResult{IndexPath: indexPath, ArchivePath: archivePath, Files: files, Template: entry}, nil
+}
+
+// templateSlug returns a name for the archive of a template from its display name
+func templateSlug(displayName string) string {
+	return strings.Trim(nonSlugCharacters.ReplaceAllString(strings.ToLower(displayName), "-"), "-")
+}
+
+// newIndexEntry describes the template, detecting the language and project type of the project when not given
+func newIndexEntry(projectPath string, options ScaffoldOptions) IndexEntry {
+	entry := IndexEntry{
+		DisplayName:  strings.TrimSpace(options.Name),
+		Description:  strings.TrimSpace(options.Description),
+		Language:     strings.TrimSpace(options.Language),
+		ProjectType:  strings.TrimSpace(options.ProjectType),
+		ProjectStyle: strings.TrimSpace(options.ProjectStyle),
+	}
+	if entry.DisplayName == "" {
+		entry.DisplayName = filepath.Base(projectPath)
+	}
+	if entry.Description == "" {
+		entry.Description = "Template created from the " + filepath.Base(projectPath) + " project"
+	}
+	if entry.Language == "" || entry.ProjectType == "" {
+		detected := project.DetectProjectType(projectPath)
+		if entry.Language == "" {
+			entry.Language = detected.Language
+		}
+		if entry.ProjectType == "" {
+			entry.ProjectType = detected.BuildType
+		}
+	}
+	return entry
+}
+
+// archiveProject writes the project files to a tar.gz archive, returning how many files it contains. The output
+// directory is skipped when it is inside the project.
+func archiveProject(projectPath string, archivePath string, outputDir string) (int, error) {
+	ignoredPaths := project.IgnoredPaths(projectPath)
+	return archiveDirectory(projectPath, archivePath, func(relativePath string, info os.FileInfo) bool {
+		if info.IsDir() && (filepath.Join(projectPath, relativePath) == outputDir || info.Name() == ".git") {
+			return true
+		}
+		return project.IsIgnoredPath(relativePath, info.IsDir(), ignoredPaths)
+	})
+}
+
+// archiveDirectory writes the files in a directory to a tar.gz archive, with paths relative to the directory,
+// returning how many files it contains. Files and directories for which skip returns true are left out.
+func archiveDirectory(root string, archivePath string, skip func(relativePath string, info os.FileInfo) bool) (int, error) {
+	archive, err := os.Create(archivePath)
+	if err != nil {
+		return 0, err
+	}
+	defer archive.Close()
+	gzipWriter := gzip.NewWriter(archive)
+	tarWriter := tar.NewWriter(gzipWriter)
+
+	files := 0
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if path == root {
+			return nil
+		}
+		relativePath := filepath.ToSlash(path[len(root)+1:])
+		if info.IsDir() && skip(relativePath, info) {
+			return filepath.SkipDir
+		}
+		if !info.IsDir() && (!info.Mode().IsRegular() || skip(relativePath, info)) {
+			return nil
+		}
+
+		header, err := tar.FileInfoHeader(info, "")
+		if err != nil {
+			return err
+		}
+		header.Name = relativePath
+		if err := tarWriter.WriteHeader(header); err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		if _, err := io.Copy(tarWriter, file); err != nil {
+			return err
+		}
+		files++
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := tarWriter.Close(); err != nil {
+		return 0, err
+	}
+	return files, gzipWriter.Close()
+}
+
+// addToIndex adds a template to an index.json, creating it if needed
+func addToIndex(indexPath string, entry IndexEntry) error {
+	index := []IndexEntry{}
+	contents, err := ioutil.ReadFile(indexPath)
+	if err == nil {
+		if err := json.Unmarshal(contents, &index); err != nil {
+			return fmt.Errorf("Error: unable to read the existing %s: %s", indexPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return err
+	}
+
+	replaced := false
+	for i := range index {
+		if strings.EqualFold(index[i].DisplayName, entry.DisplayName) {
+			index[i] = entry
+			replaced = true
+		}
+	}
+	if !replaced {
+		index = append(index, entry)
+	}
+	contents, err = json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(indexPath, contents, 0644)
+}
@@ -0,0 +1,83 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScaffoldTemplate(t *testing.T) {
+	projectPath, _ := ioutil.TempDir("", "golden-app")
+	defer os.RemoveAll(projectPath)
+	writeFile := func(name, contents string) {
+		os.MkdirAll(filepath.Dir(filepath.Join(projectPath, name)), 0755)
+		ioutil.WriteFile(filepath.Join(projectPath, name), []byte(contents), 0644)
+	}
+	writeFile("package.json", `{"name": "golden-app"}`)
+	writeFile("server/server.js", "console.log('hello')")
+	writeFile("node_modules/express/index.js", "module.exports = {}")
+	writeFile(".git/HEAD", "ref: refs/heads/master")
+	writeFile(".cw-settings", `{"ignoredPaths": ["node_modules"]}`)
+	outputDir := filepath.Join(projectPath, "template-repo")
+
+	t.Run("archives the project and indexes it with the detected language", func(t *testing.T) {
+		result, err := ScaffoldTemplate(ScaffoldOptions{ProjectPath: projectPath, OutputDir: outputDir, BaseURL: "https://templates.example.com/repo/", Name: "Golden App"})
+		require.Nil(t, err)
+		assert.Equal(t, IndexEntry{
+			DisplayName: "Golden App",
+			Description: "Template created from the " + filepath.Base(projectPath) + " project",
+			Language:    "javascript",
+			ProjectType: "nodejs",
+			Location:    "https://templates.example.com/repo/golden-app.tar.gz",
+			Links:       IndexLinks{Self: "/golden-app"},
+		}, result.Template)
+		assert.Equal(t, 3, result.Files)
+
+		extractDir, _ := ioutil.TempDir("", "golden-app-extract")
+		defer os.RemoveAll(extractDir)
+		require.Nil(t, utils.UnTar(result.ArchivePath, extractDir))
+		assert.FileExists(t, filepath.Join(extractDir, "server", "server.js"))
+		assert.FileExists(t, filepath.Join(extractDir, ".cw-settings"))
+		assert.False(t, utils.PathExists(filepath.Join(extractDir, "node_modules")))
+		assert.False(t, utils.PathExists(filepath.Join(extractDir, ".git")))
+		assert.False(t, utils.PathExists(filepath.Join(extractDir, "template-repo")))
+	})
+
+	t.Run("replaces a template of the same name in an existing index", func(t *testing.T) {
+		_, err := ScaffoldTemplate(ScaffoldOptions{ProjectPath: projectPath, OutputDir: outputDir, BaseURL: "https://templates.example.com/repo", Name: "Other App", Language: "go", ProjectType: "docker"})
+		require.Nil(t, err)
+		_, err = ScaffoldTemplate(ScaffoldOptions{ProjectPath: projectPath, OutputDir: outputDir, BaseURL: "https://templates.example.com/repo", Name: "golden app", Description: "Updated"})
+		require.Nil(t, err)
+
+		contents, _ := ioutil.ReadFile(filepath.Join(outputDir, "index.json"))
+		index := []IndexEntry{}
+		require.Nil(t, json.Unmarshal(contents, &index))
+		require.Len(t, index, 2)
+		assert.Equal(t, "Updated", index[0].Description)
+		assert.Equal(t, "go", index[1].Language)
+	})
+
+	t.Run("rejects a missing project or invalid URL", func(t *testing.T) {
+		_, err := ScaffoldTemplate(ScaffoldOptions{ProjectPath: filepath.Join(projectPath, "missing"), OutputDir: outputDir, BaseURL: "https://templates.example.com"})
+		assert.NotNil(t, err)
+		_, err = ScaffoldTemplate(ScaffoldOptions{ProjectPath: projectPath, OutputDir: outputDir, BaseURL: "templates"})
+		assert.NotNil(t, err)
+	})
+}
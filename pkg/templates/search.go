@@ -0,0 +1,113 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/eclipse/codewind-installer/pkg/connections"
+)
+
+type (
+	// SearchOptions filters the templates returned by a search
+	SearchOptions struct {
+		Keywords     []string
+		Language     string
+		ProjectStyle string
+	}
+
+	// TemplateMatch is a template found by a search, with the connection it is available from
+	TemplateMatch struct {
+		apiroutes.Template
+		ConnectionID string `json:"conid"`
+	}
+
+	// SearchResult holds the templates found, and the connections that could not be searched
+	SearchResult struct {
+		Templates []TemplateMatch   `json:"templates"`
+		Errors    map[string]string `json:"errors,omitempty"`
+	}
+)
+
+// SearchTemplates searches the templates of the enabled repositories of a connection, or of every connection when
+// conID is "all". A connection that cannot be reached is reported in the errors of the result, so the templates of
+// the others are still returned.
+func SearchTemplates(conID string, options SearchOptions) (*SearchResult, error) {
+	search := func(connection *connections.Connection) (interface{}, error) {
+		templates, err := apiroutes.GetTemplates(connection.ID, "", true)
+		if err != nil {
+			return nil, err
+		}
+		return templates, nil
+	}
+
+	var batch *connections.BatchResult
+	if connections.IsAllConnections(conID) {
+		var conErr *connections.ConError
+		batch, conErr = connections.ForEachConnection(search)
+		if conErr != nil {
+			return nil, conErr.Err
+		}
+	} else {
+		connection, conErr := connections.GetConnectionByID(conID)
+		if conErr != nil {
+			return nil, conErr.Err
+		}
+		templates, err := search(connection)
+		if err != nil {
+			return nil, err
+		}
+		batch = &connections.BatchResult{Connections: map[string]interface{}{connection.ID: templates}}
+	}
+
+	result := SearchResult{Templates: []TemplateMatch{}, Errors: batch.Errors}
+	for _, id := range batch.SortedIDs() {
+		templates, _ := batch.Connections[id].([]apiroutes.Template)
+		for _, template := range templates {
+			if MatchesSearch(template, options) {
+				result.Templates = append(result.Templates, TemplateMatch{template, id})
+			}
+		}
+	}
+	sort.SliceStable(result.Templates, func(i, j int) bool {
+		return strings.ToLower(result.Templates[i].Label) < strings.ToLower(result.Templates[j].Label)
+	})
+	return &result, nil
+}
+
+// MatchesSearch reports whether a template has the language and style searched for, and every keyword appears in
+// its label, description, language, project type or source. Matching ignores case.
+func MatchesSearch(template apiroutes.Template, options SearchOptions) bool {
+	if options.Language != "" && !strings.EqualFold(template.Language, options.Language) {
+		return false
+	}
+	if options.ProjectStyle != "" && !strings.EqualFold(TemplateStyle(template), options.ProjectStyle) {
+		return false
+	}
+	searchable := strings.ToLower(strings.Join([]string{template.Label, template.Description, template.Language, template.ProjectType, template.Source}, " "))
+	for _, keyword := range options.Keywords {
+		if !strings.Contains(searchable, strings.ToLower(keyword)) {
+			return false
+		}
+	}
+	return true
+}
+
+// TemplateStyle returns the project style of a template, templates without one being Codewind style
+func TemplateStyle(template apiroutes.Template) string {
+	if template.ProjectStyle == "" {
+		return "Codewind"
+	}
+	return template.ProjectStyle
+}
@@ -0,0 +1,78 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package templates
+
+import (
+	"testing"
+
+	"github.com/eclipse/codewind-installer/pkg/apiroutes"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMatchesSearch(t *testing.T) {
+	springTemplate := apiroutes.Template{
+		Label:       "Spring Boot®",
+		Description: "Spring Boot® web application",
+		Language:    "java",
+		ProjectType: "spring",
+		Source:      "Default templates",
+	}
+	appsodyTemplate := apiroutes.Template{
+		Label:        "Appsody Express",
+		Description:  "Express web framework for Node.js",
+		Language:     "nodejs",
+		ProjectType:  "nodejs",
+		ProjectStyle: "Appsody",
+		Source:       "Appsody Stacks",
+	}
+
+	tests := map[string]struct {
+		options     SearchOptions
+		wantSpring  bool
+		wantAppsody bool
+	}{
+		"no filters match every template": {
+			options:    SearchOptions{},
+			wantSpring: true, wantAppsody: true,
+		},
+		"keywords match the label and description ignoring case": {
+			options:    SearchOptions{Keywords: []string{"WEB", "spring"}},
+			wantSpring: true, wantAppsody: false,
+		},
+		"every keyword must match": {
+			options:    SearchOptions{Keywords: []string{"web", "python"}},
+			wantSpring: false, wantAppsody: false,
+		},
+		"keywords match the source": {
+			options:    SearchOptions{Keywords: []string{"stacks"}},
+			wantSpring: false, wantAppsody: true,
+		},
+		"language must match exactly": {
+			options:    SearchOptions{Language: "Java"},
+			wantSpring: true, wantAppsody: false,
+		},
+		"templates without a style are Codewind style": {
+			options:    SearchOptions{ProjectStyle: "codewind"},
+			wantSpring: true, wantAppsody: false,
+		},
+		"style and keywords are combined": {
+			options:    SearchOptions{ProjectStyle: "Appsody", Keywords: []string{"express"}},
+			wantSpring: false, wantAppsody: true,
+		},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.wantSpring, MatchesSearch(springTemplate, test.options))
+			assert.Equal(t, test.wantAppsody, MatchesSearch(appsodyTemplate, test.options))
+		})
+	}
+}
@@ -0,0 +1,73 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	"github.com/eclipse/codewind-installer/pkg/appconstants"
+)
+
+const (
+	// ChannelStable : Install the Codewind release this cwctl was released with
+	ChannelStable = "stable"
+	// ChannelLatest : Install the most recent Codewind images
+	ChannelLatest = "latest"
+)
+
+// releaseVersion is the version of this cwctl, replaced in tests
+var releaseVersion = appconstants.VersionNum
+
+var releaseVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+
+// ResolveImageTag : Returns the Codewind image tag to use for a channel or an explicit tag, only one of which may be
+// given. Defaults to the latest channel when neither is.
+func ResolveImageTag(channel string, tag string) (string, error) {
+	if tag != "" {
+		if channel != "" {
+			return "", errors.New("Use either a channel or a tag, not both")
+		}
+		return tag, nil
+	}
+	switch channel {
+	case "", ChannelLatest:
+		return "latest", nil
+	case ChannelStable:
+		if !releaseVersionPattern.MatchString(releaseVersion) {
+			return "", errors.New("The stable channel is only available in release builds of cwctl, use a tag to pin a version instead")
+		}
+		return releaseVersion, nil
+	}
+	return "", errors.New("Unknown channel " + channel + ", expected " + ChannelStable + " or " + ChannelLatest)
+}
+
+// ImageTag : Returns the tag of an image reference, which is latest when the reference has none
+func ImageTag(image string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	separator := strings.LastIndex(image, ":")
+	if separator == -1 || strings.Contains(image[separator:], "/") {
+		return "latest"
+	}
+	return image[separator+1:]
+}
+
+// WithImageTag : Returns an image reference with its tag replaced
+func WithImageTag(image string, tag string) string {
+	image = strings.SplitN(image, "@", 2)[0]
+	separator := strings.LastIndex(image, ":")
+	if separator != -1 && !strings.Contains(image[separator:], "/") {
+		image = image[:separator]
+	}
+	return image + ":" + tag
+}
@@ -0,0 +1,65 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveImageTag(t *testing.T) {
+	originalReleaseVersion := releaseVersion
+	defer func() { releaseVersion = originalReleaseVersion }()
+	releaseVersion = "0.9.0"
+
+	tests := map[string]struct {
+		channel string
+		tag     string
+		want    string
+		wantErr bool
+	}{
+		"defaults to latest":            {"", "", "latest", false},
+		"latest channel":                {ChannelLatest, "", "latest", false},
+		"stable channel is the release": {ChannelStable, "", "0.9.0", false},
+		"explicit tag":                  {"", "0.8.0", "0.8.0", false},
+		"channel and tag":               {ChannelStable, "0.8.0", "", true},
+		"unknown channel":               {"nightly", "", "", true},
+	}
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			tag, err := ResolveImageTag(test.channel, test.tag)
+			assert.Equal(t, test.want, tag)
+			assert.Equal(t, test.wantErr, err != nil)
+		})
+	}
+
+	t.Run("stable channel is not available in development builds", func(t *testing.T) {
+		releaseVersion = "x.x.dev"
+		_, err := ResolveImageTag(ChannelStable, "")
+		assert.Error(t, err)
+	})
+}
+
+func TestImageTag(t *testing.T) {
+	assert.Equal(t, "0.9.0", ImageTag("eclipse/codewind-pfe-amd64:0.9.0"))
+	assert.Equal(t, "latest", ImageTag("eclipse/codewind-pfe-amd64"))
+	assert.Equal(t, "latest", ImageTag("registry.example.com:5000/codewind-pfe-amd64"))
+	assert.Equal(t, "dev", ImageTag("registry.example.com:5000/codewind-pfe-amd64:dev"))
+	assert.Equal(t, "0.9.0", ImageTag("eclipse/codewind-pfe-amd64:0.9.0@sha256:abc"))
+}
+
+func TestWithImageTag(t *testing.T) {
+	assert.Equal(t, "eclipse/codewind-pfe-amd64:0.9.0", WithImageTag("eclipse/codewind-pfe-amd64:latest", "0.9.0"))
+	assert.Equal(t, "eclipse/codewind-pfe-amd64:0.9.0", WithImageTag("eclipse/codewind-pfe-amd64", "0.9.0"))
+	assert.Equal(t, "registry.example.com:5000/pfe:0.9.0", WithImageTag("registry.example.com:5000/pfe", "0.9.0"))
+}
@@ -0,0 +1,120 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+)
+
+// GitCloneOptions : The revision to check out from a git repository, and how to authenticate to it
+type GitCloneOptions struct {
+	Branch string
+	Tag    string
+	Commit string
+	// SSHKey is the private key used for SSH URLs, the SSH agent and default keys are used when empty
+	SSHKey      string
+	Credentials *GitCredentials
+}
+
+// scpLikeGitURL matches the user@host:path form of SSH URLs, such as git@github.com:org/repo.git
+var scpLikeGitURL = regexp.MustCompile(`^[A-Za-z0-9._-]+@[A-Za-z0-9.-]+:[^/]`)
+
+// IsGitCloneURL returns whether a URL should be cloned with git rather than downloaded: SSH and git protocol URLs,
+// and HTTPS URLs ending in .git
+func IsGitCloneURL(repoURL string) bool {
+	return scpLikeGitURL.MatchString(repoURL) ||
+		strings.HasPrefix(repoURL, "ssh://") ||
+		strings.HasPrefix(repoURL, "git://") ||
+		strings.HasSuffix(strings.TrimSuffix(repoURL, "/"), ".git")
+}
+
+// GitClone clones a git repository to a destination, checking out the branch, tag or commit given, or the default
+// branch. Credentials are passed to git in its environment, so they are neither shown in the process list nor saved
+// in the configuration of the clone. Branches and tags are cloned without their history.
+func GitClone(repoURL string, destination string, options GitCloneOptions) error {
+	refs := 0
+	for _, ref := range []string{options.Branch, options.Tag, options.Commit} {
+		if ref != "" {
+			refs++
+		}
+	}
+	if refs > 1 {
+		return errors.New("only one of branch, tag or commit can be given")
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		return errors.New("git must be installed to clone " + repoURL)
+	}
+
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if options.SSHKey != "" {
+		if _, err := os.Stat(options.SSHKey); err != nil {
+			return err
+		}
+		env = append(env, "GIT_SSH_COMMAND=ssh -i '"+strings.Replace(options.SSHKey, "'", `'\''`, -1)+"' -o IdentitiesOnly=yes")
+	}
+	if header := gitAuthorizationHeader(options.Credentials); header != "" {
+		env = append(env, "GIT_CONFIG_COUNT=1", "GIT_CONFIG_KEY_0=http.extraHeader", "GIT_CONFIG_VALUE_0="+header)
+	}
+
+	args := []string{"clone", "--quiet"}
+	switch {
+	case options.Branch != "":
+		args = append(args, "--depth", "1", "--branch", options.Branch)
+	case options.Tag != "":
+		args = append(args, "--depth", "1", "--branch", options.Tag)
+	case options.Commit != "":
+		args = append(args, "--no-checkout")
+	}
+	args = append(args, "--", repoURL, destination)
+	if err := runGit("", env, args...); err != nil {
+		return err
+	}
+	if options.Commit != "" {
+		return runGit(destination, env, "checkout", "--quiet", "--detach", options.Commit)
+	}
+	return nil
+}
+
+// gitAuthorizationHeader returns the basic authentication header for HTTPS git requests. Hosts such as GitHub
+// and GitLab accept a personal access token as the password of any username.
+func gitAuthorizationHeader(credentials *GitCredentials) string {
+	if credentials == nil {
+		return ""
+	}
+	username, password := credentials.Username, credentials.Password
+	if credentials.PersonalAccessToken != "" {
+		username, password = "x-access-token", credentials.PersonalAccessToken
+	}
+	if username == "" && password == "" {
+		return ""
+	}
+	return "Authorization: Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
+func runGit(dir string, env []string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("git %s failed: %s", args[0], strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
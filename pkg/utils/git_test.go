@@ -0,0 +1,132 @@
+/*******************************************************************************
+ * Copyright (c) 2020 IBM Corporation and others.
+ * All rights reserved. This program and the accompanying materials
+ * are made available under the terms of the Eclipse Public License v2.0
+ * which accompanies this distribution, and is available at
+ * http://www.eclipse.org/legal/epl-v20.html
+ *
+ * Contributors:
+ *     IBM Corporation - initial API and implementation
+ *******************************************************************************/
+
+package utils
+
+import (
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsGitCloneURL(t *testing.T) {
+	tests := map[string]bool{
+		"git@github.com:eclipse/codewind.git":                        true,
+		"ssh://git@github.ibm.com/org/repo.git":                      true,
+		"git://example.com/repo":                                     true,
+		"https://github.com/eclipse/codewind.git":                    true,
+		"https://github.com/eclipse/codewind":                        false,
+		"https://github.com/eclipse/codewind/archive/master.tar.gz":  false,
+		"https://github.com/codewind-resources/nodeExpressTemplate/": false,
+		"https://user@example.com/repo":                              false,
+		"file:///tmp/repo.git":                                       true,
+	}
+	for repoURL, want := range tests {
+		t.Run(repoURL, func(t *testing.T) {
+			assert.Equal(t, want, IsGitCloneURL(repoURL))
+		})
+	}
+}
+
+func TestGitAuthorizationHeader(t *testing.T) {
+	decode := func(header string) string {
+		decoded, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(header, "Authorization: Basic "))
+		return string(decoded)
+	}
+	assert.Equal(t, "", gitAuthorizationHeader(nil))
+	assert.Equal(t, "", gitAuthorizationHeader(&GitCredentials{}))
+	assert.Equal(t, "user:password", decode(gitAuthorizationHeader(&GitCredentials{Username: "user", Password: "password"})))
+	assert.Equal(t, "x-access-token:token", decode(gitAuthorizationHeader(&GitCredentials{PersonalAccessToken: "token"})))
+}
+
+func TestGitClone(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir, err := ioutil.TempDir("", "git-clone-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Create a repository with two commits, the first tagged v1 and the second on a branch
+	repo := filepath.Join(dir, "repo")
+	git := func(args ...string) string {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %s", args, output)
+		}
+		return strings.TrimSpace(string(output))
+	}
+	commit := func(contents string) {
+		ioutil.WriteFile(filepath.Join(repo, "file.txt"), []byte(contents), 0644)
+		git("add", "file.txt")
+		git("commit", "--quiet", "-m", contents)
+	}
+	os.MkdirAll(repo, 0755)
+	git("init", "--quiet")
+	commit("first")
+	firstCommit := git("rev-parse", "HEAD")
+	git("tag", "v1")
+	git("checkout", "--quiet", "-b", "feature")
+	commit("second")
+	repoURL := "file://" + filepath.ToSlash(repo)
+
+	readClone := func(destination string) string {
+		contents, _ := ioutil.ReadFile(filepath.Join(destination, "file.txt"))
+		return string(contents)
+	}
+
+	t.Run("clones a branch", func(t *testing.T) {
+		destination := filepath.Join(dir, "branch")
+		err := GitClone(repoURL, destination, GitCloneOptions{Branch: "feature"})
+		assert.Nil(t, err)
+		assert.Equal(t, "second", readClone(destination))
+	})
+
+	t.Run("clones a tag", func(t *testing.T) {
+		destination := filepath.Join(dir, "tag")
+		err := GitClone(repoURL, destination, GitCloneOptions{Tag: "v1"})
+		assert.Nil(t, err)
+		assert.Equal(t, "first", readClone(destination))
+	})
+
+	t.Run("clones a commit", func(t *testing.T) {
+		destination := filepath.Join(dir, "commit")
+		err := GitClone(repoURL, destination, GitCloneOptions{Commit: firstCommit})
+		assert.Nil(t, err)
+		assert.Equal(t, "first", readClone(destination))
+	})
+
+	t.Run("reports a revision that does not exist", func(t *testing.T) {
+		err := GitClone(repoURL, filepath.Join(dir, "missing"), GitCloneOptions{Branch: "missing"})
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "git clone failed")
+	})
+
+	t.Run("rejects more than one revision", func(t *testing.T) {
+		err := GitClone(repoURL, filepath.Join(dir, "both"), GitCloneOptions{Branch: "feature", Tag: "v1"})
+		assert.EqualError(t, err, "only one of branch, tag or commit can be given")
+	})
+
+	t.Run("reports an SSH key that does not exist", func(t *testing.T) {
+		err := GitClone(repoURL, filepath.Join(dir, "key"), GitCloneOptions{SSHKey: filepath.Join(dir, "id_missing")})
+		assert.NotNil(t, err)
+	})
+}
@@ -11,7 +11,10 @@
 
 package utils
 
-import "fmt"
+import (
+	"fmt"
+	"os"
+)
 
 type (
 	// TemplateRepo represents a template repository.
@@ -110,6 +113,29 @@ func OnDeleteTemplateRepo(extensions []Extension, url string, repos []TemplateRe
 	}
 }
 
+// Environment variables holding git credentials, so they need not be given on the command line
+const (
+	GitUsernameEnvVar = "CW_GIT_USERNAME"
+	GitPasswordEnvVar = "CW_GIT_PASSWORD"
+	GitTokenEnvVar    = "CW_GIT_TOKEN"
+)
+
+// GitCredentialsFromEnvironment fills in git credentials that were not provided as arguments from the environment.
+// A password is read for a username that was provided, otherwise the environment is only used when no credentials
+// were provided, a personal access token being preferred to a username and password.
+func GitCredentialsFromEnvironment(username, password, personalAccessToken string) (string, string, string) {
+	if username != "" && password == "" {
+		return username, os.Getenv(GitPasswordEnvVar), personalAccessToken
+	}
+	if username != "" || password != "" || personalAccessToken != "" {
+		return username, password, personalAccessToken
+	}
+	if token := os.Getenv(GitTokenEnvVar); token != "" {
+		return "", "", token
+	}
+	return os.Getenv(GitUsernameEnvVar), os.Getenv(GitPasswordEnvVar), ""
+}
+
 // ExtractGitCredentials extracts and formats git credentials from the provided arguments
 func ExtractGitCredentials(username, password, personalAccessToken string) (*GitCredentials, error) {
 	if personalAccessToken != "" && (username != "" || password != "") {
@@ -12,11 +12,39 @@
 package utils
 
 import (
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 )
 
+func TestGitCredentialsFromEnvironment(t *testing.T) {
+	os.Setenv(GitUsernameEnvVar, "envuser")
+	os.Setenv(GitPasswordEnvVar, "envpassword")
+	os.Setenv(GitTokenEnvVar, "envtoken")
+	defer os.Unsetenv(GitUsernameEnvVar)
+	defer os.Unsetenv(GitPasswordEnvVar)
+	defer os.Unsetenv(GitTokenEnvVar)
+
+	t.Run("credentials given as arguments are kept", func(t *testing.T) {
+		username, password, token := GitCredentialsFromEnvironment("user", "password", "")
+		assert.Equal(t, []string{"user", "password", ""}, []string{username, password, token})
+		username, password, token = GitCredentialsFromEnvironment("", "", "token")
+		assert.Equal(t, []string{"", "", "token"}, []string{username, password, token})
+	})
+	t.Run("the password for a username given as an argument is read from the environment", func(t *testing.T) {
+		username, password, token := GitCredentialsFromEnvironment("user", "", "")
+		assert.Equal(t, []string{"user", "envpassword", ""}, []string{username, password, token})
+	})
+	t.Run("a token in the environment is preferred to a username and password", func(t *testing.T) {
+		username, password, token := GitCredentialsFromEnvironment("", "", "")
+		assert.Equal(t, []string{"", "", "envtoken"}, []string{username, password, token})
+		os.Unsetenv(GitTokenEnvVar)
+		username, password, token = GitCredentialsFromEnvironment("", "", "")
+		assert.Equal(t, []string{"envuser", "envpassword", ""}, []string{username, password, token})
+	})
+}
+
 func TestExtractGitCredentials_Fail(t *testing.T) {
 	tests := map[string]struct {
 		inUsername            string